@@ -74,9 +74,9 @@ func (e *Editor) Update() error {
 				)
 			}
 		case xgal.Tap(xgal.KeyH):
-			e.Cell.Flag ^= FlagHorizontalFlip
+			e.Cell.Orient(e.Cell.Orientation().FlipH())
 		case xgal.Tap(xgal.KeyV):
-			e.Cell.Flag ^= FlagVerticalFlip
+			e.Cell.Orient(e.Cell.Orientation().FlipV())
 		case xgal.Tap(xgal.KeyF10):
 			e.Error = nil
 		case xgal.Tap(xgal.KeyF1):
@@ -11,7 +11,8 @@ import (
 )
 
 import (
-	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/xmasengine/xmas/xdat"
+	"github.com/xmasengine/xmas/xgal"
 )
 
 // Format is just the lowercase extension including the '.' prefix.
@@ -57,6 +58,28 @@ type Cell struct {
 	Flag  Flag `json:"flag" xml:"flag,attr"`
 }
 
+// Orientation returns the orientation of the cell's SMS flip bits.
+func (c Cell) Orientation() xdat.Orientation {
+	return xdat.FlipOrientation(c.Flag&FlagHorizontalFlip != 0, c.Flag&FlagVerticalFlip != 0)
+}
+
+// Orient sets the flip bits of the cell to o. The SMS has no rotation bits,
+// so it returns false and leaves the cell alone if o cannot be expressed.
+func (c *Cell) Orient(o xdat.Orientation) bool {
+	h, v, ok := o.Flips()
+	if !ok {
+		return false
+	}
+	c.Flag &^= FlagHorizontalFlip | FlagVerticalFlip
+	if h {
+		c.Flag |= FlagHorizontalFlip
+	}
+	if v {
+		c.Flag |= FlagVerticalFlip
+	}
+	return true
+}
+
 type Row struct {
 	Cells []Cell `json:"cells" xml:"cells"`
 }
@@ -179,24 +202,10 @@ func (m *Map) Render(screen *Surface, camera Rectangle) {
 			fy := idy * m.Th
 
 			from := image.Rect(fx, fy, fx+m.Tw, fy+m.Th)
-			sub := m.Surface.SubImage(from).(*Surface)
-			opts := ebiten.DrawImageOptions{}
-			if cell.Flag&FlagHorizontalFlip != 0 {
-				opts.GeoM.Scale(-1, 1)
-				opts.GeoM.Translate(float64(m.Tw), 0)
-			}
-			if cell.Flag&FlagVerticalFlip != 0 {
-				opts.GeoM.Scale(1, -1)
-				opts.GeoM.Translate(0, float64(m.Th))
-			}
-			opts.GeoM.Translate(
-				float64(int(tx)*m.Tw-camera.Min.X),
-				float64(int(ty)*m.Th-camera.Min.Y),
-			)
-
-			if sub != nil {
-				screen.DrawImage(sub, &opts)
-			}
+			atx := int(tx)*m.Tw - camera.Min.X
+			aty := int(ty)*m.Th - camera.Min.Y
+			to := image.Rect(atx, aty, atx+m.Tw, aty+m.Th)
+			xgal.Blit(screen, m.Surface, to, from, cell.Orientation().Opts())
 		}
 	}
 }
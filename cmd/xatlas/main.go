@@ -0,0 +1,195 @@
+// xatlas packs the images under a directory in a texture atlas, and writes
+// the atlas image and its index. Sprite sheets with animations, which are
+// .aseprite files and images with animations next to them, such as
+// character.xml or character.json for character.png, are packed whole,
+// so players and things can use them as their texture, and also frame by
+// frame, named like gfx/character.png#walk_south_0.
+//
+//	xatlas [-root pack] [-o pack/atlas/atlas] [-pad 1] [-extrude 1] [-trim] [-max 4096] [dir|file...]
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/png"
+	"io/fs"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/xmasengine/xmas/xdat"
+	"github.com/xmasengine/xmas/xres"
+)
+
+// extensions are the extensions of the images that are packed.
+var extensions = map[string]bool{".png": true, ".gif": true, ".aseprite": true, ".ase": true}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: xatlas [-root pack] [-o pack/atlas/atlas] [-pad 1] [-extrude 1] [-trim] [-max 4096] [dir|file...]")
+	flag.PrintDefaults()
+	os.Exit(2)
+}
+
+// animations returns the animations of the image, or nil if it has none.
+func animations(fsys fs.FS, name string) (*xdat.Animations, error) {
+	ext := strings.ToLower(path.Ext(name))
+	if ext == ".aseprite" || ext == ".ase" {
+		return xdat.LoadAnimations(fsys, name)
+	}
+	base := strings.TrimSuffix(name, path.Ext(name))
+	if _, err := fs.Stat(fsys, base+".json"); err == nil {
+		return xdat.LoadAnimations(fsys, base+".json")
+	}
+	if _, err := fs.Stat(fsys, base+".xml"); err == nil {
+		// Other XML files may share the name, so only use animations.
+		if anims, err := xdat.LoadAnimations(fsys, base+".xml"); err == nil {
+			return anims, nil
+		}
+	}
+	return nil, nil
+}
+
+// collect decodes the named image and splits it in frames if it has
+// animations, after the whole image. Frames that are in several clips are
+// packed once, and the names of the other clips are added to aliases.
+func collect(fsys fs.FS, name string, aliases map[string]string) ([]xres.AtlasImage, error) {
+	fin, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	img, _, err := image.Decode(fin)
+	fin.Close()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	anims, err := animations(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	if anims == nil {
+		return []xres.AtlasImage{{Name: name, Image: img}}, nil
+	}
+	sub, ok := img.(interface {
+		SubImage(image.Rectangle) image.Image
+	})
+	if !ok {
+		return nil, fmt.Errorf("%s: cannot split %T", name, img)
+	}
+	images := []xres.AtlasImage{{Name: name, Image: img}}
+	packed := map[image.Rectangle]string{}
+	for _, clip := range anims.Clips {
+		prefix := clip.Action
+		if clip.Direction != xdat.AnyDirection {
+			prefix += "_" + clip.Direction.String()
+		}
+		for i, frame := range clip.Frames {
+			frameName := fmt.Sprintf("%s#%s_%d", name, prefix, i)
+			if first, ok := packed[frame.Area()]; ok {
+				aliases[frameName] = first
+				continue
+			}
+			packed[frame.Area()] = frameName
+			images = append(images, xres.AtlasImage{Name: frameName, Image: sub.SubImage(frame.Area())})
+		}
+	}
+	return images, nil
+}
+
+func main() {
+	root := flag.String("root", "pack", "directory the names of the images are relative to")
+	out := flag.String("o", xres.AtlasDir+"/atlas", "atlas to write, without extension")
+	pad := flag.Int("pad", 1, "pixels between images")
+	extrude := flag.Int("extrude", 1, "pixels to repeat the edges of images")
+	trim := flag.Bool("trim", false, "remove the transparent borders of images")
+	limit := flag.Int("max", 4096, "largest width and height of the atlas")
+	flag.Usage = usage
+	flag.Parse()
+
+	fsys := os.DirFS(*root)
+	dirs := flag.Args()
+	if len(dirs) == 0 {
+		dirs = []string{*root}
+	}
+	// Do not pack atlases into themselves.
+	skip, _ := filepath.Rel(*root, filepath.Dir(*out))
+	skip = filepath.ToSlash(skip)
+
+	var images []xres.AtlasImage
+	aliases := map[string]string{}
+	for _, dir := range dirs {
+		rel, err := filepath.Rel(*root, dir)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			fmt.Fprintf(os.Stderr, "%s is not under %s\n", dir, *root)
+			os.Exit(1)
+		}
+		err = fs.WalkDir(fsys, filepath.ToSlash(rel), func(name string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if name == skip && name != "." {
+					return fs.SkipDir
+				}
+				return nil
+			}
+			if !extensions[strings.ToLower(path.Ext(name))] {
+				return nil
+			}
+			found, err := collect(fsys, name, aliases)
+			images = append(images, found...)
+			return err
+		})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+	if len(images) == 0 {
+		fmt.Fprintln(os.Stderr, "no images to pack")
+		os.Exit(1)
+	}
+
+	opts := xres.AtlasOptions{Padding: *pad, Extrude: *extrude, Trim: *trim, Limit: *limit}
+	atlas, index, err := xres.BuildAtlas(images, opts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	for _, region := range index.Regions {
+		for _, alias := range slices.Sorted(maps.Keys(aliases)) {
+			if aliases[alias] == region.Name {
+				region.Name = alias
+				index.Regions = append(index.Regions, region)
+			}
+		}
+	}
+	index.Source = filepath.Base(*out) + ".png"
+	index.Root = filepath.ToSlash(filepath.Clean(*root))
+
+	if err := os.MkdirAll(filepath.Dir(*out), 0755); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fout, err := os.Create(*out + ".png")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	err = png.Encode(fout, atlas)
+	if cerr := fout.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = index.SaveFile(*out + ".xml")
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	bounds := atlas.Bounds()
+	fmt.Printf("packed %d images in %dx%d\n", len(index.Regions), bounds.Dx(), bounds.Dy())
+}
@@ -0,0 +1,216 @@
+// xfont converts bitmap fonts between BDF and sprite sheet fonts, and
+// previews fonts with sample text.
+//
+//	xfont sheet [-columns 16] [-o font.font] font.bdf
+//	xfont bdf [-name font] [-o font.bdf] font.font
+//	xfont preview [-text sample] [-scale 3] [-o preview.png] font
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/zachomedia/go-bdf"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+
+	"github.com/xmasengine/xmas/xgal"
+)
+
+const Sample = "The quick brown fox jumps over the lazy dog.\n" +
+	"THE QUICK BROWN FOX JUMPS OVER THE LAZY DOG!\n" +
+	"0123456789 .,:;?#_-'\""
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: xfont sheet [-columns 16] [-o font.font] font.bdf")
+	fmt.Fprintln(os.Stderr, "       xfont bdf [-name font] [-o font.bdf] font.font")
+	fmt.Fprintln(os.Stderr, "       xfont preview [-text sample] [-scale 3] [-o preview.png] font")
+	os.Exit(2)
+}
+
+// replaceExt returns name with its extension replaced by ext.
+func replaceExt(name, ext string) string {
+	return strings.TrimSuffix(name, filepath.Ext(name)) + ext
+}
+
+func sheet(args []string) error {
+	set := flag.NewFlagSet("sheet", flag.ExitOnError)
+	columns := set.Int("columns", 16, "glyphs per row of the sheet")
+	out := set.String("o", "", "description to write, next to the BDF font if empty")
+	set.Parse(args)
+	if set.NArg() != 1 {
+		usage()
+	}
+	in := set.Arg(0)
+	buf, err := os.ReadFile(in)
+	if err != nil {
+		return err
+	}
+	f, err := xgal.SheetFromBDF(buf, *columns)
+	if err != nil {
+		return fmt.Errorf("%s: %w", in, err)
+	}
+	if *out == "" {
+		*out = replaceExt(in, ".font")
+	}
+	return f.SaveFile(*out)
+}
+
+func toBDF(args []string) error {
+	set := flag.NewFlagSet("bdf", flag.ExitOnError)
+	name := set.String("name", "", "name of the BDF font, the file name if empty")
+	out := set.String("o", "", "BDF font to write, next to the description if empty")
+	set.Parse(args)
+	if set.NArg() != 1 {
+		usage()
+	}
+	in := set.Arg(0)
+	f, err := xgal.LoadSheetFont(os.DirFS(filepath.Dir(in)), filepath.Base(in))
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		*out = replaceExt(in, ".bdf")
+	}
+	if *name == "" {
+		*name = replaceExt(filepath.Base(in), "")
+	}
+	fout, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	defer fout.Close()
+	return f.WriteBDF(fout, *name)
+}
+
+// face loads a BDF or sheet font as a font.Face to render without a window.
+func face(name string) (font.Face, error) {
+	if strings.ToLower(filepath.Ext(name)) == ".font" {
+		return xgal.LoadSheetFont(os.DirFS(filepath.Dir(name)), filepath.Base(name))
+	}
+	buf, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := bdf.Parse(buf)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return parsed.NewFace(), nil
+}
+
+// render draws the lines of text in black on white with a margin.
+func render(face font.Face, text string, scale int) image.Image {
+	m := face.Metrics()
+	stride := (m.Ascent + m.Descent).Ceil()
+	lines := strings.Split(text, "\n")
+	width := 0
+	for _, line := range lines {
+		width = max(width, font.MeasureString(face, line).Ceil())
+	}
+	const margin = 4
+	img := image.NewRGBA(image.Rect(0, 0, width+2*margin, len(lines)*stride+2*margin))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+	d := font.Drawer{Dst: img, Src: image.Black, Face: face}
+	for i, line := range lines {
+		d.Dot = fixed.P(margin, margin+i*stride+m.Ascent.Ceil())
+		d.DrawString(line)
+	}
+	if scale <= 1 {
+		return img
+	}
+	big := image.NewRGBA(image.Rect(0, 0, img.Rect.Dx()*scale, img.Rect.Dy()*scale))
+	for y := range big.Rect.Dy() {
+		for x := range big.Rect.Dx() {
+			big.Set(x, y, img.At(x/scale, y/scale))
+		}
+	}
+	return big
+}
+
+// Preview shows the sample text in a window.
+type Preview struct {
+	Face  xgal.Face
+	Text  string
+	Size  xgal.Point
+	Scale int
+}
+
+func (p *Preview) Update() error {
+	if xgal.Tap(xgal.KeyEscape) {
+		return xgal.Quit
+	}
+	return nil
+}
+
+func (p *Preview) Draw(screen *xgal.Surface) {
+	xgal.Clear(screen, xgal.Paint(255, 255, 255, 255))
+	xgal.Print(screen, p.Face, xgal.Paint(0, 0, 0, 255), 4, 4, p.Text)
+}
+
+func (p *Preview) Layout(w, h int) (int, int) {
+	return p.Size.X, p.Size.Y
+}
+
+var _ xgal.Game = (*Preview)(nil)
+
+func preview(args []string) error {
+	set := flag.NewFlagSet("preview", flag.ExitOnError)
+	text := set.String("text", Sample, "sample text, \\n separates lines")
+	scale := set.Int("scale", 3, "scale of the preview")
+	out := set.String("o", "", "PNG to write instead of opening a window, for BDF and sheet fonts")
+	set.Parse(args)
+	if set.NArg() != 1 {
+		usage()
+	}
+	in := set.Arg(0)
+	sample := strings.ReplaceAll(*text, `\n`, "\n")
+	if *out != "" {
+		f, err := face(in)
+		if err != nil {
+			return err
+		}
+		fout, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer fout.Close()
+		return png.Encode(fout, render(f, sample, *scale))
+	}
+
+	f, err := xgal.Font(os.DirFS(filepath.Dir(in)), filepath.Base(in))
+	if err != nil {
+		return err
+	}
+	w, h := xgal.Measure(sample, f, float64(xgal.Stride(f)))
+	p := &Preview{Face: f, Text: sample, Size: xgal.Pt(int(w)+8, int(h)+8), Scale: *scale}
+	xgal.Screen(p.Size.X*p.Scale, p.Size.Y*p.Scale, "xfont "+filepath.Base(in))
+	return xgal.Play(p)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	var err error
+	switch os.Args[1] {
+	case "sheet":
+		err = sheet(os.Args[2:])
+	case "bdf":
+		err = toBDF(os.Args[2:])
+	case "preview":
+		err = preview(os.Args[2:])
+	default:
+		usage()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
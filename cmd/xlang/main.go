@@ -0,0 +1,152 @@
+// xlang extracts translation templates from zones and checks the string
+// tables for missing keys and font coverage.
+//
+//	xlang extract [-o template.xml] zone.xml...
+//	xlang check [-dir pack/lang] [-font file.bdf] [-zones 'pack/map/*.xml'] [lang...]
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/xmasengine/xmas/xdat"
+	"github.com/xmasengine/xmas/xgal"
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: xlang extract [-o template.xml] zone.xml...")
+	fmt.Fprintln(os.Stderr, "       xlang check [-dir pack/lang] [-font file.bdf] [-zones glob] [lang...]")
+	os.Exit(2)
+}
+
+// extractFiles extracts the talk strings of the named zone files.
+func extractFiles(names []string) ([]xdat.Entry, error) {
+	var entries []xdat.Entry
+	for _, name := range names {
+		fin, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		found, err := xdat.Extract(fin)
+		fin.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		for _, e := range found {
+			e.Note = filepath.Base(name)
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+func extract(args []string) int {
+	set := flag.NewFlagSet("extract", flag.ExitOnError)
+	out := set.String("o", "", "template file to write, standard output if empty")
+	set.Parse(args)
+	entries, err := extractFiles(set.Args())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	template := xdat.Strings{Entries: entries}
+	template.XMLName.Local = "strings"
+	if *out == "" {
+		err = template.SaveTo(os.Stdout)
+		fmt.Println()
+	} else {
+		err = template.SaveFile(*out)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+func check(args []string) int {
+	set := flag.NewFlagSet("check", flag.ExitOnError)
+	dir := set.String("dir", xdat.LangDir, "directory with the string tables")
+	font := set.String("font", "", "BDF font to check coverage with, overrides the font of the tables")
+	zones := set.String("zones", "", "glob of zone files whose talks must be translated")
+	set.Parse(args)
+
+	catalog, err := xdat.LoadCatalog(os.DirFS("."), *dir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	var talks []string
+	if *zones != "" {
+		names, err := filepath.Glob(*zones)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		entries, err := extractFiles(names)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		for _, e := range entries {
+			talks = append(talks, e.ID)
+		}
+	}
+	langs := set.Args()
+	if len(langs) == 0 {
+		langs = catalog.Langs()
+	}
+
+	status := 0
+	for _, lang := range langs {
+		table := catalog.Tables[lang]
+		if table == nil {
+			fmt.Printf("%s: no string table\n", lang)
+			status = 1
+			continue
+		}
+		if missing := catalog.Missing(lang, talks...); len(missing) > 0 {
+			fmt.Printf("%s: missing %s\n", lang, strings.Join(missing, ", "))
+			status = 1
+		}
+		name := table.Font
+		if *font != "" {
+			name = *font
+		}
+		if name == "" {
+			continue
+		}
+		buf, err := os.ReadFile(name)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		covered, err := xgal.Charset(buf)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", name, err)
+			return 1
+		}
+		if uncovered := catalog.Uncovered(lang, covered); len(uncovered) > 0 {
+			fmt.Printf("%s: %s lacks glyphs for %q\n", lang, name, string(uncovered))
+			status = 1
+		}
+	}
+	return status
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	switch os.Args[1] {
+	case "extract":
+		os.Exit(extract(os.Args[2:]))
+	case "check":
+		os.Exit(check(os.Args[2:]))
+	default:
+		usage()
+	}
+}
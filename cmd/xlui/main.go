@@ -1,13 +1,17 @@
 package main
 
 import (
+	"flag"
 	"io/fs"
 	"os"
+	"slices"
 )
 
 import (
+	"github.com/xmasengine/xmas/xdat"
 	"github.com/xmasengine/xmas/xgal"
 	"github.com/xmasengine/xmas/xlui"
+	"github.com/xmasengine/xmas/xzed"
 )
 
 const (
@@ -20,9 +24,55 @@ type App struct {
 	xlui.UI
 	FS    fs.FS
 	Image *xgal.Surface
+
+	View    *xlui.View    // View of the layout, if any.
+	Watcher *xzed.Watcher // Watcher of the layout file.
+	Preview bool          // Preview only renders, to show a theme.
+
+	Themes []*xdat.Theme // Themes to switch between with F5.
+	Theme  int           // Theme is the index of the theme, -1 for the built in looks.
+}
+
+// SwitchTheme switches to the next theme, or to the built in looks after
+// the last theme.
+func (a *App) SwitchTheme() {
+	a.Theme++
+	if a.Theme >= len(a.Themes) {
+		a.Theme = -1
+	}
+	var theme *xdat.Theme
+	if a.Theme >= 0 {
+		theme = a.Themes[a.Theme]
+		println("Theme: ", theme.Name)
+	}
+	xlui.SetTheme(theme)
+	if a.Preview {
+		// The preview looks of the dragged and active states do not
+		// survive a restyle, so build it anew.
+		a.UI = xlui.UI{}
+		Preview(&a.UI)
+		return
+	}
+	a.UI.Restyle()
 }
 
 func (a *App) Update() error {
+	if xgal.Tap(xgal.KeyF5) {
+		a.SwitchTheme()
+	}
+	if a.Preview {
+		a.UI.Tick(xgal.Tick())
+		return nil
+	}
+	if a.Watcher != nil {
+		select {
+		case <-a.Watcher.C:
+			if err := a.UI.Reload(a.View); err != nil {
+				a.UI.Complain(xgal.Bound(10, 10, WindowW-10*2, 60), err)
+			}
+		default:
+		}
+	}
 	a.UI.Poll()
 	return nil
 }
@@ -33,17 +83,70 @@ func (a *App) Draw(screen *xgal.Surface) {
 }
 
 func (a *App) Layout(w, h int) (int, int) {
+	if a.Preview {
+		return PreviewW, PreviewH
+	}
 	return WindowW, WindowH
 }
 
 var _ xgal.Game = (*App)(nil)
 
+// handle registers the handlers that the demo layout binds.
+func handle() {
+	xlui.Handle("demo.volume", func(v *xlui.View, l *xlui.Layer, ctrl *xlui.Control) xlui.Class {
+		return xlui.Class{Value: func(value int) xlui.Reply {
+			if bar := v.Control("level"); bar != nil {
+				bar.Value = value
+			}
+			return xlui.Accept
+		}}
+	})
+	xlui.Handle("demo.close", func(v *xlui.View, l *xlui.Layer, ctrl *xlui.Control) xlui.Class {
+		return xlui.Class{Click: func(at xgal.Point, button int) xlui.Reply {
+			println("Layout: ", v.Control("name").Text)
+			return xlui.Finish
+		}}
+	})
+	xlui.Handle("demo.list", func(v *xlui.View, l *xlui.Layer, ctrl *xlui.Control) xlui.Class {
+		return xlui.Class{Value: func(index int) xlui.Reply {
+			println("Layout list: ", index)
+			return xlui.Accept
+		}}
+	})
+}
+
 func main() {
 	var err error
-	app := &App{}
+	layout := flag.String("layout", "", "layout to show, reloaded when it changes, like pack/layout/demo.xml")
+	theme := flag.String("theme", "", "theme in "+xdat.ThemeDir+" to start with, F5 switches themes")
+	preview := flag.Bool("preview", false, "preview every widget in every state to check a theme")
+	flag.Parse()
+
+	app := &App{Theme: -1}
 	wd, _ := os.Getwd()
 	app.FS = os.DirFS(wd)
 
+	app.Themes, err = xdat.LoadThemes(app.FS, xdat.ThemeDir)
+	if err != nil {
+		println("Themes: ", err.Error())
+	}
+	if *theme != "" {
+		if t := xdat.FindTheme(app.Themes, *theme); t != nil {
+			xlui.SetTheme(t)
+			app.Theme = slices.Index(app.Themes, t)
+		} else {
+			println("Theme not found: ", *theme)
+		}
+	}
+
+	if *preview {
+		app.Preview = true
+		Preview(&app.UI)
+		xgal.Screen(PreviewW*PreviewScale, PreviewH*PreviewScale, "xlui theme preview")
+		xgal.Play(app)
+		return
+	}
+
 	layer := app.Layer(xgal.Bound(10, 10, WindowW-10*2, 40))
 	layer.Label("hello")
 	askButton := layer.Button("Ask")
@@ -157,6 +260,16 @@ func main() {
 		return xlui.Accept
 	}
 
+	if *layout != "" {
+		handle()
+		app.View, err = app.UI.Load(app.FS, *layout)
+		if err != nil {
+			app.UI.Complain(xgal.Bound(10, 10, WindowW-10*2, 60), err)
+		} else {
+			app.Watcher = xzed.Watch(*layout)
+		}
+	}
+
 	xgal.Screen(WindowW*WindowScale, WindowH*WindowScale, "xpix")
 	xgal.Play(app)
 }
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"github.com/xmasengine/xmas/xgal"
+	"github.com/xmasengine/xmas/xlui"
+)
+
+const (
+	PreviewW     = 640
+	PreviewH     = 480
+	PreviewScale = 2
+)
+
+// previewStates are the states the preview shows the widgets in. The
+// dragged and active states have no flag in [xlui.State], so their look
+// is applied to the styles instead.
+var previewStates = []struct {
+	Name  string
+	State xlui.State
+	Look  func(xlui.Style) xlui.Style
+}{
+	{"plain", xlui.State{}, nil},
+	{"hovered", xlui.State{Hovered: true}, nil},
+	{"focused", xlui.State{Focused: true}, nil},
+	{"clicked", xlui.State{Clicked: true}, nil},
+	{"dragged", xlui.State{}, xlui.Style.DragStyle},
+	{"active", xlui.State{}, xlui.Style.ActiveStyle},
+}
+
+// Preview adds layers to the UI for every state, with every kind of
+// control, a list and a menu in that state, to preview a theme. The UI
+// should only be rendered and ticked, since polling it would change the
+// states.
+func Preview(ui *xlui.UI) {
+	h := PreviewH / len(previewStates)
+	image := xgal.Prepare(16, 16)
+	xgal.Clear(image, xgal.Paint(200, 120, 40, 255))
+	for i, st := range previewStates {
+		y := 2 + i*h
+		layer := ui.Layer(xgal.Bound(2, y, PreviewW-140, h-4))
+		layer.Label(st.Name)
+		layer.Button("Button")
+		layer.Entry("entry")
+		layer.CheckboxWithLabel(true, "Check")
+		layer.Toggle("Toggle", nil)
+		layer.Slider(xlui.Horizontal, 0, 10, 4)
+		layer.Bar(xlui.Horizontal, 6, 10)
+		layer.Statistic("%d/%d", 6, 10)
+		layer.Area("Area\ntext", 2)
+		layer.Talk("Talk text", 2)
+		layer.Chooser(image, xgal.Pt(8, 8))
+		previewState(layer, st.State, st.Look)
+
+		list := xlui.NewList(xgal.Bound(PreviewW-136, y, 64, h-4), "alpha", "beta", "gamma")
+		list.Selected = 1
+		ui.Append(&list.Layer)
+		previewState(&list.Layer, st.State, st.Look)
+
+		menu := xlui.NewMenu(xgal.Bound(PreviewW-68, y, 0, 0), "Menu 1", "Menu 2")
+		ui.Append(menu)
+		previewState(menu, st.State, st.Look)
+
+		if st.Name == "dragged" {
+			ui.Dragged = layer
+		}
+	}
+}
+
+// previewState puts the layer and its controls in the state, and applies
+// the look to their styles if it is not nil.
+func previewState(layer *xlui.Layer, state xlui.State, look func(xlui.Style) xlui.Style) {
+	for _, ctrl := range layer.Controls {
+		ctrl.State = state
+		if look != nil {
+			ctrl.Style = look(ctrl.Style)
+		}
+	}
+	layer.State = state
+	if look != nil {
+		layer.Style = look(layer.Style)
+	}
+}
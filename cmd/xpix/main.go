@@ -3,19 +3,27 @@ package main
 import (
 	"flag"
 	"fmt"
+	"io/fs"
 	"math"
 	"os"
-	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 
+	"github.com/xmasengine/xmas/wfs"
+	"github.com/xmasengine/xmas/xdat"
 	"github.com/xmasengine/xmas/xgal"
 	"github.com/xmasengine/xmas/xui"
 )
 
 const (
+	// WindowW and WindowH are the size the window opens at. The window can
+	// be resized, down to minWidth by minHeight, and the canvas and palette
+	// panes of the dock between the toolbar and the status bar follow it.
 	WindowW    = 640
 	WindowH    = 480
+	minWidth   = 600
+	minHeight  = 300
 	ToolbarH   = 28
 	StatusH    = 20
 	PalCell    = 15
@@ -23,7 +31,6 @@ const (
 
 	toggleW    = 54
 	btnW       = 44
-	sliderW    = WindowW - 8*toggleW - 2*btnW // 640 - 432 - 88 = 120
 	numToggles = 8
 )
 
@@ -64,7 +71,13 @@ type App struct {
 	zoom int
 	offX float64
 	offY float64
-	palH int
+
+	width, height int // width and height are the size of the window.
+
+	dockFile string // dockFile is the layout file of the panes, saved with Ctrl+D.
+	dock     *xui.DockLayer
+	canvas   *panel
+	palette  *panel
 
 	sel xgal.Rectangle
 
@@ -93,7 +106,11 @@ type App struct {
 
 	showHelp bool
 
-	ask *xui.AskLayer
+	ask   xui.Widget
+	files fs.FS // files is the working directory that files are opened from and saved to.
+
+	themes []*xdat.Theme // themes are switched between with F12.
+	theme  int           // theme is the index of the theme, -1 for the built in looks.
 }
 
 func docPalette() xgal.Palette {
@@ -122,29 +139,51 @@ func newDoc(w, h int) *xgal.Paletted {
 	return img
 }
 
-func (a *App) computePalH() {
-	n := len(a.doc.Palette)
-	perRow := (WindowW) / PalCell
-	if perRow < 1 {
-		perRow = 1
-	}
-	rows := (n + perRow - 1) / perRow
-	if rows > MaxPalRows {
-		rows = MaxPalRows
+// panel is a pane of the dock that the app polls and draws itself.
+type panel struct {
+	Bounds  xgal.Rectangle
+	poll    func()
+	draw    func(screen *xgal.Surface)
+	arrange func(bounds xgal.Rectangle) // arrange lays out the panel, if needed.
+}
+
+func (p *panel) Poll() xui.Reply {
+	if p.poll != nil {
+		p.poll()
 	}
-	if rows < 1 {
-		rows = 1
+	return xui.Accept
+}
+
+func (p *panel) Render(screen *xgal.Surface) {
+	p.draw(screen)
+}
+
+func (p *panel) Place(bounds xgal.Rectangle) xgal.Rectangle {
+	p.Arrange(bounds)
+	return p.Bounds
+}
+
+func (p *panel) Arrange(bounds xgal.Rectangle) {
+	p.Bounds = bounds
+	if p.arrange != nil {
+		p.arrange(bounds)
 	}
-	a.palH = rows*PalCell + 4
 }
 
+func (a *App) dockBounds() xgal.Rectangle {
+	return xgal.Rect(0, ToolbarH, a.width, a.height-StatusH)
+}
+func (a *App) statusBounds() xgal.Rectangle {
+	return xgal.Rect(0, a.height-StatusH, a.width, a.height)
+}
+
+// computeZoom picks the largest zoom at which the image fits the canvas.
 func (a *App) computeZoom() {
 	b := a.doc.Bounds()
 	iw, ih := b.Dx(), b.Dy()
-	cvW := WindowW
-	cvH := WindowH - ToolbarH - a.palH - StatusH
-	zx := float64(cvW) / float64(iw)
-	zy := float64(cvH) / float64(ih)
+	cv := a.canvas.Bounds
+	zx := float64(cv.Dx()) / float64(iw)
+	zy := float64(cv.Dy()) / float64(ih)
 	z := zx
 	if zy < z {
 		z = zy
@@ -160,54 +199,50 @@ func (a *App) computeZoom() {
 func (a *App) recenter() {
 	b := a.doc.Bounds()
 	iw, ih := b.Dx(), b.Dy()
-	cvW := WindowW
-	cvH := WindowH - ToolbarH - a.palH - StatusH
-	a.offX = float64(cvW-iw*a.zoom) / 2
-	a.offY = float64(ToolbarH) + float64(cvH-ih*a.zoom)/2
+	cv := a.canvas.Bounds
+	a.offX = float64(cv.Min.X) + float64(cv.Dx()-iw*a.zoom)/2
+	a.offY = float64(cv.Min.Y) + float64(cv.Dy()-ih*a.zoom)/2
 }
 
 func (a *App) panToCursor(x, y int) {
-	cvW := WindowW
-	cvH := WindowH - ToolbarH - a.palH - StatusH
+	cv := a.canvas.Bounds
 	sx := float64(x)*float64(a.zoom) + a.offX
 	sy := float64(y)*float64(a.zoom) + a.offY
-	if sx < 0 {
-		a.offX -= sx
-	} else if sx+float64(a.zoom) > float64(cvW) {
-		a.offX -= (sx + float64(a.zoom)) - float64(cvW)
+	if sx < float64(cv.Min.X) {
+		a.offX -= sx - float64(cv.Min.X)
+	} else if sx+float64(a.zoom) > float64(cv.Max.X) {
+		a.offX -= (sx + float64(a.zoom)) - float64(cv.Max.X)
 	}
-	if sy < float64(ToolbarH) {
-		a.offY -= sy - float64(ToolbarH)
-	} else if sy+float64(a.zoom) > float64(cvH+ToolbarH) {
-		a.offY -= (sy + float64(a.zoom)) - float64(cvH+ToolbarH)
+	if sy < float64(cv.Min.Y) {
+		a.offY -= sy - float64(cv.Min.Y)
+	} else if sy+float64(a.zoom) > float64(cv.Max.Y) {
+		a.offY -= (sy + float64(a.zoom)) - float64(cv.Max.Y)
 	}
 }
 
-func (a *App) computeLayout() {
-	a.computePalH()
-	a.computeZoom()
-}
-
 func main() {
+	theme := flag.String("theme", xdat.DefaultTheme, "theme in "+xdat.ThemeDir+" to start with, F12 switches themes")
+	dockFile := flag.String("dock", "xpix.dock.xml", "layout file of the panes, saved with Ctrl+D")
 	flag.Parse()
 	args := flag.Args()
 
 	app := &App{
-		doc:   newDoc(64, 64),
-		tool:  ToolPencil,
-		fgIdx: 1,
-		bgIdx: 0,
-		zoom:  4,
-		palH:  2*PalCell + 4,
-		msg:   "Pencil – click to draw",
-	}
-
-	if len(args) > 0 {
-		app.filename = args[0]
-		app.loadName(app.filename)
-	}
-
-	app.computeLayout()
+		doc:      newDoc(64, 64),
+		tool:     ToolPencil,
+		fgIdx:    1,
+		bgIdx:    0,
+		zoom:     4,
+		msg:      "Pencil – click to draw",
+		files:    os.DirFS("."),
+		theme:    -1,
+		width:    WindowW,
+		height:   WindowH,
+		dockFile: *dockFile,
+	}
+	if files, err := wfs.New("."); err == nil {
+		app.files = files
+	}
+	app.loadThemes(*theme)
 
 	toggles := make([]*xui.ToggleLayer, ToolCount)
 	for i := range toggles {
@@ -236,8 +271,7 @@ func main() {
 		app.pasteClipboard()
 	})
 
-	sliderX := pasteX + btnW
-	app.sizeSl = xui.Slider(xgal.Rect(sliderX, 0, WindowW, ToolbarH), func(pos int) {
+	app.sizeSl = xui.Slider(xgal.Rectangle{}, func(pos int) {
 		app.brushSize = pos
 	})
 	app.sizeSl.Low = 1
@@ -248,10 +282,58 @@ func main() {
 	app.sizeSl.High = 10
 	app.sizeSl.Pos = 0
 
+	// The canvas keeps the image centred when its pane is resized.
+	app.canvas = &panel{draw: app.drawCanvas, arrange: func(xgal.Rectangle) { app.recenter() }}
+	app.palette = &panel{draw: app.drawPalette, poll: app.pollPalette}
+	app.dock = xui.Dock(app.dockBounds())
+	app.dock.Add("canvas", "Canvas", app.canvas, xui.SideCenter)
+	app.dock.Add("palette", "Palette", app.palette, xui.SideBottom)
+	if err := app.loadDock(); err != nil && !os.IsNotExist(err) {
+		fmt.Fprintf(os.Stderr, "error loading %s: %v\n", app.dockFile, err)
+	}
+	app.relayout()
+
+	if len(args) > 0 {
+		app.filename = args[0]
+		app.loadName(app.filename)
+	}
+	app.computeZoom()
+
 	xgal.Screen(WindowW, WindowH, "xpix")
+	xgal.Stretch(true)
 	xgal.Play(app)
 }
 
+// relayout lays the toolbar and the dock out to the size of the window.
+func (a *App) relayout() {
+	sliderX := numToggles*toggleW + 2*btnW
+	a.sizeSl.Bounds = xgal.Rect(sliderX, 0, a.width, ToolbarH)
+	a.dock.Arrange(a.dockBounds())
+}
+
+// loadDock arranges the panes as in the dock layout file.
+func (a *App) loadDock() error {
+	f, err := os.Open(a.dockFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	layout, err := xui.ReadDockLayout(f)
+	if err != nil {
+		return err
+	}
+	return a.dock.Apply(layout)
+}
+
+// saveDock saves the arrangement of the panes to the dock layout file.
+func (a *App) saveDock() {
+	if err := a.dock.Layout().SaveFile(a.dockFile); err != nil {
+		a.setMsg(fmt.Sprintf("error: %v", err))
+	} else {
+		a.setMsg(fmt.Sprintf("saved layout to %s", a.dockFile))
+	}
+}
+
 func ctrlHeld() bool {
 	for _, k := range xgal.Keys() {
 		if k == xgal.KeyControl || k == xgal.KeyControlLeft || k == xgal.KeyControlRight {
@@ -274,7 +356,7 @@ func (a *App) setTool(t Tool) {
 }
 
 func (a *App) loadName(name string) {
-	src, err := xgal.Pixels(os.DirFS("."), name)
+	src, err := xgal.Pixels(a.files, name)
 	if err != nil {
 		a.setMsg("decode: " + err.Error())
 		return
@@ -285,18 +367,17 @@ func (a *App) loadName(name string) {
 	a.sel = xgal.Rectangle{}
 	a.pasting = false
 	a.clip = nil
-	a.computeLayout()
+	a.computeZoom()
 	a.setMsg(fmt.Sprintf("loaded %s (%dx%d)", a.filename, a.doc.Bounds().Dx(), a.doc.Bounds().Dy()))
 }
 
 func (a *App) saveName(name string) string {
 	a.filename = name
-	ext := filepath.Ext(a.filename)
-	if ext != ".png" && ext != ".gif" && ext != ".jpeg" {
+	if !wfs.HasExt(a.filename, ".png", ".gif", ".jpg", ".jpeg") {
 		a.filename = a.filename + ".png"
 	}
 
-	err := xgal.Scribble(name, a.doc)
+	err := xgal.ScribbleFS(a.files, a.filename, a.doc)
 	if err != nil {
 		return "save: " + err.Error()
 	}
@@ -304,6 +385,12 @@ func (a *App) saveName(name string) string {
 	return fmt.Sprintf("saved %s", a.filename)
 }
 
+// fileDialog shows a file dialog of the images in the working directory.
+func (a *App) fileDialog(prompt, name string, save bool, chosen func(name string)) {
+	bounds := xgal.Rect(a.width/2-200, a.height/2-150, a.width/2+200, a.height/2+150)
+	a.ask = xui.FadeIn(xui.FileDialog(bounds, prompt, a.files, name, save, chosen, ".png", ".gif", ".jpg", ".jpeg"), bounds)
+}
+
 func (a *App) resize(name string) {
 	parts := strings.SplitN(name, "x", 2)
 	if len(parts) != 2 {
@@ -346,10 +433,40 @@ func (a *App) resize(name string) {
 	a.pasting = false
 	a.clip = nil
 	a.dirty = true
-	a.computeLayout()
+	a.computeZoom()
 	a.setMsg(fmt.Sprintf("resized to %dx%d", w, h))
 }
 
+// loadThemes loads the themes and uses the named one, if it is found.
+func (a *App) loadThemes(name string) {
+	themes, err := xdat.LoadThemes(a.files, xdat.ThemeDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error loading themes: %v\n", err)
+	}
+	a.themes = themes
+	if t := xdat.FindTheme(themes, name); t != nil {
+		a.theme = slices.Index(themes, t)
+		xui.SetTheme(t)
+	}
+}
+
+// switchTheme switches to the next theme, or to the built in looks after
+// the last theme, and restyles the widgets.
+func (a *App) switchTheme() {
+	a.theme++
+	if a.theme >= len(a.themes) {
+		a.theme = -1
+	}
+	var theme *xdat.Theme
+	name := "built in"
+	if a.theme >= 0 {
+		theme = a.themes[a.theme]
+		name = theme.Name
+	}
+	xui.SetTheme(theme, a.dock, a.toolGrp, a.copyBtn, a.pasteBtn, a.sizeSl)
+	a.setMsg("Theme: " + name)
+}
+
 func (a *App) setMsg(msg string) {
 	a.msg = msg
 	a.msgTimer = 180
@@ -369,6 +486,9 @@ func (a *App) statusText() string {
 }
 
 func (a *App) screenToImg(sx, sy int) (int, int, bool) {
+	if !xgal.Pt(sx, sy).In(a.canvas.Bounds) {
+		return 0, 0, false
+	}
 	ix := int((float64(sx) - a.offX) / float64(a.zoom))
 	iy := int((float64(sy) - a.offY) / float64(a.zoom))
 	b := a.doc.Bounds()
@@ -619,32 +739,25 @@ func (a *App) Update() error {
 		return nil
 	}
 
+	if xgal.Tap(xgal.KeyF12) {
+		a.switchTheme()
+	}
+
 	if xgal.Tap(xgal.KeyS) && ctrlHeld() {
 		name := a.filename
 		if name == "" {
 			name = "untitled.png"
 		}
-		dw := xui.DefaultStyle().MeasureText("Save as:").X + xui.DefaultStyle().Margin.X*4
-		if dw < 300 {
-			dw = 300
-		}
-		dh := (xui.DefaultStyle().MeasureText("X").Y*2 + xui.DefaultStyle().Margin.Y*6) * 2
-		bounds := xgal.Rect(WindowW/2-dw/2, WindowH/2-dh/2, WindowW/2+dw/2, WindowH/2+dh/2)
-		a.ask = xui.AskEntry(bounds, "Save as:", name, func(s string) {
+		a.fileDialog("Save as:", name, true, func(s string) {
 			a.setMsg(a.saveName(s))
-		}, "Save", "Cancel")
+		})
 		return nil
 	}
 	if xgal.Tap(xgal.KeyO) && ctrlHeld() {
-		dw := xui.DefaultStyle().MeasureText("Open:").X + xui.DefaultStyle().Margin.X*4
-		if dw < 300 {
-			dw = 300
-		}
-		dh := (xui.DefaultStyle().MeasureText("X").Y*2 + xui.DefaultStyle().Margin.Y*6) * 2
-		bounds := xgal.Rect(WindowW/2-dw/2, WindowH/2-dh/2, WindowW/2+dw/2, WindowH/2+dh/2)
-		a.ask = xui.AskEntry(bounds, "Open:", "", func(s string) {
+		a.fileDialog("Open:", a.filename, false, func(s string) {
+			a.filename = s
 			a.loadName(s)
-		}, "Open", "Cancel")
+		})
 		return nil
 	}
 	if xgal.Tap(xgal.KeyR) && ctrlHeld() {
@@ -654,10 +767,13 @@ func (a *App) Update() error {
 			dw = 300
 		}
 		dh := (xui.DefaultStyle().MeasureText("X").Y*2 + xui.DefaultStyle().Margin.Y*6) * 2
-		bounds := xgal.Rect(WindowW/2-dw/2, WindowH/2-dh/2, WindowW/2+dw/2, WindowH/2+dh/2)
-		a.ask = xui.AskEntry(bounds, "Resize (WxH):", cur, a.resize, "Resize", "Cancel")
+		bounds := xgal.Rect(a.width/2-dw/2, a.height/2-dh/2, a.width/2+dw/2, a.height/2+dh/2)
+		a.ask = xui.FadeIn(xui.AskEntry(bounds, "Resize (WxH):", cur, a.resize, "Resize", "Cancel"), bounds)
 		return nil
 	}
+	if xgal.Tap(xgal.KeyD) && ctrlHeld() {
+		a.saveDock()
+	}
 
 	if a.msgTimer > 0 {
 		a.msgTimer--
@@ -667,7 +783,7 @@ func (a *App) Update() error {
 	}
 
 	mx, my := xgal.Cursor().X, xgal.Cursor().Y
-	ix, iy, inBounds := a.screenToImg(mx, my)
+	ix, iy, _ := a.screenToImg(mx, my)
 	a.mx, a.my = ix, iy
 
 	// Zoom
@@ -688,9 +804,8 @@ func (a *App) Update() error {
 	}
 
 	// Pan with PageUp/PageDown/Home/End
-	cvW := WindowW
-	cvH := WindowH - ToolbarH - a.palH - StatusH
-	panStep := cvH / 2
+	cv := a.canvas.Bounds
+	panStep := cv.Dy() / 2
 	if xgal.Tap(xgal.KeyPageUp) {
 		a.offY += float64(panStep)
 	}
@@ -698,13 +813,13 @@ func (a *App) Update() error {
 		a.offY -= float64(panStep)
 	}
 	if xgal.Tap(xgal.KeyHome) {
-		a.offX = 0
-		a.offY = float64(ToolbarH)
+		a.offX = float64(cv.Min.X)
+		a.offY = float64(cv.Min.Y)
 	}
 	if xgal.Tap(xgal.KeyEnd) {
 		b := a.doc.Bounds()
-		a.offX = float64(cvW - b.Dx()*a.zoom)
-		a.offY = float64(ToolbarH + cvH - b.Dy()*a.zoom)
+		a.offX = float64(cv.Max.X - b.Dx()*a.zoom)
+		a.offY = float64(cv.Max.Y - b.Dy()*a.zoom)
 	}
 
 	// F-key tool switching (F1 is help, handled above)
@@ -753,17 +868,16 @@ func (a *App) Update() error {
 		}
 	}
 
-	// Palette input
-	if !inBounds {
-		a.pollPalette(mx, my)
-	}
-
 	// UI widgets
 	a.toolGrp.Poll()
 	a.copyBtn.Poll()
 	a.pasteBtn.Poll()
 	a.sizeSl.Poll()
 
+	// The dock polls the palette when the cursor is over it, or moves panes
+	// and splitters while they are dragged.
+	a.dock.Poll()
+
 	// Copy / Paste actions
 	if xgal.Tap(xgal.KeyC) && ctrlHeld() {
 		a.copySelection()
@@ -1177,12 +1291,14 @@ func (a *App) clearSelection() {
 	a.setMsg("cleared selection")
 }
 
-func (a *App) pollPalette(mx, my int) {
-	perRow := (WindowW) / PalCell
+func (a *App) pollPalette() {
+	mx, my := xgal.Cursor().X, xgal.Cursor().Y
+	pb := a.palette.Bounds
+	perRow := pb.Dx() / PalCell
 	if perRow < 1 {
 		perRow = 1
 	}
-	palTop := WindowH - StatusH - a.palH + 2
+	palTop := pb.Min.Y + 2
 
 	for i := 1; i < len(a.doc.Palette); i++ {
 		row := i / perRow
@@ -1190,7 +1306,7 @@ func (a *App) pollPalette(mx, my int) {
 			break
 		}
 		colIdx := i % perRow
-		px := colIdx * PalCell
+		px := pb.Min.X + colIdx*PalCell
 		py := palTop + row*PalCell
 		rect := xgal.Rect(px, py, px+PalCell, py+PalCell)
 		if my >= rect.Min.Y && my < rect.Max.Y && mx >= rect.Min.X && mx < rect.Max.X {
@@ -1207,6 +1323,39 @@ func (a *App) pollPalette(mx, my int) {
 func (a *App) Draw(screen *xgal.Surface) {
 	xgal.Clear(screen, xgal.Wash(60, 60, 60, 255))
 
+	// Canvas and palette panes
+	a.dock.Render(screen)
+
+	// Toolbar background
+	xgal.Box(screen, xgal.Rect(0, 0, a.width, ToolbarH), xgal.Wash(40, 40, 40, 255))
+	a.toolGrp.Render(screen)
+	a.copyBtn.Render(screen)
+	a.pasteBtn.Render(screen)
+
+	// Brush size slider label
+	slX := numToggles*toggleW + 2*btnW
+	xgal.Ink(screen, xgal.BuiltinFace, xgal.Wash(160, 160, 160, 255), slX, 8, fmt.Sprintf("Sz:%d", a.brushSize))
+	a.sizeSl.Render(screen)
+
+	// Status bar
+	sb := a.statusBounds()
+	xgal.Box(screen, sb, xgal.Wash(30, 30, 30, 255))
+	xgal.Ink(screen, xgal.BuiltinFace, xgal.Wash(200, 200, 200, 255), sb.Min.X+4, sb.Min.Y+4, a.statusText())
+
+	// Ask dialog
+	if a.ask != nil {
+		a.ask.Render(screen)
+	}
+
+	// Help overlay
+	if a.showHelp {
+		a.drawHelp(screen)
+	}
+}
+
+func (a *App) drawCanvas(screen *xgal.Surface) {
+	xgal.Box(screen, a.canvas.Bounds, xgal.Wash(60, 60, 60, 255))
+
 	// Draw doc image
 	if a.dirty || a.docSurf == nil {
 		a.rebuildSurface()
@@ -1357,38 +1506,11 @@ func (a *App) Draw(screen *xgal.Surface) {
 		}
 	}
 
-	// Toolbar background
-	xgal.Box(screen, xgal.Rect(0, 0, WindowW, ToolbarH), xgal.Wash(40, 40, 40, 255))
-	a.toolGrp.Render(screen)
-	a.copyBtn.Render(screen)
-	a.pasteBtn.Render(screen)
-
-	// Brush size slider label
-	slX := numToggles*toggleW + 2*btnW
-	xgal.Ink(screen, xgal.BuiltinFace, xgal.Wash(160, 160, 160, 255), slX, 8, fmt.Sprintf("Sz:%d", a.brushSize))
-	a.sizeSl.Render(screen)
-
-	// Palette area
-	a.drawPalette(screen)
-
-	// Status bar
-	xgal.Box(screen, xgal.Rect(0, WindowH-StatusH, WindowW, WindowH), xgal.Wash(30, 30, 30, 255))
-	xgal.Ink(screen, xgal.BuiltinFace, xgal.Wash(200, 200, 200, 255), 4, WindowH-StatusH+4, a.statusText())
-
-	// Ask dialog
-	if a.ask != nil {
-		a.ask.Render(screen)
-	}
-
-	// Help overlay
-	if a.showHelp {
-		drawHelp(screen)
-	}
 }
 
-func drawHelp(screen *xgal.Surface) {
+func (a *App) drawHelp(screen *xgal.Surface) {
 	// Dim background
-	xgal.Box(screen, xgal.Rect(0, 0, WindowW, WindowH), xgal.Wash(0, 0, 0, 180))
+	xgal.Box(screen, xgal.Rect(0, 0, a.width, a.height), xgal.Wash(0, 0, 0, 180))
 
 	lines := []string{
 		"  xpix – Pixel Art Editor  ",
@@ -1409,10 +1531,12 @@ func drawHelp(screen *xgal.Surface) {
 		"  Ctrl+S  Save",
 		"  Ctrl+O  Open",
 		"  Ctrl+R  Resize canvas",
+		"  Ctrl+D  Save the pane layout",
 		"",
 		"VIEW",
 		"  +/-     Zoom in/out",
 		"  0       Auto-fit zoom",
+		"  F12     Switch theme",
 		"  Q/Esc   Quit",
 	}
 
@@ -1420,13 +1544,13 @@ func drawHelp(screen *xgal.Surface) {
 	lineH := 14
 	totalH := len(lines) * lineH
 	x0 := 20
-	y0 := (WindowH-totalH)/2 - 20
+	y0 := (a.height-totalH)/2 - 20
 	if y0 < 10 {
 		y0 = 10
 	}
 
-	xgal.Box(screen, xgal.Rect(x0-10, y0-10, WindowW-x0+10, y0+totalH+10), xgal.Wash(30, 30, 30, 240))
-	xgal.Outline(screen, xgal.Rect(x0-10, y0-10, WindowW-x0+10, y0+totalH+10), 1, xgal.Wash(200, 200, 200, 255))
+	xgal.Box(screen, xgal.Rect(x0-10, y0-10, a.width-x0+10, y0+totalH+10), xgal.Wash(30, 30, 30, 240))
+	xgal.Outline(screen, xgal.Rect(x0-10, y0-10, a.width-x0+10, y0+totalH+10), 1, xgal.Wash(200, 200, 200, 255))
 
 	for i, line := range lines {
 		xgal.Ink(screen, face, xgal.Wash(220, 220, 220, 255), x0, y0+i*lineH, line)
@@ -1434,14 +1558,14 @@ func drawHelp(screen *xgal.Surface) {
 }
 
 func (a *App) drawPalette(screen *xgal.Surface) {
-	perRow := (WindowW) / PalCell
+	pb := a.palette.Bounds
+	perRow := pb.Dx() / PalCell
 	if perRow < 1 {
 		perRow = 1
 	}
-	palTop := WindowH - StatusH - a.palH + 2
+	palTop := pb.Min.Y + 2
 
-	xgal.Box(screen, xgal.Rect(0, palTop-2, WindowW, WindowH-StatusH),
-		xgal.Wash(45, 45, 45, 255))
+	xgal.Box(screen, pb, xgal.Wash(45, 45, 45, 255))
 
 	for i := 1; i < len(a.doc.Palette); i++ {
 		row := i / perRow
@@ -1449,7 +1573,7 @@ func (a *App) drawPalette(screen *xgal.Surface) {
 			break
 		}
 		colIdx := i % perRow
-		px := colIdx * PalCell
+		px := pb.Min.X + colIdx*PalCell
 		py := palTop + row*PalCell
 		palColor := xgal.Recolor(a.doc.Palette[i])
 
@@ -1467,7 +1591,12 @@ func (a *App) drawPalette(screen *xgal.Surface) {
 }
 
 func (a *App) Layout(w, h int) (int, int) {
-	return WindowW, WindowH
+	w, h = max(w, minWidth), max(h, minHeight)
+	if w != a.width || h != a.height {
+		a.width, a.height = w, h
+		a.relayout()
+	}
+	return a.width, a.height
 }
 
 var _ xgal.Game = (*App)(nil)
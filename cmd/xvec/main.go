@@ -3,66 +3,39 @@ package main
 import (
 	"flag"
 	"fmt"
+	"io/fs"
 	"math"
 	"os"
 	"slices"
 
+	"github.com/xmasengine/xmas/wfs"
+	"github.com/xmasengine/xmas/xdat"
 	"github.com/xmasengine/xmas/xgal"
 	"github.com/xmasengine/xmas/xui"
 	"github.com/xmasengine/xmas/xvec"
 )
 
 const (
+	// windowWidth and windowHeight are the size the window opens at. The
+	// window can be resized, down to minWidth by minHeight, and the panes
+	// of the dock between the toolbar and the status bar follow it.
 	windowWidth  = 640
 	windowHeight = 480
+	minWidth     = 400
+	minHeight    = 300
 
-	// Layout constants — everything is derived from these so all regions
-	// scale together when windowWidth / windowHeight change.
 	toolbarHeight = 28
-	paletteHeight = 64
 	statusHeight  = 20
 
-	sliderY0 = toolbarHeight + 2
-	sliderY1 = sliderY0 + 10
-	sliderX0 = rightPanelX0 + 8
-	sliderX1 = windowWidth - 10
-
-	askY0 = windowHeight / 4
-	askY1 = windowHeight / 2
-	askX0 = 10
-	askX1 = windowWidth - 10
-
-	rightPanelX0 = windowWidth*3/4 + 2
-	rightPanelX1 = windowWidth - 2
-	listPanelY0  = sliderY1 + 16
-	listPanelY1  = windowHeight - paletteHeight - statusHeight - 46
-
-	paletteY0    = windowHeight - paletteHeight - statusHeight
-	paletteY1    = windowHeight - statusHeight
-	paletteWidth = windowWidth * 3 / 4
-	statusY0     = paletteY1
-
-	canvasX1 = rightPanelX0 - 2
-	canvasY1 = paletteY0
+	sliderHeight = 10
+	listTop      = sliderHeight + 18 // listTop is where the list starts below the slider and its label.
 
 	helpPanelW   = 360
-	helpPanelH   = 380
-	helpPanelX0  = (windowWidth - helpPanelW) / 2
-	helpPanelX1  = helpPanelX0 + helpPanelW
-	helpPanelY0  = (windowHeight - helpPanelH) / 2
-	helpPanelY1  = helpPanelY0 + helpPanelH
-	helpTextX    = helpPanelX0 + 20
-	helpLineY0   = helpPanelY0 + 20
+	helpPanelH   = 420
 	helpLineStep = 18
 
-	messageW  = 320
-	messageH  = 36
-	messageX0 = (windowWidth - messageW) / 2
-	messageX1 = messageX0 + messageW
-	messageY0 = windowHeight - messageH - 4
-	messageY1 = windowHeight - 4
-
-	f1HintX = windowWidth - 60
+	messageW = 320
+	messageH = 36
 )
 
 type Tool int
@@ -147,10 +120,45 @@ var helpLines = []struct {
 	{"Instr. list:  Click to select", false},
 	{"Del/Backspace Delete selected shape", false},
 	{"X             Clear all shapes", false},
-	{"Ctrl+S        Save", false},
+	{"Ctrl+S        Save, Ctrl+Shift+S Save as", false},
+	{"Ctrl+L        Load", false},
+	{"Ctrl+D        Save the pane layout", false},
+	{"F12           Switch the theme", false},
+	{"Tabs:         Drag to float, drop on tabs or edges", false},
 	{"Esc           Close help", false},
 }
 
+// panel is a pane of the dock that the app polls and draws itself.
+type panel struct {
+	Bounds  xgal.Rectangle
+	poll    func()
+	draw    func(screen *xgal.Surface)
+	arrange func(bounds xgal.Rectangle) // arrange lays out the widgets in the panel, if any.
+}
+
+func (p *panel) Poll() xui.Reply {
+	if p.poll != nil {
+		p.poll()
+	}
+	return xui.Accept
+}
+
+func (p *panel) Render(screen *xgal.Surface) {
+	p.draw(screen)
+}
+
+func (p *panel) Place(bounds xgal.Rectangle) xgal.Rectangle {
+	p.Arrange(bounds)
+	return p.Bounds
+}
+
+func (p *panel) Arrange(bounds xgal.Rectangle) {
+	p.Bounds = bounds
+	if p.arrange != nil {
+		p.arrange(bounds)
+	}
+}
+
 type App struct {
 	doc      *xvec.XVEC
 	docSurf  *xgal.Surface
@@ -170,10 +178,21 @@ type App struct {
 	pend   *struct{ x, y float32 }
 	pendCP *struct{ x, y float32 }
 
+	width, height int // width and height are the size of the window.
+	dockFile      string
+	dock          *xui.DockLayer
+	canvas        *panel
+	shapes        *panel
+	palette       *panel
+
 	list      *xui.ListLayer
 	toolGroup *xui.ToggleGroupLayer
 	swSlider  *xui.SliderLayer
-	ldAsk     *xui.AskLayer
+	ldAsk     *xui.FileDialogLayer
+	files     fs.FS // files is the working directory that drawings are loaded from and saved to.
+
+	themes []*xdat.Theme // themes are switched between with F12.
+	theme  int           // theme is the index of the theme, -1 for the built in looks.
 
 	// Path editing
 	pathSteps []xvec.Stepper
@@ -188,6 +207,8 @@ type App struct {
 
 func main() {
 	file := flag.String("f", "", "xvec file to edit")
+	dockFile := flag.String("dock", "xvec.dock.xml", "layout file of the panes, saved with Ctrl+D")
+	theme := flag.String("theme", xdat.DefaultTheme, "theme in "+xdat.ThemeDir+" to start with, F12 switches themes")
 	flag.Parse()
 
 	a := &App{
@@ -198,8 +219,17 @@ func main() {
 		tool:     ToolPick,
 		selInst:  -1,
 		filename: *file,
+		dockFile: *dockFile,
 		defSW:    2,
+		width:    windowWidth,
+		height:   windowHeight,
+		files:    os.DirFS("."),
+		theme:    -1,
 	}
+	if files, err := wfs.New("."); err == nil {
+		a.files = files
+	}
+	a.loadThemes(*theme)
 
 	// Load file if specified
 	if a.filename != "" {
@@ -218,10 +248,8 @@ func main() {
 	a.color = a.palColors[63]
 	a.palSel = 63
 
-	a.list = xui.List(a.listBounds())
+	a.list = xui.List(xgal.Rectangle{})
 	a.list.Selected = -1
-	lb := a.listBounds()
-	a.list.Limit = lb.Dy() / xui.ListItemHeight
 
 	// Toolbar toggles
 	btnW := windowWidth / int(toolCount)
@@ -271,7 +299,7 @@ func main() {
 		a.pathGroup.Active = 1
 	}
 
-	a.swSlider = xui.Slider(a.sliderBounds(), func(pos int) {
+	a.swSlider = xui.Slider(xgal.Rectangle{}, func(pos int) {
 		a.defSW = float32(pos)
 		if a.selInst >= 0 && a.selInst < len(a.doc.Instructions) {
 			inst := a.doc.Instructions[a.selInst]
@@ -285,25 +313,122 @@ func main() {
 	a.swSlider.High = 20
 	a.swSlider.Pos = 2
 
+	a.canvas = &panel{draw: a.drawCanvas, poll: a.pollCanvas}
+	a.shapes = &panel{draw: a.drawShapes, poll: a.pollShapes, arrange: a.arrangeShapes}
+	a.palette = &panel{draw: a.drawPalette, poll: a.pollPalette}
+	a.dock = xui.Dock(a.dockBounds())
+	a.dock.Add("canvas", "Canvas", a.canvas, xui.SideCenter)
+	a.dock.Add("palette", "Palette", a.palette, xui.SideBottom)
+	a.dock.Add("shapes", "Shapes", a.shapes, xui.SideRight)
+	if err := a.loadDock(); err != nil && !os.IsNotExist(err) {
+		fmt.Fprintf(os.Stderr, "error loading %s: %v\n", a.dockFile, err)
+	}
+	a.relayout()
+
 	title := "xvec editor"
 	if a.filename != "" {
 		title += " — " + a.filename
 	}
 	xgal.Pointer(true, xgal.Crosshair)
 	xgal.Screen(windowWidth, windowHeight, title)
+	xgal.Stretch(true)
 	xgal.Play(a)
 }
 
-func (a *App) toolbarBounds() xgal.Rectangle { return xgal.Rect(0, 0, windowWidth, toolbarHeight) }
-func (a *App) canvasBounds() xgal.Rectangle  { return xgal.Rect(0, toolbarHeight, canvasX1, canvasY1) }
-func (a *App) listBounds() xgal.Rectangle {
-	return xgal.Rect(rightPanelX0, listPanelY0, rightPanelX1, listPanelY1)
+func (a *App) toolbarBounds() xgal.Rectangle { return xgal.Rect(0, 0, a.width, toolbarHeight) }
+func (a *App) dockBounds() xgal.Rectangle {
+	return xgal.Rect(0, toolbarHeight, a.width, a.height-statusHeight)
+}
+func (a *App) canvasBounds() xgal.Rectangle  { return a.canvas.Bounds }
+func (a *App) paletteBounds() xgal.Rectangle { return a.palette.Bounds }
+func (a *App) statusBounds() xgal.Rectangle {
+	return xgal.Rect(0, a.height-statusHeight, a.width, a.height)
 }
-func (a *App) paletteBounds() xgal.Rectangle { return xgal.Rect(0, paletteY0, paletteWidth, paletteY1) }
-func (a *App) statusBounds() xgal.Rectangle  { return xgal.Rect(0, statusY0, windowWidth, windowHeight) }
-func (a *App) askBounds() xgal.Rectangle     { return xgal.Rect(askX0, askY0, askX1, askY1) }
 func (a *App) sliderBounds() xgal.Rectangle {
-	return xgal.Rect(sliderX0, sliderY0, sliderX1, sliderY1)
+	sb := a.shapes.Bounds
+	return xgal.Rect(sb.Min.X+8, sb.Min.Y+2, sb.Max.X-8, sb.Min.Y+2+sliderHeight)
+}
+func (a *App) listBounds() xgal.Rectangle {
+	sb := a.shapes.Bounds
+	return xgal.Rect(sb.Min.X+2, sb.Min.Y+listTop, sb.Max.X-2, max(sb.Max.Y-2, sb.Min.Y+listTop))
+}
+
+// loadThemes loads the themes and uses the named one, if it is found.
+func (a *App) loadThemes(name string) {
+	themes, err := xdat.LoadThemes(a.files, xdat.ThemeDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error loading themes: %v\n", err)
+	}
+	a.themes = themes
+	if t := xdat.FindTheme(themes, name); t != nil {
+		a.theme = slices.Index(themes, t)
+		xui.SetTheme(t)
+	}
+}
+
+// switchTheme switches to the next theme, or to the built in looks after
+// the last theme, and restyles the widgets.
+func (a *App) switchTheme() {
+	a.theme++
+	if a.theme >= len(a.themes) {
+		a.theme = -1
+	}
+	var theme *xdat.Theme
+	name := "built in"
+	if a.theme >= 0 {
+		theme = a.themes[a.theme]
+		name = theme.Name
+	}
+	xui.SetTheme(theme, a.dock, a.toolGroup, a.pathGroup, a.swSlider, a.list)
+	a.msg = "Theme: " + name
+	a.msgTimer = 180
+}
+
+// relayout lays the toolbars and the dock out to the size of the window.
+func (a *App) relayout() {
+	layoutToggles(a.toolGroup, a.toolbarBounds())
+	layoutToggles(a.pathGroup, a.toolbarBounds())
+	a.dock.Arrange(a.dockBounds())
+}
+
+// layoutToggles spreads the toggles of the group evenly over bounds.
+func layoutToggles(group *xui.ToggleGroupLayer, bounds xgal.Rectangle) {
+	w := bounds.Dx() / len(group.Toggles)
+	for i, t := range group.Toggles {
+		t.Bounds = xgal.Rect(bounds.Min.X+i*w, bounds.Min.Y, bounds.Min.X+(i+1)*w, bounds.Max.Y)
+	}
+}
+
+// arrangeShapes lays out the stroke slider and the list of shapes in the
+// shapes pane.
+func (a *App) arrangeShapes(bounds xgal.Rectangle) {
+	a.swSlider.Bounds = a.sliderBounds()
+	a.list.Bounds = a.listBounds()
+	a.list.Limit = max(a.list.Bounds.Dy()/a.list.ItemHeight, 1)
+}
+
+// loadDock arranges the panes as in the dock layout file.
+func (a *App) loadDock() error {
+	f, err := os.Open(a.dockFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	layout, err := xui.ReadDockLayout(f)
+	if err != nil {
+		return err
+	}
+	return a.dock.Apply(layout)
+}
+
+// saveDock saves the arrangement of the panes to the dock layout file.
+func (a *App) saveDock() {
+	if err := a.dock.Layout().SaveFile(a.dockFile); err != nil {
+		a.msg = fmt.Sprintf("Error: %v", err)
+	} else {
+		a.msg = fmt.Sprintf("Saved layout to %s", a.dockFile)
+	}
+	a.msgTimer = 180
 }
 
 func ctrlHeld() bool {
@@ -315,6 +440,15 @@ func ctrlHeld() bool {
 	return false
 }
 
+func shiftHeld() bool {
+	for _, k := range xgal.Keys() {
+		if k == xgal.KeyShift || k == xgal.KeyShiftLeft || k == xgal.KeyShiftRight {
+			return true
+		}
+	}
+	return false
+}
+
 func (a *App) setTool(t Tool) {
 	if a.tool == t {
 		return
@@ -414,7 +548,7 @@ func (a *App) save() {
 		a.msgTimer = 180
 		fn = DefaultDrawing
 	}
-	f, err := os.Create(fn)
+	f, err := wfs.Create(a.files, fn)
 	if err != nil {
 		a.msg = fmt.Sprintf("Error saving: %v", err)
 		a.msgTimer = 180
@@ -427,13 +561,19 @@ func (a *App) save() {
 		return
 	}
 	f.Close()
+	a.filename = fn
 	a.msg = fmt.Sprintf("Saved %s", fn)
 	a.msgTimer = 180
 	a.dirty = false
 }
 
+// fileDialog shows a file dialog of the drawings in the working directory.
+func (a *App) fileDialog(prompt string, save bool, chosen func(name string)) {
+	a.ldAsk = xui.FileDialog(xgal.Rect(10, 10, a.width-10, a.height-10), prompt, a.files, a.filename, save, chosen, ".xvec")
+}
+
 func (a *App) load(path string) {
-	f, err := os.Open(path)
+	f, err := a.files.Open(path)
 	if err != nil {
 		a.msg = fmt.Sprintf("Error loading: %v", err)
 		a.msgTimer = 180
@@ -457,6 +597,14 @@ func (a *App) load(path string) {
 }
 
 func (a *App) Update() error {
+	// The file dialog takes all input while it is open.
+	if a.ldAsk != nil {
+		if a.ldAsk.Poll() == xui.Finish {
+			a.ldAsk = nil
+		}
+		return nil
+	}
+
 	// Tool hotkeys: 1–6 and F2–F7
 	for i := range int(toolCount) {
 		if xgal.Tap(toolDigits[i]) || xgal.Tap(toolFKeys[i]) {
@@ -508,15 +656,28 @@ func (a *App) Update() error {
 		}
 	}
 
-	// Save: Ctrl+S
+	// Save: Ctrl+S, or save as: Ctrl+Shift+S
 	if xgal.Tap(xgal.KeyS) && ctrlHeld() {
-		a.save()
+		if a.filename == "" || shiftHeld() {
+			a.fileDialog("Save as", true, func(fn string) {
+				a.filename = fn
+				a.save()
+			})
+		} else {
+			a.save()
+		}
+	}
+	// Save the pane layout: Ctrl+D
+	if xgal.Tap(xgal.KeyD) && ctrlHeld() {
+		a.saveDock()
+	}
+	// Switch the theme: F12
+	if xgal.Tap(xgal.KeyF12) {
+		a.switchTheme()
 	}
 	// Load: Crtl+L
 	if xgal.Tap(xgal.KeyL) && ctrlHeld() {
-		a.ldAsk = xui.AskEntry(a.askBounds(), "filename?", a.filename, func(fn string) {
-			a.load(fn)
-		}, "OK", "Cancel")
+		a.fileDialog("Load", false, a.load)
 	}
 
 	// Help toggle: F1 / Esc closes
@@ -539,22 +700,10 @@ func (a *App) Update() error {
 		a.toolGroup.Poll()
 	}
 
-	// Poll asker if visible.
-	if a.ldAsk != nil {
-		res := a.ldAsk.Poll()
-		if res == xui.Accept {
-			return nil
-		} else if res == xui.Finish {
-			a.ldAsk = nil
-			return nil
-		}
-	}
-
-	a.pollCanvas()
+	// The dock polls the pane under the cursor, or moves panes and
+	// splitters while they are dragged.
+	a.dock.Poll()
 	a.pollDrag()
-	a.pollPalette()
-	a.pollList()
-	a.swSlider.Poll()
 
 	// Message timer
 	if a.msgTimer > 0 {
@@ -737,10 +886,9 @@ func (a *App) pollPalette() {
 	}
 }
 
-func (a *App) pollList() {
-	// Clicking on the slider must not also select a list item — the slider
-	// area sits entirely inside the list bounds.
-	if xgal.Click(xgal.MouseButtonLeft) && xgal.Cursor().In(a.sliderBounds()) {
+// pollShapes polls the stroke slider and the list of shapes.
+func (a *App) pollShapes() {
+	if a.swSlider.Poll() == xui.Accept {
 		return
 	}
 	res := a.list.Poll()
@@ -872,17 +1020,10 @@ func (a *App) instLabel(i int, inst xvec.Instruction) string {
 }
 
 func (a *App) Draw(screen *xgal.Surface) {
-	xgal.Box(screen, xgal.Rect(0, 0, windowWidth, windowHeight), colBG)
+	xgal.Box(screen, xgal.Rect(0, 0, a.width, a.height), colBG)
 
 	a.drawToolbar(screen)
-	if a.tool == ToolStroke || a.tool == ToolFill {
-		a.drawPathSubToolbar(screen)
-	}
-
-	a.drawCanvas(screen)
-	a.drawList(screen)
-	a.drawStrokeSlider(screen)
-	a.drawPalette(screen)
+	a.dock.Render(screen)
 	a.drawStatus(screen)
 
 	if a.ldAsk != nil {
@@ -899,7 +1040,11 @@ func (a *App) Draw(screen *xgal.Surface) {
 func (a *App) drawToolbar(screen *xgal.Surface) {
 	tb := a.toolbarBounds()
 	xgal.Box(screen, tb, colBG)
-	a.toolGroup.Render(screen)
+	if a.tool == ToolStroke || a.tool == ToolFill {
+		a.pathGroup.Render(screen)
+	} else {
+		a.toolGroup.Render(screen)
+	}
 }
 
 func (a *App) drawCanvas(screen *xgal.Surface) {
@@ -1238,13 +1383,6 @@ func (a *App) pathPoint(idx int, offX, offY, outW, outH, docW, docH float32) *xg
 	return &xgal.Point{X: px, Y: py}
 }
 
-func (a *App) drawPathSubToolbar(screen *xgal.Surface) {
-	sb := xgal.Rect(0, toolbarHeight, windowWidth, toolbarHeight+26)
-	xgal.Box(screen, sb, colBG)
-	xgal.Outline(screen, sb, 1, colOutline)
-	a.pathGroup.Render(screen)
-}
-
 func (a *App) renderDoc() {
 	w, h := int(a.doc.Size.W), int(a.doc.Size.H)
 	if a.docSurf == nil || a.docSurf.Bounds().Dx() != w || a.docSurf.Bounds().Dy() != h {
@@ -1254,23 +1392,19 @@ func (a *App) renderDoc() {
 	a.doc.Draw(a.docSurf)
 }
 
-func (a *App) drawList(screen *xgal.Surface) {
-	lb := a.list.Bounds
-	xgal.Box(screen, lb, colBG)
-	xgal.Outline(screen, lb, 1, colOutline)
-	a.list.Render(screen)
-}
-
-func (a *App) drawStrokeSlider(screen *xgal.Surface) {
-	// Background panel matching list style
-	panel := xgal.Rect(rightPanelX0, sliderY0, rightPanelX1, sliderY1)
-	xgal.Box(screen, panel, colBG)
-	xgal.Outline(screen, panel, 1, colOutline)
+// drawShapes draws the stroke slider and the list of shapes.
+func (a *App) drawShapes(screen *xgal.Surface) {
+	xgal.Box(screen, a.shapes.Bounds, colBG)
 
+	sb := a.sliderBounds()
+	xgal.Outline(screen, xgal.Rect(a.shapes.Bounds.Min.X+2, sb.Min.Y, a.shapes.Bounds.Max.X-2, sb.Max.Y), 1, colOutline)
 	label := fmt.Sprintf("Stroke: %.0f", a.defSW)
-	xgal.Ink(screen, xgal.BuiltinFace, colText, sliderX0, sliderY1+2, label)
-
+	xgal.Ink(screen, xgal.BuiltinFace, colText, sb.Min.X, sb.Max.Y+2, label)
 	a.swSlider.Render(screen)
+
+	lb := a.list.Bounds
+	xgal.Outline(screen, lb, 1, colOutline)
+	a.list.Render(screen)
 }
 
 func (a *App) drawPalette(screen *xgal.Surface) {
@@ -1316,12 +1450,13 @@ func (a *App) drawStatus(screen *xgal.Surface) {
 	xgal.Ink(screen, xgal.BuiltinFace, colText, sb.Min.X+6, sb.Min.Y+4, text)
 
 	// F1 hint on the right side
-	xgal.Ink(screen, xgal.BuiltinFace, colTextDim, f1HintX, sb.Min.Y+4, "F1 help")
+	xgal.Ink(screen, xgal.BuiltinFace, colTextDim, sb.Max.X-60, sb.Min.Y+4, "F1 help")
 }
 
 func (a *App) drawMessage(screen *xgal.Surface) {
 	// Semi-transparent overlay across the status area
-	msgBounds := xgal.Rect(messageX0, messageY0, messageX1, messageY1)
+	x0 := (a.width - messageW) / 2
+	msgBounds := xgal.Rect(x0, a.height-messageH-4, x0+messageW, a.height-4)
 	xgal.Box(screen, msgBounds, colOverlay)
 	xgal.Outline(screen, msgBounds, 1, colOutlineMsg)
 	xgal.Ink(screen, xgal.BuiltinFace, colWhite,
@@ -1330,24 +1465,31 @@ func (a *App) drawMessage(screen *xgal.Surface) {
 
 func (a *App) drawHelpOverlay(screen *xgal.Surface) {
 	// Dim the background
-	xgal.Box(screen, xgal.Rect(0, 0, windowWidth, windowHeight), colOverlay)
+	xgal.Box(screen, xgal.Rect(0, 0, a.width, a.height), colOverlay)
 
 	// Panel
-	panel := xgal.Rect(helpPanelX0, helpPanelY0, helpPanelX1, helpPanelY1)
+	x0, y0 := (a.width-helpPanelW)/2, (a.height-helpPanelH)/2
+	panel := xgal.Rect(x0, y0, x0+helpPanelW, y0+helpPanelH)
 	xgal.Box(screen, panel, colHelpPanel)
 	xgal.Outline(screen, panel, 1, colOutlineHlp)
 
-	y := helpLineY0
+	y := y0 + 20
 	for _, ln := range helpLines {
 		col := colText
 		if ln.bold {
 			col = colWhite
 		}
-		xgal.Ink(screen, xgal.BuiltinFace, col, helpTextX, y, ln.text)
+		xgal.Ink(screen, xgal.BuiltinFace, col, x0+20, y, ln.text)
 		y += helpLineStep
 	}
 }
 
+// Layout lays the editor out anew when the window is resized.
 func (a *App) Layout(w, h int) (int, int) {
-	return windowWidth, windowHeight
+	w, h = max(w, minWidth), max(h, minHeight)
+	if w != a.width || h != a.height {
+		a.width, a.height = w, h
+		a.relayout()
+	}
+	return a.width, a.height
 }
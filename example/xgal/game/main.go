@@ -36,7 +36,7 @@ func (g *game) Draw(screen *xgal.Surface) {
 
 	// FlipH: pixel center (1.5,1.5) → 8-1.5 = 6.5 → pixel (6,1)
 	dst.Clear()
-	xgal.Blit(dst, src, xgal.Rect(0, 0, 8, 8), xgal.Rect(0, 0, 8, 8), xgal.FlipH)
+	xgal.Blit(dst, src, xgal.Rect(0, 0, 8, 8), xgal.Rect(0, 0, 8, 8), xgal.BlitOpts{FlipH: true})
 	if _, _, _, a := dst.At(6, 1).RGBA(); a == 0 {
 		fmt.Println("FAIL: FlipH")
 		g.failed = true
@@ -45,7 +45,7 @@ func (g *game) Draw(screen *xgal.Surface) {
 
 	// FlipV: (1.5,1.5) → (1.5, 6.5) → pixel (1,6)
 	dst.Clear()
-	xgal.Blit(dst, src, xgal.Rect(0, 0, 8, 8), xgal.Rect(0, 0, 8, 8), xgal.FlipV)
+	xgal.Blit(dst, src, xgal.Rect(0, 0, 8, 8), xgal.Rect(0, 0, 8, 8), xgal.BlitOpts{FlipV: true})
 	if _, _, _, a := dst.At(1, 6).RGBA(); a == 0 {
 		fmt.Println("FAIL: FlipV")
 		g.failed = true
@@ -54,13 +54,33 @@ func (g *game) Draw(screen *xgal.Surface) {
 
 	// Rot180: (1.5,1.5) → (6.5,6.5) → pixel (6,6)
 	dst.Clear()
-	xgal.Blit(dst, src, xgal.Rect(0, 0, 8, 8), xgal.Rect(0, 0, 8, 8), xgal.Rot180)
+	xgal.Blit(dst, src, xgal.Rect(0, 0, 8, 8), xgal.Rect(0, 0, 8, 8), xgal.BlitOpts{Rot: xgal.Rot180})
 	if _, _, _, a := dst.At(6, 6).RGBA(); a == 0 {
 		fmt.Println("FAIL: Rot180")
 		g.failed = true
 		return
 	}
 
+	// Rot90: (1.5,1.5) → (8-1.5, 1.5) → pixel (6,1)
+	dst.Clear()
+	xgal.Blit(dst, src, xgal.Rect(0, 0, 8, 8), xgal.Rect(0, 0, 8, 8), xgal.BlitOpts{Rot: xgal.Rot90})
+	if _, _, _, a := dst.At(6, 1).RGBA(); a == 0 {
+		fmt.Println("FAIL: Rot90")
+		g.failed = true
+		return
+	}
+
+	// Rot90 then FlipH is a transpose: pixel (1,2) → (5,1) → (2,1)
+	src.Clear()
+	src.Set(1, 2, red)
+	dst.Clear()
+	xgal.Blit(dst, src, xgal.Rect(0, 0, 8, 8), xgal.Rect(0, 0, 8, 8), xgal.BlitOpts{Rot: xgal.Rot90, FlipH: true})
+	if _, _, _, a := dst.At(2, 1).RGBA(); a == 0 {
+		fmt.Println("FAIL: Rot90 FlipH")
+		g.failed = true
+		return
+	}
+
 	// Sub-rect: blit src[2,2,6,6] into dst[0,0,4,4]
 	dst.Clear()
 	src.Set(2, 2, xgal.RGBA{G: 255, A: 255})
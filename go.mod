@@ -3,6 +3,7 @@ module github.com/xmasengine/xmas
 go 1.25.0
 
 require (
+	github.com/d4l3k/messagediff v1.2.1
 	github.com/ebitengine/microui v0.0.0-20241009125851-376dbfefa1cd
 	github.com/gen2brain/mpeg v0.6.1
 	github.com/hajimehoshi/bitmapfont/v3 v3.3.0
@@ -14,7 +15,6 @@ require (
 )
 
 require (
-	github.com/d4l3k/messagediff v1.2.1 // indirect
 	github.com/ebitengine/gomobile v0.0.0-20260211053922-3d992dae95d1 // indirect
 	github.com/ebitengine/hideconsole v1.0.0 // indirect
 	github.com/ebitengine/oto/v3 v3.4.0 // indirect
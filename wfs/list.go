@@ -0,0 +1,115 @@
+package wfs
+
+import "io/fs"
+import "path"
+import "strings"
+
+import "slices"
+import "cmp"
+
+// LayerFS is an FS that consists of layers of file systems, such as an
+// Overlay.
+type LayerFS interface {
+	// It is an fs.FS
+	fs.FS
+
+	// Layer returns the index of the layer that the named file is opened
+	// from, or -1 if it is in none of them.
+	Layer(name string) int
+}
+
+// Layer returns the index in the construction order of NewOverlay of the
+// file system that the named file is opened from, or -1 if it is in none.
+func (o Overlay) Layer(name string) int {
+	for i := len(o.systems) - 1; i >= 0; i-- {
+		if o.systems[i] == nil {
+			panic("Overlay.Layer: nil filesystem")
+		}
+		if _, err := fs.Stat(o.systems[i], name); err == nil {
+			return i
+		}
+	}
+	return -1
+}
+
+var _ LayerFS = &Overlay{}
+
+// Entry is a file or directory as listed by List.
+type Entry struct {
+	Name  string
+	Dir   bool
+	Layer int // Layer is the layer of a LayerFS the entry is in, or -1.
+}
+
+// HasExt reports whether the name has one of the extensions, such as
+// ".png", ignoring case. Every name has one of no extensions.
+func HasExt(name string, exts ...string) bool {
+	if len(exts) == 0 {
+		return true
+	}
+	ext := path.Ext(name)
+	for _, want := range exts {
+		if strings.EqualFold(ext, want) {
+			return true
+		}
+	}
+	return false
+}
+
+// List lists the named directory of fsys with the directories first and
+// then the files with one of the extensions, each sorted by name.
+// For a LayerFS, the entries have the layer they are in.
+func List(fsys fs.FS, dir string, exts ...string) ([]Entry, error) {
+	dirs, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	layers, _ := fsys.(LayerFS)
+	res := []Entry{}
+	for _, de := range dirs {
+		if de == nil || (!de.IsDir() && !HasExt(de.Name(), exts...)) {
+			continue
+		}
+		entry := Entry{Name: de.Name(), Dir: de.IsDir(), Layer: -1}
+		if layers != nil {
+			entry.Layer = layers.Layer(path.Join(dir, de.Name()))
+		}
+		res = append(res, entry)
+	}
+	slices.SortFunc(res, func(e1, e2 Entry) int {
+		if e1.Dir != e2.Dir {
+			if e1.Dir {
+				return -1
+			}
+			return 1
+		}
+		return cmp.Compare(e1.Name, e2.Name)
+	})
+	return res, nil
+}
+
+// Exists reports whether the named file or directory exists in fsys.
+func Exists(fsys fs.FS, name string) bool {
+	_, err := fs.Stat(fsys, name)
+	return err == nil
+}
+
+// Create creates the named file in fsys if it is a CreateFS, or returns
+// an error with fs.ErrPermission if it is not.
+func Create(fsys fs.FS, name string) (WriterFile, error) {
+	cfs, ok := fsys.(CreateFS)
+	if !ok {
+		return nil, &fs.PathError{Op: "create", Path: name, Err: fs.ErrPermission}
+	}
+	return cfs.Create(name)
+}
+
+// Mkdir creates the named directory in fsys if it is a MkdirFS, or
+// returns an error with fs.ErrPermission if it is not.
+func Mkdir(fsys fs.FS, name string, perm fs.FileMode) error {
+	mfs, ok := fsys.(MkdirFS)
+	if !ok {
+		return &fs.PathError{Op: "mkdir", Path: name, Err: fs.ErrPermission}
+	}
+	return mfs.Mkdir(name, perm)
+}
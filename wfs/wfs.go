@@ -211,7 +211,7 @@ func (o *Overlay) ReadDir(name string) ([]fs.DirEntry, error) {
 	slices.SortStableFunc(res, func(d1, d2 fs.DirEntry) int {
 		return cmp.Compare(d1.Name(), d2.Name())
 	})
-	slices.CompactFunc(res, func(d1, d2 fs.DirEntry) bool {
+	res = slices.CompactFunc(res, func(d1, d2 fs.DirEntry) bool {
 		return d1.Name() == d2.Name()
 	})
 	return res, nil
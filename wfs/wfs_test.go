@@ -231,3 +231,76 @@ func TestDirNames(t *testing.T) {
 }
 
 // func (o *Overlay) ReadDir(name string) ([]fs.DirEntry, error) {
+
+func TestOverlayLayer(t *testing.T) {
+	low := fstest.MapFS{
+		"both.txt":     {Data: []byte("low")},
+		"low.txt":      {Data: []byte("low")},
+		"dir/deep.png": {Data: []byte("low")},
+	}
+	high := fstest.MapFS{
+		"both.txt": {Data: []byte("high")},
+		"high.xml": {Data: []byte("high")},
+	}
+	over := NewOverlay(low, high)
+	for name, want := range map[string]int{"both.txt": 1, "low.txt": 0, "high.xml": 1, "none": -1} {
+		if got := over.Layer(name); got != want {
+			t.Errorf("Layer(%s): %d, want %d", name, got, want)
+		}
+	}
+
+	entries, err := over.ReadDir(".")
+	if err != nil {
+		t.Fatalf("ReadDir: %s", err)
+	}
+	if names := DirNames(entries...); len(names) != 4 {
+		t.Errorf("ReadDir: duplicates in %v", names)
+	}
+}
+
+func TestList(t *testing.T) {
+	low := fstest.MapFS{
+		"b.PNG":     {Data: []byte("b")},
+		"a.txt":     {Data: []byte("a")},
+		"z/one.png": {Data: []byte("z")},
+	}
+	high := fstest.MapFS{"c.png": {Data: []byte("c")}}
+	over := NewOverlay(low, high)
+
+	entries, err := List(over, ".", ".png")
+	if err != nil {
+		t.Fatalf("List: %s", err)
+	}
+	want := []Entry{{"z", true, 0}, {"b.PNG", false, 0}, {"c.png", false, 1}}
+	if len(entries) != len(want) {
+		t.Fatalf("List: %v, want %v", entries, want)
+	}
+	for i := range want {
+		if entries[i] != want[i] {
+			t.Errorf("List %d: %v, want %v", i, entries[i], want[i])
+		}
+	}
+
+	entries, err = List(low, ".")
+	if err != nil || len(entries) != 3 || entries[0].Layer != -1 {
+		t.Errorf("List all: %v %v", entries, err)
+	}
+}
+
+func TestCreateMkdir(t *testing.T) {
+	if _, err := Create(fstest.MapFS{}, "a.txt"); err == nil {
+		t.Errorf("Create in a read only FS")
+	}
+	over := helperNewOverlay(t)
+	if err := Mkdir(over, "sub", 0o700); err != nil {
+		t.Fatalf("Mkdir: %s", err)
+	}
+	out, err := Create(over, "sub/a.txt")
+	if err != nil {
+		t.Fatalf("Create: %s", err)
+	}
+	out.Close()
+	if !Exists(over, "sub/a.txt") || Exists(over, "sub/b.txt") {
+		t.Errorf("Exists is wrong")
+	}
+}
@@ -0,0 +1,595 @@
+package xdat
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+import (
+	"github.com/xmasengine/xmas/xgal"
+)
+
+// Mode is how an animation clip repeats.
+type Mode string
+
+const (
+	ModeLoop     Mode = ""         // Repeat from the first frame, also read from "loop".
+	ModePingPong Mode = "pingpong" // Play forward and then backward.
+	ModeOnce     Mode = "once"     // Stop at the last frame.
+)
+
+// Frame is a frame of an animation clip: an area of the sprite sheet shown
+// for a number of ticks. The hotspot is the point of the frame that is
+// placed where the sprite is, such as its feet, and the hit box is relative
+// to the frame, like the hotspot.
+type Frame struct {
+	X     int `xml:"x,attr"`            // X of the frame in the sheet.
+	Y     int `xml:"y,attr"`            // Y of the frame in the sheet.
+	W     int `xml:"w,attr,omitempty"`  // W is the width, the cell width if 0.
+	H     int `xml:"h,attr,omitempty"`  // H is the height, the cell height if 0.
+	Ticks int `xml:"ticks,attr"`        // Ticks is how long the frame shows.
+	OX    int `xml:"ox,attr,omitempty"` // OX is the x offset of a trimmed frame.
+	OY    int `xml:"oy,attr,omitempty"` // OY is the y offset of a trimmed frame.
+	HX    int `xml:"hx,attr,omitempty"` // HX is the x of the hotspot.
+	HY    int `xml:"hy,attr,omitempty"` // HY is the y of the hotspot.
+	BX    int `xml:"bx,attr,omitempty"` // BX is the x of the hit box.
+	BY    int `xml:"by,attr,omitempty"` // BY is the y of the hit box.
+	BW    int `xml:"bw,attr,omitempty"` // BW is the width of the hit box, none if 0.
+	BH    int `xml:"bh,attr,omitempty"` // BH is the height of the hit box.
+}
+
+// Area returns the area of the frame in the sprite sheet.
+func (f Frame) Area() xgal.Rectangle {
+	return xgal.Rect(f.X, f.Y, f.X+f.W, f.Y+f.H)
+}
+
+// Hotspot returns the hotspot of the frame.
+func (f Frame) Hotspot() xgal.Point {
+	return xgal.Pt(f.HX, f.HY)
+}
+
+// Hitbox returns the hit box of the frame.
+func (f Frame) Hitbox() xgal.Rectangle {
+	return xgal.Rect(f.BX, f.BY, f.BX+f.BW, f.BY+f.BH)
+}
+
+// Clip is an animation of an action, such as walk, facing a direction.
+// The frames may be listed one by one, or as the cells of the sheet, like
+// "0 1 2:20", with the number of ticks of a frame after a colon.
+type Clip struct {
+	Action    string    `xml:"action,attr"`          // Action of the clip, like stand or walk.
+	Dir       string    `xml:"dir,attr,omitempty"`   // Dir is the direction of the clip, any if empty.
+	Mode      Mode      `xml:"mode,attr,omitempty"`  // Mode is how the clip repeats.
+	Ticks     int       `xml:"ticks,attr,omitempty"` // Ticks is how long frames show by default.
+	Cells     string    `xml:"cells,attr,omitempty"` // Cells of the sheet to use as frames.
+	Frames    []Frame   `xml:"frame"`                // Frames of the clip.
+	Direction Direction `xml:"-"`                    // Direction parsed from Dir.
+}
+
+// Duration returns the ticks one pass through the clip takes.
+func (c *Clip) Duration() int {
+	total := 0
+	for _, f := range c.Frames {
+		total += f.Ticks
+	}
+	return total
+}
+
+// next returns the frame after frame i, whether the clip plays backward
+// then, and whether the clip is done.
+func (c *Clip) next(i int, back bool) (int, bool, bool) {
+	n := len(c.Frames)
+	switch c.Mode {
+	case ModeOnce:
+		if i+1 >= n {
+			return n - 1, false, true
+		}
+		return i + 1, false, false
+	case ModePingPong:
+		if n < 2 {
+			return 0, false, false
+		}
+		if back {
+			if i <= 0 {
+				return 1, false, false
+			}
+			return i - 1, true, false
+		}
+		if i+1 >= n {
+			return n - 2, true, false
+		}
+		return i + 1, false, false
+	default:
+		return (i + 1) % n, false, false
+	}
+}
+
+// Animations is the set of animation clips of a sprite sheet. The defaults
+// apply to frames that do not set their own.
+type Animations struct {
+	XMLName xml.Name `xml:"animations"`
+	Source  string   `xml:"src,attr,omitempty"`     // Source file name of the sprite sheet.
+	W       int      `xml:"w,attr"`                 // W is the width of the cells.
+	H       int      `xml:"h,attr"`                 // H is the height of the cells.
+	Columns int      `xml:"columns,attr,omitempty"` // Columns of cells in the sheet.
+	Ticks   int      `xml:"ticks,attr,omitempty"`   // Ticks is how long frames show by default.
+	HX      int      `xml:"hx,attr,omitempty"`      // HX is the x of the default hotspot.
+	HY      int      `xml:"hy,attr,omitempty"`      // HY is the y of the default hotspot.
+	BX      int      `xml:"bx,attr,omitempty"`      // BX is the x of the default hit box.
+	BY      int      `xml:"by,attr,omitempty"`      // BY is the y of the default hit box.
+	BW      int      `xml:"bw,attr,omitempty"`      // BW is the width of the default hit box.
+	BH      int      `xml:"bh,attr,omitempty"`      // BH is the height of the default hit box.
+	Clips   []Clip   `xml:"clip"`                   // Clips of the sheet.
+}
+
+// Find returns the clip of the action that faces the direction exactly,
+// or nil if not found.
+func (a *Animations) Find(action string, dir Direction) *Clip {
+	for i := range a.Clips {
+		if a.Clips[i].Action == action && a.Clips[i].Direction == dir {
+			return &a.Clips[i]
+		}
+	}
+	return nil
+}
+
+// Best returns the clip that fits the action and direction best: the clip
+// facing the direction, then the clip for any direction, then the first
+// clip of the action, and then the first clip. It returns nil if there
+// are no clips.
+func (a *Animations) Best(action string, dir Direction) *Clip {
+	if a == nil || len(a.Clips) == 0 {
+		return nil
+	}
+	if clip := a.Find(action, dir); clip != nil {
+		return clip
+	}
+	if clip := a.Find(action, AnyDirection); clip != nil {
+		return clip
+	}
+	for i := range a.Clips {
+		if a.Clips[i].Action == action {
+			return &a.Clips[i]
+		}
+	}
+	return &a.Clips[0]
+}
+
+// Actions returns the actions of the clips, each once.
+func (a *Animations) Actions() []string {
+	actions := []string{}
+	for _, clip := range a.Clips {
+		if !slices.Contains(actions, clip.Action) {
+			actions = append(actions, clip.Action)
+		}
+	}
+	return actions
+}
+
+// cell returns the frame of the numbered cell of the sheet.
+func (a *Animations) cell(n int) Frame {
+	return Frame{X: n % a.Columns * a.W, Y: n / a.Columns * a.H}
+}
+
+// resolve parses the directions and cells of the clips and fills in the
+// defaults of the frames.
+func (a *Animations) resolve() error {
+	for ci := range a.Clips {
+		clip := &a.Clips[ci]
+		dir, err := ParseDirection(clip.Dir)
+		if err != nil {
+			return fmt.Errorf("clip %s: %w", clip.Action, err)
+		}
+		clip.Direction = dir
+		switch clip.Mode {
+		case "loop":
+			clip.Mode = ModeLoop
+		case ModeLoop, ModePingPong, ModeOnce:
+		default:
+			return fmt.Errorf("clip %s: unknown mode %s", clip.Action, clip.Mode)
+		}
+
+		if clip.Cells != "" {
+			if a.Columns <= 0 || a.W <= 0 || a.H <= 0 {
+				return fmt.Errorf("clip %s: cells need the cell size and columns", clip.Action)
+			}
+			for _, field := range strings.Fields(clip.Cells) {
+				num, ticks, timed := strings.Cut(field, ":")
+				n, err := strconv.Atoi(num)
+				if err != nil || n < 0 {
+					return fmt.Errorf("clip %s: bad cell %q", clip.Action, field)
+				}
+				frame := a.cell(n)
+				if timed {
+					frame.Ticks, err = strconv.Atoi(ticks)
+					if err != nil {
+						return fmt.Errorf("clip %s: bad cell %q", clip.Action, field)
+					}
+				}
+				clip.Frames = append(clip.Frames, frame)
+			}
+			clip.Cells = ""
+		}
+		if len(clip.Frames) == 0 {
+			return fmt.Errorf("clip %s: no frames", clip.Action)
+		}
+
+		for fi := range clip.Frames {
+			frame := &clip.Frames[fi]
+			if frame.W == 0 {
+				frame.W = a.W
+			}
+			if frame.H == 0 {
+				frame.H = a.H
+			}
+			if frame.Ticks <= 0 {
+				frame.Ticks = cmpOr(clip.Ticks, a.Ticks, 1)
+			}
+			if frame.HX == 0 && frame.HY == 0 {
+				frame.HX, frame.HY = a.HX, a.HY
+			}
+			if frame.BW == 0 && frame.BH == 0 {
+				frame.BX, frame.BY, frame.BW, frame.BH = a.BX, a.BY, a.BW, a.BH
+			}
+		}
+	}
+	return nil
+}
+
+// cmpOr returns the first of the values that is above zero.
+func cmpOr(values ...int) int {
+	for _, v := range values {
+		if v > 0 {
+			return v
+		}
+	}
+	return 0
+}
+
+func (a Animations) SaveTo(wr io.Writer) error {
+	enc := xml.NewEncoder(wr)
+	enc.Indent("", " ")
+	return enc.Encode(a)
+}
+
+func (a Animations) SaveFile(name string) error {
+	out, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	return a.SaveTo(out)
+}
+
+// ReadAnimations reads animations in the XML format of [Animations].
+func ReadAnimations(rd io.Reader) (*Animations, error) {
+	a := &Animations{}
+	if err := xml.NewDecoder(rd).Decode(a); err != nil {
+		return nil, err
+	}
+	if err := a.resolve(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// asepriteRect is a rectangle in the JSON that Aseprite exports.
+type asepriteRect struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+type asepriteFrame struct {
+	Filename         string       `json:"filename"`
+	Frame            asepriteRect `json:"frame"`
+	Trimmed          bool         `json:"trimmed"`
+	SpriteSourceSize asepriteRect `json:"spriteSourceSize"`
+	SourceSize       struct {
+		W int `json:"w"`
+		H int `json:"h"`
+	} `json:"sourceSize"`
+	Duration int `json:"duration"`
+}
+
+type asepriteTag struct {
+	Name      string `json:"name"`
+	From      int    `json:"from"`
+	To        int    `json:"to"`
+	Direction string `json:"direction"`
+	Repeat    string `json:"repeat"`
+}
+
+type asepriteSlice struct {
+	Name string `json:"name"`
+	Keys []struct {
+		Frame  int          `json:"frame"`
+		Bounds asepriteRect `json:"bounds"`
+		Pivot  *struct {
+			X int `json:"x"`
+			Y int `json:"y"`
+		} `json:"pivot"`
+	} `json:"keys"`
+}
+
+type asepriteFile struct {
+	Frames json.RawMessage `json:"frames"`
+	Meta   struct {
+		Image     string          `json:"image"`
+		FrameTags []asepriteTag   `json:"frameTags"`
+		Slices    []asepriteSlice `json:"slices"`
+	} `json:"meta"`
+}
+
+// asepriteFrames decodes the frames, which Aseprite exports either as an
+// array or as an object keyed by file name, in the order of the file.
+func asepriteFrames(raw json.RawMessage) ([]asepriteFrame, error) {
+	var frames []asepriteFrame
+	if err := json.Unmarshal(raw, &frames); err == nil {
+		return frames, nil
+	}
+	dec := json.NewDecoder(strings.NewReader(string(raw)))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil, errors.New("frames must be an array or an object")
+	}
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		var frame asepriteFrame
+		if err := dec.Decode(&frame); err != nil {
+			return nil, err
+		}
+		frame.Filename, _ = key.(string)
+		frames = append(frames, frame)
+	}
+	return frames, nil
+}
+
+// TicksPerSecond is how many times per second animations update.
+const TicksPerSecond = 60
+
+// MillisecondsToTicks converts a frame duration in milliseconds to ticks
+// of TicksPerSecond, and is at least one tick.
+func MillisecondsToTicks(ms int) int {
+	return max(1, int(math.Round(float64(ms)*TicksPerSecond/1000)))
+}
+
+// splitTag splits a tag name like walk_south or walk-south into an action
+// and a direction. Tags without a direction are for any direction.
+func splitTag(name string) (string, Direction) {
+	cut := strings.LastIndexAny(name, "_- ")
+	if cut > 0 {
+		if dir, err := ParseDirection(name[cut+1:]); err == nil && dir != AnyDirection {
+			return name[:cut], dir
+		}
+	}
+	return name, AnyDirection
+}
+
+// TagClip returns the clip of an Aseprite tag of the frames. The tag is
+// named action_direction, such as walk_south, and its direction, one of
+// forward, reverse, pingpong and pingpong_reverse, sets the mode of the
+// clip. A tag that repeats once plays once.
+func TagClip(name, direction string, once bool, frames []Frame) Clip {
+	clip := Clip{Frames: slices.Clone(frames)}
+	clip.Action, clip.Direction = splitTag(name)
+	if clip.Direction != AnyDirection {
+		clip.Dir = clip.Direction.String()
+	}
+	switch direction {
+	case "reverse":
+		slices.Reverse(clip.Frames)
+	case "pingpong":
+		clip.Mode = ModePingPong
+	case "pingpong_reverse":
+		slices.Reverse(clip.Frames)
+		clip.Mode = ModePingPong
+	}
+	if once {
+		clip.Mode = ModeOnce
+	}
+	return clip
+}
+
+// SliceFrames applies a key of an Aseprite slice to the frames from the
+// frame of the key on, so keys must be applied in order. Slices named
+// hotspot or pivot set the hotspot, at the pivot of the slice, and slices
+// named hitbox or hit set the hit box. Other slices are ignored.
+func SliceFrames(frames []Frame, name string, from int, bounds xgal.Rectangle, pivot xgal.Point) {
+	for i := max(from, 0); i < len(frames); i++ {
+		f := &frames[i]
+		switch strings.ToLower(name) {
+		case "hotspot", "pivot":
+			f.HX, f.HY = bounds.Min.X+pivot.X, bounds.Min.Y+pivot.Y
+		case "hitbox", "hit":
+			f.BX, f.BY, f.BW, f.BH = bounds.Min.X, bounds.Min.Y, bounds.Dx(), bounds.Dy()
+		}
+	}
+}
+
+// ReadAseprite reads animations from the JSON that Aseprite exports with a
+// sprite sheet. Every tag becomes a clip, as [TagClip] makes it, and the
+// slices set the hotspots and hit boxes as [SliceFrames] does. Without tags
+// all frames make one clip named default.
+func ReadAseprite(rd io.Reader) (*Animations, error) {
+	var file asepriteFile
+	if err := json.NewDecoder(rd).Decode(&file); err != nil {
+		return nil, err
+	}
+	raw, err := asepriteFrames(file.Frames)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) == 0 {
+		return nil, errors.New("no frames")
+	}
+
+	a := &Animations{Source: file.Meta.Image}
+	a.XMLName.Local = "animations"
+	a.W, a.H = raw[0].SourceSize.W, raw[0].SourceSize.H
+	frames := make([]Frame, len(raw))
+	for i, r := range raw {
+		frames[i] = Frame{
+			X: r.Frame.X, Y: r.Frame.Y, W: r.Frame.W, H: r.Frame.H,
+			Ticks: MillisecondsToTicks(r.Duration),
+			OX:    r.SpriteSourceSize.X, OY: r.SpriteSourceSize.Y,
+		}
+	}
+	for _, slice := range file.Meta.Slices {
+		for _, key := range slice.Keys {
+			bounds := xgal.Bound(key.Bounds.X, key.Bounds.Y, key.Bounds.W, key.Bounds.H)
+			pivot := xgal.Point{}
+			if key.Pivot != nil {
+				pivot = xgal.Pt(key.Pivot.X, key.Pivot.Y)
+			}
+			SliceFrames(frames, slice.Name, key.Frame, bounds, pivot)
+		}
+	}
+	for _, tag := range file.Meta.FrameTags {
+		if tag.From < 0 || tag.To >= len(frames) || tag.From > tag.To {
+			return nil, fmt.Errorf("tag %s: frames %d to %d out of range", tag.Name, tag.From, tag.To)
+		}
+		a.Clips = append(a.Clips, TagClip(tag.Name, tag.Direction, tag.Repeat == "1", frames[tag.From:tag.To+1]))
+	}
+	if len(a.Clips) == 0 {
+		a.Clips = append(a.Clips, Clip{Action: "default", Direction: AnyDirection, Frames: frames})
+	}
+	return a, nil
+}
+
+// animationReaders are the readers of animations in other formats than
+// XML, by file extension.
+var animationReaders = map[string]func(io.Reader) (*Animations, error){
+	".json": ReadAseprite,
+}
+
+// RegisterAnimations registers a reader for animation files with the
+// extension, such as ".aseprite", for LoadAnimations.
+func RegisterAnimations(ext string, read func(io.Reader) (*Animations, error)) {
+	animationReaders[strings.ToLower(ext)] = read
+}
+
+// LoadAnimations loads animations from fsys, in a registered format by the
+// extension of the name, such as Aseprite JSON for .json, and in XML format
+// otherwise. The source of animations in another format is relative to
+// their file, and is made relative to fsys. If they have no source, their
+// file is the sprite sheet.
+func LoadAnimations(fsys fs.FS, name string) (*Animations, error) {
+	fin, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer fin.Close()
+	read, other := animationReaders[strings.ToLower(path.Ext(name))]
+	if !other {
+		read = ReadAnimations
+	}
+	a, err := read(fin)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	if other {
+		if a.Source == "" {
+			a.Source = name
+		} else {
+			a.Source = path.Join(path.Dir(name), a.Source)
+		}
+	}
+	return a, nil
+}
+
+// Animator plays the clips of a set of animations, for a player or a
+// thing. Play selects the clip, Update advances it one tick and Draw
+// draws its current frame.
+type Animator struct {
+	Set   *Animations // Set is the animations to play from.
+	Clip  *Clip       // Clip that plays, nil if none.
+	Index int         // Index of the current frame of the clip.
+	Tick  int         // Tick is how long the current frame has shown.
+	back  bool
+	done  bool
+}
+
+// Play plays the clip that fits the action and direction best. If that
+// clip already plays it continues, so Play can be called every tick.
+func (a *Animator) Play(action string, dir Direction) {
+	clip := a.Set.Best(action, dir)
+	if clip == a.Clip {
+		return
+	}
+	a.Clip = clip
+	a.Restart()
+}
+
+// Restart plays the current clip from its first frame.
+func (a *Animator) Restart() {
+	a.Index, a.Tick, a.back, a.done = 0, 0, false, false
+}
+
+// Update advances the animation by one tick.
+func (a *Animator) Update() {
+	if a.Clip == nil || len(a.Clip.Frames) == 0 || a.done {
+		return
+	}
+	a.Tick++
+	if a.Tick < a.Clip.Frames[a.Index].Ticks {
+		return
+	}
+	a.Tick = 0
+	a.Index, a.back, a.done = a.Clip.next(a.Index, a.back)
+}
+
+// Done returns whether a clip that plays once is over.
+func (a *Animator) Done() bool {
+	return a.done
+}
+
+// Current returns the current frame, and false if no clip plays.
+func (a *Animator) Current() (Frame, bool) {
+	if a.Clip == nil || a.Index >= len(a.Clip.Frames) {
+		return Frame{}, false
+	}
+	return a.Clip.Frames[a.Index], true
+}
+
+// Bounds returns where the current frame draws if its hotspot is at at.
+func (a *Animator) Bounds(at xgal.Point) xgal.Rectangle {
+	frame, ok := a.Current()
+	if !ok {
+		return xgal.Rectangle{}
+	}
+	min := at.Sub(frame.Hotspot()).Add(xgal.Pt(frame.OX, frame.OY))
+	return xgal.Rectangle{Min: min, Max: min.Add(frame.Area().Size())}
+}
+
+// Hitbox returns the hit box of the current frame if its hotspot is at at.
+func (a *Animator) Hitbox(at xgal.Point) xgal.Rectangle {
+	frame, ok := a.Current()
+	if !ok {
+		return xgal.Rectangle{}
+	}
+	return frame.Hitbox().Add(at.Sub(frame.Hotspot()))
+}
+
+// Draw draws the current frame from the sprite sheet with its hotspot at
+// at.
+func (a *Animator) Draw(dst, sheet *xgal.Surface, at xgal.Point) {
+	frame, ok := a.Current()
+	if !ok || sheet == nil {
+		return
+	}
+	xgal.Blit(dst, sheet, a.Bounds(at), frame.Area())
+}
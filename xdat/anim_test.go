@@ -0,0 +1,307 @@
+package xdat
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+import (
+	"github.com/xmasengine/xmas/xgal"
+)
+
+const testAnimations = `<animations src="hero.png" w="8" h="16" columns="4" ticks="10" hx="4" hy="15" bx="1" by="8" bw="6" bh="8">
+ <clip action="walk" dir="south" cells="0 1:5 2"/>
+ <clip action="walk" dir="east" mode="pingpong" ticks="2" cells="4 5 6"/>
+ <clip action="stand" mode="loop" cells="3"/>
+ <clip action="wave" mode="once">
+  <frame x="0" y="32" w="16" ticks="1" hx="8" hy="15"/>
+  <frame x="16" y="32" w="16" ticks="1" hx="8" hy="15" bx="0" by="0" bw="16" bh="16"/>
+ </clip>
+</animations>`
+
+func readTestAnimations(t *testing.T) *Animations {
+	t.Helper()
+	a, err := ReadAnimations(strings.NewReader(testAnimations))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func TestReadAnimations(t *testing.T) {
+	a := readTestAnimations(t)
+	walk := a.Find("walk", South)
+	if walk == nil {
+		t.Fatal("no walk south")
+	}
+	want := []Frame{
+		{X: 0, Y: 0, W: 8, H: 16, Ticks: 10, HX: 4, HY: 15, BX: 1, BY: 8, BW: 6, BH: 8},
+		{X: 8, Y: 0, W: 8, H: 16, Ticks: 5, HX: 4, HY: 15, BX: 1, BY: 8, BW: 6, BH: 8},
+		{X: 16, Y: 0, W: 8, H: 16, Ticks: 10, HX: 4, HY: 15, BX: 1, BY: 8, BW: 6, BH: 8},
+	}
+	if !slices.Equal(walk.Frames, want) {
+		t.Errorf("walk frames: %+v", walk.Frames)
+	}
+	east := a.Find("walk", East)
+	if east == nil || east.Frames[0].X != 0 || east.Frames[0].Y != 16 || east.Frames[2].Ticks != 2 {
+		t.Errorf("walk east: %+v", east)
+	}
+	wave := a.Find("wave", AnyDirection)
+	if wave == nil || wave.Frames[0].H != 16 || wave.Frames[0].BW != 6 || wave.Frames[1].BW != 16 {
+		t.Errorf("wave: %+v", wave)
+	}
+	if stand := a.Find("stand", AnyDirection); stand == nil || stand.Mode != ModeLoop {
+		t.Errorf("stand: %+v", stand)
+	}
+	if got := a.Actions(); !slices.Equal(got, []string{"walk", "stand", "wave"}) {
+		t.Errorf("actions: %v", got)
+	}
+
+	for _, bad := range []string{
+		`<animations><clip action="a" cells="0"/></animations>`,
+		`<animations w="8" h="8" columns="2"><clip action="a" cells="x"/></animations>`,
+		`<animations w="8" h="8" columns="2"><clip action="a" dir="up-ish" cells="0"/></animations>`,
+		`<animations w="8" h="8" columns="2"><clip action="a" mode="bounce" cells="0"/></animations>`,
+		`<animations><clip action="a"/></animations>`,
+	} {
+		if _, err := ReadAnimations(strings.NewReader(bad)); err == nil {
+			t.Errorf("no error for %s", bad)
+		}
+	}
+}
+
+func TestAnimationsBest(t *testing.T) {
+	a := readTestAnimations(t)
+	cases := []struct {
+		action string
+		dir    Direction
+		want   *Clip
+	}{
+		{"walk", East, &a.Clips[1]},
+		{"walk", North, &a.Clips[0]},
+		{"stand", West, &a.Clips[2]},
+		{"jump", South, &a.Clips[0]},
+	}
+	for _, c := range cases {
+		if got := a.Best(c.action, c.dir); got != c.want {
+			t.Errorf("%s %s: %+v", c.action, c.dir, got)
+		}
+	}
+	var none *Animations
+	if none.Best("walk", South) != nil {
+		t.Errorf("nil animations has a clip")
+	}
+}
+
+// frames plays ticks ticks of the clip and returns the frame index of each.
+func frames(an *Animator, ticks int) []int {
+	var got []int
+	for range ticks {
+		got = append(got, an.Index)
+		an.Update()
+	}
+	return got
+}
+
+func TestAnimatorModes(t *testing.T) {
+	a := readTestAnimations(t)
+	an := Animator{Set: a}
+
+	an.Play("walk", East)
+	if got, want := frames(&an, 12), []int{0, 0, 1, 1, 2, 2, 1, 1, 0, 0, 1, 1}; !slices.Equal(got, want) {
+		t.Errorf("pingpong: %v", got)
+	}
+
+	an.Play("walk", South)
+	got := frames(&an, 30)
+	if got[0] != 0 || got[9] != 0 || got[10] != 1 || got[14] != 1 || got[15] != 2 || got[25] != 0 {
+		t.Errorf("loop: %v", got)
+	}
+	// Playing the same clip again continues it.
+	an.Play("walk", South)
+	if an.Index != 0 || an.Tick != 5 {
+		t.Errorf("continue: %d %d", an.Index, an.Tick)
+	}
+
+	an.Play("wave", South)
+	if got, want := frames(&an, 4), []int{0, 1, 1, 1}; !slices.Equal(got, want) || !an.Done() {
+		t.Errorf("once: %v %v", got, an.Done())
+	}
+	an.Restart()
+	if an.Done() || an.Index != 0 {
+		t.Errorf("restart: %v %d", an.Done(), an.Index)
+	}
+}
+
+func TestAnimatorPlacement(t *testing.T) {
+	a := readTestAnimations(t)
+	an := Animator{Set: a}
+	if _, ok := an.Current(); ok {
+		t.Errorf("current without a clip")
+	}
+	an.Play("walk", South)
+	at := xgal.Pt(100, 50)
+	if got := an.Bounds(at); got != xgal.Rect(96, 35, 104, 51) {
+		t.Errorf("bounds: %v", got)
+	}
+	if got := an.Hitbox(at); got != xgal.Rect(97, 43, 103, 51) {
+		t.Errorf("hitbox: %v", got)
+	}
+}
+
+const testAseprite = `{ "frames": {
+   "hero 0.ase": { "frame": { "x": 0, "y": 0, "w": 6, "h": 14 }, "trimmed": true,
+    "spriteSourceSize": { "x": 1, "y": 2, "w": 6, "h": 14 }, "sourceSize": { "w": 8, "h": 16 }, "duration": 100 },
+   "hero 1.ase": { "frame": { "x": 6, "y": 0, "w": 8, "h": 16 },
+    "spriteSourceSize": { "x": 0, "y": 0, "w": 8, "h": 16 }, "sourceSize": { "w": 8, "h": 16 }, "duration": 200 },
+   "hero 2.ase": { "frame": { "x": 14, "y": 0, "w": 8, "h": 16 },
+    "spriteSourceSize": { "x": 0, "y": 0, "w": 8, "h": 16 }, "sourceSize": { "w": 8, "h": 16 }, "duration": 5 }
+ },
+ "meta": { "image": "hero.png", "size": { "w": 22, "h": 16 },
+  "frameTags": [
+   { "name": "walk_south", "from": 0, "to": 1, "direction": "forward" },
+   { "name": "walk-west", "from": 0, "to": 2, "direction": "reverse" },
+   { "name": "blink", "from": 1, "to": 2, "direction": "pingpong", "repeat": "1" }
+  ],
+  "slices": [
+   { "name": "hotspot", "keys": [ { "frame": 0, "bounds": { "x": 3, "y": 14, "w": 2, "h": 2 }, "pivot": { "x": 1, "y": 1 } } ] },
+   { "name": "hitbox", "keys": [ { "frame": 0, "bounds": { "x": 1, "y": 8, "w": 6, "h": 8 } },
+                                 { "frame": 2, "bounds": { "x": 0, "y": 0, "w": 8, "h": 16 } } ] }
+  ]
+ }
+}`
+
+func TestReadAseprite(t *testing.T) {
+	a, err := ReadAseprite(strings.NewReader(testAseprite))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Source != "hero.png" || a.W != 8 || a.H != 16 || len(a.Clips) != 3 {
+		t.Fatalf("animations: %+v", a)
+	}
+	walk := a.Find("walk", South)
+	if walk == nil || len(walk.Frames) != 2 || walk.Mode != ModeLoop {
+		t.Fatalf("walk south: %+v", walk)
+	}
+	want := Frame{X: 0, Y: 0, W: 6, H: 14, Ticks: 6, OX: 1, OY: 2, HX: 4, HY: 15, BX: 1, BY: 8, BW: 6, BH: 8}
+	if walk.Frames[0] != want {
+		t.Errorf("frame 0: %+v", walk.Frames[0])
+	}
+	if walk.Frames[1].Ticks != 12 {
+		t.Errorf("frame 1 ticks: %d", walk.Frames[1].Ticks)
+	}
+	west := a.Find("walk", West)
+	if west == nil || west.Frames[0].X != 14 || west.Frames[0].Ticks != 1 || west.Frames[0].BW != 8 {
+		t.Errorf("walk west: %+v", west)
+	}
+	blink := a.Find("blink", AnyDirection)
+	if blink == nil || blink.Mode != ModeOnce {
+		t.Errorf("blink: %+v", blink)
+	}
+
+	// A trimmed frame draws at its offset, so the hotspot stays in place.
+	an := Animator{Set: a}
+	an.Play("walk", South)
+	if got := an.Bounds(xgal.Pt(10, 20)); got != xgal.Rect(7, 7, 13, 21) {
+		t.Errorf("bounds: %v", got)
+	}
+}
+
+func TestReadAsepriteArray(t *testing.T) {
+	const array = `{ "frames": [
+	 { "filename": "a", "frame": { "x": 0, "y": 0, "w": 4, "h": 4 }, "sourceSize": { "w": 4, "h": 4 }, "duration": 50 },
+	 { "filename": "b", "frame": { "x": 4, "y": 0, "w": 4, "h": 4 }, "sourceSize": { "w": 4, "h": 4 }, "duration": 50 }
+	], "meta": { "image": "a.png" } }`
+	a, err := ReadAseprite(strings.NewReader(array))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(a.Clips) != 1 || a.Clips[0].Action != "default" || len(a.Clips[0].Frames) != 2 || a.Clips[0].Frames[1].X != 4 {
+		t.Errorf("clips: %+v", a.Clips)
+	}
+}
+
+func TestAnimationsSave(t *testing.T) {
+	a := readTestAnimations(t)
+	buf := &bytes.Buffer{}
+	if err := a.SaveTo(buf); err != nil {
+		t.Fatal(err)
+	}
+	back, err := ReadAnimations(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(back.Clips) != len(a.Clips) || !slices.Equal(back.Clips[0].Frames, a.Clips[0].Frames) {
+		t.Errorf("round trip: %+v", back.Clips)
+	}
+}
+
+func TestPackAnimations(t *testing.T) {
+	name := "pack/sprite/spri_0001.xml"
+	if _, err := os.Stat("../" + name); err != nil {
+		t.Skip(err)
+	}
+	a, err := LoadAnimations(os.DirFS(".."), name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, dir := range []Direction{North, East, South, West} {
+		for _, action := range []string{"stand", "walk"} {
+			if a.Find(action, dir) == nil {
+				t.Errorf("no %s %s", action, dir)
+			}
+		}
+	}
+}
+
+func TestPlayerWalk(t *testing.T) {
+	p := &Player{At: xgal.Pt(100, 50), Direction: South}
+	p.Animator = Animator{Set: readTestAnimations(t)}
+	p.Walk(xgal.Point{})
+	if p.Animator.Clip == nil || p.Animator.Clip.Action != "stand" {
+		t.Fatalf("standing plays %+v", p.Animator.Clip)
+	}
+	p.Walk(xgal.Pt(1, 0))
+	if p.Direction != East || p.At != xgal.Pt(101, 50) || p.Animator.Clip.Direction != East {
+		t.Errorf("walk east: %v %v", p.Direction, p.At)
+	}
+	p.Walk(xgal.Pt(0, 1))
+	if p.Direction != South || p.Bound != p.Animator.Bounds(p.At) || p.Hit != xgal.Rect(98, 44, 104, 52) {
+		t.Errorf("walk south: %v %v %v", p.Direction, p.Bound, p.Hit)
+	}
+}
+
+func TestLoadThings(t *testing.T) {
+	sheet := &bytes.Buffer{}
+	if err := png.Encode(sheet, image.NewRGBA(image.Rect(0, 0, 32, 48))); err != nil {
+		t.Fatal(err)
+	}
+	fsys := fstest.MapFS{
+		"hero.xml":   &fstest.MapFile{Data: []byte(testAnimations)},
+		"hero.png":   &fstest.MapFile{Data: sheet.Bytes()},
+		"things.xml": &fstest.MapFile{Data: []byte(`<zone name="things"><thing x="40" y="30" z="1" anim="hero.xml" action="walk" dir="east"/></zone>`)},
+	}
+	zone, err := LoadFrom(bytes.NewReader(fsys["things.xml"].Data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := zone.loadThingTextures(fsys); err != nil {
+		t.Fatal(err)
+	}
+	if len(zone.Things) != 1 {
+		t.Fatalf("things: %v", zone.Things)
+	}
+	thing := zone.Things[0]
+	if thing.Texture == nil || thing.Source != "hero.png" || thing.At() != xgal.Pt(40, 30) {
+		t.Errorf("thing: %+v", thing)
+	}
+	if clip := thing.Animator.Clip; clip == nil || clip.Action != "walk" || clip.Direction != East {
+		t.Errorf("thing plays %+v", clip)
+	}
+}
@@ -0,0 +1,266 @@
+package xdat
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"io/fs"
+	"math"
+	"os"
+	"path"
+	"strings"
+)
+
+import (
+	"github.com/xmasengine/xmas/xgal"
+	"github.com/xmasengine/xmas/xvec"
+)
+
+// Shape is the shape of the area an emitter emits particles from.
+type Shape string
+
+const (
+	ShapePoint Shape = ""     // Emit from the center.
+	ShapeBox   Shape = "box"  // Emit from inside a box.
+	ShapeDisk  Shape = "disk" // Emit from inside an ellipse.
+	ShapeRing  Shape = "ring" // Emit from the edge of an ellipse.
+	ShapeLine  Shape = "line" // Emit from a horizontal line, good for snow.
+)
+
+// Effect is a named particle effect definition. Zones refer to effects
+// by name through their emitters. The time based values are in seconds,
+// angles are in degrees clockwise from the right.
+type Effect struct {
+	Name        string  `xml:"name,attr"`                  // Name of the effect.
+	Shape       Shape   `xml:"shape,attr,omitempty"`       // Shape of the emitter.
+	Width       float64 `xml:"w,attr,omitempty"`           // Width of the emitter shape in pixels.
+	Height      float64 `xml:"h,attr,omitempty"`           // Height of the emitter shape in pixels.
+	Rate        float64 `xml:"rate,attr,omitempty"`        // Rate is the particles emitted per second.
+	Burst       int     `xml:"burst,attr,omitempty"`       // Burst is the particles emitted at once when starting.
+	Max         int     `xml:"max,attr,omitempty"`         // Max is the size of the particle pool.
+	Life        float64 `xml:"life,attr"`                  // Life time of a particle.
+	LifeJitter  float64 `xml:"lifejitter,attr,omitempty"`  // Random extra life time.
+	Speed       float64 `xml:"speed,attr,omitempty"`       // Speed in pixels per second.
+	SpeedJitter float64 `xml:"speedjitter,attr,omitempty"` // Random extra speed.
+	Angle       float64 `xml:"angle,attr,omitempty"`       // Angle of the direction of emission.
+	Spread      float64 `xml:"spread,attr,omitempty"`      // Spread of the angle to both sides.
+	GravityX    float64 `xml:"gx,attr,omitempty"`          // Gravity in pixels per second squared.
+	GravityY    float64 `xml:"gy,attr,omitempty"`          // Gravity in pixels per second squared.
+	Spin        float64 `xml:"spin,attr,omitempty"`        // Spin in degrees per second.
+	SpinJitter  float64 `xml:"spinjitter,attr,omitempty"`  // Random extra spin to both sides.
+	Scale       float64 `xml:"scale,attr,omitempty"`       // Scale of the particles at birth, 1 if 0.
+	ScaleEnd    float64 `xml:"scaleend,attr,omitempty"`    // Scale of the particles at death, Scale if 0.
+	Colors      []Color `xml:"color"`                      // Colors over the life time, white if none.
+	Source      string  `xml:"src,attr,omitempty"`         // Source image or xvec file to draw the particles with.
+	FrameWidth  int     `xml:"fw,attr,omitempty"`          // FrameWidth is the width of a frame in the source image.
+	FrameHeight int     `xml:"fh,attr,omitempty"`          // FrameHeight is the height of a frame in the source image.
+	Frames      int     `xml:"frames,attr,omitempty"`      // Frames is the amount of frames played over the life time.
+
+	Texture *xgal.Surface `xml:"-"` // The texture of the particles if loaded.
+
+	owned bool // owned is set if loadTexture made the texture, so it is not shared with an atlas.
+}
+
+// DefaultMax is the pool size of an effect that has no Max.
+const DefaultMax = 64
+
+// PoolSize returns the size of the particle pool.
+func (e *Effect) PoolSize() int {
+	if e.Max <= 0 {
+		return DefaultMax
+	}
+	return e.Max
+}
+
+// ColorAt returns the color of a particle at t, which runs from 0 at
+// birth to 1 at death, interpolated between the colors of the effect.
+func (e *Effect) ColorAt(t float64) xgal.RGBA {
+	switch len(e.Colors) {
+	case 0:
+		return xgal.White
+	case 1:
+		return e.Colors[0].RGBA()
+	}
+	t = min(max(t, 0), 1)
+	at := t * float64(len(e.Colors)-1)
+	i := min(int(at), len(e.Colors)-2)
+	f := at - float64(i)
+	a, b := e.Colors[i], e.Colors[i+1]
+	lerp := func(x, y uint8) uint8 {
+		return uint8(math.Round(float64(x) + (float64(y)-float64(x))*f))
+	}
+	return xgal.Paint(lerp(a.R, b.R), lerp(a.G, b.G), lerp(a.B, b.B), lerp(a.A, b.A))
+}
+
+// ScaleAt returns the scale of a particle at t from 0 at birth to 1 at death.
+func (e *Effect) ScaleAt(t float64) float64 {
+	start := e.Scale
+	if start == 0 {
+		start = 1
+	}
+	end := e.ScaleEnd
+	if end == 0 {
+		end = start
+	}
+	return start + (end-start)*min(max(t, 0), 1)
+}
+
+// FrameAt returns the source rectangle of the frame to draw for a
+// particle at t from 0 at birth to 1 at death, or the bounds of the
+// whole texture if the effect has no frames.
+func (e *Effect) FrameAt(t float64) xgal.Rectangle {
+	if e.Texture == nil {
+		return xgal.Rectangle{}
+	}
+	bounds := e.Texture.Bounds()
+	if e.FrameWidth <= 0 || e.FrameHeight <= 0 {
+		return bounds
+	}
+	frames := max(e.Frames, 1)
+	frame := min(int(min(max(t, 0), 1)*float64(frames)), frames-1)
+	across := max(bounds.Dx()/e.FrameWidth, 1)
+	fx := (frame % across) * e.FrameWidth
+	fy := (frame / across) * e.FrameHeight
+	return xgal.Bound(fx, fy, e.FrameWidth, e.FrameHeight)
+}
+
+func (e *Effect) loadTexture(fsys fs.FS) error {
+	if e.Source == "" {
+		return nil
+	}
+	var texture *xgal.Surface
+	owned := true
+	if strings.EqualFold(path.Ext(e.Source), ".xvec") {
+		vec, err := xvec.ParseFS(fsys, e.Source)
+		if err != nil {
+			return err
+		}
+		texture = xgal.Prepare(max(int(vec.Size.W), 1), max(int(vec.Size.H), 1))
+		vec.Draw(texture)
+	} else if sub, ok := xgal.Sprite(e.Source); ok {
+		texture, owned = sub, false
+	} else {
+		var err error
+		texture, err = xgal.Texture(fsys, e.Source)
+		if err != nil {
+			return err
+		}
+	}
+	if e.Texture != nil && e.owned {
+		e.Texture.Deallocate()
+	}
+	e.Texture, e.owned = texture, owned
+	return nil
+}
+
+// Emitter places a named effect in a zone. It emits from a fixed
+// position, or from every tile of its layer that has all of its flags.
+type Emitter struct {
+	Effect string `xml:"effect,attr"`         // Effect is the name of the effect.
+	X      int    `xml:"x,attr"`              // X position of the center in pixels.
+	Y      int    `xml:"y,attr"`              // Y position of the center in pixels.
+	Depth  int    `xml:"z,attr"`              // Depth of the layer the particles are drawn over.
+	Flag   Flag   `xml:"flag,attr,omitempty"` // Flag of the tiles to emit from instead, if not 0.
+}
+
+// At returns the position of the emitter in pixels.
+func (e Emitter) At() xgal.Point {
+	return xgal.Pt(e.X, e.Y)
+}
+
+// EmitterAt returns the index of the fixed emitter at the position at,
+// or -1 if there is none.
+func (z *Zone) EmitterAt(at xgal.Point) int {
+	for i, emitter := range z.Emitters {
+		if emitter.Flag == 0 && emitter.At() == at {
+			return i
+		}
+	}
+	return -1
+}
+
+// AddEmitter adds an emitter to the zone.
+func (z *Zone) AddEmitter(emitter Emitter) {
+	z.Emitters = append(z.Emitters, emitter)
+}
+
+// RemoveEmitter removes the emitter at index i from the zone.
+func (z *Zone) RemoveEmitter(i int) {
+	if i < 0 || i >= len(z.Emitters) {
+		return
+	}
+	z.Emitters = append(z.Emitters[:i], z.Emitters[i+1:]...)
+}
+
+// Effects is a library of named effects.
+type Effects struct {
+	XMLName xml.Name  `xml:"effects"`
+	Effects []*Effect `xml:"effect"`
+}
+
+// Find returns the effect with the given name, or nil if not found.
+func (e *Effects) Find(name string) *Effect {
+	for _, effect := range e.Effects {
+		if effect.Name == name {
+			return effect
+		}
+	}
+	return nil
+}
+
+// Names returns the names of all effects.
+func (e *Effects) Names() []string {
+	names := []string{}
+	for _, effect := range e.Effects {
+		names = append(names, effect.Name)
+	}
+	return names
+}
+
+func (e Effects) SaveTo(wr io.Writer) error {
+	enc := xml.NewEncoder(wr)
+	enc.Indent("", " ")
+	return enc.Encode(e)
+}
+
+func (e Effects) SaveFile(name string) error {
+	out, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	return e.SaveTo(out)
+}
+
+func loadEffectsFrom(rd io.Reader) (*Effects, error) {
+	dec := xml.NewDecoder(rd)
+	var effects Effects
+	err := dec.Decode(&effects)
+	return &effects, err
+}
+
+func (e *Effects) loadTextures(fsys fs.FS) error {
+	var errs []error
+	for _, effect := range e.Effects {
+		err := effect.loadTexture(fsys)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// LoadEffects loads an effect library and the textures of its effects.
+func LoadEffects(fsys fs.FS, name string) (*Effects, error) {
+	fin, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer fin.Close()
+	effects, err := loadEffectsFrom(fin)
+	if err != nil {
+		return nil, err
+	}
+	err = effects.loadTextures(fsys)
+	return effects, err
+}
@@ -0,0 +1,113 @@
+package xdat
+
+import "testing"
+import "testing/fstest"
+
+import "github.com/xmasengine/xmas/xgal"
+
+func TestEffectColorAt(t *testing.T) {
+	effect := Effect{Colors: []Color{
+		Color(xgal.Tint(0, 0, 0)),
+		Color(xgal.Tint(200, 100, 0)),
+		Color(xgal.Paint(200, 100, 0, 0)),
+	}}
+	cases := []struct {
+		t      float64
+		expect xgal.RGBA
+	}{
+		{-1, xgal.Tint(0, 0, 0)},
+		{0, xgal.Tint(0, 0, 0)},
+		{0.25, xgal.Tint(100, 50, 0)},
+		{0.5, xgal.Tint(200, 100, 0)},
+		{0.75, xgal.Paint(200, 100, 0, 128)},
+		{1, xgal.Paint(200, 100, 0, 0)},
+		{2, xgal.Paint(200, 100, 0, 0)},
+	}
+	for _, c := range cases {
+		if observe := effect.ColorAt(c.t); observe != c.expect {
+			t.Errorf("%f: %v != %v", c.t, observe, c.expect)
+		}
+	}
+	if observe := (&Effect{}).ColorAt(0.5); observe != xgal.White {
+		t.Errorf("no colors: %v", observe)
+	}
+}
+
+func TestEffectScaleAt(t *testing.T) {
+	cases := []struct {
+		effect Effect
+		t      float64
+		expect float64
+	}{
+		{Effect{}, 0.5, 1},
+		{Effect{Scale: 2}, 0.5, 2},
+		{Effect{Scale: 1, ScaleEnd: 3}, 0.5, 2},
+		{Effect{ScaleEnd: 0.5}, 1, 0.5},
+	}
+	for i, c := range cases {
+		if observe := c.effect.ScaleAt(c.t); observe != c.expect {
+			t.Errorf("%d: %f != %f", i, observe, c.expect)
+		}
+	}
+}
+
+func TestLoadEffects(t *testing.T) {
+	fsys := fstest.MapFS{"fx.xml": &fstest.MapFile{Data: []byte(`<effects>
+ <effect name="snow" shape="line" w="320" rate="20" life="8" gy="4">
+  <color>#ffffff</color>
+  <color>#ffffff00</color>
+ </effect>
+ <effect name="hit" burst="16" max="16" life="0.4"/>
+</effects>`)}}
+	effects, err := LoadEffects(fsys, "fx.xml")
+	if err != nil {
+		t.Fatalf("load: %s", err)
+	}
+	if names := effects.Names(); len(names) != 2 || names[0] != "snow" || names[1] != "hit" {
+		t.Fatalf("names: %v", names)
+	}
+	snow := effects.Find("snow")
+	if snow.Shape != ShapeLine || snow.Width != 320 || snow.GravityY != 4 || len(snow.Colors) != 2 {
+		t.Errorf("snow: %+v", snow)
+	}
+	if snow.PoolSize() != DefaultMax || effects.Find("hit").PoolSize() != 16 {
+		t.Errorf("pool size")
+	}
+	if effects.Find("rain") != nil {
+		t.Errorf("found missing effect")
+	}
+}
+
+func TestEmitterAt(t *testing.T) {
+	zone := NewZone("church")
+	zone.AddEmitter(Emitter{Effect: "smoke", X: 4, Y: 4})
+	zone.AddEmitter(Emitter{Effect: "sparkle", Flag: FlagBless})
+	if observe := zone.EmitterAt(xgal.Pt(4, 4)); observe != 0 {
+		t.Errorf("emitter at: %d", observe)
+	}
+	if observe := zone.EmitterAt(xgal.Pt(0, 0)); observe != -1 {
+		t.Errorf("flag emitter found: %d", observe)
+	}
+	zone.RemoveEmitter(0)
+	if len(zone.Emitters) != 1 || zone.Emitters[0].Effect != "sparkle" {
+		t.Errorf("remove: %v", zone.Emitters)
+	}
+}
+
+func TestEffectTextureFromAtlas(t *testing.T) {
+	atlas := &xgal.Atlas{Surface: xgal.Prepare(16, 16), Regions: map[string]xgal.Region{
+		"fx/spark.png": {Area: xgal.Rect(0, 0, 4, 4), Size: xgal.Pt(4, 4)},
+	}}
+	xgal.AddAtlas(atlas)
+	defer xgal.RemoveAtlas(atlas)
+
+	effect := Effect{Source: "fx/spark.png"}
+	for range 2 {
+		if err := effect.loadTexture(fstest.MapFS{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if sub, _ := xgal.Sprite("fx/spark.png"); effect.Texture != sub || effect.owned {
+		t.Errorf("texture from the atlas owned by the effect")
+	}
+}
@@ -0,0 +1,397 @@
+package xdat
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// LangDir is the directory with the string tables.
+const LangDir = "pack/lang"
+
+// DefaultLang is the language that is used when all fallbacks fail.
+const DefaultLang = "en"
+
+// Plural is a plural form of a translated string.
+type Plural struct {
+	Form string `xml:"form,attr"` // Form is zero, one, two, few, many or other.
+	Text string `xml:",chardata"` // Text of the form.
+}
+
+// Entry is a translated string of a string table. The text may contain
+// {0} to {9} for the arguments and {n} for the count of plurals, next to
+// the markup of xgal.Typeset.
+type Entry struct {
+	ID      string   `xml:"id,attr"`             // ID is the key of the string.
+	Note    string   `xml:"note,attr,omitempty"` // Note for translators.
+	Text    string   `xml:",chardata"`           // Text of the string.
+	Plurals []Plural `xml:"plural"`              // Plurals are the plural forms, if any.
+}
+
+// Pick returns the text of the plural form, falling back to the other
+// form and then to the text.
+func (e Entry) Pick(form string) string {
+	other := ""
+	for _, p := range e.Plurals {
+		if p.Form == form {
+			return strings.TrimSpace(p.Text)
+		}
+		if p.Form == "other" {
+			other = p.Text
+		}
+	}
+	if other != "" {
+		return strings.TrimSpace(other)
+	}
+	return strings.TrimSpace(e.Text)
+}
+
+// Strings is the string table of a language.
+type Strings struct {
+	XMLName  xml.Name `xml:"strings"`
+	Lang     string   `xml:"lang,attr"`               // Lang is the language tag, such as en or pt-BR.
+	Fallback string   `xml:"fallback,attr,omitempty"` // Fallback is the language for missing strings.
+	Font     string   `xml:"font,attr,omitempty"`     // Font is the BDF font the language needs.
+	Entries  []Entry  `xml:"string"`
+}
+
+// Find returns the entry with the id, or nil if there is none.
+func (s *Strings) Find(id string) *Entry {
+	for i := range s.Entries {
+		if s.Entries[i].ID == id {
+			return &s.Entries[i]
+		}
+	}
+	return nil
+}
+
+// Runes returns the distinct runes used in the table, sorted.
+func (s *Strings) Runes() []rune {
+	seen := map[rune]bool{}
+	add := func(text string) {
+		for _, r := range text {
+			if r > ' ' {
+				seen[r] = true
+			}
+		}
+	}
+	for _, e := range s.Entries {
+		add(e.Text)
+		for _, p := range e.Plurals {
+			add(p.Text)
+		}
+	}
+	runes := make([]rune, 0, len(seen))
+	for r := range seen {
+		runes = append(runes, r)
+	}
+	slices.Sort(runes)
+	return runes
+}
+
+func (s Strings) SaveTo(wr io.Writer) error {
+	enc := xml.NewEncoder(wr)
+	enc.Indent("", " ")
+	return enc.Encode(s)
+}
+
+func (s Strings) SaveFile(name string) error {
+	out, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	return s.SaveTo(out)
+}
+
+// LoadStrings loads a string table from fsys.
+func LoadStrings(fsys fs.FS, name string) (*Strings, error) {
+	fin, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer fin.Close()
+	s := &Strings{}
+	if err := xml.NewDecoder(fin).Decode(s); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return s, nil
+}
+
+// PluralForm returns the plural form of the count n in a language.
+func PluralForm(lang string, n int) string {
+	base, _, _ := strings.Cut(lang, "-")
+	n = max(n, -n)
+	mod10, mod100 := n%10, n%100
+	switch base {
+	case "ja", "ko", "zh", "th", "vi", "id":
+		return "other"
+	case "fr":
+		if n <= 1 {
+			return "one"
+		}
+	case "ru", "uk", "be", "sr", "hr", "bs":
+		switch {
+		case mod10 == 1 && mod100 != 11:
+			return "one"
+		case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+			return "few"
+		default:
+			return "many"
+		}
+	case "pl":
+		switch {
+		case n == 1:
+			return "one"
+		case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+			return "few"
+		default:
+			return "many"
+		}
+	case "cs", "sk":
+		switch {
+		case n == 1:
+			return "one"
+		case n >= 2 && n <= 4:
+			return "few"
+		}
+	default:
+		if n == 1 {
+			return "one"
+		}
+	}
+	return "other"
+}
+
+// Format replaces {0} to {9} in text by the arguments and {n} by n. Other
+// braces are kept, so markup passes through.
+func Format(text string, n int, args ...any) string {
+	if !strings.Contains(text, "{") {
+		return text
+	}
+	out := strings.Builder{}
+	for i := 0; i < len(text); i++ {
+		if text[i] == '{' && i+2 < len(text) && text[i+2] == '}' {
+			c := text[i+1]
+			switch {
+			case c == 'n':
+				out.WriteString(strconv.Itoa(n))
+				i += 2
+				continue
+			case c >= '0' && c <= '9' && int(c-'0') < len(args):
+				fmt.Fprint(&out, args[c-'0'])
+				i += 2
+				continue
+			}
+		}
+		out.WriteByte(text[i])
+	}
+	return out.String()
+}
+
+// Catalog holds the string tables of all languages and translates to the
+// current language.
+type Catalog struct {
+	Tables  map[string]*Strings // Tables by language.
+	Lang    string              // Lang is the current language.
+	Default string              // Default is the last fallback language.
+	changed []func(lang string)
+}
+
+// NewCatalog returns a catalog of the tables, set to the default language.
+func NewCatalog(tables ...*Strings) *Catalog {
+	c := &Catalog{Tables: map[string]*Strings{}, Lang: DefaultLang, Default: DefaultLang}
+	for _, t := range tables {
+		c.Tables[t.Lang] = t
+	}
+	return c
+}
+
+// LoadCatalog loads all string tables in dir of fsys. Files without a
+// language, such as templates, are skipped.
+func LoadCatalog(fsys fs.FS, dir string) (*Catalog, error) {
+	names, err := fs.Glob(fsys, path.Join(dir, "*.xml"))
+	if err != nil {
+		return nil, err
+	}
+	c := NewCatalog()
+	for _, name := range names {
+		t, err := LoadStrings(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		if t.Lang != "" {
+			c.Tables[t.Lang] = t
+		}
+	}
+	return c, nil
+}
+
+// Langs returns the languages of the catalog, sorted.
+func (c *Catalog) Langs() []string {
+	langs := []string{}
+	for lang := range c.Tables {
+		langs = append(langs, lang)
+	}
+	slices.Sort(langs)
+	return langs
+}
+
+// Chain returns the tables to look up strings of a language in: the
+// language itself, its fallbacks, the base language of a regional one
+// such as pt for pt-BR, and finally the default language.
+func (c *Catalog) Chain(lang string) []*Strings {
+	var chain []*Strings
+	seen := map[string]bool{}
+	var walk func(lang string)
+	walk = func(lang string) {
+		if lang == "" || seen[lang] {
+			return
+		}
+		seen[lang] = true
+		t := c.Tables[lang]
+		if t != nil {
+			chain = append(chain, t)
+			walk(t.Fallback)
+		}
+		if base, _, ok := strings.Cut(lang, "-"); ok {
+			walk(base)
+		}
+	}
+	walk(lang)
+	walk(c.Default)
+	return chain
+}
+
+// find returns the entry with the id in the current language or its
+// fallbacks, together with the language it was found in.
+func (c *Catalog) find(id string) (*Entry, string) {
+	if c == nil {
+		return nil, ""
+	}
+	for _, t := range c.Chain(c.Lang) {
+		if e := t.Find(id); e != nil {
+			return e, t.Lang
+		}
+	}
+	return nil, ""
+}
+
+// Lookup returns the formatted text with the id in the current language,
+// and false if no table has it.
+func (c *Catalog) Lookup(id string, args ...any) (string, bool) {
+	e, _ := c.find(id)
+	if e == nil {
+		return id, false
+	}
+	return Format(strings.TrimSpace(e.Text), 0, args...), true
+}
+
+// Text returns the formatted text with the id in the current language.
+// The id itself is returned if no table has it, so missing strings show.
+func (c *Catalog) Text(id string, args ...any) string {
+	text, _ := c.Lookup(id, args...)
+	return text
+}
+
+// Plural returns the formatted plural form for the count n of the text
+// with the id in the current language.
+func (c *Catalog) Plural(id string, n int, args ...any) string {
+	e, lang := c.find(id)
+	if e == nil {
+		return id
+	}
+	return Format(e.Pick(PluralForm(lang, n)), n, args...)
+}
+
+// SetLang switches to another language and notifies the listeners.
+func (c *Catalog) SetLang(lang string) error {
+	if c.Tables[lang] == nil {
+		return errors.New("no strings for language " + lang)
+	}
+	c.Lang = lang
+	for _, changed := range c.changed {
+		changed(lang)
+	}
+	return nil
+}
+
+// OnChange adds a listener that is called when the language changes,
+// for example to lay out the text of the UI again.
+func (c *Catalog) OnChange(changed func(lang string)) {
+	c.changed = append(c.changed, changed)
+}
+
+// Missing returns the ids of the default language and of the extra ids
+// that the table of lang itself does not have, sorted.
+func (c *Catalog) Missing(lang string, extra ...string) []string {
+	want := slices.Clone(extra)
+	if def := c.Tables[c.Default]; def != nil {
+		for _, e := range def.Entries {
+			want = append(want, e.ID)
+		}
+	}
+	table := c.Tables[lang]
+	var missing []string
+	for _, id := range want {
+		if (table == nil || table.Find(id) == nil) && !slices.Contains(missing, id) {
+			missing = append(missing, id)
+		}
+	}
+	slices.Sort(missing)
+	return missing
+}
+
+// Uncovered returns the runes of the table of lang that are not covered,
+// for example by the glyphs of a font.
+func (c *Catalog) Uncovered(lang string, covered map[rune]bool) []rune {
+	table := c.Tables[lang]
+	if table == nil {
+		return nil
+	}
+	var out []rune
+	for _, r := range table.Runes() {
+		if !covered[r] {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// TalkKey returns the key of the nth text of the named talk.
+func TalkKey(talk string, n int) string {
+	return "talk." + talk + "." + strconv.Itoa(n)
+}
+
+// Extract scans zone XML for its talks, and returns the texts of their
+// says, asks and replies as the [Talk.Entries] of the talks, in the order
+// of the document.
+func Extract(rd io.Reader) ([]Entry, error) {
+	dec := xml.NewDecoder(rd)
+	var entries []Entry
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return entries, err
+		}
+		if el, ok := tok.(xml.StartElement); ok && el.Name.Local == "talk" {
+			var talk Talk
+			if err := dec.DecodeElement(&talk, &el); err != nil {
+				return entries, err
+			}
+			if talk.Name != "" {
+				entries = append(entries, talk.Entries()...)
+			}
+		}
+	}
+}
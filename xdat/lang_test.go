@@ -0,0 +1,258 @@
+package xdat
+
+import (
+	"encoding/xml"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func testCatalog() *Catalog {
+	en := &Strings{Lang: "en", Entries: []Entry{
+		{ID: "hello", Text: "Hello, {0}!"},
+		{ID: "bye", Text: "Bye"},
+		{ID: "gifts", Plurals: []Plural{{"one", "{n} gift"}, {"other", "{n} gifts"}}},
+	}}
+	pt := &Strings{Lang: "pt", Entries: []Entry{
+		{ID: "hello", Text: "Olá, {0}!"},
+	}}
+	br := &Strings{Lang: "pt-BR", Entries: []Entry{
+		{ID: "bye", Text: "Tchau"},
+	}}
+	ru := &Strings{Lang: "ru", Fallback: "uk", Entries: []Entry{
+		{ID: "gifts", Plurals: []Plural{{"one", "{n} подарок"}, {"few", "{n} подарка"}, {"many", "{n} подарков"}}},
+	}}
+	uk := &Strings{Lang: "uk", Fallback: "ru", Entries: []Entry{
+		{ID: "bye", Text: "Бувай"},
+	}}
+	return NewCatalog(en, pt, br, ru, uk)
+}
+
+func TestPluralForm(t *testing.T) {
+	cases := []struct {
+		lang string
+		n    int
+		want string
+	}{
+		{"en", 1, "one"}, {"en", 0, "other"}, {"en", 2, "other"},
+		{"fr", 0, "one"}, {"fr", 2, "other"},
+		{"ja", 1, "other"},
+		{"ru", 1, "one"}, {"ru", 3, "few"}, {"ru", 5, "many"}, {"ru", 11, "many"}, {"ru", 21, "one"}, {"ru", 22, "few"},
+		{"pl", 1, "one"}, {"pl", 22, "few"}, {"pl", 21, "many"},
+		{"cs", 3, "few"}, {"cs", 5, "other"},
+		{"pt-BR", 1, "one"},
+	}
+	for _, c := range cases {
+		if got := PluralForm(c.lang, c.n); got != c.want {
+			t.Errorf("%s %d: %s, want %s", c.lang, c.n, got, c.want)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	cases := []struct {
+		text string
+		want string
+	}{
+		{"plain", "plain"},
+		{"{0} has {n} of {1}", "Rudolf has 3 of nose"},
+		{"{2} is missing", "{2} is missing"},
+		{"{color=#ff0000}{0}{/color} {$name}", "{color=#ff0000}Rudolf{/color} {$name}"},
+		{"{", "{"},
+	}
+	for _, c := range cases {
+		if got := Format(c.text, 3, "Rudolf", "nose"); got != c.want {
+			t.Errorf("%q: %q, want %q", c.text, got, c.want)
+		}
+	}
+}
+
+func TestCatalogFallback(t *testing.T) {
+	c := testCatalog()
+	cases := []struct {
+		lang, id, want string
+	}{
+		{"en", "hello", "Hello, Santa!"},
+		{"pt-BR", "bye", "Tchau"},
+		{"pt-BR", "hello", "Olá, Santa!"},
+		{"pt", "bye", "Bye"},
+		{"ru", "bye", "Бувай"},
+		{"uk", "hello", "Hello, Santa!"},
+		{"en", "nope", "nope"},
+	}
+	for _, cs := range cases {
+		if err := c.SetLang(cs.lang); err != nil {
+			t.Fatal(err)
+		}
+		if got := c.Text(cs.id, "Santa"); got != cs.want {
+			t.Errorf("%s %s: %q, want %q", cs.lang, cs.id, got, cs.want)
+		}
+	}
+	if err := c.SetLang("xx"); err == nil || c.Lang != "en" {
+		t.Errorf("unknown language: %v %s", err, c.Lang)
+	}
+	var none *Catalog
+	if got := none.Text("hello"); got != "hello" {
+		t.Errorf("nil catalog: %q", got)
+	}
+}
+
+func TestCatalogPlural(t *testing.T) {
+	c := testCatalog()
+	if got := c.Plural("gifts", 1); got != "1 gift" {
+		t.Errorf("en 1: %q", got)
+	}
+	if got := c.Plural("gifts", 7); got != "7 gifts" {
+		t.Errorf("en 7: %q", got)
+	}
+	c.SetLang("ru")
+	for n, want := range map[int]string{1: "1 подарок", 3: "3 подарка", 12: "12 подарков"} {
+		if got := c.Plural("gifts", n); got != want {
+			t.Errorf("ru %d: %q, want %q", n, got, want)
+		}
+	}
+	c.SetLang("uk")
+	if got := c.Plural("gifts", 5); got != "5 подарков" {
+		t.Errorf("uk 5: %q", got)
+	}
+	// Plurals found in a fallback use the rules of the fallback.
+	c.SetLang("pt")
+	if got := c.Plural("gifts", 0); got != "0 gifts" {
+		t.Errorf("pt 0: %q", got)
+	}
+}
+
+func TestCatalogChange(t *testing.T) {
+	c := testCatalog()
+	var got []string
+	c.OnChange(func(lang string) { got = append(got, lang) })
+	c.SetLang("pt")
+	c.SetLang("xx")
+	c.SetLang("en")
+	if !slices.Equal(got, []string{"pt", "en"}) {
+		t.Errorf("changes: %v", got)
+	}
+}
+
+func TestCatalogMissing(t *testing.T) {
+	c := testCatalog()
+	if got := c.Missing("pt-BR", "talk.elf.0"); !slices.Equal(got, []string{"gifts", "hello", "talk.elf.0"}) {
+		t.Errorf("missing: %v", got)
+	}
+	covered := map[rune]bool{}
+	for _, r := range "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789{}!," {
+		covered[r] = true
+	}
+	if got := string(c.Uncovered("pt", covered)); got != "á" {
+		t.Errorf("uncovered: %q", got)
+	}
+}
+
+const talkZone = `<zone name="village">
+ <layer/>
+ <talk name="elf">
+  <speak who="Elf"><say>Hello there!</say></speak>
+  <speak who="Elf" when="gifts > 0"><ask>Do you have a gift?</ask>
+   <reply expr="give">Yes</reply><reply>No</reply>
+  </speak>
+ </talk>
+ <talk name="reindeer"><speak><say> Snort. </say></speak></talk>
+</zone>`
+
+func TestExtract(t *testing.T) {
+	entries, err := Extract(strings.NewReader(talkZone))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Entry{
+		{ID: "talk.elf.0", Text: "Hello there!"},
+		{ID: "talk.elf.1", Text: "Do you have a gift?"},
+		{ID: "talk.elf.2", Text: "Yes"},
+		{ID: "talk.elf.3", Text: "No"},
+		{ID: "talk.reindeer.0", Text: "Snort."},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("entries: %+v", entries)
+	}
+	for i := range want {
+		if entries[i].ID != want[i].ID || entries[i].Text != want[i].Text {
+			t.Errorf("entry %d: %+v, want %+v", i, entries[i], want[i])
+		}
+	}
+}
+
+func TestTalkEntries(t *testing.T) {
+	var zone Zone
+	if err := xml.Unmarshal([]byte(talkZone), &zone); err != nil {
+		t.Fatal(err)
+	}
+	extracted, err := Extract(strings.NewReader(talkZone))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entries []Entry
+	for _, name := range []string{"elf", "reindeer"} {
+		talk := zone.FindTalk(name)
+		if talk == nil {
+			t.Fatalf("talk %q not found", name)
+		}
+		entries = append(entries, talk.Entries()...)
+	}
+	if !slices.EqualFunc(entries, extracted, func(a, b Entry) bool { return a.ID == b.ID && a.Text == b.Text }) {
+		t.Errorf("entries %+v, extracted %+v", entries, extracted)
+	}
+	ask := zone.FindTalk("elf").Speak[1]
+	if ask.Who != "Elf" || ask.When != "gifts > 0" || ask.Replies[0].Expr != "give" || ask.Replies[0].Reply != "Yes" {
+		t.Errorf("ask %+v", ask)
+	}
+	if zone.FindTalk("santa") != nil {
+		t.Errorf("found a talk that is not there")
+	}
+}
+
+// oddTalkZone has an empty say, and an ask before a say.
+const oddTalkZone = `<zone name="odd">
+ <talk name="snowman">
+  <speak><say/></speak>
+  <speak><ask>Cold?</ask><say>Brr.</say><reply>Yes</reply></speak>
+  <say>Not spoken.</say>
+ </talk>
+</zone>`
+
+func TestExtractMatchesEntries(t *testing.T) {
+	var zone Zone
+	if err := xml.Unmarshal([]byte(oddTalkZone), &zone); err != nil {
+		t.Fatal(err)
+	}
+	extracted, err := Extract(strings.NewReader(oddTalkZone))
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := zone.FindTalk("snowman").Entries()
+	if !slices.EqualFunc(entries, extracted, func(a, b Entry) bool { return a.ID == b.ID && a.Text == b.Text }) {
+		t.Errorf("entries %+v, extracted %+v", entries, extracted)
+	}
+	if len(entries) != 3 || entries[0].Text != "Brr." || entries[1].Text != "Cold?" {
+		t.Errorf("entries %+v", entries)
+	}
+}
+
+func TestPackLang(t *testing.T) {
+	if _, err := os.Stat("../" + LangDir); err != nil {
+		t.Skip(err)
+	}
+	c, err := LoadCatalog(os.DirFS(".."), LangDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, lang := range c.Langs() {
+		if missing := c.Missing(lang); len(missing) > 0 {
+			t.Errorf("%s misses %v", lang, missing)
+		}
+	}
+	c.SetLang("nl")
+	if got := c.Plural("hud.gifts", 2); got != "2 cadeaus" {
+		t.Errorf("nl gifts: %q", got)
+	}
+}
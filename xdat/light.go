@@ -0,0 +1,124 @@
+package xdat
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+)
+
+import (
+	"github.com/xmasengine/xmas/xgal"
+)
+
+// Color is a color that is saved as hexadecimal text #rrggbb or #rrggbbaa.
+type Color xgal.RGBA
+
+// RGBA returns the color as an [xgal.RGBA].
+func (c Color) RGBA() xgal.RGBA {
+	return xgal.RGBA(c)
+}
+
+// IsZero reports whether the color is fully zero, that is, unset.
+func (c Color) IsZero() bool {
+	return c == Color{}
+}
+
+func (c Color) String() string {
+	if c.A == 255 {
+		return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+	}
+	return fmt.Sprintf("#%02x%02x%02x%02x", c.R, c.G, c.B, c.A)
+}
+
+func (c Color) MarshalText() (text []byte, err error) {
+	return []byte(c.String()), nil
+}
+
+// MarshalXMLAttr omits the attribute if the color is unset.
+func (c Color) MarshalXMLAttr(name xml.Name) (xml.Attr, error) {
+	if c.IsZero() {
+		return xml.Attr{}, nil
+	}
+	return xml.Attr{Name: name, Value: c.String()}, nil
+}
+
+func (c *Color) UnmarshalText(text []byte) error {
+	var r, g, b uint8
+	a := uint8(255)
+	var err error
+	switch len(text) {
+	case 7:
+		_, err = fmt.Sscanf(string(text), "#%02x%02x%02x", &r, &g, &b)
+	case 9:
+		_, err = fmt.Sscanf(string(text), "#%02x%02x%02x%02x", &r, &g, &b, &a)
+	default:
+		err = errors.New("Color must be #rrggbb or #rrggbbaa: " + string(text))
+	}
+	if err != nil {
+		return err
+	}
+	*c = Color(xgal.Paint(r, g, b, a))
+	return nil
+}
+
+// Light is a point light in a zone. It is either at a fixed position,
+// such as a torch placed on a tile, or it follows a player or a thing.
+type Light struct {
+	X       int     `xml:"x,attr"`                 // X is the X position of the center in pixels.
+	Y       int     `xml:"y,attr"`                 // Y is the Y position of the center in pixels.
+	Radius  int     `xml:"r,attr"`                 // Radius of the light in pixels.
+	Color   Color   `xml:"color,attr"`             // Color of the light.
+	Flicker float64 `xml:"flicker,attr,omitempty"` // Flicker from 0 for a steady light to 1 for a candle in the wind.
+	Follow  string  `xml:"follow,attr,omitempty"`  // Follow is the name of the player or thing to follow, if any.
+}
+
+// At returns the position of the light in pixels.
+func (l Light) At() xgal.Point {
+	return xgal.Pt(l.X, l.Y)
+}
+
+// Bounds returns the rectangle the light shines in.
+func (l Light) Bounds() xgal.Rectangle {
+	return xgal.Rect(l.X-l.Radius, l.Y-l.Radius, l.X+l.Radius, l.Y+l.Radius)
+}
+
+// NewLight returns a warm, slightly flickering torch light at x, y.
+func NewLight(x, y, radius int) Light {
+	return Light{X: x, Y: y, Radius: radius,
+		Color: Color(xgal.Tint(255, 200, 120)), Flicker: 0.2}
+}
+
+// LightAt returns the index of the fixed light that is closest to at,
+// within the radius of that light, or -1 if there is no such light.
+func (z *Zone) LightAt(at xgal.Point) int {
+	found := -1
+	best := 0
+	for i, light := range z.Lights {
+		if light.Follow != "" {
+			continue
+		}
+		d := light.At().Sub(at)
+		dist := d.X*d.X + d.Y*d.Y
+		if dist > light.Radius*light.Radius {
+			continue
+		}
+		if found < 0 || dist < best {
+			found = i
+			best = dist
+		}
+	}
+	return found
+}
+
+// AddLight adds a light to the zone.
+func (z *Zone) AddLight(light Light) {
+	z.Lights = append(z.Lights, light)
+}
+
+// RemoveLight removes the light at index i from the zone.
+func (z *Zone) RemoveLight(i int) {
+	if i < 0 || i >= len(z.Lights) {
+		return
+	}
+	z.Lights = append(z.Lights[:i], z.Lights[i+1:]...)
+}
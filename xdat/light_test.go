@@ -0,0 +1,78 @@
+package xdat
+
+import "testing"
+import "bytes"
+
+import "github.com/d4l3k/messagediff"
+
+import "github.com/xmasengine/xmas/xgal"
+
+func TestColorText(t *testing.T) {
+	cases := []struct {
+		text  string
+		color Color
+	}{
+		{"#ff8000", Color(xgal.Tint(255, 128, 0))},
+		{"#10203040", Color(xgal.Paint(16, 32, 48, 64))},
+	}
+	for _, c := range cases {
+		var observe Color
+		if err := observe.UnmarshalText([]byte(c.text)); err != nil {
+			t.Fatalf("%s: %s", c.text, err)
+		}
+		if observe != c.color {
+			t.Errorf("%s: %v != %v", c.text, observe, c.color)
+		}
+		if text := c.color.String(); text != c.text {
+			t.Errorf("%v: %s != %s", c.color, text, c.text)
+		}
+	}
+	var bad Color
+	if err := bad.UnmarshalText([]byte("red")); err == nil {
+		t.Errorf("expected error")
+	}
+}
+
+func TestLightRoundTrip(t *testing.T) {
+	expect := NewZone("church")
+	expect.Ambient = Color(xgal.Tint(40, 40, 80))
+	expect.Outside = true
+	expect.AddLight(NewLight(12, 20, 32))
+	expect.AddLight(Light{Radius: 48, Color: Color(xgal.White), Follow: "Krista"})
+	buf := &bytes.Buffer{}
+	if err := expect.SaveTo(buf); err != nil {
+		t.Fatalf("write error %s", err)
+	}
+	observe, err := LoadFrom(buf)
+	if err != nil {
+		t.Fatalf("read error: %s", err)
+	}
+	if diff, ok := messagediff.PrettyDiff(expect, observe); !ok {
+		t.Fatalf("\ndiff: %s\n", diff)
+	}
+}
+
+func TestLightAt(t *testing.T) {
+	zone := NewZone("church")
+	zone.AddLight(NewLight(10, 10, 8))
+	zone.AddLight(NewLight(20, 10, 8))
+	zone.AddLight(Light{Radius: 100, Follow: "Krista"})
+
+	cases := []struct {
+		at     xgal.Point
+		expect int
+	}{
+		{xgal.Pt(11, 10), 0},
+		{xgal.Pt(16, 10), 1},
+		{xgal.Pt(50, 50), -1},
+	}
+	for _, c := range cases {
+		if observe := zone.LightAt(c.at); observe != c.expect {
+			t.Errorf("%v: %d != %d", c.at, observe, c.expect)
+		}
+	}
+	zone.RemoveLight(0)
+	if len(zone.Lights) != 2 || zone.Lights[0].X != 20 {
+		t.Errorf("remove: %v", zone.Lights)
+	}
+}
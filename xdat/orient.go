@@ -0,0 +1,257 @@
+package xdat
+
+import (
+	"github.com/xmasengine/xmas/xgal"
+)
+
+// Orientation is one of the eight dihedral orientations a tile can have.
+// The lower two bits are the number of 90 degree clockwise rotation steps,
+// bit 2 is a horizontal mirror that is applied after the rotation.
+// This matches the order in which [xgal.Blit] applies its options.
+type Orientation uint8
+
+const (
+	Orient0         Orientation = iota // As drawn in the texture.
+	Orient90                           // Rotated 90 degrees clockwise.
+	Orient180                          // Rotated 180 degrees.
+	Orient270                          // Rotated 270 degrees clockwise.
+	OrientMirror0                      // Mirrored horizontally.
+	OrientMirror90                     // Rotated 90 degrees, then mirrored.
+	OrientMirror180                    // Rotated 180 degrees, then mirrored.
+	OrientMirror270                    // Rotated 270 degrees, then mirrored.
+	OrientCount                        // Amount of orientations.
+)
+
+const (
+	// OrientFlipH is a horizontal flip.
+	OrientFlipH = OrientMirror0
+	// OrientFlipV is a vertical flip, which is a mirrored 180 rotation.
+	OrientFlipV = OrientMirror180
+)
+
+const orientMirror Orientation = 4
+
+// MakeOrientation returns the orientation for a clockwise rotation in
+// 90 degree steps, followed by an optional horizontal mirror.
+func MakeOrientation(steps int, mirror bool) Orientation {
+	o := Orientation(((steps % 4) + 4) % 4)
+	if mirror {
+		o |= orientMirror
+	}
+	return o
+}
+
+// Steps returns the amount of 90 degree clockwise rotation steps.
+func (o Orientation) Steps() int {
+	return int(o & 3)
+}
+
+// Mirrored reports whether the orientation mirrors after rotating.
+func (o Orientation) Mirrored() bool {
+	return o&orientMirror != 0
+}
+
+// Then returns the orientation of first applying o and then next.
+func (o Orientation) Then(next Orientation) Orientation {
+	steps := next.Steps()
+	if o.Mirrored() {
+		// Mirroring reverses the direction of later rotations.
+		steps = -steps
+	}
+	return MakeOrientation(o.Steps()+steps, o.Mirrored() != next.Mirrored())
+}
+
+// Inverse returns the orientation that undoes o.
+func (o Orientation) Inverse() Orientation {
+	if o.Mirrored() {
+		// Mirrored orientations are their own inverse.
+		return o
+	}
+	return MakeOrientation(-o.Steps(), false)
+}
+
+// Rotate returns o followed by a 90 degree clockwise rotation.
+func (o Orientation) Rotate() Orientation {
+	return o.Then(Orient90)
+}
+
+// FlipH returns o followed by a horizontal flip.
+func (o Orientation) FlipH() Orientation {
+	return o.Then(OrientFlipH)
+}
+
+// FlipV returns o followed by a vertical flip.
+func (o Orientation) FlipV() Orientation {
+	return o.Then(OrientFlipV)
+}
+
+// Size returns the size of a w×h area after it is oriented with o.
+func (o Orientation) Size(size xgal.Point) xgal.Point {
+	if o.Steps()%2 == 1 {
+		return xgal.Pt(size.Y, size.X)
+	}
+	return size
+}
+
+// Apply returns where the cell at in a grid of the given size ends up
+// after the grid is oriented with o.
+func (o Orientation) Apply(at, size xgal.Point) xgal.Point {
+	for i := 0; i < o.Steps(); i++ {
+		at = xgal.Pt(size.Y-1-at.Y, at.X)
+		size = xgal.Pt(size.Y, size.X)
+	}
+	if o.Mirrored() {
+		at.X = size.X - 1 - at.X
+	}
+	return at
+}
+
+// Opts returns the blit options that draw a tile with this orientation.
+func (o Orientation) Opts() xgal.BlitOpts {
+	return xgal.BlitOpts{Rot: xgal.Rot(o.Steps()), FlipH: o.Mirrored()}
+}
+
+// Flips returns the horizontal and vertical flips that are equal to o.
+// Only four orientations can be expressed with flips alone, as is the case
+// for the SMS tile map format. ok is false for the other four.
+func (o Orientation) Flips() (h, v, ok bool) {
+	switch o {
+	case Orient0:
+		return false, false, true
+	case OrientFlipH:
+		return true, false, true
+	case OrientFlipV:
+		return false, true, true
+	case Orient180:
+		return true, true, true
+	default:
+		return false, false, false
+	}
+}
+
+// FlipOrientation returns the orientation for a horizontal and
+// vertical flip, such as the flip bits of the SMS tile map format.
+func FlipOrientation(h, v bool) Orientation {
+	o := Orient0
+	if h {
+		o = o.FlipH()
+	}
+	if v {
+		o = o.FlipV()
+	}
+	return o
+}
+
+func (o Orientation) String() string {
+	return o.Flag().String()
+}
+
+// Flag returns the canonical tile flags for o.
+// This uses the least amount of flags, so a vertical flip stays a
+// vertical flip and is not stored as a mirrored 180 degree rotation.
+func (o Orientation) Flag() Flag {
+	switch o {
+	case OrientFlipV:
+		return FlagVertical
+	}
+	f := Flag(0)
+	if o.Mirrored() {
+		f |= FlagHorizontal
+	}
+	switch o.Steps() {
+	case 1:
+		f |= FlagRotate90
+	case 2:
+		f |= FlagRotate180
+	case 3:
+		f |= FlagRotate270
+	}
+	return f
+}
+
+// FlagOrientation are all flags that affect the orientation of a tile.
+const FlagOrientation = FlagHorizontal | FlagVertical |
+	FlagRotate90 | FlagRotate180 | FlagRotate270
+
+// Orientation returns the orientation of the flags.
+// Rotation is applied first, then the horizontal and vertical flips,
+// so any combination of flags maps to one of the eight orientations.
+func (f Flag) Orientation() Orientation {
+	o := Orient0
+	if f.Has(FlagRotate90) {
+		o = o.Then(Orient90)
+	}
+	if f.Has(FlagRotate180) {
+		o = o.Then(Orient180)
+	}
+	if f.Has(FlagRotate270) {
+		o = o.Then(Orient270)
+	}
+	if f.Has(FlagHorizontal) {
+		o = o.FlipH()
+	}
+	if f.Has(FlagVertical) {
+		o = o.FlipV()
+	}
+	return o
+}
+
+// Orient returns the flags with the orientation flags replaced by the
+// canonical flags for o.
+func (f Flag) Orient(o Orientation) Flag {
+	return (f &^ FlagOrientation) | o.Flag()
+}
+
+// Orient returns the tiles in block oriented with o. The cells move to
+// their new place, and each cell's own orientation is composed with o.
+func (t Tiles) Orient(o Orientation) Tiles {
+	h := len(t.Rows)
+	w := 0
+	if h > 0 {
+		w = len(t.Rows[0])
+	}
+	size := xgal.Pt(w, h)
+	nsize := o.Size(size)
+	res := Tiles{Rows: make([]Row, nsize.Y)}
+	for y := range res.Rows {
+		res.Rows[y] = make(Row, nsize.X)
+	}
+
+	for y, row := range t.Rows {
+		for x, cell := range row {
+			to := o.Apply(xgal.Pt(x, y), size)
+			cell.Flag = cell.Flag.Orient(cell.Flag.Orientation().Then(o))
+			res.Set(to, cell)
+		}
+	}
+	return res
+}
+
+// Block returns a copy of the tiles inside of the rectangle r,
+// clipped to the tiles that exist.
+func (t Tiles) Block(r xgal.Rectangle) Tiles {
+	r = r.Canon()
+	res := Tiles{}
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		row := Row{}
+		for x := r.Min.X; x < r.Max.X; x++ {
+			if t.Contains(x, y) {
+				row = append(row, t.Rows[y][x])
+			}
+		}
+		if len(row) > 0 {
+			res.Rows = append(res.Rows, row)
+		}
+	}
+	return res
+}
+
+// Paste sets the tiles of block with the top left corner at.
+// Cells that fall outside t are skipped.
+func (t *Tiles) Paste(at xgal.Point, block Tiles) {
+	for y, row := range block.Rows {
+		for x, cell := range row {
+			t.Set(at.Add(xgal.Pt(x, y)), cell)
+		}
+	}
+}
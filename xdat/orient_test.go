@@ -0,0 +1,176 @@
+package xdat
+
+import "testing"
+
+import "github.com/xmasengine/xmas/xgal"
+
+// orientSize is a non square size so swapped axes are detected.
+var orientSize = xgal.Pt(3, 2)
+
+func TestOrientationApply(t *testing.T) {
+	cases := []struct {
+		o      Orientation
+		expect xgal.Point // where (0, 0) of a 3×2 grid ends up
+		size   xgal.Point
+	}{
+		{Orient0, xgal.Pt(0, 0), xgal.Pt(3, 2)},
+		{Orient90, xgal.Pt(1, 0), xgal.Pt(2, 3)},
+		{Orient180, xgal.Pt(2, 1), xgal.Pt(3, 2)},
+		{Orient270, xgal.Pt(0, 2), xgal.Pt(2, 3)},
+		{OrientFlipH, xgal.Pt(2, 0), xgal.Pt(3, 2)},
+		{OrientMirror90, xgal.Pt(0, 0), xgal.Pt(2, 3)},
+		{OrientFlipV, xgal.Pt(0, 1), xgal.Pt(3, 2)},
+		{OrientMirror270, xgal.Pt(1, 2), xgal.Pt(2, 3)},
+	}
+	for _, c := range cases {
+		observe := c.o.Apply(xgal.Pt(0, 0), orientSize)
+		if observe != c.expect {
+			t.Errorf("%s: apply: %v != %v", c.o, observe, c.expect)
+		}
+		if size := c.o.Size(orientSize); size != c.size {
+			t.Errorf("%s: size: %v != %v", c.o, size, c.size)
+		}
+	}
+}
+
+func TestOrientationThen(t *testing.T) {
+	for a := Orient0; a < OrientCount; a++ {
+		for b := Orient0; b < OrientCount; b++ {
+			ab := a.Then(b)
+			mid := a.Size(orientSize)
+			for y := 0; y < orientSize.Y; y++ {
+				for x := 0; x < orientSize.X; x++ {
+					at := xgal.Pt(x, y)
+					expect := b.Apply(a.Apply(at, orientSize), mid)
+					observe := ab.Apply(at, orientSize)
+					if observe != expect {
+						t.Fatalf("%s then %s = %s: %v: %v != %v",
+							a, b, ab, at, observe, expect)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestOrientationInverse(t *testing.T) {
+	for o := Orient0; o < OrientCount; o++ {
+		if observe := o.Then(o.Inverse()); observe != Orient0 {
+			t.Errorf("%s then inverse %s: %s", o, o.Inverse(), observe)
+		}
+		if observe := o.Inverse().Then(o); observe != Orient0 {
+			t.Errorf("inverse %s then %s: %s", o.Inverse(), o, observe)
+		}
+	}
+}
+
+func TestOrientationFlag(t *testing.T) {
+	cases := []struct {
+		flag   Flag
+		expect Orientation
+	}{
+		{0, Orient0},
+		{FlagRotate90, Orient90},
+		{FlagRotate180, Orient180},
+		{FlagRotate270, Orient270},
+		{FlagHorizontal, OrientFlipH},
+		{FlagVertical, OrientFlipV},
+		{FlagHorizontal | FlagVertical, Orient180},
+		{FlagRotate90 | FlagHorizontal, OrientMirror90},
+		{FlagRotate90 | FlagVertical, OrientMirror270},
+		{FlagRotate270 | FlagHorizontal, OrientMirror270},
+		{FlagSolid | FlagRotate180, Orient180},
+	}
+	for _, c := range cases {
+		if observe := c.flag.Orientation(); observe != c.expect {
+			t.Errorf("%s: %s != %s", c.flag, observe, c.expect)
+		}
+	}
+
+	for o := Orient0; o < OrientCount; o++ {
+		flag := FlagSolid.Orient(o)
+		if !flag.Has(FlagSolid) {
+			t.Errorf("%s: other flags lost: %s", o, flag)
+		}
+		if observe := flag.Orientation(); observe != o {
+			t.Errorf("%s: round trip: %s", o, observe)
+		}
+	}
+}
+
+func TestOrientationFlips(t *testing.T) {
+	for _, h := range []bool{false, true} {
+		for _, v := range []bool{false, true} {
+			o := FlipOrientation(h, v)
+			oh, ov, ok := o.Flips()
+			if !ok || oh != h || ov != v {
+				t.Errorf("%t %t: %s: %t %t %t", h, v, o, oh, ov, ok)
+			}
+		}
+	}
+	if _, _, ok := Orient90.Flips(); ok {
+		t.Errorf("rotation expressed as flips")
+	}
+}
+
+func TestFlagRotate(t *testing.T) {
+	flag := FlagHorizontal
+	flag.Rotate()
+	// Rotating a mirrored tile must rotate it clockwise as seen on screen.
+	if observe := flag.Orientation(); observe != OrientFlipH.Then(Orient90) {
+		t.Errorf("rotate mirrored: %s", observe)
+	}
+	for i := 0; i < 3; i++ {
+		flag.Rotate()
+	}
+	if flag != FlagHorizontal {
+		t.Errorf("rotate four times: %s", flag)
+	}
+}
+
+func TestTilesOrient(t *testing.T) {
+	block := Tiles{Rows: []Row{
+		{MakeTile(1, 0, 0), MakeTile(2, 0, 0), MakeTile(3, 0, 0)},
+		{MakeTile(4, 0, 0), MakeTile(5, 0, 0), MakeTile(6, 0, FlagHorizontal)},
+	}}
+	observe := block.Orient(Orient90)
+	expect := Tiles{Rows: []Row{
+		{MakeTile(4, 0, FlagRotate90), MakeTile(1, 0, FlagRotate90)},
+		{MakeTile(5, 0, FlagRotate90), MakeTile(2, 0, FlagRotate90)},
+		{MakeTile(6, 0, FlagHorizontal|FlagRotate270), MakeTile(3, 0, FlagRotate90)},
+	}}
+	for y, row := range expect.Rows {
+		for x, cell := range row {
+			if got := observe.Get(xgal.Pt(x, y)); got != cell {
+				t.Errorf("(%d,%d): %v != %v", x, y, got, cell)
+			}
+		}
+	}
+
+	back := observe.Orient(Orient90.Inverse())
+	for y, row := range block.Rows {
+		for x, cell := range row {
+			if got := back.Get(xgal.Pt(x, y)); got != cell {
+				t.Errorf("back (%d,%d): %v != %v", x, y, got, cell)
+			}
+		}
+	}
+}
+
+func TestTilesBlockPaste(t *testing.T) {
+	layer := NewLayerWith(4, 4, 8, 8)
+	layer.Set(xgal.Pt(1, 1), MakeTile(7, 0, 0))
+	layer.Set(xgal.Pt(2, 1), MakeTile(8, 0, 0))
+	block := layer.Tiles.Block(xgal.Rect(2, 2, 1, 1))
+	if len(block.Rows) != 1 || len(block.Rows[0]) != 1 {
+		t.Fatalf("block size: %v", block.Rows)
+	}
+	block = layer.Tiles.Block(xgal.Rect(1, 1, 3, 2))
+	layer.Tiles.Paste(xgal.Pt(3, 3), block)
+	if got := layer.Get(xgal.Pt(3, 3)); got != MakeTile(7, 0, 0) {
+		t.Errorf("paste: %v", got)
+	}
+	if layer.Contains(4, 3) {
+		t.Errorf("paste out of bounds")
+	}
+}
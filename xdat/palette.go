@@ -0,0 +1,121 @@
+package xdat
+
+import (
+	"errors"
+	"image"
+	"io/fs"
+	"math"
+)
+
+import (
+	"github.com/xmasengine/xmas/xgal"
+)
+
+// Cycle is a range of palette colors that rotates over time, for water,
+// lava and similar effects.
+type Cycle struct {
+	Low     int     `xml:"low,attr"`               // Low is the first index of the range.
+	High    int     `xml:"high,attr"`              // High is the last index of the range.
+	Rate    float64 `xml:"rate,attr"`              // Rate is the amount of steps per second.
+	Reverse bool    `xml:"reverse,attr,omitempty"` // Reverse rotates towards lower indexes.
+}
+
+// Steps returns how many steps the cycle has rotated after seconds.
+func (c Cycle) Steps(seconds float64) int {
+	steps := int(math.Floor(seconds * c.Rate))
+	if c.Reverse {
+		return -steps
+	}
+	return steps
+}
+
+// Apply returns pal rotated as the cycle is after seconds.
+func (c Cycle) Apply(pal xgal.Palette, seconds float64) xgal.Palette {
+	return xgal.Rotate(pal, c.Low, c.High, c.Steps(seconds))
+}
+
+// Indexing keeps the texture of a layer or player indexed if the source
+// is a paletted image, so its palette can be swapped, cycled and faded.
+type Indexing struct {
+	PaletteSource string        `xml:"pal,attr,omitempty"` // PaletteSource is a .gpl file to swap the palette with.
+	Cycles        []Cycle       `xml:"cycle"`              // Cycles are ranges of the palette that rotate.
+	Indexed       *xgal.Indexed `xml:"-"`                  // Indexed texture if the source is paletted.
+	Base          xgal.Palette  `xml:"-"`                  // Base is the palette after the swap.
+	baked         *xgal.Surface // baked is the texture of a source that is not paletted.
+}
+
+// load loads the texture from src, keeping it indexed if possible, and
+// swaps its palette with the palette source if there is one. Sources
+// without palette swap or cycles are first looked up in the added atlases,
+// like [xgal.Texture] does, so they may be logical names such as
+// gfx/character.png#walk_0. If loading fails, the texture that was loaded
+// before is kept. The texture that was loaded before is deallocated, unless
+// it came from an atlas, which shares it.
+func (ix *Indexing) load(fsys fs.FS, src string) (*xgal.Surface, error) {
+	if ix.PaletteSource == "" && len(ix.Cycles) == 0 {
+		if sub, ok := xgal.Sprite(src); ok {
+			ix.free()
+			return sub, nil
+		}
+	}
+	img, err := xgal.Pixels(fsys, src)
+	if err != nil {
+		return nil, err
+	}
+	pimg, ok := img.(*image.Paletted)
+	if !ok {
+		if ix.PaletteSource != "" || len(ix.Cycles) > 0 {
+			return nil, errors.New(src + " is not a paletted image")
+		}
+		ix.free()
+		ix.baked = xgal.Bake(img)
+		return ix.baked, nil
+	}
+	base := pimg.Palette
+	if ix.PaletteSource != "" {
+		swatch, err := xgal.LoadSwatch(fsys, ix.PaletteSource)
+		if err != nil {
+			return nil, err
+		}
+		base = xgal.Swap(pimg.Palette, 0, swatch.Colors)
+	}
+	ix.free()
+	ix.Base = base
+	ix.Indexed = xgal.Index(pimg)
+	ix.Indexed.Repaint(ix.Base)
+	return ix.Indexed.Surface, nil
+}
+
+// free deallocates the texture that load made, if any.
+func (ix *Indexing) free() {
+	if ix.Indexed != nil {
+		ix.Indexed.Deallocate()
+		ix.Indexed = nil
+	}
+	if ix.baked != nil {
+		ix.baked.Deallocate()
+		ix.baked = nil
+	}
+}
+
+// Palette returns the palette after seconds of cycling, faded towards
+// col by level.
+func (ix *Indexing) Palette(seconds float64, col xgal.RGBA, level float64) xgal.Palette {
+	pal := ix.Base
+	for _, cycle := range ix.Cycles {
+		pal = cycle.Apply(pal, seconds)
+	}
+	if level > 0 {
+		pal = xgal.Fade(pal, col, level)
+	}
+	return pal
+}
+
+// Repaint paints the indexed texture as it is after seconds of cycling,
+// faded towards col by level. It reports whether the texture changed.
+func (ix *Indexing) Repaint(seconds float64, col xgal.RGBA, level float64) bool {
+	if ix.Indexed == nil {
+		return false
+	}
+	return ix.Indexed.Repaint(ix.Palette(seconds, col, level))
+}
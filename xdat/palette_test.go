@@ -0,0 +1,88 @@
+package xdat
+
+import "bytes"
+import "image"
+import "image/color"
+import "image/png"
+import "testing"
+import "testing/fstest"
+
+import "github.com/xmasengine/xmas/xgal"
+
+func TestCycleSteps(t *testing.T) {
+	cases := []struct {
+		cycle   Cycle
+		seconds float64
+		expect  int
+	}{
+		{Cycle{Rate: 4}, 0.2, 0},
+		{Cycle{Rate: 4}, 0.5, 2},
+		{Cycle{Rate: 4, Reverse: true}, 1, -4},
+	}
+	for i, c := range cases {
+		if observe := c.cycle.Steps(c.seconds); observe != c.expect {
+			t.Errorf("%d: %d != %d", i, observe, c.expect)
+		}
+	}
+}
+
+func TestIndexingPalette(t *testing.T) {
+	a := color.NRGBA{R: 10, A: 255}
+	b := color.NRGBA{G: 20, A: 255}
+	c := color.NRGBA{B: 30, A: 255}
+	ix := Indexing{Base: xgal.Palette{a, b, c}, Cycles: []Cycle{{Low: 1, High: 2, Rate: 1}}}
+
+	pal := ix.Palette(1, xgal.Black, 0)
+	if pal[0] != a || pal[1] != c || pal[2] != b {
+		t.Errorf("cycled: %v", pal)
+	}
+	pal = ix.Palette(0, xgal.Black, 1)
+	for i, col := range pal {
+		if col != (color.NRGBA{A: 255}) {
+			t.Errorf("%d: not faded to black: %v", i, col)
+		}
+	}
+	if ix.Repaint(1, xgal.Black, 0) {
+		t.Errorf("repainted without indexed texture")
+	}
+}
+
+func TestIndexingLoadKeepsTexture(t *testing.T) {
+	var sheet bytes.Buffer
+	pal := color.Palette{color.NRGBA{A: 255}, color.NRGBA{R: 255, A: 255}}
+	if err := png.Encode(&sheet, image.NewPaletted(image.Rect(0, 0, 8, 8), pal)); err != nil {
+		t.Fatal(err)
+	}
+	fsys := fstest.MapFS{"sheet.png": &fstest.MapFile{Data: sheet.Bytes()}}
+
+	ix := Indexing{}
+	texture, err := ix.load(fsys, "sheet.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	indexed := ix.Indexed
+	ix.PaletteSource = "missing.gpl"
+	if _, err := ix.load(fsys, "sheet.png"); err == nil {
+		t.Fatalf("loaded with a missing swatch")
+	}
+	if ix.Indexed != indexed || ix.Indexed.Surface != texture || len(ix.Base) != len(pal) {
+		t.Errorf("texture not kept after a failed load")
+	}
+}
+
+func TestLayerSourceFromAtlas(t *testing.T) {
+	atlas := &xgal.Atlas{Surface: xgal.Prepare(32, 32), Regions: map[string]xgal.Region{
+		"gfx/character.png#walk_0": {Area: xgal.Rect(8, 0, 24, 16), Size: xgal.Pt(16, 16)},
+	}}
+	xgal.AddAtlas(atlas)
+	defer xgal.RemoveAtlas(atlas)
+
+	layer := NewLayer()
+	if err := layer.SetSource(fstest.MapFS{}, "gfx/character.png#walk_0"); err != nil {
+		t.Fatal(err)
+	}
+	sub, _ := xgal.Sprite("gfx/character.png#walk_0")
+	if layer.Texture != sub || layer.Indexed != nil {
+		t.Errorf("layer texture not from the atlas")
+	}
+}
@@ -0,0 +1,247 @@
+package xdat
+
+import (
+	"bufio"
+	"cmp"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Timecode is a time in a video. In XML it is written as seconds, such as
+// 1.5, or as hours, minutes and seconds, such as 00:01:02.500.
+type Timecode time.Duration
+
+// ParseTimecode parses seconds, or hours, minutes and seconds, or minutes
+// and seconds, separated by colons. The fraction of the seconds may be
+// separated by a dot or, as in SRT, by a comma.
+func ParseTimecode(text string) (Timecode, error) {
+	text = strings.ReplaceAll(strings.TrimSpace(text), ",", ".")
+	parts := strings.Split(text, ":")
+	if len(parts) > 3 {
+		return 0, fmt.Errorf("bad timecode %q", text)
+	}
+	total := 0.0
+	for i, part := range parts {
+		v, err := strconv.ParseFloat(part, 64)
+		if err != nil || v < 0 || (i < len(parts)-1 && strings.Contains(part, ".")) {
+			return 0, fmt.Errorf("bad timecode %q", text)
+		}
+		total = total*60 + v
+	}
+	return Timecode(total * float64(time.Second)), nil
+}
+
+// String formats the timecode as SRT does, like 00:01:02,500.
+func (t Timecode) String() string {
+	ms := time.Duration(t).Milliseconds()
+	return fmt.Sprintf("%02d:%02d:%02d,%03d", ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
+}
+
+// Duration returns the timecode as a duration.
+func (t Timecode) Duration() time.Duration {
+	return time.Duration(t)
+}
+
+func (t *Timecode) UnmarshalXMLAttr(attr xml.Attr) error {
+	parsed, err := ParseTimecode(attr.Value)
+	*t = parsed
+	return err
+}
+
+func (t Timecode) MarshalXMLAttr(name xml.Name) (xml.Attr, error) {
+	return xml.Attr{Name: name, Value: strconv.FormatFloat(time.Duration(t).Seconds(), 'f', -1, 64)}, nil
+}
+
+// Cue is a subtitle that shows from its start until its end.
+type Cue struct {
+	Start Timecode `xml:"start,attr"` // Start is when the cue shows.
+	End   Timecode `xml:"end,attr"`   // End is when the cue hides.
+	Text  string   `xml:",chardata"`  // Text of the cue, lines separated by newlines.
+}
+
+// Subtitles is a time coded subtitle track of a video.
+type Subtitles struct {
+	XMLName xml.Name `xml:"subtitles"`
+	Lang    string   `xml:"lang,attr,omitempty"` // Lang is the language of the track.
+	Cues    []Cue    `xml:"cue"`                 // Cues sorted by start.
+}
+
+// At returns the cues that show at time t, in the order of their start.
+func (s *Subtitles) At(t time.Duration) []Cue {
+	if s == nil {
+		return nil
+	}
+	var cues []Cue
+	for _, c := range s.Cues {
+		if c.Start.Duration() > t {
+			break
+		}
+		if t < c.End.Duration() {
+			cues = append(cues, c)
+		}
+	}
+	return cues
+}
+
+// End returns the end of the last cue.
+func (s *Subtitles) End() time.Duration {
+	end := time.Duration(0)
+	if s != nil {
+		for _, c := range s.Cues {
+			end = max(end, c.End.Duration())
+		}
+	}
+	return end
+}
+
+// sort sorts the cues by start and trims their text.
+func (s *Subtitles) sort() {
+	for i := range s.Cues {
+		s.Cues[i].Text = strings.TrimSpace(s.Cues[i].Text)
+	}
+	slices.SortStableFunc(s.Cues, func(a, b Cue) int {
+		return cmp.Compare(a.Start, b.Start)
+	})
+}
+
+func (s Subtitles) SaveTo(wr io.Writer) error {
+	enc := xml.NewEncoder(wr)
+	enc.Indent("", " ")
+	return enc.Encode(s)
+}
+
+// WriteSRT writes the subtitles in SRT format.
+func (s Subtitles) WriteSRT(wr io.Writer) error {
+	buf := bufio.NewWriter(wr)
+	for i, c := range s.Cues {
+		fmt.Fprintf(buf, "%d\n%s --> %s\n%s\n\n", i+1, c.Start, c.End, c.Text)
+	}
+	return buf.Flush()
+}
+
+// stripTags removes the HTML style tags that SRT files use for styling,
+// such as <i> and <font color="red">, since the UI does not draw them.
+func stripTags(text string) string {
+	out := strings.Builder{}
+	for {
+		open := strings.IndexByte(text, '<')
+		if open < 0 {
+			break
+		}
+		end := strings.IndexByte(text[open:], '>')
+		if end < 0 {
+			break
+		}
+		out.WriteString(text[:open])
+		text = text[open+end+1:]
+	}
+	out.WriteString(text)
+	return out.String()
+}
+
+// ReadSRT reads subtitles in SRT format: blocks of a number, a line with
+// the start and end like 00:00:01,000 --> 00:00:02,500 and the lines of
+// text, separated by blank lines.
+func ReadSRT(rd io.Reader) (*Subtitles, error) {
+	s := &Subtitles{}
+	scan := bufio.NewScanner(rd)
+	var cue *Cue
+	for line := 1; scan.Scan(); line++ {
+		text := strings.TrimRight(scan.Text(), "\r")
+		if line == 1 {
+			text = strings.TrimPrefix(text, "\ufeff")
+		}
+		switch {
+		case strings.TrimSpace(text) == "":
+			cue = nil
+		case cue != nil:
+			if cue.Text != "" {
+				cue.Text += "\n"
+			}
+			cue.Text += stripTags(text)
+		case strings.Contains(text, "-->"):
+			from, to, _ := strings.Cut(text, "-->")
+			// Some files add a position after the end.
+			fields := strings.Fields(to)
+			if len(fields) == 0 {
+				return nil, fmt.Errorf("line %d: no end time", line)
+			}
+			start, err := ParseTimecode(from)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			end, err := ParseTimecode(fields[0])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			s.Cues = append(s.Cues, Cue{Start: start, End: end})
+			cue = &s.Cues[len(s.Cues)-1]
+		default:
+			// The number of the cue.
+			if _, err := strconv.Atoi(strings.TrimSpace(text)); err != nil {
+				return nil, fmt.Errorf("line %d: expected a cue number: %q", line, text)
+			}
+		}
+	}
+	if err := scan.Err(); err != nil {
+		return nil, err
+	}
+	s.sort()
+	return s, nil
+}
+
+// ReadSubtitles reads subtitles in the XML format of [Subtitles].
+func ReadSubtitles(rd io.Reader) (*Subtitles, error) {
+	s := &Subtitles{}
+	if err := xml.NewDecoder(rd).Decode(s); err != nil {
+		return nil, err
+	}
+	s.sort()
+	return s, nil
+}
+
+// LoadSubtitles loads subtitles from fsys, in SRT format if the name ends
+// in .srt, and in XML format otherwise.
+func LoadSubtitles(fsys fs.FS, name string) (*Subtitles, error) {
+	fin, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer fin.Close()
+	read := ReadSubtitles
+	if strings.ToLower(path.Ext(name)) == ".srt" {
+		read = ReadSRT
+	}
+	s, err := read(fin)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return s, nil
+}
+
+// FindSubtitles loads the subtitles of a video, trying the track of the
+// language first, such as intro.nl.srt for intro.mpg, and then the track
+// without language, such as intro.srt. It returns nil and no error if the
+// video has no subtitles.
+func FindSubtitles(fsys fs.FS, video, lang string) (*Subtitles, error) {
+	base := strings.TrimSuffix(video, path.Ext(video))
+	var names []string
+	if lang != "" {
+		names = append(names, base+"."+lang+".srt", base+"."+lang+".xml")
+	}
+	names = append(names, base+".srt", base+".xml")
+	for _, name := range names {
+		if _, err := fs.Stat(fsys, name); err != nil {
+			continue
+		}
+		return LoadSubtitles(fsys, name)
+	}
+	return nil, nil
+}
@@ -0,0 +1,139 @@
+package xdat
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+const testSRT = "\ufeff1\r\n00:00:01,000 --> 00:00:03,500\r\nHo ho <i>ho</i>!\r\n\r\n" +
+	"3\n00:00:05,000 --> 00:00:06,000 X1:10 X2:20\nLast\n\n" +
+	"2\n00:00:03,000 --> 00:00:05,000\nTwo\nlines\n"
+
+func TestParseTimecode(t *testing.T) {
+	cases := []struct {
+		text string
+		want time.Duration
+	}{
+		{"1.5", 1500 * time.Millisecond},
+		{"00:01:02,500", 62500 * time.Millisecond},
+		{"01:00:00.000", time.Hour},
+		{"2:03", 123 * time.Second},
+	}
+	for _, c := range cases {
+		got, err := ParseTimecode(c.text)
+		if err != nil || got.Duration() != c.want {
+			t.Errorf("%q: %v %v, want %v", c.text, got.Duration(), err, c.want)
+		}
+	}
+	for _, bad := range []string{"", "soon", "1:2:3:4", "1.5:00", "-1"} {
+		if _, err := ParseTimecode(bad); err == nil {
+			t.Errorf("%q: expected error", bad)
+		}
+	}
+	if s := Timecode(62500 * time.Millisecond).String(); s != "00:01:02,500" {
+		t.Errorf("string: %s", s)
+	}
+}
+
+func TestReadSRT(t *testing.T) {
+	s, err := ReadSRT(strings.NewReader(testSRT))
+	if err != nil {
+		t.Fatal(err)
+	}
+	texts := []string{"Ho ho ho!", "Two\nlines", "Last"}
+	if len(s.Cues) != len(texts) {
+		t.Fatalf("cues: %+v", s.Cues)
+	}
+	for i, text := range texts {
+		if s.Cues[i].Text != text {
+			t.Errorf("cue %d: %q, want %q", i, s.Cues[i].Text, text)
+		}
+	}
+	at := []struct {
+		t    time.Duration
+		want string
+	}{
+		{0, ""},
+		{time.Second, "Ho ho ho!"},
+		{3200 * time.Millisecond, "Ho ho ho!|Two\nlines"},
+		{3500 * time.Millisecond, "Two\nlines"},
+		{5 * time.Second, "Last"},
+		{6 * time.Second, ""},
+	}
+	for _, a := range at {
+		var got []string
+		for _, c := range s.At(a.t) {
+			got = append(got, c.Text)
+		}
+		if strings.Join(got, "|") != a.want {
+			t.Errorf("at %v: %q, want %q", a.t, got, a.want)
+		}
+	}
+	if s.End() != 6*time.Second {
+		t.Errorf("end: %v", s.End())
+	}
+
+	buf := &bytes.Buffer{}
+	if err := s.WriteSRT(buf); err != nil {
+		t.Fatal(err)
+	}
+	back, err := ReadSRT(buf)
+	if err != nil || len(back.Cues) != 3 || back.Cues[1] != s.Cues[1] {
+		t.Errorf("round trip: %+v %v", back, err)
+	}
+
+	for _, bad := range []string{"one\n", "1\n00:00:01 --> soon\nx\n", "1\n00:00:01 -->\n"} {
+		if _, err := ReadSRT(strings.NewReader(bad)); err == nil {
+			t.Errorf("%q: expected error", bad)
+		}
+	}
+}
+
+func TestReadSubtitles(t *testing.T) {
+	s, err := ReadSubtitles(strings.NewReader(`<subtitles lang="en">
+ <cue start="00:00:02.000" end="3">Second</cue>
+ <cue start="0.5" end="2">
+  First
+ </cue>
+</subtitles>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Cues) != 2 || s.Cues[0].Text != "First" || s.Cues[1].Start.Duration() != 2*time.Second {
+		t.Fatalf("cues: %+v", s.Cues)
+	}
+	buf := &bytes.Buffer{}
+	if err := s.SaveTo(buf); err != nil {
+		t.Fatal(err)
+	}
+	back, err := ReadSubtitles(buf)
+	if err != nil || len(back.Cues) != 2 || back.Cues[0] != s.Cues[0] || back.Lang != "en" {
+		t.Errorf("round trip: %+v %v\n%s", back, err, buf.String())
+	}
+	if _, err := ReadSubtitles(strings.NewReader(`<subtitles><cue start="x"/></subtitles>`)); err == nil {
+		t.Errorf("expected error for bad start")
+	}
+}
+
+func TestFindSubtitles(t *testing.T) {
+	fsys := fstest.MapFS{
+		"video/intro.srt":    {Data: []byte("1\n0:01 --> 0:02\nHello\n")},
+		"video/intro.nl.xml": {Data: []byte(`<subtitles lang="nl"><cue start="1" end="2">Hallo</cue></subtitles>`)},
+	}
+	for lang, want := range map[string]string{"nl": "Hallo", "de": "Hello", "": "Hello"} {
+		s, err := FindSubtitles(fsys, "video/intro.mpg", lang)
+		if err != nil || s == nil || s.Cues[0].Text != want {
+			t.Errorf("%s: %+v %v", lang, s, err)
+		}
+	}
+	if s, err := FindSubtitles(fsys, "video/outro.mpg", "nl"); s != nil || err != nil {
+		t.Errorf("outro: %+v %v", s, err)
+	}
+	var none *Subtitles
+	if none.At(time.Second) != nil || none.End() != 0 {
+		t.Errorf("nil subtitles")
+	}
+}
@@ -0,0 +1,347 @@
+package xdat
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+import (
+	"github.com/xmasengine/xmas/xgal"
+	"github.com/xmasengine/xmas/xres/fontres"
+	"github.com/xmasengine/xmas/xvec"
+)
+
+// ThemeDir is the directory with the themes.
+const ThemeDir = "pack/theme"
+
+// DefaultTheme is the name of the theme that is used if none is chosen.
+const DefaultTheme = "default"
+
+// ContrastTheme is the name of the high contrast theme, with white on
+// black widgets, a yellow focus and a larger font, for players who see
+// poorly.
+const ContrastTheme = "contrast"
+
+// DefaultStyle is the name of the style that other styles build on.
+const DefaultStyle = "default"
+
+// NoFrame is the frame of a look that removes the frame of its base.
+const NoFrame = "none"
+
+// Pair is a pair of numbers that is saved as text x,y, or as a single
+// number if both are the same.
+type Pair xgal.Point
+
+// Point returns the pair as an [xgal.Point].
+func (p Pair) Point() xgal.Point {
+	return xgal.Point(p)
+}
+
+func (p Pair) String() string {
+	if p.X == p.Y {
+		return strconv.Itoa(p.X)
+	}
+	return fmt.Sprintf("%d,%d", p.X, p.Y)
+}
+
+func (p Pair) MarshalText() (text []byte, err error) {
+	return []byte(p.String()), nil
+}
+
+func (p *Pair) UnmarshalText(text []byte) error {
+	xs, ys, ok := strings.Cut(string(text), ",")
+	if !ok {
+		ys = xs
+	}
+	x, errx := strconv.Atoi(strings.TrimSpace(xs))
+	y, erry := strconv.Atoi(strings.TrimSpace(ys))
+	if errx != nil || erry != nil {
+		return errors.New("Pair must be x,y or n: " + string(text))
+	}
+	*p = Pair(xgal.Pt(x, y))
+	return nil
+}
+
+// Look is how a widget looks in a theme. Attributes that are left out
+// keep the value of the look it is applied to.
+type Look struct {
+	Fore     *Color `xml:"fore,attr,omitempty"`     // Fore is the color of text.
+	Border   *Color `xml:"border,attr,omitempty"`   // Border is the color of the outline.
+	Shadow   *Color `xml:"shadow,attr,omitempty"`   // Shadow is the color of the shadow.
+	Fill     *Color `xml:"fill,attr,omitempty"`     // Fill is the color of the background.
+	Stroke   *int   `xml:"stroke,attr,omitempty"`   // Stroke is the width of the outline.
+	Gloom    *int   `xml:"gloom,attr,omitempty"`    // Gloom is the width of the shadow.
+	Diameter *int   `xml:"diameter,attr,omitempty"` // Diameter of slider knobs and bars.
+	Margin   *Pair  `xml:"margin,attr,omitempty"`   // Margin around the contents.
+	Shade    *Pair  `xml:"shade,attr,omitempty"`    // Shade is the direction of the shadow.
+	Offset   *Pair  `xml:"offset,attr,omitempty"`   // Offset of the contents, as for pressed buttons.
+	Font     string `xml:"font,attr,omitempty"`     // Font is a registered face or a font file.
+	Frame    string `xml:"frame,attr,omitempty"`    // Frame is a nine-slice image or xvec file, or none.
+	Slice    *int   `xml:"slice,attr,omitempty"`    // Slice is the inset of the nine slices of the frame.
+
+	Face    xgal.Face     `xml:"-"` // Face of the font if loaded.
+	Texture *xgal.Surface `xml:"-"` // Texture of the frame if loaded, nil for none.
+}
+
+// override sets dst to src if src is set.
+func override[T any](dst **T, src *T) {
+	if src != nil {
+		*dst = src
+	}
+}
+
+// Merge returns the look with the attributes that are set in over
+// replaced by those of over.
+func (l Look) Merge(over Look) Look {
+	override(&l.Fore, over.Fore)
+	override(&l.Border, over.Border)
+	override(&l.Shadow, over.Shadow)
+	override(&l.Fill, over.Fill)
+	override(&l.Stroke, over.Stroke)
+	override(&l.Gloom, over.Gloom)
+	override(&l.Diameter, over.Diameter)
+	override(&l.Margin, over.Margin)
+	override(&l.Shade, over.Shade)
+	override(&l.Offset, over.Offset)
+	override(&l.Slice, over.Slice)
+	if over.Font != "" {
+		l.Font, l.Face = over.Font, over.Face
+	}
+	if over.Frame != "" {
+		l.Frame, l.Texture = over.Frame, over.Texture
+	}
+	return l
+}
+
+// State is the look of a style in a state of a widget, such as focused,
+// hovered, clicked, dragged or active.
+type State struct {
+	Name string `xml:"name,attr"`
+	Look
+}
+
+// Style is the look of a kind of widget, such as default, button, menu,
+// bar, check, knob, hud or error, with the looks of its states.
+type Style struct {
+	Name   string  `xml:"name,attr"`
+	Base   string  `xml:"base,attr,omitempty"` // Base style, default if empty.
+	Look           // Look of the style.
+	States []State `xml:"state"` // States of the style.
+}
+
+// State returns the state with the name, or nil if there is none.
+func (s *Style) State(name string) *State {
+	for i := range s.States {
+		if s.States[i].Name == name {
+			return &s.States[i]
+		}
+	}
+	return nil
+}
+
+// Theme is a named set of styles that the UI toolkits draw widgets with:
+//
+//	<theme name="night">
+//		<style name="default" fore="#ffffff" fill="#000055aa" margin="2" font="small">
+//			<state name="focused" border="#f08c28"/>
+//		</style>
+//		<style name="button" fill="#0000aa" frame="pack/image/ui/frame.xvec" slice="5"/>
+//	</theme>
+//
+// A style builds on its base style, or on the default style if it has
+// none. The toolkits apply the looks of a theme over their built in
+// styles, so a theme only needs the attributes it changes.
+type Theme struct {
+	XMLName xml.Name `xml:"theme"`
+	Name    string   `xml:"name,attr"`
+	Styles  []*Style `xml:"style"`
+}
+
+// Find returns the style with the given name, or nil if not found.
+func (t *Theme) Find(name string) *Style {
+	for _, style := range t.Styles {
+		if style.Name == name {
+			return style
+		}
+	}
+	return nil
+}
+
+// chain returns the style with the name and its bases, base first. It
+// stops at a loop of bases.
+func (t *Theme) chain(name string) []*Style {
+	var chain []*Style
+	for name != "" {
+		style := t.Find(name)
+		if style == nil || slices.Contains(chain, style) {
+			break
+		}
+		chain = slices.Insert(chain, 0, style)
+		if name = style.Base; name == "" && style.Name != DefaultStyle {
+			name = DefaultStyle
+		}
+	}
+	return chain
+}
+
+// Look returns the look of the style with the name in the state, or
+// its plain look if state is empty. The plain look merges the looks of
+// the bases of the style. The look of a state merges the looks of that
+// state of the bases only, so a widget keeps its own look apart from
+// what the state changes. A style that is not in the theme has an empty
+// plain look, and the states of the default style.
+func (t *Theme) Look(name, state string) Look {
+	chain := t.chain(name)
+	if len(chain) == 0 {
+		if state == "" {
+			return Look{}
+		}
+		chain = t.chain(DefaultStyle)
+	}
+	look := Look{}
+	for _, style := range chain {
+		if state == "" {
+			look = look.Merge(style.Look)
+		} else if st := style.State(state); st != nil {
+			look = look.Merge(st.Look)
+		}
+	}
+	return look
+}
+
+// looks returns pointers to all looks of the theme.
+func (t *Theme) looks() []*Look {
+	var looks []*Look
+	for _, style := range t.Styles {
+		looks = append(looks, &style.Look)
+		for i := range style.States {
+			looks = append(looks, &style.States[i].Look)
+		}
+	}
+	return looks
+}
+
+// loadFrame loads a nine-slice frame from an image or an xvec file.
+func loadFrame(fsys fs.FS, name string) (*xgal.Surface, error) {
+	if !strings.EqualFold(path.Ext(name), ".xvec") {
+		return xgal.Texture(fsys, name)
+	}
+	vec, err := xvec.ParseFS(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	frame := xgal.Prepare(max(int(vec.Size.W), 1), max(int(vec.Size.H), 1))
+	vec.Draw(frame)
+	return frame, nil
+}
+
+// loadAssets loads the fonts and frames of the looks of the theme. Fonts
+// are looked up by name in [fontres] first.
+func (t *Theme) loadAssets(fsys fs.FS) error {
+	var errs []error
+	frames := map[string]*xgal.Surface{}
+	for _, look := range t.looks() {
+		if look.Font != "" {
+			face, err := fontres.Open(fsys, look.Font)
+			if err != nil {
+				errs = append(errs, err)
+			}
+			look.Face = face
+		}
+		if look.Frame == "" || look.Frame == NoFrame {
+			continue
+		}
+		frame, ok := frames[look.Frame]
+		if !ok {
+			var err error
+			if frame, err = loadFrame(fsys, look.Frame); err != nil {
+				errs = append(errs, err)
+			}
+			frames[look.Frame] = frame
+		}
+		look.Texture = frame
+	}
+	return errors.Join(errs...)
+}
+
+func (t Theme) SaveTo(wr io.Writer) error {
+	enc := xml.NewEncoder(wr)
+	enc.Indent("", " ")
+	return enc.Encode(t)
+}
+
+func (t Theme) SaveFile(name string) error {
+	out, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	return t.SaveTo(out)
+}
+
+// ReadTheme reads a theme from XML without loading its fonts and frames.
+func ReadTheme(rd io.Reader) (*Theme, error) {
+	theme := &Theme{}
+	if err := xml.NewDecoder(rd).Decode(theme); err != nil {
+		return nil, err
+	}
+	return theme, nil
+}
+
+// LoadTheme loads a theme and its fonts and frames from fsys. If a font
+// or frame fails to load, the theme is returned with the error, and the
+// looks that use it keep the font or frame they are applied to.
+func LoadTheme(fsys fs.FS, name string) (*Theme, error) {
+	fin, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer fin.Close()
+	theme, err := ReadTheme(fin)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	if theme.Name == "" {
+		theme.Name = strings.TrimSuffix(path.Base(name), path.Ext(name))
+	}
+	return theme, theme.loadAssets(fsys)
+}
+
+// LoadThemes loads all themes in dir of fsys, sorted by name.
+func LoadThemes(fsys fs.FS, dir string) ([]*Theme, error) {
+	names, err := fs.Glob(fsys, path.Join(dir, "*.xml"))
+	if err != nil {
+		return nil, err
+	}
+	var themes []*Theme
+	var errs []error
+	for _, name := range names {
+		theme, err := LoadTheme(fsys, name)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		if theme != nil {
+			themes = append(themes, theme)
+		}
+	}
+	slices.SortFunc(themes, func(a, b *Theme) int { return strings.Compare(a.Name, b.Name) })
+	return themes, errors.Join(errs...)
+}
+
+// FindTheme returns the theme with the name, or nil if there is none.
+func FindTheme(themes []*Theme, name string) *Theme {
+	for _, theme := range themes {
+		if theme.Name == name {
+			return theme
+		}
+	}
+	return nil
+}
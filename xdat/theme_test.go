@@ -0,0 +1,131 @@
+package xdat
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/xmasengine/xmas/xgal"
+)
+
+const testThemeXML = `<theme name="test">
+ <style name="default" fore="#ffffff" fill="#000055aa" margin="2" stroke="1">
+  <state name="focused" border="#f08c28"/>
+  <state name="hovered" border="#f0f032"/>
+ </style>
+ <style name="button" fill="#0000aa" margin="2,0">
+  <state name="focused" fill="#8080c8"/>
+ </style>
+ <style name="big" base="button" font="medium" frame="none"/>
+ <style name="loop" base="loop" stroke="3"/>
+</theme>`
+
+func TestPair(t *testing.T) {
+	for text, want := range map[string]xgal.Point{
+		"2":     xgal.Pt(2, 2),
+		"2,0":   xgal.Pt(2, 0),
+		"-1, 3": xgal.Pt(-1, 3),
+	} {
+		var p Pair
+		if err := p.UnmarshalText([]byte(text)); err != nil || p.Point() != want {
+			t.Errorf("%q: %v, %v", text, p, err)
+		}
+	}
+	var p Pair
+	if err := p.UnmarshalText([]byte("x")); err == nil {
+		t.Errorf("no error for x")
+	}
+	if s := Pair(xgal.Pt(2, 0)).String(); s != "2,0" {
+		t.Errorf("String: %s", s)
+	}
+}
+
+func TestThemeLook(t *testing.T) {
+	theme, err := ReadTheme(strings.NewReader(testThemeXML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	look := theme.Look("big", "")
+	if look.Fill == nil || look.Fill.RGBA() != xgal.Paint(0, 0, 0xaa, 0xff) {
+		t.Errorf("big fill: %v", look.Fill)
+	}
+	if look.Fore == nil || look.Fore.RGBA() != xgal.White {
+		t.Errorf("big fore: %v", look.Fore)
+	}
+	if look.Margin == nil || look.Margin.Point() != xgal.Pt(2, 0) || look.Font != "medium" || look.Frame != NoFrame {
+		t.Errorf("big: %v %q %q", look.Margin, look.Font, look.Frame)
+	}
+
+	focused := theme.Look("big", "focused")
+	if focused.Border == nil || focused.Fill == nil || focused.Fill.RGBA() != xgal.Paint(0x80, 0x80, 0xc8, 0xff) {
+		t.Errorf("big focused: %v %v", focused.Border, focused.Fill)
+	}
+	if focused.Margin != nil {
+		t.Errorf("focused look has the plain margin")
+	}
+
+	if unknown := theme.Look("unknown", ""); unknown.Fill != nil {
+		t.Errorf("unknown style has a look")
+	}
+	if hovered := theme.Look("unknown", "hovered"); hovered.Border == nil {
+		t.Errorf("unknown style lacks the hovered state of default")
+	}
+	if loop := theme.Look("loop", ""); loop.Stroke == nil || *loop.Stroke != 3 {
+		t.Errorf("loop: %v", loop.Stroke)
+	}
+}
+
+func TestThemeSave(t *testing.T) {
+	theme, err := ReadTheme(strings.NewReader(testThemeXML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := &bytes.Buffer{}
+	if err := theme.SaveTo(buf); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "shadow") {
+		t.Errorf("unset attributes saved:\n%s", buf)
+	}
+	again, err := ReadTheme(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(again.Styles) != len(theme.Styles) || again.Look("button", "").Margin.Point() != xgal.Pt(2, 0) {
+		t.Errorf("round trip changed the theme")
+	}
+}
+
+func TestLoadThemes(t *testing.T) {
+	fsys := fstest.MapFS{
+		"theme/test.xml":    {Data: []byte(testThemeXML)},
+		"theme/missing.xml": {Data: []byte(`<theme><style name="default" frame="none.png"/></theme>`)},
+	}
+	themes, err := LoadThemes(fsys, "theme")
+	if err == nil {
+		t.Errorf("no error for a missing frame")
+	}
+	if len(themes) != 2 || themes[0].Name != "missing" || FindTheme(themes, "test") == nil {
+		t.Fatalf("themes: %v", themes)
+	}
+	if face := FindTheme(themes, "test").Look("big", "").Face; face == nil {
+		t.Errorf("font not loaded")
+	}
+
+	themes, err = LoadThemes(os.DirFS(".."), ThemeDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if FindTheme(themes, DefaultTheme) == nil {
+		t.Errorf("no default theme in %s", ThemeDir)
+	}
+	contrast := FindTheme(themes, ContrastTheme)
+	if contrast == nil {
+		t.Fatalf("no contrast theme in %s", ThemeDir)
+	}
+	if look := contrast.Look("button", "focused"); look.Fill == nil || look.Fore == nil || *look.Fill == *look.Fore {
+		t.Errorf("focused button of contrast theme: %+v", look)
+	}
+}
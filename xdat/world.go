@@ -2,9 +2,11 @@ package xdat
 
 import (
 	"encoding/xml"
+	"errors"
 	"io"
 	"io/fs"
 	"os"
+	"strings"
 )
 
 import (
@@ -20,6 +22,36 @@ const (
 	West
 )
 
+// AnyDirection is the direction of animation clips that fit any direction.
+const AnyDirection Direction = -1
+
+var directionNames = []string{"north", "east", "south", "west"}
+
+func (d Direction) String() string {
+	if d >= North && d <= West {
+		return directionNames[d]
+	}
+	return "any"
+}
+
+// ParseDirection parses the name of a direction. It also accepts up,
+// right, down and left, and returns AnyDirection for "" and any.
+func ParseDirection(name string) (Direction, error) {
+	switch strings.ToLower(name) {
+	case "", "any":
+		return AnyDirection, nil
+	case "north", "up":
+		return North, nil
+	case "east", "right":
+		return East, nil
+	case "south", "down":
+		return South, nil
+	case "west", "left":
+		return West, nil
+	}
+	return AnyDirection, errors.New("unknown direction " + name)
+}
+
 type Pose int
 
 const PoseInterval = 2
@@ -32,31 +64,69 @@ type Player struct {
 	Texture        *xgal.Surface `xml:"-"`                   // The fortarit texture for this player if loaded.
 	Sprite         *xgal.Surface `xml:"-"`                   // Sub graph to draw the player's sprite from.
 
+	At    xgal.Point     `xml:"-"` // At is where the hotspot of the player is in the zone.
 	Bound xgal.Rectangle `xml:"-"` // Bound is the rendering bound (where to draw).
 	Hit   xgal.Rectangle `xml:"-"` // Hit is the hit box (where the player "is").
 
 	Direction Direction `xml:"-"`
 	Pose      Pose      `xml:"-"`
 
+	Anim     string   `xml:"anim,attr,omitempty"` // Anim is the file with the animations of the player's sprite.
+	Animator Animator `xml:"-"`                   // Animator plays the animations of the player.
+
 	Depth uint16 `xml:"-"` // Depth is layer the player is "on".
+
+	Indexing // Indexing allows color variants of the player.
 }
 
 func (p *Player) loadTexture(fsys fs.FS) error {
+	if p.Anim != "" {
+		anims, err := LoadAnimations(fsys, p.Anim)
+		if err != nil {
+			return err
+		}
+		p.Animator = Animator{Set: anims}
+		if p.Source == "" {
+			p.Source = anims.Source
+		}
+	}
 	if p.Source == "" {
 		return nil
 	}
 
-	texture, err := xgal.Texture(fsys, p.Source)
+	texture, err := p.load(fsys, p.Source)
 	if err != nil {
 		return err
 	}
-	if p.Texture != nil {
-		p.Texture.Deallocate()
-	}
 	p.Texture = texture
 	return nil
 }
 
+// Walk moves the player by delta, turns it to face the move, and plays
+// its walk clip, or its stand clip if delta is zero. It advances the
+// animator by one tick and updates Bound and Hit, so call it every tick.
+func (p *Player) Walk(delta xgal.Point) {
+	action := "stand"
+	if delta != (xgal.Point{}) {
+		action = "walk"
+		switch {
+		case delta.X < 0:
+			p.Direction = West
+		case delta.X > 0:
+			p.Direction = East
+		case delta.Y < 0:
+			p.Direction = North
+		default:
+			p.Direction = South
+		}
+	}
+	p.At = p.At.Add(delta)
+	p.Animator.Play(action, p.Direction)
+	p.Animator.Update()
+	p.Bound = p.Animator.Bounds(p.At)
+	p.Hit = p.Animator.Hitbox(p.At)
+}
+
 type World struct {
 	XMLName   xml.Name  `xml:"world"`
 	Name      string    `xml:"name,attr"`           // Name of the world.
@@ -13,6 +13,7 @@ import (
 	"os"
 	"slices"
 	"strconv"
+	"strings"
 )
 
 import (
@@ -42,19 +43,23 @@ func (t *Flag) Toggle(f Flag) Flag {
 	return *t
 }
 
+// Rotate rotates the orientation of the flags 90 degrees clockwise,
+// taking any flips into account.
 func (t *Flag) Rotate() Flag {
-	switch {
-	case t.Has(FlagRotate90):
-		t.Toggle(FlagRotate90)
-		return t.Set(FlagRotate180)
-	case t.Has(FlagRotate180):
-		t.Toggle(FlagRotate180)
-		return t.Set(FlagRotate270)
-	case t.Has(FlagRotate270):
-		return t.Toggle(FlagRotate270)
-	default:
-		return t.Set(FlagRotate90)
-	}
+	*t = t.Orient(t.Orientation().Rotate())
+	return *t
+}
+
+// FlipH flips the orientation of the flags horizontally.
+func (t *Flag) FlipH() Flag {
+	*t = t.Orient(t.Orientation().FlipH())
+	return *t
+}
+
+// FlipV flips the orientation of the flags vertically.
+func (t *Flag) FlipV() Flag {
+	*t = t.Orient(t.Orientation().FlipV())
+	return *t
 }
 
 const (
@@ -67,8 +72,14 @@ const (
 	FlagRotate90
 	FlagRotate180
 	FlagRotate270
+	FlagAnimate
 )
 
+// TileFrames is how many frames animated tiles have. The frames of a tile
+// with FlagAnimate are the tile and the cells to the right of it in the
+// texture.
+const TileFrames = 4
+
 func (f Flag) Buffer() *bytes.Buffer {
 	b := bytes.Buffer{}
 	if f.Has(FlagSpecial) {
@@ -98,6 +109,9 @@ func (f Flag) Buffer() *bytes.Buffer {
 	if f.Has(FlagHarm) {
 		b.WriteRune('h')
 	}
+	if f.Has(FlagAnimate) {
+		b.WriteRune('A')
+	}
 	return &b
 }
 
@@ -139,6 +153,8 @@ func (f *Flag) UnmarshalText(text []byte) error {
 			v |= FlagBless
 		case 'h':
 			v |= FlagHarm
+		case 'A':
+			v |= FlagAnimate
 		default:
 			return errors.New("Unknown character in Flag " + string(text))
 		}
@@ -157,6 +173,15 @@ type Tile struct {
 	Flag
 }
 
+// Frame returns the tile as it shows at the animation frame: a tile with
+// FlagAnimate moves to the right in the texture, others stay.
+func (t Tile) Frame(frame int) Tile {
+	if t.Has(FlagAnimate) {
+		t.X += uint8(frame % TileFrames)
+	}
+	return t
+}
+
 func (t Tile) ToUint32() uint32 {
 	return uint32(t.X) + uint32(t.Y)<<8 + uint32(t.Flag)<<16
 }
@@ -180,6 +205,8 @@ const ThingSprites = 16
 type Row []Tile
 type Tiles struct {
 	Rows []Row
+
+	changed xgal.Rectangle // changed are the tiles Set since TakeChanged.
 }
 
 func (t *Tiles) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
@@ -306,9 +333,19 @@ func (t *Tiles) Set(at xgal.Point, cell Tile) bool {
 		return false
 	}
 	t.Rows[at.Y][at.X] = cell
+	t.changed = t.changed.Union(xgal.Bound(at.X, at.Y, 1, 1))
 	return true
 }
 
+// TakeChanged returns the rectangle of the tiles that were Set since the
+// last call, or an empty rectangle if none were, so caches of the tiles
+// can be updated.
+func (t *Tiles) TakeChanged() xgal.Rectangle {
+	r := t.changed
+	t.changed = xgal.Rectangle{}
+	return r
+}
+
 func (t Tiles) Get(at xgal.Point) Tile {
 	if !t.Contains(at.X, at.Y) {
 		return Tile{}
@@ -325,6 +362,7 @@ type Layer struct {
 	Source     string        `xml:"src,attr"` // Source file name to load the Layer Texture from.
 	Tiles      Tiles         `xml:"tiles"`    // Tiles
 	Texture    *xgal.Surface `xml:"-"`        // The tile texture for this layer if loaded.
+	Indexing
 }
 
 // NewLayer allocates a layer with the default size and tile size.
@@ -348,13 +386,10 @@ func NewLayerWith(w, h, tw, th int) *Layer {
 }
 
 func (l *Layer) SetSource(fsys fs.FS, src string) error {
-	texture, err := xgal.Texture(fsys, src)
+	texture, err := l.load(fsys, src)
 	if err != nil {
 		return err
 	}
-	if l.Texture != nil {
-		l.Texture.Deallocate()
-	}
 	l.Texture = texture
 	l.Source = src
 	return nil
@@ -365,13 +400,10 @@ func (l *Layer) loadTexture(fsys fs.FS) error {
 		return nil
 	}
 
-	texture, err := xgal.Texture(fsys, l.Source)
+	texture, err := l.load(fsys, l.Source)
 	if err != nil {
 		return err
 	}
-	if l.Texture != nil {
-		l.Texture.Deallocate()
-	}
 	l.Texture = texture
 	return nil
 }
@@ -432,20 +464,72 @@ type Thing struct {
 	Kind       Kind
 	Talk       string
 	Sprites    [ThingSprites]uint16
-	Depth      uint16        `xml:"z,attr"`   // Depth is the depth position of the layer
-	Width      uint16        `xml:"w,attr"`   // Width is the width expressed in tiles.
-	Height     uint16        `xml:"h,attr"`   // Height is the height expressed in tiles.
-	TileWidth  uint16        `xml:"tw,attr"`  // TileWidth is the width of the tiles in this layer.
-	TileHeight uint16        `xml:"th,attr"`  // TileHeight is the height of the thiles in this layer.
-	Source     string        `xml:"src,attr"` // Source file name to load the Layer's Texture from.
-	Texture    *xgal.Surface `xml:"-"`        // The tile texture for this Thing if loaded.
+	Depth      uint16        `xml:"z,attr"`                // Depth is the depth position of the layer
+	Width      uint16        `xml:"w,attr"`                // Width is the width expressed in tiles.
+	Height     uint16        `xml:"h,attr"`                // Height is the height expressed in tiles.
+	TileWidth  uint16        `xml:"tw,attr"`               // TileWidth is the width of the tiles in this layer.
+	TileHeight uint16        `xml:"th,attr"`               // TileHeight is the height of the thiles in this layer.
+	Source     string        `xml:"src,attr"`              // Source file name to load the Layer's Texture from.
+	Texture    *xgal.Surface `xml:"-"`                     // The tile texture for this Thing if loaded.
+	Anim       string        `xml:"anim,attr,omitempty"`   // Anim is the file with the animations of the Thing.
+	Animator   Animator      `xml:"-"`                     // Animator plays the animations of the Thing.
+	X          int           `xml:"x,attr"`                // X of the hotspot of the Thing in pixels.
+	Y          int           `xml:"y,attr"`                // Y of the hotspot of the Thing in pixels.
+	Action     string        `xml:"action,attr,omitempty"` // Action is the clip the Thing plays, stand if empty.
+	Facing     string        `xml:"dir,attr,omitempty"`    // Facing is the direction of the clip, any if empty.
+
+	Indexing // Indexing allows color variants of the Thing.
+}
+
+// At returns the position of the hotspot of the thing in pixels.
+func (t *Thing) At() xgal.Point {
+	return xgal.Pt(t.X, t.Y)
+}
+
+// loadTexture loads the animations and the texture of the thing, and
+// starts playing its action.
+func (t *Thing) loadTexture(fsys fs.FS) error {
+	if t.Anim != "" {
+		anims, err := LoadAnimations(fsys, t.Anim)
+		if err != nil {
+			return err
+		}
+		dir, err := ParseDirection(t.Facing)
+		if err != nil {
+			return err
+		}
+		action := t.Action
+		if action == "" {
+			action = "stand"
+		}
+		t.Animator = Animator{Set: anims}
+		t.Animator.Play(action, dir)
+		if t.Source == "" {
+			t.Source = anims.Source
+		}
+	}
+	if t.Source == "" {
+		return nil
+	}
+
+	texture, err := t.load(fsys, t.Source)
+	if err != nil {
+		return err
+	}
+	t.Texture = texture
+	return nil
 }
 
 type Zone struct {
-	XMLName xml.Name `xml:"zone"`
-	Name    string   `xml:"name,attr"`
-	Layers  []*Layer `xml:"layer"`
-	Talks   []Talk   `xml:"talk"`
+	XMLName  xml.Name  `xml:"zone"`
+	Name     string    `xml:"name,attr"`
+	Ambient  Color     `xml:"ambient,attr"`           // Ambient light color, unset is full daylight.
+	Outside  bool      `xml:"outside,attr,omitempty"` // Outside zones are lit by the world clock.
+	Layers   []*Layer  `xml:"layer"`
+	Lights   []Light   `xml:"light"`
+	Emitters []Emitter `xml:"emitter"`
+	Talks    []Talk    `xml:"talk"`
+	Things   []*Thing  `xml:"thing"`
 }
 
 func NewZone(name string) *Zone {
@@ -491,6 +575,16 @@ func (z *Zone) loadLayerTextures(fsys fs.FS) error {
 	return nil
 }
 
+func (z *Zone) loadThingTextures(fsys fs.FS) error {
+	for _, thing := range z.Things {
+		err := thing.loadTexture(fsys)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func LoadZone(fsys fs.FS, name string) (*Zone, error) {
 	fin, err := fsys.Open(name)
 	if err != nil {
@@ -505,6 +599,10 @@ func LoadZone(fsys fs.FS, name string) (*Zone, error) {
 	if err != nil {
 		return nil, err
 	}
+	err = zone.loadThingTextures(fsys)
+	if err != nil {
+		return nil, err
+	}
 
 	if zone.Layers[0].Texture == nil {
 		println("texture missing")
@@ -515,45 +613,73 @@ func LoadZone(fsys fs.FS, name string) (*Zone, error) {
 
 // Talk is a dialog
 type Talk struct {
-	Name  string    `xml:"name,attr"` // identifying name
-	Speak []Speaker `xml:"speak"`
-}
-
-type Speaker interface {
-	Speak() string
-}
-
-// Say is a single speech expression or question
-type Say struct {
-	When string `xml:"expr,attr,omitempty"` // expression with condition
-	Who  string `xml:"who,attr"`            // who is speaking
-	Say  string `xml:"say"`
-}
-
-func (s Say) Speak() string {
-	return s.Say
+	Name  string   `xml:"name,attr"` // identifying name
+	Speak []Speech `xml:"speak"`
 }
 
-// Ask is a speech question with multiple answers
-type Ask struct {
-	When    string `xml:"expr,attr,omitempty"` // expression with condition
-	Who     string `xml:"name,attr"`           // who is speaking
-	Ask     string `xml:"ask"`
-	Replies []Reply
+// Speech is a turn of a talk: a line that is said, or a question that is
+// asked with its replies.
+type Speech struct {
+	Who     string  `xml:"who,attr,omitempty"`  // Who is speaking.
+	When    string  `xml:"when,attr,omitempty"` // When is an expression with the condition of the speech.
+	Say     string  `xml:"say,omitempty"`
+	Ask     string  `xml:"ask,omitempty"`
+	Replies []Reply `xml:"reply"`
 }
 
-func (a Ask) Speak() string {
-	return a.Ask
+// Reply is a reply to the question of a speech.
+type Reply struct {
+	When  string `xml:"when,attr,omitempty"` // When is an expression with the condition of the reply.
+	Expr  string `xml:"expr,attr,omitempty"` // Expr is an expression with the value of the reply.
+	Reply string `xml:",chardata"`
+}
+
+// TalkLine is a text of a talk with who speaks it.
+type TalkLine struct {
+	Entry
+	Who string // Who speaks the line.
+}
+
+// Lines returns the says, asks and replies of the talk keyed with
+// [TalkKey], so they can be translated with the string tables when they
+// are shown. Of every speech the say comes first, then the ask and then
+// the replies, and empty texts are skipped. [Extract] keys the texts the
+// same way.
+func (t Talk) Lines() []TalkLine {
+	var lines []TalkLine
+	add := func(who, text string) {
+		if text = strings.TrimSpace(text); text != "" {
+			lines = append(lines, TalkLine{Entry: Entry{ID: TalkKey(t.Name, len(lines)), Text: text}, Who: who})
+		}
+	}
+	for _, speech := range t.Speak {
+		add(speech.Who, speech.Say)
+		add(speech.Who, speech.Ask)
+		for _, reply := range speech.Replies {
+			add("", reply.Reply)
+		}
+	}
+	return lines
 }
 
-type Reply struct {
-	When  string `xml:"when,attr,omitempty"` // expression with condition of reply
-	Expr  string `xml:"expr,attr,omitempty"` // expression with value of reply
-	Reply string `xml:"reply"`
+// Entries returns the entries of the [Talk.Lines] of the talk.
+func (t Talk) Entries() []Entry {
+	var entries []Entry
+	for _, line := range t.Lines() {
+		entries = append(entries, line.Entry)
+	}
+	return entries
 }
 
-func (r Reply) Speak() string {
-	return r.Reply
+// FindTalk returns the talk of the zone with the name, or nil if there is
+// none.
+func (z *Zone) FindTalk(name string) *Talk {
+	for i := range z.Talks {
+		if z.Talks[i].Name == name {
+			return &z.Talks[i]
+		}
+	}
+	return nil
 }
 
 // If can be used for simple scripting with expressions.
@@ -0,0 +1,153 @@
+package xeng
+
+import (
+	"github.com/xmasengine/xmas/xdat"
+	"github.com/xmasengine/xmas/xgal"
+)
+
+// ChunkSize is the width and height of a chunk, expressed in tiles.
+const ChunkSize = 16
+
+// Chunk is a square region of a layer that is rendered to an off screen
+// surface, so it can be drawn with a single blit.
+type Chunk struct {
+	Surface  *xgal.Surface // Surface with the rendered tiles, nil if not yet.
+	Dirty    bool          // Dirty is set if the tiles must be rendered again.
+	Animated bool          // Animated is set if some of the tiles are animated.
+}
+
+// Chunks caches the chunks of a single layer. The chunks are rendered
+// lazily when they become visible, and only rendered again after they
+// were invalidated, the layer texture changed, or the animation
+// frame advanced for chunks with animated tiles.
+type Chunks struct {
+	Layer   *xdat.Layer   // Layer the chunks are rendered from.
+	Index   int           // Index of the layer in the zone.
+	Texture *xgal.Surface // Texture the chunks were rendered with.
+	Frame   int           // Animation frame the chunks were rendered at.
+	Width   int           // Width is the width expressed in chunks.
+	Height  int           // Height is the height expressed in chunks.
+	Chunks  []Chunk       // Chunks in row major order.
+}
+
+// NewChunks allocates an empty chunk cache for the layer at index.
+func NewChunks(layer *xdat.Layer, index int) *Chunks {
+	c := &Chunks{Layer: layer, Index: index}
+	if layer == nil {
+		return c
+	}
+	c.Height = (len(layer.Tiles.Rows) + ChunkSize - 1) / ChunkSize
+	for _, row := range layer.Tiles.Rows {
+		c.Width = max(c.Width, (len(row)+ChunkSize-1)/ChunkSize)
+	}
+	c.Chunks = make([]Chunk, c.Width*c.Height)
+	return c
+}
+
+// Chunk returns the chunk at chunk coordinates cx, cy or nil if out of range.
+func (c *Chunks) Chunk(cx, cy int) *Chunk {
+	if cx < 0 || cy < 0 || cx >= c.Width || cy >= c.Height {
+		return nil
+	}
+	return &c.Chunks[cy*c.Width+cx]
+}
+
+// Invalidate marks all chunks that overlap the rectangle r, expressed in
+// tiles, as dirty.
+func (c *Chunks) Invalidate(r xgal.Rectangle) {
+	r = r.Canon()
+	if r.Empty() {
+		return
+	}
+	for cy := r.Min.Y / ChunkSize; cy <= (r.Max.Y-1)/ChunkSize; cy++ {
+		for cx := r.Min.X / ChunkSize; cx <= (r.Max.X-1)/ChunkSize; cx++ {
+			if chunk := c.Chunk(cx, cy); chunk != nil {
+				chunk.Dirty = true
+			}
+		}
+	}
+}
+
+// InvalidateAll marks all chunks as dirty.
+func (c *Chunks) InvalidateAll() {
+	for i := range c.Chunks {
+		c.Chunks[i].Dirty = true
+	}
+}
+
+// SetFrame sets the animation frame. If it changed, the chunks with
+// animated tiles are invalidated.
+func (c *Chunks) SetFrame(frame int) {
+	if frame == c.Frame {
+		return
+	}
+	c.Frame = frame
+	for i := range c.Chunks {
+		if c.Chunks[i].Animated {
+			c.Chunks[i].Dirty = true
+		}
+	}
+}
+
+// Deallocate releases the chunk surfaces.
+func (c *Chunks) Deallocate() {
+	for i := range c.Chunks {
+		if c.Chunks[i].Surface != nil {
+			c.Chunks[i].Surface.Deallocate()
+			c.Chunks[i].Surface = nil
+		}
+	}
+}
+
+// chunkSize returns the size of a chunk in pixels.
+func (c *Chunks) chunkSize() xgal.Point {
+	return xgal.Pt(ChunkSize*c.Layer.TileWidth, ChunkSize*c.Layer.TileHeight)
+}
+
+// renderChunk renders the tiles of the chunk at cx, cy to its surface.
+func (c *Chunks) renderChunk(chunk *Chunk, cx, cy int) {
+	size := c.chunkSize()
+	if chunk.Surface == nil {
+		chunk.Surface = xgal.Prepare(size.X, size.Y)
+	} else {
+		chunk.Surface.Clear()
+	}
+	tiles := xgal.Bound(cx*ChunkSize, cy*ChunkSize, ChunkSize, ChunkSize)
+	origin := xgal.Pt(cx*size.X, cy*size.Y)
+	chunk.Animated = renderTiles(chunk.Surface, c.Layer, c.Index, tiles, origin, c.Frame)
+	chunk.Dirty = false
+}
+
+// Render draws the chunks that are visible through the camera onto screen,
+// rendering the chunks that are dirty or not rendered yet first.
+func (c *Chunks) Render(screen *xgal.Surface, camera xgal.Rectangle) {
+	m := c.Layer
+	if m == nil || m.Texture == nil || m.TileWidth <= 0 || m.TileHeight <= 0 {
+		// Can't draw if there is no texture loaded.
+		return
+	}
+	if m.Texture != c.Texture {
+		// The tile size may have changed with the texture, so drop
+		// the surfaces rather than only invalidating them.
+		c.Texture = m.Texture
+		c.Deallocate()
+	}
+
+	size := c.chunkSize()
+	startx := max(camera.Min.X/size.X, 0)
+	starty := max(camera.Min.Y/size.Y, 0)
+	endx := min(1+camera.Max.X/size.X, c.Width)
+	endy := min(1+camera.Max.Y/size.Y, c.Height)
+
+	for cy := starty; cy < endy; cy++ {
+		for cx := startx; cx < endx; cx++ {
+			chunk := c.Chunk(cx, cy)
+			if chunk.Surface == nil || chunk.Dirty {
+				c.renderChunk(chunk, cx, cy)
+			}
+			at := xgal.Pt(cx*size.X, cy*size.Y).Sub(camera.Min)
+			to := xgal.Rectangle{Min: at, Max: at.Add(size)}
+			xgal.Blit(screen, chunk.Surface, to, chunk.Surface.Bounds())
+		}
+	}
+}
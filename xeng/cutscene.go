@@ -0,0 +1,280 @@
+package xeng
+
+import (
+	"log/slog"
+	"path"
+	"strings"
+	"time"
+)
+
+import (
+	"github.com/xmasengine/xmas/xdat"
+	"github.com/xmasengine/xmas/xgal"
+	"github.com/xmasengine/xmas/xlui"
+)
+
+// CutsceneDir is the directory with the cutscene videos and subtitles.
+const CutsceneDir = "pack/video"
+
+// SkipHold is how many ticks confirm must be held to skip a cutscene.
+const SkipHold = 60
+
+// SkipPrompt is how many ticks the skip prompt shows after confirm is
+// pressed once.
+const SkipPrompt = 120
+
+// Cutscene is the timeline of a cutscene. It shows the subtitles at the
+// time of its video, and ends when the video ends, or when it is skipped
+// by pressing confirm twice or by holding it. It does not decode the video
+// itself, so it can be driven by any clock.
+type Cutscene struct {
+	Subtitles *xdat.Subtitles    // Subtitles to show, may be nil.
+	Hold      int                // Hold is how many ticks to hold confirm to skip, 0 to disable.
+	Prompt    int                // Prompt is how many ticks the skip prompt shows.
+	End       func(skipped bool) // End is called once when the cutscene is over.
+	Time      time.Duration      // Time is the time of the video.
+	held      int
+	prompt    int
+	over      bool
+	said      int // said is how many cues were announced or passed.
+}
+
+// NewCutscene returns a cutscene with the subtitles that calls end once it
+// is over.
+func NewCutscene(subtitles *xdat.Subtitles, end func(skipped bool)) *Cutscene {
+	return &Cutscene{Subtitles: subtitles, Hold: SkipHold, Prompt: SkipPrompt, End: end}
+}
+
+// Update advances the cutscene to the time of the video, and ends it if
+// the video ended. It returns whether the cutscene is over.
+func (c *Cutscene) Update(now time.Duration, ended bool) bool {
+	if c.over {
+		return true
+	}
+	c.Time = now
+	c.announce()
+	if c.prompt > 0 {
+		c.prompt--
+	}
+	if ended {
+		c.finish(false)
+	}
+	return c.over
+}
+
+// announce announces the cues that started showing with [xgal.Announce].
+// Cues that ended before they were seen, for example when the video
+// skipped ahead, are not announced.
+func (c *Cutscene) announce() {
+	if c.Subtitles == nil {
+		return
+	}
+	for ; c.said < len(c.Subtitles.Cues); c.said++ {
+		cue := c.Subtitles.Cues[c.said]
+		if cue.Start.Duration() > c.Time {
+			break
+		}
+		if c.Time < cue.End.Duration() {
+			xgal.Announce(xgal.Announcement{Cue: xgal.CueLine, Value: spokenCue(cue.Text)})
+		}
+	}
+}
+
+// spokenCue returns the text of a cue without markup, on one line.
+func spokenCue(text string) string {
+	if laid, err := xgal.NewTypeset(nil, 0, 0).Layout(text); err == nil {
+		text = laid.String()
+	}
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// Confirm shows the skip prompt, or skips if the prompt already shows.
+func (c *Cutscene) Confirm() {
+	if c.prompt > 0 {
+		c.Skip()
+		return
+	}
+	c.prompt = c.Prompt
+}
+
+// HoldConfirm sets how many ticks confirm is held, and skips once it is
+// held long enough.
+func (c *Cutscene) HoldConfirm(ticks int) {
+	c.held = ticks
+	if c.Hold > 0 && c.held >= c.Hold {
+		c.Skip()
+	}
+}
+
+// Release stops holding confirm.
+func (c *Cutscene) Release() {
+	c.held = 0
+}
+
+// Skip ends the cutscene early.
+func (c *Cutscene) Skip() {
+	c.finish(true)
+}
+
+func (c *Cutscene) finish(skipped bool) {
+	if c.over {
+		return
+	}
+	c.over = true
+	if c.End != nil {
+		c.End(skipped)
+	}
+}
+
+// Over returns whether the cutscene ended or was skipped.
+func (c *Cutscene) Over() bool {
+	return c.over
+}
+
+// Cues returns the subtitles that show now.
+func (c *Cutscene) Cues() []xdat.Cue {
+	return c.Subtitles.At(c.Time)
+}
+
+// Prompting returns whether the skip prompt shows.
+func (c *Cutscene) Prompting() bool {
+	return !c.over && (c.prompt > 0 || c.held > 0)
+}
+
+// Held returns how far confirm is held towards skipping, from 0 to 1.
+func (c *Cutscene) Held() float64 {
+	if c.Hold <= 0 {
+		return 0
+	}
+	return min(float64(c.held)/float64(c.Hold), 1)
+}
+
+// isConfirm returns whether the key confirms, like it does in the UI.
+func isConfirm(key int) bool {
+	switch xgal.KeyCode(key) {
+	case xgal.KeyEnter, xgal.KeyNumpadEnter, xgal.KeySpace:
+		return true
+	}
+	return false
+}
+
+// NewCutsceneLayer returns a layer that plays the video of the cutscene
+// letterboxed in bounds, with the subtitles in the style of the layer and
+// prompt as the skip prompt. The layer takes all input while it plays,
+// and closes itself and the video once the cutscene is over.
+func NewCutsceneLayer(bounds xgal.Rectangle, video *xgal.Video, cut *Cutscene, prompt string) *xlui.Layer {
+	layer := xlui.NewLayer(bounds)
+	layer.Lock = true
+	tick := int64(0)
+	laid := map[string]*xgal.Text{}
+	typeset := xgal.NewTypeset(layer.Style.Face, bounds.Dx()*3/4, 0)
+	layout := func(text string) *xgal.Text {
+		if t, ok := laid[text]; ok {
+			return t
+		}
+		t, err := typeset.Layout(text)
+		if err != nil {
+			// Show subtitles with broken markup as they are.
+			t, _ = typeset.Layout(xgal.Escape(text))
+		}
+		laid[text] = t
+		return t
+	}
+	// box draws text in a box of the style, centered on x, with the bottom
+	// of the box at y, and returns the box.
+	box := func(screen *xgal.Surface, text *xgal.Text, x, y int) xgal.Rectangle {
+		style := layer.Style
+		size := text.Size().Add(style.Margin.Mul(2))
+		r := xgal.Bound(x-size.X/2, y-size.Y, size.X, size.Y)
+		style.DrawBox(screen, r)
+		text.Draw(screen, style.Face, style.Fore, r.Min.Add(style.Margin), 0, len(text.Glyphs), int(tick), nil)
+		return r
+	}
+
+	layer.Class.Render = func(screen *xgal.Surface) {
+		xgal.Box(screen, layer.Bounds, xgal.Paint(0, 0, 0, 255))
+		video.Draw(screen.SubImage(layer.Bounds).(*xgal.Surface))
+
+		center := (layer.Bounds.Min.X + layer.Bounds.Max.X) / 2
+		y := layer.Bounds.Max.Y - layer.Style.Margin.Y
+		cues := cut.Cues()
+		for i := len(cues) - 1; i >= 0; i-- {
+			r := box(screen, layout(cues[i].Text), center, y)
+			y = r.Min.Y - layer.Style.Margin.Y
+		}
+
+		if cut.Prompting() {
+			text := layout(prompt)
+			size := text.Size().Add(layer.Style.Margin.Mul(2))
+			r := box(screen, text, layer.Bounds.Max.X-size.X/2-layer.Style.Margin.X, layer.Bounds.Min.Y+size.Y+layer.Style.Margin.Y)
+			if held := cut.Held(); held > 0 {
+				bar := xgal.Bound(r.Min.X, r.Max.Y, int(float64(r.Dx())*held), 2)
+				xgal.Box(screen, bar, layer.Style.Fore)
+			}
+		}
+	}
+	layer.Class.Tick = func(now int64) xlui.Reply {
+		tick = now
+		if cut.Update(video.Position(), video.Ended()) {
+			if err := video.Close(); err != nil {
+				slog.Error("closing cutscene video", "err", err)
+			}
+			return xlui.Finish
+		}
+		return xlui.Ignore
+	}
+	layer.Class.Tap = func(key int, mods xlui.Mods) xlui.Reply {
+		if isConfirm(key) {
+			cut.Confirm()
+		}
+		return xlui.Accept
+	}
+	layer.Class.Key = func(key int, dur int) xlui.Reply {
+		if isConfirm(key) {
+			cut.HoldConfirm(dur)
+		}
+		return xlui.Accept
+	}
+	layer.Class.Lift = func(key int, mods xlui.Mods) xlui.Reply {
+		if isConfirm(key) {
+			cut.Release()
+		}
+		return xlui.Accept
+	}
+	layer.Class.Click = func(at xgal.Point, button int) xlui.Reply {
+		return xlui.Accept
+	}
+	return layer
+}
+
+// PlayCutscene plays the named video of the CutsceneDir full screen with
+// the subtitles for the current language, if any. The game pauses until
+// the cutscene is over, and then end is called, which may be nil.
+func (g *Engine) PlayCutscene(name string, end func(skipped bool)) error {
+	full := path.Join(CutsceneDir, name)
+	video, err := xgal.Stream(g.FS, full)
+	if err != nil {
+		return err
+	}
+	lang := ""
+	if g.Lang != nil {
+		lang = g.Lang.Lang
+	}
+	subtitles, err := xdat.FindSubtitles(g.FS, full, lang)
+	if err != nil {
+		video.Close()
+		return err
+	}
+	g.Cutscene = NewCutscene(subtitles, func(skipped bool) {
+		g.Cutscene = nil
+		if end != nil {
+			end(skipped)
+		}
+	})
+	prompt := g.Translate("cutscene.skip", "Press again or hold to skip")
+	layer := NewCutsceneLayer(xgal.Rect(0, 0, ViewWidth, ViewHeight), video, g.Cutscene, prompt)
+	xlui.Append(layer)
+	xlui.SetFocus(layer)
+	video.Play()
+	return nil
+}
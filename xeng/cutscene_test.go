@@ -0,0 +1,109 @@
+package xeng
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/xmasengine/xmas/xdat"
+	"github.com/xmasengine/xmas/xgal"
+)
+
+func testCutscene(ends *[]bool) *Cutscene {
+	subs := &xdat.Subtitles{Cues: []xdat.Cue{
+		{Start: xdat.Timecode(time.Second), End: xdat.Timecode(2 * time.Second), Text: "Ho"},
+	}}
+	return NewCutscene(subs, func(skipped bool) { *ends = append(*ends, skipped) })
+}
+
+func TestCutsceneEnds(t *testing.T) {
+	var ends []bool
+	c := testCutscene(&ends)
+	if c.Update(500*time.Millisecond, false) || len(c.Cues()) != 0 {
+		t.Fatalf("over too soon: %v", c.Cues())
+	}
+	if c.Update(1500*time.Millisecond, false); len(c.Cues()) != 1 {
+		t.Errorf("cues: %v", c.Cues())
+	}
+	if !c.Update(3*time.Second, true) || !c.Over() {
+		t.Errorf("should be over when the video ended")
+	}
+	c.Update(4*time.Second, true)
+	c.Skip()
+	if len(ends) != 1 || ends[0] {
+		t.Errorf("ends: %v", ends)
+	}
+}
+
+func TestCutsceneConfirmTwice(t *testing.T) {
+	var ends []bool
+	c := testCutscene(&ends)
+	c.Confirm()
+	if !c.Prompting() || c.Over() {
+		t.Fatalf("first confirm should prompt")
+	}
+	c.Confirm()
+	if !c.Over() || len(ends) != 1 || !ends[0] {
+		t.Errorf("second confirm should skip: %v", ends)
+	}
+	if c.Prompting() {
+		t.Errorf("no prompt after the end")
+	}
+}
+
+func TestCutscenePromptTimesOut(t *testing.T) {
+	var ends []bool
+	c := testCutscene(&ends)
+	c.Confirm()
+	for i := 0; i < c.Prompt; i++ {
+		c.Update(0, false)
+	}
+	if c.Prompting() {
+		t.Fatalf("prompt should time out")
+	}
+	c.Confirm()
+	if c.Over() {
+		t.Errorf("confirm after the prompt timed out should prompt again")
+	}
+}
+
+func TestCutsceneHold(t *testing.T) {
+	var ends []bool
+	c := testCutscene(&ends)
+	c.HoldConfirm(c.Hold / 2)
+	if c.Held() != 0.5 || !c.Prompting() {
+		t.Errorf("held: %v", c.Held())
+	}
+	c.Release()
+	if c.Held() != 0 || c.Over() {
+		t.Errorf("release: %v", c.Held())
+	}
+	c.HoldConfirm(c.Hold)
+	if !c.Over() || len(ends) != 1 || !ends[0] {
+		t.Errorf("holding should skip: %v", ends)
+	}
+	c = testCutscene(&ends)
+	c.Hold = 0
+	c.HoldConfirm(1000)
+	if c.Over() || c.Held() != 0 {
+		t.Errorf("hold to skip disabled")
+	}
+}
+
+func TestCutsceneAnnounces(t *testing.T) {
+	heard := xgal.Listen(t)
+	subs := &xdat.Subtitles{Cues: []xdat.Cue{
+		{Start: xdat.Timecode(time.Second), End: xdat.Timecode(2 * time.Second), Text: "Ho\nho"},
+		{Start: xdat.Timecode(time.Second), End: xdat.Timecode(3 * time.Second), Text: "{color=#ff0000}Merry{/color} Christmas"},
+		{Start: xdat.Timecode(4 * time.Second), End: xdat.Timecode(5 * time.Second), Text: "Missed"},
+		{Start: xdat.Timecode(6 * time.Second), End: xdat.Timecode(7 * time.Second), Text: "Bye"},
+	}}
+	c := NewCutscene(subs, nil)
+	for _, now := range []time.Duration{500 * time.Millisecond, time.Second, 1500 * time.Millisecond, 6 * time.Second, 6500 * time.Millisecond} {
+		c.Update(now, false)
+	}
+	want := []string{"line Ho ho", "line Merry Christmas", "line Bye"}
+	if got := heard.Texts(); !slices.Equal(got, want) {
+		t.Errorf("heard %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,41 @@
+package xeng
+
+import (
+	"errors"
+)
+
+import (
+	"github.com/xmasengine/xmas/xdat"
+	"github.com/xmasengine/xmas/xlui"
+)
+
+// loadLang loads the string tables and translates the UI again whenever
+// the language changes.
+func (e *Engine) loadLang() error {
+	lang, err := xdat.LoadCatalog(e.FS, xdat.LangDir)
+	if err != nil {
+		lang = xdat.NewCatalog()
+	}
+	e.Lang = lang
+	e.Lang.OnChange(func(string) {
+		xlui.Translate(func(key string) string { return e.Lang.Text(key) })
+	})
+	return err
+}
+
+// Translate returns the text of the key in the current language, or the
+// fallback if no string table has it.
+func (e *Engine) Translate(key, fallback string) string {
+	if text, ok := e.Lang.Lookup(key); ok {
+		return text
+	}
+	return fallback
+}
+
+// SetLang switches the language of the game and its UI.
+func (e *Engine) SetLang(lang string) error {
+	if e.Lang == nil {
+		return errors.New("no string tables loaded")
+	}
+	return e.Lang.SetLang(lang)
+}
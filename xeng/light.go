@@ -0,0 +1,235 @@
+package xeng
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+import (
+	"github.com/xmasengine/xmas/xdat"
+	"github.com/xmasengine/xmas/xgal"
+)
+
+// DayTicks is the default length of a day on the world clock, in ticks.
+// At 60 ticks per second a day lasts 24 minutes.
+const DayTicks = 60 * 60 * 24
+
+// Daylight are the default ambient colors of the world clock. They are
+// spread evenly over the day starting at midnight.
+var Daylight = []xgal.RGBA{
+	xgal.Tint(48, 48, 96),    // midnight
+	xgal.Tint(64, 64, 120),   // night
+	xgal.Tint(200, 150, 150), // dawn
+	xgal.Tint(255, 255, 255), // morning
+	xgal.Tint(255, 255, 255), // noon
+	xgal.Tint(255, 240, 220), // afternoon
+	xgal.Tint(230, 150, 110), // dusk
+	xgal.Tint(80, 70, 120),   // evening
+}
+
+// Clock is the world clock that drives the ambient light of outside zones.
+type Clock struct {
+	Tick   int64       // Tick is the time of the day in ticks.
+	Day    int64       // Day is the length of a day in ticks, DayTicks if 0.
+	Colors []xgal.RGBA // Colors over the day, Daylight if nil.
+}
+
+// Advance advances the clock by one tick, wrapping around at the end
+// of the day.
+func (c *Clock) Advance() {
+	c.Tick = (c.Tick + 1) % c.day()
+}
+
+func (c Clock) day() int64 {
+	if c.Day <= 0 {
+		return DayTicks
+	}
+	return c.Day
+}
+
+// Hour returns the time of the day in hours, from 0 up to 24.
+func (c Clock) Hour() float64 {
+	return 24 * float64(c.Tick%c.day()) / float64(c.day())
+}
+
+// Ambient returns the ambient color at the current time of the day,
+// interpolated between the two nearest colors.
+func (c Clock) Ambient() xgal.RGBA {
+	colors := c.Colors
+	if colors == nil {
+		colors = Daylight
+	}
+	if len(colors) == 0 {
+		return xgal.White
+	}
+	at := float64(len(colors)) * float64(c.Tick%c.day()) / float64(c.day())
+	i := int(at)
+	return mix(colors[i%len(colors)], colors[(i+1)%len(colors)], at-float64(i))
+}
+
+// mix interpolates linearly between the colors a and b.
+func mix(a, b xgal.RGBA, t float64) xgal.RGBA {
+	lerp := func(x, y uint8) uint8 {
+		return uint8(math.Round(float64(x) + (float64(y)-float64(x))*t))
+	}
+	return xgal.Paint(lerp(a.R, b.R), lerp(a.G, b.G), lerp(a.B, b.B), lerp(a.A, b.A))
+}
+
+// modulate multiplies the colors a and b channel by channel.
+func modulate(a, b xgal.RGBA) xgal.RGBA {
+	mul := func(x, y uint8) uint8 {
+		return uint8(uint16(x) * uint16(y) / 255)
+	}
+	return xgal.Paint(mul(a.R, b.R), mul(a.G, b.G), mul(a.B, b.B), mul(a.A, b.A))
+}
+
+// Flicker returns the brightness of a light with the given flicker
+// strength at tick, from 1-flicker up to 1. The seed keeps lights
+// from flickering in step.
+func Flicker(flicker float64, tick int64, seed int) float64 {
+	if flicker <= 0 {
+		return 1
+	}
+	t := float64(tick) + float64(seed)*97.0
+	// Sum of a few incommensurate waves looks irregular enough.
+	wave := math.Sin(t*0.11) + math.Sin(t*0.37+1.3)*0.5 + math.Sin(t*1.13+2.1)*0.25
+	noise := (wave/1.75 + 1) / 2 // 0 to 1
+	return 1 - min(flicker, 1)*noise
+}
+
+// Ambient returns the ambient light color of the zone at the time of clock.
+func Ambient(zone *xdat.Zone, clock Clock) xgal.RGBA {
+	ambient := xgal.White
+	if !zone.Ambient.IsZero() {
+		ambient = zone.Ambient.RGBA()
+	}
+	if zone.Outside {
+		ambient = modulate(ambient, clock.Ambient())
+	}
+	ambient.A = 255
+	return ambient
+}
+
+// Lighting renders a light map for a zone and multiplies it onto the screen.
+type Lighting struct {
+	Map  *xgal.Surface // Map is the light map, the size of the screen.
+	Glow *xgal.Surface // Glow is the texture of a single white light.
+}
+
+// glowSize is the size of the glow texture.
+const glowSize = 64
+
+// glowImage returns an image of a white radial gradient that fades out
+// to transparent at the edge.
+func glowImage() image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, glowSize, glowSize))
+	c := float64(glowSize) / 2
+	for y := 0; y < glowSize; y++ {
+		for x := 0; x < glowSize; x++ {
+			dx, dy := float64(x)+0.5-c, float64(y)+0.5-c
+			d := math.Sqrt(dx*dx+dy*dy) / c
+			v := uint8(0)
+			if d < 1 {
+				// Smooth fall off, premultiplied alpha.
+				v = uint8(255 * (1 - d*d) * (1 - d*d))
+			}
+			img.SetRGBA(x, y, color.RGBA{R: v, G: v, B: v, A: v})
+		}
+	}
+	return img
+}
+
+// prepare makes sure the light map has the size of the screen.
+func (l *Lighting) prepare(size xgal.Point) {
+	if l.Glow == nil {
+		l.Glow = xgal.Bake(glowImage())
+	}
+	if l.Map != nil && l.Map.Bounds().Size() == size {
+		return
+	}
+	if l.Map != nil {
+		l.Map.Deallocate()
+	}
+	l.Map = xgal.Prepare(size.X, size.Y)
+}
+
+// Render fills the light map with the ambient color, adds the lights
+// that are visible through the camera, and multiplies the light map onto
+// the screen. The anchor function returns the position of lights that
+// follow something, and false if that is not present.
+func (l *Lighting) Render(screen *xgal.Surface, camera xgal.Rectangle, ambient xgal.RGBA,
+	lights []xdat.Light, tick int64, anchor func(name string) (xgal.Point, bool)) {
+	if ambient == xgal.White && len(lights) == 0 {
+		return // Nothing to darken or lighten.
+	}
+	l.prepare(screen.Bounds().Size())
+	l.Map.Fill(ambient)
+
+	for i, light := range lights {
+		at := light.At()
+		if light.Follow != "" {
+			found := false
+			if anchor != nil {
+				at, found = anchor(light.Follow)
+			}
+			if !found {
+				continue
+			}
+		}
+		bounds := xgal.Rect(at.X-light.Radius, at.Y-light.Radius,
+			at.X+light.Radius, at.Y+light.Radius)
+		if !bounds.Overlaps(camera) {
+			continue
+		}
+		col := light.Color.RGBA()
+		bright := Flicker(light.Flicker, tick, i)
+		col = mix(xgal.Black, col, bright)
+		xgal.Glaze(l.Map, l.Glow, bounds.Sub(camera.Min), l.Glow.Bounds(), xgal.BlendAdd, col)
+	}
+
+	xgal.Blend(screen, l.Map, screen.Bounds(), l.Map.Bounds(), xgal.BlendMultiply)
+}
+
+// Deallocate releases the light map surfaces.
+func (l *Lighting) Deallocate() {
+	if l.Map != nil {
+		l.Map.Deallocate()
+		l.Map = nil
+	}
+	if l.Glow != nil {
+		l.Glow.Deallocate()
+		l.Glow = nil
+	}
+}
+
+// RenderLights renders the lighting of the zone through the camera.
+func (e *Engine) RenderLights(screen *xgal.Surface, camera xgal.Rectangle) {
+	if e.Zone == nil {
+		return
+	}
+	ambient := Ambient(e.Zone, e.Clock)
+	e.Lighting.Render(screen, camera, ambient, e.Zone.Lights, e.Clock.Tick, e.anchorAt)
+}
+
+// anchorAt returns the center of the player with the given name, or of
+// the first player if the name is "player", or else the hotspot of the
+// thing of the zone with the given name.
+func (e *Engine) anchorAt(name string) (xgal.Point, bool) {
+	if e.World != nil {
+		for i, player := range e.World.Players {
+			if player.Name == name || (name == "player" && i == 0) {
+				b := player.Bound
+				return b.Min.Add(b.Size().Div(2)), true
+			}
+		}
+	}
+	if e.Zone != nil {
+		for _, thing := range e.Zone.Things {
+			if thing.Name == name {
+				return thing.At(), true
+			}
+		}
+	}
+	return xgal.Point{}, false
+}
@@ -0,0 +1,86 @@
+package xeng
+
+import (
+	"testing"
+)
+
+import (
+	"github.com/xmasengine/xmas/xdat"
+	"github.com/xmasengine/xmas/xgal"
+)
+
+func TestClockAmbient(t *testing.T) {
+	clock := Clock{Day: 4, Colors: []xgal.RGBA{xgal.Black, xgal.White}}
+	cases := []struct {
+		tick   int64
+		expect xgal.RGBA
+	}{
+		{0, xgal.Black},
+		{1, xgal.Tint(128, 128, 128)},
+		{2, xgal.White},
+		{3, xgal.Tint(128, 128, 128)},
+	}
+	for _, c := range cases {
+		clock.Tick = c.tick
+		if observe := clock.Ambient(); observe != c.expect {
+			t.Errorf("tick %d: %v != %v", c.tick, observe, c.expect)
+		}
+	}
+	clock.Tick = 3
+	clock.Advance()
+	if clock.Tick != 0 {
+		t.Errorf("advance should wrap: %d", clock.Tick)
+	}
+}
+
+func TestZoneAmbient(t *testing.T) {
+	clock := Clock{Day: 2, Colors: []xgal.RGBA{xgal.Tint(100, 100, 100)}}
+	zone := xdat.NewZone("church")
+	if observe := Ambient(zone, clock); observe != xgal.White {
+		t.Errorf("unset inside: %v", observe)
+	}
+	zone.Outside = true
+	if observe := Ambient(zone, clock); observe != xgal.Tint(100, 100, 100) {
+		t.Errorf("unset outside: %v", observe)
+	}
+	zone.Ambient = xdat.Color(xgal.Tint(255, 0, 128))
+	if observe := Ambient(zone, clock); observe != xgal.Tint(100, 0, 50) {
+		t.Errorf("outside: %v", observe)
+	}
+}
+
+func TestFlicker(t *testing.T) {
+	for tick := int64(0); tick < 1000; tick++ {
+		if observe := Flicker(0, tick, 1); observe != 1 {
+			t.Fatalf("steady light flickers: %f", observe)
+		}
+		observe := Flicker(0.5, tick, 1)
+		if observe < 0.5 || observe > 1 {
+			t.Fatalf("tick %d: flicker out of range: %f", tick, observe)
+		}
+		if observe != Flicker(0.5, tick, 1) {
+			t.Fatalf("tick %d: flicker not deterministic", tick)
+		}
+	}
+}
+
+func TestAnchorAt(t *testing.T) {
+	e := &Engine{
+		World: &xdat.World{Players: []*xdat.Player{{Name: "santa", Bound: xgal.Rect(10, 10, 20, 30)}}},
+		Zone:  &xdat.Zone{Things: []*xdat.Thing{{Name: "lantern", X: 40, Y: 50}}},
+	}
+	for _, c := range []struct {
+		name string
+		want xgal.Point
+		ok   bool
+	}{
+		{"player", xgal.Pt(15, 20), true},
+		{"santa", xgal.Pt(15, 20), true},
+		{"lantern", xgal.Pt(40, 50), true},
+		{"rudolph", xgal.Point{}, false},
+	} {
+		if at, ok := e.anchorAt(c.name); at != c.want || ok != c.ok {
+			t.Errorf("%s: %v %v, want %v %v", c.name, at, ok, c.want, c.ok)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package xeng
+
+import (
+	"log/slog"
+)
+
+import (
+	"github.com/xmasengine/xmas/xgal"
+)
+
+// Fade fades the palettes of the indexed textures towards a color, for
+// example to black or white on zone transitions.
+type Fade struct {
+	Color xgal.RGBA // Color to fade towards.
+	Level float64   // Level from 0 for no fade up to 1 for all Color.
+	Step  float64   // Step is the change of Level per tick.
+}
+
+// Update moves the level one step, stopping at 0 and 1.
+func (f *Fade) Update() {
+	f.Level = min(max(f.Level+f.Step, 0), 1)
+	if f.Level == 0 || f.Level == 1 {
+		f.Step = 0
+	}
+}
+
+// Busy reports whether the fade is still changing.
+func (f Fade) Busy() bool {
+	return f.Step != 0
+}
+
+// FadeOut fades the zone out to col in the given amount of ticks.
+func (e *Engine) FadeOut(col xgal.RGBA, ticks int) {
+	e.Fade.Color = col
+	e.Fade.Step = 1 / float64(max(ticks, 1))
+}
+
+// FadeIn fades the zone back in from the fade color in the given amount
+// of ticks.
+func (e *Engine) FadeIn(ticks int) {
+	e.Fade.Step = -1 / float64(max(ticks, 1))
+}
+
+// RepaintLayers paints the indexed textures of the zone layers, the
+// players and the things with their palettes as they are after cycling
+// and fading, and invalidates the chunks of the layers that changed.
+// Players and things are drawn every frame, so they need no invalidation.
+func (e *Engine) RepaintLayers() {
+	seconds := float64(e.Ticks) * TickSeconds
+	if e.World != nil {
+		for _, player := range e.World.Players {
+			player.Repaint(seconds, e.Fade.Color, e.Fade.Level)
+		}
+	}
+	if e.Zone == nil {
+		return
+	}
+	for depth, layer := range e.Zone.Layers {
+		if layer.Repaint(seconds, e.Fade.Color, e.Fade.Level) && depth < len(e.Chunks) {
+			e.Chunks[depth].InvalidateAll()
+		}
+	}
+	for _, thing := range e.Zone.Things {
+		thing.Repaint(seconds, e.Fade.Color, e.Fade.Level)
+	}
+}
+
+// Transit fades out to black in the given amount of ticks, then loads
+// the named zone and fades it in again.
+func (e *Engine) Transit(name string, ticks int) {
+	e.FadeOut(xgal.Black, ticks)
+	e.Next = name
+	e.NextTicks = ticks
+}
+
+// updateTransit loads the next zone once the fade out is done.
+func (e *Engine) updateTransit() {
+	if e.Next == "" || e.Fade.Busy() {
+		return
+	}
+	name := e.Next
+	e.Next = ""
+	if _, err := e.LoadZone(name); err != nil {
+		slog.Error("transit", "zone", name, "err", err)
+	}
+	e.FadeIn(e.NextTicks)
+}
@@ -0,0 +1,31 @@
+package xeng
+
+import (
+	"testing"
+)
+
+import (
+	"github.com/xmasengine/xmas/xgal"
+)
+
+func TestFade(t *testing.T) {
+	e := &Engine{}
+	e.FadeOut(xgal.White, 4)
+	for i := 0; i < 3; i++ {
+		e.Fade.Update()
+	}
+	if !e.Fade.Busy() || e.Fade.Level != 0.75 {
+		t.Errorf("fading out: %+v", e.Fade)
+	}
+	e.Fade.Update()
+	e.Fade.Update()
+	if e.Fade.Busy() || e.Fade.Level != 1 || e.Fade.Color != xgal.White {
+		t.Errorf("faded out: %+v", e.Fade)
+	}
+	e.FadeIn(2)
+	e.Fade.Update()
+	e.Fade.Update()
+	if e.Fade.Busy() || e.Fade.Level != 0 {
+		t.Errorf("faded in: %+v", e.Fade)
+	}
+}
@@ -0,0 +1,316 @@
+package xeng
+
+import (
+	"math"
+	"math/rand/v2"
+)
+
+import (
+	"github.com/xmasengine/xmas/xdat"
+	"github.com/xmasengine/xmas/xgal"
+)
+
+// TickSeconds is the duration of a single engine tick in seconds.
+const TickSeconds = 1.0 / 60.0
+
+// EffectsName is the name of the library of particle effects.
+const EffectsName = "pack/fx/effects.xml"
+
+// Particle is a single particle of a spray.
+type Particle struct {
+	X, Y   float64 // X, Y is the position in pixels.
+	VX, VY float64 // VX, VY is the velocity in pixels per second.
+	Angle  float64 // Angle in radians.
+	Spin   float64 // Spin in radians per second.
+	Age    float64 // Age in seconds.
+	Life   float64 // Life time in seconds.
+}
+
+// Spray is a running emitter of particles of an effect. The particles are
+// kept in a pool that is allocated once, so emitting does not allocate.
+type Spray struct {
+	Effect    *xdat.Effect // Effect that is sprayed.
+	Origins   []xgal.Point // Origins to emit from, chosen at random.
+	Depth     int          // Depth of the layer the spray is drawn over.
+	Particles []Particle   // Particles is the pool, the live ones first.
+	Live      int          // Live is the amount of live particles.
+	Carry     float64      // Carry is the part of a particle to emit next.
+	Once      bool         // Once sprays only emit their burst and then stop.
+	Rand      *rand.Rand   // Rand is the random generator of the spray.
+}
+
+// NewSpray returns a spray of the effect that emits from the origins.
+// The seed makes the spray deterministic.
+func NewSpray(effect *xdat.Effect, depth int, seed uint64, origins ...xgal.Point) *Spray {
+	s := &Spray{Effect: effect, Depth: depth}
+	s.Particles = make([]Particle, effect.PoolSize())
+	s.Rand = rand.New(rand.NewPCG(seed, seed^0x5eed))
+	s.Start(origins...)
+	return s
+}
+
+// Move moves the spray to the origins, keeping its live particles.
+func (s *Spray) Move(origins ...xgal.Point) {
+	s.Origins = append(s.Origins[:0], origins...)
+}
+
+// Start restarts the spray at the origins, dropping all live particles
+// and emitting the burst of the effect.
+func (s *Spray) Start(origins ...xgal.Point) {
+	s.Origins = append(s.Origins[:0], origins...)
+	s.Live = 0
+	s.Carry = 0
+	s.Emit(s.Effect.Burst)
+}
+
+// jitter returns a random value between -j and j.
+func (s *Spray) jitter(j float64) float64 {
+	return (s.Rand.Float64()*2 - 1) * j
+}
+
+// Emit emits up to n particles, as far as the pool allows.
+func (s *Spray) Emit(n int) {
+	if len(s.Origins) == 0 {
+		return
+	}
+	fx := s.Effect
+	for ; n > 0 && s.Live < len(s.Particles); n-- {
+		origin := s.Origins[s.Rand.IntN(len(s.Origins))]
+		x, y := s.place()
+		angle := (fx.Angle + s.jitter(fx.Spread)) * math.Pi / 180
+		speed := fx.Speed + s.Rand.Float64()*fx.SpeedJitter
+		s.Particles[s.Live] = Particle{
+			X:     float64(origin.X) + x,
+			Y:     float64(origin.Y) + y,
+			VX:    math.Cos(angle) * speed,
+			VY:    math.Sin(angle) * speed,
+			Angle: s.Rand.Float64() * 2 * math.Pi,
+			Spin:  (fx.Spin + s.jitter(fx.SpinJitter)) * math.Pi / 180,
+			Life:  fx.Life + s.Rand.Float64()*fx.LifeJitter,
+		}
+		s.Live++
+	}
+}
+
+// place returns a random offset from the origin inside the emitter shape.
+func (s *Spray) place() (x, y float64) {
+	fx := s.Effect
+	w, h := fx.Width/2, fx.Height/2
+	switch fx.Shape {
+	case xdat.ShapeBox:
+		return s.jitter(w), s.jitter(h)
+	case xdat.ShapeLine:
+		return s.jitter(w), 0
+	case xdat.ShapeDisk:
+		a := s.Rand.Float64() * 2 * math.Pi
+		r := math.Sqrt(s.Rand.Float64())
+		return math.Cos(a) * r * w, math.Sin(a) * r * h
+	case xdat.ShapeRing:
+		a := s.Rand.Float64() * 2 * math.Pi
+		return math.Cos(a) * w, math.Sin(a) * h
+	default:
+		return 0, 0
+	}
+}
+
+// Update ages and moves the particles by dt seconds, drops the dead ones
+// and emits new ones at the rate of the effect.
+func (s *Spray) Update(dt float64) {
+	fx := s.Effect
+	for i := 0; i < s.Live; {
+		p := &s.Particles[i]
+		p.Age += dt
+		if p.Age >= p.Life {
+			// Swap the dead particle with the last live one.
+			s.Live--
+			s.Particles[i] = s.Particles[s.Live]
+			continue
+		}
+		p.VX += fx.GravityX * dt
+		p.VY += fx.GravityY * dt
+		p.X += p.VX * dt
+		p.Y += p.VY * dt
+		p.Angle += p.Spin * dt
+		i++
+	}
+	if s.Once {
+		return
+	}
+	s.Carry += fx.Rate * dt
+	n := int(s.Carry)
+	s.Carry -= float64(n)
+	s.Emit(n)
+}
+
+// Done reports whether a spray that emits once has no live particles left.
+func (s *Spray) Done() bool {
+	return s.Once && s.Live == 0
+}
+
+// Render draws the live particles that are visible through the camera.
+// The dot is drawn for effects that have no texture.
+func (s *Spray) Render(screen *xgal.Surface, camera xgal.Rectangle, dot *xgal.Surface) {
+	fx := s.Effect
+	texture := fx.Texture
+	if texture == nil {
+		texture = dot
+	}
+	for i := 0; i < s.Live; i++ {
+		p := s.Particles[i]
+		t := p.Age / p.Life
+		from := texture.Bounds()
+		if fx.Texture != nil {
+			from = fx.FrameAt(t)
+		}
+		scale := fx.ScaleAt(t)
+		reach := float64(max(from.Dx(), from.Dy())) * scale
+		if p.X+reach < float64(camera.Min.X) || p.X-reach > float64(camera.Max.X) ||
+			p.Y+reach < float64(camera.Min.Y) || p.Y-reach > float64(camera.Max.Y) {
+			continue
+		}
+		x := p.X - float64(camera.Min.X)
+		y := p.Y - float64(camera.Min.Y)
+		xgal.Turn(screen, texture, from, x, y, p.Angle, scale, fx.ColorAt(t))
+	}
+}
+
+// Particles runs the sprays of a zone and one shot bursts.
+type Particles struct {
+	Sprays   []*Spray      // Sprays that are running.
+	Spare    []*Spray      // Spare are finished sprays kept for reuse.
+	Emitters []*Spray      // Emitters are the sprays of the emitters of the zone, nil if their effect is missing.
+	Seed     uint64        // Seed for the next spray.
+	Dot      *xgal.Surface // Dot is drawn for effects without texture.
+	Lib      *xdat.Effects // Lib is the library of effects.
+}
+
+// Add starts a spray of the named effect. Once sprays emit their burst
+// and are removed when all their particles died. Returns nil if there
+// is no such effect.
+func (ps *Particles) Add(name string, depth int, once bool, origins ...xgal.Point) *Spray {
+	if ps.Lib == nil {
+		return nil
+	}
+	effect := ps.Lib.Find(name)
+	if effect == nil {
+		return nil
+	}
+	ps.Seed++
+	var spray *Spray
+	for i, spare := range ps.Spare {
+		if spare.Effect == effect {
+			spray = spare
+			ps.Spare = append(ps.Spare[:i], ps.Spare[i+1:]...)
+			break
+		}
+	}
+	if spray == nil {
+		spray = NewSpray(effect, depth, ps.Seed)
+	}
+	spray.Depth = depth
+	spray.Once = once
+	spray.Start(origins...)
+	ps.Sprays = append(ps.Sprays, spray)
+	return spray
+}
+
+// Clear stops all sprays.
+func (ps *Particles) Clear() {
+	ps.Spare = append(ps.Spare, ps.Sprays...)
+	ps.Sprays = ps.Sprays[:0]
+	ps.Emitters = ps.Emitters[:0]
+}
+
+// Update updates all sprays by dt seconds and spares the ones that are done.
+func (ps *Particles) Update(dt float64) {
+	kept := ps.Sprays[:0]
+	for _, spray := range ps.Sprays {
+		spray.Update(dt)
+		if spray.Done() {
+			ps.Spare = append(ps.Spare, spray)
+		} else {
+			kept = append(kept, spray)
+		}
+	}
+	clear(ps.Sprays[len(kept):])
+	ps.Sprays = kept
+}
+
+// Render draws the sprays at the given depth through the camera.
+func (ps *Particles) Render(screen *xgal.Surface, camera xgal.Rectangle, depth int) {
+	if ps.Dot == nil {
+		ps.Dot = xgal.Prepare(2, 2)
+		ps.Dot.Fill(xgal.White)
+	}
+	for _, spray := range ps.Sprays {
+		if spray.Depth == depth {
+			spray.Render(screen, camera, ps.Dot)
+		}
+	}
+}
+
+// ResetEmitters starts the sprays of the emitters of the current zone,
+// for example after loading it or after the editor changed them.
+func (e *Engine) ResetEmitters() {
+	e.Particles.Clear()
+	if e.Zone == nil {
+		return
+	}
+	for _, emitter := range e.Zone.Emitters {
+		origins := []xgal.Point{emitter.At()}
+		if emitter.Flag != 0 {
+			origins = flagOrigins(e.GetLayer(emitter.Depth), emitter.Flag)
+		}
+		spray := e.Particles.Add(emitter.Effect, emitter.Depth, false, origins...)
+		e.Particles.Emitters = append(e.Particles.Emitters, spray)
+	}
+}
+
+// moveEmitters moves the sprays of the emitters that emit from the flagged
+// tiles of the layer at depth to where those tiles are now.
+func (e *Engine) moveEmitters(depth int) {
+	if e.Zone == nil {
+		return
+	}
+	for i, emitter := range e.Zone.Emitters {
+		if emitter.Flag == 0 || emitter.Depth != depth || i >= len(e.Particles.Emitters) {
+			continue
+		}
+		if spray := e.Particles.Emitters[i]; spray != nil {
+			spray.Move(flagOrigins(e.GetLayer(depth), emitter.Flag)...)
+		}
+	}
+}
+
+// flagOrigins returns the centers of the tiles of the layer that have
+// all of the flags.
+func flagOrigins(layer *xdat.Layer, flag xdat.Flag) []xgal.Point {
+	if layer == nil {
+		return nil
+	}
+	origins := []xgal.Point{}
+	for ty, row := range layer.Tiles.Rows {
+		for tx, cell := range row {
+			if cell.Has(flag) {
+				origins = append(origins, xgal.Pt(
+					tx*layer.TileWidth+layer.TileWidth/2,
+					ty*layer.TileHeight+layer.TileHeight/2))
+			}
+		}
+	}
+	return origins
+}
+
+// Burst emits a one shot burst of the named effect at the position at,
+// for example when something is hit.
+func (e *Engine) Burst(name string, at xgal.Point, depth int) {
+	e.Particles.Add(name, depth, true, at)
+}
+
+// Effects returns the names of the particle effects.
+func (e *Engine) Effects() []string {
+	if e.Particles.Lib == nil {
+		return nil
+	}
+	return e.Particles.Lib.Names()
+}
@@ -0,0 +1,104 @@
+package xeng
+
+import (
+	"testing"
+)
+
+import (
+	"github.com/xmasengine/xmas/xdat"
+	"github.com/xmasengine/xmas/xgal"
+)
+
+func TestSprayPool(t *testing.T) {
+	effect := &xdat.Effect{Rate: 120, Max: 10, Life: 1}
+	spray := NewSpray(effect, 0, 1, xgal.Pt(10, 10))
+	spray.Update(0.05) // 6 particles
+	if spray.Live != 6 {
+		t.Errorf("rate: %d live", spray.Live)
+	}
+	for i := 0; i < 10; i++ {
+		spray.Update(0.05)
+	}
+	if spray.Live != 10 || len(spray.Particles) != 10 {
+		t.Errorf("pool: %d live in %d", spray.Live, len(spray.Particles))
+	}
+	for i := 0; i < 30; i++ {
+		spray.Update(0.05)
+	}
+	if spray.Live != 10 {
+		t.Errorf("pool after deaths: %d live", spray.Live)
+	}
+}
+
+func TestSprayMotion(t *testing.T) {
+	effect := &xdat.Effect{Burst: 1, Life: 10, Speed: 10, GravityY: 20}
+	spray := NewSpray(effect, 0, 1, xgal.Pt(0, 0))
+	spray.Once = true
+	spray.Update(0.5)
+	p := spray.Particles[0]
+	if p.X != 5 || p.Y != 5 || p.VY != 10 {
+		t.Errorf("motion: %+v", p)
+	}
+	spray.Update(10)
+	if !spray.Done() {
+		t.Errorf("burst should be done")
+	}
+}
+
+func TestSprayDeterministic(t *testing.T) {
+	effect := &xdat.Effect{Shape: xdat.ShapeDisk, Width: 16, Height: 8,
+		Burst: 8, Life: 1, LifeJitter: 1, Speed: 5, SpeedJitter: 5, Spread: 180}
+	a := NewSpray(effect, 0, 7, xgal.Pt(0, 0))
+	b := NewSpray(effect, 0, 7, xgal.Pt(0, 0))
+	a.Update(0.1)
+	b.Update(0.1)
+	for i := 0; i < a.Live; i++ {
+		p := a.Particles[i]
+		if p != b.Particles[i] {
+			t.Fatalf("particle %d differs", i)
+		}
+		if p.X < -8-1 || p.X > 8+1 || p.Y < -4-1 || p.Y > 4+1 {
+			t.Errorf("particle %d outside of disk: %f %f", i, p.X, p.Y)
+		}
+	}
+}
+
+func TestParticlesBurst(t *testing.T) {
+	lib := &xdat.Effects{Effects: []*xdat.Effect{{Name: "hit", Burst: 4, Life: 0.1}}}
+	ps := Particles{Lib: lib}
+	if ps.Add("rain", 0, true, xgal.Pt(0, 0)) != nil {
+		t.Fatalf("added missing effect")
+	}
+	first := ps.Add("hit", 1, true, xgal.Pt(0, 0))
+	if first == nil || first.Live != 4 {
+		t.Fatalf("burst not emitted")
+	}
+	ps.Update(0.2)
+	if len(ps.Sprays) != 0 || len(ps.Spare) != 1 {
+		t.Fatalf("done burst not spared: %d %d", len(ps.Sprays), len(ps.Spare))
+	}
+	if second := ps.Add("hit", 2, true, xgal.Pt(1, 1)); second != first || second.Depth != 2 {
+		t.Errorf("spare spray not reused")
+	}
+}
+
+func TestEmittersFollowTiles(t *testing.T) {
+	lib := &xdat.Effects{Effects: []*xdat.Effect{{Name: "smoke", Rate: 10, Max: 10, Life: 1}}}
+	zone := &xdat.Zone{Layers: []*xdat.Layer{xdat.NewLayerWith(4, 4, 8, 8)}}
+	zone.AddEmitter(xdat.Emitter{Effect: "smoke", Flag: xdat.FlagSpecial})
+	e := &Engine{Zone: zone, Particles: Particles{Lib: lib}}
+	e.ResetEmitters()
+	spray := e.Particles.Emitters[0]
+	if len(spray.Origins) != 0 {
+		t.Fatalf("origins without flagged tiles: %v", spray.Origins)
+	}
+
+	zone.Layers[0].Tiles.Set(xgal.Pt(1, 0), xdat.MakeTile(0, 0, xdat.FlagSpecial))
+	e.invalidateChanged()
+	if len(spray.Origins) != 1 || spray.Origins[0] != xgal.Pt(12, 4) {
+		t.Errorf("origins after setting a tile: %v", spray.Origins)
+	}
+	if len(e.Particles.Sprays) != 1 || e.Particles.Sprays[0] != spray {
+		t.Errorf("spray restarted: %d sprays", len(e.Particles.Sprays))
+	}
+}
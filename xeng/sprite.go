@@ -0,0 +1,64 @@
+package xeng
+
+import (
+	"cmp"
+	"slices"
+)
+
+import (
+	"github.com/xmasengine/xmas/xdat"
+	"github.com/xmasengine/xmas/xgal"
+)
+
+// sprite is a player or thing to draw, with the animator that draws it.
+type sprite struct {
+	animator *xdat.Animator
+	texture  *xgal.Surface
+	at       xgal.Point
+}
+
+// UpdateSprites walks the first player of the world by delta, and steps
+// the animators of the other players and of the things of the zone.
+func (e *Engine) UpdateSprites(delta xgal.Point) {
+	if e.World != nil {
+		for i, player := range e.World.Players {
+			if i > 0 {
+				delta = xgal.Point{}
+			}
+			player.Walk(delta)
+		}
+	}
+	if e.Zone != nil {
+		for _, thing := range e.Zone.Things {
+			thing.Animator.Update()
+		}
+	}
+}
+
+// RenderSprites draws the players and the things on the layer at depth
+// through the camera, from the back to the front.
+func (e *Engine) RenderSprites(screen *xgal.Surface, camera xgal.Rectangle, depth int) {
+	sprites := []sprite{}
+	if e.World != nil {
+		for _, player := range e.World.Players {
+			if int(player.Depth) == depth {
+				sprites = append(sprites, sprite{&player.Animator, player.Texture, player.At})
+			}
+		}
+	}
+	if e.Zone != nil {
+		for _, thing := range e.Zone.Things {
+			if int(thing.Depth) == depth {
+				sprites = append(sprites, sprite{&thing.Animator, thing.Texture, thing.At()})
+			}
+		}
+	}
+	slices.SortStableFunc(sprites, func(a, b sprite) int {
+		return cmp.Compare(a.at.Y, b.at.Y)
+	})
+	for _, s := range sprites {
+		if s.animator.Bounds(s.at).Overlaps(camera) {
+			s.animator.Draw(screen, s.texture, s.at.Sub(camera.Min))
+		}
+	}
+}
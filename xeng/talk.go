@@ -0,0 +1,89 @@
+package xeng
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+import (
+	"github.com/xmasengine/xmas/xdat"
+	"github.com/xmasengine/xmas/xgal"
+	"github.com/xmasengine/xmas/xlui"
+)
+
+// TalkLines is how many lines of a talk show at once.
+const TalkLines = 3
+
+// TalkReach is how near in pixels the player has to be to a thing to
+// talk to it.
+const TalkReach = 24
+
+// ShowTalk shows the says, asks and replies of the named talk of the zone
+// one after the other. Each is translated with the string tables when it
+// is shown, so the talk follows the current language, and announced with
+// who speaks it. Enter shows the next one, and end is called after the
+// last, if it is not nil.
+func (g *Engine) ShowTalk(name string, end func()) error {
+	if g.Zone == nil {
+		return errors.New("no zone loaded")
+	}
+	talk := g.Zone.FindTalk(name)
+	if talk == nil {
+		return fmt.Errorf("talk %q not found", name)
+	}
+	lines := talk.Lines()
+	if len(lines) == 0 {
+		return fmt.Errorf("talk %q has nothing to say", name)
+	}
+	n := 0
+	layer := xlui.NewLayer(xgal.Rect(0, ViewHeight*2/3, ViewWidth, ViewHeight))
+	ctrl := layer.Talk("", TalkLines)
+	show := func(line xdat.TalkLine) {
+		ctrl.Label = line.Who
+		ctrl.Class.Relabel(g.Translate(line.ID, line.Text))
+	}
+	show(lines[n])
+	ctrl.Class.Entry = func(string) xlui.Reply {
+		n++
+		if n < len(lines) {
+			show(lines[n])
+			return xlui.Accept
+		}
+		g.Talk = nil
+		if end != nil {
+			end()
+		}
+		return xlui.Finish
+	}
+	layer.SetFocus(ctrl)
+	g.Talk = layer
+	xlui.Append(layer)
+	xlui.SetFocus(layer)
+	return nil
+}
+
+// TalkNear shows the talk of the thing nearest to the first player, if
+// it is within TalkReach and has a talk. It reports whether it shows one.
+func (g *Engine) TalkNear() bool {
+	if g.Zone == nil || g.World == nil || len(g.World.Players) == 0 || g.Talk != nil {
+		return false
+	}
+	at := g.World.Players[0].At
+	var near *xdat.Thing
+	best := TalkReach * TalkReach
+	for _, thing := range g.Zone.Things {
+		d := thing.At().Sub(at)
+		if dist := d.X*d.X + d.Y*d.Y; thing.Talk != "" && dist <= best {
+			near, best = thing, dist
+		}
+	}
+	if near == nil {
+		return false
+	}
+	if err := g.ShowTalk(near.Talk, nil); err != nil {
+		slog.Error("talk", "name", near.Talk, "err", err)
+		return false
+	}
+	return true
+}
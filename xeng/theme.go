@@ -0,0 +1,31 @@
+package xeng
+
+import (
+	"fmt"
+)
+
+import (
+	"github.com/xmasengine/xmas/xdat"
+	"github.com/xmasengine/xmas/xlui"
+)
+
+// loadThemes loads the themes of the UI and uses the default theme. The
+// UI keeps its built in looks if there is none.
+func (e *Engine) loadThemes() error {
+	themes, err := xdat.LoadThemes(e.FS, xdat.ThemeDir)
+	e.Themes = themes
+	if theme := xdat.FindTheme(themes, xdat.DefaultTheme); theme != nil {
+		xlui.SetTheme(theme)
+	}
+	return err
+}
+
+// SetTheme switches the UI to the named theme.
+func (e *Engine) SetTheme(name string) error {
+	theme := xdat.FindTheme(e.Themes, name)
+	if theme == nil {
+		return fmt.Errorf("theme %q not found", name)
+	}
+	xlui.SetTheme(theme)
+	return nil
+}
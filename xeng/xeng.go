@@ -14,8 +14,8 @@ import (
 	"github.com/xmasengine/xmas/xdat"
 	"github.com/xmasengine/xmas/xgal"
 	"github.com/xmasengine/xmas/xlog"
-	// "github.com/xmasengine/xmas/xres"
 	"github.com/xmasengine/xmas/xlui"
+	"github.com/xmasengine/xmas/xres"
 	"github.com/xmasengine/xmas/xzed"
 )
 
@@ -27,6 +27,9 @@ const ViewHeight = 192
 
 // const ViewHeight = 240 * 2
 
+// TileFrameTicks is how many ticks each animation frame of the tiles shows.
+const TileFrameTicks = 15
+
 type Engine struct {
 	Log         xlog.Log
 	Msg         string
@@ -42,6 +45,20 @@ type Engine struct {
 	Windowed    bool
 	Zone        *xdat.Zone
 	World       *xdat.World
+	Chunks      []*Chunks     // Chunks caches the rendered layers of the Zone.
+	Frame       int           // Frame is the animation frame of the tiles, see TileFrameTicks.
+	Clock       Clock         // Clock is the world clock.
+	Lighting    Lighting      // Lighting renders the lights of the Zone.
+	Particles   Particles     // Particles runs the particle effects.
+	Fade        Fade          // Fade fades the palettes of the zone.
+	Ticks       int64         // Ticks counts the updates.
+	Next        string        // Next is the zone to load after fading out.
+	NextTicks   int           // NextTicks is how long to fade in the next zone.
+	Lang        *xdat.Catalog // Lang translates to the current language.
+	Cutscene    *Cutscene     // Cutscene that plays, if any.
+	Talk        *xlui.Layer   // Talk is the layer of the talk that shows, if any.
+	Themes      []*xdat.Theme // Themes of the UI.
+	Mixer       *xgal.Mixer   // Mixer plays the music and the sounds.
 }
 
 func New(sw, sh int) *Engine {
@@ -49,6 +66,7 @@ func New(sw, sh int) *Engine {
 	engine.Camera = image.Rect(0, 0, ViewWidth, ViewHeight)
 	engine.Pressed = make([]xgal.KeyCode, 16)
 	engine.Log.Hide = true
+	engine.Mixer = xgal.NewMixer()
 	wd, _ := os.Getwd()
 	engine.FS = os.DirFS(wd)
 	engine.loadFirst()
@@ -60,11 +78,33 @@ func dprintln(msg string, vars ...any) {
 }
 
 func (engine *Engine) loadFirst() {
+	// Load the atlases first so textures are found in them.
+	if err := xres.UseAtlases(engine.FS, xres.AtlasDir); err != nil {
+		slog.Error("loading atlases", "err", err)
+	}
+
 	world, err := engine.LoadWorld()
 	if err != nil {
 		slog.Error("loading world", "err", err)
 	}
 
+	engine.Particles.Lib, err = xdat.LoadEffects(engine.FS, EffectsName)
+	if err != nil {
+		slog.Error("loading effects", "err", err)
+	}
+
+	if err = engine.loadLang(); err != nil {
+		slog.Error("loading string tables", "err", err)
+	}
+
+	if err = engine.loadThemes(); err != nil {
+		slog.Error("loading themes", "err", err)
+	}
+
+	if err = engine.Mixer.Start(); err != nil {
+		slog.Error("starting the mixer", "err", err)
+	}
+
 	_, err = engine.LoadZone(world.Start)
 	if err != nil {
 		slog.Error("loading zone", "err", err)
@@ -73,9 +113,26 @@ func (engine *Engine) loadFirst() {
 
 func (g *Engine) Update() error {
 	g.Log.Update()
+	if g.Mixer != nil {
+		g.Mixer.Talk(g.Talking())
+	}
+	if g.Cutscene != nil {
+		// The game pauses while a cutscene plays.
+		xlui.Poll()
+		return nil
+	}
+	g.Ticks++
+	g.Frame = int(g.Ticks / TileFrameTicks)
+	g.Clock.Advance()
+	g.Particles.Update(TickSeconds)
+	g.Fade.Update()
+	g.updateTransit()
+	g.RepaintLayers()
+	g.invalidateChanged()
 
 	res := xlui.Poll()
 	if res == xlui.Finish || res == xlui.Accept {
+		g.UpdateSprites(xgal.Point{})
 		return nil
 	}
 
@@ -83,29 +140,16 @@ func (g *Engine) Update() error {
 	g.Pressed = xgal.Keys(g.Pressed)
 	var delta image.Point
 	var mdelta image.Point
-	// act := xdat.Stand
-	// var dir xdat.Direction
-	if g.Zone != nil {
-		// dir = g.Zone.Player.Direction
-	}
 	for _, k := range g.Pressed {
 		switch k {
 		case xgal.KeyArrowUp:
 			delta.Y = -1
-			// dir = xdat.North
-			// act = xdat.Walk
 		case xgal.KeyArrowDown:
 			delta.Y = 1
-			// dir = xdat.South
-			// act = xdat.Walk
 		case xgal.KeyArrowLeft:
 			delta.X = -1
-			// dir = xdat.West
-			// act = xdat.Walk
 		case xgal.KeyArrowRight:
 			delta.X = 1
-			// dir = xdat.East
-			// act = xdat.Walk
 		case xgal.KeyPageUp:
 			mdelta.Y = -1
 		case xgal.KeyPageDown:
@@ -121,11 +165,8 @@ func (g *Engine) Update() error {
 
 	if g.Zone != nil {
 		g.Camera = g.Camera.Add(mdelta)
-		// g.Zone.Player.At = g.Zone.Player.At.Add(delta)
-		// pose := g.Zone.Player.BestPose(dir, act)
-		// g.Zone.Player.Pose = pose
-		// g.Zone.Player.Update()
 	}
+	g.UpdateSprites(delta)
 
 	switch {
 	case g.Editor != nil && g.Editor.Done:
@@ -146,6 +187,9 @@ func (g *Engine) Update() error {
 				g.Editor = nil
 			}
 		}
+	case xgal.Tap(xgal.KeyEnter):
+		g.TalkNear()
+
 	case xgal.Tap(xgal.KeyF9):
 		g.Debug = !g.Debug
 		xlui.Ask(50, 50, 250, 100, "Debug", "debug", func(string) bool { return true })
@@ -159,11 +203,18 @@ func (g *Engine) Update() error {
 	return nil
 }
 
+// Talking returns whether dialogue shows, such as a talk or the subtitles
+// of a cutscene, so the music can be ducked under it.
+func (g *Engine) Talking() bool {
+	return g.Talk != nil || g.Cutscene != nil && len(g.Cutscene.Cues()) > 0
+}
+
 const tileDebug = false
 
 func (g *Engine) Draw(screen *xgal.Surface) {
 	if g.Zone != nil {
 		g.RenderZone(screen, g.Camera)
+		g.RenderLights(screen, g.Camera)
 		if g.Debug {
 			// pose := g.Zone.Player.Pose
 			// xgal.Debug(screen, fmt.Sprintf("pose: %d %d %d %d %d",
@@ -194,6 +245,8 @@ func (g *Engine) LoadZone(name string) (*xdat.Zone, error) {
 	}
 
 	g.Zone = z
+	g.resetChunks()
+	g.ResetEmitters()
 	return z, nil
 }
 
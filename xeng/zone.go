@@ -69,68 +69,129 @@ func RenderFlag(screen *xgal.Surface, bounds xgal.Rectangle, f xdat.Flag) {
 	}
 }
 
+// RenderLayer draws the visible tiles of the layer one by one.
+// RenderZone uses the faster cached [Chunks] instead.
 func (e *Engine) RenderLayer(screen *xgal.Surface, camera xgal.Rectangle, m *xdat.Layer, index int) {
 	if m == nil || m.Texture == nil {
 		// Can't draw if there is no texture loaded.
 		return
 	}
 
-	starty := camera.Min.Y / int(m.TileHeight)
-	if starty < 0 {
-		starty = 0
-	}
-	endy := min(1+camera.Max.Y/int(m.TileHeight), len(m.Tiles.Rows)-1)
+	tiles := xgal.Rect(
+		camera.Min.X/int(m.TileWidth), camera.Min.Y/int(m.TileHeight),
+		1+camera.Max.X/int(m.TileWidth), 1+camera.Max.Y/int(m.TileHeight),
+	)
+	renderTiles(screen, m, index, tiles, camera.Min, e.Frame)
+	// m.RenderPresences(screen, camera, layer)
+}
 
-	// This draws the whole layer. Only draw visible part using a camera.
+// renderTiles draws the tiles of the layer inside the tile rectangle tiles
+// onto dst as they show at the animation frame, with origin the pixel
+// position that is drawn at 0,0 of dst. It reports whether any of the
+// tiles are animated.
+func renderTiles(dst *xgal.Surface, m *xdat.Layer, index int, tiles xgal.Rectangle, origin xgal.Point, frame int) bool {
+	animated := false
+	starty := max(tiles.Min.Y, 0)
+	endy := min(tiles.Max.Y, len(m.Tiles.Rows))
+
+	// Only draw the part of the layer inside of tiles.
 	for ty := starty; ty < endy; ty++ {
 		row := m.Tiles.Rows[ty]
 
-		startx := max(camera.Min.X/int(m.TileWidth), 0)
-		endx := min(1+camera.Max.X/int(m.TileWidth), len(row)-1)
+		startx := max(tiles.Min.X, 0)
+		endx := min(tiles.Max.X, len(row))
 		for tx := startx; tx < endx; tx++ {
 			cell := row[tx]
 			if cell.X == 0 && cell.Y == 0 && index > 0 {
 				continue // 0 is empty when not level 0
 			}
+			if cell.Has(xdat.FlagAnimate) {
+				animated = true
+				cell = cell.Frame(frame)
+			}
 			idx := cell.X
 			idy := cell.Y
 			fx := int(idx) * int(m.TileWidth)
 			fy := int(idy) * int(m.TileHeight)
 
 			from := xgal.Rect(fx, fy, fx+int(m.TileWidth), fy+int(m.TileHeight))
-			sub := m.Texture.SubImage(from).(*xgal.Surface)
-			opts := xgal.BlitOpts{}
+			opts := cell.Orientation().Opts()
 
-			if cell.Has(xdat.FlagHorizontal) {
-				opts.FlipH = true
-			}
-			if cell.Has(xdat.FlagVertical) {
-				opts.FlipV = true
-			}
-			if cell.Has(xdat.FlagRotate90) {
-				opts.Rot = xgal.Rot90
-			}
-			if cell.Has(xdat.FlagRotate180) {
-				opts.Rot = xgal.Rot180
-			}
-			if cell.Has(xdat.FlagRotate270) {
-				opts.Rot = xgal.Rot270
-			}
-
-			atx := int(tx)*int(m.TileWidth) - camera.Min.X
-			aty := int(ty)*int(m.TileHeight) - camera.Min.Y
+			atx := int(tx)*int(m.TileWidth) - origin.X
+			aty := int(ty)*int(m.TileHeight) - origin.Y
 			to := xgal.Rect(atx, aty, atx+int(m.TileWidth), aty+int(m.TileHeight))
-			xgal.Blit(screen, sub, to, sub.Bounds(), opts)
+			xgal.Blit(dst, m.Texture, to, from, opts)
 		}
 	}
-	// m.RenderPresences(screen, camera, layer)
+	return animated
 }
 
 func (e *Engine) RenderZone(screen *xgal.Surface, camera xgal.Rectangle) {
+	if e.Zone == nil {
+		return
+	}
+	if e.chunksStale() {
+		e.resetChunks()
+	}
+	for depth, chunks := range e.Chunks {
+		chunks.SetFrame(e.Frame)
+		chunks.Render(screen, camera)
+		e.RenderSprites(screen, camera, depth)
+		e.Particles.Render(screen, camera, depth)
+	}
+}
+
+// chunksStale reports whether the chunks are not of the layers of the
+// current zone, for example after layers were added or replaced.
+func (e *Engine) chunksStale() bool {
+	if len(e.Chunks) != len(e.Zone.Layers) {
+		return true
+	}
+	for depth, chunks := range e.Chunks {
+		if chunks.Layer != e.Zone.Layers[depth] {
+			return true
+		}
+	}
+	return false
+}
+
+// resetChunks drops all cached chunks and prepares new, empty caches
+// for the layers of the current zone.
+func (e *Engine) resetChunks() {
+	for _, chunks := range e.Chunks {
+		chunks.Deallocate()
+	}
+	e.Chunks = nil
 	if e.Zone == nil {
 		return
 	}
 	for i, layer := range e.Zone.Layers {
-		e.RenderLayer(screen, camera, layer, i)
+		e.Chunks = append(e.Chunks, NewChunks(layer, i))
+	}
+}
+
+// InvalidateTiles marks the cached chunks that cover the rectangle r,
+// expressed in tiles, of the layer at depth as in need of rendering.
+// Tiles that are Set are invalidated by Update, so this is only needed
+// after changing tiles of a layer in another way. The emitters that emit
+// from flagged tiles of the layer follow the changed tiles.
+func (e *Engine) InvalidateTiles(depth int, r xgal.Rectangle) {
+	e.moveEmitters(depth)
+	if depth < 0 || depth >= len(e.Chunks) {
+		return
+	}
+	e.Chunks[depth].Invalidate(r)
+}
+
+// invalidateChanged invalidates the tiles of the layers of the zone that
+// were Set since the last update.
+func (e *Engine) invalidateChanged() {
+	if e.Zone == nil {
+		return
+	}
+	for depth, layer := range e.Zone.Layers {
+		if r := layer.Tiles.TakeChanged(); !r.Empty() {
+			e.InvalidateTiles(depth, r)
+		}
 	}
 }
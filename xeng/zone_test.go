@@ -0,0 +1,214 @@
+package xeng
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+import (
+	"github.com/xmasengine/xmas/xdat"
+	"github.com/xmasengine/xmas/xgal"
+)
+
+// benchZone returns an engine with a zone of count layers filled with
+// varied tiles, so no layer is drawn as empty.
+func benchZone(count int) *Engine {
+	texture := xgal.Prepare(128, 128)
+	zone := &xdat.Zone{Name: "bench"}
+	for i := 0; i < count; i++ {
+		layer := xdat.NewLayer()
+		layer.Texture = texture
+		for y, row := range layer.Tiles.Rows {
+			for x := range row {
+				o := xdat.Orientation((x + y) % int(xdat.OrientCount))
+				row[x] = xdat.MakeTile(uint8(1+x%15), uint8(y%16), o.Flag())
+			}
+		}
+		zone.Layers = append(zone.Layers, layer)
+	}
+	return &Engine{Zone: zone}
+}
+
+var benchZooms = []int{1, 2, 4}
+var benchLayers = []int{1, 2, 4}
+
+// BenchmarkRenderLayer draws every visible tile one by one, every frame.
+func BenchmarkRenderLayer(b *testing.B) {
+	for _, zoom := range benchZooms {
+		for _, count := range benchLayers {
+			b.Run(fmt.Sprintf("zoom%d/layers%d", zoom, count), func(b *testing.B) {
+				e := benchZone(count)
+				screen := xgal.Prepare(ViewWidth*zoom, ViewHeight*zoom)
+				camera := xgal.Rect(4, 4, 4+ViewWidth*zoom, 4+ViewHeight*zoom)
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					for d, layer := range e.Zone.Layers {
+						e.RenderLayer(screen, camera, layer, d)
+					}
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkRenderChunks draws the same zones from the chunk cache.
+func BenchmarkRenderChunks(b *testing.B) {
+	for _, zoom := range benchZooms {
+		for _, count := range benchLayers {
+			b.Run(fmt.Sprintf("zoom%d/layers%d", zoom, count), func(b *testing.B) {
+				e := benchZone(count)
+				screen := xgal.Prepare(ViewWidth*zoom, ViewHeight*zoom)
+				camera := xgal.Rect(4, 4, 4+ViewWidth*zoom, 4+ViewHeight*zoom)
+				e.RenderZone(screen, camera) // fill the cache
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					e.RenderZone(screen, camera)
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkRenderChunksEdit draws from the chunk cache while one tile
+// is changed every frame, as happens when painting in the editor.
+func BenchmarkRenderChunksEdit(b *testing.B) {
+	for _, zoom := range benchZooms {
+		b.Run(fmt.Sprintf("zoom%d", zoom), func(b *testing.B) {
+			e := benchZone(xdat.LayerCount)
+			screen := xgal.Prepare(ViewWidth*zoom, ViewHeight*zoom)
+			camera := xgal.Rect(4, 4, 4+ViewWidth*zoom, 4+ViewHeight*zoom)
+			e.RenderZone(screen, camera)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				at := xgal.Pt(i%20, i%12)
+				e.Zone.Layers[0].Set(at, xdat.MakeTile(2, 2, 0))
+				e.InvalidateTiles(0, xgal.Bound(at.X, at.Y, 1, 1))
+				e.RenderZone(screen, camera)
+			}
+		})
+	}
+}
+
+func TestChunksInvalidate(t *testing.T) {
+	e := benchZone(2)
+	screen := xgal.Prepare(ViewWidth, ViewHeight)
+	e.RenderZone(screen, xgal.Rect(0, 0, ViewWidth, ViewHeight))
+
+	chunks := e.Chunks[1]
+	if chunks.Width != xdat.LayerWidth/ChunkSize || chunks.Height != xdat.LayerHeight/ChunkSize {
+		t.Fatalf("chunks size: %d %d", chunks.Width, chunks.Height)
+	}
+	if chunks.Chunk(0, 0).Surface == nil || chunks.Chunk(0, 0).Dirty {
+		t.Fatalf("visible chunk not rendered")
+	}
+	if chunks.Chunk(3, 3).Surface != nil {
+		t.Fatalf("invisible chunk rendered")
+	}
+
+	e.InvalidateTiles(1, xgal.Rect(15, 15, 17, 16))
+	for _, c := range []struct {
+		cx, cy int
+		dirty  bool
+	}{{0, 0, true}, {1, 0, true}, {0, 1, false}, {2, 0, false}} {
+		if observe := chunks.Chunk(c.cx, c.cy).Dirty; observe != c.dirty {
+			t.Errorf("chunk %d %d: dirty %t", c.cx, c.cy, observe)
+		}
+	}
+	if e.Chunks[0].Chunk(0, 0).Dirty {
+		t.Errorf("other layer invalidated")
+	}
+
+	e.Frame++
+	e.RenderZone(screen, xgal.Rect(0, 0, 8, 8))
+	if !chunks.Chunk(1, 0).Dirty || chunks.Chunk(0, 0).Dirty {
+		t.Errorf("frame advance: only the visible chunk should be rendered")
+	}
+}
+
+func TestChunksAnimate(t *testing.T) {
+	e := benchZone(1)
+	screen := xgal.Prepare(ViewWidth, ViewHeight)
+	camera := xgal.Rect(0, 0, ViewWidth, ViewHeight)
+	layer := e.Zone.Layers[0]
+	e.RenderZone(screen, camera)
+	layer.Set(xgal.Pt(20, 3), xdat.MakeTile(1, 0, xdat.FlagAnimate))
+	e.invalidateChanged()
+	if !e.Chunks[0].Chunk(1, 0).Dirty {
+		t.Fatalf("chunk of a set tile not invalidated")
+	}
+	e.RenderZone(screen, camera)
+	chunks := e.Chunks[0]
+	if !chunks.Chunk(1, 0).Animated || chunks.Chunk(0, 0).Animated {
+		t.Fatalf("animated chunks: %v %v", chunks.Chunk(1, 0).Animated, chunks.Chunk(0, 0).Animated)
+	}
+
+	e.Frame++
+	chunks.SetFrame(e.Frame)
+	if !chunks.Chunk(1, 0).Dirty || chunks.Chunk(0, 0).Dirty {
+		t.Errorf("frame advance: only the animated chunk should be invalidated")
+	}
+	if got := layer.Get(xgal.Pt(20, 3)).Frame(5); got.X != 2 {
+		t.Errorf("frame 5 shows cell %d", got.X)
+	}
+
+	replaced := xdat.NewLayer()
+	replaced.Texture = layer.Texture
+	e.Zone.Layers[0] = replaced
+	e.RenderZone(screen, camera)
+	if e.Chunks[0].Layer != replaced {
+		t.Errorf("chunks of a replaced layer kept")
+	}
+}
+
+const atlasHero = `{ "frames": {
+   "hero 0.ase": { "frame": { "x": 0, "y": 0, "w": 8, "h": 16 }, "sourceSize": { "w": 8, "h": 16 }, "duration": 100 }
+ },
+ "meta": { "image": "hero.png", "size": { "w": 8, "h": 16 },
+  "frameTags": [ { "name": "walk", "from": 0, "to": 0, "direction": "forward" } ] }
+}`
+
+func TestAtlasTileAndSprite(t *testing.T) {
+	atlas := &xgal.Atlas{Root: "pack", Surface: xgal.Prepare(64, 64), Regions: map[string]xgal.Region{
+		"tile.png": {Area: xgal.Rect(8, 8, 40, 24), Size: xgal.Pt(32, 16)},
+		"hero.png": {Area: xgal.Rect(40, 30, 46, 44), Offset: xgal.Pt(1, 2), Size: xgal.Pt(8, 16)},
+	}}
+	xgal.AddAtlas(atlas)
+	defer xgal.RemoveAtlas(atlas)
+
+	tiles, err := xgal.Texture(nil, "pack/tile.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tiles.Bounds() != xgal.Rect(0, 0, 32, 16) {
+		t.Errorf("tile texture from the atlas at %v", tiles.Bounds())
+	}
+	if again, _ := xgal.Texture(nil, "tile.png"); again != tiles {
+		t.Errorf("tile texture made twice")
+	}
+	sheet, err := xgal.Texture(nil, "hero.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sheet.Bounds() != xgal.Rect(0, 0, 8, 16) {
+		t.Errorf("trimmed sheet from the atlas at %v", sheet.Bounds())
+	}
+
+	e := &Engine{Zone: &xdat.Zone{Name: "atlas"}}
+	layer := xdat.NewLayer()
+	layer.Texture = tiles
+	layer.Set(xgal.Pt(1, 1), xdat.MakeTile(1, 0, 0))
+	screen := xgal.Prepare(ViewWidth, ViewHeight)
+	e.RenderLayer(screen, xgal.Rect(0, 0, ViewWidth, ViewHeight), layer, 0)
+
+	hero, err := xdat.ReadAseprite(strings.NewReader(atlasHero))
+	if err != nil {
+		t.Fatal(err)
+	}
+	an := xdat.Animator{Set: hero}
+	an.Play("walk", xdat.South)
+	if _, ok := an.Current(); !ok {
+		t.Fatalf("no frame to draw")
+	}
+	an.Draw(screen, sheet, xgal.Pt(20, 20))
+}
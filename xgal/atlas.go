@@ -0,0 +1,96 @@
+package xgal
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Region is a named image in an atlas. A trimmed region lost the
+// transparent border of its image, and its pixels are at Offset in an
+// image of Size.
+type Region struct {
+	Area   Rectangle // Area of the region in the atlas.
+	Offset Point     // Offset of the area in the untrimmed image.
+	Size   Point     // Size of the untrimmed image.
+}
+
+// Trimmed returns whether the region lost a transparent border.
+func (r Region) Trimmed() bool {
+	return r.Offset != (Point{}) || r.Size != r.Area.Size()
+}
+
+// Atlas is a surface with many images packed in it. The images are named
+// by their file name, and the frames of sprite sheets by the file name of
+// the sheet and their name after a #, like gfx/character.png#walk_0.
+type Atlas struct {
+	Root    string            // Root is a directory that names may start with, like pack.
+	Surface *Surface          // Surface the images are packed in.
+	Regions map[string]Region // Regions of the images by name.
+
+	subs map[string]*Surface // subs are the surfaces Sub made, by name.
+}
+
+// Find returns the region of the named image, and false if it is not in
+// the atlas.
+func (a *Atlas) Find(name string) (Region, bool) {
+	if r, ok := a.Regions[name]; ok {
+		return r, true
+	}
+	if rest, ok := strings.CutPrefix(name, a.Root+"/"); ok && a.Root != "" {
+		r, ok := a.Regions[rest]
+		return r, ok
+	}
+	return Region{}, false
+}
+
+// Sub returns the named image as a surface of its untrimmed size, and
+// false if it is not in the atlas. Unlike a sub image of the atlas, the
+// surface starts at 0, 0 like any loaded image, so it can be drawn from
+// with the same rectangles. The surface is made once, and ebiten packs it
+// in its own atlas.
+func (a *Atlas) Sub(name string) (*Surface, bool) {
+	if sub, ok := a.subs[name]; ok {
+		return sub, true
+	}
+	r, ok := a.Find(name)
+	if !ok {
+		return nil, false
+	}
+	area := a.Surface.SubImage(r.Area).(*Surface)
+	sub := ebiten.NewImage(r.Size.X, r.Size.Y)
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(float64(r.Offset.X), float64(r.Offset.Y))
+	sub.DrawImage(area, op)
+	if a.subs == nil {
+		a.subs = map[string]*Surface{}
+	}
+	a.subs[name] = sub
+	return sub, true
+}
+
+// atlases are the atlases that Texture finds images in.
+var atlases []*Atlas
+
+// AddAtlas makes Texture find images in the atlas. Atlases added later
+// take precedence.
+func AddAtlas(a *Atlas) {
+	atlases = append(atlases, a)
+}
+
+// RemoveAtlas stops Texture from finding images in the atlas.
+func RemoveAtlas(a *Atlas) {
+	atlases = slices.DeleteFunc(atlases, func(b *Atlas) bool { return a == b })
+}
+
+// Sprite returns the named image from the added atlases, and false if
+// none of them has it.
+func Sprite(name string) (*Surface, bool) {
+	for i := len(atlases) - 1; i >= 0; i-- {
+		if sub, ok := atlases[i].Sub(name); ok {
+			return sub, true
+		}
+	}
+	return nil, false
+}
@@ -38,6 +38,10 @@ func decodeAudio(name string, reader io.Reader) (io.ReadSeeker, error) {
 		return mp3.DecodeF32(reader)
 	case ".ogg":
 		return vorbis.DecodeF32(reader)
+	case ".psg":
+		return decodeScore(reader)
+	case ".sfx":
+		return decodeSfx(reader)
 	default:
 		return nil, fmt.Errorf("xgal: unsupported audio format: %s", name)
 	}
@@ -50,7 +54,8 @@ type Clip struct {
 }
 
 // Sample loads an audio file from fsys as a [Clip].
-// Supported formats: WAV (.wav), MP3 (.mp3), OGG Vorbis (.ogg).
+// Supported formats: WAV (.wav), MP3 (.mp3), OGG Vorbis (.ogg), PSG
+// scores (.psg), see [Score], and sound effects (.sfx), see [Sfx].
 func Sample(fsys fs.FS, name string) (*Clip, error) {
 	buf, err := fs.ReadFile(fsys, name)
 	if err != nil {
@@ -99,7 +104,9 @@ type Song struct {
 }
 
 // Track loads an audio file from fsys as a looping [Song].
-// Supported formats: WAV (.wav), MP3 (.mp3), OGG Vorbis (.ogg).
+// Supported formats: WAV (.wav), MP3 (.mp3), OGG Vorbis (.ogg) and
+// PSG scores (.psg), see [Score]. Scores with a loop point play their
+// intro once and then loop from there.
 func Track(fsys fs.FS, name string) (*Song, error) {
 	buf, err := fs.ReadFile(fsys, name)
 	if err != nil {
@@ -120,7 +127,12 @@ func Track(fsys fs.FS, name string) (*Song, error) {
 		return nil, err
 	}
 
-	player, err := ctx.NewPlayerF32(audio.NewInfiniteLoopF32(stream, length))
+	loop := audio.NewInfiniteLoopF32(stream, length)
+	if l, ok := stream.(looper); ok && l.LoopStart() > 0 {
+		start := l.LoopStart()
+		loop = audio.NewInfiniteLoopWithIntroF32(stream, start, length-start)
+	}
+	player, err := ctx.NewPlayerF32(loop)
 	if err != nil {
 		return nil, err
 	}
@@ -7,6 +7,8 @@ import (
 )
 
 // BlitOpts specifies the options such as flip or rotation for Blit.
+// Rotation is applied first, then the horizontal flip, then the vertical
+// flip. Together they can express all eight orientations of a tile.
 type BlitOpts struct {
 	FlipH bool
 	FlipV bool
@@ -51,6 +53,16 @@ var (
 	BlendAdd BlendMode = ebiten.BlendLighter
 	// BlendErase clears the destination.
 	BlendErase BlendMode = ebiten.BlendClear
+	// BlendMultiply multiplies the destination with the source, which
+	// darkens it. Useful for light maps.
+	BlendMultiply BlendMode = ebiten.Blend{
+		BlendFactorSourceRGB:        ebiten.BlendFactorDestinationColor,
+		BlendFactorSourceAlpha:      ebiten.BlendFactorZero,
+		BlendFactorDestinationRGB:   ebiten.BlendFactorZero,
+		BlendFactorDestinationAlpha: ebiten.BlendFactorOne,
+		BlendOperationRGB:           ebiten.BlendOperationAdd,
+		BlendOperationAlpha:         ebiten.BlendOperationAdd,
+	}
 )
 
 func (opts BlitOpts) toDrawImageOptions(dr, sr Rectangle) *ebiten.DrawImageOptions {
@@ -101,7 +113,7 @@ func (opts BlitOpts) toDrawImageOptions(dr, sr Rectangle) *ebiten.DrawImageOptio
 	}
 
 	// Scale and position
-	if dw != w && dh != h {
+	if dw != w || dh != h {
 		op.GeoM.Scale(dw/w, dh/h)
 	}
 	op.GeoM.Translate(float64(dr.Min.X), float64(dr.Min.Y))
@@ -116,7 +128,7 @@ func drawImageOptions(dr, sr Rectangle) *ebiten.DrawImageOptions {
 	op := &ebiten.DrawImageOptions{}
 
 	// Scale and position
-	if dw != sw && dh != sh {
+	if dw != sw || dh != sh {
 		op.GeoM.Scale(dw/sw, dh/sh)
 	}
 	op.GeoM.Translate(float64(dr.Min.X), float64(dr.Min.Y))
@@ -146,12 +158,81 @@ func Blend(dst, src *Surface, dr, sr Rectangle, mode BlendMode, ops ...BlitOpts)
 	op := &ebiten.DrawImageOptions{}
 	if len(ops) >= 1 {
 		op = ops[0].toDrawImageOptions(dr, sr)
+	} else {
+		op = drawImageOptions(dr, sr)
+	}
+
+	op.Blend = mode
+	dst.DrawImage(sub, op)
+}
+
+// Glaze is like Blend but also multiplies the colors of src with col,
+// for example to draw a white light texture in any color.
+func Glaze(dst, src *Surface, dr, sr Rectangle, mode BlendMode, col RGBA, ops ...BlitOpts) {
+	sub := src.SubImage(sr).(*ebiten.Image)
+	op := &ebiten.DrawImageOptions{}
+	if len(ops) >= 1 {
+		op = ops[0].toDrawImageOptions(dr, sr)
+	} else {
+		op = drawImageOptions(dr, sr)
 	}
 
 	op.Blend = mode
+	op.ColorScale.ScaleWithColor(col)
 	dst.DrawImage(sub, op)
 }
 
+// Turn draws sr of src centered on cx, cy of dst, rotated clockwise by
+// angle radians, scaled by scale and with the colors multiplied by col.
+// Unlike Blit it can rotate by any angle, which suits particles. Col is
+// not premultiplied, so a color with an alpha of 0 draws nothing.
+func Turn(dst, src *Surface, sr Rectangle, cx, cy, angle, scale float64, col RGBA) {
+	sub := src.SubImage(sr).(*ebiten.Image)
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(-float64(sr.Dx())/2, -float64(sr.Dy())/2)
+	op.GeoM.Scale(scale, scale)
+	op.GeoM.Rotate(angle)
+	op.GeoM.Translate(cx, cy)
+	op.ColorScale.Scale(float32(col.R)/0xff, float32(col.G)/0xff, float32(col.B)/0xff, 1)
+	op.ColorScale.ScaleAlpha(float32(col.A) / 0xff)
+	dst.DrawImage(sub, op)
+}
+
+// Draw draws what render draws in bounds onto dst, with the motion. A
+// motion that is not still has render draw on a canvas first, which it
+// keeps for the next draw. The canvas has the same bounds as dst, which
+// may be a sub image that does not start at 0, 0, so render draws at the
+// same coordinates on either.
+func (m *Motion) Draw(dst *Surface, bounds Rectangle, render func(dst *Surface)) {
+	if m.Still() {
+		render(dst)
+		return
+	}
+	if m.Alpha <= 0 || m.Scale <= 0 {
+		return
+	}
+	area := dst.Bounds()
+	if m.canvas == nil || m.canvas.Bounds().Size() != area.Max {
+		m.canvas = ebiten.NewImage(area.Max.X, area.Max.Y)
+	}
+	canvas := m.canvas.SubImage(area).(*ebiten.Image)
+	canvas.Clear()
+	render(canvas)
+
+	bounds = bounds.Intersect(area)
+	if bounds.Empty() {
+		return
+	}
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(-float64(bounds.Dx())/2, -float64(bounds.Dy())/2)
+	op.GeoM.Scale(m.Scale, m.Scale)
+	c := bounds.Min.Add(bounds.Max).Add(m.Offset.Mul(2))
+	op.GeoM.Translate(float64(c.X)/2, float64(c.Y)/2)
+	op.ColorScale.ScaleWithColor(m.Tint)
+	op.ColorScale.ScaleAlpha(float32(m.Alpha))
+	dst.DrawImage(m.canvas.SubImage(bounds).(*ebiten.Image), op)
+}
+
 // Scale draws src onto dst scaled by sx and sy.
 func Scale(dst, src *Surface, sx, sy float64) {
 	op := &ebiten.DrawImageOptions{}
@@ -0,0 +1,254 @@
+package xgal
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/xmasengine/xmas/wfs"
+)
+
+// Previewer loads a preview of the named file of fsys.
+type Previewer func(fsys fs.FS, name string) (*Surface, error)
+
+// Previewers are the previewers of a new [Browser] by extension. Images
+// are previewed, other packages such as xvec can add their own.
+var Previewers = map[string]Previewer{
+	".png":  Texture,
+	".gif":  Texture,
+	".jpg":  Texture,
+	".jpeg": Texture,
+}
+
+// ErrNoName is returned when a file dialog is done without a file name.
+var ErrNoName = errors.New("no file name")
+
+// RecentMax is how many names a [Recent] keeps if its Max is not set.
+const RecentMax = 10
+
+// Recent are the names of the files opened or saved recently, the most
+// recent first.
+type Recent struct {
+	Names []string
+	Max   int
+}
+
+// RecentFiles are the recent files of the browsers that do not have their
+// own, so all file dialogs of an editor share them.
+var RecentFiles = &Recent{}
+
+// Add adds the name in front of the recent names.
+func (r *Recent) Add(name string) {
+	r.Names = slices.DeleteFunc(r.Names, func(n string) bool { return n == name })
+	r.Names = slices.Insert(r.Names, 0, name)
+	r.Names = r.Names[:min(len(r.Names), cmp.Or(r.Max, RecentMax))]
+}
+
+// Browser is the state of a file dialog that browses the directories of
+// an [fs.FS] and writes through a [wfs.CreateFS]. The file dialogs of xlui
+// and xui share it, like they share [Edit].
+type Browser struct {
+	FS         fs.FS
+	Dir        string      // Dir is the directory shown, "." for the root.
+	Name       string      // Name is the file name in Dir.
+	Exts       []string    // Exts are the extensions of the files shown, all if empty.
+	Save       bool        // Save is set if the file is saved rather than opened.
+	Entries    []wfs.Entry // Entries of Dir, with ".." first if Dir is not the root.
+	Recent     *Recent
+	Previewers map[string]Previewer
+
+	preview   *Surface
+	previewed string
+}
+
+// NewBrowser returns a browser of fsys, at the named file if it is set.
+// Only directories and files with one of the extensions are shown.
+func NewBrowser(fsys fs.FS, name string, save bool, exts ...string) *Browser {
+	b := &Browser{
+		FS:         fsys,
+		Dir:        ".",
+		Exts:       exts,
+		Save:       save,
+		Recent:     RecentFiles,
+		Previewers: maps.Clone(Previewers),
+	}
+	if err := b.Go(name); err != nil && name != "" {
+		b.Name = path.Base(name) // Its directory is gone, so save it in the root.
+		b.Refresh()
+	}
+	return b
+}
+
+// Refresh lists Dir again.
+func (b *Browser) Refresh() error {
+	entries, err := wfs.List(b.FS, b.Dir, b.Exts...)
+	if err != nil {
+		return err
+	}
+	if b.Dir != "." {
+		entries = slices.Insert(entries, 0, wfs.Entry{Name: "..", Dir: true, Layer: -1})
+	}
+	b.Entries = entries
+	return nil
+}
+
+// Go goes to the directory of the named file, and selects the file. If
+// the directory can not be listed, the browser stays where it is.
+func (b *Browser) Go(name string) error {
+	if name == "" {
+		return b.Refresh()
+	}
+	dir, file := path.Split(path.Clean(name))
+	old := b.Dir
+	b.Dir = path.Clean(cmp.Or(dir, "."))
+	if err := b.Refresh(); err != nil {
+		b.Dir = old
+		return err
+	}
+	b.Name = file
+	return nil
+}
+
+// Enter enters the named directory of Dir, or the parent for "..".
+func (b *Browser) Enter(dir string) error {
+	old := b.Dir
+	if dir == ".." {
+		b.Dir = path.Dir(b.Dir)
+	} else {
+		b.Dir = path.Join(b.Dir, dir)
+	}
+	if err := b.Refresh(); err != nil {
+		b.Dir = old
+		return err
+	}
+	return nil
+}
+
+// Open opens the entry: it enters a directory, or selects a file and
+// reports true.
+func (b *Browser) Open(entry wfs.Entry) (bool, error) {
+	if entry.Dir {
+		return false, b.Enter(entry.Name)
+	}
+	b.Name = entry.Name
+	return true, nil
+}
+
+// Label returns the text that an entry is shown with: directories end in
+// a slash, and entries of a layered file system have their layer.
+func (b *Browser) Label(entry wfs.Entry) string {
+	label := entry.Name
+	if entry.Dir {
+		label += "/"
+	}
+	if entry.Layer >= 0 {
+		label += fmt.Sprintf(" [%d]", entry.Layer)
+	}
+	return label
+}
+
+// Path returns the path of the selected file in the file system, or ""
+// if no file name was given.
+func (b *Browser) Path() string {
+	if b.Name == "" {
+		return ""
+	}
+	return path.Join(b.Dir, b.Name)
+}
+
+// Check checks the file name when the dialog is done. It returns the
+// path of the file, or "" if the name was of a directory, which is
+// entered instead. Files to open must exist, the name of files to save
+// must have one of the extensions, if any.
+func (b *Browser) Check() (string, error) {
+	name := b.Path()
+	if name == "" {
+		return "", ErrNoName
+	}
+	if !fs.ValidPath(name) {
+		return "", &fs.PathError{Op: "check", Path: name, Err: fs.ErrInvalid}
+	}
+	if info, err := fs.Stat(b.FS, name); err == nil && info.IsDir() {
+		dir := b.Name
+		b.Name = ""
+		return "", b.Enter(dir)
+	} else if err != nil && !b.Save {
+		return "", err
+	}
+	if b.Save && !wfs.HasExt(name, b.Exts...) {
+		return "", fmt.Errorf("%s: not one of %s", name, strings.Join(b.Exts, " "))
+	}
+	return name, nil
+}
+
+// Overwrites reports whether saving the named file would overwrite one.
+func (b *Browser) Overwrites(name string) bool {
+	return b.Save && wfs.Exists(b.FS, name)
+}
+
+// Done adds the name to the recent files.
+func (b *Browser) Done(name string) {
+	if b.Recent != nil {
+		b.Recent.Add(name)
+	}
+}
+
+// Recents returns the recent files with one of the extensions.
+func (b *Browser) Recents() []string {
+	if b.Recent == nil {
+		return nil
+	}
+	var names []string
+	for _, name := range b.Recent.Names {
+		if wfs.HasExt(name, b.Exts...) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Create creates the named file in the file system, which must be a
+// [wfs.CreateFS], and adds it to the recent files.
+func (b *Browser) Create(name string) (wfs.WriterFile, error) {
+	out, err := wfs.Create(b.FS, name)
+	if err != nil {
+		return nil, err
+	}
+	b.Done(name)
+	b.Refresh()
+	return out, nil
+}
+
+// Mkdir creates the named directory in Dir, in a file system that is a
+// [wfs.MkdirFS].
+func (b *Browser) Mkdir(name string) error {
+	if name == "" || strings.Contains(name, "/") {
+		return &fs.PathError{Op: "mkdir", Path: name, Err: fs.ErrInvalid}
+	}
+	if err := wfs.Mkdir(b.FS, path.Join(b.Dir, name), 0o755); err != nil {
+		return err
+	}
+	return b.Refresh()
+}
+
+// Preview returns the preview of the selected file, or nil if there is
+// no previewer for it or it fails to load. Previews are kept until
+// another file is selected.
+func (b *Browser) Preview() *Surface {
+	name := b.Path()
+	if name == b.previewed {
+		return b.preview
+	}
+	b.previewed, b.preview = name, nil
+	preview, ok := b.Previewers[strings.ToLower(path.Ext(name))]
+	if !ok || !wfs.Exists(b.FS, name) {
+		return nil
+	}
+	b.preview, _ = preview(b.FS, name)
+	return b.preview
+}
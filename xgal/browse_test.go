@@ -0,0 +1,87 @@
+package xgal
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/xmasengine/xmas/wfs"
+)
+
+func TestBrowser(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.png":       {Data: []byte("a")},
+		"notes.txt":   {Data: []byte("n")},
+		"art/b.png":   {Data: []byte("b")},
+		"art/c.xvec":  {Data: []byte("c")},
+		"art/sub/d.x": {Data: []byte("d")},
+	}
+	b := NewBrowser(fsys, "art/b.png", false, ".png")
+	if b.Dir != "art" || b.Name != "b.png" {
+		t.Fatalf("browser at %s %s", b.Dir, b.Name)
+	}
+	var labels []string
+	for _, entry := range b.Entries {
+		labels = append(labels, b.Label(entry))
+	}
+	if len(labels) != 3 || labels[0] != "../" || labels[1] != "sub/" || labels[2] != "b.png" {
+		t.Errorf("entries %v", labels)
+	}
+
+	if err := b.Enter(".."); err != nil || b.Dir != "." || len(b.Entries) != 2 {
+		t.Errorf("up to %s with %v: %v", b.Dir, b.Entries, err)
+	}
+	if ok, err := b.Open(wfs.Entry{Name: "art", Dir: true}); ok || err != nil || b.Dir != "art" {
+		t.Errorf("open of a directory: %v %v", ok, err)
+	}
+	if ok, _ := b.Open(wfs.Entry{Name: "b.png"}); !ok || b.Path() != "art/b.png" {
+		t.Errorf("open of a file: %v %s", ok, b.Path())
+	}
+
+	b.Name = "sub"
+	if name, err := b.Check(); name != "" || err != nil || b.Dir != "art/sub" {
+		t.Errorf("check of a directory: %q %v in %s", name, err, b.Dir)
+	}
+	if _, err := b.Check(); !errors.Is(err, ErrNoName) {
+		t.Errorf("check without a name: %v", err)
+	}
+	b.Name = "missing.png"
+	if _, err := b.Check(); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("open of a missing file: %v", err)
+	}
+}
+
+func TestBrowserSave(t *testing.T) {
+	fsys := fstest.MapFS{"a.png": {Data: []byte("a")}}
+	b := NewBrowser(fsys, "gone/new.png", true, ".png")
+	b.Recent = &Recent{Max: 2}
+	if b.Dir != "." || b.Name != "new.png" {
+		t.Errorf("browser at %s %s", b.Dir, b.Name)
+	}
+	if name, err := b.Check(); name != "new.png" || err != nil || b.Overwrites(name) {
+		t.Errorf("check of a new file: %q %v", name, err)
+	}
+	b.Name = "a.png"
+	if name, _ := b.Check(); !b.Overwrites(name) {
+		t.Errorf("a.png is not overwritten")
+	}
+	b.Name = "a.txt"
+	if _, err := b.Check(); err == nil {
+		t.Errorf("saved with the wrong extension")
+	}
+	if _, err := b.Create("a.png"); !errors.Is(err, fs.ErrPermission) {
+		t.Errorf("created in a read only FS: %v", err)
+	}
+	if err := b.Mkdir("dir"); !errors.Is(err, fs.ErrPermission) {
+		t.Errorf("made a directory in a read only FS: %v", err)
+	}
+
+	b.Done("one.png")
+	b.Done("two.txt")
+	b.Done("three.png")
+	b.Done("three.png")
+	if got := b.Recents(); len(got) != 1 || got[0] != "three.png" {
+		t.Errorf("recent %v of %v", got, b.Recent.Names)
+	}
+}
@@ -0,0 +1,467 @@
+package xgal
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Mask limits what text an [Edit] accepts.
+type Mask struct {
+	Name  string                  // Name of the mask, as used in layouts.
+	Allow func(r rune) bool       // Allow reports whether the rune may be typed.
+	Check func(text string) error // Check validates the whole text, if set.
+}
+
+var (
+	// IntegerMask accepts decimal integers with an optional sign.
+	IntegerMask = Mask{
+		Name: "integer",
+		Allow: func(r rune) bool {
+			return (r >= '0' && r <= '9') || r == '-' || r == '+'
+		},
+		Check: func(text string) error {
+			_, err := strconv.Atoi(text)
+			return err
+		},
+	}
+
+	// HexMask accepts hexadecimal numbers.
+	HexMask = Mask{
+		Name: "hex",
+		Allow: func(r rune) bool {
+			return (r >= '0' && r <= '9') || (r >= 'a' && r <= 'f') || (r >= 'A' && r <= 'F')
+		},
+		Check: func(text string) error {
+			_, err := strconv.ParseUint(text, 16, 64)
+			return err
+		},
+	}
+
+	// FloatMask accepts decimal numbers with a fraction and an exponent.
+	FloatMask = Mask{
+		Name: "float",
+		Allow: func(r rune) bool {
+			return (r >= '0' && r <= '9') || strings.ContainsRune("+-.eE", r)
+		},
+		Check: func(text string) error {
+			_, err := strconv.ParseFloat(text, 64)
+			return err
+		},
+	}
+
+	// FilenameMask accepts names of files without a directory, that are
+	// valid on all common platforms.
+	FilenameMask = Mask{
+		Name: "filename",
+		Allow: func(r rune) bool {
+			return unicode.IsPrint(r) && !strings.ContainsRune(`/\:*?"<>|`, r)
+		},
+		Check: func(text string) error {
+			switch {
+			case text == "", text == ".", text == "..":
+				return errors.New("not a file name: " + strconv.Quote(text))
+			case strings.HasSuffix(text, " "), strings.HasSuffix(text, "."):
+				return errors.New("file name ends with a space or dot")
+			}
+			return nil
+		},
+	}
+)
+
+// Masks are the masks by name.
+var Masks = map[string]Mask{
+	IntegerMask.Name:  IntegerMask,
+	HexMask.Name:      HexMask,
+	FloatMask.Name:    FloatMask,
+	FilenameMask.Name: FilenameMask,
+}
+
+// EditUndoLimit is how many edits an [Edit] can undo.
+const EditUndoLimit = 100
+
+// editState is the state an edit returns to on undo.
+type editState struct {
+	text           []rune
+	cursor, anchor int
+}
+
+// Edit is the text of an entry or area that is being edited, with a
+// cursor, a selection, a clipboard and an undo stack. The text is a
+// slice of runes so the cursor can index it directly. Edits of multiple
+// lines separate the lines with newlines.
+type Edit struct {
+	Text      []rune
+	Cursor    int  // Cursor is the index of the rune the cursor is before.
+	Anchor    int  // Anchor is the other end of the selection, Cursor if none.
+	Max       int  // Max is the maximum length in runes, 0 for no limit.
+	Multiline bool // Multiline edits take Enter and the up and down keys.
+	Page      int  // Page is how many lines page up and down move.
+	Mask      Mask // Mask limits the text, if set.
+
+	undo, redo []editState
+	typing     bool // typing is set while typed runes join the last undo.
+}
+
+// NewEdit returns an edit of the text with the cursor at the end.
+func NewEdit(text string) *Edit {
+	e := &Edit{}
+	e.SetText(text)
+	return e
+}
+
+// String returns the text.
+func (e *Edit) String() string {
+	return string(e.Text)
+}
+
+// SetText replaces the text and puts the cursor at the end. It forgets
+// the undo stack, since the text did not come from editing.
+func (e *Edit) SetText(text string) {
+	e.Text = []rune(text)
+	e.Cursor = len(e.Text)
+	e.Anchor = e.Cursor
+	e.undo, e.redo = nil, nil
+	e.typing = false
+}
+
+// Valid returns the error of the check of the mask, or nil if the text
+// is valid. An empty text is valid, so an empty entry is not an error.
+func (e *Edit) Valid() error {
+	if e.Mask.Check == nil || len(e.Text) == 0 {
+		return nil
+	}
+	return e.Mask.Check(e.String())
+}
+
+// Selection returns the start and end of the selection.
+func (e *Edit) Selection() (lo, hi int) {
+	return min(e.Cursor, e.Anchor), max(e.Cursor, e.Anchor)
+}
+
+// Selected returns the selected text.
+func (e *Edit) Selected() string {
+	lo, hi := e.Selection()
+	return string(e.Text[lo:hi])
+}
+
+// HasSelection reports whether any text is selected.
+func (e *Edit) HasSelection() bool {
+	return e.Cursor != e.Anchor
+}
+
+// MoveTo moves the cursor to at. If extend is set, the selection is
+// extended to at, otherwise it is cleared.
+func (e *Edit) MoveTo(at int, extend bool) {
+	e.Cursor = min(max(at, 0), len(e.Text))
+	if !extend {
+		e.Anchor = e.Cursor
+	}
+	e.typing = false
+}
+
+// SelectAll selects all text.
+func (e *Edit) SelectAll() {
+	e.Anchor = 0
+	e.Cursor = len(e.Text)
+	e.typing = false
+}
+
+// save pushes the state for undo.
+func (e *Edit) save() {
+	e.undo = append(e.undo, editState{slices.Clone(e.Text), e.Cursor, e.Anchor})
+	if len(e.undo) > EditUndoLimit {
+		e.undo = slices.Delete(e.undo, 0, 1)
+	}
+	e.redo = nil
+}
+
+func (e *Edit) restore(from, to *[]editState) bool {
+	if len(*from) == 0 {
+		return false
+	}
+	state := (*from)[len(*from)-1]
+	*from = (*from)[:len(*from)-1]
+	*to = append(*to, editState{e.Text, e.Cursor, e.Anchor})
+	e.Text, e.Cursor, e.Anchor = state.text, state.cursor, state.anchor
+	e.typing = false
+	return true
+}
+
+// Undo undoes the last edit, and reports whether there was one.
+func (e *Edit) Undo() bool {
+	return e.restore(&e.undo, &e.redo)
+}
+
+// Redo redoes the last undone edit, and reports whether there was one.
+func (e *Edit) Redo() bool {
+	return e.restore(&e.redo, &e.undo)
+}
+
+// filter returns the runes that the mask and the lines allow.
+func (e *Edit) filter(runes []rune) []rune {
+	res := make([]rune, 0, len(runes))
+	for _, r := range runes {
+		switch {
+		case r == '\n' && e.Multiline:
+		case r == '\r' || r == '\n' || (r == '\t' && !e.Multiline):
+			continue
+		case e.Mask.Allow != nil && !e.Mask.Allow(r):
+			continue
+		}
+		res = append(res, r)
+	}
+	return res
+}
+
+// replace replaces the selection with runes.
+func (e *Edit) replace(runes []rune) {
+	lo, hi := e.Selection()
+	e.Text = slices.Replace(e.Text, lo, hi, runes...)
+	e.Cursor = lo + len(runes)
+	e.Anchor = e.Cursor
+}
+
+// Insert replaces the selection with the runes that the mask allows, as
+// far as they fit in Max. It reports whether the text changed, which it
+// does not if no runes are left. Runes that are typed one after another
+// are undone together.
+func (e *Edit) Insert(runes ...rune) bool {
+	runes = e.filter(runes)
+	if e.Max > 0 {
+		lo, hi := e.Selection()
+		room := e.Max - (len(e.Text) - (hi - lo))
+		runes = runes[:min(len(runes), max(room, 0))]
+	}
+	if len(runes) == 0 {
+		return false
+	}
+	if !e.typing || e.HasSelection() {
+		e.save()
+	}
+	e.replace(runes)
+	e.typing = len(runes) == 1 && !unicode.IsSpace(runes[0])
+	return true
+}
+
+// Delete deletes the selection, or else the rune or word before the
+// cursor if back is set, or after it otherwise. It reports whether the
+// text changed.
+func (e *Edit) Delete(back, word bool) bool {
+	if !e.HasSelection() {
+		switch {
+		case back && word:
+			e.Anchor = e.wordLeft(e.Cursor)
+		case back:
+			e.Anchor = max(e.Cursor-1, 0)
+		case word:
+			e.Anchor = e.wordRight(e.Cursor)
+		default:
+			e.Anchor = min(e.Cursor+1, len(e.Text))
+		}
+	}
+	if !e.HasSelection() {
+		return false
+	}
+	e.save()
+	e.replace(nil)
+	e.typing = false
+	return true
+}
+
+// Copy copies the selection to the clipboard.
+func (e *Edit) Copy() {
+	if e.HasSelection() {
+		Board(ClipboardText, []byte(e.Selected()))
+	}
+}
+
+// Cut copies the selection to the clipboard and deletes it. It reports
+// whether the text changed.
+func (e *Edit) Cut() bool {
+	if !e.HasSelection() {
+		return false
+	}
+	e.Copy()
+	return e.Delete(true, false)
+}
+
+// Paste replaces the selection with the text on the clipboard. It
+// reports whether the text changed.
+func (e *Edit) Paste() bool {
+	text := Paste(ClipboardText)
+	if len(text) == 0 {
+		return false
+	}
+	e.typing = false
+	return e.Insert([]rune(string(text))...)
+}
+
+// isWord reports whether the rune is part of a word.
+func isWord(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+// wordLeft returns the start of the word before at.
+func (e *Edit) wordLeft(at int) int {
+	for at > 0 && !isWord(e.Text[at-1]) {
+		at--
+	}
+	for at > 0 && isWord(e.Text[at-1]) {
+		at--
+	}
+	return at
+}
+
+// wordRight returns the end of the word after at.
+func (e *Edit) wordRight(at int) int {
+	for at < len(e.Text) && !isWord(e.Text[at]) {
+		at++
+	}
+	for at < len(e.Text) && isWord(e.Text[at]) {
+		at++
+	}
+	return at
+}
+
+// LineCol returns the line and column of the index at in the text.
+func (e *Edit) LineCol(at int) (line, col int) {
+	at = min(max(at, 0), len(e.Text))
+	start := 0
+	for i, r := range e.Text[:at] {
+		if r == '\n' {
+			line++
+			start = i + 1
+		}
+	}
+	return line, at - start
+}
+
+// Index returns the index in the text of the line and column, clamped
+// to the text and to the length of the line.
+func (e *Edit) Index(line, col int) int {
+	start := 0
+	for ; line > 0; line-- {
+		i := slices.Index(e.Text[start:], '\n')
+		if i < 0 {
+			break
+		}
+		start += i + 1
+	}
+	end := slices.Index(e.Text[start:], '\n')
+	if end < 0 {
+		end = len(e.Text) - start
+	}
+	return start + min(max(col, 0), end)
+}
+
+// Lines returns the lines of the text.
+func (e *Edit) Lines() []string {
+	return strings.Split(e.String(), "\n")
+}
+
+// Key handles an editing key, with shift extending the selection and
+// control moving by words or choosing the clipboard and undo commands.
+// It reports whether the key was handled, and whether the text changed.
+// Enter is only handled by multiline edits, which insert a newline.
+func (e *Edit) Key(key KeyCode, shift, control bool) (handled, changed bool) {
+	line, col := e.LineCol(e.Cursor)
+	switch key {
+	case KeyArrowLeft:
+		switch {
+		case control:
+			e.MoveTo(e.wordLeft(e.Cursor), shift)
+		case e.HasSelection() && !shift:
+			lo, _ := e.Selection()
+			e.MoveTo(lo, false)
+		default:
+			e.MoveTo(e.Cursor-1, shift)
+		}
+	case KeyArrowRight:
+		switch {
+		case control:
+			e.MoveTo(e.wordRight(e.Cursor), shift)
+		case e.HasSelection() && !shift:
+			_, hi := e.Selection()
+			e.MoveTo(hi, false)
+		default:
+			e.MoveTo(e.Cursor+1, shift)
+		}
+	case KeyArrowUp, KeyArrowDown, KeyPageUp, KeyPageDown:
+		if !e.Multiline {
+			return false, false
+		}
+		step := 1
+		if key == KeyPageUp || key == KeyPageDown {
+			step = max(e.Page, 1)
+		}
+		if key == KeyArrowUp || key == KeyPageUp {
+			step = -step
+		}
+		e.MoveTo(e.Index(max(line+step, 0), col), shift)
+	case KeyHome:
+		if control {
+			e.MoveTo(0, shift)
+		} else {
+			e.MoveTo(e.Index(line, 0), shift)
+		}
+	case KeyEnd:
+		if control {
+			e.MoveTo(len(e.Text), shift)
+		} else {
+			e.MoveTo(e.Index(line, len(e.Text)), shift)
+		}
+	case KeyBackspace:
+		changed = e.Delete(true, control)
+	case KeyDelete:
+		changed = e.Delete(false, control)
+	case KeyEnter:
+		if !e.Multiline {
+			return false, false
+		}
+		e.typing = false
+		changed = e.Insert('\n')
+	case KeyA, KeyC, KeyX, KeyV, KeyZ, KeyY:
+		if !control {
+			return false, false
+		}
+		switch key {
+		case KeyA:
+			e.SelectAll()
+		case KeyC:
+			e.Copy()
+		case KeyX:
+			changed = e.Cut()
+		case KeyV:
+			changed = e.Paste()
+		case KeyZ:
+			if shift {
+				changed = e.Redo()
+			} else {
+				changed = e.Undo()
+			}
+		case KeyY:
+			changed = e.Redo()
+		}
+	default:
+		return false, false
+	}
+	return true, changed
+}
+
+// Column returns the column in line of the text that is nearest to x,
+// given a function that measures the width of text.
+func Column(line string, x int, measure func(text string) int) int {
+	runes := []rune(line)
+	prev := 0
+	for i := range runes {
+		w := measure(string(runes[:i+1]))
+		if x < (prev+w)/2 {
+			return i
+		}
+		prev = w
+	}
+	return len(runes)
+}
@@ -0,0 +1,130 @@
+package xgal
+
+import "testing"
+
+func TestEditTyping(t *testing.T) {
+	e := NewEdit("")
+	for _, r := range "hello world" {
+		e.Insert(r)
+	}
+	if e.String() != "hello world" || e.Cursor != 11 {
+		t.Fatalf("typed %q, cursor %d", e, e.Cursor)
+	}
+	// Undo removes a typed word at a time.
+	if !e.Undo() || e.String() != "hello " {
+		t.Fatalf("undo: %q", e)
+	}
+	if !e.Undo() || e.String() != "" || e.Undo() {
+		t.Fatalf("undo twice: %q", e)
+	}
+	if !e.Redo() || e.String() != "hello " {
+		t.Fatalf("redo: %q", e)
+	}
+}
+
+func TestEditSelection(t *testing.T) {
+	e := NewEdit("one two three")
+	e.Key(KeyArrowLeft, true, true)
+	if e.Selected() != "three" {
+		t.Fatalf("selected %q", e.Selected())
+	}
+	e.Insert('3')
+	if e.String() != "one two 3" {
+		t.Fatalf("replaced: %q", e)
+	}
+	e.Key(KeyHome, false, false)
+	e.Key(KeyArrowRight, false, true)
+	if e.Cursor != 3 {
+		t.Errorf("word right: %d", e.Cursor)
+	}
+	e.Key(KeyDelete, false, true)
+	if e.String() != "one 3" {
+		t.Errorf("delete word: %q", e)
+	}
+	e.Key(KeyA, false, true)
+	if lo, hi := e.Selection(); lo != 0 || hi != len(e.Text) {
+		t.Errorf("select all: %d-%d", lo, hi)
+	}
+	// Left without shift collapses the selection to its start.
+	e.Key(KeyArrowLeft, false, false)
+	if e.HasSelection() || e.Cursor != 0 {
+		t.Errorf("collapse: %d-%d", e.Anchor, e.Cursor)
+	}
+	if handled, _ := e.Key(KeyC, false, false); handled {
+		t.Errorf("c without control handled")
+	}
+}
+
+func TestEditMask(t *testing.T) {
+	e := NewEdit("")
+	e.Mask = HexMask
+	e.Max = 4
+	e.Insert([]rune("0xBEEF")...)
+	if e.String() != "0BEE" {
+		t.Errorf("hex: %q", e)
+	}
+	if e.Valid() != nil {
+		t.Errorf("hex invalid: %v", e.Valid())
+	}
+
+	e = NewEdit("")
+	e.Mask = IntegerMask
+	e.Insert([]rune("-")...)
+	if e.Valid() == nil {
+		t.Errorf("- is a valid integer")
+	}
+	e.Insert([]rune("12a")...)
+	if e.String() != "-12" || e.Valid() != nil {
+		t.Errorf("integer: %q, %v", e, e.Valid())
+	}
+
+	e = NewEdit("")
+	e.Mask = FilenameMask
+	e.Insert([]rune("a/b:c.txt")...)
+	if e.String() != "abc.txt" || e.Valid() != nil {
+		t.Errorf("filename: %q, %v", e, e.Valid())
+	}
+}
+
+func TestEditLines(t *testing.T) {
+	e := NewEdit("first\nsecond line\nthird")
+	e.Multiline = true
+	e.Page = 2
+	if line, col := e.LineCol(e.Cursor); line != 2 || col != 5 {
+		t.Fatalf("end at %d:%d", line, col)
+	}
+	e.Key(KeyArrowUp, false, false)
+	if line, col := e.LineCol(e.Cursor); line != 1 || col != 5 {
+		t.Errorf("up to %d:%d", line, col)
+	}
+	e.Key(KeyPageUp, true, false)
+	if line, col := e.LineCol(e.Cursor); line != 0 || col != 5 || e.Selected() != "\nsecon" {
+		t.Errorf("page up to %d:%d selecting %q", line, col, e.Selected())
+	}
+	e.Key(KeyEnd, false, false)
+	e.Key(KeyEnter, false, false)
+	if got := e.Lines(); len(got) != 4 || got[1] != "" {
+		t.Errorf("enter: %q", got)
+	}
+	if e.Index(9, 9) != len(e.Text) {
+		t.Errorf("index past the end: %d", e.Index(9, 9))
+	}
+
+	single := NewEdit("x")
+	if handled, _ := single.Key(KeyEnter, false, false); handled {
+		t.Errorf("single line edit handled enter")
+	}
+	single.Insert([]rune("a\nb")...)
+	if single.String() != "xab" {
+		t.Errorf("single line insert: %q", single)
+	}
+}
+
+func TestColumn(t *testing.T) {
+	measure := func(text string) int { return len(text) * 6 }
+	for x, want := range map[int]int{0: 0, 2: 0, 4: 1, 11: 2, 40: 3} {
+		if got := Column("abc", x, measure); got != want {
+			t.Errorf("x %d: column %d, want %d", x, got, want)
+		}
+	}
+}
@@ -14,6 +14,33 @@ type Button = ebiten.GamepadButton
 // AxisID identifies a gamepad axis.
 type AxisID = ebiten.GamepadAxisType
 
+// StandardButton is a button of the standard gamepad layout, which is
+// the same on every pad that has it, see [Standard].
+type StandardButton = ebiten.StandardGamepadButton
+
+const (
+	PadA     StandardButton = ebiten.StandardGamepadButtonRightBottom // PadA is the bottom face button.
+	PadB     StandardButton = ebiten.StandardGamepadButtonRightRight  // PadB is the right face button.
+	PadX     StandardButton = ebiten.StandardGamepadButtonRightLeft   // PadX is the left face button.
+	PadY     StandardButton = ebiten.StandardGamepadButtonRightTop    // PadY is the top face button.
+	PadUp    StandardButton = ebiten.StandardGamepadButtonLeftTop
+	PadDown  StandardButton = ebiten.StandardGamepadButtonLeftBottom
+	PadLeft  StandardButton = ebiten.StandardGamepadButtonLeftLeft
+	PadRight StandardButton = ebiten.StandardGamepadButtonLeftRight
+	PadBack  StandardButton = ebiten.StandardGamepadButtonCenterLeft
+	PadStart StandardButton = ebiten.StandardGamepadButtonCenterRight
+)
+
+// StandardAxis is an axis of the standard gamepad layout.
+type StandardAxis = ebiten.StandardGamepadAxis
+
+const (
+	PadLeftX  StandardAxis = ebiten.StandardGamepadAxisLeftStickHorizontal
+	PadLeftY  StandardAxis = ebiten.StandardGamepadAxisLeftStickVertical
+	PadRightX StandardAxis = ebiten.StandardGamepadAxisRightStickHorizontal
+	PadRightY StandardAxis = ebiten.StandardGamepadAxisRightStickVertical
+)
+
 // Plugs returns all gamepads that were just connected this frame.
 // If buf is provided, results are appended to it.
 func Plugs(buf ...[]PadID) []PadID {
@@ -44,6 +71,24 @@ func Nudge(pad PadID, btn Button) bool {
 	return inpututil.IsGamepadButtonJustPressed(pad, btn)
 }
 
+// Standard reports whether the gamepad has the standard layout, so its
+// buttons and axes can be read as a [StandardButton] and a [StandardAxis].
+func Standard(pad PadID) bool {
+	return ebiten.IsStandardGamepadLayoutAvailable(pad)
+}
+
+// StandardNudge reports whether the button of the standard layout was
+// just pressed.
+func StandardNudge(pad PadID, btn StandardButton) bool {
+	return inpututil.IsStandardGamepadButtonJustPressed(pad, btn)
+}
+
+// StandardTilt returns the value of the axis of the standard layout, from
+// -1 to 1.
+func StandardTilt(pad PadID, axis StandardAxis) float64 {
+	return ebiten.StandardGamepadAxisValue(pad, axis)
+}
+
 // Squeeze reports whether the gamepad button is currently held.
 func Squeeze(pad PadID, btn Button) bool {
 	for _, b := range inpututil.AppendPressedGamepadButtons(pad, nil) {
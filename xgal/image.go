@@ -17,10 +17,15 @@ import (
 
 import (
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/xmasengine/xmas/wfs"
 )
 
-// Texture loads an image file from fsys as a [Surface].
+// Texture loads an image file from fsys as a [Surface]. Images in the
+// added atlases are not loaded but returned as their [Sprite].
 func Texture(fsys fs.FS, name string) (*Surface, error) {
+	if sub, ok := Sprite(name); ok {
+		return sub, nil
+	}
 	f, err := fsys.Open(name)
 	if err != nil {
 		return nil, err
@@ -146,6 +151,21 @@ func Scribble(name string, pimg PalettedImage) error {
 	return encoder.Encode(f, pimg)
 }
 
+// ScribbleFS writes a PalettedImage to a file of a file system that is a
+// [wfs.CreateFS].
+func ScribbleFS(fsys fs.FS, name string, pimg PalettedImage) error {
+	encoder := encoderFor(name)
+	if encoder == nil {
+		return errors.New("file format not supported: " + name)
+	}
+	f, err := wfs.Create(fsys, name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return encoder.Encode(f, pimg)
+}
+
 // Express (like paint on a palette) creates an empty paletted image.
 func Express(rect Rectangle, pal color.Palette) *Paletted {
 	return image.NewPaletted(rect, pal)
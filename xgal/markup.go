@@ -0,0 +1,420 @@
+package xgal
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Effect animates the glyphs of a [Text].
+type Effect int
+
+const (
+	EffectShake Effect = 1 << iota // EffectShake jitters the glyphs.
+	EffectWave                     // EffectWave moves the glyphs up and down.
+)
+
+// IconRune is the rune of glyphs that are inline icons.
+const IconRune = '\uFFFC'
+
+// pageRune marks a page break while laying out.
+const pageRune = '\f'
+
+// Glyph is a laid out rune or inline icon of a [Text].
+type Glyph struct {
+	Rune   rune   // Rune of the glyph, IconRune for icons.
+	Icon   string // Icon is the name of the icon for icons.
+	X, Y   int    // X, Y is the position of the glyph in its page.
+	Width  int    // Width of the glyph.
+	Height int    // Height of the glyph.
+	Line   int    // Line of the text the glyph is on.
+	Page   int    // Page the glyph is on.
+	Color  RGBA   // Color of the glyph, transparent for the default.
+	Effect Effect // Effect that animates the glyph.
+	Delay  int    // Delay in ticks before the glyph is revealed.
+}
+
+// Offset returns the offset of the glyph with index i of its text at the
+// given tick, for the effects of the glyph.
+func (g Glyph) Offset(i, tick int) Point {
+	var at Point
+	if g.Effect&EffectShake != 0 {
+		h := uint32(i)*2654435761 ^ uint32(tick/3)*40503
+		h ^= h >> 13
+		at.X += int(h%3) - 1
+		at.Y += int(h/3%3) - 1
+	}
+	if g.Effect&EffectWave != 0 {
+		at.Y += int(math.Round(2 * math.Sin(float64(tick)*0.15+float64(i)*0.6)))
+	}
+	return at
+}
+
+// IconSizer measures the inline icons of a text, such as an
+// xres.IconAtlas does.
+type IconSizer interface {
+	IconSize(name string) (Point, bool)
+}
+
+// IconDrawer draws the inline icons of a text, such as an xres.IconAtlas
+// does.
+type IconDrawer interface {
+	DrawIcon(dst *Surface, name string, r Rectangle)
+}
+
+// Typeset lays out text with inline markup. The markup consists of tags
+// between braces:
+//
+//	{color=#rrggbb} ... {/color}  colors the text, also #rrggbbaa.
+//	{shake} ... {/shake}          shakes the text.
+//	{wave} ... {/wave}            waves the text.
+//	{speed=ticks} ... {/speed}    reveals a glyph every so many ticks.
+//	{wait=ticks}                  waits before revealing the next glyph.
+//	{icon=name}                   places an icon inline.
+//	{page}                        starts a new page.
+//	{$name}                       is replaced by the variable name.
+//
+// A brace is written as {{ or }}.
+type Typeset struct {
+	Width   int               // Width to wrap the text at, 0 to not wrap.
+	Lines   int               // Lines per page, 0 for a single page.
+	Stride  int               // Stride is the height of a line.
+	Tick    int               // Tick is the amount of ticks to reveal a glyph.
+	Vars    map[string]string // Vars are the variables to substitute.
+	Icons   IconSizer         // Icons measures inline icons, may be nil.
+	Measure func(string) int  // Measure returns the width of a string.
+}
+
+// NewTypeset returns a typeset that measures with face and wraps at width
+// into pages of lines.
+func NewTypeset(face Face, width, lines int) *Typeset {
+	if face == nil {
+		face = BuiltinFace
+	}
+	stride := Stride(face)
+	return &Typeset{
+		Width:  width,
+		Lines:  lines,
+		Stride: stride,
+		Tick:   1,
+		Measure: func(s string) int {
+			w, _ := Measure(s, face, float64(stride))
+			return int(math.Ceil(w))
+		},
+	}
+}
+
+// Escape escapes the braces in s so it lays out as plain text.
+func Escape(s string) string {
+	return strings.NewReplacer("{", "{{", "}", "}}").Replace(s)
+}
+
+// Text is text that was laid out by a [Typeset].
+type Text struct {
+	Glyphs []Glyph // Glyphs of the text in reading order.
+	Lines  int     // Lines is the amount of lines.
+	Pages  int     // Pages is the amount of pages.
+	Stride int     // Stride is the height of a line.
+}
+
+// parseHex parses a #rrggbb or #rrggbbaa color.
+func parseHex(s string) (RGBA, error) {
+	var r, g, b uint8
+	a := uint8(255)
+	var err error
+	switch len(s) {
+	case 7:
+		_, err = fmt.Sscanf(s, "#%02x%02x%02x", &r, &g, &b)
+	case 9:
+		_, err = fmt.Sscanf(s, "#%02x%02x%02x%02x", &r, &g, &b, &a)
+	default:
+		err = fmt.Errorf("bad color %q", s)
+	}
+	return Paint(r, g, b, a), err
+}
+
+// parse turns markup into unplaced glyphs, including newlines and page
+// breaks.
+func (t *Typeset) parse(markup string) ([]Glyph, error) {
+	var glyphs []Glyph
+	colors := []RGBA{Transparent}
+	speeds := []int{max(t.Tick, 0)}
+	effect := Effect(0)
+	wait := 0
+	add := func(g Glyph) {
+		g.Color = colors[len(colors)-1]
+		g.Effect = effect
+		g.Delay = speeds[len(speeds)-1] + wait
+		g.Height = t.Stride
+		wait = 0
+		if g.Rune != IconRune && g.Rune != '\n' && g.Rune != pageRune {
+			g.Width = t.Measure(string(g.Rune))
+		}
+		glyphs = append(glyphs, g)
+	}
+	for i := 0; i < len(markup); {
+		if strings.HasPrefix(markup[i:], "{{") || strings.HasPrefix(markup[i:], "}}") {
+			add(Glyph{Rune: rune(markup[i])})
+			i += 2
+			continue
+		}
+		if markup[i] != '{' {
+			r, size := utf8.DecodeRuneInString(markup[i:])
+			add(Glyph{Rune: r})
+			i += size
+			continue
+		}
+		end := strings.IndexByte(markup[i:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("unclosed tag at %d", i)
+		}
+		tag := markup[i+1 : i+end]
+		i += end + 1
+		name, value, _ := strings.Cut(tag, "=")
+		switch name {
+		case "color":
+			col, err := parseHex(value)
+			if err != nil {
+				return nil, err
+			}
+			colors = append(colors, col)
+		case "shake":
+			effect |= EffectShake
+		case "wave":
+			effect |= EffectWave
+		case "speed", "wait":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("bad %s %q", name, value)
+			}
+			if name == "speed" {
+				speeds = append(speeds, n)
+			} else {
+				wait += n
+			}
+		case "/color":
+			colors = colors[:max(len(colors)-1, 1)]
+		case "/shake":
+			effect &^= EffectShake
+		case "/wave":
+			effect &^= EffectWave
+		case "/speed":
+			speeds = speeds[:max(len(speeds)-1, 1)]
+		case "icon":
+			if t.Icons == nil {
+				return nil, fmt.Errorf("no icons for %q", value)
+			}
+			size, ok := t.Icons.IconSize(value)
+			if !ok {
+				return nil, fmt.Errorf("unknown icon %q", value)
+			}
+			add(Glyph{Rune: IconRune, Icon: value})
+			glyphs[len(glyphs)-1].Width = size.X
+			glyphs[len(glyphs)-1].Height = size.Y
+		case "page":
+			add(Glyph{Rune: pageRune})
+		default:
+			variable, ok := strings.CutPrefix(tag, "$")
+			if !ok {
+				return nil, fmt.Errorf("unknown tag %q", tag)
+			}
+			text, ok := t.Vars[variable]
+			if !ok {
+				return nil, fmt.Errorf("unknown variable %q", variable)
+			}
+			for _, r := range text {
+				add(Glyph{Rune: r})
+			}
+		}
+	}
+	return glyphs, nil
+}
+
+// Layout parses the markup and lays it out. Words are wrapped to the
+// width of the typeset, and glyphs that do not fit on a line of their own
+// are wrapped as well. The spaces where a line is wrapped are dropped.
+func (t *Typeset) Layout(markup string) (*Text, error) {
+	parsed, err := t.parse(markup)
+	if err != nil {
+		return nil, err
+	}
+	text := &Text{Stride: t.Stride}
+	x, line, carry := 0, 0, 0
+	place := func(g Glyph) {
+		g.X, g.Line = x, line
+		g.Delay += carry
+		carry = 0
+		x += g.Width
+		text.Glyphs = append(text.Glyphs, g)
+	}
+	fits := func(w int) bool {
+		return t.Width <= 0 || x == 0 || x+w <= t.Width
+	}
+	var spaces []Glyph
+	for i := 0; i < len(parsed); {
+		g := parsed[i]
+		switch g.Rune {
+		case ' ':
+			spaces = append(spaces, g)
+			i++
+			continue
+		case '\n', pageRune:
+			for _, s := range spaces {
+				place(s)
+			}
+			spaces = spaces[:0]
+			carry += g.Delay
+			if g.Rune == '\n' {
+				line++
+			} else if t.Lines > 0 && (x > 0 || line%t.Lines != 0) {
+				line = (line/t.Lines + 1) * t.Lines
+			}
+			x = 0
+			i++
+			continue
+		}
+		end := i
+		width := 0
+		for end < len(parsed) && parsed[end].Rune != ' ' &&
+			parsed[end].Rune != '\n' && parsed[end].Rune != pageRune {
+			width += parsed[end].Width
+			end++
+		}
+		gap := 0
+		for _, s := range spaces {
+			gap += s.Width
+		}
+		if fits(gap + width) {
+			for _, s := range spaces {
+				place(s)
+			}
+		} else {
+			for _, s := range spaces {
+				carry += s.Delay
+			}
+			line++
+			x = 0
+		}
+		spaces = spaces[:0]
+		for _, g := range parsed[i:end] {
+			if !fits(g.Width) {
+				line++
+				x = 0
+			}
+			place(g)
+		}
+		i = end
+	}
+	for _, s := range spaces {
+		place(s)
+	}
+	text.Lines = line + 1
+	text.Pages = 1
+	if t.Lines > 0 {
+		text.Pages = line/t.Lines + 1
+	}
+	for i := range text.Glyphs {
+		g := &text.Glyphs[i]
+		if t.Lines > 0 {
+			g.Page = g.Line / t.Lines
+		}
+		g.Y = (g.Line - g.Page*max(t.Lines, 0)) * t.Stride
+	}
+	return text, nil
+}
+
+// Page returns the range of the indexes of the glyphs of a page.
+func (t *Text) Page(page int) (from, to int) {
+	for from < len(t.Glyphs) && t.Glyphs[from].Page < page {
+		from++
+	}
+	for to = from; to < len(t.Glyphs) && t.Glyphs[to].Page == page; to++ {
+	}
+	return from, to
+}
+
+// Runes returns the runes of the text per line, with icons as IconRune.
+func (t *Text) Runes() [][]rune {
+	lines := make([][]rune, t.Lines)
+	for _, g := range t.Glyphs {
+		lines[g.Line] = append(lines[g.Line], g.Rune)
+	}
+	return lines
+}
+
+// String returns the text without markup, with the lines as laid out.
+func (t *Text) String() string {
+	lines := t.Runes()
+	out := make([]string, len(lines))
+	for i, line := range lines {
+		out[i] = string(line)
+	}
+	return strings.Join(out, "\n")
+}
+
+// Size returns the width of the widest line and the height of all lines.
+func (t *Text) Size() Point {
+	width := 0
+	for _, g := range t.Glyphs {
+		width = max(width, g.X+g.Width)
+	}
+	return Pt(width, t.Lines*t.Stride)
+}
+
+// Reveal returns the amount of glyphs that a typewriter has revealed
+// after the given amount of ticks.
+func (t *Text) Reveal(ticks int) int {
+	at := 0
+	for i, g := range t.Glyphs {
+		at += g.Delay
+		if at > ticks {
+			return i
+		}
+	}
+	return len(t.Glyphs)
+}
+
+// Duration returns the amount of ticks to reveal the whole text.
+func (t *Text) Duration() int {
+	total := 0
+	for _, g := range t.Glyphs {
+		total += g.Delay
+	}
+	return total
+}
+
+// Cursor returns the position in its page just after the first n glyphs.
+func (t *Text) Cursor(n int) Point {
+	if n <= 0 || len(t.Glyphs) == 0 {
+		return Point{}
+	}
+	g := t.Glyphs[min(n, len(t.Glyphs))-1]
+	return Pt(g.X+g.Width, g.Y)
+}
+
+// Draw draws the glyphs of a page out of the first n glyphs at at, with
+// the effects animated at tick. Glyphs without color are drawn with col.
+// The icons may be nil if the text has no inline icons.
+func (t *Text) Draw(dst *Surface, face Face, col RGBA, at Point, page, n, tick int, icons IconDrawer) {
+	from, to := t.Page(page)
+	for i := from; i < min(to, n); i++ {
+		g := t.Glyphs[i]
+		pos := at.Add(Pt(g.X, g.Y)).Add(g.Offset(i, tick))
+		if g.Rune == IconRune {
+			if icons != nil {
+				icons.DrawIcon(dst, g.Icon, Bound(pos.X, pos.Y, g.Width, g.Height))
+			}
+			continue
+		}
+		if g.Rune == ' ' {
+			continue
+		}
+		ink := col
+		if g.Color.A != 0 {
+			ink = g.Color
+		}
+		Ink(dst, face, ink, pos.X, pos.Y, string(g.Rune))
+	}
+}
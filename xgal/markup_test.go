@@ -0,0 +1,171 @@
+package xgal
+
+import (
+	"strings"
+	"testing"
+)
+
+// mono returns a typeset where every rune is 8 pixels wide.
+func mono(width, lines int) *Typeset {
+	return &Typeset{
+		Width:   width,
+		Lines:   lines,
+		Stride:  10,
+		Tick:    2,
+		Measure: func(s string) int { return 8 * len([]rune(s)) },
+	}
+}
+
+type testIcons map[string]Point
+
+func (ti testIcons) IconSize(name string) (Point, bool) {
+	size, ok := ti[name]
+	return size, ok
+}
+
+func TestLayoutWrap(t *testing.T) {
+	cases := []struct {
+		markup string
+		width  int
+		want   string
+	}{
+		{"Hello world", 0, "Hello world"},
+		{"Hello world", 80, "Hello\nworld"},
+		{"Hello world", 88, "Hello world"},
+		{"a b c d e", 24, "a b\nc d\ne"},
+		{"Antidisestablishment", 64, "Antidise\nstablish\nment"},
+		{"one\n\ntwo", 80, "one\n\ntwo"},
+		{"Hi {$name}!", 0, "Hi Santa!"},
+		{"{{braces}}", 0, "{braces}"},
+		{"Héllo wörld", 48, "Héllo\nwörld"},
+	}
+	for _, c := range cases {
+		ts := mono(c.width, 0)
+		ts.Vars = map[string]string{"name": "Santa"}
+		text, err := ts.Layout(c.markup)
+		if err != nil {
+			t.Errorf("%q: %v", c.markup, err)
+			continue
+		}
+		if got := text.String(); got != c.want {
+			t.Errorf("%q at %d: %q, want %q", c.markup, c.width, got, c.want)
+		}
+	}
+}
+
+func TestLayoutGlyphs(t *testing.T) {
+	ts := mono(48, 0)
+	ts.Icons = testIcons{"star": Pt(12, 12)}
+	text, err := ts.Layout("a {color=#ff0000}b{icon=star}{/color} {shake}{wave}c{/wave}d{/shake}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := text.Glyphs
+	if len(g) != 6 {
+		t.Fatalf("glyphs: %+v", g)
+	}
+	if g[2].Color != Paint(255, 0, 0, 255) || g[3].Color != Paint(255, 0, 0, 255) || g[4].Color != Transparent {
+		t.Errorf("colors: %v %v %v", g[2].Color, g[3].Color, g[4].Color)
+	}
+	if g[3].Rune != IconRune || g[3].Icon != "star" || g[3].X != 24 || g[3].Width != 12 || g[3].Height != 12 {
+		t.Errorf("icon: %+v", g[3])
+	}
+	if g[4].Effect != EffectShake|EffectWave || g[5].Effect != EffectShake {
+		t.Errorf("effects: %v %v", g[4].Effect, g[5].Effect)
+	}
+	// "a bX" is 36 wide, so " cd" wraps and the space is dropped.
+	if g[3].Line != 0 || g[4].Line != 1 || g[4].X != 0 || g[4].Y != 10 {
+		t.Errorf("wrap: %+v %+v", g[3], g[4])
+	}
+	if g[4].Delay != 4 {
+		t.Errorf("the dropped space should delay: %d", g[4].Delay)
+	}
+}
+
+func TestLayoutPages(t *testing.T) {
+	text, err := mono(40, 2).Layout("one two three four{page}five")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text.Lines != 5 || text.Pages != 3 {
+		t.Fatalf("%d lines, %d pages: %q", text.Lines, text.Pages, text.String())
+	}
+	want := [][2]int{{0, 6}, {6, 15}, {15, 19}}
+	for page, w := range want {
+		if from, to := text.Page(page); from != w[0] || to != w[1] {
+			t.Errorf("page %d: %d to %d, want %v", page, from, to, w)
+		}
+	}
+	last := text.Glyphs[len(text.Glyphs)-1]
+	if last.Page != 2 || last.Line != 4 || last.Y != 0 {
+		t.Errorf("five: %+v", last)
+	}
+	if from, to := text.Page(3); from != to {
+		t.Errorf("no page 3: %d %d", from, to)
+	}
+}
+
+func TestLayoutReveal(t *testing.T) {
+	text, err := mono(0, 0).Layout("ab{wait=10}c{speed=5}de{/speed}f")
+	if err != nil {
+		t.Fatal(err)
+	}
+	delays := []int{2, 2, 12, 5, 5, 2}
+	for i, d := range delays {
+		if text.Glyphs[i].Delay != d {
+			t.Errorf("delay %d: %d != %d", i, text.Glyphs[i].Delay, d)
+		}
+	}
+	if text.Duration() != 28 {
+		t.Errorf("duration: %d", text.Duration())
+	}
+	reveal := []struct{ ticks, n int }{{0, 0}, {1, 0}, {2, 1}, {4, 2}, {15, 2}, {16, 3}, {21, 4}, {28, 6}, {99, 6}}
+	for _, r := range reveal {
+		if n := text.Reveal(r.ticks); n != r.n {
+			t.Errorf("reveal at %d: %d != %d", r.ticks, n, r.n)
+		}
+	}
+	if c := text.Cursor(3); c != Pt(24, 0) {
+		t.Errorf("cursor: %v", c)
+	}
+}
+
+func TestLayoutErrors(t *testing.T) {
+	for _, bad := range []string{
+		"{color=red}", "{wait=soon}", "{speed=-1}", "{blink}", "{$who}", "{icon=star}", "open {",
+	} {
+		if _, err := mono(0, 0).Layout(bad); err == nil {
+			t.Errorf("%q: expected error", bad)
+		}
+	}
+	escaped := Escape("{blink} }")
+	text, err := mono(0, 0).Layout(escaped)
+	if err != nil || text.String() != "{blink} }" {
+		t.Errorf("escape: %q %v", escaped, err)
+	}
+}
+
+func TestGlyphOffset(t *testing.T) {
+	plain := Glyph{}
+	if plain.Offset(3, 17) != (Point{}) {
+		t.Errorf("plain glyphs do not move")
+	}
+	shake := Glyph{Effect: EffectShake}
+	moved := false
+	for tick := 0; tick < 60; tick++ {
+		at := shake.Offset(1, tick)
+		if at.X < -1 || at.X > 1 || at.Y < -1 || at.Y > 1 {
+			t.Fatalf("shake too far: %v", at)
+		}
+		moved = moved || at != (Point{})
+		if at != shake.Offset(1, tick) {
+			t.Fatalf("shake is not deterministic")
+		}
+	}
+	if !moved {
+		t.Errorf("shake never moved")
+	}
+	if strings.Contains(Escape("a"), "{") {
+		t.Errorf("escape changed plain text")
+	}
+}
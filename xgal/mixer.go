@@ -0,0 +1,514 @@
+package xgal
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"io/fs"
+	"math"
+	"sync"
+
+	"github.com/hajimehoshi/ebiten/v2/audio"
+)
+
+// Sound is decoded audio that the [Mixer] can play any number of times
+// at once. The samples are stereo, interleaved left and right, at
+// [SampleRate].
+type Sound struct {
+	Name    string    // Name of the sound.
+	Samples []float32 // Samples are left and right samples, interleaved.
+	Voices  int       // Voices is the maximum amount of simultaneous voices, DefaultVoices if 0.
+}
+
+// DefaultVoices is the amount of voices a sound may play simultaneously
+// if it has no Voices.
+const DefaultVoices = 4
+
+// Frames returns the length of the sound in stereo frames.
+func (s *Sound) Frames() int {
+	return len(s.Samples) / 2
+}
+
+// Decode loads and decodes an audio file from fsys as a [Sound].
+// Supported formats are those of [Sample].
+func Decode(fsys fs.FS, name string) (*Sound, error) {
+	buf, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	stream, err := decodeAudio(name, bytes.NewReader(buf))
+	if err != nil {
+		return nil, err
+	}
+	pcm, err := io.ReadAll(stream)
+	if err != nil {
+		return nil, err
+	}
+	return &Sound{Name: name, Samples: floats(pcm)}, nil
+}
+
+// floats converts little endian 32 bits float bytes to samples.
+func floats(pcm []byte) []float32 {
+	samples := make([]float32, len(pcm)/4)
+	for i := range samples {
+		samples[i] = math.Float32frombits(binary.LittleEndian.Uint32(pcm[i*4:]))
+	}
+	return samples
+}
+
+// raw converts samples to little endian 32 bits float bytes.
+func raw(samples []float32) []byte {
+	out := make([]byte, 4*len(samples))
+	for i, v := range samples {
+		binary.LittleEndian.PutUint32(out[i*4:], math.Float32bits(v))
+	}
+	return out
+}
+
+// Bus is a named group of voices with a shared gain, such as music or
+// sound effects. All buses are also mixed through the master bus.
+type Bus struct {
+	Name string  // Name of the bus.
+	Gain float32 // Gain of the bus, 1 is unchanged.
+	Mute bool    // Mute silences the bus.
+	Duck float32 // Duck is the current extra gain while ducking, 1 if not.
+
+	duckTo   float32 // duckTo is the Duck gain to ramp to.
+	duckStep float32 // duckStep is the change of Duck per frame.
+}
+
+// level returns the effective gain of the bus.
+func (b *Bus) level() float32 {
+	if b.Mute {
+		return 0
+	}
+	return b.Gain * b.Duck
+}
+
+// ramp moves Duck towards its target for the given amount of frames.
+func (b *Bus) ramp(frames int) {
+	if b.Duck == b.duckTo {
+		return
+	}
+	step := b.duckStep * float32(frames)
+	if b.Duck < b.duckTo {
+		b.Duck = min(b.Duck+step, b.duckTo)
+	} else {
+		b.Duck = max(b.Duck-step, b.duckTo)
+	}
+}
+
+// Names of the buses every mixer has.
+const (
+	BusMaster = "master"
+	BusMusic  = "music"
+	BusEffect = "effect"
+	BusVoice  = "voice"
+)
+
+// voice is a single playing instance of a [Sound]. It is only used with
+// the lock of its mixer held.
+type voice struct {
+	Sound      *Sound  // Sound that is played.
+	Bus        *Bus    // Bus the voice plays on.
+	Gain       float32 // Gain of the voice, 1 is unchanged.
+	Pan        float32 // Pan from -1 for left to 1 for right.
+	Loop       bool    // Loop restarts the sound at the end.
+	Positional bool    // Positional voices are placed relative to the listener.
+	At         Point   // At is the position of a positional voice.
+	Pos        int     // Pos is the current frame.
+	Fade       float32 // Fade is the gain of a fade in or out.
+	FadeStep   float32 // FadeStep is the change of Fade per frame.
+	Done       bool    // Done is set when the voice stopped.
+	serial     uint64
+}
+
+// Voice is a handle to a sound that a [Mixer] plays. Its methods may be
+// called from any goroutine. Once the sound ended, or its voice was stolen
+// by another sound, the handle no longer controls anything, so the zero
+// Voice can be used for no sound.
+type Voice struct {
+	mixer  *Mixer
+	v      *voice
+	serial uint64
+}
+
+// with calls set with the voice of the handle while holding the lock of
+// the mixer, if the handle still controls it.
+func (h Voice) with(set func(v *voice)) {
+	if h.mixer == nil {
+		return
+	}
+	h.mixer.mu.Lock()
+	defer h.mixer.mu.Unlock()
+	if h.v.serial == h.serial && !h.v.Done {
+		set(h.v)
+	}
+}
+
+// Playing returns whether the sound of the handle still plays.
+func (h Voice) Playing() bool {
+	playing := false
+	h.with(func(v *voice) { playing = true })
+	return playing
+}
+
+// SetGain sets the gain of the voice, 1 is unchanged.
+func (h Voice) SetGain(gain float32) {
+	h.with(func(v *voice) { v.Gain = gain })
+}
+
+// SetPan sets the pan of the voice, from -1 for left to 1 for right.
+func (h Voice) SetPan(pan float32) {
+	h.with(func(v *voice) { v.Pan = pan })
+}
+
+// SetLoop sets whether the voice restarts the sound at the end.
+func (h Voice) SetLoop(loop bool) {
+	h.with(func(v *voice) { v.Loop = loop })
+}
+
+// SetAt places the voice at the position at relative to the listener.
+func (h Voice) SetAt(at Point) {
+	h.with(func(v *voice) { v.Positional, v.At = true, at })
+}
+
+// Stop fades out the voice over the given seconds and then stops it.
+func (h Voice) Stop(seconds float64) {
+	h.with(func(v *voice) { v.fadeOut(seconds) })
+}
+
+// Mixer mixes any number of voices on named buses into one stereo
+// stream. Use [Mixer.Start] to play it on the audio device. The mixing
+// itself is done by [Mixer.Mix] on float32 samples.
+type Mixer struct {
+	Buses     map[string]*Bus // Buses by name.
+	MaxVoices int             // MaxVoices is the maximum of voices in total.
+
+	mu       sync.Mutex
+	listener Point   // listener is the position positional voices are heard from.
+	reach    float64 // reach is the distance beyond which positional voices are silent.
+	serial   uint64
+	voices   []*voice // voices that are playing.
+	song     *voice   // song is the current music voice, if any.
+	talking  bool     // talking is set while the music is ducked for dialogue.
+	mix      []float32
+	player   *audio.Player
+}
+
+// DefaultMaxVoices is the default maximum amount of voices of a mixer.
+const DefaultMaxVoices = 32
+
+// DefaultReach is the default reach of positional voices in pixels.
+const DefaultReach = 320
+
+// NewMixer returns a mixer with the master, music, effect and voice buses.
+func NewMixer() *Mixer {
+	m := &Mixer{Buses: map[string]*Bus{}, MaxVoices: DefaultMaxVoices, reach: DefaultReach}
+	for _, name := range []string{BusMaster, BusMusic, BusEffect, BusVoice} {
+		m.bus(name)
+	}
+	return m
+}
+
+// bus returns the named bus, adding it if needed. Must hold the lock.
+func (m *Mixer) bus(name string) *Bus {
+	b, ok := m.Buses[name]
+	if !ok {
+		b = &Bus{Name: name, Gain: 1, Duck: 1, duckTo: 1}
+		m.Buses[name] = b
+	}
+	return b
+}
+
+// SetListener sets the position that positional voices are heard from.
+func (m *Mixer) SetListener(at Point) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.listener = at
+}
+
+// SetReach sets the distance beyond which positional voices are silent.
+func (m *Mixer) SetReach(reach float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.reach = reach
+}
+
+// SetGain sets the gain of the named bus.
+func (m *Mixer) SetGain(bus string, gain float32) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.bus(bus).Gain = gain
+}
+
+// SetMute mutes or unmutes the named bus.
+func (m *Mixer) SetMute(bus string, mute bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.bus(bus).Mute = mute
+}
+
+// Duck lowers the named bus to gain over the given seconds, for example
+// to lower the music under dialogue. Duck to 1 to restore it.
+func (m *Mixer) Duck(bus string, gain float32, seconds float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	b := m.bus(bus)
+	b.duckTo = gain
+	b.duckStep = float32(math.Abs(float64(gain-b.Duck)) / max(seconds*SampleRate, 1))
+}
+
+// DialogueDuck is the gain of the music while dialogue shows.
+const DialogueDuck = 0.35
+
+// DuckSeconds is how long the music takes to duck or to come back.
+const DuckSeconds = 0.25
+
+// Talk ducks the music to DialogueDuck while talking, and brings it back
+// once talking stops. It can be called every tick.
+func (m *Mixer) Talk(talking bool) {
+	m.mu.Lock()
+	if talking == m.talking {
+		m.mu.Unlock()
+		return
+	}
+	m.talking = talking
+	m.mu.Unlock()
+	if talking {
+		m.Duck(BusMusic, DialogueDuck, DuckSeconds)
+	} else {
+		m.Duck(BusMusic, 1, DuckSeconds)
+	}
+}
+
+// Play plays the sound on the named bus, and returns a handle to control
+// it. If the sound or the mixer has too many voices playing, the oldest
+// one is stolen. Playing a nil sound returns a Voice that plays nothing.
+func (m *Mixer) Play(s *Sound, bus string) Voice {
+	if s == nil {
+		return Voice{}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.handle(m.play(s, bus))
+}
+
+// PlayAt plays the sound on the named bus, placed at the position at
+// relative to the listener.
+func (m *Mixer) PlayAt(s *Sound, bus string, at Point) Voice {
+	if s == nil {
+		return Voice{}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	v := m.play(s, bus)
+	v.Positional = true
+	v.At = at
+	return m.handle(v)
+}
+
+// handle returns a handle to the voice as it plays now.
+func (m *Mixer) handle(v *voice) Voice {
+	return Voice{mixer: m, v: v, serial: v.serial}
+}
+
+func (m *Mixer) play(s *Sound, bus string) *voice {
+	limit := s.Voices
+	if limit <= 0 {
+		limit = DefaultVoices
+	}
+	var v *voice
+	if m.count(s) >= limit {
+		v = m.oldest(s)
+	} else if len(m.voices) >= max(m.MaxVoices, 1) {
+		v = m.oldest(nil)
+	}
+	if v == nil {
+		v = &voice{}
+		m.voices = append(m.voices, v)
+	}
+	m.serial++
+	*v = voice{Sound: s, Bus: m.bus(bus), Gain: 1, Fade: 1, serial: m.serial}
+	return v
+}
+
+// count returns how many voices play the sound.
+func (m *Mixer) count(s *Sound) int {
+	n := 0
+	for _, v := range m.voices {
+		if v.Sound == s {
+			n++
+		}
+	}
+	return n
+}
+
+// oldest returns the oldest voice that plays the sound, or the oldest
+// voice of any sound but the song if s is nil.
+func (m *Mixer) oldest(s *Sound) *voice {
+	var found *voice
+	for _, v := range m.voices {
+		if (s != nil && v.Sound != s) || (s == nil && v == m.song) {
+			continue
+		}
+		if found == nil || v.serial < found.serial {
+			found = v
+		}
+	}
+	return found
+}
+
+// fadeOut fades out the voice over the given seconds and then stops it.
+func (v *voice) fadeOut(seconds float64) {
+	if seconds <= 0 {
+		v.Done = true
+		return
+	}
+	v.FadeStep = -v.Fade / float32(seconds*SampleRate)
+}
+
+// Music crossfades from the current song to the sound, which loops on
+// the music bus, over the given seconds. A nil sound fades out the music.
+func (m *Mixer) Music(s *Sound, seconds float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.song != nil && m.song.Sound == s && !m.song.Done {
+		return // Already playing.
+	}
+	if m.song != nil {
+		m.song.fadeOut(seconds)
+		m.song = nil
+	}
+	if s == nil {
+		return
+	}
+	m.song = m.play(s, BusMusic)
+	m.song.Loop = true
+	if seconds > 0 {
+		m.song.Fade = 0
+		m.song.FadeStep = 1 / float32(seconds*SampleRate)
+	}
+}
+
+// Place returns the gain and pan of a sound at the position at, heard
+// from listener, where it is silent at distance reach or more.
+func Place(listener, at Point, reach float64) (gain, pan float32) {
+	if reach <= 0 {
+		return 1, 0
+	}
+	dx := float64(at.X - listener.X)
+	dy := float64(at.Y - listener.Y)
+	dist := math.Hypot(dx, dy)
+	gain = float32(max(0, 1-dist/reach))
+	pan = float32(min(max(dx/reach, -1), 1))
+	return gain, pan
+}
+
+// panning returns the left and right gains of an equal power pan,
+// normalized so the center is 1 on both sides.
+func panning(pan float32) (left, right float32) {
+	angle := (float64(pan) + 1) * math.Pi / 4
+	return float32(math.Cos(angle) * math.Sqrt2), float32(math.Sin(angle) * math.Sqrt2)
+}
+
+// Mix mixes the voices into out, which holds interleaved left and right
+// samples, and advances them. Voices that end are removed.
+func (m *Mixer) Mix(out []float32) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	clear(out)
+	frames := len(out) / 2
+	master := m.bus(BusMaster)
+
+	for _, v := range m.voices {
+		if v.Done {
+			continue
+		}
+		gain := v.Gain
+		pan := v.Pan
+		if v.Positional {
+			pg, pp := Place(m.listener, v.At, m.reach)
+			gain *= pg
+			pan = min(max(pan+pp, -1), 1)
+		}
+		gain *= v.Bus.level() * master.level()
+		left, right := panning(pan)
+		samples := v.Sound.Samples
+		for f := 0; f < frames; f++ {
+			if v.Pos >= v.Sound.Frames() {
+				if !v.Loop || v.Sound.Frames() == 0 {
+					v.Done = true
+					break
+				}
+				v.Pos = 0
+			}
+			g := gain * v.Fade
+			out[2*f] += samples[2*v.Pos] * g * left
+			out[2*f+1] += samples[2*v.Pos+1] * g * right
+			v.Pos++
+			if v.FadeStep != 0 {
+				v.Fade = min(max(v.Fade+v.FadeStep, 0), 1)
+				if v.Fade == 0 && v.FadeStep < 0 {
+					v.Done = true
+					break
+				}
+				if v.Fade == 1 {
+					v.FadeStep = 0
+				}
+			}
+		}
+	}
+
+	for _, b := range m.Buses {
+		b.ramp(frames)
+	}
+
+	for i := range out {
+		out[i] = min(max(out[i], -1), 1)
+	}
+
+	kept := m.voices[:0]
+	for _, v := range m.voices {
+		if !v.Done {
+			kept = append(kept, v)
+		}
+	}
+	clear(m.voices[len(kept):])
+	m.voices = kept
+}
+
+// Read mixes into p as little endian 32 bits float stereo samples, so the
+// mixer can be played by an audio player. It never ends.
+func (m *Mixer) Read(p []byte) (int, error) {
+	n := len(p) / 8 * 8
+	if cap(m.mix) < n/4 {
+		m.mix = make([]float32, n/4)
+	}
+	mix := m.mix[:n/4]
+	m.Mix(mix)
+	for i, s := range mix {
+		binary.LittleEndian.PutUint32(p[i*4:], math.Float32bits(s))
+	}
+	return n, nil
+}
+
+// Start starts playing the mixer on the audio device.
+func (m *Mixer) Start() error {
+	if m.player == nil {
+		player, err := audioContext().NewPlayerF32(m)
+		if err != nil {
+			return err
+		}
+		m.player = player
+	}
+	m.player.Play()
+	return nil
+}
+
+// Pause pauses playing the mixer on the audio device.
+func (m *Mixer) Pause() {
+	if m.player != nil {
+		m.player.Pause()
+	}
+}
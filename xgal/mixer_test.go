@@ -0,0 +1,201 @@
+package xgal
+
+import (
+	"math"
+	"testing"
+)
+
+// flat returns a sound of the given amount of frames with all samples v.
+func flat(frames int, v float32) *Sound {
+	s := &Sound{Samples: make([]float32, frames*2)}
+	for i := range s.Samples {
+		s.Samples[i] = v
+	}
+	return s
+}
+
+func near(a, b float32) bool {
+	return math.Abs(float64(a-b)) < 1e-4
+}
+
+func TestMixBuses(t *testing.T) {
+	m := NewMixer()
+	s := flat(8, 0.25)
+	m.Play(s, BusEffect)
+	m.Play(s, BusMusic)
+	out := make([]float32, 4)
+	m.Mix(out)
+	if !near(out[0], 0.5) || !near(out[1], 0.5) {
+		t.Errorf("two voices: %v", out)
+	}
+	m.SetGain(BusEffect, 0.5)
+	m.Mix(out)
+	if !near(out[0], 0.375) {
+		t.Errorf("effect gain: %v", out)
+	}
+	m.SetMute(BusMusic, true)
+	m.SetGain(BusMaster, 0.5)
+	m.Mix(out)
+	if !near(out[0], 0.0625) {
+		t.Errorf("mute and master: %v", out)
+	}
+}
+
+func TestMixEnd(t *testing.T) {
+	m := NewMixer()
+	m.Play(flat(3, 0.5), BusEffect)
+	loop := m.Play(flat(3, 0.25), BusEffect)
+	loop.SetLoop(true)
+	out := make([]float32, 10)
+	m.Mix(out)
+	expect := []float32{0.75, 0.75, 0.75, 0.75, 0.75, 0.75, 0.25, 0.25, 0.25, 0.25}
+	for i := range out {
+		if !near(out[i], expect[i]) {
+			t.Fatalf("end: %v != %v", out, expect)
+		}
+	}
+	if len(m.voices) != 1 || m.voices[0] != loop.v || loop.v.Pos != 2 {
+		t.Errorf("voices after end: %d, pos %d", len(m.voices), loop.v.Pos)
+	}
+}
+
+func TestMixClamp(t *testing.T) {
+	m := NewMixer()
+	s := flat(4, 0.75)
+	m.Play(s, BusEffect)
+	m.Play(s, BusEffect)
+	out := make([]float32, 2)
+	m.Mix(out)
+	if out[0] != 1 || out[1] != 1 {
+		t.Errorf("not clamped: %v", out)
+	}
+}
+
+func TestVoiceStealing(t *testing.T) {
+	m := NewMixer()
+	s := flat(100, 0.1)
+	s.Voices = 2
+	first := m.Play(s, BusEffect)
+	first.v.Pos = 50
+	m.Play(s, BusEffect)
+	third := m.Play(s, BusEffect)
+	if len(m.voices) != 2 || third.v != first.v || third.v.Pos != 0 {
+		t.Errorf("sound limit: %d voices", len(m.voices))
+	}
+	// The stolen handle no longer controls the voice.
+	first.SetGain(0)
+	first.Stop(0)
+	if first.Playing() || !third.Playing() || third.v.Gain != 1 {
+		t.Errorf("stolen handle controls the new sound")
+	}
+
+	m = NewMixer()
+	m.MaxVoices = 3
+	var voices []Voice
+	for i := 0; i < 4; i++ {
+		voices = append(voices, m.Play(flat(10, 0.1), BusEffect))
+	}
+	if len(m.voices) != 3 || voices[3].v != voices[0].v {
+		t.Errorf("mixer limit: %d voices", len(m.voices))
+	}
+	if (Voice{}).Playing() {
+		t.Errorf("zero voice plays")
+	}
+	if m.Play(nil, BusEffect).Playing() || m.PlayAt(nil, BusEffect, Pt(0, 0)).Playing() {
+		t.Errorf("nil sound plays")
+	}
+}
+
+func TestMusicCrossfade(t *testing.T) {
+	m := NewMixer()
+	a := flat(SampleRate, 0.5)
+	b := flat(SampleRate, 0.25)
+	m.Music(a, 0)
+	song := m.song
+	if !song.Loop || song.Bus.Name != BusMusic || song.Fade != 1 {
+		t.Fatalf("song: %+v", song)
+	}
+	m.Music(b, 1)
+	out := make([]float32, SampleRate) // half a second
+	m.Mix(out)
+	// The fades accumulate rounding errors over many frames.
+	if math.Abs(float64(song.Fade-0.5)) > 0.01 || math.Abs(float64(m.song.Fade-0.5)) > 0.01 {
+		t.Errorf("halfway: %f %f", song.Fade, m.song.Fade)
+	}
+	m.Mix(out)
+	m.Mix(out)
+	if !song.Done || len(m.voices) != 1 || m.song.Fade != 1 {
+		t.Errorf("done: %t %d %f", song.Done, len(m.voices), m.song.Fade)
+	}
+}
+
+func TestDuck(t *testing.T) {
+	m := NewMixer()
+	m.Duck(BusMusic, 0.25, 1)
+	m.Mix(make([]float32, SampleRate))
+	if b := m.Buses[BusMusic]; !near(b.Duck, 0.625) {
+		t.Errorf("half ducked: %f", b.Duck)
+	}
+	m.Mix(make([]float32, 4*SampleRate))
+	if b := m.Buses[BusMusic]; b.Duck != 0.25 {
+		t.Errorf("ducked: %f", b.Duck)
+	}
+
+	m = NewMixer()
+	m.Talk(true)
+	m.Talk(true)
+	m.Mix(make([]float32, 2*SampleRate))
+	if b := m.Buses[BusMusic]; b.Duck != DialogueDuck {
+		t.Errorf("ducked under dialogue: %f", b.Duck)
+	}
+	m.Talk(false)
+	m.Mix(make([]float32, 2*SampleRate))
+	if b := m.Buses[BusMusic]; b.Duck != 1 {
+		t.Errorf("back after dialogue: %f", b.Duck)
+	}
+}
+
+func TestPlace(t *testing.T) {
+	cases := []struct {
+		at   Point
+		gain float32
+		pan  float32
+	}{
+		{Pt(0, 0), 1, 0},
+		{Pt(50, 0), 0.5, 0.5},
+		{Pt(-100, 0), 0, -1},
+		{Pt(0, 200), 0, 0},
+	}
+	for _, c := range cases {
+		gain, pan := Place(Pt(0, 0), c.at, 100)
+		if !near(gain, c.gain) || !near(pan, c.pan) {
+			t.Errorf("%v: %f %f != %f %f", c.at, gain, pan, c.gain, c.pan)
+		}
+	}
+	m := NewMixer()
+	m.SetReach(100)
+	m.PlayAt(flat(4, 0.5), BusEffect, Pt(-100, 0))
+	out := make([]float32, 2)
+	m.Mix(out)
+	if out[0] != 0 || out[1] != 0 {
+		t.Errorf("out of reach: %v", out)
+	}
+	m.PlayAt(flat(4, 0.5), BusEffect, Pt(-50, 0))
+	m.Mix(out)
+	if out[0] <= out[1] {
+		t.Errorf("left: %v", out)
+	}
+}
+
+func TestMixerRead(t *testing.T) {
+	m := NewMixer()
+	m.Play(flat(4, 0.5), BusEffect)
+	p := make([]byte, 19)
+	n, err := m.Read(p)
+	if n != 16 || err != nil {
+		t.Fatalf("read: %d %v", n, err)
+	}
+	if samples := floats(p[:n]); !near(samples[0], 0.5) || !near(samples[3], 0.5) {
+		t.Errorf("read samples: %v", samples)
+	}
+}
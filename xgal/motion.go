@@ -0,0 +1,93 @@
+package xgal
+
+// MotionTicks is how many ticks the motion presets take by default.
+const MotionTicks = 12
+
+// Motion are the animatable properties of how a widget is drawn, on top
+// of where it is laid out, so animating them does not change the layout.
+// The size is animated by placing the widget, see [TweenRect].
+// Motions are drawn with [Motion.Draw].
+type Motion struct {
+	Offset Point   // Offset moves the widget from its bounds.
+	Alpha  float64 // Alpha is the opacity, from 0 for invisible to 1.
+	Tint   RGBA    // Tint multiplies the colours of the widget, White for none.
+	Scale  float64 // Scale scales the widget around its center.
+
+	canvas *Surface // canvas is what the widget is drawn on before it is moved.
+}
+
+// NewMotion returns a motion that is still.
+func NewMotion() *Motion {
+	return &Motion{Alpha: 1, Tint: White, Scale: 1}
+}
+
+// Still reports whether the motion draws the widget as it is. A nil
+// motion is still.
+func (m *Motion) Still() bool {
+	return m == nil || (m.Offset == Point{} && m.Alpha == 1 && m.Tint == White && m.Scale == 1)
+}
+
+// Box returns where the widget with the bounds is drawn.
+func (m *Motion) Box(bounds Rectangle) Rectangle {
+	if m.Still() {
+		return bounds
+	}
+	w := int(float64(bounds.Dx()) * m.Scale)
+	h := int(float64(bounds.Dy()) * m.Scale)
+	c := bounds.Min.Add(bounds.Max).Div(2).Add(m.Offset)
+	return Rect(c.X-w/2, c.Y-h/2, c.X-w/2+w, c.Y-h/2+h)
+}
+
+// Move returns a tween of the offset of the motion.
+func (m *Motion) Move(from, to Point, ticks int, ease Ease) *Tween {
+	return TweenPoint(from, to, ticks, ease, func(p Point) { m.Offset = p })
+}
+
+// Fade returns a tween of the alpha of the motion.
+func (m *Motion) Fade(from, to float64, ticks int, ease Ease) *Tween {
+	return NewTween(from, to, ticks, ease, func(v float64) { m.Alpha = v })
+}
+
+// Zoom returns a tween of the scale of the motion.
+func (m *Motion) Zoom(from, to float64, ticks int, ease Ease) *Tween {
+	return NewTween(from, to, ticks, ease, func(v float64) { m.Scale = v })
+}
+
+// Paint returns a tween of the tint of the motion.
+func (m *Motion) Paint(from, to RGBA, ticks int, ease Ease) *Tween {
+	return TweenColor(from, to, ticks, ease, func(c RGBA) { m.Tint = c })
+}
+
+// SlideIn returns an animation that slides the widget in from the offset
+// while it fades in, such as for a menu that opens. The motion starts
+// out, so the widget is not drawn in before the first tick.
+func (m *Motion) SlideIn(from Point, ticks int) Animation {
+	m.Offset, m.Alpha = from, 0
+	return NewParallel(m.Move(from, Point{}, ticks, EaseOutCubic), m.Fade(0, 1, ticks, EaseOutQuad))
+}
+
+// SlideOut returns an animation that slides the widget out to the offset
+// while it fades out.
+func (m *Motion) SlideOut(to Point, ticks int) Animation {
+	return NewParallel(m.Move(Point{}, to, ticks, EaseInCubic), m.Fade(1, 0, ticks, EaseInQuad))
+}
+
+// FadeIn returns an animation that fades the widget in while it grows a
+// little, such as for a dialog that opens. The motion starts out.
+func (m *Motion) FadeIn(ticks int) Animation {
+	m.Alpha, m.Scale = 0, 0.9
+	return NewParallel(m.Fade(0, 1, ticks, EaseOutQuad), m.Zoom(0.9, 1, ticks, EaseOutBack))
+}
+
+// FadeOut returns an animation that fades the widget out while it
+// shrinks a little.
+func (m *Motion) FadeOut(ticks int) Animation {
+	return NewParallel(m.Fade(1, 0, ticks, EaseInQuad), m.Zoom(1, 0.9, ticks, EaseInQuad))
+}
+
+// Bounce returns an animation that squeezes the widget and lets it bounce
+// back, such as for a button that is pressed.
+func (m *Motion) Bounce(ticks int) Animation {
+	squeeze := max(ticks/3, 1)
+	return NewSequence(m.Zoom(1, 0.9, squeeze, EaseOutQuad), m.Zoom(0.9, 1, ticks-squeeze, EaseOutBounce))
+}
@@ -0,0 +1,259 @@
+package xgal
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"io/fs"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Swatch is a named palette as stored in a GIMP .gpl palette file.
+type Swatch struct {
+	Name    string   // Name of the palette.
+	Columns int      // Columns is the preferred amount of columns to show.
+	Colors  Palette  // Colors of the palette.
+	Names   []string // Names of the colors, may be empty strings.
+}
+
+// TransparentName is the name of a palette color that is transparent.
+// GIMP palettes have no alpha, so this is how transparency is stored.
+const TransparentName = "Transparent"
+
+// ReadSwatch reads a GIMP palette. Colors named [TransparentName] get
+// an alpha of 0 but keep their red, green and blue.
+func ReadSwatch(rd io.Reader) (*Swatch, error) {
+	scan := bufio.NewScanner(rd)
+	if !scan.Scan() || strings.TrimSpace(scan.Text()) != "GIMP Palette" {
+		return nil, errors.New("not a GIMP palette")
+	}
+	s := &Swatch{}
+	for line := 2; scan.Scan(); line++ {
+		text := strings.TrimSpace(scan.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		if name, ok := strings.CutPrefix(text, "Name:"); ok {
+			s.Name = strings.TrimSpace(name)
+			continue
+		}
+		if columns, ok := strings.CutPrefix(text, "Columns:"); ok {
+			n, err := strconv.Atoi(strings.TrimSpace(columns))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			s.Columns = n
+			continue
+		}
+		fields := strings.Fields(text)
+		if len(fields) < 3 {
+			return nil, fmt.Errorf("line %d: expected R G B", line)
+		}
+		var rgb [3]uint8
+		for i := range rgb {
+			v, err := strconv.ParseUint(fields[i], 10, 8)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			rgb[i] = uint8(v)
+		}
+		name := strings.Join(fields[3:], " ")
+		a := uint8(255)
+		if strings.EqualFold(name, TransparentName) {
+			a = 0
+		}
+		s.Colors = append(s.Colors, color.NRGBA{R: rgb[0], G: rgb[1], B: rgb[2], A: a})
+		s.Names = append(s.Names, name)
+	}
+	return s, scan.Err()
+}
+
+// Write writes the swatch as a GIMP palette.
+func (s Swatch) Write(wr io.Writer) error {
+	buf := bufio.NewWriter(wr)
+	fmt.Fprintln(buf, "GIMP Palette")
+	fmt.Fprintf(buf, "Name: %s\n", s.Name)
+	if s.Columns > 0 {
+		fmt.Fprintf(buf, "Columns: %d\n", s.Columns)
+	}
+	for i, c := range s.Colors {
+		n := color.NRGBAModel.Convert(c).(color.NRGBA)
+		name := ""
+		if i < len(s.Names) {
+			name = s.Names[i]
+		}
+		if n.A == 0 && name == "" {
+			name = TransparentName
+		}
+		fmt.Fprintf(buf, "%3d %3d %3d\t%s\n", n.R, n.G, n.B, name)
+	}
+	return buf.Flush()
+}
+
+// SaveFile writes the swatch to the named file.
+func (s Swatch) SaveFile(name string) error {
+	out, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	return s.Write(out)
+}
+
+// LoadSwatch loads a GIMP palette file from fsys.
+func LoadSwatch(fsys fs.FS, name string) (*Swatch, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	s, err := ReadSwatch(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return s, nil
+}
+
+// Swap returns a copy of pal where the colors starting at index from
+// are replaced by the colors of with. This makes color variants of the
+// same image, such as differently colored enemies.
+func Swap(pal Palette, from int, with Palette) Palette {
+	out := make(Palette, len(pal))
+	copy(out, pal)
+	for i, c := range with {
+		if at := from + i; at >= 0 && at < len(out) {
+			out[at] = c
+		}
+	}
+	return out
+}
+
+// Rotate returns a copy of pal where the colors from index low up to and
+// including high are rotated by steps places. Positive steps move each
+// color to a higher index, with the highest one wrapping to low.
+func Rotate(pal Palette, low, high, steps int) Palette {
+	out := make(Palette, len(pal))
+	copy(out, pal)
+	low, high = max(low, 0), min(high, len(pal)-1)
+	n := high - low + 1
+	if n <= 1 {
+		return out
+	}
+	steps = ((steps % n) + n) % n
+	for i := 0; i < n; i++ {
+		out[low+(i+steps)%n] = pal[low+i]
+	}
+	return out
+}
+
+// Fade returns a copy of pal with every color faded towards col by
+// level, from 0 for unchanged up to 1 for all col. Alpha is kept, so
+// transparent colors stay transparent.
+func Fade(pal Palette, col RGBA, level float64) Palette {
+	level = min(max(level, 0), 1)
+	out := make(Palette, len(pal))
+	lerp := func(x, y uint8) uint8 {
+		return uint8(math.Round(float64(x) + (float64(y)-float64(x))*level))
+	}
+	for i, c := range pal {
+		n := color.NRGBAModel.Convert(c).(color.NRGBA)
+		out[i] = color.NRGBA{R: lerp(n.R, col.R), G: lerp(n.G, col.G), B: lerp(n.B, col.B), A: n.A}
+	}
+	return out
+}
+
+// Colorize fills pix, which must have room for 4 bytes per pixel, with
+// the premultiplied RGBA colors of img as looked up in pal instead of
+// in the palette of img itself. Indexes outside of pal are transparent.
+func Colorize(img *Paletted, pal Palette, pix []byte) {
+	var lut [256][4]byte
+	for i, c := range pal {
+		if i >= len(lut) {
+			break
+		}
+		r, g, b, a := c.RGBA()
+		lut[i] = [4]byte{byte(r >> 8), byte(g >> 8), byte(b >> 8), byte(a >> 8)}
+	}
+	b := img.Bounds()
+	at := 0
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		row := img.Pix[(y-b.Min.Y)*img.Stride:]
+		for x := 0; x < b.Dx(); x++ {
+			copy(pix[at:at+4], lut[row[x]][:])
+			at += 4
+		}
+	}
+}
+
+// Indexed is a paletted image that is drawn through a [Surface]. The
+// surface is painted again whenever the palette changes, which allows
+// palette swaps, cycling and fades on indexed textures.
+type Indexed struct {
+	Image   *Paletted // Image with the indexes and the base palette.
+	Palette Palette   // Palette the surface was last painted with.
+	Surface *Surface  // Surface to draw with.
+	pix     []byte
+}
+
+// Index makes an [Indexed] surface from a paletted image.
+func Index(img *Paletted) *Indexed {
+	b := img.Bounds()
+	i := &Indexed{Image: img}
+	i.Surface = Prepare(b.Dx(), b.Dy())
+	i.pix = make([]byte, 4*b.Dx()*b.Dy())
+	i.Repaint(img.Palette)
+	return i
+}
+
+// Repaint paints the surface with pal if it differs from the palette it
+// was painted with last. It reports whether it painted.
+func (i *Indexed) Repaint(pal Palette) bool {
+	if i.Palette != nil && samePalette(i.Palette, pal) {
+		return false
+	}
+	i.Palette = append(i.Palette[:0], pal...)
+	Colorize(i.Image, pal, i.pix)
+	i.Surface.WritePixels(i.pix)
+	return true
+}
+
+// Deallocate releases the surface.
+func (i *Indexed) Deallocate() {
+	if i.Surface != nil {
+		i.Surface.Deallocate()
+		i.Surface = nil
+	}
+}
+
+func samePalette(a, b Palette) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		r1, g1, b1, a1 := a[i].RGBA()
+		r2, g2, b2, a2 := b[i].RGBA()
+		if r1 != r2 || g1 != g2 || b1 != b2 || a1 != a2 {
+			return false
+		}
+	}
+	return true
+}
+
+// Stencil loads a paletted image file from fsys as an [Indexed] surface.
+func Stencil(fsys fs.FS, name string) (*Indexed, error) {
+	img, err := Pixels(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	pimg, ok := img.(*image.Paletted)
+	if !ok {
+		return nil, errors.New(name + " is not a paletted image")
+	}
+	return Index(pimg), nil
+}
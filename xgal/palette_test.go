@@ -0,0 +1,132 @@
+package xgal
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"os"
+	"testing"
+)
+
+const testGPL = `GIMP Palette
+Name: Test
+Columns: 4
+# comment
+  0 254 127	Transparent
+  0   0   0	Black
+255 255 255	White
+ 85 170 255
+`
+
+func TestReadSwatch(t *testing.T) {
+	s, err := ReadSwatch(bytes.NewBufferString(testGPL))
+	if err != nil {
+		t.Fatalf("read: %s", err)
+	}
+	if s.Name != "Test" || s.Columns != 4 || len(s.Colors) != 4 {
+		t.Fatalf("swatch: %+v", s)
+	}
+	if _, _, _, a := s.Colors[0].RGBA(); a != 0 {
+		t.Errorf("transparent color has alpha %d", a)
+	}
+	if s.Colors[3] != (color.NRGBA{R: 85, G: 170, B: 255, A: 255}) || s.Names[3] != "" {
+		t.Errorf("unnamed color: %v %q", s.Colors[3], s.Names[3])
+	}
+
+	buf := &bytes.Buffer{}
+	if err := s.Write(buf); err != nil {
+		t.Fatalf("write: %s", err)
+	}
+	again, err := ReadSwatch(buf)
+	if err != nil {
+		t.Fatalf("read again: %s", err)
+	}
+	if !samePalette(s.Colors, again.Colors) || again.Colors[0] != s.Colors[0] {
+		t.Errorf("round trip: %v != %v", again.Colors, s.Colors)
+	}
+
+	if _, err := ReadSwatch(bytes.NewBufferString("JASC-PAL\n")); err == nil {
+		t.Errorf("expected error")
+	}
+}
+
+func TestLoadSwatchPack(t *testing.T) {
+	s, err := LoadSwatch(os.DirFS("../pack/pal"), "xmas.gpl")
+	if err != nil {
+		t.Fatalf("load: %s", err)
+	}
+	if len(s.Colors) != 65 || s.Names[0] != TransparentName {
+		t.Errorf("xmas palette: %d colors, first %q", len(s.Colors), s.Names[0])
+	}
+}
+
+var (
+	red   = color.NRGBA{R: 255, A: 255}
+	green = color.NRGBA{G: 255, A: 255}
+	blue  = color.NRGBA{B: 255, A: 255}
+	gray  = color.NRGBA{R: 128, G: 128, B: 128, A: 255}
+)
+
+func TestSwap(t *testing.T) {
+	pal := Palette{red, green, blue}
+	observe := Swap(pal, 1, Palette{gray, gray, gray})
+	expect := Palette{red, gray, gray}
+	if !samePalette(observe, expect) {
+		t.Errorf("swap: %v != %v", observe, expect)
+	}
+	if pal[1] != green {
+		t.Errorf("swap changed the original")
+	}
+}
+
+func TestRotate(t *testing.T) {
+	pal := Palette{gray, red, green, blue, gray}
+	cases := []struct {
+		steps  int
+		expect Palette
+	}{
+		{0, Palette{gray, red, green, blue, gray}},
+		{1, Palette{gray, blue, red, green, gray}},
+		{-1, Palette{gray, green, blue, red, gray}},
+		{4, Palette{gray, blue, red, green, gray}},
+	}
+	for _, c := range cases {
+		if observe := Rotate(pal, 1, 3, c.steps); !samePalette(observe, c.expect) {
+			t.Errorf("%d: %v != %v", c.steps, observe, c.expect)
+		}
+	}
+	if observe := Rotate(pal, 3, 9, 1); !samePalette(observe, Palette{gray, red, green, gray, blue}) {
+		t.Errorf("clipped range: %v", observe)
+	}
+}
+
+func TestFade(t *testing.T) {
+	pal := Palette{color.NRGBA{}, red, gray}
+	half := Fade(pal, Black, 0.5)
+	expect := Palette{color.NRGBA{}, color.NRGBA{R: 128, A: 255}, color.NRGBA{R: 64, G: 64, B: 64, A: 255}}
+	if !samePalette(half, expect) {
+		t.Errorf("half: %v != %v", half, expect)
+	}
+	white := Fade(pal, White, 1)
+	if white[1] != (color.NRGBA{R: 255, G: 255, B: 255, A: 255}) || white[0] != (color.NRGBA{R: 255, G: 255, B: 255}) {
+		t.Errorf("white: %v", white)
+	}
+	if _, _, _, a := white[0].RGBA(); a != 0 {
+		t.Errorf("transparent color became visible")
+	}
+}
+
+func TestColorize(t *testing.T) {
+	img := image.NewPaletted(image.Rect(2, 3, 4, 5), Palette{red, green})
+	img.SetColorIndex(2, 3, 1)
+	img.SetColorIndex(3, 4, 1)
+	pix := make([]byte, 4*4)
+	Colorize(img, Palette{color.NRGBA{}, blue}, pix)
+	expect := []byte{
+		0, 0, 255, 255, 0, 0, 0, 0,
+		0, 0, 0, 0, 0, 0, 255, 255,
+	}
+	if !bytes.Equal(pix, expect) {
+		t.Errorf("colorize: %v != %v", pix, expect)
+	}
+}
@@ -0,0 +1,389 @@
+package xgal
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// PSGClock is the clock of the SN76489 sound chip of the Master System.
+const PSGClock = 3579545
+
+// PSGChannels is the amount of channels of the chip: three square wave
+// tone channels and one noise channel.
+const PSGChannels = 4
+
+// PSG emulates an SN76489 style programmable sound generator.
+type PSG struct {
+	Period      [PSGChannels]int  // Period of the tone channels, 1 to 1023.
+	Attenuation [PSGChannels]int  // Attenuation from 0 for loud to 15 for silent.
+	Noise       int               // Noise mode: bit 2 white noise, bits 0-1 the rate.
+	Mute        [PSGChannels]bool // Mute silences channels.
+	counter     [PSGChannels]int  // Counter to the next flip.
+	output      [PSGChannels]bool // Output level of the channel.
+	shift       uint16            // Shift register of the noise channel.
+	ticks       float64           // Ticks left over from the last sample.
+}
+
+// psgVolumes are the amplitudes of the attenuations in 2dB steps.
+var psgVolumes = func() (v [16]float32) {
+	for i := 0; i < 15; i++ {
+		v[i] = float32(math.Pow(10, -2*float64(i)/20))
+	}
+	return v
+}()
+
+// psgNoiseReset is the value the noise shift register starts with.
+const psgNoiseReset = 0x8000
+
+// NewPSG returns a silent chip.
+func NewPSG() *PSG {
+	p := &PSG{shift: psgNoiseReset}
+	for i := range p.Attenuation {
+		p.Attenuation[i] = 15
+		p.Period[i] = 1
+	}
+	return p
+}
+
+// SetNoise sets the noise mode and resets the noise shift register, as
+// writing the noise register does on the real chip.
+func (p *PSG) SetNoise(mode int) {
+	p.Noise = mode & 7
+	p.shift = psgNoiseReset
+}
+
+// noisePeriod returns the period of the noise channel.
+func (p *PSG) noisePeriod() int {
+	switch p.Noise & 3 {
+	case 0:
+		return 0x10
+	case 1:
+		return 0x20
+	case 2:
+		return 0x40
+	default:
+		return p.Period[2]
+	}
+}
+
+// tick advances the chip by one tick of the clock divided by 16.
+func (p *PSG) tick() {
+	for c := 0; c < 3; c++ {
+		p.counter[c]--
+		if p.counter[c] <= 0 {
+			p.counter[c] = max(p.Period[c], 1)
+			p.output[c] = !p.output[c]
+		}
+	}
+	p.counter[3]--
+	if p.counter[3] <= 0 {
+		p.counter[3] = max(p.noisePeriod(), 1)
+		p.output[3] = !p.output[3]
+		if p.output[3] {
+			// The shift register shifts on the rising edge only.
+			var feedback uint16
+			if p.Noise&4 != 0 {
+				feedback = (p.shift ^ p.shift>>3) & 1 // white noise
+			} else {
+				feedback = p.shift & 1 // periodic noise
+			}
+			p.shift = p.shift>>1 | feedback<<15
+		}
+	}
+}
+
+// level returns the output of channel c.
+func (p *PSG) level(c int) float32 {
+	if p.Mute[c] {
+		return 0
+	}
+	high := p.output[c]
+	if c == 3 {
+		high = p.shift&1 != 0
+	}
+	amp := psgVolumes[p.Attenuation[c]&15] / PSGChannels
+	if high {
+		return amp
+	}
+	return -amp
+}
+
+// Render fills out with mono samples at [SampleRate]. Every sample is the
+// average of the chip ticks it covers, which keeps high notes clean.
+func (p *PSG) Render(out []float32) {
+	step := float64(PSGClock) / 16 / SampleRate
+	for i := range out {
+		p.ticks += step
+		n := int(p.ticks)
+		p.ticks -= float64(n)
+		var sum float32
+		for t := 0; t < n; t++ {
+			p.tick()
+			for c := 0; c < PSGChannels; c++ {
+				sum += p.level(c)
+			}
+		}
+		if n > 0 {
+			sum /= float32(n)
+		}
+		out[i] = sum
+	}
+}
+
+// PeriodOf returns the tone period of the chip for a frequency in Hz.
+func PeriodOf(hz float64) int {
+	if hz <= 0 {
+		return 1023
+	}
+	return min(max(int(math.Round(PSGClock/(32*hz))), 1), 1023)
+}
+
+// Note is one cell of a row of a score.
+type Note struct {
+	Hold   bool // Hold keeps the channel as it is.
+	Off    bool // Off silences the channel.
+	Hz     float64
+	Noise  int // Noise mode for the noise channel.
+	Volume int // Volume from 0 for silent to 15 for loud.
+}
+
+// Row is a row of notes, one per channel, played for one step.
+type Row struct {
+	Notes [PSGChannels]Note
+	Tempo int // Tempo in beats per minute from this row on, 0 to keep it.
+}
+
+// Score is a simple text based song for the [PSG]. Every line has one
+// cell per channel, separated by white space: a note such as C4, F#3 or
+// Bb5, "." to hold, or "-" to stop the channel. For the noise channel
+// the cell is n0 to n3 for periodic noise or w0 to w3 for white noise,
+// where 3 uses the period of the third tone channel. A cell may end in
+// ":volume", with volume from 0 up to 15. Lines that start with # are
+// comments, and the following commands are supported:
+//
+//	title name  sets the title.
+//	tempo bpm   changes the tempo, also in the middle of the song.
+//	speed rows  sets the amount of rows per beat, 4 by default.
+//	loop        marks the row the song loops back to.
+type Score struct {
+	Title string
+	Tempo int   // Tempo is the starting tempo in beats per minute.
+	Speed int   // Speed is the amount of rows per beat.
+	Loop  int   // Loop is the row to loop back to, -1 if the song does not loop.
+	Rows  []Row // Rows of the score.
+}
+
+// noteNames are the semitone offsets of the notes from C.
+var noteNames = map[byte]int{'C': 0, 'D': 2, 'E': 4, 'F': 5, 'G': 7, 'A': 9, 'B': 11}
+
+// Pitch returns the frequency of a note name such as A4, C#3 or Bb5.
+func Pitch(name string) (float64, error) {
+	if len(name) < 2 {
+		return 0, fmt.Errorf("bad note %q", name)
+	}
+	semi, ok := noteNames[name[0]]
+	if !ok {
+		return 0, fmt.Errorf("bad note %q", name)
+	}
+	rest := name[1:]
+	switch rest[0] {
+	case '#':
+		semi++
+		rest = rest[1:]
+	case 'b':
+		semi--
+		rest = rest[1:]
+	}
+	octave, err := strconv.Atoi(rest)
+	if err != nil {
+		return 0, fmt.Errorf("bad note %q", name)
+	}
+	midi := 12*(octave+1) + semi
+	return 440 * math.Pow(2, float64(midi-69)/12), nil
+}
+
+// parseNote parses a cell of channel c.
+func parseNote(cell string, c int) (Note, error) {
+	note := Note{Volume: 15}
+	if name, vol, ok := strings.Cut(cell, ":"); ok {
+		v, err := strconv.Atoi(vol)
+		if err != nil || v < 0 || v > 15 {
+			return note, fmt.Errorf("bad volume %q", cell)
+		}
+		note.Volume = v
+		cell = name
+	}
+	switch {
+	case cell == ".":
+		note.Hold = true
+	case cell == "-":
+		note.Off = true
+	case c == 3:
+		if len(cell) != 2 || (cell[0] != 'n' && cell[0] != 'w') || cell[1] < '0' || cell[1] > '3' {
+			return note, fmt.Errorf("bad noise %q", cell)
+		}
+		note.Noise = int(cell[1] - '0')
+		if cell[0] == 'w' {
+			note.Noise |= 4
+		}
+	default:
+		hz, err := Pitch(cell)
+		if err != nil {
+			return note, err
+		}
+		note.Hz = hz
+	}
+	return note, nil
+}
+
+// ReadScore reads a score in the text format described at [Score].
+func ReadScore(rd io.Reader) (*Score, error) {
+	s := &Score{Tempo: 120, Speed: 4, Loop: -1}
+	scan := bufio.NewScanner(rd)
+	tempo := 0
+	for line := 1; scan.Scan(); line++ {
+		text := strings.TrimSpace(scan.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		var err error
+		switch fields[0] {
+		case "title":
+			s.Title = strings.TrimSpace(strings.TrimPrefix(text, "title"))
+			continue
+		case "tempo", "speed":
+			if len(fields) != 2 {
+				return nil, fmt.Errorf("line %d: %s needs one value", line, fields[0])
+			}
+			var v int
+			v, err = strconv.Atoi(fields[1])
+			if err != nil || v <= 0 {
+				return nil, fmt.Errorf("line %d: bad %s %q", line, fields[0], fields[1])
+			}
+			if fields[0] == "speed" {
+				s.Speed = v
+			} else if len(s.Rows) == 0 {
+				s.Tempo = v
+			} else {
+				tempo = v
+			}
+			continue
+		case "loop":
+			s.Loop = len(s.Rows)
+			continue
+		}
+		if len(fields) > PSGChannels {
+			return nil, fmt.Errorf("line %d: more than %d channels", line, PSGChannels)
+		}
+		row := Row{Tempo: tempo}
+		tempo = 0
+		for c := range row.Notes {
+			if c >= len(fields) {
+				row.Notes[c].Hold = true
+				continue
+			}
+			row.Notes[c], err = parseNote(fields[c], c)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+		}
+		s.Rows = append(s.Rows, row)
+	}
+	return s, scan.Err()
+}
+
+// Render plays the score once on a new chip with the given channels
+// muted and returns the mono samples at [SampleRate], together with the
+// sample the loop starts at, or -1 if the score does not loop.
+func (s *Score) Render(mute ...int) (samples []float32, loop int) {
+	psg := NewPSG()
+	for _, c := range mute {
+		if c >= 0 && c < PSGChannels {
+			psg.Mute[c] = true
+		}
+	}
+	loop = -1
+	tempo := s.Tempo
+	speed := max(s.Speed, 1)
+	due := 0.0
+	for r, row := range s.Rows {
+		if r == s.Loop {
+			loop = len(samples)
+		}
+		if row.Tempo > 0 {
+			tempo = row.Tempo
+		}
+		for c, note := range row.Notes {
+			switch {
+			case note.Hold:
+			case note.Off:
+				psg.Attenuation[c] = 15
+			case c == 3:
+				psg.SetNoise(note.Noise)
+				psg.Attenuation[c] = 15 - note.Volume
+			default:
+				psg.Period[c] = PeriodOf(note.Hz)
+				psg.Attenuation[c] = 15 - note.Volume
+			}
+		}
+		due += SampleRate * 60 / float64(max(tempo, 1)*speed)
+		n := int(due) - len(samples)
+		samples = append(samples, make([]float32, n)...)
+		psg.Render(samples[len(samples)-n:])
+	}
+	return samples, loop
+}
+
+// Stereo returns mono samples as interleaved stereo samples.
+func Stereo(mono []float32) []float32 {
+	out := make([]float32, 2*len(mono))
+	for i, s := range mono {
+		out[2*i] = s
+		out[2*i+1] = s
+	}
+	return out
+}
+
+// Compose renders the score as a [Sound] for the [Mixer], with the given
+// channels muted.
+func Compose(s *Score, mute ...int) *Sound {
+	mono, _ := s.Render(mute...)
+	return &Sound{Name: s.Title, Samples: Stereo(mono)}
+}
+
+// scoreStream is a rendered score as little endian 32 bits float stereo
+// bytes, which knows where its loop starts.
+type scoreStream struct {
+	*bytes.Reader
+	loop int64
+}
+
+// LoopStart returns the byte offset the loop starts at, or -1.
+func (s scoreStream) LoopStart() int64 {
+	return s.loop
+}
+
+// looper is a stream that loops back to an offset other than the start.
+type looper interface {
+	LoopStart() int64
+}
+
+// decodeScore renders a score file like the other audio decoders do.
+func decodeScore(reader io.Reader) (io.ReadSeeker, error) {
+	score, err := ReadScore(reader)
+	if err != nil {
+		return nil, err
+	}
+	mono, loop := score.Render()
+	start := int64(-1)
+	if loop >= 0 {
+		start = int64(loop) * 8
+	}
+	return scoreStream{Reader: bytes.NewReader(raw(Stereo(mono))), loop: start}, nil
+}
@@ -0,0 +1,192 @@
+package xgal
+
+import (
+	"io"
+	"math"
+	"os"
+	"strings"
+	"testing"
+)
+
+// squareReference returns the reference PCM of a single tone channel at
+// full volume: a square wave that flips every period chip ticks, with
+// every sample the average of the ticks it covers.
+func squareReference(period, samples int) []float32 {
+	step := float64(PSGClock) / 16 / SampleRate
+	out := make([]float32, samples)
+	done := 0
+	for i := range out {
+		end := int(float64(i+1) * step)
+		var sum float32
+		for k := done; k < end; k++ {
+			if (k/period)%2 == 0 {
+				sum += 0.25
+			} else {
+				sum -= 0.25
+			}
+		}
+		out[i] = sum / float32(end-done)
+		done = end
+	}
+	return out
+}
+
+func TestPSGTone(t *testing.T) {
+	p := NewPSG()
+	p.Period[0] = PeriodOf(2000)
+	p.Attenuation[0] = 0
+	out := make([]float32, 1000)
+	p.Render(out)
+	expect := squareReference(p.Period[0], len(out))
+	for i := range out {
+		if math.Abs(float64(out[i]-expect[i])) > 1e-5 {
+			t.Fatalf("sample %d: %f != %f", i, out[i], expect[i])
+		}
+	}
+}
+
+func TestPSGPitch(t *testing.T) {
+	hz, err := Pitch("A4")
+	if err != nil || hz != 440 {
+		t.Fatalf("A4: %f %v", hz, err)
+	}
+	if period := PeriodOf(hz); period != 254 {
+		t.Errorf("A4 period: %d", period)
+	}
+	if sharp, _ := Pitch("C#4"); sharp != func() float64 { f, _ := Pitch("Db4"); return f }() {
+		t.Errorf("C#4 != Db4")
+	}
+	for _, bad := range []string{"H4", "C", "Cx"} {
+		if _, err := Pitch(bad); err == nil {
+			t.Errorf("%s: expected error", bad)
+		}
+	}
+}
+
+func TestPSGPeriodicNoise(t *testing.T) {
+	p := NewPSG()
+	p.SetNoise(0)
+	var ones []int
+	for shift := 1; shift <= 48; shift++ {
+		// Run the chip until the next shift of the noise register.
+		for {
+			p.tick()
+			if p.output[3] && p.counter[3] == p.noisePeriod() {
+				break
+			}
+		}
+		if p.shift&1 != 0 {
+			ones = append(ones, shift)
+		}
+	}
+	if len(ones) != 3 || ones[1]-ones[0] != 16 || ones[2]-ones[1] != 16 {
+		t.Errorf("periodic noise: %v", ones)
+	}
+}
+
+const testScore = `
+# test
+title Test
+tempo 120
+speed 4
+A4 - - -
+loop
+. . . w0:8
+tempo 60
+- . . .
+`
+
+func TestReadScore(t *testing.T) {
+	s, err := ReadScore(strings.NewReader(testScore))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Title != "Test" || s.Tempo != 120 || s.Speed != 4 || s.Loop != 1 || len(s.Rows) != 3 {
+		t.Fatalf("score: %+v", s)
+	}
+	if n := s.Rows[1].Notes[3]; n.Noise != 4 || n.Volume != 8 {
+		t.Errorf("noise: %+v", n)
+	}
+	if s.Rows[2].Tempo != 60 || !s.Rows[2].Notes[0].Off || !s.Rows[2].Notes[1].Hold {
+		t.Errorf("tempo change: %+v", s.Rows[2])
+	}
+	for _, bad := range []string{"A4 B4 C4 w0 D4", "Q4", "- - - x1", "A4:16", "tempo fast"} {
+		if _, err := ReadScore(strings.NewReader(bad)); err == nil {
+			t.Errorf("%q: expected error", bad)
+		}
+	}
+}
+
+func TestScoreRender(t *testing.T) {
+	s, _ := ReadScore(strings.NewReader(testScore))
+	samples, loop := s.Render()
+	// Two rows at 120 beats of 4 rows and one row at 60.
+	row := SampleRate * 60 / (120 * 4.0)
+	if expect := int(2*row + 2*row); len(samples) != expect {
+		t.Errorf("length: %d != %d", len(samples), expect)
+	}
+	if loop != int(row) {
+		t.Errorf("loop: %d != %d", loop, int(row))
+	}
+	if samples[0] == 0 {
+		t.Errorf("first row is silent")
+	}
+	if last := samples[len(samples)-1]; last == 0 {
+		t.Errorf("noise should still play in the last row")
+	}
+
+	muted, _ := s.Render(0, 3)
+	for i, v := range muted {
+		if v != 0 {
+			t.Fatalf("muted sample %d: %f", i, v)
+		}
+	}
+	// Only the A4 tone plays. It flips every 254 clocks, which is about
+	// 50 samples of 5.07 clocks.
+	tone, _ := s.Render(3)
+	golden := map[int]float32{0: 0.25, 49: 0.25, 50: -0.15, 99: -0.25, 100: 0.15, 1000: -0.25}
+	for i, want := range golden {
+		if tone[i] != want {
+			t.Errorf("tone sample %d: %f != %f", i, tone[i], want)
+		}
+	}
+}
+
+func TestDecodeScore(t *testing.T) {
+	stream, err := decodeScore(strings.NewReader(testScore))
+	if err != nil {
+		t.Fatal(err)
+	}
+	length, _ := stream.Seek(0, io.SeekEnd)
+	stream.Seek(0, io.SeekStart)
+	raw, _ := io.ReadAll(stream)
+	if int64(len(raw)) != length || length%8 != 0 {
+		t.Fatalf("length: %d", length)
+	}
+	samples := floats(raw)
+	if samples[0] != samples[1] {
+		t.Errorf("not stereo: %f %f", samples[0], samples[1])
+	}
+	l, ok := stream.(looper)
+	if !ok || l.LoopStart() != 8*int64(SampleRate*60/(120*4)) {
+		t.Errorf("loop start")
+	}
+}
+
+func TestJingle(t *testing.T) {
+	f, err := os.Open("../pack/music/jingle.psg")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer f.Close()
+	s, err := ReadScore(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Loop < 0 || len(s.Rows) == 0 {
+		t.Errorf("jingle: loop %d, %d rows", s.Loop, len(s.Rows))
+	}
+	if sound := Compose(s); sound.Frames() == 0 {
+		t.Errorf("jingle is empty")
+	}
+}
@@ -0,0 +1,148 @@
+package xgal
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// UIScale is the scale factor of the user interfaces, so they can be
+// read on a small screen that is upscaled. The UI toolkits scale their
+// fonts, the lengths of their styles and their layouts by it. It should
+// be at least 1.
+var UIScale = 1
+
+// UILength returns the length n scaled by [UIScale].
+func UILength(n int) int {
+	return n * max(UIScale, 1)
+}
+
+// UIPoint returns the point scaled by [UIScale].
+func UIPoint(p Point) Point {
+	return p.Mul(max(UIScale, 1))
+}
+
+// UIRect returns the rectangle scaled by [UIScale].
+func UIRect(r Rectangle) Rectangle {
+	return Rectangle{Min: UIPoint(r.Min), Max: UIPoint(r.Max)}
+}
+
+// scaleKey is the key of a scaled face.
+type scaleKey struct {
+	face  Face
+	scale int
+}
+
+// scaledFaces are the faces made by ScaleFace, so they keep their
+// glyph caches.
+var scaledFaces = map[scaleKey]Face{}
+
+// ScaleFace returns the face scaled by a whole factor. Bitmap faces are
+// scaled without smoothing so they stay crisp. Faces of another kind, or
+// a scale of 1 or less, return the face as it is.
+func ScaleFace(face Face, scale int) Face {
+	if face == nil || scale <= 1 {
+		return face
+	}
+	key := scaleKey{face, scale}
+	if scaled, ok := scaledFaces[key]; ok {
+		return scaled
+	}
+	scaled := face
+	switch face := face.(type) {
+	case *text.GoXFace:
+		scaled = text.NewGoXFace(newScaledFace(face.UnsafeInternal(), scale))
+	case *text.GoTextFace:
+		copied := *face
+		copied.Size *= float64(scale)
+		scaled = &copied
+	}
+	scaledFaces[key] = scaled
+	return scaled
+}
+
+// scaledGlyph is a glyph of a scaled face at the origin.
+type scaledGlyph struct {
+	dr      image.Rectangle
+	mask    *image.Alpha
+	advance fixed.Int26_6
+	ok      bool
+}
+
+// scaledFace is a font face that scales the glyphs of another face.
+type scaledFace struct {
+	font.Face
+	scale  int
+	glyphs map[rune]scaledGlyph
+}
+
+var _ font.Face = &scaledFace{}
+
+func newScaledFace(face font.Face, scale int) *scaledFace {
+	return &scaledFace{Face: face, scale: scale, glyphs: map[rune]scaledGlyph{}}
+}
+
+// times returns the fixed point value scaled.
+func (f *scaledFace) times(v fixed.Int26_6) fixed.Int26_6 {
+	return v * fixed.Int26_6(f.scale)
+}
+
+// glyph returns the scaled glyph of the rune, which it scales if it is
+// not cached yet.
+func (f *scaledFace) glyph(r rune) scaledGlyph {
+	if g, ok := f.glyphs[r]; ok {
+		return g
+	}
+	dr, mask, maskp, advance, ok := f.Face.Glyph(fixed.Point26_6{}, r)
+	g := scaledGlyph{advance: f.times(advance), ok: ok}
+	if ok {
+		n := f.scale
+		g.dr = image.Rectangle{Min: dr.Min.Mul(n), Max: dr.Max.Mul(n)}
+		g.mask = image.NewAlpha(image.Rect(0, 0, g.dr.Dx(), g.dr.Dy()))
+		for y := 0; y < g.dr.Dy(); y++ {
+			for x := 0; x < g.dr.Dx(); x++ {
+				_, _, _, a := mask.At(maskp.X+x/n, maskp.Y+y/n).RGBA()
+				g.mask.SetAlpha(x, y, color.Alpha{A: uint8(a >> 8)})
+			}
+		}
+	}
+	f.glyphs[r] = g
+	return g
+}
+
+func (f *scaledFace) Glyph(dot fixed.Point26_6, r rune) (dr image.Rectangle, mask image.Image, maskp image.Point, advance fixed.Int26_6, ok bool) {
+	g := f.glyph(r)
+	if !g.ok {
+		return image.Rectangle{}, nil, image.Point{}, g.advance, false
+	}
+	return g.dr.Add(image.Pt(dot.X.Round(), dot.Y.Round())), g.mask, image.Point{}, g.advance, true
+}
+
+func (f *scaledFace) GlyphBounds(r rune) (bounds fixed.Rectangle26_6, advance fixed.Int26_6, ok bool) {
+	bounds, advance, ok = f.Face.GlyphBounds(r)
+	bounds.Min = fixed.Point26_6{X: f.times(bounds.Min.X), Y: f.times(bounds.Min.Y)}
+	bounds.Max = fixed.Point26_6{X: f.times(bounds.Max.X), Y: f.times(bounds.Max.Y)}
+	return bounds, f.times(advance), ok
+}
+
+func (f *scaledFace) GlyphAdvance(r rune) (advance fixed.Int26_6, ok bool) {
+	advance, ok = f.Face.GlyphAdvance(r)
+	return f.times(advance), ok
+}
+
+func (f *scaledFace) Kern(r0, r1 rune) fixed.Int26_6 {
+	return f.times(f.Face.Kern(r0, r1))
+}
+
+func (f *scaledFace) Metrics() font.Metrics {
+	m := f.Face.Metrics()
+	m.Height = f.times(m.Height)
+	m.Ascent = f.times(m.Ascent)
+	m.Descent = f.times(m.Descent)
+	m.XHeight = f.times(m.XHeight)
+	m.CapHeight = f.times(m.CapHeight)
+	return m
+}
@@ -0,0 +1,52 @@
+package xgal
+
+import (
+	"testing"
+
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+func TestUILength(t *testing.T) {
+	defer func(scale int) { UIScale = scale }(UIScale)
+	UIScale = 0
+	if UILength(5) != 5 {
+		t.Errorf("scale 0 scales 5 to %d", UILength(5))
+	}
+	UIScale = 3
+	if UILength(5) != 15 || UIPoint(Pt(1, 2)) != Pt(3, 6) || UIRect(Rect(1, 1, 2, 2)) != Rect(3, 3, 6, 6) {
+		t.Errorf("scale 3 scales wrong")
+	}
+}
+
+func TestScaledFace(t *testing.T) {
+	face := basicfont.Face7x13
+	scaled := newScaledFace(face, 2)
+
+	if got, want := scaled.Metrics().Height, face.Metrics().Height*2; got != want {
+		t.Errorf("height %v, want %v", got, want)
+	}
+	adv, _ := face.GlyphAdvance('A')
+	if got, _ := scaled.GlyphAdvance('A'); got != adv*2 {
+		t.Errorf("advance %v, want %v", got, adv*2)
+	}
+
+	dot := fixed.P(10, 20)
+	dr, mask, maskp, _, _ := face.Glyph(fixed.Point26_6{}, 'A')
+	sdr, smask, smaskp, sadv, ok := scaled.Glyph(dot, 'A')
+	if !ok || sadv != adv*2 {
+		t.Fatalf("no scaled glyph")
+	}
+	if sdr.Dx() != dr.Dx()*2 || sdr.Dy() != dr.Dy()*2 || sdr.Min != dr.Min.Mul(2).Add(Pt(10, 20)) {
+		t.Fatalf("scaled glyph at %v, glyph at %v", sdr, dr)
+	}
+	for y := 0; y < sdr.Dy(); y++ {
+		for x := 0; x < sdr.Dx(); x++ {
+			_, _, _, want := mask.At(maskp.X+x/2, maskp.Y+y/2).RGBA()
+			_, _, _, got := smask.At(smaskp.X+x, smaskp.Y+y).RGBA()
+			if got>>8 != want>>8 {
+				t.Fatalf("pixel %d,%d is %d, want %d", x, y, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,363 @@
+package xgal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"math/rand/v2"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Wave is the wave shape of a sound effect.
+type Wave int
+
+const (
+	WaveSquare Wave = iota
+	WaveSaw
+	WaveSine
+	WaveTriangle
+	WaveNoise
+)
+
+var waveNames = []string{"square", "saw", "sine", "triangle", "noise"}
+
+func (w Wave) String() string {
+	if w < 0 || int(w) >= len(waveNames) {
+		return "square"
+	}
+	return waveNames[w]
+}
+
+// Sfx are the parameters of a procedural sound effect, in the style of
+// sfxr. Times are in seconds and frequencies in Hz.
+type Sfx struct {
+	Wave         Wave    // Wave shape.
+	Duty         float64 // Duty cycle of the square wave, 0.5 by default.
+	Attack       float64 // Attack time of the envelope.
+	Sustain      float64 // Sustain time of the envelope.
+	Punch        float64 // Punch is the extra volume at the start of the sustain.
+	Decay        float64 // Decay time of the envelope.
+	Freq         float64 // Freq is the start frequency.
+	MinFreq      float64 // MinFreq cuts the sound off when the frequency slides below it.
+	Slide        float64 // Slide of the frequency in octaves per second.
+	DeltaSlide   float64 // DeltaSlide changes the slide in octaves per second squared.
+	VibratoDepth float64 // VibratoDepth as a fraction of the frequency.
+	VibratoSpeed float64 // VibratoSpeed in Hz.
+	Arpeggio     float64 // Arpeggio multiplies the frequency once, 0 for none.
+	ArpeggioTime float64 // ArpeggioTime is when the arpeggio happens.
+	Volume       float64 // Volume from 0 to 1.
+	Seed         uint64  // Seed of the noise.
+}
+
+// NewSfx returns a short beep.
+func NewSfx() Sfx {
+	return Sfx{Duty: 0.5, Sustain: 0.1, Decay: 0.1, Freq: 440, Volume: 0.5}
+}
+
+// Length returns the length of the effect in seconds.
+func (s Sfx) Length() float64 {
+	return max(s.Attack, 0) + max(s.Sustain, 0) + max(s.Decay, 0)
+}
+
+// envelope returns the volume of the envelope at t seconds.
+func (s Sfx) envelope(t float64) float64 {
+	switch {
+	case t < s.Attack:
+		return t / s.Attack
+	case t < s.Attack+s.Sustain:
+		return 1 + s.Punch*(1-(t-s.Attack)/s.Sustain)
+	case s.Decay > 0:
+		return max(1-(t-s.Attack-s.Sustain)/s.Decay, 0)
+	default:
+		return 0
+	}
+}
+
+// noiseSteps is the amount of random values per period of the noise wave.
+const noiseSteps = 32
+
+// Synthesize returns the mono samples of the effect at [SampleRate]. The
+// same parameters always give the same samples.
+func (s Sfx) Synthesize() []float32 {
+	n := int(s.Length() * SampleRate)
+	out := make([]float32, 0, n)
+	rnd := rand.New(rand.NewPCG(s.Seed, s.Seed^0x5eed))
+	var noise [noiseSteps]float64
+	fill := func() {
+		for i := range noise {
+			noise[i] = rnd.Float64()*2 - 1
+		}
+	}
+	fill()
+	duty := s.Duty
+	if duty <= 0 || duty >= 1 {
+		duty = 0.5
+	}
+	dt := 1.0 / SampleRate
+	freq, slide, phase := s.Freq, s.Slide, 0.0
+	arpeggio := s.Arpeggio > 0
+	for i := 0; i < n; i++ {
+		t := float64(i) * dt
+		if arpeggio && t >= s.ArpeggioTime {
+			freq *= s.Arpeggio
+			arpeggio = false
+		}
+		freq *= math.Exp2(slide * dt)
+		slide += s.DeltaSlide * dt
+		if freq < s.MinFreq || freq <= 0 {
+			break
+		}
+		var v float64
+		switch s.Wave {
+		case WaveSaw:
+			v = 2*phase - 1
+		case WaveSine:
+			v = math.Sin(2 * math.Pi * phase)
+		case WaveTriangle:
+			v = 1 - 4*math.Abs(phase-0.5)
+		case WaveNoise:
+			v = noise[int(phase*noiseSteps)%noiseSteps]
+		default:
+			v = -1
+			if phase < duty {
+				v = 1
+			}
+		}
+		v *= s.envelope(t) * s.Volume
+		out = append(out, float32(min(max(v, -1), 1)))
+		phase += freq * (1 + s.VibratoDepth*math.Sin(2*math.Pi*s.VibratoSpeed*t)) * dt
+		if phase >= 1 {
+			phase -= math.Floor(phase)
+			if s.Wave == WaveNoise {
+				fill()
+			}
+		}
+	}
+	return out
+}
+
+// Sound returns the effect as a [Sound] for the [Mixer].
+func (s Sfx) Sound(name string) *Sound {
+	return &Sound{Name: name, Samples: Stereo(s.Synthesize())}
+}
+
+// Clip returns the effect as a [Clip].
+func (s Sfx) Clip() (*Clip, error) {
+	stream := bytes.NewReader(raw(Stereo(s.Synthesize())))
+	player, err := audioContext().NewPlayerF32(stream)
+	if err != nil {
+		return nil, err
+	}
+	return &Clip{player: player}, nil
+}
+
+// fields returns the numeric parameters with their names in file order.
+func (s *Sfx) fields() []struct {
+	name  string
+	value *float64
+} {
+	return []struct {
+		name  string
+		value *float64
+	}{
+		{"duty", &s.Duty},
+		{"attack", &s.Attack},
+		{"sustain", &s.Sustain},
+		{"punch", &s.Punch},
+		{"decay", &s.Decay},
+		{"freq", &s.Freq},
+		{"minfreq", &s.MinFreq},
+		{"slide", &s.Slide},
+		{"deltaslide", &s.DeltaSlide},
+		{"vibratodepth", &s.VibratoDepth},
+		{"vibratospeed", &s.VibratoSpeed},
+		{"arpeggio", &s.Arpeggio},
+		{"arpeggiotime", &s.ArpeggioTime},
+		{"volume", &s.Volume},
+	}
+}
+
+// Write writes the parameters as text, one "name value" per line.
+func (s Sfx) Write(wr io.Writer) error {
+	buf := bufio.NewWriter(wr)
+	fmt.Fprintf(buf, "wave %s\n", s.Wave)
+	fmt.Fprintf(buf, "seed %d\n", s.Seed)
+	for _, f := range s.fields() {
+		fmt.Fprintf(buf, "%s %s\n", f.name, strconv.FormatFloat(*f.value, 'g', -1, 64))
+	}
+	return buf.Flush()
+}
+
+// ReadSfx reads parameters as written by [Sfx.Write]. Missing parameters
+// keep the values of [NewSfx], and lines that start with # are comments.
+func ReadSfx(rd io.Reader) (Sfx, error) {
+	s := NewSfx()
+	fields := s.fields()
+	scan := bufio.NewScanner(rd)
+	for line := 1; scan.Scan(); line++ {
+		text := strings.TrimSpace(scan.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		name, value, _ := strings.Cut(text, " ")
+		value = strings.TrimSpace(value)
+		switch name {
+		case "wave":
+			found := false
+			for i, w := range waveNames {
+				if w == value {
+					s.Wave, found = Wave(i), true
+				}
+			}
+			if !found {
+				return s, fmt.Errorf("line %d: unknown wave %q", line, value)
+			}
+			continue
+		case "seed":
+			seed, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				return s, fmt.Errorf("line %d: %w", line, err)
+			}
+			s.Seed = seed
+			continue
+		}
+		found := false
+		for _, f := range fields {
+			if f.name == name {
+				v, err := strconv.ParseFloat(value, 64)
+				if err != nil {
+					return s, fmt.Errorf("line %d: %w", line, err)
+				}
+				*f.value, found = v, true
+			}
+		}
+		if !found {
+			return s, fmt.Errorf("line %d: unknown parameter %q", line, name)
+		}
+	}
+	return s, scan.Err()
+}
+
+// SaveFile writes the parameters to the named file.
+func (s Sfx) SaveFile(name string) error {
+	out, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	return s.Write(out)
+}
+
+// LoadSfx loads sound effect parameters from fsys.
+func LoadSfx(fsys fs.FS, name string) (Sfx, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return Sfx{}, err
+	}
+	defer f.Close()
+	s, err := ReadSfx(f)
+	if err != nil {
+		return s, fmt.Errorf("%s: %w", name, err)
+	}
+	return s, nil
+}
+
+// decodeSfx synthesizes a sound effect file like the other audio decoders do.
+func decodeSfx(reader io.Reader) (io.ReadSeeker, error) {
+	s, err := ReadSfx(reader)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(raw(Stereo(s.Synthesize()))), nil
+}
+
+// WriteWAV writes interleaved samples with the given amount of channels
+// as a 16 bits PCM WAV file at [SampleRate].
+func WriteWAV(wr io.Writer, samples []float32, channels int) error {
+	size := 2 * len(samples)
+	header := []any{
+		[4]byte{'R', 'I', 'F', 'F'}, uint32(36 + size), [4]byte{'W', 'A', 'V', 'E'},
+		[4]byte{'f', 'm', 't', ' '}, uint32(16), uint16(1), uint16(channels),
+		uint32(SampleRate), uint32(SampleRate * 2 * channels), uint16(2 * channels), uint16(16),
+		[4]byte{'d', 'a', 't', 'a'}, uint32(size),
+	}
+	buf := bufio.NewWriter(wr)
+	for _, h := range header {
+		if err := binary.Write(buf, binary.LittleEndian, h); err != nil {
+			return err
+		}
+	}
+	pcm := make([]int16, len(samples))
+	for i, v := range samples {
+		pcm[i] = int16(math.Round(float64(min(max(v, -1), 1)) * math.MaxInt16))
+	}
+	if err := binary.Write(buf, binary.LittleEndian, pcm); err != nil {
+		return err
+	}
+	return buf.Flush()
+}
+
+// SaveWAV synthesizes the effect and writes it to the named WAV file.
+func (s Sfx) SaveWAV(name string) error {
+	out, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	return WriteWAV(out, s.Synthesize(), 1)
+}
+
+// PresetNames are the names of the presets of [Preset].
+var PresetNames = []string{"coin", "jump", "hit", "powerup"}
+
+// Preset returns a random variation of the named preset. The same seed
+// always gives the same parameters. Returns false if there is no such
+// preset.
+func Preset(name string, seed uint64) (Sfx, bool) {
+	rnd := rand.New(rand.NewPCG(seed, seed^0x5eed))
+	between := func(low, high float64) float64 {
+		return low + rnd.Float64()*(high-low)
+	}
+	s := NewSfx()
+	s.Seed = seed
+	switch name {
+	case "coin":
+		s.Freq = between(800, 1400)
+		s.Sustain = between(0.03, 0.08)
+		s.Punch = between(0.3, 0.6)
+		s.Decay = between(0.1, 0.3)
+		s.Arpeggio = between(1.3, 1.6)
+		s.ArpeggioTime = between(0.03, 0.06)
+	case "jump":
+		s.Duty = between(0.2, 0.5)
+		s.Freq = between(300, 600)
+		s.Slide = between(1, 2.5)
+		s.Sustain = between(0.1, 0.2)
+		s.Decay = between(0.1, 0.2)
+	case "hit":
+		s.Wave = WaveNoise
+		if rnd.IntN(3) == 0 {
+			s.Wave = WaveSaw
+		}
+		s.Freq = between(600, 1000)
+		s.Slide = -between(4, 8)
+		s.Sustain = between(0.02, 0.05)
+		s.Decay = between(0.1, 0.2)
+	case "powerup":
+		s.Freq = between(300, 500)
+		s.Slide = between(1, 2)
+		s.VibratoDepth = between(0.1, 0.3)
+		s.VibratoSpeed = between(10, 20)
+		s.Sustain = between(0.2, 0.4)
+		s.Decay = between(0.2, 0.3)
+	default:
+		return s, false
+	}
+	return s, true
+}
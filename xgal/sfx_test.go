@@ -0,0 +1,137 @@
+package xgal
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestSfxSquare(t *testing.T) {
+	s := Sfx{Duty: 0.5, Sustain: 0.01, Freq: SampleRate / 10.0, Volume: 0.5}
+	samples := s.Synthesize()
+	if len(samples) != SampleRate/100 {
+		t.Fatalf("length: %d", len(samples))
+	}
+	// Ten samples per period: five high and five low.
+	expect := []float32{0.5, 0.5, 0.5, 0.5, 0.5, -0.5, -0.5, -0.5, -0.5, -0.5}
+	for i, v := range expect {
+		if math.Abs(float64(samples[i]-v)) > 1e-6 {
+			t.Fatalf("samples: %v != %v", samples[:10], expect)
+		}
+	}
+}
+
+func TestSfxEnvelope(t *testing.T) {
+	s := Sfx{Attack: 1, Sustain: 1, Punch: 0.5, Decay: 2}
+	cases := []struct{ t, v float64 }{
+		{0, 0}, {0.5, 0.5}, {1, 1.5}, {1.5, 1.25}, {2, 1}, {3, 0.5}, {4, 0}, {5, 0},
+	}
+	for _, c := range cases {
+		if v := s.envelope(c.t); math.Abs(v-c.v) > 1e-9 {
+			t.Errorf("envelope at %f: %f != %f", c.t, v, c.v)
+		}
+	}
+}
+
+func TestSfxMinFreq(t *testing.T) {
+	s := NewSfx()
+	s.Slide = -10
+	s.MinFreq = 220
+	// One octave down at ten octaves per second takes a tenth of a second.
+	if n := len(s.Synthesize()); n < SampleRate/10-2 || n > SampleRate/10+2 {
+		t.Errorf("cut off after %d samples", n)
+	}
+}
+
+func TestSfxDeterministic(t *testing.T) {
+	for _, name := range PresetNames {
+		a, ok := Preset(name, 7)
+		if !ok {
+			t.Fatalf("no preset %s", name)
+		}
+		b, _ := Preset(name, 7)
+		c, _ := Preset(name, 8)
+		if a != b || a == c {
+			t.Errorf("%s: seeds %+v %+v %+v", name, a, b, c)
+		}
+		one, two := a.Synthesize(), b.Synthesize()
+		if len(one) == 0 || len(one) != len(two) {
+			t.Fatalf("%s: lengths %d %d", name, len(one), len(two))
+		}
+		for i := range one {
+			if one[i] != two[i] {
+				t.Fatalf("%s: sample %d differs", name, i)
+			}
+		}
+	}
+	if _, ok := Preset("explosion", 1); ok {
+		t.Errorf("unknown preset")
+	}
+}
+
+func TestSfxText(t *testing.T) {
+	s, _ := Preset("hit", 3)
+	buf := &bytes.Buffer{}
+	if err := s.Write(buf); err != nil {
+		t.Fatal(err)
+	}
+	back, err := ReadSfx(buf)
+	if err != nil || back != s {
+		t.Errorf("round trip: %v\n%+v\n%+v", err, s, back)
+	}
+	for _, bad := range []string{"wave pulse", "seed -1", "freq high", "loudness 1"} {
+		if _, err := ReadSfx(strings.NewReader(bad)); err == nil {
+			t.Errorf("%q: expected error", bad)
+		}
+	}
+}
+
+func TestWriteWAV(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := WriteWAV(buf, []float32{0, 1, -1, 2}, 2); err != nil {
+		t.Fatal(err)
+	}
+	wav := buf.Bytes()
+	if len(wav) != 44+8 || string(wav[:4]) != "RIFF" || string(wav[36:40]) != "data" {
+		t.Fatalf("header: %q", wav[:44])
+	}
+	if channels := binary.LittleEndian.Uint16(wav[22:]); channels != 2 {
+		t.Errorf("channels: %d", channels)
+	}
+	pcm := make([]int16, 4)
+	binary.Read(bytes.NewReader(wav[44:]), binary.LittleEndian, pcm)
+	if pcm[0] != 0 || pcm[1] != math.MaxInt16 || pcm[2] != -math.MaxInt16 || pcm[3] != math.MaxInt16 {
+		t.Errorf("pcm: %v", pcm)
+	}
+
+	if err := WriteWAV(failWriter{}, make([]float32, SampleRate), 1); err != os.ErrClosed {
+		t.Errorf("write to a failing writer: %v", err)
+	}
+}
+
+// failWriter is a writer that always fails.
+type failWriter struct{}
+
+func (failWriter) Write(p []byte) (int, error) {
+	return 0, os.ErrClosed
+}
+
+func TestPackSfx(t *testing.T) {
+	for _, name := range PresetNames {
+		f, err := os.Open("../pack/sfx/" + name + ".sfx")
+		if err != nil {
+			t.Skip(err)
+		}
+		s, err := ReadSfx(f)
+		f.Close()
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+		if len(s.Synthesize()) == 0 {
+			t.Errorf("%s is silent", name)
+		}
+	}
+}
@@ -0,0 +1,493 @@
+package xgal
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/zachomedia/go-bdf"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// SheetGlyph is a glyph of a [SheetFont].
+type SheetGlyph struct {
+	Area    Rectangle // Area of the glyph in the sheet, empty for blank glyphs.
+	Offset  Point     // Offset of the top left of the area from the pen on the baseline.
+	Advance int       // Advance of the pen after the glyph.
+}
+
+// SheetFont is a bitmap font drawn as a sprite sheet, such as a PNG grid
+// of glyphs. Only the alpha of the sheet is used, so the glyphs take the
+// color they are drawn with. It implements [font.Face], so [Font] can load
+// it as a [Face].
+type SheetFont struct {
+	Sheet        Image               // Sheet is the image with the glyphs.
+	SheetName    string              // SheetName is the file of the sheet, relative to the description.
+	Cell         Point               // Cell is the size of the grid cells.
+	Ascent       int                 // Ascent is the distance from the top of a line, or cell, to the baseline.
+	Descent      int                 // Descent is the distance from the baseline to the bottom of a line.
+	Proportional bool                // Proportional trims the glyphs of cells to their ink.
+	Spacing      int                 // Spacing is added after the ink of proportional glyphs.
+	Default      rune                // Default is drawn for runes without glyph, if it has one.
+	Glyphs       map[rune]SheetGlyph // Glyphs by rune.
+	Kerning      map[[2]rune]int     // Kerning adjusts the advance between pairs of runes.
+}
+
+// NewSheetFont returns an empty sheet font for the sheet.
+func NewSheetFont(sheet Image) *SheetFont {
+	return &SheetFont{
+		Sheet:   sheet,
+		Glyphs:  map[rune]SheetGlyph{},
+		Kerning: map[[2]rune]int{},
+	}
+}
+
+// ink returns the bounds of the pixels of the area that are not fully
+// transparent.
+func (f *SheetFont) ink(area Rectangle) Rectangle {
+	ink := Rectangle{}
+	for y := area.Min.Y; y < area.Max.Y; y++ {
+		for x := area.Min.X; x < area.Max.X; x++ {
+			if _, _, _, a := f.Sheet.At(x, y).RGBA(); a > 0 {
+				ink = ink.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+	return ink
+}
+
+// Cells adds the runes as glyphs of consecutive grid cells of the sheet,
+// starting at the cell in column col and row row. Fixed width glyphs use
+// the whole cell, proportional ones are trimmed to their ink, and blank
+// proportional cells advance half a cell.
+func (f *SheetFont) Cells(col, row int, runes string) {
+	at := Pt(col*f.Cell.X, row*f.Cell.Y)
+	for _, r := range runes {
+		cell := Rect(at.X, at.Y, at.X+f.Cell.X, at.Y+f.Cell.Y)
+		glyph := SheetGlyph{Area: cell, Offset: Pt(0, -f.Ascent), Advance: f.Cell.X}
+		if f.Proportional {
+			ink := f.ink(cell)
+			glyph.Area = ink
+			glyph.Offset = Pt(0, ink.Min.Y-cell.Min.Y-f.Ascent)
+			glyph.Advance = ink.Dx() + f.Spacing
+			if ink.Empty() {
+				glyph.Offset = Point{}
+				glyph.Advance = f.Cell.X / 2
+			}
+		}
+		f.Glyphs[r] = glyph
+		at.X += f.Cell.X
+	}
+}
+
+// lookup returns the glyph of r or of the default rune.
+func (f *SheetFont) lookup(r rune) (SheetGlyph, bool) {
+	if g, ok := f.Glyphs[r]; ok {
+		return g, true
+	}
+	g, ok := f.Glyphs[f.Default]
+	return g, ok
+}
+
+// Close implements font.Face.
+func (f *SheetFont) Close() error {
+	return nil
+}
+
+// Glyph implements font.Face.
+func (f *SheetFont) Glyph(dot fixed.Point26_6, r rune) (dr image.Rectangle, mask image.Image, maskp image.Point, advance fixed.Int26_6, ok bool) {
+	g, ok := f.lookup(r)
+	if !ok {
+		return image.Rectangle{}, nil, image.Point{}, 0, false
+	}
+	at := Pt(dot.X.Floor(), dot.Y.Floor()).Add(g.Offset)
+	dr = Rect(at.X, at.Y, at.X+g.Area.Dx(), at.Y+g.Area.Dy())
+	return dr, f.Sheet, g.Area.Min, fixed.I(g.Advance), true
+}
+
+// GlyphBounds implements font.Face.
+func (f *SheetFont) GlyphBounds(r rune) (bounds fixed.Rectangle26_6, advance fixed.Int26_6, ok bool) {
+	g, ok := f.lookup(r)
+	if !ok {
+		return fixed.R(0, -f.Ascent, 0, f.Descent), 0, false
+	}
+	min := g.Offset
+	max := min.Add(g.Area.Size())
+	return fixed.R(min.X, min.Y, max.X, max.Y), fixed.I(g.Advance), true
+}
+
+// GlyphAdvance implements font.Face.
+func (f *SheetFont) GlyphAdvance(r rune) (advance fixed.Int26_6, ok bool) {
+	g, ok := f.lookup(r)
+	return fixed.I(g.Advance), ok
+}
+
+// Kern implements font.Face.
+func (f *SheetFont) Kern(r0, r1 rune) fixed.Int26_6 {
+	return fixed.I(f.Kerning[[2]rune{r0, r1}])
+}
+
+// Metrics implements font.Face.
+func (f *SheetFont) Metrics() font.Metrics {
+	return font.Metrics{
+		Height:  fixed.I(f.Ascent + f.Descent),
+		Ascent:  fixed.I(f.Ascent),
+		Descent: fixed.I(f.Descent),
+	}
+}
+
+// Runes returns the runes the font has glyphs for, sorted.
+func (f *SheetFont) Runes() []rune {
+	runes := make([]rune, 0, len(f.Glyphs))
+	for r := range f.Glyphs {
+		runes = append(runes, r)
+	}
+	slices.Sort(runes)
+	return runes
+}
+
+// sheetRune formats a rune as a single word of a font description.
+func sheetRune(r rune) string {
+	if unicode.IsGraphic(r) && !unicode.IsSpace(r) {
+		return string(r)
+	}
+	return fmt.Sprintf("U+%04X", r)
+}
+
+// parseSheetRune parses a rune formatted by sheetRune.
+func parseSheetRune(word string) (rune, error) {
+	if len(word) > 2 && strings.HasPrefix(word, "U+") {
+		code, err := strconv.ParseUint(word[2:], 16, 32)
+		return rune(code), err
+	}
+	runes := []rune(word)
+	if len(runes) != 1 {
+		return 0, fmt.Errorf("bad rune %q", word)
+	}
+	return runes[0], nil
+}
+
+// Write writes the description of the font as text, with every glyph
+// listed explicitly. The sheet itself is not written.
+func (f *SheetFont) Write(wr io.Writer) error {
+	buf := bufio.NewWriter(wr)
+	fmt.Fprintf(buf, "sheet %s\n", f.SheetName)
+	fmt.Fprintf(buf, "ascent %d\n", f.Ascent)
+	fmt.Fprintf(buf, "descent %d\n", f.Descent)
+	if _, ok := f.Glyphs[f.Default]; ok && f.Default != 0 {
+		fmt.Fprintf(buf, "default %s\n", sheetRune(f.Default))
+	}
+	for _, r := range f.Runes() {
+		g := f.Glyphs[r]
+		fmt.Fprintf(buf, "glyph %s %d %d %d %d %d %d %d\n", sheetRune(r),
+			g.Area.Min.X, g.Area.Min.Y, g.Area.Dx(), g.Area.Dy(), g.Offset.X, g.Offset.Y, g.Advance)
+	}
+	pairs := make([][2]rune, 0, len(f.Kerning))
+	for pair := range f.Kerning {
+		pairs = append(pairs, pair)
+	}
+	slices.SortFunc(pairs, func(a, b [2]rune) int {
+		if a[0] != b[0] {
+			return int(a[0] - b[0])
+		}
+		return int(a[1] - b[1])
+	})
+	for _, pair := range pairs {
+		fmt.Fprintf(buf, "kern %s %s %d\n", sheetRune(pair[0]), sheetRune(pair[1]), f.Kerning[pair])
+	}
+	return buf.Flush()
+}
+
+// sheetNumbers is the amount of numbers of the lines of a font
+// description that are not sheet or cells.
+var sheetNumbers = map[string]int{
+	"cell": 2, "ascent": 1, "descent": 1, "proportional": 1,
+	"glyph": 7, "advance": 1, "kern": 1, "default": 0,
+}
+
+// ReadSheetFont reads a font description, one "name values" per line:
+//
+//	sheet font.png        the sheet, loaded with load
+//	cell 8 16             size of the grid cells
+//	ascent 8              baseline from the top of a cell or line
+//	descent 8             baseline to the bottom of a line
+//	proportional 1        trim cells to their ink, then add this spacing
+//	cells 0 0 AaBbCc      glyphs of consecutive cells from a column and row
+//	glyph A 0 0 8 8 0 -8 8  a glyph: area x y w h, offset x y and advance
+//	advance i 3           override the advance of a glyph
+//	kern A V -1           adjust the advance between a pair
+//	default ?             glyph for runes without glyph
+//
+// Runes are written as themselves or as U+0020 for spaces and invisible
+// runes. Lines apply in order, so the sheet, cell, ascent and proportional
+// lines go before the cells lines they affect. Lines that start with # are
+// comments. If the font has cells but no space, a blank space is added.
+func ReadSheetFont(rd io.Reader, load func(name string) (Image, error)) (*SheetFont, error) {
+	f := NewSheetFont(nil)
+	ascent, descent := false, false
+	scan := bufio.NewScanner(rd)
+	for line := 1; scan.Scan(); line++ {
+		text := strings.TrimSpace(scan.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		name, value, _ := strings.Cut(text, " ")
+		value = strings.TrimSpace(value)
+		if name == "sheet" {
+			img, err := load(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			f.Sheet, f.SheetName = img, value
+			continue
+		}
+		if name == "cells" {
+			// The runes may contain spaces, so only cut off the numbers.
+			col, rest, _ := strings.Cut(value, " ")
+			row, runes, _ := strings.Cut(rest, " ")
+			c, err1 := strconv.Atoi(col)
+			r, err2 := strconv.Atoi(row)
+			if err := errors.Join(err1, err2); err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			if f.Sheet == nil || f.Cell.X <= 0 || f.Cell.Y <= 0 {
+				return nil, fmt.Errorf("line %d: cells need a sheet and a cell size first", line)
+			}
+			f.Cells(c, r, runes)
+			continue
+		}
+
+		count, ok := sheetNumbers[name]
+		if !ok {
+			return nil, fmt.Errorf("line %d: unknown line %q", line, name)
+		}
+		words := strings.Fields(value)
+		runes := []rune{}
+		ints := []int{}
+		for _, word := range words {
+			if len(runes) == 0 && (name == "glyph" || name == "advance" || name == "default") ||
+				len(runes) < 2 && name == "kern" {
+				r, err := parseSheetRune(word)
+				if err != nil {
+					return nil, fmt.Errorf("line %d: %w", line, err)
+				}
+				runes = append(runes, r)
+				continue
+			}
+			i, err := strconv.Atoi(word)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			ints = append(ints, i)
+		}
+		if len(ints) != count {
+			return nil, fmt.Errorf("line %d: %s needs %d numbers", line, name, count)
+		}
+		switch name {
+		case "cell":
+			f.Cell = Pt(ints[0], ints[1])
+			if !ascent {
+				f.Ascent = f.Cell.Y
+			}
+		case "ascent":
+			f.Ascent, ascent = ints[0], true
+		case "descent":
+			f.Descent, descent = ints[0], true
+		case "proportional":
+			f.Proportional, f.Spacing = true, ints[0]
+		case "glyph":
+			f.Glyphs[runes[0]] = SheetGlyph{
+				Area:    Rect(ints[0], ints[1], ints[0]+ints[2], ints[1]+ints[3]),
+				Offset:  Pt(ints[4], ints[5]),
+				Advance: ints[6],
+			}
+		case "advance":
+			g, ok := f.Glyphs[runes[0]]
+			if !ok {
+				return nil, fmt.Errorf("line %d: no glyph %q", line, runes[0])
+			}
+			g.Advance = ints[0]
+			f.Glyphs[runes[0]] = g
+		case "kern":
+			f.Kerning[[2]rune{runes[0], runes[1]}] = ints[0]
+		case "default":
+			f.Default = runes[0]
+		}
+	}
+	if err := scan.Err(); err != nil {
+		return nil, err
+	}
+	if f.Sheet == nil {
+		return nil, errors.New("no sheet")
+	}
+	if !descent && f.Cell.Y > f.Ascent {
+		f.Descent = f.Cell.Y - f.Ascent
+	}
+	if _, ok := f.Glyphs[' ']; !ok && f.Cell.X > 0 {
+		space := SheetGlyph{Advance: f.Cell.X}
+		if f.Proportional {
+			space.Advance = f.Cell.X / 2
+		}
+		f.Glyphs[' '] = space
+	}
+	return f, nil
+}
+
+// LoadSheetFont loads a font description from fsys, together with its
+// sheet from the same directory.
+func LoadSheetFont(fsys fs.FS, name string) (*SheetFont, error) {
+	fin, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer fin.Close()
+	f, err := ReadSheetFont(fin, func(sheet string) (Image, error) {
+		return Pixels(fsys, path.Join(path.Dir(name), sheet))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return f, nil
+}
+
+// SaveFile writes the description to the named file, and the sheet as a
+// PNG next to it.
+func (f *SheetFont) SaveFile(name string) error {
+	if f.SheetName == "" {
+		f.SheetName = strings.TrimSuffix(filepath.Base(name), filepath.Ext(name)) + ".png"
+	}
+	sheet, err := os.Create(filepath.Join(filepath.Dir(name), f.SheetName))
+	if err != nil {
+		return err
+	}
+	defer sheet.Close()
+	if err := png.Encode(sheet, f.Sheet); err != nil {
+		return err
+	}
+	out, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	return f.Write(out)
+}
+
+// SheetFromBDF converts a BDF font to a sheet font. The glyphs are drawn
+// in black in a grid of the given amount of columns, in the order of the
+// runes.
+func SheetFromBDF(buf []byte, columns int) (*SheetFont, error) {
+	parsed, err := bdf.Parse(buf)
+	if err != nil {
+		return nil, err
+	}
+	columns = max(columns, 1)
+	chars := slices.Clone(parsed.Characters)
+	slices.SortFunc(chars, func(a, b bdf.Character) int { return int(a.Encoding - b.Encoding) })
+	cell := Point{}
+	for _, c := range chars {
+		if c.Alpha != nil {
+			cell.X = max(cell.X, c.Alpha.Rect.Dx())
+			cell.Y = max(cell.Y, c.Alpha.Rect.Dy())
+		}
+	}
+	rows := (len(chars) + columns - 1) / columns
+	sheet := image.NewNRGBA(image.Rect(0, 0, max(columns*cell.X, 1), max(rows*cell.Y, 1)))
+	f := NewSheetFont(sheet)
+	f.Cell = cell
+	f.Ascent, f.Descent = parsed.Ascent, parsed.Descent
+	for i, c := range chars {
+		glyph := SheetGlyph{Advance: c.Advance[0]}
+		if c.Alpha != nil && !c.Alpha.Rect.Empty() {
+			at := Pt(i%columns*cell.X, i/columns*cell.Y)
+			size := c.Alpha.Rect.Size()
+			glyph.Area = Rect(at.X, at.Y, at.X+size.X, at.Y+size.Y)
+			glyph.Offset = Pt(c.LowerPoint[0], -c.LowerPoint[1]-size.Y)
+			for y := 0; y < size.Y; y++ {
+				for x := 0; x < size.X; x++ {
+					a := c.Alpha.AlphaAt(x, y).A
+					sheet.SetNRGBA(at.X+x, at.Y+y, color.NRGBA{A: a})
+				}
+			}
+		}
+		f.Glyphs[c.Encoding] = glyph
+	}
+	if _, ok := f.Glyphs[parsed.DefaultChar]; ok {
+		f.Default = parsed.DefaultChar
+	}
+	return f, nil
+}
+
+// WriteBDF writes the font as a BDF font with the given name. Pixels are
+// set where the sheet is at least half opaque. BDF has no kerning, so the
+// kerning pairs are lost.
+func (f *SheetFont) WriteBDF(wr io.Writer, name string) error {
+	bounds := Rectangle{}
+	for _, g := range f.Glyphs {
+		if !g.Area.Empty() {
+			bounds = bounds.Union(Rect(g.Offset.X, g.Offset.Y, g.Offset.X+g.Area.Dx(), g.Offset.Y+g.Area.Dy()))
+		}
+	}
+	size := f.Ascent + f.Descent
+	buf := bufio.NewWriter(wr)
+	fmt.Fprintf(buf, "STARTFONT 2.1\n")
+	fmt.Fprintf(buf, "FONT %s\n", name)
+	fmt.Fprintf(buf, "SIZE %d 75 75\n", size)
+	fmt.Fprintf(buf, "FONTBOUNDINGBOX %d %d %d %d\n", bounds.Dx(), bounds.Dy(), bounds.Min.X, -bounds.Max.Y)
+	properties := []string{
+		fmt.Sprintf("FONT_ASCENT %d", f.Ascent),
+		fmt.Sprintf("FONT_DESCENT %d", f.Descent),
+		`CHARSET_REGISTRY "ISO10646"`,
+		`CHARSET_ENCODING "1"`,
+	}
+	if _, ok := f.Glyphs[f.Default]; ok && f.Default != 0 {
+		properties = append(properties, fmt.Sprintf("DEFAULT_CHAR %d", f.Default))
+	}
+	fmt.Fprintf(buf, "STARTPROPERTIES %d\n", len(properties))
+	for _, p := range properties {
+		fmt.Fprintln(buf, p)
+	}
+	fmt.Fprintf(buf, "ENDPROPERTIES\n")
+	runes := f.Runes()
+	fmt.Fprintf(buf, "CHARS %d\n", len(runes))
+	for _, r := range runes {
+		g := f.Glyphs[r]
+		w, h := g.Area.Dx(), g.Area.Dy()
+		fmt.Fprintf(buf, "STARTCHAR U+%04X\n", r)
+		fmt.Fprintf(buf, "ENCODING %d\n", r)
+		fmt.Fprintf(buf, "SWIDTH %d 0\n", g.Advance*1000/max(size, 1))
+		fmt.Fprintf(buf, "DWIDTH %d 0\n", g.Advance)
+		if g.Area.Empty() {
+			fmt.Fprintf(buf, "BBX 0 0 0 0\nBITMAP\nENDCHAR\n")
+			continue
+		}
+		fmt.Fprintf(buf, "BBX %d %d %d %d\n", w, h, g.Offset.X, -g.Offset.Y-h)
+		fmt.Fprintf(buf, "BITMAP\n")
+		row := make([]byte, (w+7)/8)
+		for y := g.Area.Min.Y; y < g.Area.Max.Y; y++ {
+			clear(row)
+			for x := g.Area.Min.X; x < g.Area.Max.X; x++ {
+				if _, _, _, a := f.Sheet.At(x, y).RGBA(); a >= 0x8000 {
+					i := x - g.Area.Min.X
+					row[i/8] |= 0x80 >> (i % 8)
+				}
+			}
+			fmt.Fprintf(buf, "%X\n", row)
+		}
+		fmt.Fprintf(buf, "ENDCHAR\n")
+	}
+	fmt.Fprintf(buf, "ENDFONT\n")
+	return buf.Flush()
+}
@@ -0,0 +1,178 @@
+package xgal
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"os"
+	"strings"
+	"testing"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// testSheet returns a sheet of 4×6 cells with a full block in the first
+// cell, a bar of one column in the second and an empty third cell.
+func testSheet() Image {
+	sheet := image.NewNRGBA(image.Rect(0, 0, 12, 6))
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			sheet.SetNRGBA(x, y, color.NRGBA{A: 255})
+		}
+		sheet.SetNRGBA(5, y+1, color.NRGBA{A: 255})
+	}
+	return sheet
+}
+
+func readTestSheet(t *testing.T, desc string) *SheetFont {
+	t.Helper()
+	f, err := ReadSheetFont(strings.NewReader(desc), func(name string) (Image, error) {
+		return testSheet(), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func TestSheetFontCells(t *testing.T) {
+	f := readTestSheet(t, "sheet test.png\ncell 4 6\nascent 5\ncells 0 0 #|-\nkern # | -1\ndefault -\n")
+	if f.Ascent != 5 || f.Descent != 1 {
+		t.Errorf("metrics: %d %d", f.Ascent, f.Descent)
+	}
+	bar := f.Glyphs['|']
+	if bar.Area != image.Rect(4, 0, 8, 6) || bar.Offset != Pt(0, -5) || bar.Advance != 4 {
+		t.Errorf("fixed bar: %+v", bar)
+	}
+	if f.Glyphs[' '].Advance != 4 {
+		t.Errorf("space: %+v", f.Glyphs[' '])
+	}
+	if got := font.MeasureString(f, "#|"); got != fixed.I(7) {
+		t.Errorf("kerned width: %v", got)
+	}
+	if adv, ok := f.GlyphAdvance('x'); !ok || adv != fixed.I(4) {
+		t.Errorf("default: %v %v", adv, ok)
+	}
+
+	f = readTestSheet(t, "sheet test.png\ncell 4 6\nascent 5\nproportional 1\ncells 0 0 #|-\nadvance # 6\n")
+	bar = f.Glyphs['|']
+	if bar.Area != image.Rect(5, 1, 6, 5) || bar.Offset != Pt(0, -4) || bar.Advance != 2 {
+		t.Errorf("proportional bar: %+v", bar)
+	}
+	if f.Glyphs['#'].Advance != 6 || f.Glyphs['-'].Advance != 2 || f.Glyphs[' '].Advance != 2 {
+		t.Errorf("advances: %+v", f.Glyphs)
+	}
+	if _, ok := f.GlyphAdvance('x'); ok {
+		t.Errorf("no default, so no glyph")
+	}
+}
+
+func TestSheetFontDraw(t *testing.T) {
+	f := readTestSheet(t, "sheet test.png\ncell 4 6\nascent 5\nproportional 1\ncells 0 0 #|\n")
+	dst := image.NewAlpha(image.Rect(0, 0, 10, 8))
+	d := font.Drawer{Dst: dst, Src: image.Opaque, Face: f, Dot: fixed.P(1, 6)}
+	d.DrawString("#|")
+	var rows []string
+	for y := 0; y < 8; y++ {
+		row := ""
+		for x := 0; x < 10; x++ {
+			if dst.AlphaAt(x, y).A > 0 {
+				row += "#"
+			} else {
+				row += "."
+			}
+		}
+		rows = append(rows, row)
+	}
+	want := []string{
+		"..........",
+		".####.....",
+		".####.#...",
+		".####.#...",
+		".####.#...",
+		"......#...",
+		"..........",
+		"..........",
+	}
+	if strings.Join(rows, "\n") != strings.Join(want, "\n") {
+		t.Errorf("drawn:\n%s", strings.Join(rows, "\n"))
+	}
+}
+
+func TestSheetFontRoundTrip(t *testing.T) {
+	f := readTestSheet(t, "sheet test.png\ncell 4 6\nascent 5\nproportional 1\ncells 0 0 #|\nkern | U+0020 2\n")
+	buf := &bytes.Buffer{}
+	if err := f.Write(buf); err != nil {
+		t.Fatal(err)
+	}
+	back := readTestSheet(t, buf.String())
+	for r, g := range f.Glyphs {
+		if back.Glyphs[r] != g {
+			t.Errorf("%q: %+v, want %+v", r, back.Glyphs[r], g)
+		}
+	}
+	if len(back.Glyphs) != len(f.Glyphs) || back.Kerning[[2]rune{'|', ' '}] != 2 {
+		t.Errorf("round trip:\n%s", buf.String())
+	}
+}
+
+func TestSheetFontBDF(t *testing.T) {
+	f := readTestSheet(t, "sheet test.png\ncell 4 6\nascent 5\nproportional 1\ncells 0 0 #|\n")
+	buf := &bytes.Buffer{}
+	if err := f.WriteBDF(buf, "test"); err != nil {
+		t.Fatal(err)
+	}
+	back, err := SheetFromBDF(buf.Bytes(), 4)
+	if err != nil {
+		t.Fatalf("%v\n%s", err, buf.String())
+	}
+	if back.Ascent != 5 || back.Descent != 1 || len(back.Glyphs) != len(f.Glyphs) {
+		t.Fatalf("font: %d %d %+v", back.Ascent, back.Descent, back.Glyphs)
+	}
+	for r, g := range f.Glyphs {
+		b := back.Glyphs[r]
+		if b.Offset != g.Offset || b.Advance != g.Advance || b.Area.Size() != g.Area.Size() {
+			t.Errorf("%q: %+v, want %+v", r, b, g)
+		}
+		for y := 0; y < g.Area.Dy(); y++ {
+			for x := 0; x < g.Area.Dx(); x++ {
+				_, _, _, want := f.Sheet.At(g.Area.Min.X+x, g.Area.Min.Y+y).RGBA()
+				_, _, _, got := back.Sheet.At(b.Area.Min.X+x, b.Area.Min.Y+y).RGBA()
+				if got != want {
+					t.Fatalf("%q pixel %d,%d: %d, want %d", r, x, y, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestSheetFontErrors(t *testing.T) {
+	load := func(string) (Image, error) { return testSheet(), nil }
+	for _, bad := range []string{
+		"cell 4 6\n", "sheet a.png\ncells 0 0 ab\n", "sheet a.png\nglyph ab 0 0 1 1 0 0 1\n",
+		"sheet a.png\nglyph a 0 0 1\n", "sheet a.png\nblink 1\n", "sheet a.png\nadvance x 2\n",
+	} {
+		if _, err := ReadSheetFont(strings.NewReader(bad), load); err == nil {
+			t.Errorf("%q: expected error", bad)
+		}
+	}
+}
+
+func TestPackSheetFont(t *testing.T) {
+	if _, err := os.Stat("../pack/image/gfx/font.font"); err != nil {
+		t.Skip(err)
+	}
+	f, err := LoadSheetFont(os.DirFS(".."), "pack/image/gfx/font.font")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range "AaZz09.!♥ " {
+		if _, ok := f.Glyphs[r]; !ok {
+			t.Errorf("no glyph for %q", r)
+		}
+	}
+	if f.Glyphs['g'].Offset.Y+f.Glyphs['g'].Area.Dy() <= 0 {
+		t.Errorf("g should descend below the baseline: %+v", f.Glyphs['g'])
+	}
+}
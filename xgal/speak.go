@@ -0,0 +1,106 @@
+package xgal
+
+import "strings"
+
+// Cue is what an [Announcement] is about.
+type Cue int
+
+const (
+	CueFocus Cue = iota // CueFocus: a control got the focus.
+	CueValue            // CueValue: the value of the focused control changed.
+	CueLine             // CueLine: a line of dialogue is shown.
+)
+
+// String returns the name of the cue.
+func (c Cue) String() string {
+	switch c {
+	case CueFocus:
+		return "focus"
+	case CueValue:
+		return "value"
+	case CueLine:
+		return "line"
+	}
+	return "cue"
+}
+
+// Announcement is an event of the accessibility stream, which tells a
+// [Speaker] what happens in the user interface.
+type Announcement struct {
+	Cue   Cue
+	Label string // Label of the control, or who speaks a line.
+	Value string // Value of the control, or the line that is spoken.
+}
+
+// Text returns the announcement as it is spoken, the label and the value
+// that are set, separated by a colon.
+func (a Announcement) Text() string {
+	if a.Label == "" || a.Value == "" {
+		return a.Label + a.Value
+	}
+	return a.Label + ": " + a.Value
+}
+
+// Speaker consumes the accessibility stream, for example to read it out
+// with a text to speech engine, or to show it in large print.
+type Speaker interface {
+	// Speak is called for every announcement, in order.
+	Speak(a Announcement)
+}
+
+// SpeakerFunc is a function that is a [Speaker].
+type SpeakerFunc func(a Announcement)
+
+func (f SpeakerFunc) Speak(a Announcement) {
+	f(a)
+}
+
+// speaker is the speaker of the announcements, if any.
+var speaker Speaker
+
+// SetSpeaker makes the speaker consume the announcements, or none if it
+// is nil, and returns the speaker it had before.
+func SetSpeaker(s Speaker) Speaker {
+	old := speaker
+	speaker = s
+	return old
+}
+
+// Announce passes the announcement to the speaker, if there is one. Labels
+// and values are trimmed, and empty announcements are dropped.
+func Announce(a Announcement) {
+	if speaker == nil {
+		return
+	}
+	a.Label, a.Value = strings.TrimSpace(a.Label), strings.TrimSpace(a.Value)
+	if a.Label == "" && a.Value == "" {
+		return
+	}
+	speaker.Speak(a)
+}
+
+// Transcript is a speaker that records the announcements, for example to
+// check what a user interface announces in its tests.
+type Transcript []Announcement
+
+func (t *Transcript) Speak(a Announcement) {
+	*t = append(*t, a)
+}
+
+// Texts returns the recorded announcements as their cue and their text.
+func (t Transcript) Texts() []string {
+	texts := []string{}
+	for _, a := range t {
+		texts = append(texts, a.Cue.String()+" "+a.Text())
+	}
+	return texts
+}
+
+// Listen makes a new transcript the speaker until the cleanups of c run,
+// such as those of a test, and returns it.
+func Listen(c interface{ Cleanup(func()) }) *Transcript {
+	heard := &Transcript{}
+	old := SetSpeaker(heard)
+	c.Cleanup(func() { SetSpeaker(old) })
+	return heard
+}
@@ -0,0 +1,33 @@
+package xgal
+
+import "testing"
+
+func TestAnnounce(t *testing.T) {
+	Announce(Announcement{Label: "nobody listens"})
+
+	heard := Listen(t)
+
+	Announce(Announcement{Cue: CueFocus, Label: " Name ", Value: "Santa\n"})
+	Announce(Announcement{Cue: CueValue, Label: "  "})
+	Announce(Announcement{Cue: CueLine, Value: "Ho ho ho!"})
+	if len(*heard) != 2 {
+		t.Fatalf("heard %v", *heard)
+	}
+	if got := (*heard)[0]; got.Cue != CueFocus || got.Text() != "Name: Santa" {
+		t.Errorf("focus %v %q", got.Cue, got.Text())
+	}
+	if got := (*heard)[1]; got.Cue.String() != "line" || got.Text() != "Ho ho ho!" {
+		t.Errorf("line %v %q", got.Cue, got.Text())
+	}
+
+	if got := heard.Texts(); len(got) != 2 || got[1] != "line Ho ho ho!" {
+		t.Errorf("texts %q", got)
+	}
+
+	said := ""
+	SetSpeaker(SpeakerFunc(func(a Announcement) { said = a.Text() }))
+	Announce(Announcement{Label: "OK"})
+	if said != "OK" {
+		t.Errorf("speaker func said %q", said)
+	}
+}
@@ -18,7 +18,8 @@ type Face = text.Face
 var BuiltinFace Face = text.NewGoXFace(bitmapfont.Face)
 
 // Font loads a font file from fsys as a [Face].
-// Supported formats: BDF (.bdf), TrueType (.ttf), OpenType (.otf).
+// Supported formats: BDF (.bdf), TrueType (.ttf), OpenType (.otf) and
+// sprite sheet fonts (.font), see [ReadSheetFont].
 // A point size may be provided for TTF/OTF fonts by passing it as an optional
 // argument; the default is 12. BDF fonts ignore the size.
 func Font(fsys fs.FS, name string, size ...float64) (Face, error) {
@@ -27,6 +28,14 @@ func Font(fsys fs.FS, name string, size ...float64) (Face, error) {
 		pt = size[0]
 	}
 
+	if strings.ToLower(filepath.Ext(name)) == ".font" {
+		sheet, err := LoadSheetFont(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		return text.NewGoXFace(sheet), nil
+	}
+
 	buf, err := fs.ReadFile(fsys, name)
 	if err != nil {
 		return nil, err
@@ -137,3 +146,17 @@ func Stride(face Face) int {
 	m := face.Metrics()
 	return int(m.HAscent + m.HDescent + m.HLineGap)
 }
+
+// Charset returns the runes that a BDF font has glyphs for, for example
+// to check that a font covers the text of a language.
+func Charset(buf []byte) (map[rune]bool, error) {
+	parsed, err := bdf.Parse(buf)
+	if err != nil {
+		return nil, err
+	}
+	runes := make(map[rune]bool, len(parsed.Characters))
+	for _, c := range parsed.Characters {
+		runes[c.Encoding] = true
+	}
+	return runes, nil
+}
@@ -0,0 +1,369 @@
+package xgal
+
+import (
+	"math"
+	"reflect"
+	"slices"
+)
+
+// Ease maps the progress t of a [Tween] from 0 to 1 to the eased
+// progress, which starts at 0 and ends at 1 but may overshoot in between.
+type Ease func(t float64) float64
+
+// The easing functions. In eases start slow, out eases end slow, and in
+// out eases do both.
+var (
+	EaseLinear    Ease = func(t float64) float64 { return t }
+	EaseInQuad    Ease = func(t float64) float64 { return t * t }
+	EaseOutQuad   Ease = func(t float64) float64 { return t * (2 - t) }
+	EaseInOutQuad Ease = func(t float64) float64 {
+		if t < 0.5 {
+			return 2 * t * t
+		}
+		return 1 - 2*(1-t)*(1-t)
+	}
+	EaseInCubic    Ease = func(t float64) float64 { return t * t * t }
+	EaseOutCubic   Ease = func(t float64) float64 { return 1 - (1-t)*(1-t)*(1-t) }
+	EaseInOutCubic Ease = func(t float64) float64 {
+		if t < 0.5 {
+			return 4 * t * t * t
+		}
+		return 1 - 4*(1-t)*(1-t)*(1-t)
+	}
+	EaseInSine  Ease = func(t float64) float64 { return 1 - math.Cos(t*math.Pi/2) }
+	EaseOutSine Ease = func(t float64) float64 { return math.Sin(t * math.Pi / 2) }
+	// EaseOutBack overshoots the end a little and comes back.
+	EaseOutBack Ease = func(t float64) float64 {
+		const c = 1.70158
+		u := t - 1
+		return 1 + (c+1)*u*u*u + c*u*u
+	}
+	// EaseOutBounce bounces on the end like a dropped ball.
+	EaseOutBounce Ease = func(t float64) float64 {
+		const n, d = 7.5625, 2.75
+		switch {
+		case t < 1/d:
+			return n * t * t
+		case t < 2/d:
+			t -= 1.5 / d
+			return n*t*t + 0.75
+		case t < 2.5/d:
+			t -= 2.25 / d
+			return n*t*t + 0.9375
+		default:
+			t -= 2.625 / d
+			return n*t*t + 0.984375
+		}
+	}
+	// EaseOutElastic springs past the end a few times.
+	EaseOutElastic Ease = func(t float64) float64 {
+		if t <= 0 || t >= 1 {
+			return t
+		}
+		return math.Pow(2, -10*t)*math.Sin((t*10-0.75)*2*math.Pi/3) + 1
+	}
+)
+
+// Eases are the easing functions by name, such as for themes and data
+// files.
+var Eases = map[string]Ease{
+	"linear":       EaseLinear,
+	"in-quad":      EaseInQuad,
+	"out-quad":     EaseOutQuad,
+	"in-out-quad":  EaseInOutQuad,
+	"in-cubic":     EaseInCubic,
+	"out-cubic":    EaseOutCubic,
+	"in-out-cubic": EaseInOutCubic,
+	"in-sine":      EaseInSine,
+	"out-sine":     EaseOutSine,
+	"out-back":     EaseOutBack,
+	"out-bounce":   EaseOutBounce,
+	"out-elastic":  EaseOutElastic,
+}
+
+// Animation is anything that is animated tick by tick. Animations are
+// deterministic: they only advance when stepped, not with the clock.
+type Animation interface {
+	// Step advances the animation by one tick and reports whether it is
+	// done. Stepping a done animation does nothing and reports true.
+	Step() bool
+	// Reset returns the animation to its start so it can be played again.
+	Reset()
+}
+
+// Tween is an [Animation] of a value from From to To in Ticks ticks,
+// with an easing. On every step the value is passed to Set.
+type Tween struct {
+	From  float64
+	To    float64
+	Ticks int
+	Ease  Ease // Ease is the easing, linear if nil.
+	Set   func(v float64)
+	tick  int
+	done  bool
+}
+
+// NewTween returns a new [Tween].
+func NewTween(from, to float64, ticks int, ease Ease, set func(v float64)) *Tween {
+	return &Tween{From: from, To: to, Ticks: ticks, Ease: ease, Set: set}
+}
+
+// Progress returns the eased progress of the tween, from 0 to 1.
+func (t *Tween) Progress() float64 {
+	if t.Ticks <= 0 || t.tick >= t.Ticks {
+		return 1
+	}
+	p := float64(t.tick) / float64(t.Ticks)
+	if t.Ease == nil {
+		return p
+	}
+	return t.Ease(p)
+}
+
+// Value returns the current value of the tween.
+func (t *Tween) Value() float64 {
+	return Lerp(t.From, t.To, t.Progress())
+}
+
+// Done reports whether the tween reached its end.
+func (t *Tween) Done() bool {
+	return t.done
+}
+
+func (t *Tween) Step() bool {
+	if t.done {
+		return true
+	}
+	if t.tick < t.Ticks {
+		t.tick++
+	}
+	t.done = t.tick >= t.Ticks
+	if t.Set != nil {
+		t.Set(t.Value())
+	}
+	return t.done
+}
+
+func (t *Tween) Reset() {
+	t.tick, t.done = 0, false
+}
+
+// Lerp returns the value a fraction t of the way from a to b.
+func Lerp(a, b, t float64) float64 {
+	return a + (b-a)*t
+}
+
+// LerpPoint returns the point a fraction t of the way from a to b.
+func LerpPoint(a, b Point, t float64) Point {
+	return Pt(int(math.Round(Lerp(float64(a.X), float64(b.X), t))), int(math.Round(Lerp(float64(a.Y), float64(b.Y), t))))
+}
+
+// LerpRect returns the rectangle a fraction t of the way from a to b.
+func LerpRect(a, b Rectangle, t float64) Rectangle {
+	return Rectangle{Min: LerpPoint(a.Min, b.Min, t), Max: LerpPoint(a.Max, b.Max, t)}
+}
+
+// LerpColor returns the colour a fraction t of the way from a to b.
+func LerpColor(a, b RGBA, t float64) RGBA {
+	c := func(a, b uint8) uint8 {
+		return uint8(min(max(math.Round(Lerp(float64(a), float64(b), t)), 0), 255))
+	}
+	return RGBA{R: c(a.R, b.R), G: c(a.G, b.G), B: c(a.B, b.B), A: c(a.A, b.A)}
+}
+
+// TweenPoint returns a tween of a point, such as a position.
+func TweenPoint(from, to Point, ticks int, ease Ease, set func(p Point)) *Tween {
+	return NewTween(0, 1, ticks, ease, func(t float64) { set(LerpPoint(from, to, t)) })
+}
+
+// TweenRect returns a tween of a rectangle, such as the bounds of a
+// widget that grows.
+func TweenRect(from, to Rectangle, ticks int, ease Ease, set func(r Rectangle)) *Tween {
+	return NewTween(0, 1, ticks, ease, func(t float64) { set(LerpRect(from, to, t)) })
+}
+
+// TweenColor returns a tween of a colour.
+func TweenColor(from, to RGBA, ticks int, ease Ease, set func(c RGBA)) *Tween {
+	return NewTween(0, 1, ticks, ease, func(t float64) { set(LerpColor(from, to, t)) })
+}
+
+// Wait is an [Animation] that does nothing for a number of ticks, to
+// delay the next step of a [Sequence].
+type Wait struct {
+	Ticks int
+	tick  int
+}
+
+// NewWait returns a new [Wait].
+func NewWait(ticks int) *Wait {
+	return &Wait{Ticks: ticks}
+}
+
+func (w *Wait) Step() bool {
+	if w.tick < w.Ticks {
+		w.tick++
+	}
+	return w.tick >= w.Ticks
+}
+
+func (w *Wait) Reset() {
+	w.tick = 0
+}
+
+// Call is an [Animation] that calls the function once and is done, such
+// as to close a widget at the end of a [Sequence].
+type Call func()
+
+func (c Call) Step() bool {
+	if c != nil {
+		c()
+	}
+	return true
+}
+
+func (c Call) Reset() {}
+
+// Sequence is an [Animation] that plays its steps one after the other.
+// Calls and waits of no ticks do not take a tick of their own, they are
+// played in the same tick as the step before or after them.
+type Sequence struct {
+	Steps []Animation
+	at    int
+}
+
+// NewSequence returns a new [Sequence] of the steps.
+func NewSequence(steps ...Animation) *Sequence {
+	return &Sequence{Steps: steps}
+}
+
+func (s *Sequence) Step() bool {
+	for s.at < len(s.Steps) {
+		step := s.Steps[s.at]
+		if !step.Step() {
+			return false
+		}
+		s.at++
+		if s.at < len(s.Steps) && !instant(step) && !instant(s.Steps[s.at]) {
+			return false
+		}
+	}
+	return true
+}
+
+// instant reports whether the animation is done without taking a tick.
+func instant(a Animation) bool {
+	switch a := a.(type) {
+	case Call:
+		return true
+	case *Wait:
+		return a.Ticks <= 0
+	}
+	return false
+}
+
+func (s *Sequence) Reset() {
+	s.at = 0
+	for _, step := range s.Steps {
+		step.Reset()
+	}
+}
+
+// Parallel is an [Animation] that plays its parts together, and is done
+// when all of them are.
+type Parallel struct {
+	Parts []Animation
+	done  []bool
+}
+
+// NewParallel returns a new [Parallel] of the parts.
+func NewParallel(parts ...Animation) *Parallel {
+	return &Parallel{Parts: parts}
+}
+
+func (p *Parallel) Step() bool {
+	if len(p.done) != len(p.Parts) {
+		p.done = make([]bool, len(p.Parts))
+	}
+	all := true
+	for i, part := range p.Parts {
+		if !p.done[i] {
+			p.done[i] = part.Step()
+		}
+		all = all && p.done[i]
+	}
+	return all
+}
+
+func (p *Parallel) Reset() {
+	p.done = nil
+	for _, part := range p.Parts {
+		part.Reset()
+	}
+}
+
+// Timeline plays animations, stepping all of them on every Tick until
+// they are done. It is the timeline of a UI.
+type Timeline struct {
+	Playing []Animation
+
+	stepping []Animation // stepping are the animations that Tick steps.
+	stopped  []bool      // stopped marks the stepping animations that were stopped.
+}
+
+// Play starts playing the animation, and returns it.
+func (t *Timeline) Play(anim Animation) Animation {
+	t.Playing = append(t.Playing, anim)
+	return anim
+}
+
+// Stop stops playing the animation where it is, also when it is stopped
+// by an animation that is stepped in the same tick. Animations that can
+// not be compared, such as a bare [Call], can not be stopped.
+func (t *Timeline) Stop(anim Animation) {
+	t.Playing = slices.DeleteFunc(t.Playing, func(p Animation) bool { return same(p, anim) })
+	for i, p := range t.stepping {
+		if same(p, anim) {
+			t.stopped[i] = true
+		}
+	}
+}
+
+// same reports whether a and b are the same animation, without panicking
+// on animations that can not be compared.
+func same(a, b Animation) bool {
+	ta := reflect.TypeOf(a)
+	if ta == nil || ta != reflect.TypeOf(b) || !ta.Comparable() {
+		return ta == nil && b == nil
+	}
+	return a == b
+}
+
+// Busy reports whether any animation is playing.
+func (t *Timeline) Busy() bool {
+	return len(t.Playing) > 0 || len(t.stepping) > 0
+}
+
+// Tick steps all playing animations once, and drops the ones that are
+// done or stopped. Animations may play new animations while they are
+// stepped, those are first stepped on the next tick.
+func (t *Timeline) Tick() {
+	t.stepping, t.Playing = t.Playing, nil
+	t.stopped = make([]bool, len(t.stepping))
+	var kept []Animation
+	for i, anim := range t.stepping {
+		if !t.stopped[i] && !anim.Step() && !t.stopped[i] {
+			kept = append(kept, anim)
+		}
+	}
+	t.Playing = append(kept, t.Playing...)
+	t.stepping, t.stopped = nil, nil
+}
+
+// Finish steps all playing animations to their end, such as to skip
+// them. Animations that do not end within limit ticks are dropped.
+func (t *Timeline) Finish(limit int) {
+	for i := 0; i < limit && t.Busy(); i++ {
+		t.Tick()
+	}
+	t.Playing = nil
+}
@@ -0,0 +1,235 @@
+package xgal
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEases(t *testing.T) {
+	for name, ease := range Eases {
+		if got := ease(0); math.Abs(got) > 1e-9 {
+			t.Errorf("%s(0) = %v", name, got)
+		}
+		if got := ease(1); math.Abs(got-1) > 1e-9 {
+			t.Errorf("%s(1) = %v", name, got)
+		}
+	}
+	if got := EaseInQuad(0.5); got != 0.25 {
+		t.Errorf("in-quad(0.5) = %v", got)
+	}
+	if got := EaseOutQuad(0.5); got != 0.75 {
+		t.Errorf("out-quad(0.5) = %v", got)
+	}
+	if got := EaseOutBack(0.7); got <= 1 {
+		t.Errorf("out-back(0.7) = %v does not overshoot", got)
+	}
+}
+
+func TestTween(t *testing.T) {
+	var got []float64
+	tween := NewTween(10, 20, 4, nil, func(v float64) { got = append(got, v) })
+	steps := 0
+	for !tween.Step() {
+		steps++
+	}
+	if steps != 3 || !tween.Done() {
+		t.Fatalf("tween of 4 ticks done after %d steps", steps+1)
+	}
+	want := []float64{12.5, 15, 17.5, 20}
+	if len(got) != len(want) {
+		t.Fatalf("values %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("values %v, want %v", got, want)
+			break
+		}
+	}
+	if !tween.Step() || len(got) != 4 {
+		t.Errorf("done tween set a value again")
+	}
+
+	tween.Reset()
+	if tween.Done() || tween.Value() != 10 {
+		t.Errorf("reset tween at %v", tween.Value())
+	}
+
+	end := 0.0
+	if !NewTween(0, 5, 0, EaseOutBounce, func(v float64) { end = v }).Step() || end != 5 {
+		t.Errorf("tween of no ticks not done at once: %v", end)
+	}
+}
+
+func TestLerp(t *testing.T) {
+	if got := LerpPoint(Pt(0, 10), Pt(10, 20), 0.25); got != Pt(3, 13) {
+		t.Errorf("LerpPoint = %v", got)
+	}
+	if got := LerpRect(Rect(0, 0, 10, 10), Rect(10, 10, 30, 30), 0.5); got != Rect(5, 5, 20, 20) {
+		t.Errorf("LerpRect = %v", got)
+	}
+	a, b := RGBA{R: 0, G: 100, B: 200, A: 255}, RGBA{R: 100, G: 100, B: 0, A: 55}
+	if got := LerpColor(a, b, 0.5); got != (RGBA{R: 50, G: 100, B: 100, A: 155}) {
+		t.Errorf("LerpColor = %v", got)
+	}
+}
+
+func TestSequence(t *testing.T) {
+	var log []string
+	x := 0.0
+	seq := NewSequence(
+		Call(func() { log = append(log, "start") }),
+		NewTween(0, 2, 2, nil, func(v float64) { x = v }),
+		NewWait(2),
+		Call(func() { log = append(log, "end") }),
+	)
+	ticks := 1
+	for !seq.Step() {
+		ticks++
+	}
+	if ticks != 4 {
+		t.Errorf("sequence of 4 ticks took %d", ticks)
+	}
+	if len(log) != 2 || log[0] != "start" || log[1] != "end" || x != 2 {
+		t.Errorf("log %v, x %v", log, x)
+	}
+
+	seq.Reset()
+	log = nil
+	seq.Step()
+	if len(log) != 1 || x != 1 {
+		t.Errorf("reset sequence not played again: %v, x %v", log, x)
+	}
+}
+
+func TestParallel(t *testing.T) {
+	x, y := 0.0, 0.0
+	par := NewParallel(
+		NewTween(0, 1, 2, nil, func(v float64) { x = v }),
+		NewTween(0, 4, 4, nil, func(v float64) { y = v }),
+	)
+	if par.Step() || x != 0.5 || y != 1 {
+		t.Fatalf("after 1 tick x %v y %v", x, y)
+	}
+	par.Step()
+	if par.Step() || x != 1 || y != 3 {
+		t.Fatalf("after 3 ticks x %v y %v", x, y)
+	}
+	if !par.Step() || y != 4 {
+		t.Errorf("parallel not done after 4 ticks")
+	}
+}
+
+func TestTimeline(t *testing.T) {
+	var a Timeline
+	x := 0.0
+	done := false
+	a.Play(NewSequence(
+		NewTween(0, 3, 3, EaseLinear, func(v float64) { x = v }),
+		Call(func() {
+			done = true
+			a.Play(NewWait(1))
+		}),
+	))
+	stop := a.Play(NewWait(100))
+	a.Stop(stop)
+
+	a.Tick()
+	if x != 1 || len(a.Playing) != 1 {
+		t.Fatalf("after 1 tick x %v, %d playing", x, len(a.Playing))
+	}
+	a.Tick()
+	a.Tick()
+	if !done || x != 3 || len(a.Playing) != 1 {
+		t.Fatalf("after 3 ticks done %v x %v, %d playing", done, x, len(a.Playing))
+	}
+	a.Tick()
+	if a.Busy() {
+		t.Errorf("animation played during the tick not done")
+	}
+
+	m := NewMotion()
+	a.Play(m.FadeOut(MotionTicks))
+	a.Finish(100)
+	if a.Busy() || m.Alpha != 0 || m.Scale != 0.9 {
+		t.Errorf("finished fade at alpha %v scale %v", m.Alpha, m.Scale)
+	}
+}
+
+func TestTimelineStopDuringTick(t *testing.T) {
+	var a Timeline
+	ticks := 0
+	later := NewTween(0, 1, 10, nil, func(float64) { ticks++ })
+	a.Play(Call(func() { a.Stop(later) }))
+	a.Play(later)
+	a.Stop(Call(func() {}))
+
+	a.Tick()
+	if ticks != 0 || a.Busy() {
+		t.Errorf("stopped during the tick: %d ticks, busy %v", ticks, a.Busy())
+	}
+
+	var self Animation
+	self = NewSequence(NewWait(1), Call(func() { a.Stop(self) }), NewWait(5))
+	a.Play(self)
+	a.Tick()
+	a.Tick()
+	if a.Busy() {
+		t.Errorf("sequence that stopped itself still plays")
+	}
+}
+
+func TestMotion(t *testing.T) {
+	m := NewMotion()
+	if !m.Still() || !(*Motion)(nil).Still() {
+		t.Fatalf("new motion not still")
+	}
+	bounds := Rect(10, 10, 30, 50)
+	if got := m.Box(bounds); got != bounds {
+		t.Errorf("still box %v", got)
+	}
+	m.Offset, m.Scale = Pt(5, 0), 0.5
+	if got := m.Box(bounds); got != Rect(20, 20, 30, 40) {
+		t.Errorf("moved box %v", got)
+	}
+
+	m = NewMotion()
+	bounce := m.Bounce(9)
+	least := 1.0
+	for !bounce.Step() {
+		least = min(least, m.Scale)
+	}
+	if least != 0.9 || m.Scale != 1 {
+		t.Errorf("bounce to %v and back to %v", least, m.Scale)
+	}
+
+	slide := m.SlideIn(Pt(0, -20), 4)
+	if m.Offset != Pt(0, -20) || m.Alpha != 0 {
+		t.Errorf("slide does not start out")
+	}
+	slide.Step()
+	if m.Offset.Y >= 0 || m.Alpha >= 1 {
+		t.Errorf("slide starts at %v alpha %v", m.Offset, m.Alpha)
+	}
+	for !slide.Step() {
+	}
+	if !m.Still() {
+		t.Errorf("slide ends at %v alpha %v", m.Offset, m.Alpha)
+	}
+}
+
+func TestMotionDrawSubImage(t *testing.T) {
+	screen := Prepare(160, 100)
+	pane := screen.SubImage(Rect(40, 30, 120, 90)).(*Surface)
+	m := NewMotion()
+	m.Alpha = 0.5
+	for range 2 {
+		var canvas Rectangle
+		m.Draw(pane, Rect(50, 40, 80, 60), func(dst *Surface) { canvas = dst.Bounds() })
+		if canvas != pane.Bounds() {
+			t.Fatalf("widget drawn on %v, not on %v", canvas, pane.Bounds())
+		}
+	}
+	if !Rect(50, 40, 80, 60).In(m.canvas.Bounds()) {
+		t.Errorf("canvas %v clips the widget", m.canvas.Bounds())
+	}
+}
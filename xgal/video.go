@@ -19,6 +19,7 @@ import (
 type Video struct {
 	mpg   *mpeg.MPEG
 	frame *Surface
+	shown bool // shown is set once a frame was decoded.
 
 	audioPlayer *audio.Player
 
@@ -81,22 +82,23 @@ func (v *Video) Draw(screen *Surface) {
 
 	pos := v.playbackPos()
 	video := v.mpg.Video()
-	if video.HasEnded() {
-		return
-	}
 
 	d := 1 / v.mpg.Framerate()
 	var mpegFrame *mpeg.Frame
 	for video.Time()+d <= pos && !video.HasEnded() {
 		mpegFrame = video.Decode()
 	}
-	if mpegFrame == nil {
+	if mpegFrame != nil {
+		rgba := mpegFrame.RGBA()
+		v.frame.WritePixels(rgba.Pix)
+		v.shown = true
+	}
+	// Keep drawing the last frame until the next one is due, so the
+	// video does not flicker when the screen is cleared every frame.
+	if !v.shown {
 		return
 	}
 
-	rgba := mpegFrame.RGBA()
-	v.frame.WritePixels(rgba.Pix)
-
 	sw, sh := screen.Bounds().Dx(), screen.Bounds().Dy()
 	fw, fh := v.frame.Bounds().Dx(), v.frame.Bounds().Dy()
 	op := &ebiten.DrawImageOptions{}
@@ -152,6 +154,13 @@ func (v *Video) Playing() bool {
 	return v.refTime != (time.Time{})
 }
 
+// Position returns how far the video has played.
+func (v *Video) Position() time.Duration {
+	v.m.Lock()
+	defer v.m.Unlock()
+	return time.Duration(v.playbackPos() * float64(time.Second))
+}
+
 func (v *Video) playbackPos() float64 {
 	if v.audioPlayer != nil {
 		return v.audioPlayer.Position().Seconds()
@@ -0,0 +1,62 @@
+package xlui
+
+import "slices"
+
+import "github.com/xmasengine/xmas/xgal"
+
+// MenuSlide is how far menus slide in from above.
+const MenuSlide = 8
+
+// Animate plays the animation on the UI. Animations are stepped on every
+// Tick of the UI, so they are driven by Poll.
+func (u *UI) Animate(anim xgal.Animation) xgal.Animation {
+	return u.Timeline.Play(anim)
+}
+
+// motion returns the motion of the layer, which it gets if it has none.
+func (l *Layer) motion() *xgal.Motion {
+	if l.Motion == nil {
+		l.Motion = xgal.NewMotion()
+	}
+	return l.Motion
+}
+
+// SlideIn slides the layer in from the offset while it fades in. The
+// layer fades out again when it is closed. Does nothing if the UI is
+// Still.
+func (u *UI) SlideIn(l *Layer, from xgal.Point) {
+	if u.Still {
+		return
+	}
+	u.Animate(l.motion().SlideIn(from, xgal.MotionTicks))
+}
+
+// FadeIn fades the layer in while it grows a little. The layer fades out
+// again when it is closed. Does nothing if the UI is Still.
+func (u *UI) FadeIn(l *Layer) {
+	if u.Still {
+		return
+	}
+	u.Animate(l.motion().FadeIn(xgal.MotionTicks))
+}
+
+// fadeOut fades out a layer that was closed. It is drawn on top of the
+// other layers until it is gone, but gets no more input.
+func (u *UI) fadeOut(l *Layer) {
+	u.fading = append(u.fading, l)
+	u.Animate(xgal.NewSequence(
+		l.Motion.FadeOut(xgal.MotionTicks),
+		xgal.Call(func() {
+			u.fading = slices.DeleteFunc(u.fading, func(f *Layer) bool { return f == l })
+		}),
+	))
+}
+
+// Bounce makes the control bounce like a pressed button. Does nothing if
+// the UI is Still or the control has no Motion.
+func (u *UI) Bounce(c *Control) {
+	if u.Still || c.Motion == nil {
+		return
+	}
+	u.Animate(c.Motion.Bounce(xgal.MotionTicks))
+}
@@ -0,0 +1,66 @@
+package xlui
+
+import (
+	"testing"
+
+	"github.com/xmasengine/xmas/xgal"
+)
+
+// TestDialogFades ensures dialogs fade in, and fade out after closing.
+func TestDialogFades(t *testing.T) {
+	u := &UI{}
+	dialog := u.Dialog(xgal.Rect(0, 0, 100, 50), "Sure?", "Yes", "No")
+	if dialog.Motion == nil || dialog.Motion.Alpha != 0 || !u.Timeline.Busy() {
+		t.Fatalf("dialog does not fade in")
+	}
+	u.Tick(1)
+	if a := dialog.Motion.Alpha; a <= 0 || a >= 1 {
+		t.Errorf("alpha after a tick = %v", a)
+	}
+	for i := 0; i < xgal.MotionTicks; i++ {
+		u.Tick(int64(i + 2))
+	}
+	if !dialog.Motion.Still() || u.Timeline.Busy() {
+		t.Fatalf("dialog not in after %d ticks", xgal.MotionTicks)
+	}
+
+	u.CloseLayer(dialog)
+	if len(u.Layers) != 0 || len(u.fading) != 1 {
+		t.Fatalf("closed dialog: %d layers, %d fading", len(u.Layers), len(u.fading))
+	}
+	u.Timeline.Finish(100)
+	if len(u.fading) != 0 || dialog.Motion.Alpha != 0 {
+		t.Errorf("dialog not faded out: %d fading, alpha %v", len(u.fading), dialog.Motion.Alpha)
+	}
+}
+
+// TestStillUI ensures a still UI does not animate.
+func TestStillUI(t *testing.T) {
+	u := &UI{Still: true}
+	menu := u.Menu(xgal.Rect(0, 0, 100, 50), "a", "b")
+	if menu.Motion != nil || u.Timeline.Busy() {
+		t.Errorf("menu of a still UI slides in")
+	}
+	u.CloseLayer(menu)
+	if len(u.fading) != 0 {
+		t.Errorf("menu of a still UI fades out")
+	}
+}
+
+// TestButtonBounces ensures clicked buttons bounce.
+func TestButtonBounces(t *testing.T) {
+	u := &UI{Still: true}
+	layer := u.Layer(xgal.Rect(0, 0, 100, 50))
+	button := layer.Button("OK")
+	u.Click(button.Bounds.Min, int(xgal.MouseButtonLeft))
+	if u.Timeline.Busy() {
+		t.Errorf("button of a still UI bounces")
+	}
+	u.Still = false
+	u.Release(button.Bounds.Min, int(xgal.MouseButtonLeft))
+	u.Click(button.Bounds.Min, int(xgal.MouseButtonLeft))
+	u.Tick(1)
+	if button.Motion.Scale >= 1 {
+		t.Errorf("clicked button scale = %v", button.Motion.Scale)
+	}
+}
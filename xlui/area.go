@@ -1,223 +1,89 @@
 package xlui
 
-import "slices"
-import "strings"
-
 import "github.com/xmasengine/xmas/xgal"
 
 const AreaSizer = "WWWWWWWWWWWWWWWWWWWWWWWWWWWWWWWW"
 const AreaBlink = 60
 
-func runeLinesToText(input [][]rune) string {
-	lines := make([]string, len(input))
-	for i, in := range input {
-		if in == nil {
-			lines[i] = ""
-		} else {
-			lines[i] = string(in)
-		}
-	}
-	return strings.Join(lines, "\n")
-}
-
-func textToRuneLines(text string) [][]rune {
-	lines := strings.Split(text, "\n")
-	res := make([][]rune, len(lines))
-	for i, line := range lines {
-		res[i] = []rune(line)
-	}
-	return res
-}
-
-// NewArea returns a new multi line text area Control.
+// NewArea returns a new multi line text area Control that shows the given
+// amount of lines. It edits like an entry, but Enter starts a new line and
+// Numpad Enter calls the Entry of the Class if the text is valid. The
+// text scrolls with the cursor and the mouse wheel. Set Numbers of its
+// [TextEdit] to show line numbers, for example to edit scripts.
 func NewArea(at xgal.Point, text string, lines int) *Control {
-	// area variables
-	var (
-		blink  = true
-		cursor xgal.Point
-		input  [][]rune
-	)
-
 	area := NewControl(at)
 	area.Text = text
 	area.State.Clicked = false
 	size := area.Style.Measure(AreaSizer)
-	input = textToRuneLines(text)
-
-	area.Bounds = xgal.Bound(area.Bounds.Min.X, area.Bounds.Min.Y, size.X, size.Y*lines)
-	clip := xgal.Bound(
-		area.Bounds.Min.X-area.Style.Margin.X,
-		area.Bounds.Min.Y-area.Style.Margin.Y,
-		area.Bounds.Dx()+area.Style.Margin.X,
-		area.Bounds.Dy()+area.Style.Margin.Y,
-	)
-
-	area.Clip = &clip
+	size.Y = area.Style.Stride() * lines
+	size = size.Add(area.Style.Margin.Mul(2))
+	area.Bounds = xgal.Bound(area.Bounds.Min.X, area.Bounds.Min.Y, size.X, size.Y)
+	edit := newTextEdit(area, lines)
+	edit.Multiline = true
 
 	render := func(screen *xgal.Surface) {
-		if area.Clip != nil {
-			sub := screen.SubImage(*area.Clip)
-			screen = sub.(*xgal.Surface)
-		}
-
-		delta := xgal.Pt(2, 0)
-		style := area.Style
-		if area.State.Hovered {
-			style = style.Hovered()
+		style := edit.style(area)
+		style.DrawBox(screen, area.Bounds)
+		edit.render(screen, area, style)
+		// Scroll markers.
+		if edit.Top+edit.Shown < len(edit.Lines()) {
+			xgal.Polyfill(screen, style.Fore,
+				area.Bounds.Max.X-5, area.Bounds.Max.Y,
+				area.Bounds.Max.X, area.Bounds.Max.Y-10,
+				area.Bounds.Max.X-10, area.Bounds.Max.Y-10,
+			)
 		}
-		if area.State.Focused {
-			style = style.Focused()
-		}
-
-		style.DrawBox(screen, area.Bounds.Add(delta))
-		// shift markers
-		if len(input) > lines {
-			if cursor.Y < len(input)-1 {
-				xgal.Polyfill(screen, style.Fore,
-					area.Bounds.Max.X-5, area.Bounds.Max.Y,
-					area.Bounds.Max.X, area.Bounds.Max.Y-10,
-					area.Bounds.Max.X-10, area.Bounds.Max.Y-10,
-				)
-			}
-			if area.From.Y < 0 {
-				xgal.Polyfill(screen, style.Fore,
-					area.Bounds.Max.X-5, area.Bounds.Min.Y,
-					area.Bounds.Max.X, area.Bounds.Min.Y+10,
-					area.Bounds.Max.X-10, area.Bounds.Min.Y+10,
-				)
-			}
-		}
-
-		// don't shift the box, but do shift the text and cursor
-		delta = delta.Add(area.From)
-		style.Print(screen, area.Bounds.Min.Add(delta), area.Text)
-		// Draw cursor if focused.
-		if area.State.Focused {
-			sz := area.Style.Measure(string(input[cursor.Y][:cursor.X]))
-			box := area.Bounds
-			min := box.Min.Add(delta)
-			cx := min.X + style.Margin.X + sz.X
-			cy := min.Y + style.Margin.Y + cursor.Y*size.Y
-			ch := size.Y
-			if blink {
-				xgal.Line(screen, cx, cy, cx, cy+ch, style.Stroke, style.Fore)
-			}
+		if edit.Top > 0 {
+			xgal.Polyfill(screen, style.Fore,
+				area.Bounds.Max.X-5, area.Bounds.Min.Y,
+				area.Bounds.Max.X, area.Bounds.Min.Y+10,
+				area.Bounds.Max.X-10, area.Bounds.Min.Y+10,
+			)
 		}
 	}
 
 	click := func(at xgal.Point, which int) Reply {
-		return Accept
+		return edit.click(area, at)
 	}
 
 	release := func(at xgal.Point, which int) Reply {
-		return Accept
+		return edit.release()
 	}
 
 	hover := func(at xgal.Point) Reply {
-		return Accept
+		return edit.hover(area, at)
+	}
+
+	wheel := func(at xgal.Point, delta int) Reply {
+		return edit.wheel(area, delta)
 	}
 
 	tap := func(key int, mods Mods) Reply {
-		switch xgal.KeyCode(key) {
-		case xgal.KeyArrowLeft:
-			if cursor.X == 0 && cursor.Y > 0 {
-				cursor.Y--
-				cursor.X = len(input[cursor.Y])
-			} else {
-				cursor.X = max(0, cursor.X-1)
-			}
-		case xgal.KeyArrowRight:
-			if cursor.X == len(input[cursor.Y]) && cursor.Y < (len(input)-1) {
-				cursor.Y++
-				cursor.X = 0
-			} else {
-				cursor.X = min(cursor.X+1, len(input[cursor.Y]))
+		if xgal.KeyCode(key) == xgal.KeyNumpadEnter {
+			edit.sync(area)
+			if edit.Valid() != nil {
+				return Accept
 			}
-		case xgal.KeyArrowUp:
-			cursor.Y = max(0, cursor.Y-1)
-			cursor.X = min(cursor.X, len(input[cursor.Y]))
-		case xgal.KeyArrowDown:
-			cursor.Y = min(cursor.Y+1, len(input)-1)
-			cursor.X = min(cursor.X, len(input[cursor.Y]))
-		case xgal.KeyHome:
-			cursor.X = 0
-		case xgal.KeyEnd:
-			cursor.X = len(input[cursor.Y])
-		case xgal.KeyPageUp:
-			cursor.Y = 0
-		case xgal.KeyPageDown:
-			cursor.Y = len(input) - 1
-		case xgal.KeyNumpadEnter:
 			if area.Class.Entry != nil {
-				area.Text = runeLinesToText(input)
 				return area.Class.Entry(area.Text)
 			}
-		case xgal.KeyBackspace:
-			if cursor.X > 0 {
-				input[cursor.Y] = slices.Delete(input[cursor.Y], cursor.X-1, cursor.X)
-				cursor.X--
-			} else if cursor.Y > 0 {
-				cursor.X = len(input[cursor.Y-1])
-				line := append(input[cursor.Y-1], input[cursor.Y]...)
-				input = slices.Delete(input, cursor.Y-1, cursor.Y)
-				cursor.Y--
-				input[cursor.Y] = line
-			}
-		case xgal.KeyDelete:
-			if cursor.X < len(input[cursor.Y]) {
-				input[cursor.Y] = slices.Delete(input[cursor.Y], cursor.X, cursor.X+1)
-			} else if cursor.Y < len(input)-1 {
-				// cursor.X = len(input[cursor.Y+1])
-				line := append(input[cursor.Y], input[cursor.Y+1]...)
-				input = slices.Delete(input, cursor.Y, cursor.Y+1)
-				input[cursor.Y] = line
-			}
-		case xgal.KeyEnter:
-			line := input[cursor.Y]
-			before, after := slices.Clone(line[:cursor.X]), slices.Clone(line[cursor.X:])
-			input = slices.Insert(input, cursor.Y, before)
-			cursor.Y++
-			input[cursor.Y] = after
-			cursor.X = 0
-		default:
 			return Ignore
 		}
-		blink = true
-		area.Text = runeLinesToText(input)
-		area.From = xgal.Pt(0, 0)
-		if cursor.Y >= lines {
-			// shift up
-			area.From.Y = (lines - cursor.Y - 1) * size.Y
-		}
-		lineLen := len(AreaSizer)
-		if cursor.X >= len(AreaSizer) {
-			// shift left
-			area.From.X = (lineLen - cursor.X - 1) * (size.X / len(AreaSizer))
-		}
-
-		return Accept
+		return edit.tap(area, key, mods)
 	}
 
 	chars := func(chrs ...rune) Reply {
-		if len(chrs) > 0 {
-			input[cursor.Y] = slices.Insert(input[cursor.Y], cursor.X, chrs...)
-			cursor.X += len(chrs)
-			lineLen := len(AreaSizer)
-			if cursor.X >= len(AreaSizer) {
-				// shift left
-				area.From.X = (lineLen - cursor.X - 1) * (size.X / len(AreaSizer))
-			}
-			area.Text = runeLinesToText(input)
-			return Accept
-		}
-		return Ignore
+		return edit.chars(area, chrs...)
+	}
+
+	// Keep the size when the text is set, the text scrolls instead.
+	relabel := func(text string) {
+		area.Text = text
+		edit.sync(area)
 	}
 
 	tick := func(t int64) Reply {
-		if (t % AreaBlink) == 0 {
-			blink = !blink
-		}
-		return Ignore
+		return edit.tick(t, AreaBlink)
 	}
 
 	area.Class = Class{
@@ -225,9 +91,12 @@ func NewArea(at xgal.Point, text string, lines int) *Control {
 		Click:   click,
 		Release: release,
 		Hover:   hover,
+		Wheel:   wheel,
 		Tap:     tap,
 		Chars:   chars,
 		Tick:    tick,
+		Relabel: relabel,
+		Say:     func() string { return area.Text },
 	}
 	return area
 }
@@ -14,6 +14,7 @@ func (l *Layer) Checkbox(checked bool) *Control {
 func (l *Layer) CheckboxWithLabel(checked bool, text string) (*Control, *Control) {
 	checkbox := l.Checkbox(checked)
 	label := l.Label(text)
+	checkbox.Label = text
 	label.Class.Click = func(at xgal.Point, button int) Reply {
 		return checkbox.Class.Click(at, button)
 	}
@@ -26,8 +27,8 @@ func NewCheckbox(at xgal.Point, checked bool) *Control {
 	checkbox.State.Clicked = false
 	checkbox.Checked = checked
 	size := checkbox.Style.Measure(CheckboxSizer)
-	size.X = max(size.X, CheckboxSize)
-	size.Y = max(size.Y, CheckboxSize)
+	size.X = max(size.X, xgal.UILength(CheckboxSize))
+	size.Y = max(size.Y, xgal.UILength(CheckboxSize))
 
 	checkbox.Bounds = xgal.Bound(checkbox.Bounds.Min.X, checkbox.Bounds.Min.Y, size.X, size.Y)
 
@@ -37,8 +38,9 @@ func NewCheckbox(at xgal.Point, checked bool) *Control {
 			style = style.Hovered()
 		}
 		box := checkbox.Bounds
-		cy := box.Min.Y + (box.Dy()-CheckboxSize)/2
-		ibox := xgal.Rect(box.Min.X+style.Margin.X, cy, box.Min.X+style.Margin.X+CheckboxSize, cy+CheckboxSize)
+		size := xgal.UILength(CheckboxSize)
+		cy := box.Min.Y + (box.Dy()-size)/2
+		ibox := xgal.Rect(box.Min.X+style.Margin.X, cy, box.Min.X+style.Margin.X+size, cy+size)
 
 		cstyle := style.CheckStyle()
 		if checkbox.Checked {
@@ -66,6 +68,7 @@ func NewCheckbox(at xgal.Point, checked bool) *Control {
 		Click:   click,
 		Release: release,
 		Hover:   hover,
+		Say:     sayChecked(checkbox, "checked", "unchecked"),
 	}
 	return checkbox
 }
@@ -24,6 +24,11 @@ type Class struct {
 	Chars  func(chars ...rune) Reply
 	MoveBy func(delta xgal.Point)
 	Set    func(args ...any) error
+	// Relabel changes the text of controls that lay out their text.
+	Relabel func(text string)
+	// Say returns the value of the control as it is announced, see
+	// [Control.Said].
+	Say func() string
 }
 
 type ClickFunc func(at xgal.Point, button int) Reply
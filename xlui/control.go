@@ -15,6 +15,8 @@ type Control struct {
 	Class
 	// Data
 	Text    string // For use by text controls.
+	Key     string // Key is the string table key of the text, if any.
+	Label   string // Label names the control for the speaker, if its Text does not.
 	Checked bool   // For use by boolean controls like a checkbox or radio button.
 	Value   int
 	Low     int
@@ -24,12 +26,17 @@ type Control struct {
 	Clip   *xgal.Rectangle
 	Style
 	From        xgal.Point
-	Orientation Orientation // layout orientation in the layer
-	State       State       // State of the control
-	Data        any         // Additional data if needed.
+	Orientation Orientation  // layout orientation in the layer
+	State       State        // State of the control
+	Data        any          // Additional data if needed.
+	Motion      *xgal.Motion // Motion animates how the control is drawn, if set.
 }
 
 func (c Control) Render(s *xgal.Surface) {
+	c.Motion.Draw(s, c.Bounds, c.render)
+}
+
+func (c Control) render(s *xgal.Surface) {
 	if c.Class.Render == nil {
 		c.Style.DrawBox(s, c.Bounds)
 		return
@@ -95,6 +102,7 @@ func NewLabel(at xgal.Point, text string) *Control {
 func NewButton(at xgal.Point, text string) *Control {
 	button := NewControlWithText(at, ButtonStyle(), text)
 	button.State.Clicked = false
+	button.Motion = xgal.NewMotion()
 
 	render := func(screen *xgal.Surface) {
 		style := button.Style.ForState(button.State)
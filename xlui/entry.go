@@ -1,108 +1,70 @@
 package xlui
 
-import "slices"
-
 import "github.com/xmasengine/xmas/xgal"
 
 const EntrySizer = "WWWWWWWW"
 const EntryBlink = 60
 
-// NewEntry returns a new text entry Control.
+// NewEntry returns a new text entry Control. The entry edits its text
+// with a [TextEdit] in its Data: shift and the mouse select, control with
+// A, C, X and V selects all and uses the clipboard, control with the arrow
+// keys jumps by words and control with Z and Y undoes and redoes.
+// Enter calls the Entry of the Class if the text is valid for the mask.
+// Text from an input method arrives through [xgal.Chars] once it is
+// committed; the entry does not show the composition while it is typed.
 func NewEntry(at xgal.Point, text string) *Control {
 	entry := NewControl(at)
 	entry.Text = text
 	entry.State.Clicked = false
-	size := entry.Style.Measure(EntrySizer)
+	size := entry.Style.Measure(EntrySizer).Add(entry.Style.Margin.Mul(2))
 	entry.Bounds = xgal.Bound(entry.Bounds.Min.X, entry.Bounds.Min.Y, size.X, size.Y)
-	// entry variables
-	var (
-		blink  = true
-		cursor int
-		input  []rune
-	)
-
-	if len(entry.Text) > 0 {
-		input = []rune(entry.Text)
-		cursor = len(input)
-	}
+	edit := newTextEdit(entry, 1)
 
 	render := func(screen *xgal.Surface) {
-		delta := xgal.Pt(2, 0)
-		style := entry.Style.ForState(entry.State)
-
-		style.DrawBox(screen, entry.Bounds.Add(delta))
-		style.Print(screen, entry.Bounds.Min.Add(delta), entry.Text)
-		// Draw cursor if focused.
-		if entry.State.Focused {
-			sz := entry.Style.Measure(entry.Text[:cursor])
-			box := entry.Bounds
-			min := box.Min.Add(delta)
-			cx := min.X + style.Margin.X + sz.X
-			cy := min.Y + style.Margin.Y
-			ch := box.Dy() - style.Margin.Y*2
-			if blink {
-				xgal.Line(screen, cx, cy, cx, cy+ch, style.Stroke, style.Fore)
-			}
-		}
+		style := edit.style(entry)
+		style.DrawBox(screen, entry.Bounds)
+		edit.render(screen, entry, style)
 	}
 
 	click := func(at xgal.Point, which int) Reply {
-		return Accept
+		return edit.click(entry, at)
 	}
 
 	release := func(at xgal.Point, which int) Reply {
-		return Accept
+		return edit.release()
 	}
 
 	hover := func(at xgal.Point) Reply {
-		return Accept
+		return edit.hover(entry, at)
 	}
 
 	tap := func(key int, mods Mods) Reply {
 		switch xgal.KeyCode(key) {
-		case xgal.KeyArrowLeft:
-			cursor = max(0, cursor-1)
-		case xgal.KeyArrowRight:
-			cursor = min(cursor+1, len(input))
-		case xgal.KeyHome:
-			cursor = 0
-		case xgal.KeyEnd:
-			cursor = len(input)
-		case xgal.KeyEnter:
-			if entry.Class.Entry != nil {
-				return entry.Class.Entry(string(input))
+		case xgal.KeyEnter, xgal.KeyNumpadEnter:
+			edit.sync(entry)
+			if edit.Valid() != nil {
+				return Accept
 			}
-		case xgal.KeyBackspace:
-			if cursor > 0 {
-				input = slices.Delete(input, cursor-1, cursor)
-				cursor--
-			}
-		case xgal.KeyDelete:
-			if cursor < len(input) {
-				input = slices.Delete(input, cursor, cursor+1)
+			if entry.Class.Entry != nil {
+				return entry.Class.Entry(entry.Text)
 			}
-		default:
 			return Ignore
 		}
-		entry.Text = string(input)
-		return Accept
+		return edit.tap(entry, key, mods)
 	}
 
 	chars := func(chrs ...rune) Reply {
-		if len(chrs) > 0 {
-			input = slices.Insert(input, cursor, chrs...)
-			cursor += len(chrs)
-			entry.Text = string(input)
-			return Accept
-		}
-		return Ignore
+		return edit.chars(entry, chrs...)
+	}
+
+	// Keep the size when the text is set, the text scrolls instead.
+	relabel := func(text string) {
+		entry.Text = text
+		edit.sync(entry)
 	}
 
 	tick := func(t int64) Reply {
-		if (t % EntryBlink) == 0 {
-			blink = !blink
-		}
-		return Ignore
+		return edit.tick(t, EntryBlink)
 	}
 
 	entry.Class = Class{
@@ -113,6 +75,8 @@ func NewEntry(at xgal.Point, text string) *Control {
 		Tap:     tap,
 		Chars:   chars,
 		Tick:    tick,
+		Relabel: relabel,
+		Say:     func() string { return entry.Text },
 	}
 	return entry
 }
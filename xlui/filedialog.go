@@ -0,0 +1,395 @@
+package xlui
+
+import (
+	"io/fs"
+
+	"github.com/xmasengine/xmas/xgal"
+	"github.com/xmasengine/xmas/xvec"
+)
+
+// FileDialogLayer is a layer that opens or saves a file in the
+// directories of an [fs.FS], with an [xgal.Browser]. It lists the
+// directories and the files with the extensions of the browser, with the
+// layer they are in for a [wfs.Overlay], and previews the selected file
+// if it is an image or an xvec file. Clicking a directory enters it,
+// clicking a file selects it and clicking it again chooses it. The
+// buttons go up a directory, make a new folder, show the recent files,
+// and choose the file or cancel. Saving over a file asks to confirm.
+// The Entry of the Class is called with the path of the chosen file.
+type FileDialogLayer struct {
+	Layer
+	*xgal.Browser
+
+	Prompt string
+	Offset int // First visible item index.
+
+	ui      *UI      // ui asks the questions of the dialog, if set.
+	list    *Control // list shows the items.
+	preview *Control // preview shows the preview of the selected file.
+	entry   *Control // entry is the name of the file.
+	recent  *Control // recent toggles between the recent files and Dir.
+	recents bool     // recents shows the recent files rather than Dir.
+	hover   int      // hover is the hovered item, -1 for none.
+	err     error    // err is the last error, shown instead of Dir.
+}
+
+// NewFileDialog returns a new [FileDialogLayer] that browses fsys,
+// starting at the named file if it is set. Only files with one of the
+// extensions are shown. If save is set, the file is saved rather than
+// opened. Without a UI to ask in, there is no button to make a folder
+// and saving over a file is not confirmed.
+func NewFileDialog(ui *UI, bounds xgal.Rectangle, prompt string, fsys fs.FS, name string, save bool, exts ...string) *FileDialogLayer {
+	f := &FileDialogLayer{
+		Layer:   *NewLayer(bounds),
+		Browser: xgal.NewBrowser(fsys, name, save, exts...),
+		Prompt:  prompt,
+		ui:      ui,
+		hover:   -1,
+	}
+	f.Previewers[".xvec"] = xvec.Preview
+	f.Class.Render = f.render
+
+	f.list = f.Append(NewControl(bounds.Min))
+	f.list.Class = Class{
+		Render: f.renderList,
+		Hover:  f.hoverList,
+		Click:  f.clickList,
+		Wheel:  f.wheelList,
+	}
+	f.preview = f.Append(NewControl(bounds.Min))
+	f.preview.Class.Render = f.renderPreview
+
+	f.entry = f.Append(NewEntry(bounds.Min, f.Name))
+	f.entry.Class.Entry = func(string) Reply { return f.choose() }
+	edit := f.entry.Class.Tap
+	f.entry.Class.Tap = func(key int, mods Mods) Reply {
+		if res := f.tap(key, mods); res != Ignore {
+			return res
+		}
+		return edit(key, mods)
+	}
+
+	f.button("Up", func() Reply {
+		f.recents = false
+		f.err = f.Enter("..")
+		f.Offset = 0
+		return Accept
+	})
+	if ui != nil {
+		f.button("Folder", f.askFolder)
+	}
+	f.recent = f.button("Recent", func() Reply {
+		f.recents = !f.recents
+		f.recent.Text = "Recent"
+		if f.recents {
+			f.recent.Text = "Files"
+		}
+		f.Offset = 0
+		return Accept
+	})
+	done := "Open"
+	if save {
+		done = "Save"
+	}
+	f.button(done, f.choose)
+	f.button("Cancel", func() Reply { return Finish })
+
+	f.place()
+	f.SetFocus(f.entry)
+	return f
+}
+
+// FileDialog adds a new [FileDialogLayer] to the UI.
+func (u *UI) FileDialog(bounds xgal.Rectangle, prompt string, fsys fs.FS, name string, save bool, exts ...string) *FileDialogLayer {
+	f := NewFileDialog(u, bounds, prompt, fsys, name, save, exts...)
+	u.Append(&f.Layer)
+	return f
+}
+
+// button adds a button that calls pressed when clicked.
+func (f *FileDialogLayer) button(text string, pressed func() Reply) *Control {
+	button := f.Append(NewButton(f.Bounds.Min, text))
+	button.Class.Click = func(at xgal.Point, which int) Reply {
+		f.err = nil
+		return pressed()
+	}
+	return button
+}
+
+// lineHeight returns the height of a line of text with its margins.
+func (f *FileDialogLayer) lineHeight() int {
+	return f.Style.Measure("X").Y + f.Style.Margin.Y*2
+}
+
+// place lays out the controls: the title on top, the list and the
+// preview beside it, and the entry above a row of buttons at the bottom.
+func (f *FileDialogLayer) place() {
+	pad := f.Style.Margin
+	lh := f.lineHeight()
+	inner := xgal.Rect(f.Bounds.Min.X+pad.X, f.Bounds.Min.Y+pad.Y, f.Bounds.Max.X-pad.X, f.Bounds.Max.Y-pad.Y)
+
+	buttons := f.Controls[3:]
+	bw := (inner.Dx() - pad.X*(len(buttons)-1)) / len(buttons)
+	by := inner.Max.Y - lh
+	for i, button := range buttons {
+		bx := inner.Min.X + i*(bw+pad.X)
+		button.Bounds = xgal.Rect(bx, by, bx+bw, by+lh)
+	}
+	ey := by - pad.Y - lh
+	f.entry.Bounds = xgal.Rect(inner.Min.X, ey, inner.Max.X, ey+lh)
+	top := inner.Min.Y + lh + pad.Y
+	bottom := max(ey-pad.Y, top)
+	split := f.Bounds.Min.X + f.Bounds.Dx()*2/3
+	f.list.Bounds = xgal.Rect(inner.Min.X, top, split, bottom)
+	f.preview.Bounds = xgal.Rect(split+pad.X, top, inner.Max.X, bottom)
+}
+
+// items returns the texts of the items of the list.
+func (f *FileDialogLayer) items() []string {
+	if f.recents {
+		return f.Recents()
+	}
+	items := make([]string, len(f.Entries))
+	for i, entry := range f.Entries {
+		items[i] = f.Browser.Label(entry)
+	}
+	return items
+}
+
+// limit returns how many items the list shows.
+func (f *FileDialogLayer) limit() int {
+	return max(f.list.Bounds.Dy()/ListItemHeight, 1)
+}
+
+func (f *FileDialogLayer) clampOffset() {
+	f.Offset = min(max(f.Offset, 0), max(len(f.items())-f.limit(), 0))
+}
+
+// selected returns the index of the item of the selected file, or -1.
+func (f *FileDialogLayer) selected() int {
+	if f.recents {
+		return -1
+	}
+	for i, entry := range f.Entries {
+		if !entry.Dir && entry.Name == f.Name {
+			return i
+		}
+	}
+	return -1
+}
+
+// setName sets the name of the file and shows it in the entry.
+func (f *FileDialogLayer) setName(name string) {
+	f.Name = name
+	f.entry.SetText(name)
+	if te := f.entry.TextEdit(); te != nil {
+		te.SelectAll()
+	}
+}
+
+// pick handles a click on the i-th item.
+func (f *FileDialogLayer) pick(i int) Reply {
+	f.err = nil
+	if f.recents {
+		f.err = f.Go(f.Recents()[i])
+		f.recents = false
+		f.recent.Text = "Recent"
+		f.Offset = 0
+		f.setName(f.Name)
+		return Accept
+	}
+	entry := f.Entries[i]
+	if !entry.Dir && entry.Name != f.Name {
+		f.setName(entry.Name)
+		return Accept
+	}
+	chosen, err := f.Open(entry)
+	f.err = err
+	if chosen {
+		return f.choose()
+	}
+	f.Offset = 0
+	return Accept
+}
+
+// choose checks the name of the file, and finishes the dialog with it,
+// after asking to confirm overwriting it.
+func (f *FileDialogLayer) choose() Reply {
+	f.Name = f.entry.Text
+	name, err := f.Check()
+	f.err = err
+	if err != nil || name == "" {
+		f.setName(f.Name)
+		return Accept
+	}
+	if f.ui != nil && f.Overwrites(name) {
+		confirm := f.ui.Dialog(f.askBounds(), "Overwrite "+name+"?", "Yes", "No")
+		confirm.Class.Value = func(v int) Reply {
+			if v == 0 && f.finish(name) == Finish {
+				f.ui.CloseLayer(&f.Layer)
+			}
+			return Finish
+		}
+		return Accept
+	}
+	return f.finish(name)
+}
+
+// finish adds the name to the recent files and calls the Entry of the
+// Class with it.
+func (f *FileDialogLayer) finish(name string) Reply {
+	f.Done(name)
+	if f.Class.Entry != nil {
+		return f.Class.Entry(name)
+	}
+	return Finish
+}
+
+// askFolder asks for the name of a new folder and makes it in Dir.
+func (f *FileDialogLayer) askFolder() Reply {
+	ask := f.ui.Asker(f.askBounds(), "Folder name?", "", " X ", " V ")
+	ask.Class.Entry = func(name string) Reply {
+		f.err = f.Mkdir(name)
+		return Finish
+	}
+	return Accept
+}
+
+// askBounds returns the bounds of a question asked on top.
+func (f *FileDialogLayer) askBounds() xgal.Rectangle {
+	lh := f.lineHeight()
+	c := f.Bounds.Min.Add(f.Bounds.Max).Div(2)
+	return xgal.Rect(f.Bounds.Min.X+lh, c.Y-lh*2, f.Bounds.Max.X-lh, c.Y+lh*2)
+}
+
+// tap handles the keys of the entry that it does not use: escape cancels
+// and the arrow keys select the next or previous file.
+func (f *FileDialogLayer) tap(key int, mods Mods) Reply {
+	sel := f.selected()
+	switch xgal.KeyCode(key) {
+	case xgal.KeyEscape:
+		return Finish
+	case xgal.KeyArrowDown:
+		if sel >= 0 && sel < len(f.Entries)-1 && !f.Entries[sel+1].Dir {
+			f.setName(f.Entries[sel+1].Name)
+			f.show(sel + 1)
+			return Accept
+		}
+	case xgal.KeyArrowUp:
+		if sel > 0 && !f.Entries[sel-1].Dir {
+			f.setName(f.Entries[sel-1].Name)
+			f.show(sel - 1)
+			return Accept
+		}
+	}
+	return Ignore
+}
+
+// show scrolls the list so the i-th item is in view.
+func (f *FileDialogLayer) show(i int) {
+	if i < f.Offset {
+		f.Offset = i
+	} else if i >= f.Offset+f.limit() {
+		f.Offset = i - f.limit() + 1
+	}
+	f.clampOffset()
+}
+
+// itemIndex returns the index of the item under at, or -1.
+func (f *FileDialogLayer) itemIndex(at xgal.Point) int {
+	if !at.In(f.list.Bounds) {
+		return -1
+	}
+	i := f.Offset + (at.Y-f.list.Bounds.Min.Y)/ListItemHeight
+	if i >= len(f.items()) {
+		return -1
+	}
+	return i
+}
+
+func (f *FileDialogLayer) hoverList(at xgal.Point) Reply {
+	f.hover = f.itemIndex(at)
+	return Accept
+}
+
+func (f *FileDialogLayer) clickList(at xgal.Point, which int) Reply {
+	// Keep the keys going to the entry.
+	f.SetFocus(f.entry)
+	if which != int(xgal.MouseButtonLeft) {
+		return Accept
+	}
+	if i := f.itemIndex(at); i >= 0 {
+		return f.pick(i)
+	}
+	return Accept
+}
+
+func (f *FileDialogLayer) wheelList(at xgal.Point, delta int) Reply {
+	f.Offset -= delta
+	f.clampOffset()
+	return Accept
+}
+
+func (f *FileDialogLayer) render(s *xgal.Surface) {
+	style := f.Style
+	if f.State.Focused {
+		style = style.Focused()
+	}
+	style.DrawBox(s, f.Bounds)
+	pad := f.Style.Margin
+	title := xgal.Rect(f.Bounds.Min.X+pad.X, f.Bounds.Min.Y+pad.Y, f.Bounds.Max.X-pad.X, f.Bounds.Min.Y+pad.Y+f.lineHeight())
+	if f.err != nil {
+		f.Style.Error().Ink(s, title, f.err.Error())
+	} else if f.recents {
+		f.Style.Ink(s, title, f.Prompt+" recent files")
+	} else {
+		f.Style.Ink(s, title, f.Prompt+" "+f.Dir)
+	}
+}
+
+func (f *FileDialogLayer) renderList(s *xgal.Surface) {
+	f.clampOffset()
+	list := f.list.Bounds
+	f.Style.DrawBox(s, list)
+	if f.Hovered != f.list {
+		f.hover = -1
+	}
+	items := f.items()
+	sel := f.selected()
+	for i := f.Offset; i < min(len(items), f.Offset+f.limit()); i++ {
+		y := list.Min.Y + (i-f.Offset)*ListItemHeight
+		item := xgal.Rect(list.Min.X, y, list.Max.X, y+ListItemHeight)
+		style := f.Style
+		if i == f.hover {
+			style = style.HoverStyle()
+		}
+		if i == sel {
+			style = style.Focused()
+		}
+		style.DrawBox(s, item)
+		style.Ink(s, item, items[i])
+	}
+}
+
+func (f *FileDialogLayer) renderPreview(s *xgal.Surface) {
+	box := f.preview.Bounds
+	f.Style.DrawBox(s, box)
+	preview := f.Preview()
+	if preview == nil || box.Empty() {
+		return
+	}
+	xgal.Blit(s, preview, fitBounds(preview.Bounds().Size(), box.Inset(2)), preview.Bounds())
+}
+
+// fitBounds returns the largest bounds of the size's aspect centered in box.
+func fitBounds(size xgal.Point, box xgal.Rectangle) xgal.Rectangle {
+	if size.X <= 0 || size.Y <= 0 {
+		return xgal.Rectangle{}
+	}
+	w, h := box.Dx(), size.Y*box.Dx()/size.X
+	if h > box.Dy() {
+		w, h = size.X*box.Dy()/size.Y, box.Dy()
+	}
+	at := box.Min.Add(xgal.Pt(box.Dx()-w, box.Dy()-h).Div(2))
+	return xgal.Rectangle{Min: at, Max: at.Add(xgal.Pt(w, h))}
+}
@@ -0,0 +1,77 @@
+package xlui
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/xmasengine/xmas/xgal"
+)
+
+func testFiles() fstest.MapFS {
+	return fstest.MapFS{
+		"a.txt":       {Data: []byte("a")},
+		"b.txt":       {Data: []byte("b")},
+		"c.png":       {Data: []byte("c")},
+		"sub/d.txt":   {Data: []byte("d")},
+		"sub/e.other": {Data: []byte("e")},
+	}
+}
+
+// TestFileDialogOpen ensures directories are entered and files chosen.
+func TestFileDialogOpen(t *testing.T) {
+	u := &UI{}
+	f := u.FileDialog(xgal.Rect(0, 0, 300, 200), "Open", testFiles(), "", false, ".txt")
+	f.Recent = &xgal.Recent{}
+	chosen := ""
+	f.Class.Entry = func(name string) Reply { chosen = name; return Finish }
+
+	if items := f.items(); len(items) != 3 || items[0] != "sub/" || items[1] != "a.txt" {
+		t.Fatalf("items = %v", items)
+	}
+	if res := f.pick(0); res != Accept || f.Dir != "sub" {
+		t.Fatalf("pick sub: %v, Dir = %q", res, f.Dir)
+	}
+	if items := f.items(); len(items) != 2 || items[0] != "../" || items[1] != "d.txt" {
+		t.Fatalf("items in sub = %v", items)
+	}
+	f.pick(1)
+	if f.entry.Text != "d.txt" || f.selected() != 1 {
+		t.Fatalf("entry %q, selected %d", f.entry.Text, f.selected())
+	}
+	if res := f.pick(1); res != Finish || chosen != "sub/d.txt" {
+		t.Fatalf("pick d.txt again: %v, chosen %q", res, chosen)
+	}
+	if recent := f.Recents(); len(recent) != 1 || recent[0] != "sub/d.txt" {
+		t.Errorf("recent = %v", recent)
+	}
+
+	f.entry.Text = "gone.txt"
+	if res := f.choose(); res != Accept || f.err == nil {
+		t.Errorf("missing file chosen: %v, %v", res, f.err)
+	}
+}
+
+// TestFileDialogOverwrite ensures saving over a file asks first.
+func TestFileDialogOverwrite(t *testing.T) {
+	u := &UI{}
+	f := u.FileDialog(xgal.Rect(0, 0, 300, 200), "Save", testFiles(), "a.txt", true, ".txt")
+	f.Recent = &xgal.Recent{}
+	chosen := ""
+	f.Class.Entry = func(name string) Reply { chosen = name; return Finish }
+
+	if res := f.choose(); res != Accept || chosen != "" || len(u.Layers) != 2 {
+		t.Fatalf("overwrite not asked: %v, %q, %d layers", res, chosen, len(u.Layers))
+	}
+	confirm := u.Layers[1]
+	if res := confirm.Class.Value(0); res != Finish || chosen != "a.txt" {
+		t.Fatalf("overwrite not confirmed: %v, %q", res, chosen)
+	}
+	if u.LayerIndex(&f.Layer) >= 0 {
+		t.Errorf("file dialog still open")
+	}
+
+	f.entry.Text = "new.png"
+	if res := f.choose(); res != Accept || f.err == nil {
+		t.Errorf("saved with the wrong extension: %v, %v", res, f.err)
+	}
+}
@@ -40,27 +40,38 @@ type Layer struct {
 	Orientation Orientation // layout orientation in the group
 	State       State       // state of the layer
 
-	Hovered *Control // Hovered is currently hovered control or none if nil.
-	Clicked *Control // Clicked is currently clicked control or none if nil.
-	Focused *Control // Clicked is currently focused control or none if nil.
-	Data    any      // Additional data if needed.
+	Hovered *Control     // Hovered is currently hovered control or none if nil.
+	Clicked *Control     // Clicked is currently clicked control or none if nil.
+	Focused *Control     // Clicked is currently focused control or none if nil.
+	Data    any          // Additional data if needed.
+	Motion  *xgal.Motion // Motion animates how the layer is drawn, if set.
 }
 
 func NewLayer(bounds xgal.Rectangle) *Layer {
 	return &Layer{Bounds: bounds, Style: DefaultStyle(), Orientation: Horizontal}
 }
 
+// SetFocus focuses the control, or none if it is nil. A control that gets
+// the focus is announced to the speaker.
 func (l *Layer) SetFocus(c *Control) {
+	old := l.Focused
 	if l.Focused != nil {
 		l.Focused.State.Focused = false
 	}
 	l.Focused = c
 	if l.Focused != nil {
 		l.Focused.State.Focused = true
+		if c != old {
+			c.speak(xgal.CueFocus)
+		}
 	}
 }
 
 func (l Layer) Render(s *xgal.Surface) {
+	l.Motion.Draw(s, l.Bounds, l.render)
+}
+
+func (l Layer) render(s *xgal.Surface) {
 	if l.Class.Render == nil {
 		if l.State.Focused {
 			l.Style.Focused().DrawBox(s, l.Bounds)
@@ -91,23 +102,30 @@ func (l *Layer) MoveBy(delta xgal.Point) {
 
 // Appends adds a control to this layer and lays it out by a simple line algorithm.
 func (l *Layer) Append(ctrl *Control) *Control {
+	var last *Control
+	if len(l.Controls) > 0 {
+		last = l.Controls[len(l.Controls)-1]
+	}
+	l.place(ctrl, last, ctrl.Bounds.Min.X)
+	l.Controls = append(l.Controls, ctrl)
+	return ctrl
+}
+
+// place moves the control after the last one, or to the start of the
+// layer if last is nil. Controls that do not fit on the line start at x.
+func (l *Layer) place(ctrl, last *Control, x int) {
 	margin := l.Style.Margin
-	if len(l.Controls) == 0 {
-		at := xgal.Pt(ctrl.Bounds.Min.X+margin.X, ctrl.Bounds.Min.Y+margin.Y)
+	if last == nil {
+		at := xgal.Pt(x+margin.X, ctrl.Bounds.Min.Y+margin.Y)
+		ctrl.MoveTo(at)
+	} else if l.Orientation == Horizontal && last.Bounds.Dx()+ctrl.Bounds.Dx() < l.Bounds.Dx() {
+		// fits on the line
+		at := xgal.Pt(last.Bounds.Max.X+margin.X, last.Bounds.Min.Y)
 		ctrl.MoveTo(at)
 	} else {
-		last := l.Controls[len(l.Controls)-1]
-		if l.Orientation == Horizontal && last.Bounds.Dx()+ctrl.Bounds.Dx() < l.Bounds.Dx() {
-			// fits on the line
-			at := xgal.Pt(last.Bounds.Max.X+margin.X, last.Bounds.Min.Y)
-			ctrl.MoveTo(at)
-		} else {
-			at := xgal.Pt(ctrl.Bounds.Min.X+margin.X, last.Bounds.Max.Y+margin.Y)
-			ctrl.MoveTo(at)
-		}
+		at := xgal.Pt(x+margin.X, last.Bounds.Max.Y+margin.Y)
+		ctrl.MoveTo(at)
 	}
-	l.Controls = append(l.Controls, ctrl)
-	return ctrl
 }
 
 func (l *Layer) Label(text string) *Control {
@@ -160,7 +178,9 @@ func (l *Layer) OnClick(at xgal.Point, button int) Reply {
 
 	for ctrl := range l.ControlsAt(at) {
 		if ctrl.Class.Click != nil {
+			_, was := ctrl.Said()
 			res := ctrl.Class.Click(at, button)
+			ctrl.speakChange(was)
 			if res != Ignore {
 				ctrl.State.Clicked = true
 				l.Clicked = ctrl
@@ -183,7 +203,9 @@ func (l *Layer) OnWheel(at xgal.Point, button int) Reply {
 
 	for ctrl := range l.ControlsAt(at) {
 		if ctrl.Class.Wheel != nil {
+			_, was := ctrl.Said()
 			res := ctrl.Class.Wheel(at, button)
+			ctrl.speakChange(was)
 			if res != Ignore {
 				return res
 			}
@@ -294,10 +316,16 @@ func (l *Layer) OnTap(key int, mods Mods) Reply {
 		}
 	}
 
-	if l.Focused != nil {
-		if l.Focused.Class.Tap != nil {
-			return l.Focused.Class.Tap(key, mods)
+	// Keys go to the focused control only, so the arrow keys that it
+	// ignores can move the focus rather than change another control.
+	if ctrl := l.Focused; ctrl != nil {
+		if ctrl.Class.Tap != nil {
+			_, was := ctrl.Said()
+			res := ctrl.Class.Tap(key, mods)
+			ctrl.speakChange(was)
+			return res
 		}
+		return Ignore
 	}
 
 	for ctrl := range l.AllControlsWhere(func(ctrl *Control) bool {
@@ -394,6 +422,7 @@ func NewAsker(bounds xgal.Rectangle, label, entry string, buttons ...string) *La
 	asker.Label(label)
 	asker.Orientation = Vertical
 	e := asker.Entry(entry)
+	e.Label = label
 
 	e.Class.Entry = func(entry string) Reply {
 		if asker.Class.Entry != nil {
@@ -611,6 +640,7 @@ func NewComplainer(bounds xgal.Rectangle, err error) *Layer {
 func (u *UI) Complain(bounds xgal.Rectangle, err error) *Layer {
 	layer := NewComplainer(bounds, err)
 	u.Layers = append(u.Layers, layer)
+	u.FadeIn(layer)
 	return layer
 }
 
@@ -641,6 +671,7 @@ func (u *UI) Display(bounds xgal.Rectangle, text string) *Layer {
 	layer := NewDisplayer(bounds, text)
 	u.Layers = append(u.Layers, layer)
 	u.SetFocus(layer)
+	u.FadeIn(layer)
 	return layer
 }
 
@@ -0,0 +1,625 @@
+package xlui
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+)
+
+import "github.com/xmasengine/xmas/xgal"
+
+// Layout describes the layers of a screen and their controls, so screens
+// can be designed in XML rather than placed by hand in Go:
+//
+//	<layout>
+//		<layer id="options" x="10" y="10" w="120" h="60" focus="name">
+//			<label key="options.name">Name</label>
+//			<entry id="name" newline="true" fill="true" handler="options.name"/>
+//			<button newline="true" handler="options.cancel">Cancel</button>
+//			<button handler="options.ok">OK</button>
+//		</layer>
+//		<list id="saves" x="130" y="10" w="80" h="60" handler="options.save">
+//			<item>one</item>
+//			<item>two</item>
+//		</list>
+//	</layout>
+//
+// The layers and lists are in bottom to top order, and the controls are
+// laid out in order by [Layer.Append]. Positions and sizes are scaled by
+// [xgal.UIScale].
+type Layout struct {
+	XMLName xml.Name      `xml:"layout"`
+	Layers  []LayerLayout `xml:",any"`
+}
+
+// LayerLayout describes a layer, or a list if its element is list.
+type LayerLayout struct {
+	XMLName     xml.Name
+	ID          string          `xml:"id,attr"`
+	X           int             `xml:"x,attr"`
+	Y           int             `xml:"y,attr"`
+	W           int             `xml:"w,attr"`
+	H           int             `xml:"h,attr"`
+	Orientation string          `xml:"orientation,attr,omitempty"`
+	Style       string          `xml:"style,attr,omitempty"`
+	Handler     string          `xml:"handler,attr,omitempty"`
+	Lock        bool            `xml:"lock,attr,omitempty"`
+	Focus       string          `xml:"focus,attr,omitempty"`    // Focus is the id of the focused control.
+	Selected    *int            `xml:"selected,attr,omitempty"` // Selected item of a list.
+	Items       []string        `xml:"item"`                    // Items of a list.
+	Controls    []ControlLayout `xml:",any"`
+}
+
+// ControlLayout describes a control. The element is the kind of control,
+// and the text of the element is the text of the control.
+type ControlLayout struct {
+	XMLName     xml.Name
+	ID          string `xml:"id,attr,omitempty"`
+	Text        string `xml:",chardata"`
+	Key         string `xml:"key,attr,omitempty"` // Key in the string table.
+	Style       string `xml:"style,attr,omitempty"`
+	Handler     string `xml:"handler,attr,omitempty"`
+	Newline     bool   `xml:"newline,attr,omitempty"`     // Newline starts the control on a new line.
+	W           int    `xml:"w,attr,omitempty"`           // W overrides the width.
+	Fill        bool   `xml:"fill,attr,omitempty"`        // Fill widens to the end of the layer.
+	Orientation string `xml:"orientation,attr,omitempty"` // Of sliders and bars.
+	Value       int    `xml:"value,attr,omitempty"`
+	Low         int    `xml:"low,attr,omitempty"`
+	High        int    `xml:"high,attr,omitempty"`
+	Checked     bool   `xml:"checked,attr,omitempty"`
+	Group       string `xml:"group,attr,omitempty"`   // Group of toggles.
+	Lines       int    `xml:"lines,attr,omitempty"`   // Lines of areas and talks.
+	Image       string `xml:"image,attr,omitempty"`   // Image of choosers and frames.
+	TW          int    `xml:"tw,attr,omitempty"`      // Tile width of choosers.
+	TH          int    `xml:"th,attr,omitempty"`      // Tile height of choosers.
+	Mask        string `xml:"mask,attr,omitempty"`    // Mask of entries and areas, see [xgal.Masks].
+	Max         int    `xml:"max,attr,omitempty"`     // Max length of entries and areas.
+	Numbers     bool   `xml:"numbers,attr,omitempty"` // Numbers shows line numbers in areas.
+}
+
+// ReadLayout reads a layout from XML.
+func ReadLayout(rd io.Reader) (*Layout, error) {
+	layout := &Layout{}
+	dec := xml.NewDecoder(rd)
+	if err := dec.Decode(layout); err != nil {
+		return nil, err
+	}
+	return layout, nil
+}
+
+// LoadLayout loads the named layout from fsys.
+func LoadLayout(fsys fs.FS, name string) (*Layout, error) {
+	fin, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer fin.Close()
+	layout, err := ReadLayout(fin)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return layout, nil
+}
+
+// Handler returns the handlers that a layout binds to a control or layer
+// by name. The control is nil for layers. The handlers of the returned
+// Class that are set are called after those of the control or layer
+// itself, and their reply is used. Other controls of the view can be
+// found with [View.Control]. Note that a layer that handles clicks, keys
+// or ticks itself does not pass them on to its controls, so layers mostly
+// bind Value and Entry.
+type Handler func(view *View, layer *Layer, ctrl *Control) Class
+
+// handlers are the handlers by name.
+var handlers = map[string]Handler{}
+
+// Handle registers the handler under the name that layouts bind it by.
+func Handle(name string, handler Handler) {
+	handlers[name] = handler
+}
+
+// styles are the styles that layouts use by name.
+var styles = map[string]func() Style{
+	"default": DefaultStyle,
+	"button":  ButtonStyle,
+	"menu":    MenuStyle,
+	"bar":     BarStyle,
+	"check":   CheckStyle,
+	"hud":     HUDBarStyle,
+	"error":   func() Style { return DefaultStyle().Error() },
+}
+
+// RegisterStyle makes the style available to layouts by name. The style
+// takes the name, so a theme can give it a look and [Style.Restyle] can
+// make it anew.
+func RegisterStyle(name string, style func() Style) {
+	styles[name] = func() Style {
+		s := style()
+		s.Name = name
+		return s.themed("")
+	}
+}
+
+// Bind adds the handlers of other that are set to those of c. Handlers
+// that have a reply are called after the existing ones, and the reply of
+// other is used. Other handlers are replaced.
+func (c *Class) Bind(other Class) {
+	if f := other.Render; f != nil {
+		c.Render = f
+	}
+	if f := other.MoveBy; f != nil {
+		c.MoveBy = f
+	}
+	if f := other.Set; f != nil {
+		c.Set = f
+	}
+	if f := other.Relabel; f != nil {
+		c.Relabel = f
+	}
+	if f, old := other.Hover, c.Hover; f != nil {
+		c.Hover = func(at xgal.Point) Reply {
+			if old != nil {
+				old(at)
+			}
+			return f(at)
+		}
+	}
+	if f, old := other.Click, c.Click; f != nil {
+		c.Click = func(at xgal.Point, button int) Reply {
+			if old != nil {
+				old(at, button)
+			}
+			return f(at, button)
+		}
+	}
+	if f, old := other.Release, c.Release; f != nil {
+		c.Release = func(at xgal.Point, button int) Reply {
+			if old != nil {
+				old(at, button)
+			}
+			return f(at, button)
+		}
+	}
+	if f, old := other.Wheel, c.Wheel; f != nil {
+		c.Wheel = func(at xgal.Point, delta int) Reply {
+			if old != nil {
+				old(at, delta)
+			}
+			return f(at, delta)
+		}
+	}
+	if f, old := other.Key, c.Key; f != nil {
+		c.Key = func(key int, duration int) Reply {
+			if old != nil {
+				old(key, duration)
+			}
+			return f(key, duration)
+		}
+	}
+	if f, old := other.Tap, c.Tap; f != nil {
+		c.Tap = func(key int, mods Mods) Reply {
+			if old != nil {
+				old(key, mods)
+			}
+			return f(key, mods)
+		}
+	}
+	if f, old := other.Lift, c.Lift; f != nil {
+		c.Lift = func(key int, mods Mods) Reply {
+			if old != nil {
+				old(key, mods)
+			}
+			return f(key, mods)
+		}
+	}
+	if f, old := other.Entry, c.Entry; f != nil {
+		c.Entry = func(text string) Reply {
+			if old != nil {
+				old(text)
+			}
+			return f(text)
+		}
+	}
+	if f, old := other.Value, c.Value; f != nil {
+		c.Value = func(value int) Reply {
+			if old != nil {
+				old(value)
+			}
+			return f(value)
+		}
+	}
+	if f, old := other.Tick, c.Tick; f != nil {
+		c.Tick = func(tick int64) Reply {
+			if old != nil {
+				old(tick)
+			}
+			return f(tick)
+		}
+	}
+	if f, old := other.Chars, c.Chars; f != nil {
+		c.Chars = func(chars ...rune) Reply {
+			if old != nil {
+				old(chars...)
+			}
+			return f(chars...)
+		}
+	}
+}
+
+// View is a layout built into layers and controls.
+type View struct {
+	Layout *Layout
+	Layers []*Layer // Layers in bottom to top order.
+	FS     fs.FS    // FS the layout and its images are loaded from.
+	Name   string   // Name of the layout file, if loaded from one.
+
+	layers   map[string]*Layer
+	lists    map[string]*ListLayer
+	controls map[string]*Control
+	groups   map[string]*Group
+}
+
+// Layer returns the layer with the id, or nil if there is none.
+func (v *View) Layer(id string) *Layer {
+	return v.layers[id]
+}
+
+// List returns the list with the id, or nil if there is none.
+func (v *View) List(id string) *ListLayer {
+	return v.lists[id]
+}
+
+// Control returns the control with the id, or nil if there is none.
+func (v *View) Control(id string) *Control {
+	return v.controls[id]
+}
+
+// Translate translates the texts of the controls of the view that have a
+// key.
+func (v *View) Translate(tr func(key string) string) {
+	for _, layer := range v.Layers {
+		layer.Translate(tr)
+	}
+}
+
+func parseOrientation(name string) (Orientation, error) {
+	switch name {
+	case "", "horizontal":
+		return Horizontal, nil
+	case "vertical":
+		return Vertical, nil
+	}
+	return Horizontal, fmt.Errorf("unknown orientation %q", name)
+}
+
+func findStyle(name string) (Style, error) {
+	style, ok := styles[name]
+	if !ok {
+		return Style{}, fmt.Errorf("unknown style %q", name)
+	}
+	return style(), nil
+}
+
+// setupEdit sets the mask, maximum length and line numbers of the text
+// edit of an entry or area.
+func setupEdit(ctrl *Control, lc ControlLayout) (*Control, error) {
+	edit := ctrl.TextEdit()
+	if lc.Mask != "" {
+		mask, ok := xgal.Masks[lc.Mask]
+		if !ok {
+			return nil, fmt.Errorf("unknown mask %q", lc.Mask)
+		}
+		edit.Mask = mask
+	}
+	edit.Max = lc.Max
+	edit.Numbers = lc.Numbers
+	return ctrl, nil
+}
+
+// controlMaker makes a control of a kind at the start of the layer.
+type controlMaker func(v *View, at xgal.Point, lc ControlLayout) (*Control, error)
+
+var controlMakers = map[string]controlMaker{
+	"label": func(v *View, at xgal.Point, lc ControlLayout) (*Control, error) {
+		return NewLabel(at, lc.Text), nil
+	},
+	"button": func(v *View, at xgal.Point, lc ControlLayout) (*Control, error) {
+		return NewButton(at, lc.Text), nil
+	},
+	"entry": func(v *View, at xgal.Point, lc ControlLayout) (*Control, error) {
+		return setupEdit(NewEntry(at, lc.Text), lc)
+	},
+	"area": func(v *View, at xgal.Point, lc ControlLayout) (*Control, error) {
+		return setupEdit(NewArea(at, lc.Text, max(lc.Lines, 1)), lc)
+	},
+	"talk": func(v *View, at xgal.Point, lc ControlLayout) (*Control, error) {
+		return NewTalk(at, lc.Text, max(lc.Lines, 1)), nil
+	},
+	"checkbox": func(v *View, at xgal.Point, lc ControlLayout) (*Control, error) {
+		return NewCheckbox(at, lc.Checked), nil
+	},
+	"toggle": func(v *View, at xgal.Point, lc ControlLayout) (*Control, error) {
+		var group *Group
+		if lc.Group != "" {
+			group = v.groups[lc.Group]
+			if group == nil {
+				group = &Group{}
+				v.groups[lc.Group] = group
+			}
+		}
+		toggle := NewToggle(at, lc.Text, group)
+		toggle.Checked = lc.Checked
+		if group != nil {
+			group.Controls = append(group.Controls, toggle)
+		}
+		return toggle, nil
+	},
+	"slider": func(v *View, at xgal.Point, lc ControlLayout) (*Control, error) {
+		orientation, err := parseOrientation(lc.Orientation)
+		if err != nil {
+			return nil, err
+		}
+		return NewSlider(at, orientation, lc.Low, lc.High, lc.Value), nil
+	},
+	"bar": func(v *View, at xgal.Point, lc ControlLayout) (*Control, error) {
+		orientation, err := parseOrientation(lc.Orientation)
+		if err != nil {
+			return nil, err
+		}
+		return NewBar(at, orientation, lc.Value, lc.High), nil
+	},
+	"chooser": func(v *View, at xgal.Point, lc ControlLayout) (*Control, error) {
+		img, err := xgal.Texture(v.FS, lc.Image)
+		if err != nil {
+			return nil, err
+		}
+		return NewChooser(at, img, xgal.Pt(max(lc.TW, 1), max(lc.TH, 1))), nil
+	},
+	"frame": func(v *View, at xgal.Point, lc ControlLayout) (*Control, error) {
+		img, err := xgal.Texture(v.FS, lc.Image)
+		if err != nil {
+			return nil, err
+		}
+		return NewFrame(at, img), nil
+	},
+}
+
+// textSized are the kinds of controls that are as large as their text.
+var textSized = map[string]bool{"label": true, "button": true, "toggle": true}
+
+// binding is a handler to bind once all controls are built.
+type binding struct {
+	handler Handler
+	layer   *Layer
+	ctrl    *Control
+}
+
+func findHandler(name string) (Handler, error) {
+	handler, ok := handlers[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown handler %q", name)
+	}
+	return handler, nil
+}
+
+// buildControl builds the control and appends it to the layer.
+func (v *View) buildControl(layer *Layer, lc ControlLayout, bindings *[]binding) error {
+	kind := lc.XMLName.Local
+	maker, ok := controlMakers[kind]
+	if !ok {
+		return fmt.Errorf("unknown control %q", kind)
+	}
+	lc.Text = strings.TrimSpace(lc.Text)
+	ctrl, err := maker(v, layer.Bounds.Min, lc)
+	if err != nil {
+		return fmt.Errorf("%s: %w", kind, err)
+	}
+	ctrl.Key = lc.Key
+	if lc.Style != "" {
+		if ctrl.Style, err = findStyle(lc.Style); err != nil {
+			return fmt.Errorf("%s: %w", kind, err)
+		}
+		if textSized[kind] {
+			ctrl.SetText(ctrl.Text)
+		}
+	}
+	if lc.W > 0 {
+		ctrl.Bounds.Max.X = ctrl.Bounds.Min.X + xgal.UILength(lc.W)
+	}
+
+	orientation := layer.Orientation
+	if lc.Newline {
+		layer.Orientation = Vertical
+	}
+	layer.Append(ctrl)
+	layer.Orientation = orientation
+	if lc.Fill {
+		ctrl.Bounds.Max.X = layer.Bounds.Max.X - layer.Style.Margin.X
+	}
+
+	if lc.ID != "" {
+		if v.controls[lc.ID] != nil {
+			return fmt.Errorf("%s: duplicate id %q", kind, lc.ID)
+		}
+		v.controls[lc.ID] = ctrl
+	}
+	if lc.Handler != "" {
+		handler, err := findHandler(lc.Handler)
+		if err != nil {
+			return fmt.Errorf("%s: %w", kind, err)
+		}
+		*bindings = append(*bindings, binding{handler, layer, ctrl})
+	}
+	return nil
+}
+
+// buildLayer builds the layer or list and its controls.
+func (v *View) buildLayer(ll LayerLayout, bindings *[]binding) (*Layer, error) {
+	kind := ll.XMLName.Local
+	bounds := xgal.UIRect(xgal.Bound(ll.X, ll.Y, ll.W, ll.H))
+	var layer *Layer
+	switch kind {
+	case "layer":
+		layer = NewLayer(bounds)
+	case "list":
+		list := NewList(bounds, ll.Items...)
+		if ll.Selected != nil {
+			list.Select(*ll.Selected)
+		}
+		if ll.ID != "" {
+			v.lists[ll.ID] = list
+		}
+		layer = &list.Layer
+	default:
+		return nil, fmt.Errorf("unknown layer %q", kind)
+	}
+	layer.Lock = ll.Lock
+	var err error
+	if layer.Orientation, err = parseOrientation(ll.Orientation); err != nil {
+		return nil, err
+	}
+	if ll.Style != "" {
+		if layer.Style, err = findStyle(ll.Style); err != nil {
+			return nil, err
+		}
+	}
+	if ll.ID != "" {
+		if v.layers[ll.ID] != nil {
+			return nil, fmt.Errorf("duplicate id %q", ll.ID)
+		}
+		v.layers[ll.ID] = layer
+	}
+	if ll.Handler != "" {
+		handler, err := findHandler(ll.Handler)
+		if err != nil {
+			return nil, err
+		}
+		*bindings = append(*bindings, binding{handler, layer, nil})
+	}
+
+	for _, lc := range ll.Controls {
+		if err := v.buildControl(layer, lc, bindings); err != nil {
+			return nil, err
+		}
+	}
+	if ll.Focus != "" {
+		ctrl := v.controls[ll.Focus]
+		if ctrl == nil {
+			return nil, fmt.Errorf("unknown focus %q", ll.Focus)
+		}
+		layer.SetFocus(ctrl)
+	}
+	return layer, nil
+}
+
+// Build builds the layers and controls of the layout, and binds the
+// handlers to them once all of them are built. Images of choosers and
+// frames are loaded from fsys.
+func (lo *Layout) Build(fsys fs.FS) (*View, error) {
+	v := &View{Layout: lo, FS: fsys,
+		layers:   map[string]*Layer{},
+		lists:    map[string]*ListLayer{},
+		controls: map[string]*Control{},
+		groups:   map[string]*Group{},
+	}
+	var bindings []binding
+	for i, ll := range lo.Layers {
+		layer, err := v.buildLayer(ll, &bindings)
+		if err != nil {
+			if ll.ID != "" {
+				return nil, fmt.Errorf("%s %s: %w", ll.XMLName.Local, ll.ID, err)
+			}
+			return nil, fmt.Errorf("%s %d: %w", ll.XMLName.Local, i, err)
+		}
+		v.Layers = append(v.Layers, layer)
+	}
+	for _, b := range bindings {
+		class := b.handler(v, b.layer, b.ctrl)
+		if b.ctrl != nil {
+			b.ctrl.Class.Bind(class)
+		} else {
+			b.layer.Class.Bind(class)
+		}
+	}
+	return v, nil
+}
+
+// LoadView loads the named layout from fsys and builds it.
+func LoadView(fsys fs.FS, name string) (*View, error) {
+	layout, err := LoadLayout(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	v, err := layout.Build(fsys)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	v.Name = name
+	return v, nil
+}
+
+// Show appends the layers of the view to the UI.
+func (u *UI) Show(v *View) {
+	for _, layer := range v.Layers {
+		u.Append(layer)
+	}
+}
+
+// Load loads the named layout from fsys, builds it and shows it.
+func (u *UI) Load(fsys fs.FS, name string) (*View, error) {
+	v, err := LoadView(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	u.Show(v)
+	return v, nil
+}
+
+// Reload loads the layout of the view again, for example after it was
+// changed on disk, and replaces the layers of the view in the UI. The view
+// is left as it was if the layout does not load.
+func (u *UI) Reload(v *View) error {
+	fresh, err := LoadView(v.FS, v.Name)
+	if err != nil {
+		return err
+	}
+	var added []*Layer
+	for i, layer := range fresh.Layers {
+		idx := -1
+		if i < len(v.Layers) {
+			idx = u.LayerIndex(v.Layers[i])
+		}
+		if idx < 0 {
+			added = append(added, layer)
+			continue
+		}
+		u.Layers[idx] = layer
+		if u.Focused == v.Layers[i] {
+			u.SetFocus(layer)
+		}
+		if u.Dragged == v.Layers[i] {
+			u.Dragged = nil
+		}
+	}
+	for i := len(fresh.Layers); i < len(v.Layers); i++ {
+		u.CloseLayer(v.Layers[i])
+	}
+	for _, layer := range added {
+		u.Append(layer)
+	}
+	*v = *fresh
+	return nil
+}
+
+// Load loads the named layout from fsys, builds it and shows it in the
+// global UI.
+func Load(fsys fs.FS, name string) (*View, error) {
+	return xlui.Load(fsys, name)
+}
+
+// Reload reloads the view in the global UI.
+func Reload(v *View) error {
+	return xlui.Reload(v)
+}
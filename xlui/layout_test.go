@@ -0,0 +1,173 @@
+package xlui
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+import "github.com/xmasengine/xmas/xgal"
+
+const testLayout = `<layout>
+	<layer id="options" x="10" y="10" w="200" h="80" focus="name" handler="test.options">
+		<label key="options.name">Name</label>
+		<entry id="name" newline="true" fill="true">Santa</entry>
+		<checkbox id="snow" newline="true" checked="true"/>
+		<toggle id="red" group="color" checked="true">Red</toggle>
+		<toggle id="green" group="color">Green</toggle>
+		<slider id="volume" newline="true" low="0" high="10" value="3"/>
+		<bar id="health" orientation="vertical" value="5" high="20" style="hud"/>
+		<button id="ok" newline="true" handler="test.ok">OK</button>
+	</layer>
+	<list id="saves" x="10" y="100" w="80" h="60" selected="1">
+		<item>one</item>
+		<item>two</item>
+	</list>
+</layout>`
+
+func TestLayoutBuild(t *testing.T) {
+	var okView *View
+	clicked := 0
+	Handle("test.ok", func(v *View, l *Layer, ctrl *Control) Class {
+		okView = v
+		return Class{Click: func(at xgal.Point, button int) Reply {
+			clicked++
+			return Finish
+		}}
+	})
+	Handle("test.options", func(v *View, l *Layer, ctrl *Control) Class {
+		if ctrl != nil {
+			t.Errorf("layer handler with control %v", ctrl)
+		}
+		return Class{Value: func(value int) Reply { return Accept }}
+	})
+	layout, err := ReadLayout(strings.NewReader(testLayout))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := layout.Build(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(v.Layers) != 2 || v.Layer("options") != v.Layers[0] || &v.List("saves").Layer != v.Layers[1] {
+		t.Fatalf("layers: %v", v.Layers)
+	}
+	options := v.Layer("options")
+	if len(options.Controls) != 8 || options.Class.Value == nil {
+		t.Fatalf("options: %d controls", len(options.Controls))
+	}
+	name := v.Control("name")
+	if name.Text != "Santa" || options.Focused != name {
+		t.Errorf("name: %q focused %v", name.Text, options.Focused == name)
+	}
+	if name.Bounds.Max.X != options.Bounds.Max.X-options.Style.Margin.X {
+		t.Errorf("name does not fill: %v in %v", name.Bounds, options.Bounds)
+	}
+	label := options.Controls[0]
+	if label.Key != "options.name" || name.Bounds.Min.Y < label.Bounds.Max.Y {
+		t.Errorf("label: %+v, name at %v", label.Bounds, name.Bounds)
+	}
+	if !v.Control("snow").Checked || !v.Control("red").Checked || v.Control("green").Checked {
+		t.Errorf("checked: %v %v %v", v.Control("snow").Checked, v.Control("red").Checked, v.Control("green").Checked)
+	}
+	green := v.Control("green")
+	green.Class.Click(green.Bounds.Min, 0)
+	if v.Control("red").Checked || !green.Checked {
+		t.Errorf("toggles are not grouped")
+	}
+	if volume := v.Control("volume"); volume.Low != 0 || volume.High != 10 || volume.Value != 3 {
+		t.Errorf("volume: %d %d %d", volume.Low, volume.High, volume.Value)
+	}
+	if health := v.Control("health"); health.Orientation != Vertical || health.Style != HUDBarStyle() || health.High != 20 {
+		t.Errorf("health: %v %v", health.Orientation, health.High)
+	}
+
+	ok := v.Control("ok")
+	if res := ok.Class.Click(ok.Bounds.Min, 0); res != Finish || clicked != 1 || !ok.State.Clicked {
+		t.Errorf("ok click: %v %d %v", res, clicked, ok.State.Clicked)
+	}
+	if okView != v {
+		t.Errorf("handler got another view")
+	}
+	if saves := v.List("saves"); saves.Selected != 1 || len(saves.Items) != 2 {
+		t.Errorf("saves: %d %v", saves.Selected, saves.Items)
+	}
+}
+
+func TestLayoutErrors(t *testing.T) {
+	for _, bad := range []string{
+		`<layout><layer><dial/></layer></layout>`,
+		`<layout><window/></layout>`,
+		`<layout><layer><button handler="test.missing">X</button></layer></layout>`,
+		`<layout><layer style="fancy"/></layout>`,
+		`<layout><layer><slider orientation="diagonal"/></layer></layout>`,
+		`<layout><layer><label id="a"/><label id="a"/></layer></layout>`,
+		`<layout><layer focus="nothing"/></layout>`,
+	} {
+		layout, err := ReadLayout(strings.NewReader(bad))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := layout.Build(nil); err == nil {
+			t.Errorf("no error for %s", bad)
+		}
+	}
+	if _, err := ReadLayout(strings.NewReader(`<screen/>`)); err == nil {
+		t.Errorf("no error for a screen")
+	}
+}
+
+func TestClassBind(t *testing.T) {
+	var calls []string
+	class := Class{Value: func(value int) Reply {
+		calls = append(calls, "own")
+		return Accept
+	}}
+	class.Bind(Class{
+		Value: func(value int) Reply {
+			calls = append(calls, "bound")
+			return Finish
+		},
+		Entry: func(text string) Reply { return Accept },
+	})
+	if res := class.Value(1); res != Finish || strings.Join(calls, " ") != "own bound" {
+		t.Errorf("value: %v %v", res, calls)
+	}
+	if class.Entry == nil || class.Click != nil {
+		t.Errorf("bind set the wrong handlers")
+	}
+}
+
+func TestReload(t *testing.T) {
+	fsys := fstest.MapFS{"ui/main.xml": {Data: []byte(`<layout>
+		<layer id="main" x="0" y="0" w="100" h="40"><label>Before</label></layer>
+		<layer id="extra" x="0" y="50" w="100" h="40"/>
+	</layout>`)}}
+	u := &UI{}
+	u.Append(testLayer())
+	v, err := u.Load(fsys, "ui/main.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(u.Layers) != 3 || u.Focused != v.Layer("extra") {
+		t.Fatalf("layers: %d", len(u.Layers))
+	}
+
+	fsys["ui/main.xml"] = &fstest.MapFile{Data: []byte(`<layout>
+		<layer id="main" x="0" y="0" w="100" h="40"><label>After</label></layer>
+	</layout>`)}
+	if err := u.Reload(v); err != nil {
+		t.Fatal(err)
+	}
+	if len(u.Layers) != 2 || u.Layers[1] != v.Layer("main") || v.Layer("extra") != nil {
+		t.Fatalf("reloaded layers: %d", len(u.Layers))
+	}
+	if text := v.Layer("main").Controls[0].Text; text != "After" {
+		t.Errorf("text: %q", text)
+	}
+
+	fsys["ui/main.xml"] = &fstest.MapFile{Data: []byte(`<layout><layer><dial/></layer></layout>`)}
+	if err := u.Reload(v); err == nil || u.Layers[1] != v.Layer("main") {
+		t.Errorf("a bad layout replaced the view: %v", err)
+	}
+}
@@ -51,11 +51,15 @@ func (u *UI) List(bounds xgal.Rectangle, items ...string) *ListLayer {
 }
 
 // Select programmatically selects the i-th item
-// and calls Class.Value if available.
+// and calls Class.Value if available. A newly selected item is
+// announced to the speaker.
 func (l *ListLayer) Select(i int) {
 	if i < 0 || i >= len(l.Items) {
 		return
 	}
+	if i != l.Selected {
+		xgal.Announce(xgal.Announcement{Cue: xgal.CueFocus, Value: l.Items[i]})
+	}
 	l.Selected = i
 	l.EnsureVisible()
 	if l.Class.Value != nil {
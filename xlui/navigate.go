@@ -0,0 +1,309 @@
+package xlui
+
+import "github.com/xmasengine/xmas/xgal"
+
+// PadMap maps gamepad buttons and the stick to the keys that the UI
+// navigates by, so gamepads drive the UI like the keyboard does. It maps
+// the standard layout, so the buttons are in the same place on every pad.
+type PadMap struct {
+	Buttons map[xgal.StandardButton]xgal.KeyCode
+	X, Y    xgal.StandardAxis // X and Y are the axes of the stick.
+	Dead    float64           // Dead is how far the stick must be pushed.
+}
+
+// PadKeys is the gamepad mapping of the UI. The default maps the d-pad
+// and the left stick to the arrow keys, A to Enter and B to Escape.
+// Pads without the standard layout do not drive the UI.
+var PadKeys = PadMap{
+	Buttons: map[xgal.StandardButton]xgal.KeyCode{
+		xgal.PadA:     xgal.KeyEnter,
+		xgal.PadB:     xgal.KeyEscape,
+		xgal.PadUp:    xgal.KeyArrowUp,
+		xgal.PadRight: xgal.KeyArrowRight,
+		xgal.PadDown:  xgal.KeyArrowDown,
+		xgal.PadLeft:  xgal.KeyArrowLeft,
+	},
+	X:    xgal.PadLeftX,
+	Y:    xgal.PadLeftY,
+	Dead: 0.5,
+}
+
+// stickKeys are the keys for the directions of the stick.
+var stickKeys = map[xgal.Point]xgal.KeyCode{
+	{X: 0, Y: -1}: xgal.KeyArrowUp,
+	{X: 1, Y: 0}:  xgal.KeyArrowRight,
+	{X: 0, Y: 1}:  xgal.KeyArrowDown,
+	{X: -1, Y: 0}: xgal.KeyArrowLeft,
+}
+
+// stickDirection returns the direction the stick is pushed in, along the
+// axis it is pushed furthest, or 0, 0 if it is within dead.
+func stickDirection(x, y, dead float64) xgal.Point {
+	ax, ay := max(x, -x), max(y, -y)
+	switch {
+	case ax < dead && ay < dead:
+		return xgal.Point{}
+	case ax >= ay && x > 0:
+		return xgal.Pt(1, 0)
+	case ax >= ay:
+		return xgal.Pt(-1, 0)
+	case y > 0:
+		return xgal.Pt(0, 1)
+	}
+	return xgal.Pt(0, -1)
+}
+
+// pollPads taps the keys of the gamepad buttons that were pressed, and of
+// the stick when it is pushed in a new direction.
+func (u *UI) pollPads() Reply {
+	if u.sticks == nil {
+		u.sticks = map[xgal.PadID]xgal.Point{}
+	}
+	accepted := false
+	tap := func(key xgal.KeyCode) {
+		res := u.Tap(key, u.Mods)
+		if res == Accept {
+			accepted = true
+		} else if res != Ignore {
+			u.onReply(u.focusOrTopIndex(), res)
+		}
+	}
+	for _, pad := range xgal.Pads() {
+		if !xgal.Standard(pad) {
+			continue
+		}
+		for button, key := range PadKeys.Buttons {
+			if xgal.StandardNudge(pad, button) {
+				tap(key)
+			}
+		}
+		dir := stickDirection(xgal.StandardTilt(pad, PadKeys.X), xgal.StandardTilt(pad, PadKeys.Y), PadKeys.Dead)
+		if dir != u.sticks[pad] && dir != (xgal.Point{}) {
+			tap(stickKeys[dir])
+		}
+		u.sticks[pad] = dir
+	}
+	if accepted {
+		return Accept
+	}
+	return Ignore
+}
+
+// directions are the directions of the arrow keys.
+var directions = map[xgal.KeyCode]xgal.Point{
+	xgal.KeyArrowUp:    {X: 0, Y: -1},
+	xgal.KeyArrowRight: {X: 1, Y: 0},
+	xgal.KeyArrowDown:  {X: 0, Y: 1},
+	xgal.KeyArrowLeft:  {X: -1, Y: 0},
+}
+
+func center(r xgal.Rectangle) xgal.Point {
+	return r.Min.Add(r.Max).Div(2)
+}
+
+// gap returns the distance between the ranges lo1-hi1 and lo2-hi2, which
+// is 0 if they overlap.
+func gap(lo1, hi1, lo2, hi2 int) int {
+	return max(lo2-hi1, lo1-hi2, 0)
+}
+
+// nearest returns the index of the target that is nearest to from in the
+// direction, or -1 if there is none. Targets that line up with from count
+// as nearer than ones that are off to the side.
+func nearest(from xgal.Rectangle, dir xgal.Point, targets []xgal.Rectangle) int {
+	best, bestScore, bestSide := -1, 0, 0
+	fc := center(from)
+	for i, target := range targets {
+		delta := center(target).Sub(fc)
+		along := delta.X*dir.X + delta.Y*dir.Y
+		if along <= 0 {
+			continue
+		}
+		var side, off int
+		if dir.X != 0 {
+			side = gap(from.Min.Y, from.Max.Y, target.Min.Y, target.Max.Y)
+			off = max(delta.Y, -delta.Y)
+		} else {
+			side = gap(from.Min.X, from.Max.X, target.Min.X, target.Max.X)
+			off = max(delta.X, -delta.X)
+		}
+		score := along + 2*side
+		if best < 0 || score < bestScore || (score == bestScore && off < bestSide) {
+			best, bestScore, bestSide = i, score, off
+		}
+	}
+	return best
+}
+
+// farthest returns the index of the target that is farthest from from
+// against the direction, where focus wraps to, or -1 if there is none.
+func farthest(from xgal.Rectangle, dir xgal.Point, targets []xgal.Rectangle) int {
+	best, bestScore := -1, 0
+	fc := center(from)
+	for i, target := range targets {
+		delta := center(target).Sub(fc)
+		side := max(delta.Y, -delta.Y)
+		if dir.Y != 0 {
+			side = max(delta.X, -delta.X)
+		}
+		score := delta.X*dir.X + delta.Y*dir.Y + side
+		if best < 0 || score < bestScore {
+			best, bestScore = i, score
+		}
+	}
+	return best
+}
+
+// target is a control that can take the focus, or a layer without
+// controls that takes keys itself, like a list.
+type target struct {
+	layer *Layer
+	ctrl  *Control
+}
+
+func (t target) bounds() xgal.Rectangle {
+	if t.ctrl != nil {
+		return t.ctrl.Bounds
+	}
+	return t.layer.Bounds
+}
+
+// Focusable returns whether the control can take the focus, that is,
+// whether it handles clicks or keys.
+func (c *Control) Focusable() bool {
+	return c.Class.Click != nil || c.Class.Tap != nil
+}
+
+// targets returns the targets of the layer.
+func (l *Layer) targets() []target {
+	if len(l.Controls) == 0 {
+		if l.Class.Tap != nil {
+			return []target{{layer: l}}
+		}
+		return nil
+	}
+	var found []target
+	for _, ctrl := range l.Controls {
+		if ctrl.Focusable() {
+			found = append(found, target{layer: l, ctrl: ctrl})
+		}
+	}
+	return found
+}
+
+func (u *UI) focusTarget(t target) {
+	u.SetFocus(t.layer)
+	if t.ctrl != nil {
+		t.layer.SetFocus(t.ctrl)
+	}
+}
+
+// pick returns the target chosen from the bounds of the targets by
+// choose, and false if it chose none.
+func pick(targets []target, choose func([]xgal.Rectangle) int) (target, bool) {
+	rects := make([]xgal.Rectangle, len(targets))
+	for i, t := range targets {
+		rects[i] = t.bounds()
+	}
+	if i := choose(rects); i >= 0 {
+		return targets[i], true
+	}
+	return target{}, false
+}
+
+// Navigate moves the focus to the nearest control in the direction: in
+// the focused layer, else in the other layers, else it wraps around to the
+// farthest control the other way. If nothing has the focus yet, the first
+// control of the focused or top layer gets it.
+func (u *UI) Navigate(dir xgal.Point) Reply {
+	top := u.focusOrTop()
+	if top == nil {
+		return Ignore
+	}
+	own := top.targets()
+	from := top.Bounds
+	if top.Focused != nil {
+		from = top.Focused.Bounds
+	} else if len(top.Controls) > 0 {
+		if len(own) == 0 {
+			return Ignore
+		}
+		u.focusTarget(own[0])
+		return Accept
+	}
+
+	var others []target
+	for i := len(u.Layers) - 1; i >= 0; i-- {
+		if layer := u.Layers[i]; layer != top {
+			others = append(others, layer.targets()...)
+		}
+	}
+	var all []target
+	for _, t := range append(own, others...) {
+		if t.ctrl != top.Focused || t.layer != top {
+			all = append(all, t)
+		}
+	}
+	closest := func(rects []xgal.Rectangle) int { return nearest(from, dir, rects) }
+	if t, ok := pick(own, closest); ok {
+		u.focusTarget(t)
+		return Accept
+	}
+	if t, ok := pick(others, closest); ok {
+		u.focusTarget(t)
+		return Accept
+	}
+	if t, ok := pick(all, func(rects []xgal.Rectangle) int { return farthest(from, dir, rects) }); ok {
+		u.focusTarget(t)
+		return Accept
+	}
+	return Ignore
+}
+
+// Confirm clicks the focused control of the focused layer, for controls
+// that do not handle Enter themselves.
+func (u *UI) Confirm() Reply {
+	top := u.focusOrTop()
+	if top == nil || top.Focused == nil || top.Focused.Class.Click == nil {
+		return Ignore
+	}
+	at := center(top.Focused.Bounds)
+	res := top.OnClick(at, 0)
+	top.OnRelease(at, 0)
+	return res
+}
+
+// Cancel takes the focus from the focused control of the focused or top
+// layer, or else from the focused layer. Layers that close on cancel, such
+// as dialogs, handle Escape themselves. Once nothing has the focus,
+// cancel is ignored, so the game can handle it.
+func (u *UI) Cancel() Reply {
+	top := u.focusOrTop()
+	switch {
+	case top != nil && top.Focused != nil:
+		top.SetFocus(nil)
+	case u.Focused != nil:
+		u.SetFocus(nil)
+	default:
+		return Ignore
+	}
+	return Accept
+}
+
+// navigate handles the keys that the focused layer ignored: the arrow
+// keys navigate, Enter confirms and Escape cancels.
+func (u *UI) navigate(key xgal.KeyCode, mods Mods) Reply {
+	if mods.Alt || mods.Control || mods.Meta {
+		return Ignore
+	}
+	if dir, ok := directions[key]; ok {
+		return u.Navigate(dir)
+	}
+	switch key {
+	case xgal.KeyEnter:
+		return u.Confirm()
+	case xgal.KeyEscape:
+		return u.Cancel()
+	}
+	return Ignore
+}
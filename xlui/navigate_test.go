@@ -0,0 +1,152 @@
+package xlui
+
+import (
+	"testing"
+
+	"github.com/xmasengine/xmas/xgal"
+)
+
+// testTarget returns a focusable control at bounds.
+func testTarget(l *Layer, bounds xgal.Rectangle) *Control {
+	ctrl := NewControl(bounds.Min)
+	ctrl.Bounds = bounds
+	ctrl.Class.Click = func(at xgal.Point, button int) Reply { return Accept }
+	l.Controls = append(l.Controls, ctrl)
+	return ctrl
+}
+
+func TestNavigateGrid(t *testing.T) {
+	u := &UI{}
+	l := u.Append(NewLayer(xgal.Rect(0, 0, 100, 100)))
+	l.Label("Not focusable")
+	topLeft := testTarget(l, xgal.Rect(10, 10, 30, 20))
+	topRight := testTarget(l, xgal.Rect(50, 10, 70, 20))
+	bottomLeft := testTarget(l, xgal.Rect(10, 40, 30, 50))
+	bottomRight := testTarget(l, xgal.Rect(50, 40, 70, 50))
+
+	for i, step := range []struct {
+		key  xgal.KeyCode
+		want *Control
+	}{
+		{xgal.KeyArrowDown, topLeft}, // The first press focuses the first control.
+		{xgal.KeyArrowRight, topRight},
+		{xgal.KeyArrowDown, bottomRight},
+		{xgal.KeyArrowLeft, bottomLeft},
+		{xgal.KeyArrowUp, topLeft},
+		{xgal.KeyArrowUp, bottomLeft}, // Wraps around.
+	} {
+		if res := u.Tap(step.key, Mods{}); res != Accept {
+			t.Errorf("step %d: reply %v", i, res)
+		}
+		if l.Focused != step.want {
+			t.Fatalf("step %d: focused %v, want %v", i, l.Focused.Bounds, step.want.Bounds)
+		}
+		if !step.want.State.Focused {
+			t.Errorf("step %d: state not focused", i)
+		}
+	}
+}
+
+func TestNavigateLayers(t *testing.T) {
+	u := &UI{}
+	left := u.Append(NewLayer(xgal.Rect(0, 0, 50, 50)))
+	first := testTarget(left, xgal.Rect(5, 5, 20, 15))
+	right := u.Append(NewLayer(xgal.Rect(60, 0, 110, 50)))
+	second := testTarget(right, xgal.Rect(65, 5, 80, 15))
+	list := NewList(xgal.Rect(0, 60, 50, 100), "a", "b")
+	u.Append(&list.Layer)
+
+	u.SetFocus(left)
+	left.SetFocus(first)
+	u.Tap(xgal.KeyArrowRight, Mods{})
+	if u.Focused != right || right.Focused != second {
+		t.Fatalf("focus did not move to the right layer")
+	}
+	u.Tap(xgal.KeyArrowRight, Mods{})
+	if u.Focused != left || left.Focused != first {
+		t.Fatalf("focus did not wrap to the left layer")
+	}
+	u.Tap(xgal.KeyArrowDown, Mods{})
+	if u.Focused != &list.Layer {
+		t.Fatalf("focus did not move to the list")
+	}
+	// The list takes the arrow keys while it can select.
+	u.Tap(xgal.KeyArrowDown, Mods{})
+	u.Tap(xgal.KeyArrowDown, Mods{})
+	if u.Focused != &list.Layer || list.Selected != 1 {
+		t.Errorf("list: focused %v, selected %d", u.Focused == &list.Layer, list.Selected)
+	}
+	u.Tap(xgal.KeyArrowDown, Mods{})
+	if u.Focused == &list.Layer {
+		t.Errorf("focus stays in the list at its end")
+	}
+}
+
+func TestConfirm(t *testing.T) {
+	u := &UI{}
+	l := u.Append(NewLayer(xgal.Rect(0, 0, 100, 100)))
+	ok := testTarget(l, xgal.Rect(10, 10, 30, 20))
+	var at xgal.Point
+	ok.Class.Click = func(p xgal.Point, button int) Reply {
+		at = p
+		return Finish
+	}
+	l.SetFocus(ok)
+	if res := u.Tap(xgal.KeyEnter, Mods{}); res != Finish || at != xgal.Pt(20, 15) {
+		t.Errorf("confirm: %v at %v", res, at)
+	}
+}
+
+func TestCancel(t *testing.T) {
+	u := &UI{}
+	l := u.Append(NewLayer(xgal.Rect(0, 0, 100, 100)))
+	ok := testTarget(l, xgal.Rect(10, 10, 30, 20))
+	u.SetFocus(l)
+	l.SetFocus(ok)
+	if res := u.Tap(xgal.KeyEscape, Mods{}); res != Accept || l.Focused != nil || ok.State.Focused {
+		t.Errorf("escape did not blur the control: %v", res)
+	}
+	if res := u.Tap(xgal.KeyEscape, Mods{}); res != Accept || u.Focused != nil || l.State.Focused {
+		t.Errorf("escape did not blur the layer: %v", res)
+	}
+	if res := u.Tap(xgal.KeyEscape, Mods{}); res != Ignore {
+		t.Errorf("escape without focus: %v", res)
+	}
+}
+
+func TestNavigateSlider(t *testing.T) {
+	u := &UI{}
+	l := u.Append(NewLayer(xgal.Rect(0, 0, 200, 100)))
+	slider := l.Slider(Horizontal, 0, 2, 1)
+	after := testTarget(l, xgal.Rect(150, 0, 170, 10))
+	l.SetFocus(slider)
+	values := 0
+	slider.Class.Value = func(int) Reply { values++; return Accept }
+
+	u.Tap(xgal.KeyArrowRight, Mods{})
+	if slider.Value != 2 || values != 1 || l.Focused != slider {
+		t.Fatalf("slider: value %d, focused %v", slider.Value, l.Focused == slider)
+	}
+	u.Tap(xgal.KeyArrowRight, Mods{})
+	if l.Focused != after {
+		t.Errorf("focus stays on the slider at its end")
+	}
+}
+
+func TestStickDirection(t *testing.T) {
+	for _, c := range []struct {
+		x, y float64
+		want xgal.Point
+	}{
+		{0, 0, xgal.Pt(0, 0)},
+		{0.3, -0.4, xgal.Pt(0, 0)},
+		{0.9, 0.2, xgal.Pt(1, 0)},
+		{-0.9, 0.6, xgal.Pt(-1, 0)},
+		{0.2, 0.7, xgal.Pt(0, 1)},
+		{0.5, -0.8, xgal.Pt(0, -1)},
+	} {
+		if got := stickDirection(c.x, c.y, 0.5); got != c.want {
+			t.Errorf("%v, %v: %v, want %v", c.x, c.y, got, c.want)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package xlui
 
 import "github.com/xmasengine/xmas/xgal"
 import "log/slog"
+import "strconv"
 
 const defaultDiameter = 8
 
@@ -107,12 +108,38 @@ func NewSlider(at xgal.Point, orientation Orientation, low, high, value int) *Co
 		return Accept
 	}
 
+	// The arrow keys along the slider move it, until it is at an end.
+	tap := func(key int, mods Mods) Reply {
+		step := 0
+		switch xgal.KeyCode(key) {
+		case xgal.KeyArrowLeft, xgal.KeyArrowUp:
+			step = -1
+		case xgal.KeyArrowRight, xgal.KeyArrowDown:
+			step = 1
+		}
+		vertical := xgal.KeyCode(key) == xgal.KeyArrowUp || xgal.KeyCode(key) == xgal.KeyArrowDown
+		if step == 0 || vertical != (orientation == Vertical) {
+			return Ignore
+		}
+		value := min(max(slider.Value+step, slider.Low), slider.High)
+		if value == slider.Value {
+			return Ignore
+		}
+		slider.Value = value
+		if slider.Class.Value != nil {
+			slider.Class.Value(slider.Value)
+		}
+		return Accept
+	}
+
 	slider.Class = Class{
 		Render:  render,
 		Click:   click,
 		Release: release,
 		Wheel:   wheel,
 		Hover:   hover,
+		Tap:     tap,
+		Say:     func() string { return strconv.Itoa(slider.Value) },
 	}
 
 	return slider
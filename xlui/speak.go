@@ -0,0 +1,41 @@
+package xlui
+
+import "github.com/xmasengine/xmas/xgal"
+
+// Said returns what is announced of the control to the speaker of
+// [xgal.Announce]: its label, which is its Label, or else its Text unless
+// the text is what the control lays out with Relabel, as for entries, and
+// its value, which the Say of its Class returns.
+func (c *Control) Said() (label, value string) {
+	label = c.Label
+	if label == "" && c.Class.Relabel == nil {
+		label = c.Text
+	}
+	if c.Class.Say != nil {
+		value = c.Class.Say()
+	}
+	return label, value
+}
+
+// speak announces the control with the cue.
+func (c *Control) speak(cue xgal.Cue) {
+	label, value := c.Said()
+	xgal.Announce(xgal.Announcement{Cue: cue, Label: label, Value: value})
+}
+
+// speakChange announces the value of the control if it changed from was.
+func (c *Control) speakChange(was string) {
+	if _, value := c.Said(); value != was {
+		c.speak(xgal.CueValue)
+	}
+}
+
+// sayChecked returns what is said of a control that is checked or not.
+func sayChecked(c *Control, on, off string) func() string {
+	return func() string {
+		if c.Checked {
+			return on
+		}
+		return off
+	}
+}
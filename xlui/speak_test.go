@@ -0,0 +1,58 @@
+package xlui
+
+import (
+	"testing"
+
+	"github.com/xmasengine/xmas/xgal"
+)
+
+func TestSpeakFocus(t *testing.T) {
+	heard := xgal.Listen(t)
+	l := testLayer()
+	box, _ := l.CheckboxWithLabel(true, "Snow")
+	name := l.Entry("Santa")
+	name.Label = "Name"
+	ok := l.Button("OK")
+
+	l.SetFocus(box)
+	l.SetFocus(box)
+	l.SetFocus(name)
+	l.SetFocus(ok)
+	want := []string{"focus Snow: checked", "focus Name: Santa", "focus OK"}
+	if got := heard.Texts(); len(got) != len(want) || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+		t.Errorf("heard %q, want %q", got, want)
+	}
+}
+
+func TestSpeakValue(t *testing.T) {
+	l := testLayer()
+	box := l.Checkbox(false)
+	box.Label = "Snow"
+	slider := l.Slider(Horizontal, 0, 10, 3)
+	slider.Label = "Volume"
+	heard := xgal.Listen(t)
+
+	l.OnClick(box.Bounds.Min, int(xgal.MouseButtonLeft))
+	l.SetFocus(slider)
+	l.OnTap(int(xgal.KeyArrowRight), Mods{})
+	l.OnTap(int(xgal.KeyArrowUp), Mods{})
+	want := []string{"value Snow: checked", "focus Volume: 3", "value Volume: 4"}
+	if got := heard.Texts(); len(got) != len(want) || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+		t.Errorf("heard %q, want %q", got, want)
+	}
+}
+
+func TestSpeakLines(t *testing.T) {
+	heard := xgal.Listen(t)
+	talk := NewTalk(xgal.Pt(0, 0), "Ho ho\nho!", 2)
+	talk.Label = "Santa"
+	talk.Class.Relabel("Merry {wave}Christmas{/wave}")
+	talk.SetText(talk.Text)
+	list := NewList(xgal.Rect(0, 0, 50, 50), "one", "two")
+	list.Select(1)
+	list.Select(1)
+	want := []string{"line Ho ho ho!", "line Santa: Merry Christmas", "focus two"}
+	if got := heard.Texts(); len(got) != len(want) || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+		t.Errorf("heard %q, want %q", got, want)
+	}
+}
@@ -4,6 +4,7 @@ import "github.com/xmasengine/xmas/xgal"
 import "github.com/xmasengine/xmas/xvec"
 import "github.com/xmasengine/xmas/xres/fontres"
 
+import "cmp"
 import "os"
 import "io/fs"
 
@@ -51,6 +52,9 @@ type Style struct {
 
 	Vec   *xvec.XVEC
 	Frame *xgal.Surface
+	Slice int // Slice is the inset of the nine slices of the frame, OffNine if 0.
+
+	Name string // Name of the style in the theme.
 }
 
 var DefaultFS = os.DirFS("pack/image/ui")
@@ -85,14 +89,15 @@ func DefaultStyle() Style {
 	s.Border = xgal.RGBA{R: 0x55, G: 0x55, B: 0x55, A: 0xff}
 	s.Shadow = xgal.RGBA{R: 0x00, G: 0x00, B: 0x00, A: 0x88}
 	s.Fill = xgal.RGBA{R: 0x00, G: 0x00, B: 0x55, A: 0xaa}
-	s.Stroke = 1
-	s.Gloom = 1
-	s.Margin = xgal.Pt(2, 2)
-	s.Shade = xgal.Pt(1, 1)
-	s.Face = DefaultFace
-	s.Diameter = defaultDiameter
+	s.Stroke = xgal.UILength(1)
+	s.Gloom = xgal.UILength(1)
+	s.Margin = xgal.UIPoint(xgal.Pt(2, 2))
+	s.Shade = xgal.UIPoint(xgal.Pt(1, 1))
+	s.Face = xgal.ScaleFace(DefaultFace, xgal.UIScale)
+	s.Diameter = xgal.UILength(defaultDiameter)
 	s.Frame = nil
-	return s
+	s.Name = "default"
+	return s.themed("")
 }
 
 func (s Style) DrawRect(dst *xgal.Surface, r xgal.Rectangle) {
@@ -112,7 +117,8 @@ func (s Style) DrawBox(dst *xgal.Surface, r xgal.Rectangle) {
 		offset := r.Min.Add(s.Offset)
 		offsetX, offsetY := float32(offset.X), float32(offset.Y)
 		dstW, dstH := (r.Dx()), (r.Dy())
-		xgal.NineSlice(dst, s.Frame, offsetX, offsetY, dstW, dstH, OffNine, OffNine, OffNine, OffNine)
+		slice := cmp.Or(s.Slice, OffNine)
+		xgal.NineSlice(dst, s.Frame, offsetX, offsetY, dstW, dstH, slice, slice, slice, slice)
 	}
 
 	if s.Gloom > 0 {
@@ -174,121 +180,127 @@ func (s Style) Inset(bounds xgal.Rectangle) xgal.Rectangle {
 
 func (s Style) Focused() Style {
 	s.Border = xgal.Paint(240, 240, 240, 245)
-	return s
+	return s.themed("focused")
 }
 
 func (s Style) Clicked() Style {
 	s.Border = xgal.Paint(0xff, 0xff, 0xff, 0xff)
 	s.Fill = xgal.Paint(0x55, 0x55, 0xff, 0xff)
-	s.Gloom = 2
-	s.Shade = xgal.Pt(-1, -1)
-	s.Offset = xgal.Pt(0, 1)
-	return s
+	s.Gloom = xgal.UILength(2)
+	s.Shade = xgal.UIPoint(xgal.Pt(-1, -1))
+	s.Offset = xgal.UIPoint(xgal.Pt(0, 1))
+	return s.themed("clicked")
 }
 
 func (s Style) Hovered() Style {
 	s.Border = xgal.Paint(240, 240, 50, 250)
-	return s
+	return s.themed("hovered")
 }
 
 func (s Style) Error() Style {
 	s.Fill = xgal.Paint(200, 128, 128, 240)
-	return s
+	s.Name = "error"
+	return s.themed("")
 }
 
 func FocusStyle() Style {
 	s := DefaultStyle()
 	s.Border = xgal.Paint(240, 140, 40, 245)
 	s.Fill = xgal.Paint(128, 128, 200, 240)
-	return s
+	return s.themed("focused")
 }
 
 func HoverStyle() Style {
 	s := DefaultStyle()
 	s.Border = xgal.Paint(240, 240, 50, 250)
-	return s
+	return s.themed("hovered")
 }
 
 func PressStyle() Style {
 	s := DefaultStyle()
 	s.Fill = xgal.Paint(15, 45, 200, 240)
-	return s
+	return s.themed("clicked")
 }
 
 func ButtonStyle() Style {
 	s := DefaultStyle()
-	s.Margin = xgal.Pt(2, 0)
+	s.Margin = xgal.UIPoint(xgal.Pt(2, 0))
 	s.Fill = xgal.Paint(0x00, 0x00, 0xaa, 0xaa)
 	s.Shadow = xgal.Paint(0x00, 0x00, 0x00, 0xff)
-	return s
+	s.Name = "button"
+	return s.themed("")
 }
 
 func BarStyle() Style {
 	s := DefaultStyle()
 	s.Fill = xgal.Paint(45, 45, 200, 250)
-	return s
+	s.Name = "bar"
+	return s.themed("")
 }
 
 func CheckStyle() Style {
 	s := DefaultStyle()
 	s.Fill = xgal.Paint(245, 245, 245, 250)
-	return s
+	s.Name = "check"
+	return s.themed("")
 }
 
 func (s Style) HoverStyle() Style {
 	s.Border = xgal.Paint(200, 200, 45, 250)
-	return s
+	return s.themed("hovered")
 }
 
 func (s Style) FocusStyle() Style {
 	s.Border = xgal.Paint(240, 140, 40, 245)
 	s.Fill = xgal.Paint(128, 128, 200, 245)
-	return s
+	return s.themed("focused")
 }
 
 func (s Style) PressStyle() Style {
 	s.Fill = xgal.Paint(15, 45, 200, 240)
-	return s
+	return s.themed("clicked")
 }
 
 func (s Style) DragStyle() Style {
 	s.Fill = xgal.Paint(15, 128, 200, 240)
-	return s
+	return s.themed("dragged")
 }
 
 func (s Style) BarStyle() Style {
 	s.Fill = xgal.Paint(45, 45, 245, 250)
-	return s
+	return s.part("bar")
 }
 
 func (s Style) CheckStyle() Style {
 	s.Fill = xgal.Paint(245, 245, 245, 250)
-	return s
+	return s.part("check")
 }
 
 func (s Style) ActiveStyle() Style {
 	s.Fill = xgal.Paint(60, 60, 140, 255)
 	s.Border = xgal.Paint(120, 120, 220, 255)
-	return s
+	return s.themed("active")
 }
 
 func (s Style) KnobStyle() Style {
 	s.Fill = xgal.Paint(245, 245, 245, 250)
-	return s
+	return s.part("knob")
 }
 
 func MenuStyle() Style {
 	s := DefaultStyle()
-	s.Margin = xgal.Pt(2, 0)
+	s.Margin = xgal.UIPoint(xgal.Pt(2, 0))
 	s.Gloom = 0
 	s.Stroke = 0
-	return s
+	s.Name = "menu"
+	return s.themed("")
 }
 
 func HUDBarStyle() Style {
 	s := DefaultStyle()
 	s.Fill = xgal.RGBA{R: 0xff, G: 0x00, B: 0x00, A: 0xff}
-	return s
+	s.Name = "hud"
+	return s.themed("")
 }
 
 func (s Style) ForState(state State) Style {
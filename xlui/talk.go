@@ -1,10 +1,39 @@
 package xlui
 
+import "strings"
+
 import "github.com/xmasengine/xmas/xgal"
 
 const TalkSizer = "WWWWWWWWWWWWWWWWWWWWWWWWWWWWWWWW"
 const TalkTick = 10
 
+func runeLinesToText(input [][]rune) string {
+	lines := make([]string, len(input))
+	for i, in := range input {
+		if in == nil {
+			lines[i] = ""
+		} else {
+			lines[i] = string(in)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func textToRuneLines(text string) [][]rune {
+	lines := strings.Split(text, "\n")
+	res := make([][]rune, len(lines))
+	for i, line := range lines {
+		res[i] = []rune(line)
+	}
+	return res
+}
+
+// spokenText returns the rune lines as one line of words, as a speaker
+// says them.
+func spokenText(output [][]rune) string {
+	return strings.Join(strings.Fields(runeLinesToText(output)), " ")
+}
+
 // revealedText returns the first n characters of the rune lines as a string,
 // with newlines inserted between the lines.
 func revealedText(output [][]rune, n int) string {
@@ -38,7 +67,8 @@ func revealCursor(output [][]rune, n int) xgal.Point {
 	return xgal.Pt(len(output[last]), last)
 }
 
-// NewTalk returns a new animated multi line text display Control.
+// NewTalk returns a new animated multi line text display Control. The text
+// may use the markup of [xgal.Typeset] and is wrapped to the talk width.
 func NewTalk(at xgal.Point, text string, lines int) *Control {
 	// talk variables
 	var (
@@ -46,13 +76,37 @@ func NewTalk(at xgal.Point, text string, lines int) *Control {
 		cursor xgal.Point
 		output [][]rune
 		reveal int
+		start  = int64(-1)
+		now    int64
 	)
 
 	talk := NewControl(at)
 	talk.Text = text
 	talk.State.Clicked = false
 	size := talk.Style.Measure(TalkSizer)
-	output = textToRuneLines(text)
+	typeset := xgal.NewTypeset(talk.Style.Face, size.X, 0)
+	typeset.Tick = TalkTick
+	var laid *xgal.Text
+	var label string
+	relabel := func(text string) {
+		if laid != nil && text == talk.Text && talk.Label == label {
+			// Keep revealing the same text, for example after a restyle.
+			return
+		}
+		var err error
+		talk.Text = text
+		label = talk.Label
+		laid, err = typeset.Layout(text)
+		if err != nil {
+			// Show text with broken markup as it is.
+			laid, _ = typeset.Layout(xgal.Escape(text))
+		}
+		output = laid.Runes()
+		reveal, start, cursor = 0, -1, xgal.Point{}
+		talk.From = xgal.Pt(0, 0)
+		xgal.Announce(xgal.Announcement{Cue: xgal.CueLine, Label: talk.Label, Value: spokenText(output)})
+	}
+	relabel(text)
 
 	talk.Bounds = xgal.Bound(talk.Bounds.Min.X, talk.Bounds.Min.Y, size.X, size.Y*lines)
 	clip := xgal.Bound(
@@ -100,7 +154,8 @@ func NewTalk(at xgal.Point, text string, lines int) *Control {
 
 		// don't shift the box, but do shift the text and cursor
 		delta = delta.Add(talk.From)
-		style.Print(screen, talk.Bounds.Min.Add(delta), revealedText(output, reveal))
+		at := talk.Bounds.Min.Add(delta).Add(style.Margin).Add(style.Offset)
+		laid.Draw(screen, style.Face, style.Fore, at, 0, reveal, int(now), nil)
 		// Draw cursor
 		sz := talk.Style.Measure(string(output[cursor.Y][:cursor.X]))
 		box := talk.Bounds
@@ -165,8 +220,12 @@ func NewTalk(at xgal.Point, text string, lines int) *Control {
 	}
 
 	tick := func(t int64) Reply {
-		if (t%TalkTick) == 0 && (reveal < len(talk.Text)) {
-			reveal++
+		now = t
+		if start < 0 {
+			start = t
+		}
+		if n := laid.Reveal(int(t - start)); n != reveal {
+			reveal = n
 			cursor = revealCursor(output, reveal)
 			if cursor.Y >= lines {
 				// shift up
@@ -188,6 +247,8 @@ func NewTalk(at xgal.Point, text string, lines int) *Control {
 		Hover:   hover,
 		Tap:     tap,
 		Tick:    tick,
+		Relabel: relabel,
+		Say:     func() string { return spokenText(output) },
 	}
 	return talk
 }
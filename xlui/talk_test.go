@@ -77,3 +77,28 @@ func TestRevealTrace(t *testing.T) {
 		}
 	}
 }
+
+// TestRevealLayout ensures the reveal cursor over the runes of laid out
+// markup matches the glyph positions of the layout.
+func TestRevealLayout(t *testing.T) {
+	typeset := &xgal.Typeset{
+		Width:   40,
+		Stride:  10,
+		Tick:    1,
+		Measure: func(s string) int { return 8 * len([]rune(s)) },
+	}
+	laid, err := typeset.Layout("{color=#ff0000}Ho ho{/color} ho {wave}merry{/wave}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := laid.Runes()
+	if got := revealedText(lines, len(laid.Glyphs)); got != "Ho ho\nho\nmerry" {
+		t.Fatalf("wrapped: %q", got)
+	}
+	for n := 0; n <= len(laid.Glyphs); n++ {
+		c := revealCursor(lines, n)
+		if want := xgal.Pt(8*c.X, 10*c.Y); laid.Cursor(n) != want {
+			t.Errorf("reveal step %d: cursor %v, glyphs at %v", n, want, laid.Cursor(n))
+		}
+	}
+}
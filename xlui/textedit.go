@@ -0,0 +1,218 @@
+package xlui
+
+import "fmt"
+import "strconv"
+
+import "github.com/xmasengine/xmas/xgal"
+
+// TextEdit is the Data of entries and areas. It is the [xgal.Edit] of
+// their text, with a mask and maximum length that can be set, and how
+// the text is scrolled.
+type TextEdit struct {
+	*xgal.Edit
+	Numbers bool // Numbers shows line numbers in front of the lines of an area.
+	Top     int  // Top is the first line that an area shows.
+	Scroll  int  // Scroll is how far the text is scrolled to the left, in pixels.
+	Shown   int  // Shown is how many lines are shown.
+
+	blink bool
+	drag  bool
+}
+
+// TextEdit returns the text edit of an entry or area, or nil for other
+// controls.
+func (c *Control) TextEdit() *TextEdit {
+	te, _ := c.Data.(*TextEdit)
+	return te
+}
+
+// newTextEdit makes a text edit that shows lines of the text of the
+// control, and sets it as the Data of the control.
+func newTextEdit(c *Control, lines int) *TextEdit {
+	te := &TextEdit{Edit: xgal.NewEdit(c.Text), Shown: lines, blink: true}
+	te.Multiline = lines > 1
+	te.Page = lines
+	c.Data = te
+	return te
+}
+
+// sync takes over a text that was set on the control directly, such as
+// by [Control.SetText].
+func (te *TextEdit) sync(c *Control) {
+	if c.Text != te.String() {
+		te.SetText(c.Text)
+	}
+}
+
+// gutter returns the width of the line numbers.
+func (te *TextEdit) gutter(style Style) int {
+	if !te.Numbers || !te.Multiline {
+		return 0
+	}
+	digits := len(strconv.Itoa(len(te.Lines())))
+	return style.Measure(fmt.Sprintf("%0*d ", digits, 0)).X
+}
+
+// textBox returns the box that the text is shown in.
+func (te *TextEdit) textBox(c *Control) xgal.Rectangle {
+	box := c.Style.Inset(c.Bounds)
+	box.Min.X += te.gutter(c.Style)
+	return box
+}
+
+// at returns the index of the rune nearest to the point at.
+func (te *TextEdit) at(c *Control, at xgal.Point) int {
+	box := te.textBox(c)
+	line := te.Top + (at.Y-box.Min.Y)/max(c.Style.Stride(), 1)
+	lines := te.Lines()
+	line = min(max(line, 0), len(lines)-1)
+	measure := func(text string) int { return c.Style.Measure(text).X }
+	col := xgal.Column(lines[line], at.X-box.Min.X+te.Scroll, measure)
+	return te.Index(line, col)
+}
+
+// show scrolls the text so the cursor is in view.
+func (te *TextEdit) show(c *Control) {
+	line, col := te.LineCol(te.Cursor)
+	if line < te.Top {
+		te.Top = line
+	} else if line >= te.Top+te.Shown {
+		te.Top = line - te.Shown + 1
+	}
+	box := te.textBox(c)
+	x := c.Style.Measure(string([]rune(te.Lines()[line])[:col])).X
+	if x < te.Scroll {
+		te.Scroll = x
+	} else if x > te.Scroll+box.Dx() {
+		te.Scroll = x - box.Dx()
+	}
+}
+
+// changed updates the control after the text edit changed or moved.
+func (te *TextEdit) changed(c *Control) {
+	c.Text = te.String()
+	te.blink = true
+	te.show(c)
+}
+
+// tap handles the editing keys. At the ends of the text, the keys that
+// would move the cursor past them are ignored, so they can move the
+// focus instead.
+func (te *TextEdit) tap(c *Control, key int, mods Mods) Reply {
+	te.sync(c)
+	code := xgal.KeyCode(key)
+	if !mods.Shift && !mods.Control && !te.HasSelection() {
+		line, _ := te.LineCol(te.Cursor)
+		switch {
+		case code == xgal.KeyArrowLeft && te.Cursor == 0,
+			code == xgal.KeyArrowRight && te.Cursor == len(te.Text),
+			code == xgal.KeyArrowUp && te.Multiline && line == 0,
+			code == xgal.KeyArrowDown && te.Multiline && line == len(te.Lines())-1:
+			return Ignore
+		}
+	}
+	handled, _ := te.Key(code, mods.Shift, mods.Control || mods.Meta)
+	if !handled {
+		return Ignore
+	}
+	te.changed(c)
+	return Accept
+}
+
+// chars inserts typed runes.
+func (te *TextEdit) chars(c *Control, chrs ...rune) Reply {
+	te.sync(c)
+	if !te.Insert(chrs...) {
+		return Ignore
+	}
+	te.changed(c)
+	return Accept
+}
+
+// click moves the cursor to the clicked rune, or selects up to it with
+// shift, and starts selecting by dragging.
+func (te *TextEdit) click(c *Control, at xgal.Point) Reply {
+	te.sync(c)
+	te.MoveTo(te.at(c, at), xgal.Key(xgal.KeyShift))
+	te.drag = true
+	te.changed(c)
+	return Accept
+}
+
+// hover selects up to the hovered rune while dragging.
+func (te *TextEdit) hover(c *Control, at xgal.Point) Reply {
+	if te.drag && c.State.Clicked {
+		te.MoveTo(te.at(c, at), true)
+		te.show(c)
+	}
+	return Accept
+}
+
+func (te *TextEdit) release() Reply {
+	te.drag = false
+	return Accept
+}
+
+// wheel scrolls the lines of an area.
+func (te *TextEdit) wheel(c *Control, delta int) Reply {
+	if !te.Multiline {
+		return Ignore
+	}
+	te.Top = min(max(te.Top-delta, 0), max(len(te.Lines())-te.Shown, 0))
+	return Accept
+}
+
+func (te *TextEdit) tick(t int64, every int64) Reply {
+	if t%every == 0 {
+		te.blink = !te.blink
+	}
+	return Ignore
+}
+
+// style returns the style of the control in its state, or its error style
+// if the text is not valid for the mask.
+func (te *TextEdit) style(c *Control) Style {
+	style := c.Style.ForState(c.State)
+	if te.Valid() != nil {
+		style = style.Error()
+	}
+	return style
+}
+
+// render draws the shown lines of the text with the selection, the line
+// numbers if any, and the cursor if the control is focused.
+func (te *TextEdit) render(screen *xgal.Surface, c *Control, style Style) {
+	te.sync(c)
+	box := te.textBox(c)
+	stride := max(style.Stride(), 1)
+	lines := te.Lines()
+	lo, hi := te.Selection()
+	start := te.Index(te.Top, 0)
+	clip := screen.SubImage(box.Add(style.Offset)).(*xgal.Surface)
+	for i := te.Top; i < min(len(lines), te.Top+te.Shown); i++ {
+		line := []rune(lines[i])
+		y := box.Min.Y + (i-te.Top)*stride
+		x := box.Min.X - te.Scroll
+		if te.Numbers && te.Multiline {
+			gutter := c.Style.Inset(c.Bounds).Min.X
+			xgal.Ink(screen, style.Face, style.Border, gutter+style.Offset.X, y+style.Offset.Y, strconv.Itoa(i+1))
+		}
+		end := start + len(line)
+		if c.State.Focused && lo < hi && lo <= end && hi > start {
+			from := max(lo, start) - start
+			to := min(hi, end) - start
+			x0 := x + style.Measure(string(line[:from])).X
+			x1 := x + style.Measure(string(line[:to])).X
+			if hi > end {
+				x1 += style.Measure(" ").X // Show the selected newline.
+			}
+			xgal.Box(clip, xgal.Rect(x0, y, x1, y+stride).Add(style.Offset), style.ActiveStyle().Fill)
+		}
+		xgal.Ink(clip, style.Face, style.Fore, x+style.Offset.X, y+style.Offset.Y, string(line))
+		if c.State.Focused && te.blink && te.Cursor >= start && te.Cursor <= end {
+			cx := x + style.Measure(string(line[:te.Cursor-start])).X
+			xgal.Line(clip, cx, y, cx, y+stride, style.Stroke, style.Fore)
+		}
+		start = end + 1
+	}
+}
@@ -0,0 +1,105 @@
+package xlui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/xmasengine/xmas/xgal"
+)
+
+func TestEntryEdit(t *testing.T) {
+	var submitted []string
+	entry := NewEntry(xgal.Pt(0, 0), "")
+	entry.Class.Entry = func(text string) Reply {
+		submitted = append(submitted, text)
+		return Accept
+	}
+	edit := entry.TextEdit()
+	edit.Mask = xgal.IntegerMask
+	edit.Max = 3
+
+	entry.Class.Chars([]rune("-12a3")...)
+	if entry.Text != "-12" {
+		t.Fatalf("typed %q", entry.Text)
+	}
+	entry.Class.Tap(int(xgal.KeyArrowLeft), Mods{Shift: true, Control: true})
+	if edit.Selected() != "12" {
+		t.Errorf("selected %q", edit.Selected())
+	}
+	entry.Class.Tap(int(xgal.KeyBackspace), Mods{})
+	if entry.Text != "-" {
+		t.Errorf("deleted to %q", entry.Text)
+	}
+	// The invalid text is not submitted.
+	entry.Class.Tap(int(xgal.KeyEnter), Mods{})
+	if len(submitted) != 0 {
+		t.Errorf("submitted %q", submitted)
+	}
+	entry.Class.Tap(int(xgal.KeyZ), Mods{Control: true})
+	entry.Class.Tap(int(xgal.KeyEnter), Mods{})
+	if strings.Join(submitted, ",") != "-12" {
+		t.Errorf("submitted %q after undo", submitted)
+	}
+	// Setting the text directly is taken over by the edit.
+	entry.SetText("7")
+	entry.Class.Chars('8')
+	if entry.Text != "78" {
+		t.Errorf("typed after set: %q", entry.Text)
+	}
+	// Left at the start of the text is left for navigation.
+	entry.Class.Tap(int(xgal.KeyHome), Mods{})
+	if res := entry.Class.Tap(int(xgal.KeyArrowLeft), Mods{}); res != Ignore {
+		t.Errorf("left at the start: %v", res)
+	}
+}
+
+func TestAreaScroll(t *testing.T) {
+	area := NewArea(xgal.Pt(0, 0), "", 2)
+	edit := area.TextEdit()
+	for i := range 4 {
+		if i > 0 {
+			area.Class.Tap(int(xgal.KeyEnter), Mods{})
+		}
+		area.Class.Chars('a' + rune(i))
+	}
+	if area.Text != "a\nb\nc\nd" || edit.Top != 2 {
+		t.Fatalf("text %q, top %d", area.Text, edit.Top)
+	}
+	area.Class.Wheel(area.Bounds.Min, 5)
+	if edit.Top != 0 {
+		t.Errorf("wheel up to %d", edit.Top)
+	}
+	area.Class.Tap(int(xgal.KeyPageUp), Mods{})
+	if line, _ := edit.LineCol(edit.Cursor); line != 1 || edit.Top != 0 {
+		t.Errorf("page up to line %d, top %d", line, edit.Top)
+	}
+}
+
+func TestEditLayout(t *testing.T) {
+	layout, err := ReadLayout(strings.NewReader(`<layout><layer>
+		<entry id="hex" mask="hex" max="4"/>
+		<area id="script" lines="8" numbers="true"/>
+	</layer></layout>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := layout.Build(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hex := v.Control("hex").TextEdit()
+	if hex.Mask.Name != "hex" || hex.Max != 4 {
+		t.Errorf("hex entry: mask %q, max %d", hex.Mask.Name, hex.Max)
+	}
+	if script := v.Control("script").TextEdit(); !script.Numbers || !script.Multiline {
+		t.Errorf("script area: numbers %v", script.Numbers)
+	}
+
+	bad, err := ReadLayout(strings.NewReader(`<layout><layer><entry mask="roman"/></layer></layout>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bad.Build(nil); err == nil {
+		t.Errorf("no error for an unknown mask")
+	}
+}
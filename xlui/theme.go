@@ -0,0 +1,135 @@
+package xlui
+
+import "github.com/xmasengine/xmas/xdat"
+import "github.com/xmasengine/xmas/xgal"
+
+// theme is the theme that styles apply, or nil for the built in looks.
+var theme *xdat.Theme
+
+// Theme returns the current theme, or nil if there is none.
+func Theme() *xdat.Theme {
+	return theme
+}
+
+// SetTheme makes new styles use the looks of the theme, or the built in
+// looks if it is nil, and restyles the global UI.
+func SetTheme(t *xdat.Theme) {
+	theme = t
+	xlui.Restyle()
+}
+
+// Apply returns the style with the attributes that are set in the look.
+// The lengths and the font of the look are scaled by [xgal.UIScale].
+func (s Style) Apply(look xdat.Look) Style {
+	if look.Fore != nil {
+		s.Fore = look.Fore.RGBA()
+	}
+	if look.Border != nil {
+		s.Border = look.Border.RGBA()
+	}
+	if look.Shadow != nil {
+		s.Shadow = look.Shadow.RGBA()
+	}
+	if look.Fill != nil {
+		s.Fill = look.Fill.RGBA()
+	}
+	if look.Stroke != nil {
+		s.Stroke = xgal.UILength(*look.Stroke)
+	}
+	if look.Gloom != nil {
+		s.Gloom = xgal.UILength(*look.Gloom)
+	}
+	if look.Diameter != nil {
+		s.Diameter = xgal.UILength(*look.Diameter)
+	}
+	if look.Margin != nil {
+		s.Margin = xgal.UIPoint(look.Margin.Point())
+	}
+	if look.Shade != nil {
+		s.Shade = xgal.UIPoint(look.Shade.Point())
+	}
+	if look.Offset != nil {
+		s.Offset = xgal.UIPoint(look.Offset.Point())
+	}
+	if look.Face != nil {
+		s.Face = xgal.ScaleFace(look.Face, xgal.UIScale)
+	}
+	if look.Frame != "" {
+		s.Frame = look.Texture
+	}
+	if look.Slice != nil {
+		s.Slice = *look.Slice
+	}
+	return s
+}
+
+// themed returns the style with the look of its name in the state from
+// the theme applied, or the plain look if state is empty.
+func (s Style) themed(state string) Style {
+	if theme == nil || s.Name == "" {
+		return s
+	}
+	return s.Apply(theme.Look(s.Name, state))
+}
+
+// part returns the style with the plain look of the named style of the
+// theme applied, for parts of widgets such as the knob of a slider.
+func (s Style) part(name string) Style {
+	if theme == nil {
+		return s
+	}
+	return s.Apply(theme.Look(name, ""))
+}
+
+// Restyle returns the style with the same name made anew, for example
+// after the theme changed. Styles without a known name are kept.
+func (s Style) Restyle() Style {
+	if style, ok := styles[s.Name]; ok {
+		return style()
+	}
+	return s
+}
+
+// Restyle restyles the layer and its controls. If the font or margin of
+// a control that was sized to fit its text changed, it is resized to fit
+// the text again and the layer is reflowed. Controls with a width of
+// their own, such as filled ones, and relabeled controls such as talks
+// keep their size.
+func (l *Layer) Restyle() {
+	l.Style = l.Style.Restyle()
+	changed := false
+	for _, ctrl := range l.Controls {
+		old := ctrl.Style
+		ctrl.Style = old.Restyle()
+		if ctrl.Text == "" || ctrl.Class.Relabel != nil {
+			continue
+		}
+		if ctrl.Style.Face == old.Face && ctrl.Style.Margin == old.Margin {
+			continue
+		}
+		fit := old.Measure(ctrl.Text).Add(old.Margin.Mul(2))
+		if ctrl.Bounds.Size() != fit {
+			continue
+		}
+		size := ctrl.Style.Measure(ctrl.Text).Add(ctrl.Style.Margin.Mul(2))
+		ctrl.Bounds.Max = ctrl.Bounds.Min.Add(size)
+		changed = true
+	}
+	if changed {
+		l.Reflow()
+	}
+}
+
+// Restyle restyles all layers of the UI.
+func (u *UI) Restyle() {
+	for _, layer := range u.Layers {
+		layer.Restyle()
+	}
+}
+
+// SetScale sets the scale of the user interfaces, [xgal.UIScale], which
+// new styles and layouts use, and restyles the global UI.
+func SetScale(scale int) {
+	xgal.UIScale = max(scale, 1)
+	xlui.Restyle()
+}
@@ -0,0 +1,98 @@
+package xlui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/xmasengine/xmas/xdat"
+	"github.com/xmasengine/xmas/xgal"
+)
+
+const testTheme = `<theme name="test">
+ <style name="default" fill="#102030" margin="4">
+  <state name="focused" border="#ff0000"/>
+ </style>
+ <style name="button" fill="#405060"/>
+</theme>`
+
+func TestSetTheme(t *testing.T) {
+	th, err := xdat.ReadTheme(strings.NewReader(testTheme))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer SetTheme(nil)
+
+	u := &UI{}
+	l := u.Append(NewLayer(xgal.Rect(0, 0, 100, 100)))
+	button := l.Button("OK")
+	plain := button.Bounds
+	wide := l.Button("Wide")
+	wide.Bounds.Max.X = l.Bounds.Max.X
+	filled := wide.Bounds
+
+	theme = th
+	u.Restyle()
+	if wide.Bounds.Size() != filled.Size() {
+		t.Errorf("filled button resized: %v, was %v", wide.Bounds, filled)
+	}
+	if button.Style.Fill != xgal.Paint(0x40, 0x50, 0x60, 0xff) || button.Style.Margin != xgal.Pt(4, 4) {
+		t.Errorf("button: fill %v, margin %v", button.Style.Fill, button.Style.Margin)
+	}
+	if l.Style.Fill != xgal.Paint(0x10, 0x20, 0x30, 0xff) {
+		t.Errorf("layer: fill %v", l.Style.Fill)
+	}
+	if button.Bounds.Dy() != plain.Dy()+4*2 {
+		t.Errorf("button not resized for the margin: %v, was %v", button.Bounds, plain)
+	}
+	if focused := button.Style.Focused(); focused.Border != xgal.Paint(0xff, 0, 0, 0xff) || focused.Fill != button.Style.Fill {
+		t.Errorf("focused: border %v, fill %v", focused.Border, focused.Fill)
+	}
+	if hud := HUDBarStyle(); hud.Fill != xgal.Paint(0xff, 0, 0, 0xff) || hud.Margin != xgal.Pt(4, 4) {
+		t.Errorf("hud: fill %v, margin %v", hud.Fill, hud.Margin)
+	}
+
+	theme = nil
+	u.Restyle()
+	if button.Style != ButtonStyle() || button.Bounds != plain {
+		t.Errorf("button not restored: %v", button.Bounds)
+	}
+}
+
+func TestScale(t *testing.T) {
+	defer func() { xgal.UIScale = 1 }()
+
+	u := &UI{}
+	l := u.Append(NewLayer(xgal.Rect(0, 0, 100, 100)))
+	button := l.Button("OK")
+	plain := button.Bounds
+
+	xgal.UIScale = 2
+	u.Restyle()
+	if button.Style.Margin != xgal.Pt(4, 0) || button.Style.Stroke != 2 {
+		t.Errorf("button: margin %v, stroke %d", button.Style.Margin, button.Style.Stroke)
+	}
+	if button.Bounds.Dx() != plain.Dx()*2 || button.Bounds.Dy() != plain.Dy()*2 {
+		t.Errorf("button not scaled: %v, was %v", button.Bounds, plain)
+	}
+
+	layout, err := ReadLayout(strings.NewReader(`<layout><layer id="a" x="10" y="5" w="40" h="20"/></layout>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := layout.Build(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bounds := v.Layer("a").Bounds; bounds != xgal.Rect(20, 10, 100, 50) {
+		t.Errorf("layout not scaled: %v", bounds)
+	}
+	if bounds := uiBound(10, 5, 40, 20); bounds != xgal.Rect(20, 10, 100, 50) {
+		t.Errorf("helper bounds not scaled: %v", bounds)
+	}
+
+	xgal.UIScale = 1
+	u.Restyle()
+	if button.Style != ButtonStyle() || button.Bounds != plain {
+		t.Errorf("button not restored: %v", button.Bounds)
+	}
+}
@@ -54,6 +54,7 @@ func NewToggle(at xgal.Point, text string, group *Group) *Control {
 		Click:   click,
 		Release: release,
 		Hover:   hover,
+		Say:     sayChecked(toggle, "on", "off"),
 	}
 	return toggle
 }
@@ -0,0 +1,55 @@
+package xlui
+
+// SetText changes the text of the control. Controls that lay out their
+// text do so again, other controls are resized to fit the text.
+func (c *Control) SetText(text string) {
+	if c.Class.Relabel != nil {
+		c.Class.Relabel(text)
+		return
+	}
+	c.Text = text
+	if text == "" {
+		return
+	}
+	size := c.Style.Measure(text).Add(c.Style.Margin.Mul(2))
+	c.Bounds.Max = c.Bounds.Min.Add(size)
+}
+
+// Reflow places the controls after each other again, for example after
+// their texts changed size.
+func (l *Layer) Reflow() {
+	for i := 1; i < len(l.Controls); i++ {
+		l.place(l.Controls[i], l.Controls[i-1], l.Bounds.Min.X)
+	}
+}
+
+// Translate sets the texts of the controls that have a key to tr of that
+// key, and reflows the layer if any text changed.
+func (l *Layer) Translate(tr func(key string) string) {
+	changed := false
+	for _, ctrl := range l.Controls {
+		if ctrl.Key == "" {
+			continue
+		}
+		if text := tr(ctrl.Key); text != ctrl.Text {
+			ctrl.SetText(text)
+			changed = true
+		}
+	}
+	if changed {
+		l.Reflow()
+	}
+}
+
+// Translate translates all layers of the UI.
+func (u *UI) Translate(tr func(key string) string) {
+	for _, layer := range u.Layers {
+		layer.Translate(tr)
+	}
+}
+
+// Translate translates all layers of the global UI, for example after
+// switching the language.
+func Translate(tr func(key string) string) {
+	xlui.Translate(tr)
+}
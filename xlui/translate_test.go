@@ -0,0 +1,23 @@
+package xlui
+
+import "testing"
+
+func TestLayerTranslate(t *testing.T) {
+	l := testLayer()
+	first := l.Label("Save")
+	first.Key = "save"
+	second := l.Label("Load")
+	second.Key = "load"
+	plain := l.Label("Plain")
+	words := map[string]string{"save": "Opslaan als bestand", "load": "Laden"}
+	l.Translate(func(key string) string { return words[key] })
+	if first.Text != words["save"] || second.Text != words["load"] || plain.Text != "Plain" {
+		t.Fatalf("texts: %q %q %q", first.Text, second.Text, plain.Text)
+	}
+	if first.Bounds.Dx() <= second.Bounds.Dx() {
+		t.Errorf("the longer text should be wider: %v %v", first.Bounds, second.Bounds)
+	}
+	if second.Bounds.Min.Y < first.Bounds.Max.Y && second.Bounds.Min.X < first.Bounds.Max.X {
+		t.Errorf("controls overlap after reflow: %v %v", first.Bounds, second.Bounds)
+	}
+}
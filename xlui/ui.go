@@ -11,6 +11,11 @@ type UI struct {
 	Dragged    *Layer   // Layer that is currently being dragged.
 	LastCursor xgal.Point
 	Mods       Mods
+	Timeline   xgal.Timeline // Timeline plays the animations of the UI on every Tick.
+	Still      bool          // Still turns off the animations of menus, dialogs and buttons.
+
+	sticks map[xgal.PadID]xgal.Point // Directions of the gamepad sticks.
+	fading []*Layer                  // Layers that were closed and fade out.
 }
 
 func (u *UI) Append(l *Layer) *Layer {
@@ -26,6 +31,9 @@ func (u *UI) Poll() Reply {
 	u.Tick(xgal.Tick())
 
 	kr := u.pollKeys()
+	if pr := u.pollPads(); pr != Ignore {
+		kr = pr
+	}
 
 	for mb := xgal.MouseButton(0); mb < xgal.MouseButtonMax; mb++ {
 		cursor := xgal.Cursor()
@@ -141,12 +149,16 @@ func (u *UI) focusOrTop() *Layer {
 	return u.Layers[len(u.Layers)-1]
 }
 
+// Tap passes the key to the focused or top layer. If the layer ignores
+// it, the arrow keys move the focus and Enter clicks the focused control.
 func (u *UI) Tap(key xgal.KeyCode, mods Mods) Reply {
 	top := u.focusOrTop()
 	if top != nil {
-		return top.OnTap(int(key), mods)
+		if res := top.OnTap(int(key), mods); res != Ignore {
+			return res
+		}
 	}
-	return Ignore
+	return u.navigate(key, mods)
 }
 
 func (u *UI) Key(key xgal.KeyCode, duration int) Reply {
@@ -174,6 +186,7 @@ func (u *UI) Chars(chars ...rune) Reply {
 }
 
 func (u *UI) Tick(tick int64) Reply {
+	u.Timeline.Tick()
 	// Tick is passed to all layers since it is used for animation.
 	res := Ignore
 	for i, layer := range u.Layers {
@@ -225,6 +238,9 @@ func (u *UI) closeLayerByIndex(i int) {
 		u.SetFocus(nil)
 	}
 	u.Layers = slices.Delete(u.Layers, i, i+1)
+	if del.Motion != nil && !u.Still {
+		u.fadeOut(del)
+	}
 }
 
 func (u *UI) setFocusByIndex(i int) {
@@ -307,6 +323,9 @@ func (u *UI) Click(at xgal.Point, button int) Reply {
 		}
 
 		res := layer.OnClick(at, button)
+		if ctrl := layer.Clicked; res != Ignore && ctrl != nil && ctrl.State.Clicked {
+			u.Bounce(ctrl)
+		}
 		if res != Accept {
 			if u.Dragged == nil && !layer.Lock {
 				u.Dragged = layer
@@ -349,6 +368,9 @@ func (u *UI) Render(s *xgal.Surface) {
 			layer.Render(s)
 		}
 	}
+	for _, layer := range u.fading {
+		layer.Render(s)
+	}
 }
 
 func (u *UI) Hover(at xgal.Point) Reply {
@@ -370,23 +392,27 @@ func (u *UI) Layer(bounds xgal.Rectangle) *Layer {
 func (u *UI) Asker(bounds xgal.Rectangle, label, entry string, buttons ...string) *Layer {
 	layer := NewAsker(bounds, label, entry, buttons...)
 	u.Append(layer)
+	u.FadeIn(layer)
 	return layer
 }
 
 func (u *UI) Menu(bounds xgal.Rectangle, options ...string) *Layer {
 	layer := NewMenu(bounds, options...)
 	u.Append(layer)
+	u.SlideIn(layer, xgal.Pt(0, -MenuSlide))
 	return layer
 }
 
 func (u *UI) MenuWithValueOffset(bounds xgal.Rectangle, offset int, options ...string) *Layer {
 	layer := NewMenuWithValueOffset(bounds, offset, options...)
 	u.Append(layer)
+	u.SlideIn(layer, xgal.Pt(0, -MenuSlide))
 	return layer
 }
 
 func (u *UI) Dialog(bounds xgal.Rectangle, label string, buttons ...string) *Layer {
 	layer := NewDialog(bounds, label, buttons...)
 	u.Append(layer)
+	u.FadeIn(layer)
 	return layer
 }
@@ -20,6 +20,7 @@
 // accept input must accept it.
 package xlui
 
+import "io/fs"
 import "strconv"
 import "github.com/xmasengine/xmas/xgal"
 
@@ -48,8 +49,14 @@ func Asker(bounds xgal.Rectangle, label, entry string, buttons ...string) *Layer
 	return xlui.Asker(bounds, label, entry, buttons...)
 }
 
+// uiBound returns the bounds for the helpers that take x, y, w and h,
+// scaled by [xgal.UIScale] like those of layouts.
+func uiBound(x, y, w, h int) xgal.Rectangle {
+	return xgal.UIRect(xgal.Bound(x, y, w, h))
+}
+
 func Ask(x, y, w, h int, label, entry string, handler func(name string) bool) *Layer {
-	ask := xlui.Asker(xgal.Bound(x, y, w, h), label, entry, " X ", " V ")
+	ask := xlui.Asker(uiBound(x, y, w, h), label, entry, " X ", " V ")
 	ask.Class.Entry = func(v string) Reply {
 		if handler(v) {
 			return Finish
@@ -60,13 +67,28 @@ func Ask(x, y, w, h int, label, entry string, handler func(name string) bool) *L
 	return ask
 }
 
+// FileDialog adds a file dialog that browses fsys and calls the handler
+// with the path of the chosen file. Only files with one of the extensions
+// are shown. If save is set, the file is saved rather than opened.
+func FileDialog(x, y, w, h int, prompt string, fsys fs.FS, name string, save bool, handler func(name string) bool, exts ...string) *FileDialogLayer {
+	dialog := xlui.FileDialog(uiBound(x, y, w, h), prompt, fsys, name, save, exts...)
+	dialog.Class.Entry = func(name string) Reply {
+		if handler(name) {
+			return Finish
+		} else {
+			return Accept
+		}
+	}
+	return dialog
+}
+
 func Complain(x, y, w, h int, err error) *Layer {
-	complain := xlui.Complain(xgal.Bound(x, y, w, h), err)
+	complain := xlui.Complain(uiBound(x, y, w, h), err)
 	return complain
 }
 
 func Display(x, y, w, h int, text string) *Layer {
-	display := xlui.Display(xgal.Bound(x, y, w, h), text)
+	display := xlui.Display(uiBound(x, y, w, h), text)
 	return display
 }
 
@@ -115,7 +137,7 @@ func Dialog(x, y, w, h int, prompt string, handler func(v int) bool, buttons ...
 		}
 	}
 
-	dialog := xlui.Dialog(xgal.Bound(x, y, w, h), prompt, buttons...)
+	dialog := xlui.Dialog(uiBound(x, y, w, h), prompt, buttons...)
 	dialog.Class.Value = value
 	return dialog
 }
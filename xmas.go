@@ -7,6 +7,7 @@ import (
 	"os"
 	"runtime/pprof"
 
+	"github.com/xmasengine/xmas/xdat"
 	"github.com/xmasengine/xmas/xeng"
 	"github.com/xmasengine/xmas/xgal"
 )
@@ -14,8 +15,10 @@ import (
 func main() {
 	prof := ""
 	pmem := ""
+	contrast := false
 	flag.StringVar(&prof, "P", "", "pprof profile file")
 	flag.StringVar(&pmem, "M", "", "memory profile file")
+	flag.BoolVar(&contrast, "contrast", false, "use the high contrast theme of the UI")
 	flag.Parse()
 
 	if prof != "" {
@@ -45,6 +48,11 @@ func main() {
 	en := xeng.New(mon.Size())
 	logger := en.Log.Logger()
 	slog.SetDefault(logger)
+	if contrast {
+		if err := en.SetTheme(xdat.ContrastTheme); err != nil {
+			slog.Error("setting theme", "err", err)
+		}
+	}
 	if err := xgal.Play(en); err != nil {
 		fmt.Printf("error: %s", err)
 		os.Exit(1)
@@ -0,0 +1,651 @@
+package xres
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"io"
+	"io/fs"
+)
+
+import (
+	"github.com/xmasengine/xmas/xdat"
+	"github.com/xmasengine/xmas/xgal"
+)
+
+// Color modes of Aseprite files, as bits per pixel.
+const (
+	AseIndexed   = 8
+	AseGrayscale = 16
+	AseRGBA      = 32
+)
+
+// Flags of Aseprite layers.
+const (
+	AseVisible    = 1
+	AseBackground = 8
+)
+
+// Types of Aseprite layers.
+const (
+	AseImageLayer   = 0
+	AseGroupLayer   = 1
+	AseTilemapLayer = 2
+)
+
+// Chunk types of Aseprite files that are read. Other chunks are skipped.
+const (
+	aseOldPalette  = 0x0004
+	aseOldPalette6 = 0x0011
+	aseLayerChunk  = 0x2004
+	aseCelChunk    = 0x2005
+	aseTagsChunk   = 0x2018
+	asePalette     = 0x2019
+	aseSliceChunk  = 0x2022
+)
+
+// aseMaxColors is the most palette entries that are read, which keeps a
+// broken palette chunk from growing the palette without bounds. Indexed
+// sprites have at most 256.
+const aseMaxColors = 1 << 16
+
+// Cel types of Aseprite files.
+const (
+	aseRawCel        = 0
+	aseLinkedCel     = 1
+	aseCompressedCel = 2
+)
+
+const (
+	aseFileMagic  = 0xA5E0
+	aseFrameMagic = 0xF1FA
+)
+
+// AseDirections are the names of the directions of Aseprite tags, as
+// [xdat.TagClip] takes them.
+var AseDirections = []string{"forward", "reverse", "pingpong", "pingpong_reverse"}
+
+// AseLayer is a layer of an Aseprite file.
+type AseLayer struct {
+	Name    string
+	Flags   uint16
+	Type    uint16
+	Level   int   // Level is how deep the layer is in groups.
+	Blend   int   // Blend is the blend mode, only normal is supported.
+	Opacity uint8 // Opacity of the layer.
+}
+
+// AseCel is the image of a layer in a frame. Its image is an
+// *image.Paletted in indexed mode, and an *image.NRGBA otherwise.
+type AseCel struct {
+	Layer   int
+	At      image.Point
+	Opacity uint8
+	Image   image.Image
+}
+
+// AseFrame is a frame of an Aseprite file.
+type AseFrame struct {
+	Duration int // Duration in milliseconds.
+	Cels     []AseCel
+}
+
+// AseTag is a named range of frames of an Aseprite file.
+type AseTag struct {
+	Name      string
+	From, To  int
+	Direction string // Direction, one of AseDirections.
+	Repeat    int    // Repeat is how often the tag plays, 0 for forever.
+}
+
+// AseSliceKey is where a slice is from a frame on.
+type AseSliceKey struct {
+	Frame  int
+	Bounds image.Rectangle
+	Pivot  image.Point
+}
+
+// AseSlice is a named area of an Aseprite file.
+type AseSlice struct {
+	Name string
+	Keys []AseSliceKey
+}
+
+// Aseprite is a parsed .aseprite or .ase file.
+type Aseprite struct {
+	Width, Height int
+	Depth         int           // Depth is the color mode, AseIndexed, AseGrayscale or AseRGBA.
+	Transparent   uint8         // Transparent is the transparent index in indexed mode.
+	Palette       color.Palette // Palette of the sprite.
+	Layers        []AseLayer
+	Frames        []AseFrame
+	Tags          []AseTag
+	Slices        []AseSlice
+}
+
+// aseReader reads the little endian values of Aseprite files, and keeps
+// the first error.
+type aseReader struct {
+	buf []byte
+	err error
+}
+
+func (r *aseReader) bytes(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(r.buf) {
+		r.err = io.ErrUnexpectedEOF
+		r.buf = nil
+		return nil
+	}
+	b := r.buf[:n]
+	r.buf = r.buf[n:]
+	return b
+}
+
+func (r *aseReader) byte() uint8 {
+	if b := r.bytes(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (r *aseReader) word() uint16 {
+	if b := r.bytes(2); b != nil {
+		return binary.LittleEndian.Uint16(b)
+	}
+	return 0
+}
+
+func (r *aseReader) short() int {
+	return int(int16(r.word()))
+}
+
+func (r *aseReader) dword() uint32 {
+	if b := r.bytes(4); b != nil {
+		return binary.LittleEndian.Uint32(b)
+	}
+	return 0
+}
+
+func (r *aseReader) long() int {
+	return int(int32(r.dword()))
+}
+
+func (r *aseReader) string() string {
+	return string(r.bytes(int(r.word())))
+}
+
+// ReadAseprite reads an Aseprite file.
+func ReadAseprite(rd io.Reader) (*Aseprite, error) {
+	buf, err := io.ReadAll(rd)
+	if err != nil {
+		return nil, err
+	}
+	r := &aseReader{buf: buf}
+	r.dword() // File size.
+	if r.word() != aseFileMagic {
+		return nil, errors.New("not an aseprite file")
+	}
+	ase := &Aseprite{}
+	frames := int(r.word())
+	ase.Width, ase.Height = int(r.word()), int(r.word())
+	ase.Depth = int(r.word())
+	flags := r.dword()
+	r.bytes(2 + 4 + 4) // Speed and reserved.
+	ase.Transparent = r.byte()
+	r.bytes(3)
+	r.word()          // Number of colors.
+	r.bytes(128 - 34) // Pixel ratio, grid and reserved.
+	if r.err != nil {
+		return nil, r.err
+	}
+	switch ase.Depth {
+	case AseIndexed, AseGrayscale, AseRGBA:
+	default:
+		return nil, fmt.Errorf("unknown color depth %d", ase.Depth)
+	}
+	if ase.Width <= 0 || ase.Height <= 0 {
+		return nil, fmt.Errorf("bad size %dx%d", ase.Width, ase.Height)
+	}
+
+	newPalette := false
+	for i := 0; i < frames; i++ {
+		size := int(r.dword())
+		frame := &aseReader{buf: r.bytes(size - 4)}
+		if r.err != nil {
+			return nil, fmt.Errorf("frame %d: %w", i, r.err)
+		}
+		if frame.word() != aseFrameMagic {
+			return nil, fmt.Errorf("frame %d: bad magic", i)
+		}
+		chunks := int(frame.word())
+		duration := int(frame.word())
+		frame.bytes(2)
+		if n := frame.dword(); n != 0 {
+			chunks = int(n)
+		}
+		ase.Frames = append(ase.Frames, AseFrame{Duration: duration})
+
+		for c := 0; c < chunks && frame.err == nil; c++ {
+			size := int(frame.dword())
+			kind := frame.word()
+			chunk := &aseReader{buf: frame.bytes(size - 6)}
+			if frame.err != nil {
+				break
+			}
+			switch kind {
+			case asePalette:
+				newPalette = true
+				ase.readPalette(chunk)
+			case aseOldPalette, aseOldPalette6:
+				if !newPalette {
+					ase.readOldPalette(chunk, kind == aseOldPalette6)
+				}
+			case aseLayerChunk:
+				ase.readLayer(chunk, flags&1 != 0)
+			case aseCelChunk:
+				err = ase.readCel(chunk, i)
+			case aseTagsChunk:
+				ase.readTags(chunk)
+			case aseSliceChunk:
+				ase.readSlice(chunk)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("frame %d: %w", i, err)
+			}
+			if chunk.err != nil {
+				return nil, fmt.Errorf("frame %d: chunk %#x: %w", i, kind, chunk.err)
+			}
+		}
+		if frame.err != nil {
+			return nil, fmt.Errorf("frame %d: %w", i, frame.err)
+		}
+	}
+	if ase.Depth == AseIndexed {
+		// Every index has a color, and the transparent index is transparent
+		// whatever its color.
+		ase.setColor(255, ase.colorAt(255))
+		ase.Palette[ase.Transparent] = color.RGBA{}
+	}
+	return ase, nil
+}
+
+// colorAt returns color i of the palette, or transparent if there is none.
+func (ase *Aseprite) colorAt(i int) color.Color {
+	if i < len(ase.Palette) {
+		return ase.Palette[i]
+	}
+	return color.RGBA{}
+}
+
+// setColor sets color i of the palette, growing it if needed.
+func (ase *Aseprite) setColor(i int, c color.Color) {
+	for len(ase.Palette) <= i {
+		ase.Palette = append(ase.Palette, color.RGBA{})
+	}
+	ase.Palette[i] = c
+}
+
+func (ase *Aseprite) readPalette(r *aseReader) {
+	r.dword() // New size.
+	first, last := int(r.dword()), int(r.dword())
+	r.bytes(8)
+	if r.err != nil {
+		return
+	}
+	// Every entry takes at least 6 bytes.
+	most := aseMaxColors
+	if ase.Depth == AseIndexed {
+		most = 256
+	}
+	if first > last || last-first >= len(r.buf)/6 || last >= most {
+		r.err = fmt.Errorf("bad palette entries %d to %d", first, last)
+		return
+	}
+	for i := first; i <= last && r.err == nil; i++ {
+		flags := r.word()
+		c := color.NRGBA{R: r.byte(), G: r.byte(), B: r.byte(), A: r.byte()}
+		if flags&1 != 0 {
+			r.string()
+		}
+		ase.setColor(i, color.RGBAModel.Convert(c))
+	}
+}
+
+func (ase *Aseprite) readOldPalette(r *aseReader, six bool) {
+	packets := int(r.word())
+	index := 0
+	for p := 0; p < packets && r.err == nil; p++ {
+		index += int(r.byte())
+		count := int(r.byte())
+		if count == 0 {
+			count = 256
+		}
+		for k := 0; k < count && r.err == nil; k++ {
+			c := color.RGBA{R: r.byte(), G: r.byte(), B: r.byte(), A: 255}
+			if six {
+				c.R, c.G, c.B = c.R<<2|c.R>>4, c.G<<2|c.G>>4, c.B<<2|c.B>>4
+			}
+			ase.setColor(index, c)
+			index++
+		}
+	}
+}
+
+func (ase *Aseprite) readLayer(r *aseReader, opacity bool) {
+	layer := AseLayer{Flags: r.word(), Type: r.word(), Level: int(r.word())}
+	r.bytes(4) // Default size.
+	layer.Blend = int(r.word())
+	layer.Opacity = r.byte()
+	if !opacity {
+		layer.Opacity = 255
+	}
+	r.bytes(3)
+	layer.Name = r.string()
+	ase.Layers = append(ase.Layers, layer)
+}
+
+func (ase *Aseprite) readCel(r *aseReader, frame int) error {
+	cel := AseCel{Layer: int(r.word())}
+	cel.At = image.Pt(r.short(), r.short())
+	cel.Opacity = r.byte()
+	kind := r.word()
+	r.bytes(2 + 5) // Z index and reserved.
+
+	var pix []byte
+	w, h := 0, 0
+	switch kind {
+	case aseRawCel:
+		w, h = int(r.word()), int(r.word())
+		pix = r.buf
+	case aseLinkedCel:
+		from := int(r.word())
+		if from >= frame {
+			return fmt.Errorf("cel links to frame %d", from)
+		}
+		for _, linked := range ase.Frames[from].Cels {
+			if linked.Layer == cel.Layer {
+				cel.Image = linked.Image
+				cel.At = linked.At
+			}
+		}
+		if cel.Image == nil {
+			return fmt.Errorf("cel links to an empty cel of frame %d", from)
+		}
+	case aseCompressedCel:
+		w, h = int(r.word()), int(r.word())
+		zr, err := zlib.NewReader(bytes.NewReader(r.buf))
+		if err != nil {
+			return err
+		}
+		pix, err = io.ReadAll(zr)
+		if err != nil {
+			return err
+		}
+	default:
+		// Tilemap cels are not supported and are not drawn.
+		return nil
+	}
+	if r.err != nil {
+		return r.err
+	}
+	if cel.Image == nil {
+		img, err := ase.celImage(w, h, pix)
+		if err != nil {
+			return err
+		}
+		cel.Image = img
+	}
+	f := &ase.Frames[frame]
+	f.Cels = append(f.Cels, cel)
+	return nil
+}
+
+// celImage makes the image of a cel from its pixels.
+func (ase *Aseprite) celImage(w, h int, pix []byte) (image.Image, error) {
+	bpp := ase.Depth / 8
+	if len(pix) < w*h*bpp {
+		return nil, fmt.Errorf("cel of %dx%d has %d bytes", w, h, len(pix))
+	}
+	bounds := image.Rect(0, 0, w, h)
+	switch ase.Depth {
+	case AseIndexed:
+		img := image.NewPaletted(bounds, nil)
+		copy(img.Pix, pix)
+		return img, nil
+	case AseGrayscale:
+		img := image.NewNRGBA(bounds)
+		for i := 0; i < w*h; i++ {
+			v, a := pix[i*2], pix[i*2+1]
+			copy(img.Pix[i*4:], []byte{v, v, v, a})
+		}
+		return img, nil
+	default:
+		img := image.NewNRGBA(bounds)
+		copy(img.Pix, pix)
+		return img, nil
+	}
+}
+
+func (ase *Aseprite) readTags(r *aseReader) {
+	count := int(r.word())
+	r.bytes(8)
+	for i := 0; i < count && r.err == nil; i++ {
+		tag := AseTag{From: int(r.word()), To: int(r.word())}
+		dir := int(r.byte())
+		tag.Repeat = int(r.word())
+		r.bytes(6 + 3 + 1) // Reserved and color.
+		tag.Name = r.string()
+		if dir < len(AseDirections) {
+			tag.Direction = AseDirections[dir]
+		}
+		ase.Tags = append(ase.Tags, tag)
+	}
+}
+
+func (ase *Aseprite) readSlice(r *aseReader) {
+	count := int(r.dword())
+	flags := r.dword()
+	r.dword()
+	slice := AseSlice{Name: r.string()}
+	for i := 0; i < count && r.err == nil; i++ {
+		key := AseSliceKey{Frame: int(r.dword())}
+		x, y := r.long(), r.long()
+		w, h := int(r.dword()), int(r.dword())
+		key.Bounds = image.Rect(x, y, x+w, y+h)
+		if flags&1 != 0 {
+			r.bytes(16) // Nine patch center.
+		}
+		if flags&2 != 0 {
+			key.Pivot = image.Pt(r.long(), r.long())
+		}
+		slice.Keys = append(slice.Keys, key)
+	}
+	ase.Slices = append(ase.Slices, slice)
+}
+
+// visible returns whether the layer and the groups it is in are visible.
+func (ase *Aseprite) visible(layer int) bool {
+	level := ase.Layers[layer].Level
+	if ase.Layers[layer].Flags&AseVisible == 0 {
+		return false
+	}
+	for i := layer - 1; i >= 0 && level > 0; i-- {
+		if ase.Layers[i].Level < level {
+			if ase.Layers[i].Flags&AseVisible == 0 {
+				return false
+			}
+			level = ase.Layers[i].Level
+		}
+	}
+	return true
+}
+
+// Frame draws the cels of the visible layers of the frame. The image is an
+// *image.Paletted in indexed mode, and an *image.RGBA otherwise.
+func (ase *Aseprite) Frame(i int) image.Image {
+	img := ase.canvas(image.Rect(0, 0, ase.Width, ase.Height))
+	ase.draw(img, i, image.Point{})
+	return img
+}
+
+func (ase *Aseprite) canvas(bounds image.Rectangle) draw.Image {
+	if ase.Depth == AseIndexed {
+		img := image.NewPaletted(bounds, ase.Palette)
+		if ase.Transparent != 0 {
+			for i := range img.Pix {
+				img.Pix[i] = ase.Transparent
+			}
+		}
+		return img
+	}
+	return image.NewRGBA(bounds)
+}
+
+// draw draws frame i at the offset. Layers draw in their order, from the
+// bottom up, and all blend modes blend as normal.
+func (ase *Aseprite) draw(dst draw.Image, i int, offset image.Point) {
+	frame := image.Rect(0, 0, ase.Width, ase.Height).Add(offset)
+	for l := range ase.Layers {
+		layer := ase.Layers[l]
+		if layer.Type != AseImageLayer || !ase.visible(l) {
+			continue
+		}
+		for _, cel := range ase.Frames[i].Cels {
+			if cel.Layer != l {
+				continue
+			}
+			at := cel.At.Add(offset)
+			r := cel.Image.Bounds().Add(at).Intersect(frame)
+			if pdst, ok := dst.(*image.Paletted); ok {
+				src := cel.Image.(*image.Paletted)
+				for y := r.Min.Y; y < r.Max.Y; y++ {
+					for x := r.Min.X; x < r.Max.X; x++ {
+						index := src.ColorIndexAt(x-at.X, y-at.Y)
+						if index != ase.Transparent || layer.Flags&AseBackground != 0 {
+							pdst.SetColorIndex(x, y, index)
+						}
+					}
+				}
+				continue
+			}
+			alpha := uint16(cel.Opacity) * uint16(layer.Opacity) / 255
+			mask := image.NewUniform(color.Alpha{A: uint8(alpha)})
+			draw.DrawMask(dst, r, cel.Image, r.Min.Sub(at), mask, image.Point{}, draw.Over)
+		}
+	}
+}
+
+// SheetColumns is the most frames in a row of the sheet of an Aseprite file.
+const SheetColumns = 16
+
+// Columns returns how many frames are in a row of the sheet.
+func (ase *Aseprite) Columns() int {
+	return max(1, min(len(ase.Frames), SheetColumns))
+}
+
+// Sheet draws all frames in a sprite sheet, left to right and then top to
+// bottom, in rows of Columns frames. The image is an *image.Paletted in
+// indexed mode, and an *image.RGBA otherwise.
+func (ase *Aseprite) Sheet() image.Image {
+	columns := ase.Columns()
+	rows := max(1, (len(ase.Frames)+columns-1)/columns)
+	img := ase.canvas(image.Rect(0, 0, columns*ase.Width, rows*ase.Height))
+	for i := range ase.Frames {
+		ase.draw(img, i, image.Pt(i%columns*ase.Width, i/columns*ase.Height))
+	}
+	return img
+}
+
+// Texture draws the sheet of the file on a surface.
+func (ase *Aseprite) Texture() *xgal.Surface {
+	return xgal.Bake(ase.Sheet())
+}
+
+// Animations returns the animations of the sheet of the file. Every tag
+// becomes a clip and the slices set the hotspots and hit boxes, as they
+// do for the JSON that Aseprite exports.
+func (ase *Aseprite) Animations() *xdat.Animations {
+	a := &xdat.Animations{W: ase.Width, H: ase.Height, Columns: ase.Columns()}
+	a.XMLName.Local = "animations"
+	frames := make([]xdat.Frame, len(ase.Frames))
+	for i, f := range ase.Frames {
+		frames[i] = xdat.Frame{
+			X: i % a.Columns * a.W, Y: i / a.Columns * a.H, W: a.W, H: a.H,
+			Ticks: xdat.MillisecondsToTicks(f.Duration),
+		}
+	}
+	for _, slice := range ase.Slices {
+		for _, key := range slice.Keys {
+			xdat.SliceFrames(frames, slice.Name, key.Frame, key.Bounds, key.Pivot)
+		}
+	}
+	for _, tag := range ase.Tags {
+		if tag.From < 0 || tag.To >= len(frames) || tag.From > tag.To {
+			continue
+		}
+		a.Clips = append(a.Clips, xdat.TagClip(tag.Name, tag.Direction, tag.Repeat == 1, frames[tag.From:tag.To+1]))
+	}
+	if len(a.Clips) == 0 {
+		a.Clips = append(a.Clips, xdat.Clip{Action: "default", Direction: xdat.AnyDirection, Frames: frames})
+	}
+	return a
+}
+
+// LoadAseprite loads an Aseprite file from fsys.
+func LoadAseprite(fsys fs.FS, name string) (*Aseprite, error) {
+	fin, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer fin.Close()
+	ase, err := ReadAseprite(fin)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return ase, nil
+}
+
+func decodeAseprite(rd io.Reader) (image.Image, error) {
+	ase, err := ReadAseprite(rd)
+	if err != nil {
+		return nil, err
+	}
+	return ase.Sheet(), nil
+}
+
+func decodeAsepriteConfig(rd io.Reader) (image.Config, error) {
+	ase, err := ReadAseprite(rd)
+	if err != nil {
+		return image.Config{}, err
+	}
+	bounds := ase.Sheet().Bounds()
+	config := image.Config{Width: bounds.Dx(), Height: bounds.Dy(), ColorModel: color.RGBAModel}
+	if ase.Depth == AseIndexed {
+		config.ColorModel = ase.Palette
+	}
+	return config, nil
+}
+
+func readAsepriteAnimations(rd io.Reader) (*xdat.Animations, error) {
+	ase, err := ReadAseprite(rd)
+	if err != nil {
+		return nil, err
+	}
+	return ase.Animations(), nil
+}
+
+// Aseprite files decode as images of their sheet, so textures can be
+// loaded from them directly, and as animations of that sheet.
+func init() {
+	image.RegisterFormat("aseprite", "????\xe0\xa5", decodeAseprite, decodeAsepriteConfig)
+	xdat.RegisterAnimations(".aseprite", readAsepriteAnimations)
+	xdat.RegisterAnimations(".ase", readAsepriteAnimations)
+}
@@ -0,0 +1,253 @@
+package xres
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+import (
+	"github.com/xmasengine/xmas/xdat"
+)
+
+// le encodes values little endian, and strings as Aseprite does.
+func le(values ...any) []byte {
+	buf := &bytes.Buffer{}
+	for _, v := range values {
+		switch v := v.(type) {
+		case string:
+			binary.Write(buf, binary.LittleEndian, uint16(len(v)))
+			buf.WriteString(v)
+		case []byte:
+			buf.Write(v)
+		default:
+			binary.Write(buf, binary.LittleEndian, v)
+		}
+	}
+	return buf.Bytes()
+}
+
+type aseChunk struct {
+	kind uint16
+	data []byte
+}
+
+type aseTestFrame struct {
+	duration uint16
+	chunks   []aseChunk
+}
+
+// aseFile encodes an Aseprite file.
+func aseFile(w, h, depth int, transparent uint8, frames ...aseTestFrame) []byte {
+	body := &bytes.Buffer{}
+	for _, f := range frames {
+		chunks := &bytes.Buffer{}
+		for _, c := range f.chunks {
+			chunks.Write(le(uint32(len(c.data)+6), c.kind, c.data))
+		}
+		body.Write(le(uint32(16+chunks.Len()), uint16(aseFrameMagic), uint16(len(f.chunks)), f.duration, uint16(0), uint32(0), chunks.Bytes()))
+	}
+	header := le(uint32(128+body.Len()), uint16(aseFileMagic), uint16(len(frames)), uint16(w), uint16(h), uint16(depth),
+		uint32(1), uint16(100), uint32(0), uint32(0), transparent, make([]byte, 3), uint16(0), make([]byte, 94))
+	return append(header, body.Bytes()...)
+}
+
+func aseLayer(name string, flags, kind, level uint16, opacity uint8) aseChunk {
+	return aseChunk{aseLayerChunk, le(flags, kind, level, uint16(0), uint16(0), uint16(0), opacity, make([]byte, 3), name)}
+}
+
+func aseRaw(layer uint16, x, y int16, w, h uint16, pix []byte) aseChunk {
+	return aseChunk{aseCelChunk, le(layer, x, y, uint8(255), uint16(aseRawCel), int16(0), make([]byte, 5), w, h, pix)}
+}
+
+func aseCompressed(layer uint16, x, y int16, w, h uint16, pix []byte) aseChunk {
+	buf := &bytes.Buffer{}
+	zw := zlib.NewWriter(buf)
+	zw.Write(pix)
+	zw.Close()
+	return aseChunk{aseCelChunk, le(layer, x, y, uint8(255), uint16(aseCompressedCel), int16(0), make([]byte, 5), w, h, buf.Bytes())}
+}
+
+func aseLinked(layer uint16, frame uint16) aseChunk {
+	return aseChunk{aseCelChunk, le(layer, int16(0), int16(0), uint8(255), uint16(aseLinkedCel), int16(0), make([]byte, 5), frame)}
+}
+
+var testPalette = aseChunk{asePalette, le(uint32(4), uint32(0), uint32(3), make([]byte, 8),
+	uint16(0), []byte{0, 0, 0, 255},
+	uint16(0), []byte{255, 0, 0, 255},
+	uint16(1), []byte{0, 255, 0, 255}, "green",
+	uint16(0), []byte{0, 0, 255, 255},
+)}
+
+// testIndexed is a 4x4 indexed sprite of two frames: a red background, a
+// hidden group with a blue layer, and a top layer with a green square in
+// the first frame and a blue pixel in the second.
+func testIndexed() []byte {
+	tags := aseChunk{aseTagsChunk, le(uint16(2), make([]byte, 8),
+		uint16(0), uint16(1), uint8(2), uint16(0), make([]byte, 10), "walk_east",
+		uint16(1), uint16(1), uint8(0), uint16(1), make([]byte, 10), "hit",
+	)}
+	hotspot := aseChunk{aseSliceChunk, le(uint32(1), uint32(2), uint32(0), "hotspot",
+		uint32(0), int32(0), int32(0), uint32(4), uint32(4), int32(2), int32(3))}
+	hitbox := aseChunk{aseSliceChunk, le(uint32(1), uint32(0), uint32(0), "hitbox",
+		uint32(1), int32(1), int32(1), uint32(2), uint32(2))}
+	return aseFile(4, 4, AseIndexed, 0,
+		aseTestFrame{100, []aseChunk{
+			testPalette,
+			aseLayer("background", AseVisible|AseBackground, AseImageLayer, 0, 255),
+			aseLayer("group", 0, AseGroupLayer, 0, 255),
+			aseLayer("hidden", AseVisible, AseImageLayer, 1, 255),
+			aseLayer("top", AseVisible, AseImageLayer, 0, 255),
+			aseRaw(0, 0, 0, 4, 4, bytes.Repeat([]byte{1}, 16)),
+			aseRaw(2, 0, 0, 4, 4, bytes.Repeat([]byte{3}, 16)),
+			aseCompressed(3, 1, 1, 2, 2, []byte{2, 2, 2, 0}),
+			tags, hotspot, hitbox,
+		}},
+		aseTestFrame{50, []aseChunk{
+			aseLinked(0, 0),
+			aseRaw(3, 3, 3, 1, 1, []byte{3}),
+		}},
+	)
+}
+
+func indexes(img *image.Paletted) []uint8 {
+	var pix []uint8
+	for y := img.Rect.Min.Y; y < img.Rect.Max.Y; y++ {
+		for x := img.Rect.Min.X; x < img.Rect.Max.X; x++ {
+			pix = append(pix, img.ColorIndexAt(x, y))
+		}
+	}
+	return pix
+}
+
+func TestReadAsepriteIndexed(t *testing.T) {
+	ase, err := ReadAseprite(bytes.NewReader(testIndexed()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ase.Width != 4 || ase.Height != 4 || len(ase.Frames) != 2 || len(ase.Layers) != 4 {
+		t.Fatalf("aseprite: %+v", ase)
+	}
+	if ase.Frames[0].Duration != 100 || ase.Frames[1].Duration != 50 {
+		t.Errorf("durations: %d %d", ase.Frames[0].Duration, ase.Frames[1].Duration)
+	}
+	if len(ase.Palette) != 256 || ase.Palette[1] != (color.RGBA{R: 255, A: 255}) || ase.Palette[0] != (color.RGBA{}) {
+		t.Errorf("palette: %v", ase.Palette[:4])
+	}
+
+	first := ase.Frame(0).(*image.Paletted)
+	want := []uint8{
+		1, 1, 1, 1,
+		1, 2, 2, 1,
+		1, 2, 1, 1,
+		1, 1, 1, 1,
+	}
+	if got := indexes(first); !slices.Equal(got, want) {
+		t.Errorf("frame 0: %v", got)
+	}
+	second := ase.Frame(1).(*image.Paletted)
+	if got := indexes(second); got[15] != 3 || got[5] != 1 {
+		t.Errorf("frame 1: %v", got)
+	}
+
+	sheet := ase.Sheet().(*image.Paletted)
+	if sheet.Bounds() != image.Rect(0, 0, 8, 4) {
+		t.Fatalf("sheet: %v", sheet.Bounds())
+	}
+	if sheet.ColorIndexAt(1, 1) != 2 || sheet.ColorIndexAt(5, 1) != 1 || sheet.ColorIndexAt(7, 3) != 3 {
+		t.Errorf("sheet: %v", indexes(sheet))
+	}
+}
+
+func TestAsepriteAnimations(t *testing.T) {
+	ase, err := ReadAseprite(bytes.NewReader(testIndexed()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := ase.Animations()
+	walk := a.Find("walk", xdat.East)
+	if walk == nil || walk.Mode != xdat.ModePingPong || len(walk.Frames) != 2 {
+		t.Fatalf("walk: %+v", walk)
+	}
+	want := []xdat.Frame{
+		{X: 0, Y: 0, W: 4, H: 4, Ticks: 6, HX: 2, HY: 3},
+		{X: 4, Y: 0, W: 4, H: 4, Ticks: 3, HX: 2, HY: 3, BX: 1, BY: 1, BW: 2, BH: 2},
+	}
+	if !slices.Equal(walk.Frames, want) {
+		t.Errorf("walk frames: %+v", walk.Frames)
+	}
+	hit := a.Find("hit", xdat.AnyDirection)
+	if hit == nil || hit.Mode != xdat.ModeOnce || len(hit.Frames) != 1 {
+		t.Errorf("hit: %+v", hit)
+	}
+}
+
+func TestReadAsepriteRGBA(t *testing.T) {
+	pix := []byte{255, 0, 0, 255, 0, 0, 0, 0}
+	file := aseFile(2, 1, AseRGBA, 0, aseTestFrame{100, []aseChunk{
+		aseLayer("ink", AseVisible, AseImageLayer, 0, 255),
+		aseLayer("glass", AseVisible, AseImageLayer, 0, 128),
+		aseRaw(0, 0, 0, 2, 1, pix),
+		aseRaw(1, 1, 0, 1, 1, []byte{0, 0, 255, 255}),
+	}})
+	ase, err := ReadAseprite(bytes.NewReader(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	img := ase.Frame(0).(*image.RGBA)
+	if got := img.RGBAAt(0, 0); got != (color.RGBA{R: 255, A: 255}) {
+		t.Errorf("ink: %v", got)
+	}
+	if got := img.RGBAAt(1, 0); got.B < 127 || got.B > 128 || got.A < 127 || got.A > 128 || got.R != 0 {
+		t.Errorf("glass: %v", got)
+	}
+}
+
+// asePaletteFile is a sprite with a palette chunk of entries first to last
+// that only has room for one entry.
+func asePaletteFile(depth int, first, last uint32) []byte {
+	palette := aseChunk{asePalette, le(last+1, first, last, make([]byte, 8), uint16(0), []byte{0, 0, 0, 255})}
+	return aseFile(4, 4, depth, 0, aseTestFrame{100, []aseChunk{palette}})
+}
+
+func TestReadAsepriteBad(t *testing.T) {
+	good := testIndexed()
+	for _, bad := range [][]byte{
+		nil,
+		good[:20],
+		good[:200],
+		append(append([]byte{}, good[:4]...), 0, 0),
+		asePaletteFile(AseIndexed, 0, 1<<30),
+		asePaletteFile(AseIndexed, 300, 300),
+		asePaletteFile(AseRGBA, 1<<30, 1<<30),
+	} {
+		if _, err := ReadAseprite(bytes.NewReader(bad)); err == nil {
+			t.Errorf("no error for %d bytes", len(bad))
+		}
+	}
+}
+
+func TestAsepriteFormat(t *testing.T) {
+	fsys := fstest.MapFS{"pack/sprite/hero.aseprite": {Data: testIndexed()}}
+	fin, _ := fsys.Open("pack/sprite/hero.aseprite")
+	img, format, err := image.Decode(fin)
+	fin.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := img.(*image.Paletted); format != "aseprite" || !ok || img.Bounds().Dx() != 8 {
+		t.Errorf("decoded %s %T %v", format, img, img.Bounds())
+	}
+	a, err := xdat.LoadAnimations(fsys, "pack/sprite/hero.aseprite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Source != "pack/sprite/hero.aseprite" || len(a.Clips) != 2 {
+		t.Errorf("animations: %+v", a)
+	}
+}
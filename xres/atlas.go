@@ -0,0 +1,361 @@
+package xres
+
+import (
+	"cmp"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"io"
+	"io/fs"
+	"math"
+	"os"
+	"path"
+	"slices"
+)
+
+import (
+	"github.com/xmasengine/xmas/xgal"
+)
+
+// AtlasDir is the directory with the atlases of the game.
+const AtlasDir = "pack/atlas"
+
+// Packer packs rectangles in a bin with the MaxRects algorithm: it keeps
+// the maximal free rectangles of the bin, and puts every rectangle in the
+// free rectangle it fits best along its short side.
+type Packer struct {
+	Size image.Point
+	free []image.Rectangle
+}
+
+// NewPacker returns a packer for a bin of w by h.
+func NewPacker(w, h int) *Packer {
+	return &Packer{Size: image.Pt(w, h), free: []image.Rectangle{image.Rect(0, 0, w, h)}}
+}
+
+// Insert places a rectangle of w by h, and returns where, or false if it
+// does not fit.
+func (p *Packer) Insert(w, h int) (image.Rectangle, bool) {
+	best := -1
+	bestShort, bestLong := math.MaxInt, math.MaxInt
+	for i, f := range p.free {
+		if f.Dx() < w || f.Dy() < h {
+			continue
+		}
+		short := min(f.Dx()-w, f.Dy()-h)
+		long := max(f.Dx()-w, f.Dy()-h)
+		if short < bestShort || (short == bestShort && long < bestLong) {
+			best, bestShort, bestLong = i, short, long
+		}
+	}
+	if best < 0 {
+		return image.Rectangle{}, false
+	}
+	at := p.free[best].Min
+	placed := image.Rect(at.X, at.Y, at.X+w, at.Y+h)
+	p.split(placed)
+	p.prune()
+	return placed, true
+}
+
+// split splits the free rectangles that overlap used into the maximal
+// rectangles around it.
+func (p *Packer) split(used image.Rectangle) {
+	var free []image.Rectangle
+	for _, f := range p.free {
+		if !f.Overlaps(used) {
+			free = append(free, f)
+			continue
+		}
+		if used.Min.X > f.Min.X {
+			free = append(free, image.Rect(f.Min.X, f.Min.Y, used.Min.X, f.Max.Y))
+		}
+		if used.Max.X < f.Max.X {
+			free = append(free, image.Rect(used.Max.X, f.Min.Y, f.Max.X, f.Max.Y))
+		}
+		if used.Min.Y > f.Min.Y {
+			free = append(free, image.Rect(f.Min.X, f.Min.Y, f.Max.X, used.Min.Y))
+		}
+		if used.Max.Y < f.Max.Y {
+			free = append(free, image.Rect(f.Min.X, used.Max.Y, f.Max.X, f.Max.Y))
+		}
+	}
+	p.free = free
+}
+
+// prune removes the free rectangles that are inside another one.
+func (p *Packer) prune() {
+	for i := 0; i < len(p.free); i++ {
+		for j := i + 1; j < len(p.free); j++ {
+			if p.free[i].In(p.free[j]) {
+				p.free = slices.Delete(p.free, i, i+1)
+				i--
+				break
+			}
+			if p.free[j].In(p.free[i]) {
+				p.free = slices.Delete(p.free, j, j+1)
+				j--
+			}
+		}
+	}
+}
+
+// Pack places rectangles of the sizes in the smallest bin it finds, with
+// sides that are powers of two up to limit. It returns where they are, in
+// the order of the sizes, and the size of the bin.
+func Pack(sizes []image.Point, limit int) ([]image.Rectangle, image.Point, error) {
+	area := 0
+	for _, s := range sizes {
+		if s.X <= 0 || s.Y <= 0 {
+			return nil, image.Point{}, fmt.Errorf("cannot pack an empty rectangle of %v", s)
+		}
+		if s.X > limit || s.Y > limit {
+			return nil, image.Point{}, fmt.Errorf("%v does not fit in %d", s, limit)
+		}
+		area += s.X * s.Y
+	}
+	// Big rectangles first, so the small ones fill the gaps.
+	order := make([]int, len(sizes))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		sa, sb := sizes[a], sizes[b]
+		if c := cmp.Compare(max(sb.X, sb.Y), max(sa.X, sa.Y)); c != 0 {
+			return c
+		}
+		return cmp.Compare(sb.X*sb.Y, sa.X*sa.Y)
+	})
+
+	bin := image.Pt(1, 1)
+	for bin.X*bin.Y < area {
+		if bin.X <= bin.Y {
+			bin.X *= 2
+		} else {
+			bin.Y *= 2
+		}
+	}
+	for bin.X <= limit && bin.Y <= limit {
+		placed := make([]image.Rectangle, len(sizes))
+		packer := NewPacker(bin.X, bin.Y)
+		fits := true
+		for _, i := range order {
+			r, ok := packer.Insert(sizes[i].X, sizes[i].Y)
+			if !ok {
+				fits = false
+				break
+			}
+			placed[i] = r
+		}
+		if fits {
+			return placed, bin, nil
+		}
+		if bin.X <= bin.Y {
+			bin.X *= 2
+		} else {
+			bin.Y *= 2
+		}
+	}
+	return nil, image.Point{}, fmt.Errorf("images do not fit in %dx%d", limit, limit)
+}
+
+// Opaque returns the bounds of the pixels of img that are not fully
+// transparent, or an empty rectangle if there are none.
+func Opaque(img image.Image) image.Rectangle {
+	b := img.Bounds()
+	r := image.Rectangle{}
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if _, _, _, a := img.At(x, y).RGBA(); a != 0 {
+				r = r.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+	return r
+}
+
+// AtlasImage is an image to pack in an atlas.
+type AtlasImage struct {
+	Name  string
+	Image image.Image
+}
+
+// AtlasOptions are the options of BuildAtlas.
+type AtlasOptions struct {
+	Padding int  // Padding is the space between images.
+	Extrude int  // Extrude repeats the edges of the images this many pixels.
+	Trim    bool // Trim removes the transparent borders of the images.
+	Limit   int  // Limit is the largest size of the atlas.
+}
+
+// AtlasRegion is a packed image in the index of an atlas.
+type AtlasRegion struct {
+	Name string `xml:"name,attr"`         // Name of the image.
+	X    int    `xml:"x,attr"`            // X of the image in the atlas.
+	Y    int    `xml:"y,attr"`            // Y of the image in the atlas.
+	W    int    `xml:"w,attr"`            // W is the width in the atlas.
+	H    int    `xml:"h,attr"`            // H is the height in the atlas.
+	OX   int    `xml:"ox,attr,omitempty"` // OX is the x of a trimmed image in the original.
+	OY   int    `xml:"oy,attr,omitempty"` // OY is the y of a trimmed image in the original.
+	SW   int    `xml:"sw,attr,omitempty"` // SW is the width of the original if trimmed.
+	SH   int    `xml:"sh,attr,omitempty"` // SH is the height of the original if trimmed.
+}
+
+// AtlasIndex is the index of the images in an atlas.
+type AtlasIndex struct {
+	XMLName xml.Name      `xml:"atlas"`
+	Source  string        `xml:"src,attr"`            // Source is the atlas image, relative to the index.
+	Root    string        `xml:"root,attr,omitempty"` // Root is the directory the names are relative to.
+	Regions []AtlasRegion `xml:"region"`
+}
+
+func (a AtlasIndex) SaveTo(wr io.Writer) error {
+	enc := xml.NewEncoder(wr)
+	enc.Indent("", " ")
+	return enc.Encode(a)
+}
+
+func (a AtlasIndex) SaveFile(name string) error {
+	out, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	return a.SaveTo(out)
+}
+
+// Atlas returns the runtime atlas of the index with the surface.
+func (a *AtlasIndex) Atlas(surface *xgal.Surface) *xgal.Atlas {
+	atlas := &xgal.Atlas{Root: a.Root, Surface: surface, Regions: map[string]xgal.Region{}}
+	for _, r := range a.Regions {
+		region := xgal.Region{Area: image.Rect(r.X, r.Y, r.X+r.W, r.Y+r.H), Size: image.Pt(r.W, r.H)}
+		if r.SW > 0 && r.SH > 0 {
+			region.Offset = image.Pt(r.OX, r.OY)
+			region.Size = image.Pt(r.SW, r.SH)
+		}
+		atlas.Regions[r.Name] = region
+	}
+	return atlas
+}
+
+// extrude repeats the edge pixels of the area of dst outward by n pixels.
+func extrude(dst draw.Image, area image.Rectangle, n int) {
+	outer := area.Inset(-n).Intersect(dst.Bounds())
+	for y := outer.Min.Y; y < outer.Max.Y; y++ {
+		for x := outer.Min.X; x < outer.Max.X; x++ {
+			if (image.Point{X: x, Y: y}).In(area) {
+				continue
+			}
+			sx := min(max(x, area.Min.X), area.Max.X-1)
+			sy := min(max(y, area.Min.Y), area.Max.Y-1)
+			dst.Set(x, y, dst.At(sx, sy))
+		}
+	}
+}
+
+// BuildAtlas packs the images in one atlas image, and returns it and its
+// index. Images with the same name are packed once.
+func BuildAtlas(images []AtlasImage, opts AtlasOptions) (*image.NRGBA, *AtlasIndex, error) {
+	if opts.Limit <= 0 {
+		opts.Limit = 4096
+	}
+	if opts.Padding < 0 || opts.Extrude < 0 {
+		return nil, nil, errors.New("padding and extrusion cannot be negative")
+	}
+	index := &AtlasIndex{}
+	index.XMLName.Local = "atlas"
+	areas := make([]image.Rectangle, 0, len(images))
+	sizes := make([]image.Point, 0, len(images))
+	seen := map[string]bool{}
+	var packed []AtlasImage
+	for _, img := range images {
+		if seen[img.Name] {
+			continue
+		}
+		seen[img.Name] = true
+		area := img.Image.Bounds()
+		if opts.Trim {
+			area = Opaque(img.Image)
+			if area.Empty() {
+				// Keep a pixel of images that are fully transparent.
+				area = image.Rectangle{Min: img.Image.Bounds().Min, Max: img.Image.Bounds().Min.Add(image.Pt(1, 1))}
+			}
+		}
+		if area.Empty() {
+			return nil, nil, fmt.Errorf("%s: empty image", img.Name)
+		}
+		packed = append(packed, img)
+		areas = append(areas, area)
+		margin := 2*opts.Extrude + opts.Padding
+		sizes = append(sizes, area.Size().Add(image.Pt(margin, margin)))
+	}
+
+	cells, bin, err := Pack(sizes, opts.Limit)
+	if err != nil {
+		return nil, nil, err
+	}
+	atlas := image.NewNRGBA(image.Rect(0, 0, bin.X, bin.Y))
+	for i, img := range packed {
+		at := cells[i].Min.Add(image.Pt(opts.Extrude, opts.Extrude))
+		area := areas[i]
+		dst := image.Rectangle{Min: at, Max: at.Add(area.Size())}
+		draw.Draw(atlas, dst, img.Image, area.Min, draw.Src)
+		extrude(atlas, dst, opts.Extrude)
+
+		region := AtlasRegion{Name: img.Name, X: dst.Min.X, Y: dst.Min.Y, W: dst.Dx(), H: dst.Dy()}
+		if full := img.Image.Bounds(); area != full {
+			region.OX, region.OY = area.Min.X-full.Min.X, area.Min.Y-full.Min.Y
+			region.SW, region.SH = full.Dx(), full.Dy()
+		}
+		index.Regions = append(index.Regions, region)
+	}
+	return atlas, index, nil
+}
+
+// ReadAtlasIndex reads the index of an atlas.
+func ReadAtlasIndex(rd io.Reader) (*AtlasIndex, error) {
+	index := &AtlasIndex{}
+	if err := xml.NewDecoder(rd).Decode(index); err != nil {
+		return nil, err
+	}
+	return index, nil
+}
+
+// LoadAtlas loads the index of an atlas and its image from fsys.
+func LoadAtlas(fsys fs.FS, name string) (*xgal.Atlas, error) {
+	fin, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer fin.Close()
+	index, err := ReadAtlasIndex(fin)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	surface, err := xgal.Texture(fsys, path.Join(path.Dir(name), index.Source))
+	if err != nil {
+		return nil, err
+	}
+	return index.Atlas(surface), nil
+}
+
+// UseAtlases loads all atlases in dir of fsys, and adds them so that
+// xgal.Texture finds their images. A missing dir has no atlases.
+func UseAtlases(fsys fs.FS, dir string) error {
+	names, err := fs.Glob(fsys, path.Join(dir, "*.xml"))
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, name := range names {
+		atlas, err := LoadAtlas(fsys, name)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		xgal.AddAtlas(atlas)
+	}
+	return errors.Join(errs...)
+}
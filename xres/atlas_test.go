@@ -0,0 +1,169 @@
+package xres
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"math/rand/v2"
+	"testing"
+)
+
+// checkPlacement checks that the rectangles have the sizes, are inside the
+// bin and do not overlap.
+func checkPlacement(t *testing.T, sizes []image.Point, placed []image.Rectangle, bin image.Point) {
+	t.Helper()
+	bounds := image.Rectangle{Max: bin}
+	for i, r := range placed {
+		if r.Size() != sizes[i] {
+			t.Errorf("%d: %v is not %v", i, r, sizes[i])
+		}
+		if !r.In(bounds) {
+			t.Errorf("%d: %v is outside %v", i, r, bounds)
+		}
+		for j := i + 1; j < len(placed); j++ {
+			if r.Overlaps(placed[j]) {
+				t.Errorf("%d: %v overlaps %d: %v", i, r, j, placed[j])
+			}
+		}
+	}
+}
+
+func TestPackNoOverlap(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(1, 2))
+	for round := 0; round < 20; round++ {
+		sizes := make([]image.Point, 10+rnd.IntN(90))
+		for i := range sizes {
+			sizes[i] = image.Pt(1+rnd.IntN(40), 1+rnd.IntN(40))
+		}
+		placed, bin, err := Pack(sizes, 1024)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkPlacement(t, sizes, placed, bin)
+		if bin.X&(bin.X-1) != 0 || bin.Y&(bin.Y-1) != 0 {
+			t.Errorf("bin %v is not a power of two", bin)
+		}
+	}
+}
+
+func TestPackTight(t *testing.T) {
+	// Sixteen squares of 8 fill a bin of 32 by 32 exactly.
+	sizes := make([]image.Point, 16)
+	for i := range sizes {
+		sizes[i] = image.Pt(8, 8)
+	}
+	placed, bin, err := Pack(sizes, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bin != image.Pt(32, 32) {
+		t.Errorf("bin: %v", bin)
+	}
+	checkPlacement(t, sizes, placed, bin)
+
+	if _, _, err := Pack([]image.Point{{100, 1}}, 64); err == nil {
+		t.Errorf("no error for a rectangle that is too big")
+	}
+	if _, _, err := Pack(append(sizes, image.Pt(64, 64)), 64); err == nil {
+		t.Errorf("no error when the rectangles do not fit")
+	}
+}
+
+func TestPacker(t *testing.T) {
+	p := NewPacker(10, 10)
+	var placed []image.Rectangle
+	var sizes []image.Point
+	for _, s := range []image.Point{{6, 4}, {4, 4}, {10, 6}} {
+		r, ok := p.Insert(s.X, s.Y)
+		if !ok {
+			t.Fatalf("%v does not fit", s)
+		}
+		placed = append(placed, r)
+		sizes = append(sizes, s)
+	}
+	checkPlacement(t, sizes, placed, p.Size)
+	if _, ok := p.Insert(1, 1); ok {
+		t.Errorf("a full bin takes more")
+	}
+}
+
+// dot returns an image of w by h with an opaque pixel of col at x, y.
+func dot(w, h, x, y int, col color.NRGBA) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	img.SetNRGBA(x, y, col)
+	return img
+}
+
+func TestBuildAtlas(t *testing.T) {
+	red := color.NRGBA{R: 255, A: 255}
+	blue := color.NRGBA{B: 255, A: 255}
+	images := []AtlasImage{
+		{Name: "gfx/red.png", Image: dot(8, 8, 2, 3, red)},
+		{Name: "gfx/hero.png#walk_0", Image: dot(4, 4, 0, 0, blue)},
+		{Name: "gfx/red.png", Image: dot(8, 8, 0, 0, blue)},
+	}
+	atlas, index, err := BuildAtlas(images, AtlasOptions{Padding: 1, Extrude: 1, Trim: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(index.Regions) != 2 {
+		t.Fatalf("regions: %+v", index.Regions)
+	}
+	r := index.Regions[0]
+	if r.Name != "gfx/red.png" || r.W != 1 || r.H != 1 || r.OX != 2 || r.OY != 3 || r.SW != 8 || r.SH != 8 {
+		t.Errorf("red: %+v", r)
+	}
+	if got := atlas.NRGBAAt(r.X, r.Y); got != red {
+		t.Errorf("red pixel: %v", got)
+	}
+	// The edges are extruded.
+	for _, p := range []image.Point{{-1, 0}, {1, 0}, {0, -1}, {0, 1}, {-1, -1}, {1, 1}} {
+		if got := atlas.NRGBAAt(r.X+p.X, r.Y+p.Y); got != red {
+			t.Errorf("extruded %v: %v", p, got)
+		}
+	}
+	b := index.Regions[1]
+	if b.W != 1 || b.H != 1 || b.OX != 0 || b.SW != 4 {
+		t.Errorf("blue: %+v", b)
+	}
+	areas := []image.Rectangle{}
+	for _, r := range index.Regions {
+		areas = append(areas, image.Rect(r.X, r.Y, r.X+r.W, r.Y+r.H).Inset(-1))
+	}
+	if areas[0].Overlaps(areas[1]) {
+		t.Errorf("extruded regions overlap: %v", areas)
+	}
+
+	runtime := index.Atlas(nil)
+	if region, ok := runtime.Find("gfx/red.png"); !ok || !region.Trimmed() || region.Size != image.Pt(8, 8) {
+		t.Errorf("runtime red: %+v %v", region, ok)
+	}
+}
+
+func TestAtlasIndexSave(t *testing.T) {
+	images := []AtlasImage{{Name: "a.png", Image: dot(2, 2, 1, 1, color.NRGBA{G: 255, A: 255})}}
+	_, index, err := BuildAtlas(images, AtlasOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	index.Source = "atlas.png"
+	index.Root = "pack"
+	buf := &bytes.Buffer{}
+	if err := index.SaveTo(buf); err != nil {
+		t.Fatal(err)
+	}
+	back, err := ReadAtlasIndex(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if back.Source != "atlas.png" || len(back.Regions) != 1 || back.Regions[0] != index.Regions[0] {
+		t.Errorf("round trip: %+v", back)
+	}
+	atlas := back.Atlas(nil)
+	if _, ok := atlas.Find("pack/a.png"); !ok {
+		t.Errorf("no region by the name with the root")
+	}
+	if region, _ := atlas.Find("a.png"); region.Trimmed() {
+		t.Errorf("untrimmed region is trimmed: %+v", region)
+	}
+}
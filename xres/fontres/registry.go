@@ -0,0 +1,48 @@
+package fontres
+
+import (
+	"io/fs"
+	"slices"
+
+	"github.com/xmasengine/xmas/xgal"
+)
+
+// registry holds the faces by name.
+var registry = map[string]xgal.Face{}
+
+// Register makes a face available under a name, so styles and data files
+// can refer to fonts by name.
+func Register(name string, face xgal.Face) {
+	registry[name] = face
+}
+
+// Lookup returns the face registered under the name.
+func Lookup(name string) (xgal.Face, bool) {
+	face, ok := registry[name]
+	return face, ok
+}
+
+// Names returns the names of the registered faces, sorted.
+func Names() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Open returns the face registered under the name. If there is none, it
+// loads the font file with that name from fsys with [xgal.Font], and
+// registers it under the name.
+func Open(fsys fs.FS, name string, size ...float64) (xgal.Face, error) {
+	if face, ok := registry[name]; ok {
+		return face, nil
+	}
+	face, err := xgal.Font(fsys, name, size...)
+	if err != nil {
+		return nil, err
+	}
+	Register(name, face)
+	return face, nil
+}
@@ -40,4 +40,11 @@ func init() {
 	if err != nil {
 		panic(err)
 	}
+	Register("tiny", TinyFace)
+	Register("small", SmallFace)
+	Register("medium", MediumFace)
+	Register("builtin", xgal.BuiltinFace)
+	Register("spleen8", TinyFace)
+	Register("f6x10", SmallFace)
+	Register("f8x13", MediumFace)
 }
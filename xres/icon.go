@@ -100,3 +100,13 @@ func (a IconAtlas) GetIcon(name string) *Icon {
 	}
 	return a.Icons[idx]
 }
+
+// IconSize returns the size of the named icon, and false if there is no
+// such icon.
+func (a IconAtlas) IconSize(name string) (image.Point, bool) {
+	icon := a.GetIcon(name)
+	if icon == nil {
+		return image.Point{}, false
+	}
+	return image.Pt(icon.Width, icon.Height), true
+}
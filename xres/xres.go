@@ -10,6 +10,7 @@ import _ "image/gif"
 import "image"
 import "os"
 import "log/slog"
+import "github.com/xmasengine/xmas/xgal"
 
 func FromName(name string) func() (io.ReadCloser, error) {
 	return func() (io.ReadCloser, error) {
@@ -104,7 +105,12 @@ func LoadImageFromFile(name string) (*ebiten.Image, error) {
 	return DecodeImage(rd)
 }
 
+// LoadImageFromFS loads the named image from the FS, or from the atlases
+// added to xgal if it is in one of them.
 func LoadImageFromFS(from fs.FS, name string) (*ebiten.Image, error) {
+	if sub, ok := xgal.Sprite(name); ok {
+		return sub, nil
+	}
 	rd, err := from.Open(name)
 	if err != nil {
 		slog.Error("LoadImageFromFS open failed", "err", err)
@@ -0,0 +1,84 @@
+package xui
+
+import "github.com/xmasengine/xmas/xgal"
+
+// Still turns off the animations of the widgets, so they snap in and out.
+var Still bool
+
+// MenuSlide is how far menus slide in from above.
+const MenuSlide = 8
+
+// MotionLayer animates the widget it wraps in when it is made, and out
+// again when it replies Finish. The animations are stepped by Poll.
+type MotionLayer struct {
+	Widget
+	Bounds xgal.Rectangle
+	Motion *xgal.Motion
+	// Leave returns the animation to play before the widget finishes, if
+	// any.
+	Leave   func(m *xgal.Motion) xgal.Animation
+	anim    xgal.Timeline
+	leaving bool
+	done    bool
+}
+
+// Animate wraps the widget with the bounds in a [MotionLayer] that plays
+// the animations made by enter and leave, which may be nil.
+func Animate(w Widget, bounds xgal.Rectangle, enter, leave func(m *xgal.Motion) xgal.Animation) *MotionLayer {
+	a := &MotionLayer{Widget: w, Bounds: bounds, Motion: xgal.NewMotion(), Leave: leave}
+	if enter != nil && !Still {
+		a.anim.Play(enter(a.Motion))
+	}
+	return a
+}
+
+// FadeIn wraps the widget, such as a dialog, so it fades in and out.
+func FadeIn(w Widget, bounds xgal.Rectangle) *MotionLayer {
+	return Animate(w, bounds,
+		func(m *xgal.Motion) xgal.Animation { return m.FadeIn(xgal.MotionTicks) },
+		func(m *xgal.Motion) xgal.Animation { return m.FadeOut(xgal.MotionTicks) })
+}
+
+// SlideIn wraps the widget, such as a menu, so it slides in from the
+// offset and out to it again.
+func SlideIn(w Widget, bounds xgal.Rectangle, from xgal.Point) *MotionLayer {
+	return Animate(w, bounds,
+		func(m *xgal.Motion) xgal.Animation { return m.SlideIn(from, xgal.MotionTicks) },
+		func(m *xgal.Motion) xgal.Animation { return m.SlideOut(from, xgal.MotionTicks) })
+}
+
+var _ Mover = &MotionLayer{}
+
+func (a *MotionLayer) Poll() Reply {
+	a.anim.Tick()
+	if a.done {
+		return Finish
+	}
+	if a.leaving {
+		return Accept
+	}
+	res := a.Widget.Poll()
+	if res != Finish || a.Leave == nil || Still {
+		return res
+	}
+	a.leaving = true
+	a.anim = xgal.Timeline{}
+	a.anim.Play(xgal.NewSequence(a.Leave(a.Motion), xgal.Call(func() { a.done = true })))
+	return Accept
+}
+
+func (a *MotionLayer) Render(s *xgal.Surface) {
+	a.Motion.Draw(s, a.Bounds, a.Widget.Render)
+}
+
+func (a *MotionLayer) Place(bounds xgal.Rectangle) xgal.Rectangle {
+	a.Bounds = a.Widget.Place(bounds)
+	return a.Bounds
+}
+
+func (a *MotionLayer) MoveBy(delta xgal.Point) {
+	a.Bounds = a.Bounds.Add(delta)
+	if mv, ok := a.Widget.(Mover); ok {
+		mv.MoveBy(delta)
+	}
+}
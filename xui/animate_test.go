@@ -0,0 +1,99 @@
+package xui
+
+import (
+	"testing"
+
+	"github.com/xmasengine/xmas/xgal"
+)
+
+// finisher is a widget that finishes when told to.
+type finisher struct {
+	Layer
+	finish bool
+}
+
+func (f *finisher) Poll() Reply {
+	if f.finish {
+		return Finish
+	}
+	return Accept
+}
+
+func TestFadeIn(t *testing.T) {
+	w := &finisher{}
+	a := FadeIn(w, xgal.Rect(0, 0, 50, 50))
+	if a.Motion.Alpha != 0 {
+		t.Fatalf("dialog starts at alpha %v", a.Motion.Alpha)
+	}
+	for range xgal.MotionTicks {
+		a.Poll()
+	}
+	if !a.Motion.Still() {
+		t.Fatalf("dialog not in after %d ticks", xgal.MotionTicks)
+	}
+
+	w.finish = true
+	ticks := 1
+	for a.Poll() != Finish {
+		ticks++
+	}
+	if ticks != xgal.MotionTicks+1 || a.Motion.Alpha != 0 {
+		t.Errorf("dialog finished after %d ticks at alpha %v", ticks, a.Motion.Alpha)
+	}
+}
+
+func TestStill(t *testing.T) {
+	Still = true
+	defer func() { Still = false }()
+
+	a := FadeIn(&finisher{finish: true}, xgal.Rect(0, 0, 50, 50))
+	if !a.Motion.Still() || a.Poll() != Finish {
+		t.Errorf("still dialog animated")
+	}
+	m := Menu(xgal.Rect(0, 0, 50, 50))
+	m.Show(true)
+	if !m.Shown() || m.anim.Busy() {
+		t.Errorf("still menu animated")
+	}
+}
+
+func TestMenuSlides(t *testing.T) {
+	m := Menu(xgal.Rect(0, 0, 50, 50))
+	m.Show(true)
+	if !m.Shown() || m.Motion.Offset != xgal.Pt(0, -MenuSlide) {
+		t.Fatalf("menu not sliding in from %v", m.Motion.Offset)
+	}
+	for range xgal.MotionTicks {
+		m.Poll()
+	}
+	if !m.Motion.Still() {
+		t.Errorf("menu not in at %v", m.Motion.Offset)
+	}
+	m.Show(false)
+	if m.Shown() || m.Poll() != Ignore {
+		t.Errorf("hidden menu shown")
+	}
+}
+
+func TestRingRotates(t *testing.T) {
+	r := Ring(100, 100, 40, []RingItem{{Label: "a"}, {Label: "b"}})
+	r.rotate(1)
+	if r.spinOff != 1 {
+		t.Fatalf("spin starts at %v", r.spinOff)
+	}
+	r.rotate(-1)
+	if len(r.anim.Playing) != 1 {
+		t.Fatalf("%d spins playing", len(r.anim.Playing))
+	}
+	last := r.spinOff
+	for range xgal.MotionTicks {
+		r.anim.Tick()
+		if r.spinOff < last {
+			t.Fatalf("spin goes back from %v to %v", last, r.spinOff)
+		}
+		last = r.spinOff
+	}
+	if r.spinOff != 0 || r.anim.Busy() {
+		t.Errorf("spin ends at %v", r.spinOff)
+	}
+}
@@ -18,8 +18,9 @@ type AskLayer struct {
 
 // Ask returns a new [AskLayer]. The caller must add it to a container
 // (e.g. via [Layer.Add]). After Poll returns Finish, read Result to see which
-// button was pressed.
+// button was pressed. The prompt is announced to the speaker.
 func Ask(bounds xgal.Rectangle, prompt string, buttons ...string) *AskLayer {
+	announce(xgal.CueFocus, prompt, "")
 	return &AskLayer{
 		Bounds:  bounds,
 		Style:   DefaultStyle(),
@@ -47,6 +48,7 @@ func AskEntry(bounds xgal.Rectangle, prompt string, entry string, enter func(str
 	entryWidget := Entry(entryBounds, entry, wrap)
 	// Focus entry by default
 	entryWidget.focus = true
+	announce(xgal.CueFocus, prompt, entry)
 	res = &AskLayer{
 		Bounds:  bounds,
 		Style:   DefaultStyle(),
@@ -92,7 +94,7 @@ func (a *AskLayer) Poll() Reply {
 			if xgal.Click(xgal.MouseButtonLeft) {
 				a.Result = i
 				if a.Entry != nil && i == 0 && a.Entry.Change != nil {
-					a.Entry.Change(string(a.Entry.Input))
+					a.Entry.Change(a.Entry.String())
 				}
 				return Finish
 			}
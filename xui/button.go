@@ -7,26 +7,35 @@ type ButtonLayer struct {
 	Bounds  xgal.Rectangle
 	Style   Style
 	Text    string
-	Icon    Icon // optional icon, drawn left of text
+	Key     string // Key is the string table key of the text, if any.
+	Icon    Icon   // optional icon, drawn left of text
 	Clicked func()
+	Motion  *xgal.Motion // Motion bounces the button when it is clicked.
 	pressed bool
 	hover   bool
+	anim    xgal.Timeline // plays the bounce
 }
 
 // Button returns a new [ButtonLayer] with the given bounds, text, and click handler.
 func Button(bounds xgal.Rectangle, text string, clicked func()) *ButtonLayer {
 	return &ButtonLayer{
 		Bounds:  bounds,
-		Style:   DefaultStyle(),
+		Style:   NamedStyle("button"),
 		Text:    text,
 		Clicked: clicked,
+		Motion:  xgal.NewMotion(),
 	}
 }
 
 var _ Widget = &ButtonLayer{}
 
 func (b *ButtonLayer) Poll() Reply {
-	b.hover = xgal.Cursor().In(b.Bounds)
+	b.anim.Tick()
+	hover := xgal.Cursor().In(b.Bounds)
+	if hover && !b.hover {
+		announce(xgal.CueFocus, b.Text, "")
+	}
+	b.hover = hover
 	if !b.hover {
 		if xgal.Loose(xgal.MouseButtonLeft) {
 			b.pressed = false
@@ -41,6 +50,7 @@ func (b *ButtonLayer) Poll() Reply {
 
 	if xgal.Loose(xgal.MouseButtonLeft) {
 		b.pressed = false
+		b.bounce()
 		if b.Clicked != nil {
 			b.Clicked()
 		}
@@ -50,7 +60,21 @@ func (b *ButtonLayer) Poll() Reply {
 	return Ignore
 }
 
+// bounce lets the button bounce, unless the widgets are [Still].
+func (b *ButtonLayer) bounce() {
+	if Still || b.Motion == nil {
+		return
+	}
+	b.anim = xgal.Timeline{}
+	b.Motion.Scale = 1
+	b.anim.Play(b.Motion.Bounce(xgal.MotionTicks))
+}
+
 func (b *ButtonLayer) Render(s *xgal.Surface) {
+	b.Motion.Draw(s, b.Bounds, b.render)
+}
+
+func (b *ButtonLayer) render(s *xgal.Surface) {
 	box := b.Bounds
 	style := b.Style
 
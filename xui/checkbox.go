@@ -9,6 +9,7 @@ type CheckboxLayer struct {
 	Bounds  xgal.Rectangle
 	Style   Style
 	Text    string
+	Key     string // Key is the string table key of the text, if any.
 	Checked bool
 	OnCheck func(bool)
 	hover   bool
@@ -29,7 +30,11 @@ func Checkbox(bounds xgal.Rectangle, text string, onCheck func(bool)) *CheckboxL
 var _ Widget = &CheckboxLayer{}
 
 func (c *CheckboxLayer) Poll() Reply {
-	c.hover = xgal.Cursor().In(c.Bounds)
+	hover := xgal.Cursor().In(c.Bounds)
+	if hover && !c.hover {
+		announce(xgal.CueFocus, c.Text, c.said())
+	}
+	c.hover = hover
 
 	if c.hover && xgal.Click(xgal.MouseButtonLeft) {
 		c.pressed = true
@@ -41,6 +46,7 @@ func (c *CheckboxLayer) Poll() Reply {
 			c.pressed = false
 			if c.hover {
 				c.Checked = !c.Checked
+				announce(xgal.CueValue, c.Text, c.said())
 				if c.OnCheck != nil {
 					c.OnCheck(c.Checked)
 				}
@@ -56,6 +62,11 @@ func (c *CheckboxLayer) Poll() Reply {
 	return Ignore
 }
 
+// said returns what is said of the state of the checkbox.
+func (c *CheckboxLayer) said() string {
+	return sayChecked(c.Checked, "checked", "unchecked")
+}
+
 func (c *CheckboxLayer) Render(s *xgal.Surface) {
 	box := c.Bounds
 	style := c.Style
@@ -66,8 +77,9 @@ func (c *CheckboxLayer) Render(s *xgal.Surface) {
 
 	style.DrawBox(s, box)
 
-	cy := box.Min.Y + (box.Dy()-CheckboxSize)/2
-	ibox := xgal.Rect(box.Min.X+style.Margin.X, cy, box.Min.X+style.Margin.X+CheckboxSize, cy+CheckboxSize)
+	size := xgal.UILength(CheckboxSize)
+	cy := box.Min.Y + (box.Dy()-size)/2
+	ibox := xgal.Rect(box.Min.X+style.Margin.X, cy, box.Min.X+style.Margin.X+size, cy+size)
 
 	cstyle := style.CheckStyle()
 	if c.Checked {
@@ -82,10 +94,11 @@ func (c *CheckboxLayer) Render(s *xgal.Surface) {
 
 func (c *CheckboxLayer) Place(bounds xgal.Rectangle) xgal.Rectangle {
 	tsz := c.Style.MeasureText(c.Text)
-	nw := c.Style.Margin.X + CheckboxSize + c.Style.Margin.X + tsz.X + c.Style.Margin.X
+	size := xgal.UILength(CheckboxSize)
+	nw := c.Style.Margin.X + size + c.Style.Margin.X + tsz.X + c.Style.Margin.X
 	nh := tsz.Y + c.Style.Margin.Y*2
-	if nh < CheckboxSize+c.Style.Margin.Y*2 {
-		nh = CheckboxSize + c.Style.Margin.Y*2
+	if nh < size+c.Style.Margin.Y*2 {
+		nh = size + c.Style.Margin.Y*2
 	}
 	c.Bounds = xgal.Rect(bounds.Min.X, bounds.Min.Y, bounds.Min.X+nw, bounds.Min.Y+nh)
 	return c.Bounds
@@ -0,0 +1,655 @@
+package xui
+
+import (
+	"slices"
+
+	"github.com/xmasengine/xmas/xgal"
+)
+
+const (
+	DockTabHeight = 16 // DockTabHeight is the unscaled height of the tab bars of docked panes.
+	DockSplitter  = 4  // DockSplitter is the width of the splitters between docked panes.
+	DockEdge      = 24 // DockEdge is how near the edge of the dock a dropped pane docks there.
+	DockMinSize   = 24 // DockMinSize is the smallest size that a splitter leaves a pane.
+	DockFloatW    = 160
+	DockFloatH    = 120
+	dockDragMin   = 4 // dockDragMin is how far a tab is dragged before its pane floats.
+)
+
+// dockTabHeight returns the height of the tab bars, scaled by [xgal.UIScale].
+func dockTabHeight() int {
+	return xgal.UILength(DockTabHeight)
+}
+
+// Side is where a pane is docked, relative to a node of a [DockLayer].
+type Side int
+
+const (
+	SideCenter Side = iota // SideCenter tabs the pane together with the panes of the node.
+	SideLeft
+	SideRight
+	SideTop
+	SideBottom
+)
+
+// DockPane is a widget with a title that can be docked in a [DockLayer].
+type DockPane struct {
+	Name    string // Name identifies the pane in a [DockLayout].
+	Title   string
+	Content Widget
+	// Frame is the bounds of the pane with its tab while it floats.
+	Frame xgal.Rectangle
+}
+
+// DockNode is a node of the docked panes of a [DockLayer]. It is either a
+// split of two kids with a splitter between them, or a leaf with tabs of
+// panes, of which the active one is shown.
+type DockNode struct {
+	Kids      []*DockNode // Kids are the two kids of a split, nil for a leaf.
+	Axis      Axis        // Axis of a split, Horizontal has the kids side by side.
+	Ratio     float64     // Ratio is the share of the first kid of a split.
+	Panes     []*DockPane // Panes are the tabbed panes of a leaf.
+	Active    int         // Active is the index of the shown pane of a leaf.
+	Collapsed bool        // Collapsed leaves only show their tabs.
+	Bounds    xgal.Rectangle
+	parent    *DockNode
+}
+
+// Leaf reports whether the node is a leaf.
+func (n *DockNode) Leaf() bool {
+	return len(n.Kids) == 0
+}
+
+// Pane returns the active pane of a leaf, or nil.
+func (n *DockNode) Pane() *DockPane {
+	if n.Active < 0 || n.Active >= len(n.Panes) {
+		return nil
+	}
+	return n.Panes[n.Active]
+}
+
+// tabs returns the bounds of the tab bar of a leaf.
+func (n *DockNode) tabs() xgal.Rectangle {
+	return xgal.Rect(n.Bounds.Min.X, n.Bounds.Min.Y, n.Bounds.Max.X, min(n.Bounds.Min.Y+dockTabHeight(), n.Bounds.Max.Y))
+}
+
+// collapser returns the bounds of the button that collapses a leaf.
+func (n *DockNode) collapser() xgal.Rectangle {
+	tabs := n.tabs()
+	return xgal.Rect(tabs.Max.X-dockTabHeight(), tabs.Min.Y, tabs.Max.X, tabs.Max.Y).Inset(2)
+}
+
+// content returns the bounds of the content of a leaf.
+func (n *DockNode) content() xgal.Rectangle {
+	return xgal.Rect(n.Bounds.Min.X, n.tabs().Max.Y, n.Bounds.Max.X, n.Bounds.Max.Y)
+}
+
+// splitter returns the bounds of the splitter of a split.
+func (n *DockNode) splitter() xgal.Rectangle {
+	first := n.Kids[0].Bounds
+	at := n.Axis.main(first.Max)
+	if n.Axis == Horizontal {
+		return xgal.Rect(at, n.Bounds.Min.Y, at+DockSplitter, n.Bounds.Max.Y)
+	}
+	return xgal.Rect(n.Bounds.Min.X, at, n.Bounds.Max.X, at+DockSplitter)
+}
+
+// leaves calls each for the leaves of the node, depth first.
+func (n *DockNode) leaves(each func(leaf *DockNode)) {
+	if n == nil {
+		return
+	}
+	if n.Leaf() {
+		each(n)
+		return
+	}
+	n.Kids[0].leaves(each)
+	n.Kids[1].leaves(each)
+}
+
+// DockLayer arranges panes in a tree of splits and tabs that fills its
+// bounds, with panes that float on top. Dragging a splitter resizes the
+// panes beside it. Clicking a tab shows its pane, and the button at the
+// end of the tabs collapses them. Dragging a tab floats its pane, and
+// dropping a floating pane on the tabs of a leaf tabs it together with
+// those, or on the edge of the dock docks it to that edge.
+type DockLayer struct {
+	Bounds xgal.Rectangle
+	Style  Style
+	Root   *DockNode   // Root of the docked panes, nil if none are docked.
+	Floats []*DockPane // Floats are the floating panes, the topmost last.
+	Panes  []*DockPane // Panes are all panes, docked or not.
+
+	split   *DockNode  // split is the split whose splitter is dragged.
+	press   *DockPane  // press is the pane whose tab was pressed.
+	pressAt xgal.Point // pressAt is where it was pressed.
+	moving  *DockPane  // moving is the floating pane that is dragged.
+	grab    xgal.Point // grab is where the moving pane was grabbed.
+}
+
+// Dock returns a new empty [DockLayer].
+func Dock(bounds xgal.Rectangle) *DockLayer {
+	return &DockLayer{Bounds: bounds, Style: NamedStyle("dock")}
+}
+
+var _ Arranger = &DockLayer{}
+
+// AddDock adds a [DockLayer] to this layer.
+func (m *Layer) AddDock(bounds xgal.Rectangle) *DockLayer {
+	d := Dock(bounds)
+	m.Add(d)
+	return d
+}
+
+// Add adds a pane with the content to the dock, docked to the side of all
+// docked panes.
+func (d *DockLayer) Add(name, title string, content Widget, side Side) *DockPane {
+	pane := &DockPane{Name: name, Title: title, Content: content}
+	d.Panes = append(d.Panes, pane)
+	d.DockTo(pane, d.Root, side)
+	return pane
+}
+
+// Pane returns the pane with the name, or nil.
+func (d *DockLayer) Pane(name string) *DockPane {
+	for _, pane := range d.Panes {
+		if pane.Name == name {
+			return pane
+		}
+	}
+	return nil
+}
+
+// Node returns the leaf that the pane is docked in, or nil if it floats.
+func (d *DockLayer) Node(pane *DockPane) *DockNode {
+	var found *DockNode
+	d.Root.leaves(func(leaf *DockNode) {
+		if slices.Contains(leaf.Panes, pane) {
+			found = leaf
+		}
+	})
+	return found
+}
+
+// remove takes the pane out of its leaf or the floats. Leaves that are
+// left empty are removed, and their sibling takes the place of their
+// parent.
+func (d *DockLayer) remove(pane *DockPane) {
+	if i := slices.Index(d.Floats, pane); i >= 0 {
+		d.Floats = slices.Delete(d.Floats, i, i+1)
+		return
+	}
+	leaf := d.Node(pane)
+	if leaf == nil {
+		return
+	}
+	i := slices.Index(leaf.Panes, pane)
+	leaf.Panes = slices.Delete(leaf.Panes, i, i+1)
+	leaf.Active = clamp(leaf.Active, 0, max(len(leaf.Panes)-1, 0))
+	if len(leaf.Panes) > 0 {
+		return
+	}
+	parent := leaf.parent
+	if parent == nil {
+		d.Root = nil
+		return
+	}
+	sibling := parent.Kids[0]
+	if sibling == leaf {
+		sibling = parent.Kids[1]
+	}
+	d.replace(parent, sibling)
+	leaf.parent, parent.parent = nil, nil
+}
+
+// replace puts node in the place of old in the tree.
+func (d *DockLayer) replace(old, node *DockNode) {
+	node.parent = old.parent
+	if old.parent == nil {
+		d.Root = node
+		return
+	}
+	kids := old.parent.Kids
+	kids[slices.Index(kids, old)] = node
+}
+
+// DockTo docks the pane to the side of the node, or of all docked panes if
+// node is nil. The pane takes a quarter of the node when docked to its
+// side, or is tabbed together with the panes of the node for SideCenter,
+// or of its first leaf if it is a split.
+func (d *DockLayer) DockTo(pane *DockPane, node *DockNode, side Side) {
+	if node == nil {
+		node = d.Root
+	}
+	if node != nil && node.Leaf() && len(node.Panes) == 1 && node.Panes[0] == pane {
+		return // Docking a pane to itself changes nothing.
+	}
+	d.remove(pane)
+	if node != nil && !d.inTree(node) {
+		node = d.Root // The node was removed with the pane.
+	}
+	leaf := &DockNode{Panes: []*DockPane{pane}}
+	if node == nil {
+		d.Root = leaf
+		return
+	}
+	if side == SideCenter {
+		for !node.Leaf() {
+			node = node.Kids[0]
+		}
+		node.Panes = append(node.Panes, pane)
+		node.Active = len(node.Panes) - 1
+		return
+	}
+	split := &DockNode{Axis: Horizontal, Ratio: 0.75}
+	if side == SideTop || side == SideBottom {
+		split.Axis = Vertical
+	}
+	d.replace(node, split)
+	split.Kids = []*DockNode{node, leaf}
+	if side == SideLeft || side == SideTop {
+		split.Kids = []*DockNode{leaf, node}
+		split.Ratio = 0.25
+	}
+	node.parent = split
+	leaf.parent = split
+}
+
+// inTree reports whether the node is in the tree of docked panes.
+func (d *DockLayer) inTree(node *DockNode) bool {
+	for n := node; n != nil; n = n.parent {
+		if n == d.Root {
+			return true
+		}
+	}
+	return false
+}
+
+// Float takes the pane out of the docked panes and floats it in frame on
+// top of the others.
+func (d *DockLayer) Float(pane *DockPane, frame xgal.Rectangle) {
+	d.remove(pane)
+	pane.Frame = frame
+	d.Floats = append(d.Floats, pane)
+	d.arrangeFloat(pane)
+}
+
+// Collapse collapses the leaf that the pane is docked in, so only its tabs
+// are shown, or expands it again.
+func (d *DockLayer) Collapse(pane *DockPane, collapsed bool) {
+	if leaf := d.Node(pane); leaf != nil {
+		leaf.Collapsed = collapsed
+		d.Arrange(d.Bounds)
+	}
+}
+
+// Show makes the pane the active one of its tabs and expands them.
+func (d *DockLayer) Show(pane *DockPane) {
+	if leaf := d.Node(pane); leaf != nil {
+		leaf.Active = slices.Index(leaf.Panes, pane)
+		leaf.Collapsed = false
+		d.Arrange(d.Bounds)
+	}
+}
+
+// collapsedSize returns the size of the node along the axis when it is
+// collapsed, or 0 if it is not.
+func (n *DockNode) collapsedSize() int {
+	if n.Leaf() && n.Collapsed {
+		return dockTabHeight()
+	}
+	if !n.Leaf() && n.Kids[0].collapsedSize() > 0 && n.Kids[1].collapsedSize() > 0 {
+		return dockTabHeight()*2 + DockSplitter
+	}
+	return 0
+}
+
+// Arrange lays the docked panes out in bounds, and the floating panes in
+// their frames. The panes that are not shown, because they are in other
+// tabs or collapsed, are arranged to an empty rectangle.
+func (d *DockLayer) Arrange(bounds xgal.Rectangle) {
+	d.Bounds = bounds
+	for _, pane := range d.Panes {
+		if pane.Content != nil {
+			Arrange(pane.Content, xgal.Rectangle{})
+		}
+	}
+	if d.Root != nil {
+		d.arrangeNode(d.Root, bounds)
+	}
+	for _, pane := range d.Floats {
+		d.arrangeFloat(pane)
+	}
+}
+
+func (d *DockLayer) arrangeNode(n *DockNode, r xgal.Rectangle) {
+	n.Bounds = r
+	if n.Leaf() {
+		if pane := n.Pane(); pane != nil && pane.Content != nil && !n.Collapsed {
+			Arrange(pane.Content, n.content())
+		}
+		return
+	}
+	space := n.Axis.main(r.Size()) - DockSplitter
+	first := int(float64(space)*n.Ratio + 0.5)
+	if size := n.Kids[0].collapsedSize(); size > 0 {
+		first = size
+	} else if size := n.Kids[1].collapsedSize(); size > 0 {
+		first = space - size
+	}
+	first = clamp(first, 0, max(space, 0))
+	cross := n.Axis.cross(r.Size())
+	one := xgal.Rectangle{Min: r.Min, Max: r.Min.Add(n.Axis.pt(first, cross))}
+	two := xgal.Rectangle{Min: r.Min.Add(n.Axis.pt(first+DockSplitter, 0)), Max: r.Max}
+	d.arrangeNode(n.Kids[0], one)
+	d.arrangeNode(n.Kids[1], two)
+}
+
+func (d *DockLayer) arrangeFloat(pane *DockPane) {
+	if pane.Content != nil {
+		content := pane.Frame
+		content.Min.Y += dockTabHeight()
+		Arrange(pane.Content, content)
+	}
+}
+
+// Place arranges the dock to fill bounds.
+func (d *DockLayer) Place(bounds xgal.Rectangle) xgal.Rectangle {
+	d.Arrange(bounds)
+	return d.Bounds
+}
+
+func (d *DockLayer) MoveBy(delta xgal.Point) {
+	for _, pane := range d.Floats {
+		pane.Frame = pane.Frame.Add(delta)
+	}
+	d.Arrange(d.Bounds.Add(delta))
+}
+
+// drag moves the splitter that is dragged to at.
+func (d *DockLayer) drag(at xgal.Point) {
+	n := d.split
+	space := n.Axis.main(n.Bounds.Size()) - DockSplitter
+	if space <= 0 {
+		return
+	}
+	first := n.Axis.main(at.Sub(n.Bounds.Min)) - DockSplitter/2
+	first = clamp(first, min(DockMinSize, space/2), max(space-DockMinSize, space/2))
+	n.Ratio = float64(first) / float64(space)
+	d.arrangeNode(n, n.Bounds)
+}
+
+// Target returns the node and side that a pane dropped at would dock to,
+// and false if it would keep floating. Near the edges of the dock it docks
+// to all panes, on the tabs of a leaf it is tabbed together with them.
+func (d *DockLayer) Target(at xgal.Point) (*DockNode, Side, bool) {
+	if !at.In(d.Bounds) {
+		return nil, SideCenter, false
+	}
+	if d.Root == nil {
+		return nil, SideCenter, true
+	}
+	switch {
+	case at.X < d.Bounds.Min.X+DockEdge:
+		return nil, SideLeft, true
+	case at.X >= d.Bounds.Max.X-DockEdge:
+		return nil, SideRight, true
+	case at.Y < d.Bounds.Min.Y+DockEdge:
+		return nil, SideTop, true
+	case at.Y >= d.Bounds.Max.Y-DockEdge:
+		return nil, SideBottom, true
+	}
+	var found *DockNode
+	d.Root.leaves(func(leaf *DockNode) {
+		if at.In(leaf.tabs()) {
+			found = leaf
+		}
+	})
+	return found, SideCenter, found != nil
+}
+
+// targetBounds returns the bounds that a pane dropped at would take.
+func (d *DockLayer) targetBounds(at xgal.Point) (xgal.Rectangle, bool) {
+	node, side, ok := d.Target(at)
+	if !ok {
+		return xgal.Rectangle{}, false
+	}
+	b := d.Bounds
+	if node != nil {
+		b = node.Bounds
+	}
+	w, h := b.Dx()/4, b.Dy()/4
+	switch side {
+	case SideLeft:
+		b.Max.X = b.Min.X + w
+	case SideRight:
+		b.Min.X = b.Max.X - w
+	case SideTop:
+		b.Max.Y = b.Min.Y + h
+	case SideBottom:
+		b.Min.Y = b.Max.Y - h
+	}
+	return b, true
+}
+
+// tabAt returns the index of the tab of the leaf at x, or -1.
+func (d *DockLayer) tabAt(leaf *DockNode, x int) int {
+	left := leaf.Bounds.Min.X
+	for i, pane := range leaf.Panes {
+		right := left + d.tabWidth(pane)
+		if x >= left && x < right {
+			return i
+		}
+		left = right
+	}
+	return -1
+}
+
+func (d *DockLayer) tabWidth(pane *DockPane) int {
+	return d.Style.MeasureText(pane.Title).X + d.Style.Margin.X*2 + 4
+}
+
+// pollLeaf polls the tabs and the shown pane of a leaf.
+func (d *DockLayer) pollLeaf(leaf *DockNode, pos xgal.Point) Reply {
+	if pos.In(leaf.tabs()) {
+		if !xgal.Click(xgal.MouseButtonLeft) {
+			return Accept
+		}
+		if pos.In(leaf.collapser()) {
+			leaf.Collapsed = !leaf.Collapsed
+			d.Arrange(d.Bounds)
+		} else if i := d.tabAt(leaf, pos.X); i >= 0 {
+			leaf.Active = i
+			d.press, d.pressAt = leaf.Panes[i], pos
+			d.Arrange(d.Bounds)
+		}
+		return Accept
+	}
+	pane := leaf.Pane()
+	if pane == nil || pane.Content == nil || leaf.Collapsed || !pos.In(leaf.content()) {
+		return Ignore
+	}
+	return pane.Content.Poll()
+}
+
+func (d *DockLayer) Poll() Reply {
+	pos := xgal.Cursor()
+
+	if d.split != nil {
+		if xgal.Loose(xgal.MouseButtonLeft) {
+			d.split = nil
+		} else {
+			d.drag(pos)
+		}
+		return Accept
+	}
+
+	if d.moving != nil {
+		pane := d.moving
+		if xgal.Loose(xgal.MouseButtonLeft) {
+			d.moving = nil
+			if node, side, ok := d.Target(pos); ok {
+				d.DockTo(pane, node, side)
+				d.Arrange(d.Bounds)
+			}
+		} else {
+			pane.Frame = pane.Frame.Add(pos.Sub(d.grab).Sub(pane.Frame.Min))
+			d.arrangeFloat(pane)
+		}
+		return Accept
+	}
+
+	if d.press != nil {
+		if !xgal.Grip(xgal.MouseButtonLeft) {
+			d.press = nil
+		} else if delta := pos.Sub(d.pressAt); max(delta.X, -delta.X, delta.Y, -delta.Y) >= dockDragMin {
+			// Dragging a tab floats its pane under the cursor.
+			pane := d.press
+			d.press = nil
+			size := xgal.Pt(DockFloatW, DockFloatH)
+			if leaf := d.Node(pane); leaf != nil {
+				size = leaf.Bounds.Size().Div(2)
+			}
+			d.grab = xgal.Pt(d.tabWidth(pane)/2, dockTabHeight()/2)
+			d.Float(pane, xgal.Rectangle{Min: pos.Sub(d.grab), Max: pos.Sub(d.grab).Add(size)})
+			d.moving = pane
+			d.Arrange(d.Bounds)
+			return Accept
+		}
+	}
+
+	for i := len(d.Floats) - 1; i >= 0; i-- {
+		pane := d.Floats[i]
+		if !pos.In(pane.Frame) {
+			continue
+		}
+		tab := pane.Frame
+		tab.Max.Y = tab.Min.Y + dockTabHeight()
+		if pos.In(tab) {
+			if xgal.Click(xgal.MouseButtonLeft) {
+				d.Floats = append(slices.Delete(d.Floats, i, i+1), pane)
+				d.moving = pane
+				d.grab = pos.Sub(pane.Frame.Min)
+			}
+			return Accept
+		}
+		if pane.Content != nil {
+			pane.Content.Poll()
+		}
+		return Accept
+	}
+
+	if d.Root == nil || !pos.In(d.Bounds) {
+		return Ignore
+	}
+
+	res := Ignore
+	var walk func(n *DockNode) bool
+	walk = func(n *DockNode) bool {
+		if !pos.In(n.Bounds) {
+			return false
+		}
+		if n.Leaf() {
+			res = d.pollLeaf(n, pos)
+			return true
+		}
+		if pos.In(n.splitter()) {
+			if xgal.Click(xgal.MouseButtonLeft) {
+				d.split = n
+			}
+			res = Accept
+			return true
+		}
+		return walk(n.Kids[0]) || walk(n.Kids[1])
+	}
+	walk(d.Root)
+	return res
+}
+
+// renderTab renders a tab with the title.
+func (d *DockLayer) renderTab(s *xgal.Surface, style Style, bounds xgal.Rectangle, title string) {
+	style.DrawBox(s, bounds)
+	clip := s.SubImage(bounds).(*xgal.Surface)
+	style.Ink(clip, bounds, title)
+}
+
+func (d *DockLayer) renderLeaf(s *xgal.Surface, leaf *DockNode) {
+	tabs := leaf.tabs()
+	d.Style.BarStyle().DrawBox(s, tabs)
+	x := tabs.Min.X
+	for i, pane := range leaf.Panes {
+		style := d.Style
+		if i == leaf.Active {
+			style = style.ActiveStyle()
+		}
+		w := d.tabWidth(pane)
+		d.renderTab(s, style, xgal.Rect(x, tabs.Min.Y, min(x+w, tabs.Max.X), tabs.Max.Y), pane.Title)
+		x += w
+	}
+	button := leaf.collapser()
+	d.Style.DrawBox(s, button)
+	mid := (button.Min.Y + button.Max.Y) / 2
+	xgal.Line(s, button.Min.X+2, mid, button.Max.X-2, mid, 1, d.Style.Fore)
+	if leaf.Collapsed {
+		mid := (button.Min.X + button.Max.X) / 2
+		xgal.Line(s, mid, button.Min.Y+2, mid, button.Max.Y-2, 1, d.Style.Fore)
+		return
+	}
+	if pane := leaf.Pane(); pane != nil && pane.Content != nil {
+		content := leaf.content()
+		d.Style.DrawBox(s, content)
+		if !content.Empty() {
+			pane.Content.Render(s.SubImage(content).(*xgal.Surface))
+		}
+	}
+}
+
+func (d *DockLayer) renderNode(s *xgal.Surface, n *DockNode) {
+	if n.Leaf() {
+		d.renderLeaf(s, n)
+		return
+	}
+	d.renderNode(s, n.Kids[0])
+	d.renderNode(s, n.Kids[1])
+	style := d.Style.BarStyle()
+	if n == d.split {
+		style = style.DragStyle()
+	} else if xgal.Cursor().In(n.splitter()) {
+		style = style.HoverStyle()
+	}
+	style.DrawBox(s, n.splitter())
+}
+
+func (d *DockLayer) Render(s *xgal.Surface) {
+	if d.Root == nil {
+		d.Style.DrawBox(s, d.Bounds)
+	} else {
+		d.renderNode(s, d.Root)
+	}
+	for _, pane := range d.Floats {
+		tab := pane.Frame
+		tab.Max.Y = tab.Min.Y + dockTabHeight()
+		content := pane.Frame
+		content.Min.Y = tab.Max.Y
+		d.Style.DrawBox(s, pane.Frame)
+		d.renderTab(s, d.Style.ActiveStyle(), tab, pane.Title)
+		if pane.Content != nil && !content.Empty() {
+			pane.Content.Render(s.SubImage(content).(*xgal.Surface))
+		}
+	}
+	if d.moving != nil {
+		if target, ok := d.targetBounds(xgal.Cursor()); ok {
+			d.Style.HoverStyle().DrawRect(s, target)
+		}
+	}
+}
+
+// Restyle restyles the dock and the contents of its panes.
+func (d *DockLayer) Restyle() {
+	d.Style = d.Style.Restyle()
+	for _, pane := range d.Panes {
+		if pane.Content != nil {
+			Restyle(pane.Content)
+		}
+	}
+}
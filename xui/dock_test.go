@@ -0,0 +1,153 @@
+package xui
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+
+	"github.com/xmasengine/xmas/xgal"
+)
+
+// dockNames returns the names of the panes of the leaves, depth first.
+func dockNames(d *DockLayer) []string {
+	var names []string
+	d.Root.leaves(func(leaf *DockNode) {
+		for _, pane := range leaf.Panes {
+			names = append(names, pane.Name)
+		}
+	})
+	return names
+}
+
+func TestDockSplit(t *testing.T) {
+	d := Dock(xgal.Rect(0, 0, 400, 300))
+	canvas := d.Add("canvas", "Canvas", nil, SideCenter)
+	list := d.Add("list", "List", nil, SideLeft)
+	d.Add("palette", "Palette", nil, SideBottom)
+	d.Arrange(d.Bounds)
+
+	if d.Root.Axis != Vertical || d.Root.Kids[1].Pane().Name != "palette" {
+		t.Fatalf("palette not docked at the bottom")
+	}
+	if got := d.Root.Bounds; got != d.Bounds {
+		t.Errorf("root bounds %v", got)
+	}
+	split := d.Root.Kids[0]
+	if split.Axis != Horizontal || split.Kids[0].Pane() != list || split.Kids[1].Pane() != canvas {
+		t.Fatalf("list not docked at the left")
+	}
+	if w := d.Node(list).Bounds.Dx(); w != 99 {
+		t.Errorf("list is %d wide", w)
+	}
+	if d.Node(canvas).Bounds.Min.X != d.Node(list).Bounds.Max.X+DockSplitter {
+		t.Errorf("no splitter between list and canvas")
+	}
+
+	d.split = split
+	d.drag(xgal.Pt(200+DockSplitter/2, 10))
+	if w := d.Node(list).Bounds.Dx(); w != 200 {
+		t.Errorf("dragged list is %d wide", w)
+	}
+	d.drag(xgal.Pt(0, 10))
+	if w := d.Node(list).Bounds.Dx(); w != DockMinSize {
+		t.Errorf("list dragged shut is %d wide", w)
+	}
+}
+
+func TestDockTabFloat(t *testing.T) {
+	d := Dock(xgal.Rect(0, 0, 400, 300))
+	canvas := d.Add("canvas", "Canvas", nil, SideCenter)
+	list := d.Add("list", "List", nil, SideRight)
+	help := d.Add("help", "Help", nil, SideCenter)
+
+	if d.Node(help) != d.Node(canvas) || d.Node(canvas).Pane() != help {
+		t.Fatalf("help not tabbed with the canvas")
+	}
+
+	d.Float(list, xgal.Rect(10, 10, 110, 90))
+	if !d.Root.Leaf() || len(d.Floats) != 1 || d.Node(list) != nil {
+		t.Fatalf("floating list left a split")
+	}
+
+	d.Arrange(d.Bounds)
+	node, side, ok := d.Target(xgal.Pt(5, 150))
+	if !ok || node != nil || side != SideLeft {
+		t.Errorf("left edge targets %v %v %v", node, side, ok)
+	}
+	node, side, ok = d.Target(xgal.Pt(200, 5))
+	if !ok || side != SideTop {
+		t.Errorf("top edge targets %v %v %v", node, side, ok)
+	}
+	if _, _, ok := d.Target(xgal.Pt(200, 150)); ok {
+		t.Errorf("content of a pane is a target")
+	}
+	if _, _, ok := d.Target(xgal.Pt(500, 150)); ok {
+		t.Errorf("outside of the dock is a target")
+	}
+
+	d.DockTo(list, d.Root, SideLeft)
+	if len(d.Floats) != 0 || d.Root.Kids[0].Pane() != list {
+		t.Errorf("list not docked again")
+	}
+
+	d.Collapse(list, true)
+	if w := d.Node(list).Bounds.Dx(); w != DockTabHeight {
+		t.Errorf("collapsed list is %d wide", w)
+	}
+	d.Show(list)
+	if d.Node(list).Collapsed {
+		t.Errorf("shown list is collapsed")
+	}
+}
+
+func TestDockLayout(t *testing.T) {
+	newDock := func() *DockLayer {
+		d := Dock(xgal.Rect(0, 0, 400, 300))
+		d.Add("canvas", "Canvas", nil, SideCenter)
+		d.Add("list", "List", nil, SideLeft)
+		d.Add("palette", "Palette", nil, SideBottom)
+		d.Add("help", "Help", nil, SideCenter)
+		return d
+	}
+	d := newDock()
+	d.Float(d.Pane("help"), xgal.Rect(20, 30, 120, 130))
+	d.Node(d.Pane("palette")).Collapsed = true
+	d.Root.Ratio = 0.5
+
+	var buf bytes.Buffer
+	if err := d.Layout().SaveTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	layout, err := ReadDockLayout(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := newDock()
+	if err := e.Apply(layout); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := dockNames(e), dockNames(d); !slices.Equal(got, want) {
+		t.Errorf("panes %v, want %v", got, want)
+	}
+	if e.Root.Ratio != 0.5 || !e.Node(e.Pane("palette")).Collapsed {
+		t.Errorf("ratio or collapse not restored")
+	}
+	if len(e.Floats) != 1 || e.Floats[0].Frame != xgal.Rect(20, 30, 120, 130) {
+		t.Errorf("float not restored: %v", e.Floats)
+	}
+
+	// Panes missing from the layout are tabbed in, unknown ones are left out.
+	layout = &DockLayout{Root: &DockLayoutNode{Panes: []string{"canvas", "gone"}}}
+	if err := e.Apply(layout); err != nil {
+		t.Fatal(err)
+	}
+	if names := dockNames(e); len(names) != 4 || names[0] != "canvas" || !e.Root.Leaf() {
+		t.Errorf("panes %v", names)
+	}
+
+	bad := &DockLayout{Root: &DockLayoutNode{Axis: "diagonal", Kids: []*DockLayoutNode{{}, {}}}}
+	if err := e.Apply(bad); err == nil {
+		t.Errorf("bad axis applied")
+	}
+}
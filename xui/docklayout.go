@@ -0,0 +1,192 @@
+package xui
+
+import (
+	"cmp"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+
+	"github.com/xmasengine/xmas/xgal"
+)
+
+// DockLayout is the arrangement of the panes of a [DockLayer], as it is
+// saved to and loaded from XML. Panes are referred to by their name.
+type DockLayout struct {
+	XMLName xml.Name          `xml:"dock"`
+	Root    *DockLayoutNode   `xml:"node,omitempty"`
+	Floats  []DockLayoutFloat `xml:"float"`
+}
+
+// DockLayoutNode is a [DockNode] in a [DockLayout]. A split has an axis
+// and two kids, a leaf has panes.
+type DockLayoutNode struct {
+	Axis      string            `xml:"axis,attr,omitempty"` // Axis is "horizontal" or "vertical" for a split.
+	Ratio     float64           `xml:"ratio,attr,omitempty"`
+	Active    int               `xml:"active,attr,omitempty"`
+	Collapsed bool              `xml:"collapsed,attr,omitempty"`
+	Panes     []string          `xml:"pane"`
+	Kids      []*DockLayoutNode `xml:"node"`
+}
+
+// DockLayoutFloat is a floating [DockPane] in a [DockLayout].
+type DockLayoutFloat struct {
+	Pane string `xml:"pane,attr"`
+	X    int    `xml:"x,attr"`
+	Y    int    `xml:"y,attr"`
+	W    int    `xml:"w,attr"`
+	H    int    `xml:"h,attr"`
+}
+
+// Layout returns the current arrangement of the panes of the dock.
+func (d *DockLayer) Layout() *DockLayout {
+	layout := &DockLayout{Root: layoutNode(d.Root)}
+	for _, pane := range d.Floats {
+		f := pane.Frame
+		layout.Floats = append(layout.Floats, DockLayoutFloat{
+			Pane: pane.Name, X: f.Min.X, Y: f.Min.Y, W: f.Dx(), H: f.Dy(),
+		})
+	}
+	return layout
+}
+
+func layoutNode(n *DockNode) *DockLayoutNode {
+	if n == nil {
+		return nil
+	}
+	if n.Leaf() {
+		node := &DockLayoutNode{Active: n.Active, Collapsed: n.Collapsed}
+		for _, pane := range n.Panes {
+			node.Panes = append(node.Panes, pane.Name)
+		}
+		return node
+	}
+	node := &DockLayoutNode{Axis: "vertical", Ratio: n.Ratio}
+	if n.Axis == Horizontal {
+		node.Axis = "horizontal"
+	}
+	node.Kids = []*DockLayoutNode{layoutNode(n.Kids[0]), layoutNode(n.Kids[1])}
+	return node
+}
+
+// Apply arranges the panes of the dock as in the layout. Panes in the
+// layout that the dock does not have are left out, and panes of the dock
+// that are not in the layout are tabbed together with the first leaf. It
+// returns an error and leaves the dock as it was if the layout is not
+// valid.
+func (d *DockLayer) Apply(layout *DockLayout) error {
+	used := map[*DockPane]bool{}
+	root, err := d.applyNode(layout.Root, used)
+	if err != nil {
+		return err
+	}
+	var floats []*DockPane
+	for _, float := range layout.Floats {
+		pane := d.Pane(float.Pane)
+		if pane == nil || used[pane] {
+			continue
+		}
+		used[pane] = true
+		pane.Frame = xgal.Rect(float.X, float.Y, float.X+float.W, float.Y+float.H)
+		floats = append(floats, pane)
+	}
+	d.Root, d.Floats = root, floats
+	d.split, d.press, d.moving = nil, nil, nil
+	for _, pane := range d.Panes {
+		if !used[pane] {
+			d.DockTo(pane, d.Root, SideCenter)
+		}
+	}
+	d.Arrange(d.Bounds)
+	return nil
+}
+
+// applyNode returns the node for the layout node, or nil if it has no
+// panes that the dock has.
+func (d *DockLayer) applyNode(node *DockLayoutNode, used map[*DockPane]bool) (*DockNode, error) {
+	if node == nil {
+		return nil, nil
+	}
+	if len(node.Kids) == 0 {
+		leaf := &DockNode{Collapsed: node.Collapsed}
+		for _, name := range node.Panes {
+			if pane := d.Pane(name); pane != nil && !used[pane] {
+				used[pane] = true
+				leaf.Panes = append(leaf.Panes, pane)
+			}
+		}
+		if len(leaf.Panes) == 0 {
+			return nil, nil
+		}
+		leaf.Active = clamp(node.Active, 0, len(leaf.Panes)-1)
+		return leaf, nil
+	}
+	if len(node.Kids) != 2 || len(node.Panes) > 0 {
+		return nil, fmt.Errorf("dock layout: a split needs two nodes and no panes")
+	}
+	split := &DockNode{Ratio: node.Ratio}
+	switch node.Axis {
+	case "horizontal":
+		split.Axis = Horizontal
+	case "vertical":
+		split.Axis = Vertical
+	default:
+		return nil, fmt.Errorf("dock layout: unknown axis %q", node.Axis)
+	}
+	if split.Ratio < 0 || split.Ratio > 1 {
+		return nil, fmt.Errorf("dock layout: ratio %g out of range 0 to 1", split.Ratio)
+	}
+	one, err := d.applyNode(node.Kids[0], used)
+	if err != nil {
+		return nil, err
+	}
+	two, err := d.applyNode(node.Kids[1], used)
+	if err != nil {
+		return nil, err
+	}
+	if one == nil || two == nil {
+		return cmp.Or(one, two), nil // Splits of a missing pane are left out.
+	}
+	split.Kids = []*DockNode{one, two}
+	one.parent, two.parent = split, split
+	return split, nil
+}
+
+func (l DockLayout) SaveTo(wr io.Writer) error {
+	enc := xml.NewEncoder(wr)
+	enc.Indent("", " ")
+	return enc.Encode(l)
+}
+
+func (l DockLayout) SaveFile(name string) error {
+	out, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	return l.SaveTo(out)
+}
+
+// ReadDockLayout reads a dock layout from XML.
+func ReadDockLayout(rd io.Reader) (*DockLayout, error) {
+	layout := &DockLayout{}
+	if err := xml.NewDecoder(rd).Decode(layout); err != nil {
+		return nil, err
+	}
+	return layout, nil
+}
+
+// LoadDockLayout loads a dock layout from fsys.
+func LoadDockLayout(fsys fs.FS, name string) (*DockLayout, error) {
+	fin, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer fin.Close()
+	layout, err := ReadDockLayout(fin)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return layout, nil
+}
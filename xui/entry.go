@@ -1,29 +1,32 @@
 package xui
 
 import (
-	"slices"
-
 	"github.com/xmasengine/xmas/xgal"
 )
 
-// EntryLayer is a text input field.
+// EntryLayer is a text input field. It edits its text with an
+// [xgal.Edit], which has the selection, clipboard, undo and mask.
+// Text from an input method arrives through [xgal.Chars] once it is
+// committed; the entry does not show the composition while it is typed.
 type EntryLayer struct {
 	Bounds xgal.Rectangle
 	Style  Style
-	Input  []rune
+	*xgal.Edit
 	Label  string
 	Change func(string)
-	cursor int
+	scroll int
+	drag   bool
 	hasFocus
 }
 
 // Entry returns a new [EntryLayer] with the given bounds, initial text, and
-// change callback. The callback is called when Enter is pressed.
+// change callback. The callback is called when Enter is pressed and the
+// text is valid for the mask of the edit.
 func Entry(bounds xgal.Rectangle, text string, change func(string)) *EntryLayer {
 	return &EntryLayer{
 		Bounds: bounds,
 		Style:  DefaultStyle(),
-		Input:  []rune(text),
+		Edit:   xgal.NewEdit(text),
 		Change: change,
 	}
 }
@@ -31,7 +34,18 @@ func Entry(bounds xgal.Rectangle, text string, change func(string)) *EntryLayer
 var _ Widget = &EntryLayer{}
 
 func (e *EntryLayer) Text() string {
-	return string(e.Input)
+	return e.String()
+}
+
+// Input returns the runes of the text. They are those of the edit, so
+// use SetInput rather than changing them.
+func (e *EntryLayer) Input() []rune {
+	return e.Edit.Text
+}
+
+// SetInput replaces the text with the runes and puts the cursor at the end.
+func (e *EntryLayer) SetInput(input []rune) {
+	e.SetText(string(input))
 }
 
 // hasFocus is a helper subwidget to help manage focus and hovering
@@ -79,45 +93,56 @@ func (h *hasFocus) pollFocus(bounds xgal.Rectangle) Reply {
 	return Proceed
 }
 
+// at returns the index of the rune nearest to the x coordinate.
+func (e *EntryLayer) at(x int) int {
+	measure := func(text string) int { return e.Style.MeasureText(text).X }
+	return xgal.Column(e.String(), x-e.Bounds.Min.X-e.Style.Margin.X+e.scroll, measure)
+}
+
 func (e *EntryLayer) Poll() Reply {
+	focus := e.focus
 	res := e.pollFocus(e.Bounds)
+	if e.focus && !focus {
+		announce(xgal.CueFocus, e.Label, e.String())
+	}
+	if e.drag {
+		if xgal.Grip(xgal.MouseButtonLeft) {
+			e.MoveTo(e.at(xgal.Cursor().X), true)
+		} else {
+			e.drag = false
+		}
+	}
+	if res == Accept || (res == Proceed && xgal.Click() && e.hover) {
+		e.MoveTo(e.at(xgal.Cursor().X), xgal.Key(xgal.KeyShift))
+		e.drag = true
+		return Accept
+	}
 	if res != Proceed {
 		return res
 	}
 
+	shift := xgal.Key(xgal.KeyShift)
+	control := xgal.Key(xgal.KeyControl) || xgal.Key(xgal.KeyMeta)
+	handled := false
 	taps := xgal.Taps(nil)
 	for _, k := range taps {
 		switch k {
-		case xgal.KeyArrowLeft:
-			e.cursor = max(0, e.cursor-1)
-		case xgal.KeyArrowRight:
-			e.cursor = min(e.cursor+1, len(e.Input))
-		case xgal.KeyHome:
-			e.cursor = 0
-		case xgal.KeyEnd:
-			e.cursor = len(e.Input)
-		case xgal.KeyEnter:
-			if e.Change != nil {
-				e.Change(string(e.Input))
-			}
-		case xgal.KeyBackspace:
-			if e.cursor > 0 {
-				e.Input = slices.Delete(e.Input, e.cursor-1, e.cursor)
-				e.cursor--
-			}
-		case xgal.KeyDelete:
-			if e.cursor < len(e.Input) {
-				e.Input = slices.Delete(e.Input, e.cursor, e.cursor+1)
+		case xgal.KeyEnter, xgal.KeyNumpadEnter:
+			if e.Change != nil && e.Valid() == nil {
+				e.Change(e.String())
 			}
+			handled = true
+		default:
+			done, _ := e.Key(k, shift, control)
+			handled = handled || done
 		}
 	}
 
 	chars := xgal.Chars(nil)
-	if len(chars) > 0 {
-		e.Input = slices.Insert(e.Input, e.cursor, chars...)
-		e.cursor += len(chars)
+	if len(chars) > 0 && !control {
+		handled = e.Insert(chars...) || handled
 	}
-	if len(taps) > 0 || len(chars) > 0 {
+	if handled {
 		return Accept
 	}
 	return Ignore
@@ -132,19 +157,35 @@ func (e *EntryLayer) Render(s *xgal.Surface) {
 	} else if e.hover {
 		style = style.HoverStyle()
 	}
+	if e.Valid() != nil {
+		style = style.ErrorStyle()
+	}
 
 	style.DrawBox(s, box)
 
-	txt := string(e.Input)
-	style.Ink(s, box, txt)
+	// Scroll the text so the cursor is in view.
+	inner := style.Inset(box)
+	runes := e.Edit.Text
+	cx := style.MeasureText(string(runes[:e.Cursor])).X
+	if cx < e.scroll {
+		e.scroll = cx
+	} else if cx > e.scroll+inner.Dx() {
+		e.scroll = cx - inner.Dx()
+	}
+	clip := s.SubImage(inner).(*xgal.Surface)
+	x := inner.Min.X - e.scroll
+
+	// Draw the selection and the text.
+	if lo, hi := e.Selection(); e.focus && lo < hi {
+		x0 := x + style.MeasureText(string(runes[:lo])).X
+		x1 := x + style.MeasureText(string(runes[:hi])).X
+		xgal.Box(clip, xgal.Rect(x0, inner.Min.Y, x1, inner.Max.Y), style.ActiveStyle().Fill)
+	}
+	xgal.Ink(clip, style.Face, style.Fore, x, inner.Min.Y, e.String())
 
 	// Draw the cursor if focused.
 	if e.focus {
-		sz := style.MeasureText(txt[:e.cursor])
-		cx := box.Min.X + style.Margin.X + sz.X
-		cy := box.Min.Y + style.Margin.Y
-		ch := box.Dy() - style.Margin.Y*2
-		xgal.Line(s, cx, cy, cx, cy+ch, style.Stroke, style.Fore)
+		xgal.Line(clip, x+cx, inner.Min.Y, x+cx, inner.Max.Y, style.Stroke, style.Fore)
 	}
 
 	if e.Label != "" {
@@ -157,7 +198,7 @@ const minEntryW = 80
 const minEntryH = 16
 
 func (e *EntryLayer) Place(bounds xgal.Rectangle) xgal.Rectangle {
-	sz := e.Style.MeasureText(string(e.Input) + "  ")
+	sz := e.Style.MeasureText(e.String() + "  ")
 	nw := sz.X + e.Style.Margin.X*2
 	if nw < minEntryW {
 		nw = minEntryW
@@ -0,0 +1,18 @@
+package xui
+
+import (
+	"testing"
+
+	"github.com/xmasengine/xmas/xgal"
+)
+
+func TestEntryInput(t *testing.T) {
+	e := Entry(xgal.Rect(0, 0, 100, 20), "Santa", nil)
+	if string(e.Input()) != "Santa" {
+		t.Errorf("input %q", string(e.Input()))
+	}
+	e.SetInput([]rune("Rudolph"))
+	if e.Text() != "Rudolph" || e.Cursor != len("Rudolph") {
+		t.Errorf("set input %q, cursor %d", e.Text(), e.Cursor)
+	}
+}
@@ -0,0 +1,402 @@
+package xui
+
+import (
+	"io/fs"
+
+	"github.com/xmasengine/xmas/xgal"
+	"github.com/xmasengine/xmas/xvec"
+)
+
+// The buttons of a [FileDialogLayer].
+const (
+	fileUp = iota
+	fileFolder
+	fileRecent
+	fileDone
+	fileCancel
+)
+
+// FileDialogLayer is a dialog that opens or saves a file in the
+// directories of an [fs.FS], with an [xgal.Browser]. It lists the
+// directories and the files with the extensions of the browser, with the
+// layer they are in for a [wfs.Overlay], and previews the selected file
+// if it is an image or an xvec file. Clicking a directory enters it,
+// clicking a file selects it and clicking it again chooses it. The
+// buttons go up a directory, make a new folder, show the recent files,
+// and choose the file or cancel. Saving over a file asks to confirm.
+// Once done, Poll returns Finish and Result is 0 if a file was chosen.
+type FileDialogLayer struct {
+	Bounds xgal.Rectangle
+	Style  Style
+	*xgal.Browser
+	Prompt string
+	Entry  *EntryLayer       // Entry is the name of the file.
+	Chosen func(name string) // Chosen is called with the path of the chosen file.
+	Result int               // -1 while open
+	Offset int               // first visible item index
+
+	recent  bool      // recent shows the recent files rather than Dir.
+	ask     *AskLayer // ask is the question asked on top, if any.
+	folder  bool      // folder is set if ask asks for a folder name.
+	pending string    // pending is the file that is saved if overwriting it is confirmed.
+	err     error     // err is the last error, shown instead of Dir.
+	hover   int       // hover is the hovered item, -1 for none.
+	button  int       // button is the hovered button, -1 for none.
+}
+
+// FileDialog returns a new [FileDialogLayer] that browses fsys, starting
+// at the named file if it is set. Only files with one of the extensions
+// are shown. If save is set, the file is saved rather than opened.
+func FileDialog(bounds xgal.Rectangle, prompt string, fsys fs.FS, name string, save bool, chosen func(name string), exts ...string) *FileDialogLayer {
+	f := &FileDialogLayer{
+		Bounds:  bounds,
+		Style:   DefaultStyle(),
+		Browser: xgal.NewBrowser(fsys, name, save, exts...),
+		Prompt:  prompt,
+		Chosen:  chosen,
+		Result:  -1,
+		hover:   -1,
+		button:  -1,
+	}
+	f.Previewers[".xvec"] = xvec.Preview
+	f.Entry = Entry(xgal.Rectangle{}, f.Name, func(string) { f.choose() })
+	f.Entry.focus = true
+	f.Place(bounds)
+	return f
+}
+
+var _ Widget = &FileDialogLayer{}
+
+// AddFileDialog is a helper to add a [FileDialogLayer] to a [Layer].
+func (m *Layer) AddFileDialog(bounds xgal.Rectangle, prompt string, fsys fs.FS, name string, save bool, chosen func(name string), exts ...string) *FileDialogLayer {
+	f := FileDialog(bounds, prompt, fsys, name, save, chosen, exts...)
+	m.Add(f)
+	return f
+}
+
+// buttons returns the texts of the buttons.
+func (f *FileDialogLayer) buttons() []string {
+	done := "Open"
+	if f.Save {
+		done = "Save"
+	}
+	recent := "Recent"
+	if f.recent {
+		recent = "Files"
+	}
+	return []string{"Up", "Folder", recent, done, "Cancel"}
+}
+
+// lineHeight returns the height of a line of text with its margins.
+func (f *FileDialogLayer) lineHeight() int {
+	return f.Style.MeasureText("X").Y + f.Style.Margin.Y*2
+}
+
+// buttonBounds returns the bounds of the i-th button.
+func (f *FileDialogLayer) buttonBounds(i int) xgal.Rectangle {
+	pad := f.Style.Margin
+	n := len(f.buttons())
+	bw := (f.Bounds.Dx() - pad.X*(n+1)) / n
+	bh := f.lineHeight()
+	bx := f.Bounds.Min.X + pad.X + i*(bw+pad.X)
+	by := f.Bounds.Max.Y - pad.Y - bh
+	return xgal.Rect(bx, by, bx+bw, by+bh)
+}
+
+// listBounds returns the bounds of the list of files.
+func (f *FileDialogLayer) listBounds() xgal.Rectangle {
+	pad := f.Style.Margin
+	top := f.Bounds.Min.Y + pad.Y + f.lineHeight()
+	bottom := f.Entry.Bounds.Min.Y - pad.Y
+	return xgal.Rect(f.Bounds.Min.X+pad.X, top, f.Bounds.Min.X+f.Bounds.Dx()*2/3, max(bottom, top))
+}
+
+// previewBounds returns the bounds of the preview of the selected file.
+func (f *FileDialogLayer) previewBounds() xgal.Rectangle {
+	list := f.listBounds()
+	return xgal.Rect(list.Max.X+f.Style.Margin.X, list.Min.Y, f.Bounds.Max.X-f.Style.Margin.X, list.Max.Y)
+}
+
+// items returns the texts of the items of the list.
+func (f *FileDialogLayer) items() []string {
+	if f.recent {
+		return f.Recents()
+	}
+	items := make([]string, len(f.Entries))
+	for i, entry := range f.Entries {
+		items[i] = f.Label(entry)
+	}
+	return items
+}
+
+// itemHeight returns the height of the items of the list.
+func (f *FileDialogLayer) itemHeight() int {
+	return xgal.UILength(ListItemHeight)
+}
+
+// limit returns how many items the list shows.
+func (f *FileDialogLayer) limit() int {
+	return max(f.listBounds().Dy()/f.itemHeight(), 1)
+}
+
+func (f *FileDialogLayer) clampOffset() {
+	f.Offset = clamp(f.Offset, 0, max(len(f.items())-f.limit(), 0))
+}
+
+// selected returns the index of the item of the selected file, or -1.
+func (f *FileDialogLayer) selected() int {
+	if f.recent {
+		return -1
+	}
+	for i, entry := range f.Entries {
+		if !entry.Dir && entry.Name == f.Name {
+			return i
+		}
+	}
+	return -1
+}
+
+// setName sets the name of the file and shows it in the entry.
+func (f *FileDialogLayer) setName(name string) {
+	f.Name = name
+	f.Entry.SetText(name)
+	f.Entry.SelectAll()
+}
+
+// pick handles a click on the i-th item.
+func (f *FileDialogLayer) pick(i int) {
+	f.err = nil
+	if f.recent {
+		f.err = f.Go(f.Recents()[i])
+		f.recent = false
+		f.Offset = 0
+		f.setName(f.Name)
+		return
+	}
+	entry := f.Entries[i]
+	if !entry.Dir && entry.Name != f.Name {
+		f.setName(entry.Name)
+		return
+	}
+	chosen, err := f.Open(entry)
+	f.err = err
+	if chosen {
+		f.choose()
+	} else if entry.Dir {
+		f.Offset = 0
+	}
+}
+
+// choose checks the name of the file, and finishes the dialog with it,
+// after asking to confirm overwriting it.
+func (f *FileDialogLayer) choose() {
+	f.Name = f.Entry.String()
+	name, err := f.Check()
+	f.err = err
+	if err != nil || name == "" {
+		f.setName(f.Name)
+		return
+	}
+	if f.Overwrites(name) {
+		f.pending = name
+		f.folder = false
+		f.ask = Ask(f.askBounds(), "Overwrite "+name+"?", "Yes", "No")
+		return
+	}
+	f.finish(name)
+}
+
+func (f *FileDialogLayer) finish(name string) {
+	f.Done(name)
+	f.Result = 0
+	if f.Chosen != nil {
+		f.Chosen(name)
+	}
+}
+
+// askBounds returns the bounds of a question asked on top.
+func (f *FileDialogLayer) askBounds() xgal.Rectangle {
+	lh := f.lineHeight()
+	c := f.Bounds.Min.Add(f.Bounds.Max).Div(2)
+	return xgal.Rect(f.Bounds.Min.X+lh, c.Y-lh*2, f.Bounds.Max.X-lh, c.Y+lh*2)
+}
+
+// press handles a click on the i-th button.
+func (f *FileDialogLayer) press(i int) Reply {
+	f.err = nil
+	switch i {
+	case fileUp:
+		f.recent = false
+		f.err = f.Enter("..")
+		f.Offset = 0
+	case fileFolder:
+		f.folder = true
+		f.ask = AskEntry(f.askBounds(), "Folder name?", "", func(name string) {
+			f.err = f.Mkdir(name)
+		}, "Create", "Cancel")
+	case fileRecent:
+		f.recent = !f.recent
+		f.Offset = 0
+	case fileDone:
+		f.choose()
+	case fileCancel:
+		f.Result = 1
+	}
+	if f.Result >= 0 {
+		return Finish
+	}
+	return Accept
+}
+
+// pollAsk polls the question that is asked, if any.
+func (f *FileDialogLayer) pollAsk() Reply {
+	if f.ask.Poll() != Finish && !xgal.Tap(xgal.KeyEscape) {
+		return Accept
+	}
+	if !f.folder && f.ask.Result == 0 {
+		f.finish(f.pending)
+	}
+	f.ask = nil
+	if f.Result >= 0 {
+		return Finish
+	}
+	return Accept
+}
+
+func (f *FileDialogLayer) Poll() Reply {
+	f.hover, f.button = -1, -1
+	if f.Result >= 0 {
+		return Finish
+	}
+	if f.ask != nil {
+		return f.pollAsk()
+	}
+	if xgal.Tap(xgal.KeyEscape) {
+		f.Result = 1
+		return Finish
+	}
+	if f.Entry.Poll() == Accept {
+		if f.Result >= 0 {
+			return Finish
+		}
+		return Accept
+	}
+
+	items := f.items()
+	f.clampOffset()
+	if sel := f.selected(); sel >= 0 {
+		if xgal.Tap(xgal.KeyArrowDown) && sel < len(items)-1 && !f.Entries[sel+1].Dir {
+			f.setName(f.Entries[sel+1].Name)
+		} else if xgal.Tap(xgal.KeyArrowUp) && sel > 0 && !f.Entries[sel-1].Dir {
+			f.setName(f.Entries[sel-1].Name)
+		}
+	}
+
+	pos := xgal.Cursor()
+	if !pos.In(f.Bounds) {
+		return Ignore
+	}
+	list := f.listBounds()
+	if pos.In(list) {
+		if _, wy := xgal.Wheel(); wy != 0 {
+			f.Offset -= int(wy)
+			f.clampOffset()
+		}
+		if i := f.Offset + (pos.Y-list.Min.Y)/f.itemHeight(); i < len(items) {
+			f.hover = i
+			if xgal.Click(xgal.MouseButtonLeft) {
+				f.pick(i)
+			}
+		}
+		return Accept
+	}
+	for i := range f.buttons() {
+		if pos.In(f.buttonBounds(i)) {
+			f.button = i
+			if xgal.Click(xgal.MouseButtonLeft) {
+				return f.press(i)
+			}
+		}
+	}
+	return Accept
+}
+
+func (f *FileDialogLayer) Render(s *xgal.Surface) {
+	f.Style.DrawBox(s, f.Bounds)
+	pad := f.Style.Margin
+	title := xgal.Rect(f.Bounds.Min.X+pad.X, f.Bounds.Min.Y+pad.Y, f.Bounds.Max.X-pad.X, f.Bounds.Min.Y+pad.Y+f.lineHeight())
+	if f.err != nil {
+		f.Style.ErrorStyle().Ink(s, title, f.err.Error())
+	} else if f.recent {
+		f.Style.Ink(s, title, f.Prompt+" recent files")
+	} else {
+		f.Style.Ink(s, title, f.Prompt+" "+f.Dir)
+	}
+
+	list := f.listBounds()
+	f.Style.DrawBox(s, list)
+	items := f.items()
+	sel := f.selected()
+	for i := f.Offset; i < min(len(items), f.Offset+f.limit()); i++ {
+		y := list.Min.Y + (i-f.Offset)*f.itemHeight()
+		item := xgal.Rect(list.Min.X, y, list.Max.X, y+f.itemHeight())
+		st := f.Style
+		if i == f.hover {
+			st = st.HoverStyle()
+		}
+		if i == sel {
+			st = st.ActiveStyle()
+		}
+		st.DrawBox(s, item)
+		st.Ink(s.SubImage(item).(*xgal.Surface), item, items[i])
+	}
+
+	box := f.previewBounds()
+	f.Style.DrawBox(s, box)
+	if preview := f.Preview(); preview != nil && !box.Empty() {
+		xgal.Blit(s, preview, fitBounds(preview.Bounds().Size(), box.Inset(2)), preview.Bounds())
+	}
+
+	f.Entry.Render(s)
+	for i, label := range f.buttons() {
+		st := f.Style
+		if i == f.button {
+			st = st.HoverStyle()
+		}
+		bb := f.buttonBounds(i)
+		st.DrawBox(s, bb)
+		st.Ink(s, bb, label)
+	}
+	if f.ask != nil {
+		f.ask.Render(s)
+	}
+}
+
+// fitBounds returns the largest bounds of the size's aspect centered in box.
+func fitBounds(size xgal.Point, box xgal.Rectangle) xgal.Rectangle {
+	if size.X <= 0 || size.Y <= 0 {
+		return xgal.Rectangle{}
+	}
+	w, h := box.Dx(), size.Y*box.Dx()/size.X
+	if h > box.Dy() {
+		w, h = size.X*box.Dy()/size.Y, box.Dy()
+	}
+	at := box.Min.Add(xgal.Pt(box.Dx()-w, box.Dy()-h).Div(2))
+	return xgal.Rectangle{Min: at, Max: at.Add(xgal.Pt(w, h))}
+}
+
+// Place takes the bounds and lays out the entry above the buttons.
+func (f *FileDialogLayer) Place(bounds xgal.Rectangle) xgal.Rectangle {
+	f.Bounds = bounds
+	pad := f.Style.Margin
+	by := f.buttonBounds(0).Min.Y
+	f.Entry.Bounds = xgal.Rect(bounds.Min.X+pad.X, by-pad.Y-f.lineHeight(), bounds.Max.X-pad.X, by-pad.Y)
+	return f.Bounds
+}
+
+func (f *FileDialogLayer) MoveBy(delta xgal.Point) {
+	f.Place(f.Bounds.Add(delta))
+	if f.ask != nil {
+		f.ask.MoveBy(delta)
+	}
+}
@@ -0,0 +1,56 @@
+package xui
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/xmasengine/xmas/xgal"
+)
+
+func TestFileDialog(t *testing.T) {
+	files := fstest.MapFS{
+		"a.txt":     {Data: []byte("a")},
+		"c.png":     {Data: []byte("c")},
+		"sub/d.txt": {Data: []byte("d")},
+	}
+	chosen := ""
+	f := FileDialog(xgal.Rect(0, 0, 300, 200), "Open", files, "", false, func(name string) { chosen = name }, ".txt")
+	f.Recent = &xgal.Recent{}
+
+	if items := f.items(); len(items) != 2 || items[0] != "sub/" || items[1] != "a.txt" {
+		t.Fatalf("items %v", items)
+	}
+	f.pick(0)
+	if f.Dir != "sub" || f.Offset != 0 {
+		t.Fatalf("sub not entered: %q", f.Dir)
+	}
+	f.pick(1)
+	if f.Entry.String() != "d.txt" || f.selected() != 1 || chosen != "" {
+		t.Fatalf("d.txt not selected: %q %d", f.Entry.String(), f.selected())
+	}
+	f.pick(1)
+	if chosen != "sub/d.txt" || f.Result != 0 {
+		t.Fatalf("d.txt not chosen: %q %d", chosen, f.Result)
+	}
+	if recent := f.Recents(); len(recent) != 1 || recent[0] != "sub/d.txt" {
+		t.Errorf("recent %v", recent)
+	}
+}
+
+func TestFileDialogOverwrite(t *testing.T) {
+	files := fstest.MapFS{"a.txt": {Data: []byte("a")}}
+	chosen := ""
+	f := FileDialog(xgal.Rect(0, 0, 300, 200), "Save", files, "a.txt", true, func(name string) { chosen = name }, ".txt")
+	f.Recent = &xgal.Recent{}
+
+	f.choose()
+	if f.ask == nil || f.pending != "a.txt" || chosen != "" {
+		t.Fatalf("overwrite not asked")
+	}
+	f.ask = nil
+	f.Entry.SetText("b.png")
+	f.choose()
+	if f.err == nil || f.ask != nil {
+		t.Errorf("saved with the wrong extension")
+	}
+}
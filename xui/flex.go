@@ -0,0 +1,740 @@
+package xui
+
+import "github.com/xmasengine/xmas/xgal"
+
+// Layout happens in two passes over the widget tree. Measure asks the
+// widgets how large they would like to be, and Arrange then gives them
+// their bounds. Containers measure and arrange their kids in turn. The
+// computations only involve sizes and rectangles, so they can be tested
+// without rendering anything.
+
+// Size are the sizes that a widget can be laid out at.
+type Size struct {
+	Min  xgal.Point // Min is the smallest size.
+	Pref xgal.Point // Pref is the preferred size.
+	Max  xgal.Point // Max is the largest size, 0 for no limit.
+}
+
+// Measurer is an optional interface for widgets that know their sizes.
+// avail is the size that is available, which widgets that wrap their
+// contents may use.
+type Measurer interface {
+	Widget
+	Measure(avail xgal.Point) Size
+}
+
+// Arranger is an optional interface for widgets that take the bounds a
+// layout gives them, rather than a size of their own.
+type Arranger interface {
+	Widget
+	Arrange(bounds xgal.Rectangle)
+}
+
+// Measure returns the sizes of the widget. Widgets that do not measure
+// themselves are placed, and the size they take is their minimum and
+// preferred size.
+func Measure(w Widget, avail xgal.Point) Size {
+	if m, ok := w.(Measurer); ok {
+		return m.Measure(avail)
+	}
+	size := w.Place(xgal.Rect(0, 0, avail.X, avail.Y)).Size()
+	return Size{Min: size, Pref: size}
+}
+
+// Arrange gives the widget its bounds. Widgets that do not arrange
+// themselves are placed at the start of the bounds, at their own size.
+func Arrange(w Widget, bounds xgal.Rectangle) {
+	if a, ok := w.(Arranger); ok {
+		a.Arrange(bounds)
+		return
+	}
+	w.Place(bounds)
+}
+
+// limit clamps v between low and high, where high 0 is no limit.
+func limit(v, low, high int) int {
+	if high > 0 && v > high {
+		v = high
+	}
+	return max(v, low)
+}
+
+// Align is how kids are aligned across the axis of a container.
+type Align int
+
+const (
+	AlignAuto    Align = iota // AlignAuto uses the alignment of the container, or stretches.
+	AlignStart                // AlignStart aligns at the top or left.
+	AlignCenter               // AlignCenter centers.
+	AlignEnd                  // AlignEnd aligns at the bottom or right.
+	AlignStretch              // AlignStretch stretches up to the maximum size.
+)
+
+// Justify is how kids are spread along the axis of a container when they
+// do not fill it.
+type Justify int
+
+const (
+	JustifyStart   Justify = iota // JustifyStart packs the kids at the start.
+	JustifyCenter                 // JustifyCenter packs the kids in the center.
+	JustifyEnd                    // JustifyEnd packs the kids at the end.
+	JustifyBetween                // JustifyBetween spreads the space between the kids.
+	JustifyAround                 // JustifyAround spreads the space around the kids.
+	JustifyEvenly                 // JustifyEvenly spreads the space evenly, also at the ends.
+)
+
+// Item lays out a widget with other sizes than it measures, or that grows
+// or shrinks with the container. Widgets that are not an Item shrink with
+// factor 1 and do not grow.
+type Item struct {
+	Widget
+	Size   Size  // Sizes that are not 0 replace the measured ones.
+	Grow   int   // Grow is the factor of the free space the item grows by.
+	Shrink int   // Shrink is the factor of the lack of space the item shrinks by.
+	Align  Align // Align overrides the alignment of the container.
+}
+
+// Grow returns an [Item] of the widget that grows by grow.
+func Grow(w Widget, grow int) *Item {
+	return &Item{Widget: w, Grow: grow, Shrink: 1}
+}
+
+var _ Measurer = &Item{}
+var _ Arranger = &Item{}
+
+func (it *Item) Measure(avail xgal.Point) Size {
+	size := Measure(it.Widget, avail)
+	if it.Size.Min.X > 0 {
+		size.Min.X = it.Size.Min.X
+	}
+	if it.Size.Min.Y > 0 {
+		size.Min.Y = it.Size.Min.Y
+	}
+	if it.Size.Pref.X > 0 {
+		size.Pref.X = it.Size.Pref.X
+	}
+	if it.Size.Pref.Y > 0 {
+		size.Pref.Y = it.Size.Pref.Y
+	}
+	if it.Size.Max.X > 0 {
+		size.Max.X = it.Size.Max.X
+	}
+	if it.Size.Max.Y > 0 {
+		size.Max.Y = it.Size.Max.Y
+	}
+	return size
+}
+
+func (it *Item) Arrange(bounds xgal.Rectangle) {
+	Arrange(it.Widget, bounds)
+}
+
+func (it *Item) MoveBy(delta xgal.Point) {
+	if mv, ok := it.Widget.(Mover); ok {
+		mv.MoveBy(delta)
+	}
+}
+
+// main returns the coordinate of p along the axis.
+func (a Axis) main(p xgal.Point) int {
+	if a == Horizontal {
+		return p.X
+	}
+	return p.Y
+}
+
+// cross returns the coordinate of p across the axis.
+func (a Axis) cross(p xgal.Point) int {
+	if a == Horizontal {
+		return p.Y
+	}
+	return p.X
+}
+
+// pt returns the point with the coordinates along and across the axis.
+func (a Axis) pt(main, cross int) xgal.Point {
+	if a == Horizontal {
+		return xgal.Pt(main, cross)
+	}
+	return xgal.Pt(cross, main)
+}
+
+// track is a size along an axis that grows and shrinks.
+type track struct {
+	min, pref, max int
+	grow, shrink   int
+}
+
+// distribute returns the sizes of the tracks so they fill space: their
+// preferred sizes, grown or shrunk by their factors but kept between their
+// minimum and maximum. Shrinking is in proportion to the preferred size as
+// well, so small tracks do not vanish first. Tracks that reach a limit
+// stop, and the others share the rest.
+func distribute(tracks []track, space int) []int {
+	sizes := make([]int, len(tracks))
+	frozen := make([]bool, len(tracks))
+	used := 0
+	for i, t := range tracks {
+		sizes[i] = limit(t.pref, t.min, t.max)
+		used += sizes[i]
+	}
+	for {
+		free := space - used
+		if free == 0 {
+			return sizes
+		}
+		weights := make([]int, len(tracks))
+		total := 0
+		for i, t := range tracks {
+			if frozen[i] {
+				continue
+			}
+			if free > 0 {
+				weights[i] = t.grow
+			} else {
+				weights[i] = t.shrink * max(t.pref, 1)
+			}
+			total += weights[i]
+		}
+		if total == 0 {
+			return sizes
+		}
+		// Hand out the shares cumulatively so they add up to free.
+		acc, given, clamped := 0, 0, false
+		for i, w := range weights {
+			if w == 0 {
+				continue
+			}
+			acc += free * w
+			share := acc/total - given
+			given += share
+			want := sizes[i] + share
+			got := limit(want, tracks[i].min, tracks[i].max)
+			if got != want {
+				frozen[i] = true
+				clamped = true
+			}
+			used += got - sizes[i]
+			sizes[i] = got
+		}
+		if !clamped {
+			return sizes
+		}
+	}
+}
+
+// justify returns the offsets of the sizes in space, with gap between
+// them.
+func justify(sizes []int, space, gap int, how Justify) []int {
+	offsets := make([]int, len(sizes))
+	if len(sizes) == 0 {
+		return offsets
+	}
+	used := gap * (len(sizes) - 1)
+	for _, s := range sizes {
+		used += s
+	}
+	free := max(space-used, 0)
+	n := len(sizes)
+	start := 0
+	extra := func(i int) int { return 0 } // Extra spacing after kid i.
+	switch how {
+	case JustifyCenter:
+		start = free / 2
+	case JustifyEnd:
+		start = free
+	case JustifyBetween:
+		if n > 1 {
+			extra = func(i int) int { return free*(i+1)/(n-1) - free*i/(n-1) }
+		}
+	case JustifyAround:
+		// Each kid has half a share on both sides.
+		start = free / (2 * n)
+		extra = func(i int) int { return free*(2*i+3)/(2*n) - free*(2*i+1)/(2*n) }
+	case JustifyEvenly:
+		start = free / (n + 1)
+		extra = func(i int) int { return free*(i+2)/(n+1) - free*(i+1)/(n+1) }
+	}
+	at := start
+	for i, s := range sizes {
+		offsets[i] = at
+		at += s + gap + extra(i)
+	}
+	return offsets
+}
+
+// align returns the offset and size of a kid with the sizes in a line of
+// size space.
+func align(low, pref, high, space int, how Align) (int, int) {
+	if how == AlignStretch {
+		return 0, limit(space, low, high)
+	}
+	size := limit(min(pref, space), low, high)
+	switch how {
+	case AlignCenter:
+		return (space - size) / 2, size
+	case AlignEnd:
+		return space - size, size
+	}
+	return 0, size
+}
+
+// flexKid is a kid of a layout: its sizes and how it grows and aligns.
+type flexKid struct {
+	Size
+	grow, shrink int
+	align        Align
+}
+
+// measureKids measures the widgets as kids of a layout.
+func measureKids(kids []Widget, avail xgal.Point) []flexKid {
+	measured := make([]flexKid, len(kids))
+	for i, kid := range kids {
+		measured[i] = flexKid{Size: Measure(kid, avail), shrink: 1}
+		if it, ok := kid.(*Item); ok {
+			measured[i].grow, measured[i].shrink, measured[i].align = it.Grow, it.Shrink, it.Align
+		}
+	}
+	return measured
+}
+
+// flex are the settings of a flex layout.
+type flex struct {
+	axis    Axis
+	gap     xgal.Point
+	justify Justify
+	align   Align
+	wrap    bool
+}
+
+// gaps returns the gaps along and across the axis.
+func (fl flex) gaps() (int, int) {
+	return fl.axis.main(fl.gap), fl.axis.cross(fl.gap)
+}
+
+// lines splits the kids in lines that fit in space, or returns one line
+// if the layout does not wrap. Every line has at least one kid.
+func (fl flex) lines(kids []flexKid, space int) [][]flexKid {
+	if !fl.wrap || len(kids) == 0 {
+		return [][]flexKid{kids}
+	}
+	gap, _ := fl.gaps()
+	var lines [][]flexKid
+	start, used := 0, 0
+	for i, kid := range kids {
+		size := limit(fl.axis.main(kid.Pref), fl.axis.main(kid.Min), fl.axis.main(kid.Max))
+		if i > start && used+gap+size > space {
+			lines = append(lines, kids[start:i])
+			start, used = i, 0
+		}
+		if i > start {
+			used += gap
+		}
+		used += size
+	}
+	return append(lines, kids[start:])
+}
+
+// lineCross returns the cross size of the line: the largest preferred
+// cross size of its kids.
+func (fl flex) lineCross(line []flexKid) int {
+	cross := 0
+	for _, kid := range line {
+		c := limit(fl.axis.cross(kid.Pref), fl.axis.cross(kid.Min), fl.axis.cross(kid.Max))
+		cross = max(cross, c)
+	}
+	return cross
+}
+
+// measure returns the sizes of the layout of the kids, without padding.
+func (fl flex) measure(kids []flexKid, avail xgal.Point) Size {
+	mainGap, crossGap := fl.gaps()
+	var minMain, minCross, prefMain, prefCross int
+	for i, kid := range kids {
+		if fl.wrap {
+			minMain = max(minMain, fl.axis.main(kid.Min))
+		} else {
+			if i > 0 {
+				minMain += mainGap
+			}
+			minMain += fl.axis.main(kid.Min)
+		}
+		minCross = max(minCross, fl.axis.cross(kid.Min))
+	}
+	space := fl.axis.main(avail)
+	if space <= 0 {
+		// Nothing wraps when there is no limit.
+		space = int(^uint(0) >> 1)
+	}
+	for i, line := range fl.lines(kids, space) {
+		used := 0
+		for j, kid := range line {
+			if j > 0 {
+				used += mainGap
+			}
+			used += limit(fl.axis.main(kid.Pref), fl.axis.main(kid.Min), fl.axis.main(kid.Max))
+		}
+		prefMain = max(prefMain, used)
+		if i > 0 {
+			prefCross += crossGap
+		}
+		prefCross += fl.lineCross(line)
+	}
+	return Size{Min: fl.axis.pt(minMain, minCross), Pref: fl.axis.pt(prefMain, max(prefCross, minCross))}
+}
+
+// arrange returns the bounds of the kids laid out in area.
+func (fl flex) arrange(kids []flexKid, area xgal.Rectangle) []xgal.Rectangle {
+	mainGap, crossGap := fl.gaps()
+	space := fl.axis.main(area.Size())
+	lines := fl.lines(kids, space)
+	origin := area.Min
+	rects := make([]xgal.Rectangle, 0, len(kids))
+	cross := 0
+	for _, line := range lines {
+		lineCross := fl.axis.cross(area.Size())
+		if fl.wrap {
+			lineCross = fl.lineCross(line)
+		}
+		tracks := make([]track, len(line))
+		for i, kid := range line {
+			tracks[i] = track{
+				min:  fl.axis.main(kid.Min),
+				pref: fl.axis.main(kid.Pref),
+				max:  fl.axis.main(kid.Max),
+				grow: kid.grow, shrink: kid.shrink,
+			}
+		}
+		sizes := distribute(tracks, space-mainGap*(len(line)-1))
+		offsets := justify(sizes, space, mainGap, fl.justify)
+		for i, kid := range line {
+			how := kid.align
+			if how == AlignAuto {
+				how = fl.align
+			}
+			if how == AlignAuto {
+				how = AlignStretch
+			}
+			at, size := align(fl.axis.cross(kid.Min), fl.axis.cross(kid.Pref), fl.axis.cross(kid.Max), lineCross, how)
+			corner := origin.Add(fl.axis.pt(offsets[i], cross+at))
+			rects = append(rects, xgal.Rectangle{Min: corner, Max: corner.Add(fl.axis.pt(sizes[i], size))})
+		}
+		cross += lineCross + crossGap
+	}
+	return rects
+}
+
+// FlexLayer lays out its kids along its Axis in the order they were added.
+// Kids grow and shrink to fill the line, and wrap to new lines if Wrap is
+// set. The Margin of the style is the padding around the kids. Wrap kids
+// in an [Item] to change how they grow, shrink or align.
+type FlexLayer struct {
+	Layer
+	Gap     xgal.Point // Gap between the kids and between the lines.
+	Justify Justify    // Justify spreads the kids along the axis.
+	Align   Align      // Align aligns the kids across the axis.
+	Wrap    bool       // Wrap wraps kids that do not fit to a new line.
+}
+
+// Flex returns a new [FlexLayer] along the axis.
+func Flex(bounds xgal.Rectangle, axis Axis) *FlexLayer {
+	f := &FlexLayer{}
+	f.Layer = MakeLayer(bounds)
+	f.Axis = axis
+	return f
+}
+
+var _ Measurer = &FlexLayer{}
+var _ Arranger = &FlexLayer{}
+
+// ordered returns the kids in the order they were added, which is the
+// order they are laid out in.
+func (m *Layer) ordered() []Widget {
+	kids := make([]Widget, 0, len(m.Kids))
+	for i := len(m.Kids) - 1; i >= 0; i-- {
+		if m.Kids[i] != nil {
+			kids = append(kids, m.Kids[i])
+		}
+	}
+	return kids
+}
+
+func (f *FlexLayer) flex() flex {
+	return flex{axis: f.Axis, gap: f.Gap, justify: f.Justify, align: f.Align, wrap: f.Wrap}
+}
+
+func (f *FlexLayer) Measure(avail xgal.Point) Size {
+	pad := f.Style.Margin.Mul(2)
+	inner := avail.Sub(pad)
+	size := f.flex().measure(measureKids(f.ordered(), inner), inner)
+	size.Min = size.Min.Add(pad)
+	size.Pref = size.Pref.Add(pad)
+	return size
+}
+
+func (f *FlexLayer) Arrange(bounds xgal.Rectangle) {
+	f.Bounds = bounds
+	area := f.Style.Inset(bounds)
+	kids := f.ordered()
+	rects := f.flex().arrange(measureKids(kids, area.Size()), area)
+	for i, kid := range kids {
+		Arrange(kid, rects[i])
+	}
+}
+
+// Place arranges the layer to fill bounds.
+func (f *FlexLayer) Place(bounds xgal.Rectangle) xgal.Rectangle {
+	f.Arrange(bounds)
+	return f.Bounds
+}
+
+// AddFlex adds a [FlexLayer] along the axis to this layer.
+func (m *Layer) AddFlex(bounds xgal.Rectangle, axis Axis) *FlexLayer {
+	f := Flex(bounds, axis)
+	m.Add(f)
+	return f
+}
+
+// grid returns the column and row tracks of the kids in columns.
+func grid(kids []flexKid, columns int) ([]track, []track) {
+	rows := (len(kids) + columns - 1) / columns
+	cols := make([]track, columns)
+	lines := make([]track, rows)
+	for i := range cols {
+		cols[i].grow, cols[i].shrink = 1, 1
+	}
+	for i := range lines {
+		lines[i].shrink = 1
+	}
+	for i, kid := range kids {
+		col, row := &cols[i%columns], &lines[i/columns]
+		col.min, col.pref = max(col.min, kid.Min.X), max(col.pref, kid.Pref.X)
+		row.min, row.pref = max(row.min, kid.Min.Y), max(row.pref, kid.Pref.Y)
+	}
+	return cols, lines
+}
+
+// sum returns the sum of the sizes of the tracks and the gaps between
+// them.
+func sum(tracks []track, gap int, size func(track) int) int {
+	total := gap * max(len(tracks)-1, 0)
+	for _, t := range tracks {
+		total += size(t)
+	}
+	return total
+}
+
+// GridLayer lays out its kids in cells of Columns columns, row after row,
+// in the order they were added. The columns share the width, and the rows
+// are as high as their highest kid. The Margin of the style is the padding
+// around the cells.
+type GridLayer struct {
+	Layer
+	Columns int
+	Gap     xgal.Point // Gap between the columns and rows.
+	Align   Align      // Align aligns the kids in their cells.
+}
+
+// Grid returns a new [GridLayer] of columns.
+func Grid(bounds xgal.Rectangle, columns int) *GridLayer {
+	g := &GridLayer{Columns: columns}
+	g.Layer = MakeLayer(bounds)
+	return g
+}
+
+var _ Measurer = &GridLayer{}
+var _ Arranger = &GridLayer{}
+
+func (g *GridLayer) Measure(avail xgal.Point) Size {
+	pad := g.Style.Margin.Mul(2)
+	kids := measureKids(g.ordered(), avail.Sub(pad))
+	cols, rows := grid(kids, max(g.Columns, 1))
+	minOf := func(t track) int { return t.min }
+	prefOf := func(t track) int { return t.pref }
+	return Size{
+		Min:  xgal.Pt(sum(cols, g.Gap.X, minOf), sum(rows, g.Gap.Y, minOf)).Add(pad),
+		Pref: xgal.Pt(sum(cols, g.Gap.X, prefOf), sum(rows, g.Gap.Y, prefOf)).Add(pad),
+	}
+}
+
+// arrangeGrid returns the bounds of the kids in the cells of the grid in
+// area.
+func arrangeGrid(kids []flexKid, columns int, gap xgal.Point, how Align, area xgal.Rectangle) []xgal.Rectangle {
+	cols, rows := grid(kids, columns)
+	widths := distribute(cols, area.Dx()-gap.X*(len(cols)-1))
+	// Rows are as high as they prefer, unless they do not fit.
+	prefs := sum(rows, 0, func(t track) int { return t.pref })
+	heights := distribute(rows, min(area.Dy()-gap.Y*max(len(rows)-1, 0), prefs))
+	xs := justify(widths, area.Dx(), gap.X, JustifyStart)
+	ys := justify(heights, area.Dy(), gap.Y, JustifyStart)
+	rects := make([]xgal.Rectangle, len(kids))
+	for i, kid := range kids {
+		col, row := i%columns, i/columns
+		cell := kid.align
+		if cell == AlignAuto {
+			cell = how
+		}
+		if cell == AlignAuto {
+			cell = AlignStretch
+		}
+		x, w := align(kid.Min.X, kid.Pref.X, kid.Max.X, widths[col], cell)
+		y, h := align(kid.Min.Y, kid.Pref.Y, kid.Max.Y, heights[row], cell)
+		corner := area.Min.Add(xgal.Pt(xs[col]+x, ys[row]+y))
+		rects[i] = xgal.Rectangle{Min: corner, Max: corner.Add(xgal.Pt(w, h))}
+	}
+	return rects
+}
+
+func (g *GridLayer) Arrange(bounds xgal.Rectangle) {
+	g.Bounds = bounds
+	area := g.Style.Inset(bounds)
+	kids := g.ordered()
+	rects := arrangeGrid(measureKids(kids, area.Size()), max(g.Columns, 1), g.Gap, g.Align, area)
+	for i, kid := range kids {
+		Arrange(kid, rects[i])
+	}
+}
+
+// Place arranges the layer to fill bounds.
+func (g *GridLayer) Place(bounds xgal.Rectangle) xgal.Rectangle {
+	g.Arrange(bounds)
+	return g.Bounds
+}
+
+// AddGrid adds a [GridLayer] of columns to this layer.
+func (m *Layer) AddGrid(bounds xgal.Rectangle, columns int) *GridLayer {
+	g := Grid(bounds, columns)
+	m.Add(g)
+	return g
+}
+
+// ScrollLayer shows its kids, laid out along its Axis, in a view that can
+// be smaller than they are. The kids are clipped to the view, and the
+// mouse wheel scrolls them. Kids only get input while the cursor is in
+// the view.
+type ScrollLayer struct {
+	Layer
+	Gap     xgal.Point     // Gap between the kids.
+	Scroll  int            // Scroll is how far the kids are scrolled along the axis.
+	Content xgal.Rectangle // Content is the area of the kids, unscrolled.
+}
+
+// Scroller returns a new vertical [ScrollLayer].
+func Scroller(bounds xgal.Rectangle) *ScrollLayer {
+	s := &ScrollLayer{}
+	s.Layer = MakeLayer(bounds)
+	return s
+}
+
+var _ Measurer = &ScrollLayer{}
+var _ Arranger = &ScrollLayer{}
+
+func (s *ScrollLayer) flex() flex {
+	return flex{axis: s.Axis, gap: s.Gap}
+}
+
+// Measure prefers the size of the kids, but can shrink to nothing along
+// the axis since the kids scroll.
+func (s *ScrollLayer) Measure(avail xgal.Point) Size {
+	pad := s.Style.Margin.Mul(2)
+	size := s.flex().measure(measureKids(s.ordered(), avail.Sub(pad)), avail.Sub(pad))
+	size.Min = s.Axis.pt(0, s.Axis.cross(size.Min)).Add(pad)
+	size.Pref = size.Pref.Add(pad)
+	return size
+}
+
+// View returns the area the kids are shown in.
+func (s *ScrollLayer) View() xgal.Rectangle {
+	return s.Style.Inset(s.Bounds)
+}
+
+// MaxScroll returns how far the kids can scroll.
+func (s *ScrollLayer) MaxScroll() int {
+	return max(s.Axis.main(s.Content.Size())-s.Axis.main(s.View().Size()), 0)
+}
+
+// Arrange lays the kids out in the view, as long along the axis as they
+// prefer, and keeps them scrolled as far as they can be.
+func (s *ScrollLayer) Arrange(bounds xgal.Rectangle) {
+	s.Bounds = bounds
+	view := s.View()
+	kids := s.ordered()
+	fl := s.flex()
+	measured := measureKids(kids, view.Size())
+	pref := fl.measure(measured, view.Size()).Pref
+	main := max(s.Axis.main(pref), s.Axis.main(view.Size()))
+	s.Content = xgal.Rectangle{Min: view.Min, Max: view.Min.Add(s.Axis.pt(main, s.Axis.cross(view.Size())))}
+	s.Scroll = clamp(s.Scroll, 0, s.MaxScroll())
+	rects := fl.arrange(measured, s.Content.Sub(s.Axis.pt(s.Scroll, 0)))
+	for i, kid := range kids {
+		Arrange(kid, rects[i])
+	}
+}
+
+// Place arranges the layer to fill bounds.
+func (s *ScrollLayer) Place(bounds xgal.Rectangle) xgal.Rectangle {
+	s.Arrange(bounds)
+	return s.Bounds
+}
+
+// ScrollBy scrolls the kids by delta along the axis, as far as they can.
+func (s *ScrollLayer) ScrollBy(delta int) {
+	scroll := clamp(s.Scroll+delta, 0, s.MaxScroll())
+	move := s.Axis.pt(s.Scroll-scroll, 0)
+	s.Scroll = scroll
+	for _, kid := range s.Kids {
+		if mv, ok := kid.(Mover); ok {
+			mv.MoveBy(move)
+		}
+	}
+}
+
+func (s *ScrollLayer) MoveBy(delta xgal.Point) {
+	s.Layer.MoveBy(delta)
+	s.Content = s.Content.Add(delta)
+}
+
+func (s *ScrollLayer) Poll() Reply {
+	if !xgal.Cursor().In(s.View()) {
+		return Ignore
+	}
+	_, wy := xgal.Wheel()
+	if wy != 0 {
+		s.ScrollBy(int(wy) * ScrollSpeed)
+		return Accept
+	}
+	return s.PollKids()
+}
+
+func (s *ScrollLayer) Render(screen *xgal.Surface) {
+	s.Style.DrawBox(screen, s.Bounds)
+	view := s.View()
+	clipped := screen.SubImage(view).(*xgal.Surface)
+	s.RenderKids(clipped)
+	if most := s.MaxScroll(); most > 0 {
+		// A thumb on the edge shows how far the kids are scrolled.
+		length := s.Axis.main(view.Size())
+		thumb := max(length*length/(length+most), 4)
+		at := (length - thumb) * s.Scroll / most
+		bar := xgal.Rectangle{Min: view.Min.Add(s.Axis.pt(at, s.Axis.cross(view.Size())-2)), Max: view.Max}
+		bar.Max = bar.Min.Add(s.Axis.pt(thumb, 2))
+		s.Style.BarStyle().DrawBox(screen, bar)
+	}
+}
+
+// AddScroll adds a [ScrollLayer] to this layer.
+func (m *Layer) AddScroll(bounds xgal.Rectangle) *ScrollLayer {
+	s := Scroller(bounds)
+	m.Add(s)
+	return s
+}
+
+// Arrange gives the label the bounds.
+func (l *LabelLayer) Arrange(bounds xgal.Rectangle) {
+	l.Bounds = bounds
+}
+
+// Arrange gives the button the bounds.
+func (b *ButtonLayer) Arrange(bounds xgal.Rectangle) {
+	b.Bounds = bounds
+}
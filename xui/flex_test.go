@@ -0,0 +1,213 @@
+package xui
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/xmasengine/xmas/xgal"
+)
+
+// box is a widget that only has sizes, to test layouts on rectangles.
+type box struct {
+	size   Size
+	bounds xgal.Rectangle
+}
+
+func (b *box) Poll() Reply                   { return Ignore }
+func (b *box) Render(s *xgal.Surface)        {}
+func (b *box) Measure(xgal.Point) Size       { return b.size }
+func (b *box) Arrange(bounds xgal.Rectangle) { b.bounds = bounds }
+func (b *box) MoveBy(delta xgal.Point)       { b.bounds = b.bounds.Add(delta) }
+func (b *box) Place(bounds xgal.Rectangle) xgal.Rectangle {
+	b.bounds = xgal.Rectangle{Min: bounds.Min, Max: bounds.Min.Add(b.size.Pref)}
+	return b.bounds
+}
+
+func fixed(w, h int) *box {
+	return &box{size: Size{Min: xgal.Pt(w, h), Pref: xgal.Pt(w, h)}}
+}
+
+func sized(minW, prefW, maxW, h int) *box {
+	return &box{size: Size{Min: xgal.Pt(minW, h), Pref: xgal.Pt(prefW, h), Max: xgal.Pt(maxW, 0)}}
+}
+
+func TestDistribute(t *testing.T) {
+	for _, c := range []struct {
+		name   string
+		tracks []track
+		space  int
+		want   []int
+	}{
+		{"preferred", []track{{pref: 10}, {pref: 20}}, 30, []int{10, 20}},
+		{"grow", []track{{pref: 10, grow: 1}, {pref: 10, grow: 3}}, 60, []int{20, 40}},
+		{"no grow", []track{{pref: 10}, {pref: 10}}, 60, []int{10, 10}},
+		{"grow to max", []track{{pref: 10, max: 15, grow: 1}, {pref: 10, grow: 1}}, 60, []int{15, 45}},
+		{"shrink by size", []track{{pref: 30, shrink: 1}, {pref: 10, shrink: 1}}, 20, []int{15, 5}},
+		{"shrink to min", []track{{min: 25, pref: 30, shrink: 1}, {pref: 10, shrink: 1}}, 30, []int{25, 5}},
+		{"rounding", []track{{grow: 1}, {grow: 1}, {grow: 1}}, 10, []int{3, 3, 4}},
+	} {
+		if got := distribute(c.tracks, c.space); !slices.Equal(got, c.want) {
+			t.Errorf("%s: %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestJustify(t *testing.T) {
+	sizes := []int{10, 10, 10}
+	for how, want := range map[Justify][]int{
+		JustifyStart:   {0, 12, 24},
+		JustifyCenter:  {33, 45, 57},
+		JustifyEnd:     {66, 78, 90},
+		JustifyBetween: {0, 45, 90},
+		JustifyAround:  {11, 45, 79},
+		JustifyEvenly:  {16, 45, 73},
+	} {
+		if got := justify(sizes, 100, 2, how); !slices.Equal(got, want) {
+			t.Errorf("justify %d: %v, want %v", how, got, want)
+		}
+	}
+}
+
+func TestFlexRow(t *testing.T) {
+	a, b, c := fixed(20, 10), sized(10, 30, 0, 10), fixed(10, 20)
+	f := Flex(xgal.Rect(0, 0, 100, 40), Horizontal)
+	f.Style = Style{Margin: xgal.Pt(2, 2)}
+	f.Gap = xgal.Pt(4, 4)
+	f.Align = AlignCenter
+	f.Add(a)
+	f.Add(Grow(b, 1))
+	f.Add(c)
+	f.Place(xgal.Rect(0, 0, 100, 40))
+	// 96 wide inside: 20 + 4 + grown + 4 + 10.
+	if want := xgal.Rect(2, 15, 22, 25); a.bounds != want {
+		t.Errorf("a: %v, want %v", a.bounds, want)
+	}
+	if want := xgal.Rect(26, 15, 84, 25); b.bounds != want {
+		t.Errorf("b: %v, want %v", b.bounds, want)
+	}
+	if want := xgal.Rect(88, 10, 98, 30); c.bounds != want {
+		t.Errorf("c: %v, want %v", c.bounds, want)
+	}
+
+	size := f.Measure(xgal.Pt(100, 40))
+	if size.Pref != xgal.Pt(2+20+4+30+4+10+2, 24) || size.Min != xgal.Pt(2+20+4+10+4+10+2, 24) {
+		t.Errorf("measure: %+v", size)
+	}
+
+	// Without room the growing kid shrinks to its minimum, and the
+	// others shrink as far as they can.
+	f.Place(xgal.Rect(0, 0, 40, 40))
+	if b.bounds.Dx() != 10 || a.bounds.Dx() != 20 {
+		t.Errorf("shrunk: %v %v", a.bounds, b.bounds)
+	}
+}
+
+func TestFlexStretchAndWrap(t *testing.T) {
+	kids := []*box{fixed(30, 10), fixed(30, 20), fixed(30, 10), sized(10, 10, 0, 5)}
+	f := Flex(xgal.Rect(0, 0, 70, 100), Horizontal)
+	f.Style = Style{}
+	f.Wrap = true
+	f.Gap = xgal.Pt(5, 3)
+	for _, kid := range kids {
+		f.Add(kid)
+	}
+	f.Place(xgal.Rect(0, 0, 70, 100))
+	want := []xgal.Rectangle{
+		xgal.Rect(0, 0, 30, 20), // Stretched to the height of the line.
+		xgal.Rect(35, 0, 65, 20),
+		xgal.Rect(0, 23, 30, 33),
+		xgal.Rect(35, 23, 45, 33),
+	}
+	for i, kid := range kids {
+		if kid.bounds != want[i] {
+			t.Errorf("kid %d: %v, want %v", i, kid.bounds, want[i])
+		}
+	}
+	size := f.Measure(xgal.Pt(70, 0))
+	if size.Pref != xgal.Pt(65, 33) || size.Min.X != 30 {
+		t.Errorf("measure: %+v", size)
+	}
+}
+
+func TestFlexNested(t *testing.T) {
+	inner := Flex(xgal.Rectangle{}, Vertical)
+	inner.Style = Style{}
+	top, bottom := fixed(10, 10), fixed(20, 10)
+	inner.Add(top)
+	inner.Add(bottom)
+
+	outer := Flex(xgal.Rectangle{}, Horizontal)
+	outer.Style = Style{}
+	side := fixed(5, 5)
+	outer.Add(side)
+	outer.Add(Grow(inner, 1))
+	outer.Place(xgal.Rect(10, 10, 60, 40))
+
+	if want := xgal.Rect(15, 10, 60, 40); inner.Bounds != want {
+		t.Errorf("inner: %v, want %v", inner.Bounds, want)
+	}
+	if want := xgal.Rect(15, 20, 60, 30); bottom.bounds != want {
+		t.Errorf("bottom: %v, want %v", bottom.bounds, want)
+	}
+	if want := xgal.Rect(10, 10, 15, 40); side.bounds != want {
+		t.Errorf("side: %v, want %v", side.bounds, want)
+	}
+}
+
+func TestGrid(t *testing.T) {
+	kids := []*box{fixed(10, 10), fixed(20, 5), fixed(10, 15), fixed(5, 5), fixed(5, 5)}
+	g := Grid(xgal.Rectangle{}, 2)
+	g.Style = Style{}
+	g.Gap = xgal.Pt(2, 1)
+	g.Align = AlignStart
+	for _, kid := range kids {
+		g.Add(kid)
+	}
+	size := g.Measure(xgal.Pt(100, 100))
+	if size.Pref != xgal.Pt(10+2+20, 10+1+15+1+5) {
+		t.Errorf("measure: %+v", size)
+	}
+	g.Place(xgal.Rect(0, 0, 42, 100))
+	// The columns grow from 10 and 20 to 15 and 25.
+	want := []xgal.Rectangle{
+		xgal.Rect(0, 0, 10, 10),
+		xgal.Rect(17, 0, 37, 5),
+		xgal.Rect(0, 11, 10, 26),
+		xgal.Rect(17, 11, 22, 16),
+		xgal.Rect(0, 27, 5, 32),
+	}
+	for i, kid := range kids {
+		if kid.bounds != want[i] {
+			t.Errorf("kid %d: %v, want %v", i, kid.bounds, want[i])
+		}
+	}
+}
+
+func TestScroll(t *testing.T) {
+	s := Scroller(xgal.Rectangle{})
+	s.Style = Style{}
+	kids := []*box{fixed(10, 30), fixed(10, 30), fixed(10, 30)}
+	for _, kid := range kids {
+		s.Add(kid)
+	}
+	if size := s.Measure(xgal.Pt(50, 50)); size.Min.Y != 0 || size.Pref.Y != 90 {
+		t.Errorf("measure: %+v", size)
+	}
+	s.Place(xgal.Rect(0, 0, 50, 40))
+	if s.MaxScroll() != 50 || kids[1].bounds != xgal.Rect(0, 30, 50, 60) {
+		t.Fatalf("max scroll %d, kid %v", s.MaxScroll(), kids[1].bounds)
+	}
+	s.ScrollBy(100)
+	if s.Scroll != 50 || kids[2].bounds != xgal.Rect(0, 10, 50, 40) {
+		t.Errorf("scrolled %d: %v", s.Scroll, kids[2].bounds)
+	}
+	// Placing again keeps the kids scrolled.
+	s.Place(xgal.Rect(0, 0, 50, 40))
+	if kids[2].bounds != xgal.Rect(0, 10, 50, 40) {
+		t.Errorf("placed again: %v", kids[2].bounds)
+	}
+	s.Place(xgal.Rect(0, 0, 50, 100))
+	if s.Scroll != 0 || s.MaxScroll() != 0 {
+		t.Errorf("scrolled without need: %d", s.Scroll)
+	}
+}
@@ -49,7 +49,7 @@ const hudMinH = 32
 func HUD(screenW int) *HUDLayer {
 	return &HUDLayer{
 		Bounds: xgal.Rect(0, 0, screenW, hudMinH),
-		Style:  DefaultStyle(),
+		Style:  NamedStyle("hud"),
 	}
 }
 
@@ -0,0 +1,291 @@
+package xui
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/xmasengine/xmas/xgal"
+)
+
+// InspectTag is the struct tag that an [InspectorLayer] honours. Its
+// value is the label of the field, followed by options after commas:
+//
+//	Width int `ui:"Width in tiles,range=1:256"`
+//	Cache int `ui:"-"`
+//
+// A label of "-" hides the field, and an empty label uses the name of the
+// field. The option range=low:high edits an integer with a slider.
+const InspectTag = "ui"
+
+// Property is a field of a struct that an [InspectorLayer] edits.
+type Property struct {
+	Name   string       // Name is the path of the field, such as "Bound.Min.X".
+	Label  string       // Label is shown in front of the field.
+	Index  []int        // Index is the index sequence of the field, for [reflect.Value.FieldByIndex].
+	Kind   reflect.Kind // Kind is the kind of the field.
+	Low    int          // Low is the lowest value of a ranged integer.
+	High   int          // High is the highest value of a ranged integer.
+	Ranged bool         // Ranged is set if the integer has a range.
+}
+
+// Properties returns the properties of the exported fields of the struct
+// type that can be edited, which are those of bools, numbers and strings.
+// The fields of embedded structs are included as if they were fields of
+// the struct, and those of other struct fields with the name of the field
+// in front. Other fields are left out, as are fields with a label of "-".
+func Properties(typ reflect.Type) ([]Property, error) {
+	return properties(typ, nil, "", "")
+}
+
+func properties(typ reflect.Type, index []int, name, label string) ([]Property, error) {
+	var props []Property
+	for i := range typ.NumField() {
+		field := typ.Field(i)
+		embedded := field.Anonymous && field.Type.Kind() == reflect.Struct
+		if !field.IsExported() && !embedded {
+			continue
+		}
+		tag := strings.Split(field.Tag.Get(InspectTag), ",")
+		if tag[0] == "-" {
+			continue
+		}
+		prop := Property{
+			Name:  name + field.Name,
+			Label: label + field.Name,
+			Index: append(append([]int{}, index...), i),
+			Kind:  field.Type.Kind(),
+		}
+		if tag[0] != "" {
+			prop.Label = label + tag[0]
+		}
+		for _, opt := range tag[1:] {
+			key, value, _ := strings.Cut(opt, "=")
+			switch key {
+			case "range":
+				low, high, _ := strings.Cut(value, ":")
+				var err1, err2 error
+				prop.Low, err1 = strconv.Atoi(low)
+				prop.High, err2 = strconv.Atoi(high)
+				if err1 != nil || err2 != nil || prop.High < prop.Low {
+					return nil, fmt.Errorf("%s: bad range %q", prop.Name, value)
+				}
+				prop.Ranged = true
+			default:
+				return nil, fmt.Errorf("%s: unknown option %q", prop.Name, opt)
+			}
+		}
+
+		switch prop.Kind {
+		case reflect.Struct:
+			subName, subLabel := prop.Name+".", prop.Label+" "
+			if embedded {
+				subName, subLabel = name, label
+			}
+			sub, err := properties(field.Type, prop.Index, subName, subLabel)
+			if err != nil {
+				return nil, err
+			}
+			props = append(props, sub...)
+			continue
+		case reflect.Bool, reflect.String, reflect.Float32, reflect.Float64:
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		default:
+			continue
+		}
+		if prop.Ranged && !prop.integer() {
+			return nil, fmt.Errorf("%s: range of a %s", prop.Name, prop.Kind)
+		}
+		props = append(props, prop)
+	}
+	return props, nil
+}
+
+// integer reports whether the property is an integer.
+func (p Property) integer() bool {
+	return p.signed() || p.unsigned()
+}
+
+func (p Property) signed() bool {
+	return p.Kind >= reflect.Int && p.Kind <= reflect.Int64
+}
+
+func (p Property) unsigned() bool {
+	return p.Kind >= reflect.Uint && p.Kind <= reflect.Uint64
+}
+
+// Mask returns the mask of an entry for the property.
+func (p Property) Mask() xgal.Mask {
+	switch {
+	case p.integer():
+		return xgal.IntegerMask
+	case p.Kind == reflect.Float32 || p.Kind == reflect.Float64:
+		return xgal.FloatMask
+	}
+	return xgal.Mask{}
+}
+
+// Get returns the value of the property of the struct as text.
+func (p Property) Get(target reflect.Value) string {
+	return fmt.Sprint(target.FieldByIndex(p.Index).Interface())
+}
+
+// Set sets the property of the struct to the value of the text. It
+// returns an error if the text is not a valid value, or is out of range.
+func (p Property) Set(target reflect.Value, text string) error {
+	field := target.FieldByIndex(p.Index)
+	switch {
+	case p.Kind == reflect.String:
+		field.SetString(text)
+		return nil
+	case p.Kind == reflect.Bool:
+		b, err := strconv.ParseBool(text)
+		if err != nil {
+			return fmt.Errorf("%s: %w", p.Name, err)
+		}
+		field.SetBool(b)
+		return nil
+	case p.signed():
+		i, err := strconv.ParseInt(text, 10, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("%s: %w", p.Name, err)
+		}
+		if err := p.check(i); err != nil {
+			return err
+		}
+		field.SetInt(i)
+	case p.unsigned():
+		u, err := strconv.ParseUint(text, 10, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("%s: %w", p.Name, err)
+		}
+		if err := p.check(int64(u)); err != nil {
+			return err
+		}
+		field.SetUint(u)
+	default:
+		f, err := strconv.ParseFloat(text, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("%s: %w", p.Name, err)
+		}
+		field.SetFloat(f)
+	}
+	return nil
+}
+
+// check checks that the integer is in the range, if any.
+func (p Property) check(i int64) error {
+	if p.Ranged && (i < int64(p.Low) || i > int64(p.High)) {
+		return fmt.Errorf("%s: %d out of range %d to %d", p.Name, i, p.Low, p.High)
+	}
+	return nil
+}
+
+// InspectorLayer edits the fields of a struct, such as an [xdat.Layer],
+// with a control per field in a grid with their labels: checkboxes for
+// bools, sliders for integers with a range, and entries for the other
+// numbers and strings. Entries set their field when Enter is pressed.
+// See [InspectTag] for the struct tags that it honours.
+type InspectorLayer struct {
+	GridLayer
+	Target   reflect.Value // Target is the struct that is edited.
+	Props    []Property
+	OnChange func(prop Property) // OnChange is called after a property changed.
+	labels   []*LabelLayer
+	editors  []Widget
+}
+
+// Inspector returns a new [InspectorLayer] that edits the struct that
+// target points to. It returns an error if target is not a pointer to a
+// struct or if its struct tags are not valid.
+func Inspector(bounds xgal.Rectangle, target any) (*InspectorLayer, error) {
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Pointer || value.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("cannot inspect %T", target)
+	}
+	props, err := Properties(value.Elem().Type())
+	if err != nil {
+		return nil, err
+	}
+	in := &InspectorLayer{Target: value.Elem(), Props: props}
+	in.GridLayer = *Grid(bounds, 2)
+	in.Gap = xgal.Pt(2, 2)
+	for i := range props {
+		in.addProperty(i)
+	}
+	in.Refresh()
+	return in, nil
+}
+
+// AddInspector is a helper to add an [InspectorLayer] to a [Layer].
+func (m *Layer) AddInspector(bounds xgal.Rectangle, target any) (*InspectorLayer, error) {
+	in, err := Inspector(bounds, target)
+	if err != nil {
+		return nil, err
+	}
+	m.Add(in)
+	return in, nil
+}
+
+// addProperty adds the label and the editor of the i-th property.
+func (in *InspectorLayer) addProperty(i int) {
+	prop := in.Props[i]
+	label := in.AddLabel(xgal.Rectangle{}, prop.Label)
+	var editor Widget
+	switch {
+	case prop.Kind == reflect.Bool:
+		editor = in.AddCheckbox(xgal.Rectangle{}, "", func(checked bool) {
+			in.set(i, strconv.FormatBool(checked))
+		})
+	case prop.Ranged:
+		slider := in.AddSlider(xgal.Rect(0, 0, 100, xgal.UILength(knobSize)), func(pos int) {
+			in.set(i, strconv.Itoa(pos))
+		})
+		slider.Low, slider.High = prop.Low, prop.High
+		editor = slider
+	default:
+		entry := in.AddEntry(xgal.Rectangle{}, "", func(text string) {
+			in.set(i, text)
+		})
+		entry.Mask = prop.Mask()
+		editor = entry
+	}
+	in.labels = append(in.labels, label)
+	in.editors = append(in.editors, editor)
+}
+
+// set sets the i-th property and calls OnChange. Invalid values are
+// replaced by the value of the field.
+func (in *InspectorLayer) set(i int, text string) {
+	err := in.Props[i].Set(in.Target, text)
+	in.refresh(i)
+	if err == nil && in.OnChange != nil {
+		in.OnChange(in.Props[i])
+	}
+}
+
+// Refresh shows the values of the fields again, for example after the
+// struct was changed elsewhere.
+func (in *InspectorLayer) Refresh() {
+	for i := range in.Props {
+		in.refresh(i)
+	}
+}
+
+func (in *InspectorLayer) refresh(i int) {
+	prop := in.Props[i]
+	value := prop.Get(in.Target)
+	switch editor := in.editors[i].(type) {
+	case *CheckboxLayer:
+		editor.Checked = value == "true"
+	case *SliderLayer:
+		editor.Pos, _ = strconv.Atoi(value)
+		in.labels[i].Text = prop.Label + " " + value
+	case *EntryLayer:
+		if editor.String() != value {
+			editor.SetText(value)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package xui
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/xmasengine/xmas/xgal"
+)
+
+type inspected struct {
+	Name   string
+	Width  int  `ui:"Width in tiles,range=1:64"`
+	Solid  bool `ui:"Is solid"`
+	Speed  float64
+	Depth  uint16
+	Cache  int `ui:"-"`
+	Bound  xgal.Rectangle
+	Shown  *xgal.Surface
+	hidden int
+	inspectedBase
+}
+
+type inspectedBase struct {
+	Color int
+}
+
+func TestProperties(t *testing.T) {
+	props, err := Properties(reflect.TypeFor[inspected]())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names, labels []string
+	for _, prop := range props {
+		names = append(names, prop.Name)
+		labels = append(labels, prop.Label)
+	}
+	want := []string{"Name", "Width", "Solid", "Speed", "Depth", "Bound.Min.X", "Bound.Min.Y", "Bound.Max.X", "Bound.Max.Y", "Color"}
+	if !reflect.DeepEqual(names, want) {
+		t.Fatalf("names %q, want %q", names, want)
+	}
+	if labels[1] != "Width in tiles" || labels[2] != "Is solid" || labels[5] != "Bound Min X" {
+		t.Errorf("labels %q", labels)
+	}
+	if width := props[1]; !width.Ranged || width.Low != 1 || width.High != 64 {
+		t.Errorf("width range: %+v", width)
+	}
+
+	var bad struct {
+		Name string `ui:"Name,range=1:2"`
+	}
+	if _, err := Properties(reflect.TypeOf(bad)); err == nil {
+		t.Errorf("no error for the range of a string")
+	}
+}
+
+func TestPropertySet(t *testing.T) {
+	var target inspected
+	value := reflect.ValueOf(&target).Elem()
+	props, err := Properties(value.Type())
+	if err != nil {
+		t.Fatal(err)
+	}
+	set := func(i int, text string) error {
+		return props[i].Set(value, text)
+	}
+	if err := set(1, "12"); err != nil || target.Width != 12 {
+		t.Errorf("width: %d, %v", target.Width, err)
+	}
+	if err := set(1, "65"); err == nil || target.Width != 12 {
+		t.Errorf("width out of range: %d, %v", target.Width, err)
+	}
+	if err := set(4, "-1"); err == nil {
+		t.Errorf("no error for a negative depth")
+	}
+	if err := set(3, "1.5"); err != nil || target.Speed != 1.5 {
+		t.Errorf("speed: %v, %v", target.Speed, err)
+	}
+	if err := set(6, "7"); err != nil || target.Bound.Min.Y != 7 {
+		t.Errorf("bound: %v, %v", target.Bound, err)
+	}
+	if err := set(9, "3"); err != nil || target.Color != 3 || props[9].Get(value) != "3" {
+		t.Errorf("embedded color: %d, %v", target.Color, err)
+	}
+}
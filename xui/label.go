@@ -7,7 +7,8 @@ type LabelLayer struct {
 	Bounds xgal.Rectangle
 	Style  Style
 	Text   string
-	Icon   Icon // optional icon, drawn left of text
+	Key    string // Key is the string table key of the text, if any.
+	Icon   Icon   // optional icon, drawn left of text
 	hover  bool
 }
 
@@ -2,6 +2,8 @@ package xui
 
 import "github.com/xmasengine/xmas/xgal"
 
+// ListItemHeight is the height of the items of lists, trees and tables,
+// before it is scaled by [xgal.UIScale].
 const ListItemHeight = 14
 
 // ListLayer is a vertical list of selectable text items with optional scrolling.
@@ -18,7 +20,7 @@ type ListLayer struct {
 
 // List returns a new [ListLayer].
 func List(bounds xgal.Rectangle) *ListLayer {
-	l := &ListLayer{Selected: -1, hoverIdx: -1, ItemHeight: ListItemHeight}
+	l := &ListLayer{Selected: -1, hoverIdx: -1, ItemHeight: xgal.UILength(ListItemHeight)}
 	l.Layer = MakeLayer(bounds)
 	return l
 }
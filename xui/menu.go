@@ -53,7 +53,8 @@ type MenuItemLayer struct {
 	Bounds  xgal.Rectangle
 	Style   Style
 	Text    string
-	Icon    Icon // optional icon, drawn left of text
+	Key     string // Key is the string table key of the text, if any.
+	Icon    Icon   // optional icon, drawn left of text
 	Click   func()
 	Submenu *MenuLayer
 	leaf    bool // true when this item is in a dropdown and should return Finish on click
@@ -64,7 +65,7 @@ type MenuItemLayer struct {
 func MenuItem(bounds xgal.Rectangle, text string, click func()) *MenuItemLayer {
 	return &MenuItemLayer{
 		Bounds: bounds,
-		Style:  DefaultStyle(),
+		Style:  NamedStyle("menu"),
 		Text:   text,
 		Click:  click,
 	}
@@ -73,9 +74,13 @@ func MenuItem(bounds xgal.Rectangle, text string, click func()) *MenuItemLayer {
 var _ Widget = &MenuItemLayer{}
 
 func (i *MenuItemLayer) Poll() Reply {
-	i.hover = xgal.Cursor().In(i.Bounds)
+	hover := xgal.Cursor().In(i.Bounds)
+	if hover && !i.hover {
+		announce(xgal.CueFocus, i.Text, "")
+	}
+	i.hover = hover
 
-	if i.Submenu != nil && !i.Submenu.hidden {
+	if i.Submenu != nil && i.Submenu.Shown() {
 		i.Submenu.Bounds.Min = i.Bounds.Min.Add(xgal.Pt(0, i.Bounds.Dy()))
 		i.Submenu.Bounds.Max = i.Submenu.Bounds.Min.Add(
 			xgal.Pt(i.Bounds.Dx()*3, i.Submenu.ItemHeight()*len(i.Submenu.Kids)))
@@ -83,7 +88,7 @@ func (i *MenuItemLayer) Poll() Reply {
 		res := i.Submenu.Poll()
 		if res != Ignore {
 			if res == Finish {
-				i.Submenu.hidden = true
+				i.Submenu.Show(false)
 				return Accept // don't propagate Finish — would delete this item
 			}
 			return res
@@ -97,7 +102,7 @@ func (i *MenuItemLayer) Poll() Reply {
 			i.Click()
 		}
 		if i.Submenu != nil {
-			i.Submenu.hidden = !i.Submenu.hidden
+			i.Submenu.Show(!i.Submenu.Shown())
 			return Accept
 		}
 		return i.reply()
@@ -127,7 +132,7 @@ func (i *MenuItemLayer) Render(s *xgal.Surface) {
 	i.Icon.Blit(s, i.Bounds.Min)
 	style.Ink(s, i.Icon.TextBounds(i.Bounds), i.Text)
 
-	if i.Submenu != nil && !i.Submenu.hidden {
+	if i.Submenu != nil && i.Submenu.Shown() {
 		i.Submenu.Render(s)
 	}
 }
@@ -162,22 +167,48 @@ type MenuLayer struct {
 	Bounds xgal.Rectangle
 	Style  Style
 	Kids   []Widget
+	Motion *xgal.Motion // Motion slides the menu in when it is shown.
 	hidden bool
-	itemH  int // cached item height
+	itemH  int           // cached item height
+	anim   xgal.Timeline // plays the slide
 }
 
 // Menu returns a new [MenuLayer] at the given position (hidden by default).
 func Menu(bounds xgal.Rectangle) *MenuLayer {
 	return &MenuLayer{
 		Bounds: bounds,
-		Style:  DefaultStyle(),
+		Style:  NamedStyle("menu"),
+		Motion: xgal.NewMotion(),
 		hidden: true,
 	}
 }
 
 var _ Widget = &MenuLayer{}
 
+// Shown reports whether the menu is shown.
+func (m *MenuLayer) Shown() bool {
+	return !m.hidden
+}
+
+// Show shows or hides the menu. A menu that is shown slides in from
+// above, unless the widgets are [Still].
+func (m *MenuLayer) Show(show bool) {
+	if show == !m.hidden {
+		return
+	}
+	m.hidden = !show
+	m.anim = xgal.Timeline{}
+	if m.Motion == nil {
+		return
+	}
+	*m.Motion = *xgal.NewMotion()
+	if show && !Still {
+		m.anim.Play(m.Motion.SlideIn(xgal.Pt(0, -MenuSlide), xgal.MotionTicks))
+	}
+}
+
 func (m *MenuLayer) Poll() Reply {
+	m.anim.Tick()
 	if m.hidden {
 		return Ignore
 	}
@@ -195,6 +226,10 @@ func (m *MenuLayer) Render(s *xgal.Surface) {
 	if m.hidden {
 		return
 	}
+	m.Motion.Draw(s, m.Bounds, m.render)
+}
+
+func (m *MenuLayer) render(s *xgal.Surface) {
 	m.Style.DrawBox(s, m.Bounds)
 	for i := len(m.Kids) - 1; i >= 0; i-- {
 		m.Kids[i].Render(s)
@@ -239,7 +274,7 @@ func (m *MenuLayer) ItemHeight() int {
 		}
 	}
 	if m.itemH == 0 {
-		m.itemH = CaptionHeight
+		m.itemH = xgal.UILength(CaptionHeight)
 	}
 	return m.itemH
 }
@@ -253,7 +288,7 @@ func (m *MenuLayer) AddItem(text string, click func()) *MenuItemLayer {
 			bounds.Max.X, last.Bounds.Max.Y+bounds.Dy())
 	}
 	item := MenuItem(bounds, text, click)
-	item.Style = DefaultStyle()
+	item.Style = NamedStyle("menu")
 	item.leaf = true
 	m.Kids = append(m.Kids, item)
 	return item
@@ -269,7 +304,7 @@ func (m *MenuLayer) FitItem(text string, click func()) *MenuItemLayer {
 	}
 	bounds := xgal.Rect(m.Bounds.Min.X, y, m.Bounds.Max.X, y+sz.Y+m.Style.Margin.Y*2)
 	item := MenuItem(bounds, text, click)
-	item.Style = DefaultStyle()
+	item.Style = NamedStyle("menu")
 	item.leaf = true
 	m.Kids = append(m.Kids, item)
 	return item
@@ -283,7 +318,7 @@ func (p *PaneLayer) AddMenuBar() *MenuBarLayer {
 	if p.Caption != nil {
 		y = p.Caption.Bounds.Max.Y
 	}
-	bounds := xgal.Rect(p.Bounds.Min.X, y, p.Bounds.Max.X, y+CaptionHeight)
+	bounds := xgal.Rect(p.Bounds.Min.X, y, p.Bounds.Max.X, y+xgal.UILength(CaptionHeight))
 	mb := MenuBar(bounds)
 	p.Add(mb)
 	return mb
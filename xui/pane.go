@@ -22,7 +22,7 @@ func NewCaption(bounds xgal.Rectangle, text string) *Caption {
 	c.Style.Margin.Y = 0
 	c.Text = text
 	c.Bounds = bounds
-	c.Bounds.Max.Y = bounds.Min.Y + CaptionHeight
+	c.Bounds.Max.Y = bounds.Min.Y + xgal.UILength(CaptionHeight)
 	c.Close = c.Bounds
 	c.Close.Min.X = c.Close.Max.X - CaptionCloseSize
 	c.Close = c.Close.Inset(CaptionCloseMargin)
@@ -129,7 +129,7 @@ func (p *PaneLayer) renderPopups(s *xgal.Surface) {
 	for _, kid := range p.Kids {
 		if mb, ok := kid.(*MenuBarLayer); ok {
 			for _, mkid := range mb.Kids {
-				if mi, ok := mkid.(*MenuItemLayer); ok && mi.Submenu != nil && !mi.Submenu.hidden {
+				if mi, ok := mkid.(*MenuItemLayer); ok && mi.Submenu != nil && mi.Submenu.Shown() {
 					mi.Submenu.Render(s)
 				}
 			}
@@ -147,7 +147,7 @@ func (p *PaneLayer) Place(bounds xgal.Rectangle) xgal.Rectangle {
 	pos := p.Bounds.Min
 	cy := pos.Y
 	if p.Caption != nil {
-		cy = pos.Y + CaptionHeight
+		cy = pos.Y + xgal.UILength(CaptionHeight)
 	}
 	cb := xgal.Rect(pos.X, cy, bounds.Max.X, bounds.Max.Y)
 	r := p.Layer.Place(cb)
@@ -166,7 +166,7 @@ func (p *PaneLayer) Place(bounds xgal.Rectangle) xgal.Rectangle {
 	}
 
 	if p.Caption != nil {
-		p.Caption.Bounds = xgal.Rect(pos.X, pos.Y, pos.X+totalW, pos.Y+CaptionHeight)
+		p.Caption.Bounds = xgal.Rect(pos.X, pos.Y, pos.X+totalW, pos.Y+xgal.UILength(CaptionHeight))
 		p.Caption.Close = p.Caption.Bounds
 		p.Caption.Close.Min.X = p.Caption.Close.Max.X - CaptionCloseSize
 		p.Caption.Close = p.Caption.Close.Inset(CaptionCloseMargin)
@@ -32,11 +32,13 @@ type RingLayer struct {
 	Confirm func() bool
 	Cancel  func() bool
 
-	open    bool
-	angle   float64    // open animation t going from 0 to one
-	spin    int        // exact rotation step
-	spinOff float64    // fractional offset for smooth animation
-	sub     *RingLayer // active sub-ring, if any
+	open     bool
+	angle    float64        // open animation t going from 0 to one
+	spin     int            // exact rotation step
+	spinOff  float64        // fractional offset for smooth animation
+	spinning xgal.Animation // tween of spinOff back to 0
+	anim     xgal.Timeline  // plays the opening and the spin
+	sub      *RingLayer     // active sub-ring, if any
 }
 
 // Ring creates a ring menu. Set Left/Right/Confirm/Cancel fields on
@@ -65,21 +67,24 @@ func (r *RingLayer) Poll() Reply {
 
 	// animate opening
 	if !r.open {
-		r.angle += 0.15
-		if r.angle >= 1 {
-			r.angle = 1
-			r.open = true
+		if Still {
+			r.angle, r.open = 1, true
+			r.speak()
+		} else if !r.anim.Busy() {
+			r.anim.Play(xgal.NewSequence(
+				xgal.NewTween(0, 1, xgal.MotionTicks, xgal.EaseOutBack, func(v float64) { r.angle = v }),
+				xgal.Call(func() {
+					r.open = true
+					r.speak()
+				}),
+			))
 		}
+		r.anim.Tick()
 		return Accept
 	}
 
-	// decay spin offset toward 0
-	if r.spinOff != 0 {
-		r.spinOff *= 0.8
-		if math.Abs(r.spinOff) < 0.001 {
-			r.spinOff = 0
-		}
-	}
+	// ease the spin offset back to 0
+	r.anim.Tick()
 
 	if input(r.Cancel, DefaultInput.Cancel) {
 		return Finish
@@ -93,12 +98,14 @@ func (r *RingLayer) Poll() Reply {
 	if input(r.Left, DefaultInput.Left) {
 		r.Sel = (r.Sel - 1 + n) % n
 		r.spin++
-		r.spinOff = -1
+		r.rotate(-1)
+		r.speak()
 	}
 	if input(r.Right, DefaultInput.Right) {
 		r.Sel = (r.Sel + 1) % n
 		r.spin--
-		r.spinOff = 1
+		r.rotate(1)
+		r.speak()
 	}
 
 	if input(r.Confirm, DefaultInput.Confirm) {
@@ -113,6 +120,8 @@ func (r *RingLayer) Poll() Reply {
 				sub.angle = 0
 				sub.spin = 0
 				sub.spinOff = 0
+				sub.spinning = nil
+				sub.anim = xgal.Timeline{}
 				sub.Sel = 0
 				r.sub = sub
 				return Accept
@@ -128,6 +137,27 @@ func (r *RingLayer) Poll() Reply {
 	return Accept
 }
 
+// speak announces the item under the cursor.
+func (r *RingLayer) speak() {
+	if r.Sel >= 0 && r.Sel < len(r.Items) {
+		announce(xgal.CueFocus, r.Items[r.Sel].Label, "")
+	}
+}
+
+// rotate starts the spin of the ring by one item from the offset, which
+// is -1 or 1, back to where it is.
+func (r *RingLayer) rotate(from float64) {
+	if r.spinning != nil {
+		r.anim.Stop(r.spinning)
+	}
+	if Still {
+		r.spinOff, r.spinning = 0, nil
+		return
+	}
+	r.spinOff = from
+	r.spinning = r.anim.Play(xgal.NewTween(from, 0, xgal.MotionTicks, xgal.EaseOutCubic, func(v float64) { r.spinOff = v }))
+}
+
 func (r *RingLayer) Render(s *xgal.Surface) {
 	n := len(r.Items)
 	if n == 0 {
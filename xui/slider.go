@@ -1,7 +1,12 @@
 package xui
 
-import "github.com/xmasengine/xmas/xgal"
+import (
+	"strconv"
 
+	"github.com/xmasengine/xmas/xgal"
+)
+
+// knobSize is the size of the knob of a slider, before it is scaled.
 const knobSize = 8
 
 // SliderLayer is a draggable slider for selecting a value in a range.
@@ -35,7 +40,11 @@ func Slider(bounds xgal.Rectangle, onSlide func(int)) *SliderLayer {
 var _ Widget = &SliderLayer{}
 
 func (s *SliderLayer) Poll() Reply {
-	s.hover = xgal.Cursor().In(s.Bounds)
+	hover := xgal.Cursor().In(s.Bounds)
+	if hover && !s.hover {
+		announce(xgal.CueFocus, "", strconv.Itoa(s.Pos))
+	}
+	s.hover = hover
 
 	if s.hover && xgal.Click(xgal.MouseButtonLeft) {
 		s.dragging = true
@@ -58,10 +67,7 @@ func (s *SliderLayer) Poll() Reply {
 		if s.horizontal {
 			delta = int(wx)
 		}
-		s.Pos = clamp(s.Pos-delta, s.Low, s.High)
-		if s.OnSlide != nil {
-			s.OnSlide(s.Pos)
-		}
+		s.slide(clamp(s.Pos-delta, s.Low, s.High))
 		return Accept
 	}
 
@@ -78,7 +84,16 @@ func (s *SliderLayer) slideTo(mouse xgal.Point) {
 		mousePos = mouse.Y - s.Bounds.Min.Y - s.Style.Margin.Y
 	}
 	p := mousePos * (s.High - s.Low) / track
-	s.Pos = clamp(p, s.Low, s.High)
+	s.slide(clamp(p, s.Low, s.High))
+}
+
+// slide sets the position of the slider, which is announced if it
+// changed.
+func (s *SliderLayer) slide(pos int) {
+	if pos != s.Pos {
+		announce(xgal.CueValue, "", strconv.Itoa(pos))
+	}
+	s.Pos = pos
 	if s.OnSlide != nil {
 		s.OnSlide(s.Pos)
 	}
@@ -95,9 +110,9 @@ func (s *SliderLayer) knobPos() int {
 
 func (s *SliderLayer) trackSize() int {
 	if s.horizontal {
-		return s.Bounds.Dx() - 2*s.Style.Margin.X - knobSize
+		return s.Bounds.Dx() - 2*s.Style.Margin.X - xgal.UILength(knobSize)
 	}
-	return s.Bounds.Dy() - 2*s.Style.Margin.Y - knobSize
+	return s.Bounds.Dy() - 2*s.Style.Margin.Y - xgal.UILength(knobSize)
 }
 
 func (s *SliderLayer) Render(dst *xgal.Surface) {
@@ -117,12 +132,13 @@ func (s *SliderLayer) Render(dst *xgal.Surface) {
 	}
 
 	kp := s.knobPos()
+	knob := xgal.UILength(knobSize)
 	var kbox xgal.Rectangle
 	if s.horizontal {
 		kbox = xgal.Rect(
 			s.Bounds.Min.X+s.Style.Margin.X+kp,
 			s.Bounds.Min.Y,
-			s.Bounds.Min.X+s.Style.Margin.X+kp+knobSize,
+			s.Bounds.Min.X+s.Style.Margin.X+kp+knob,
 			s.Bounds.Max.Y,
 		)
 	} else {
@@ -130,7 +146,7 @@ func (s *SliderLayer) Render(dst *xgal.Surface) {
 			s.Bounds.Min.X,
 			s.Bounds.Min.Y+s.Style.Margin.Y+kp,
 			s.Bounds.Max.X,
-			s.Bounds.Min.Y+s.Style.Margin.Y+kp+knobSize,
+			s.Bounds.Min.Y+s.Style.Margin.Y+kp+knob,
 		)
 	}
 
@@ -146,13 +162,13 @@ func (s *SliderLayer) Place(bounds xgal.Rectangle) xgal.Rectangle {
 		if nw > maxWidth {
 			nw = maxWidth
 		}
-		nh := knobSize + s.Style.Margin.Y*2
+		nh := xgal.UILength(knobSize) + s.Style.Margin.Y*2
 		s.Bounds = xgal.Rect(bounds.Min.X, bounds.Min.Y, bounds.Min.X+nw, bounds.Min.Y+nh)
 		return s.Bounds
 	}
 	// vertical
 	const minHeight = 40
-	nw := knobSize + s.Style.Margin.X*2
+	nw := xgal.UILength(knobSize) + s.Style.Margin.X*2
 	nh := bounds.Dy() - s.Style.Margin.Y*2
 	if nh < minHeight {
 		nh = minHeight
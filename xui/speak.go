@@ -0,0 +1,16 @@
+package xui
+
+import "github.com/xmasengine/xmas/xgal"
+
+// announce tells the speaker of [xgal.Announce] about a widget.
+func announce(cue xgal.Cue, label, value string) {
+	xgal.Announce(xgal.Announcement{Cue: cue, Label: label, Value: value})
+}
+
+// sayChecked returns what is said of a widget that is checked or not.
+func sayChecked(checked bool, on, off string) string {
+	if checked {
+		return on
+	}
+	return off
+}
@@ -0,0 +1,56 @@
+package xui
+
+import (
+	"testing"
+
+	"github.com/xmasengine/xmas/xgal"
+)
+
+func expectHeard(t *testing.T, heard *xgal.Transcript, want ...string) {
+	t.Helper()
+	got := heard.Texts()
+	if len(got) != len(want) {
+		t.Fatalf("heard %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("heard %q, want %q", got[i], want[i])
+		}
+	}
+}
+
+func TestSpeakTalk(t *testing.T) {
+	heard := xgal.Listen(t)
+	next := false
+	talk := Talk(xgal.Rect(0, 0, 200, 50), nil, []string{"Ho ho ho!", "Merry Christmas"}, func() bool { return next })
+	talk.reveal = len("Ho ho ho!")
+	talk.Poll()
+	next = true
+	talk.Poll()
+	talk.reveal = len("Merry Christmas")
+	if talk.Poll() != Finish {
+		t.Errorf("talk does not finish")
+	}
+	expectHeard(t, heard, "line Ho ho ho!", "line Merry Christmas")
+}
+
+func TestSpeakAsk(t *testing.T) {
+	heard := xgal.Listen(t)
+	Ask(xgal.Rect(0, 0, 200, 80), "Quit?", "Yes", "No")
+	AskEntry(xgal.Rect(0, 0, 200, 80), "Name", "Santa", nil)
+	expectHeard(t, heard, "focus Quit?", "focus Name: Santa")
+}
+
+func TestSpeakRingAndSlider(t *testing.T) {
+	heard := xgal.Listen(t)
+	r := Ring(100, 100, 40, []RingItem{{Label: "Sled"}, {Label: "Sack"}})
+	r.speak()
+	r.Sel = 1
+	r.speak()
+
+	s := Slider(xgal.Rect(0, 0, 100, 10), nil)
+	s.slide(4)
+	s.slide(4)
+	s.slide(5)
+	expectHeard(t, heard, "focus Sled", "focus Sack", "value 4", "value 5")
+}
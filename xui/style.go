@@ -1,7 +1,12 @@
 package xui
 
+import "cmp"
+
 import "github.com/xmasengine/xmas/xgal"
 
+// DefaultSlice is the inset of the nine slices of a frame without Slice.
+const DefaultSlice = 5
+
 func (s Style) MeasureText(txt string) xgal.Point {
 	w, h := xgal.Measure(txt, s.Face, float64(xgal.Stride(s.Face)))
 	return xgal.Pt(int(w), int(h))
@@ -30,6 +35,9 @@ type Style struct {
 	Stroke int
 	Margin xgal.Point
 	Face   xgal.Face
+	Frame  *xgal.Surface // Frame is a nine-slice frame drawn over the fill, if any.
+	Slice  int           // Slice is the inset of the nine slices of the frame.
+	Name   string        // Name of the style in the theme.
 }
 
 func DefaultStyle() Style {
@@ -38,10 +46,19 @@ func DefaultStyle() Style {
 	s.Border = xgal.RGBA{R: 0x55, G: 0x55, B: 0x55, A: 0xff}
 	s.Shadow = xgal.RGBA{R: 0x00, G: 0x00, B: 0x00, A: 0xaa}
 	s.Fill = xgal.RGBA{R: 0x00, G: 0x00, B: 0x55, A: 0xaa}
-	s.Stroke = 1
-	s.Margin = xgal.Pt(2, 2)
-	s.Face = xgal.BuiltinFace
-	return s
+	s.Stroke = xgal.UILength(1)
+	s.Margin = xgal.UIPoint(xgal.Pt(2, 2))
+	s.Face = xgal.ScaleFace(xgal.BuiltinFace, xgal.UIScale)
+	s.Name = "default"
+	return s.themed("")
+}
+
+// NamedStyle returns the default style under another name, with the
+// look of that name in the theme, such as button, menu or hud.
+func NamedStyle(name string) Style {
+	s := DefaultStyle()
+	s.Name = name
+	return s.themed("")
 }
 
 func (s Style) DrawRect(dst *xgal.Surface, r xgal.Rectangle) {
@@ -58,6 +75,11 @@ func (s Style) DrawBox(dst *xgal.Surface, r xgal.Rectangle) {
 
 	xgal.Box(dst, r, s.Fill)
 
+	if s.Frame != nil {
+		slice := cmp.Or(s.Slice, DefaultSlice)
+		xgal.NineSlice(dst, s.Frame, float32(r.Min.X), float32(r.Min.Y), r.Dx(), r.Dy(), slice, slice, slice, slice)
+	}
+
 	if s.Stroke > 0 {
 		xgal.Outline(dst, r, s.Stroke, s.Border)
 	}
@@ -108,71 +130,79 @@ func FocusStyle() Style {
 	s := DefaultStyle()
 	s.Border = xgal.Wash(240, 140, 40, 245)
 	s.Fill = xgal.Wash(128, 128, 200, 240)
-	return s
+	return s.themed("focused")
 }
 
 func HoverStyle() Style {
 	s := DefaultStyle()
 	s.Border = xgal.Wash(240, 240, 50, 250)
-	return s
+	return s.themed("hovered")
 }
 
 func PressStyle() Style {
 	s := DefaultStyle()
 	s.Fill = xgal.Wash(15, 45, 200, 240)
-	return s
+	return s.themed("clicked")
 }
 
 func BarStyle() Style {
 	s := DefaultStyle()
 	s.Fill = xgal.Wash(45, 45, 200, 250)
-	return s
+	s.Name = "bar"
+	return s.themed("")
 }
 
 func CheckStyle() Style {
 	s := DefaultStyle()
 	s.Fill = xgal.Wash(245, 245, 245, 250)
-	return s
+	s.Name = "check"
+	return s.themed("")
 }
 
 func (s Style) HoverStyle() Style {
 	s.Border = xgal.Wash(200, 200, 45, 250)
-	return s
+	return s.themed("hovered")
 }
 
 func (s Style) FocusStyle() Style {
 	s.Border = xgal.Wash(240, 140, 40, 245)
 	s.Fill = xgal.Wash(128, 128, 200, 245)
-	return s
+	return s.themed("focused")
 }
 
 func (s Style) PressStyle() Style {
 	s.Fill = xgal.Wash(15, 45, 200, 240)
-	return s
+	return s.themed("clicked")
 }
 
 func (s Style) DragStyle() Style {
 	s.Fill = xgal.Wash(15, 128, 200, 240)
-	return s
+	return s.themed("dragged")
+}
+
+// ErrorStyle is the style of widgets with invalid input.
+func (s Style) ErrorStyle() Style {
+	s.Fill = xgal.Wash(200, 128, 128, 240)
+	return s.part("error")
 }
 
 func (s Style) BarStyle() Style {
 	s.Fill = xgal.Wash(45, 45, 245, 250)
-	return s
+	return s.part("bar")
 }
 
 func (s Style) CheckStyle() Style {
 	s.Fill = xgal.Wash(245, 245, 245, 250)
-	return s
+	return s.part("check")
 }
 
 func (s Style) ActiveStyle() Style {
 	s.Fill = xgal.Wash(60, 60, 140, 255)
 	s.Border = xgal.Wash(120, 120, 220, 255)
-	return s
+	return s.themed("active")
 }
 
 func (s Style) KnobStyle() Style {
 	s.Fill = xgal.Wash(245, 245, 245, 250)
-	return s
+	return s.part("knob")
 }
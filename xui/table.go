@@ -0,0 +1,331 @@
+package xui
+
+import (
+	"cmp"
+	"slices"
+	"strconv"
+
+	"github.com/xmasengine/xmas/xgal"
+)
+
+const (
+	TableColumnW    = 60 // TableColumnW is the unscaled width of new columns.
+	TableMinColumnW = 16 // TableMinColumnW is the unscaled narrowest a column can be resized to.
+	tableGrip       = 3  // tableGrip is how near the edge of a column a drag resizes it.
+)
+
+// TableColumn is a column of a [TableLayer].
+type TableColumn struct {
+	Title string
+	Width int
+	// Compare compares two cells of the column for sorting, if set.
+	// Otherwise cells that are both numbers are compared as numbers, and
+	// other cells as text.
+	Compare func(a, b string) int
+}
+
+// compareCells compares cells as numbers if they both are, or as text.
+func compareCells(a, b string) int {
+	fa, erra := strconv.ParseFloat(a, 64)
+	fb, errb := strconv.ParseFloat(b, 64)
+	if erra == nil && errb == nil {
+		return cmp.Compare(fa, fb)
+	}
+	return cmp.Compare(a, b)
+}
+
+// TableLayer is a table of text cells with a header row. Clicking the
+// title of a column sorts the table by it, clicking it again reverses the
+// order. Dragging the edge of a title resizes the column. The rows are
+// virtual: Cell is only called for the rows that are shown, and when
+// sorting, so tables can have many rows.
+type TableLayer struct {
+	Layer
+	Columns   []TableColumn
+	Rows      int                          // Rows is the amount of rows.
+	Cell      func(row, column int) string // Cell returns the text of a cell.
+	Selected  int                          // Selected is the selected row, -1 for none.
+	OnSelect  func(row int)
+	RowHeight int
+	Limit     int // max visible rows, 0 = show all
+	Offset    int // first visible row in sorted order
+
+	SortColumn int  // SortColumn is the column sorted by, -1 for none.
+	Descending bool // Descending sorts from high to low.
+
+	order    []int // order are the rows in sorted order.
+	resizing int   // resizing is the column that is being resized, or -1.
+	hover    int
+	scale    int // scale is the UI scale that the column widths are for.
+}
+
+// Table returns a new [TableLayer] with columns of the given titles, that
+// shows rows with cell.
+func Table(bounds xgal.Rectangle, rows int, cell func(row, column int) string, titles ...string) *TableLayer {
+	t := &TableLayer{
+		Rows:       rows,
+		Cell:       cell,
+		Selected:   -1,
+		RowHeight:  xgal.UILength(ListItemHeight),
+		SortColumn: -1,
+		resizing:   -1,
+		hover:      -1,
+		scale:      xgal.UIScale,
+	}
+	for _, title := range titles {
+		t.Columns = append(t.Columns, TableColumn{Title: title, Width: xgal.UILength(TableColumnW)})
+	}
+	t.Layer = MakeLayer(bounds)
+	return t
+}
+
+var _ Widget = &TableLayer{}
+
+// AddTable is a helper to add a [TableLayer] to a [Layer].
+func (m *Layer) AddTable(bounds xgal.Rectangle, rows int, cell func(row, column int) string, titles ...string) *TableLayer {
+	t := Table(bounds, rows, cell, titles...)
+	m.Add(t)
+	return t
+}
+
+// Refresh sorts the rows again, for example after the cells changed.
+func (t *TableLayer) Refresh() {
+	t.order = t.order[:0]
+	for row := range t.Rows {
+		t.order = append(t.order, row)
+	}
+	if t.SortColumn >= 0 && t.SortColumn < len(t.Columns) && t.Cell != nil {
+		col := t.SortColumn
+		compare := t.Columns[col].Compare
+		if compare == nil {
+			compare = compareCells
+		}
+		slices.SortStableFunc(t.order, func(a, b int) int {
+			res := compare(t.Cell(a, col), t.Cell(b, col))
+			if t.Descending {
+				return -res
+			}
+			return res
+		})
+	}
+	t.clampOffset()
+}
+
+// sync sorts the rows again if their amount changed.
+func (t *TableLayer) sync() {
+	if len(t.order) != t.Rows {
+		t.Refresh()
+	}
+}
+
+// Order returns the rows in the order they are shown.
+func (t *TableLayer) Order() []int {
+	t.sync()
+	return t.order
+}
+
+// Sort sorts the table by the column. If it is already sorted by it, the
+// order is reversed.
+func (t *TableLayer) Sort(column int) {
+	if column == t.SortColumn {
+		t.Descending = !t.Descending
+	} else {
+		t.SortColumn = column
+		t.Descending = false
+	}
+	t.Refresh()
+}
+
+// SelectRow selects the row, shows it and calls OnSelect.
+func (t *TableLayer) SelectRow(row int) {
+	if row < 0 || row >= t.Rows {
+		return
+	}
+	t.Selected = row
+	t.EnsureVisible()
+	if t.OnSelect != nil {
+		t.OnSelect(row)
+	}
+}
+
+// EnsureVisible scrolls the table so the selected row is in view.
+func (t *TableLayer) EnsureVisible() {
+	idx := slices.Index(t.Order(), t.Selected)
+	if idx < 0 || t.Limit <= 0 {
+		t.clampOffset()
+		return
+	}
+	if idx < t.Offset {
+		t.Offset = idx
+	}
+	if idx >= t.Offset+t.Limit {
+		t.Offset = idx - t.Limit + 1
+	}
+	t.clampOffset()
+}
+
+func (t *TableLayer) visibleCount() int {
+	if t.Limit > 0 && t.Rows > t.Limit {
+		return t.Limit
+	}
+	return t.Rows
+}
+
+func (t *TableLayer) clampOffset() {
+	t.Offset = clamp(t.Offset, 0, max(t.Rows-t.visibleCount(), 0))
+}
+
+// columnX returns the left edge of the column.
+func (t *TableLayer) columnX(column int) int {
+	x := t.Bounds.Min.X
+	for _, col := range t.Columns[:column] {
+		x += col.Width
+	}
+	return x
+}
+
+// columnAt returns the column at x, or -1, and whether x is on the grip
+// at the right edge of the column.
+func (t *TableLayer) columnAt(x int) (int, bool) {
+	left := t.Bounds.Min.X
+	for i, col := range t.Columns {
+		right := left + col.Width
+		if x >= right-tableGrip && x < right+tableGrip {
+			return i, true
+		}
+		if x >= left && x < right {
+			return i, false
+		}
+		left = right
+	}
+	return -1, false
+}
+
+// header returns the bounds of the header row.
+func (t *TableLayer) header() xgal.Rectangle {
+	return xgal.Rect(t.Bounds.Min.X, t.Bounds.Min.Y, t.Bounds.Max.X, t.Bounds.Min.Y+t.RowHeight)
+}
+
+// rowBounds returns the bounds of the i-th visible row.
+func (t *TableLayer) rowBounds(i int) xgal.Rectangle {
+	y := t.Bounds.Min.Y + (i+1)*t.RowHeight
+	return xgal.Rect(t.Bounds.Min.X, y, t.Bounds.Max.X, y+t.RowHeight)
+}
+
+func (t *TableLayer) Poll() Reply {
+	pos := xgal.Cursor()
+	t.hover = -1
+	t.sync()
+
+	if t.resizing >= 0 {
+		if xgal.Loose(xgal.MouseButtonLeft) {
+			t.resizing = -1
+		} else {
+			t.Columns[t.resizing].Width = max(pos.X-t.columnX(t.resizing), xgal.UILength(TableMinColumnW))
+		}
+		return Accept
+	}
+
+	if !pos.In(t.Bounds) {
+		return Ignore
+	}
+
+	if _, wy := xgal.Wheel(); wy != 0 && t.Limit > 0 {
+		t.Offset -= int(wy)
+		t.clampOffset()
+	}
+
+	if idx := slices.Index(t.order, t.Selected); idx >= 0 {
+		if xgal.Tap(xgal.KeyArrowDown) && idx < len(t.order)-1 {
+			t.SelectRow(t.order[idx+1])
+			return Accept
+		}
+		if xgal.Tap(xgal.KeyArrowUp) && idx > 0 {
+			t.SelectRow(t.order[idx-1])
+			return Accept
+		}
+	}
+
+	if pos.In(t.header()) {
+		if xgal.Click(xgal.MouseButtonLeft) {
+			col, grip := t.columnAt(pos.X)
+			if grip {
+				t.resizing = col
+			} else if col >= 0 {
+				t.Sort(col)
+			}
+		}
+		return Accept
+	}
+
+	vc := t.visibleCount()
+	for i := 0; i < vc; i++ {
+		if !pos.In(t.rowBounds(i)) {
+			continue
+		}
+		t.hover = t.order[t.Offset+i]
+		if xgal.Click(xgal.MouseButtonLeft) {
+			t.SelectRow(t.hover)
+		}
+		break
+	}
+	return Accept
+}
+
+// renderRow renders the cells of a row with the texts.
+func (t *TableLayer) renderRow(s *xgal.Surface, st Style, bounds xgal.Rectangle, text func(column int) string) {
+	st.DrawBox(s, bounds)
+	x := bounds.Min.X
+	for i, col := range t.Columns {
+		cell := xgal.Rect(x, bounds.Min.Y, x+col.Width, bounds.Max.Y).Intersect(bounds)
+		if !cell.Empty() {
+			st.Ink(s.SubImage(cell).(*xgal.Surface), cell, text(i))
+		}
+		x += col.Width
+		xgal.Line(s, x, bounds.Min.Y, x, bounds.Max.Y, 1, st.Border)
+	}
+}
+
+func (t *TableLayer) Render(s *xgal.Surface) {
+	t.Layer.Render(s)
+	t.sync()
+
+	t.renderRow(s, t.Style.BarStyle(), t.header(), func(column int) string {
+		title := t.Columns[column].Title
+		if column == t.SortColumn {
+			if t.Descending {
+				return title + " v"
+			}
+			return title + " ^"
+		}
+		return title
+	})
+
+	if t.Cell == nil {
+		return
+	}
+	vc := t.visibleCount()
+	for i := 0; i < vc; i++ {
+		row := t.order[t.Offset+i]
+		st := t.Style
+		if row == t.hover {
+			st = st.HoverStyle()
+		}
+		if row == t.Selected {
+			st = st.ActiveStyle()
+		}
+		t.renderRow(s, st, t.rowBounds(i), func(column int) string {
+			return t.Cell(row, column)
+		})
+	}
+}
+
+func (t *TableLayer) Place(bounds xgal.Rectangle) xgal.Rectangle {
+	nw := 0
+	for _, col := range t.Columns {
+		nw += col.Width
+	}
+	nh := (t.visibleCount() + 1) * t.RowHeight
+	t.Bounds = xgal.Rect(bounds.Min.X, bounds.Min.Y, bounds.Min.X+max(nw, minTotalW), bounds.Min.Y+nh)
+	return t.Bounds
+}
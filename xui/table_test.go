@@ -0,0 +1,56 @@
+package xui
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/xmasengine/xmas/xgal"
+)
+
+func TestTableSort(t *testing.T) {
+	cells := [][]string{
+		{"b", "10"},
+		{"a", "9"},
+		{"c", "100"},
+	}
+	table := Table(xgal.Rect(0, 0, 100, 100), len(cells), func(row, column int) string {
+		return cells[row][column]
+	}, "Name", "Count")
+
+	if order := table.Order(); !slices.Equal(order, []int{0, 1, 2}) {
+		t.Errorf("unsorted: %v", order)
+	}
+	table.Sort(0)
+	if order := table.Order(); !slices.Equal(order, []int{1, 0, 2}) {
+		t.Errorf("by name: %v", order)
+	}
+	// Numbers sort as numbers, and sorting again reverses.
+	table.Sort(1)
+	if order := table.Order(); !slices.Equal(order, []int{1, 0, 2}) {
+		t.Errorf("by count: %v", order)
+	}
+	table.Sort(1)
+	if order := table.Order(); !slices.Equal(order, []int{2, 0, 1}) {
+		t.Errorf("by count descending: %v", order)
+	}
+
+	cells = append(cells, []string{"d", "1000"})
+	table.Rows = len(cells)
+	if order := table.Order(); !slices.Equal(order, []int{3, 2, 0, 1}) {
+		t.Errorf("after adding a row: %v", order)
+	}
+
+	table.Limit = 2
+	table.SelectRow(1)
+	if table.Offset != 2 {
+		t.Errorf("offset %d after selecting the last row", table.Offset)
+	}
+
+	table.Place(xgal.Rect(0, 0, 200, 200))
+	if col, grip := table.columnAt(TableColumnW + 1); col != 0 || !grip {
+		t.Errorf("grip at %d, %v", col, grip)
+	}
+	if col, grip := table.columnAt(TableColumnW + 10); col != 1 || grip {
+		t.Errorf("column at %d, %v", col, grip)
+	}
+}
@@ -28,7 +28,11 @@ const talkSpeed = 30 * time.Millisecond
 
 // Talk creates a talk dialog.  advance is called each frame
 // and should return true when the player presses confirm (A/Enter/etc).
+// Each message is announced to the speaker as a line when it is shown.
 func Talk(bounds xgal.Rectangle, portrait *xgal.Surface, messages []string, advance func() bool) *TalkLayer {
+	if len(messages) > 0 {
+		announce(xgal.CueLine, "", messages[0])
+	}
 	return &TalkLayer{
 		Bounds:    bounds,
 		Style:     DefaultStyle(),
@@ -66,6 +70,7 @@ func (t *TalkLayer) Poll() Reply {
 			t.done = true
 			return Finish
 		}
+		announce(xgal.CueLine, "", t.messages[t.msgIdx])
 	}
 	return Accept
 }
@@ -0,0 +1,221 @@
+package xui
+
+import "github.com/xmasengine/xmas/xdat"
+import "github.com/xmasengine/xmas/xgal"
+
+// theme is the theme that styles apply, or nil for the built in looks.
+var theme *xdat.Theme
+
+// Theme returns the current theme, or nil if there is none.
+func Theme() *xdat.Theme {
+	return theme
+}
+
+// SetTheme makes new styles use the looks of the theme, or the built in
+// looks if it is nil, and restyles the roots with [Restyle] so a theme can
+// be switched while the widgets are shown. Other widgets keep their looks
+// until they are restyled.
+func SetTheme(t *xdat.Theme, roots ...Widget) {
+	theme = t
+	restyleKids(roots)
+}
+
+// SetScale sets the scale of the user interfaces, [xgal.UIScale], which
+// new styles and sizes use, and restyles the roots like [SetTheme].
+func SetScale(scale int, roots ...Widget) {
+	xgal.UIScale = max(scale, 1)
+	restyleKids(roots)
+}
+
+// Apply returns the style with the attributes that are set in the look.
+// The look attributes that this toolkit does not draw are ignored. The
+// lengths and the font of the look are scaled by [xgal.UIScale].
+func (s Style) Apply(look xdat.Look) Style {
+	if look.Fore != nil {
+		s.Fore = look.Fore.RGBA()
+	}
+	if look.Border != nil {
+		s.Border = look.Border.RGBA()
+	}
+	if look.Shadow != nil {
+		s.Shadow = look.Shadow.RGBA()
+	}
+	if look.Fill != nil {
+		s.Fill = look.Fill.RGBA()
+	}
+	if look.Stroke != nil {
+		s.Stroke = xgal.UILength(*look.Stroke)
+	}
+	if look.Margin != nil {
+		s.Margin = xgal.UIPoint(look.Margin.Point())
+	}
+	if look.Face != nil {
+		s.Face = xgal.ScaleFace(look.Face, xgal.UIScale)
+	}
+	if look.Frame != "" {
+		s.Frame = look.Texture
+	}
+	if look.Slice != nil {
+		s.Slice = *look.Slice
+	}
+	return s
+}
+
+// themed returns the style with the look of its name in the state from
+// the theme applied, or the plain look if state is empty.
+func (s Style) themed(state string) Style {
+	if theme == nil || s.Name == "" {
+		return s
+	}
+	return s.Apply(theme.Look(s.Name, state))
+}
+
+// part returns the style with the plain look of the named style of the
+// theme applied, for parts of widgets such as the knob of a slider.
+func (s Style) part(name string) Style {
+	if theme == nil {
+		return s
+	}
+	return s.Apply(theme.Look(name, ""))
+}
+
+// Restyle returns the style with the same name made anew, for example
+// after the theme changed. Styles without a name are kept.
+func (s Style) Restyle() Style {
+	if s.Name == "" {
+		return s
+	}
+	return NamedStyle(s.Name)
+}
+
+// Restyler is an optional interface for widgets with a style.
+type Restyler interface {
+	// Restyle should make the styles of the widget anew.
+	Restyle()
+}
+
+// Restyle restyles the widget if it has a style, for example after
+// [SetTheme]. Since widgets are placed every frame, new sizes are laid
+// out on the next frame.
+func Restyle(w Widget) {
+	if r, ok := w.(Restyler); ok {
+		r.Restyle()
+	}
+}
+
+// restyleKids restyles the kids that are restylers.
+func restyleKids(kids []Widget) {
+	for _, kid := range kids {
+		Restyle(kid)
+	}
+}
+
+// Restyle restyles the layer and its kids.
+func (m *Layer) Restyle() {
+	m.Style = m.Style.Restyle()
+	restyleKids(m.Kids)
+}
+
+// Restyle restyles the list and scales the height of its items.
+func (l *ListLayer) Restyle() {
+	l.Layer.Restyle()
+	l.ItemHeight = xgal.UILength(ListItemHeight)
+}
+
+// Restyle restyles the tree and scales the height of its items.
+func (t *TreeLayer) Restyle() {
+	t.Layer.Restyle()
+	t.ItemHeight = xgal.UILength(ListItemHeight)
+}
+
+// Restyle restyles the table and scales its rows and columns.
+func (t *TableLayer) Restyle() {
+	t.Layer.Restyle()
+	t.RowHeight = xgal.UILength(ListItemHeight)
+	scale := max(xgal.UIScale, 1)
+	for i := range t.Columns {
+		t.Columns[i].Width = t.Columns[i].Width * scale / max(t.scale, 1)
+	}
+	t.scale = scale
+}
+
+func (m *MenuLayer) Restyle() {
+	m.Style = m.Style.Restyle()
+	restyleKids(m.Kids)
+}
+
+func (p *PaneLayer) Restyle() {
+	p.Layer.Restyle()
+	p.Caption.Style = p.Caption.Style.Restyle()
+	p.Caption.Style.Margin.Y = 0
+}
+
+func (a *AskLayer) Restyle() {
+	a.Style = a.Style.Restyle()
+}
+
+func (b *ButtonLayer) Restyle() {
+	b.Style = b.Style.Restyle()
+}
+
+func (c *CheckboxLayer) Restyle() {
+	c.Style = c.Style.Restyle()
+}
+
+func (e *EntryLayer) Restyle() {
+	e.Style = e.Style.Restyle()
+}
+
+func (f *FileDialogLayer) Restyle() {
+	f.Style = f.Style.Restyle()
+	f.Entry.Restyle()
+}
+
+func (h *HUDLayer) Restyle() {
+	h.Style = h.Style.Restyle()
+}
+
+func (l *LabelLayer) Restyle() {
+	l.Style = l.Style.Restyle()
+}
+
+func (i *MenuItemLayer) Restyle() {
+	i.Style = i.Style.Restyle()
+	if i.Submenu != nil {
+		i.Submenu.Restyle()
+	}
+}
+
+func (r *RingLayer) Restyle() {
+	r.Style = r.Style.Restyle()
+}
+
+func (s *ScreenLayer) Restyle() {
+	s.Style = s.Style.Restyle()
+}
+
+func (s *SliderLayer) Restyle() {
+	s.Style = s.Style.Restyle()
+}
+
+func (t *TalkLayer) Restyle() {
+	t.Style = t.Style.Restyle()
+}
+
+func (t *ToggleLayer) Restyle() {
+	t.Style = t.Style.Restyle()
+}
+
+func (tg *ToggleGroupLayer) Restyle() {
+	for _, t := range tg.Toggles {
+		t.Restyle()
+	}
+}
+
+func (t *TooltipLayer) Restyle() {
+	t.Style = t.Style.Restyle()
+}
+
+func (z *PopupLayer) Restyle() {
+	z.Style = z.Style.Restyle()
+}
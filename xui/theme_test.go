@@ -0,0 +1,69 @@
+package xui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/xmasengine/xmas/xdat"
+	"github.com/xmasengine/xmas/xgal"
+)
+
+const testTheme = `<theme name="test">
+ <style name="default" fill="#102030"/>
+ <style name="button" fill="#405060"/>
+</theme>`
+
+func TestSetTheme(t *testing.T) {
+	th, err := xdat.ReadTheme(strings.NewReader(testTheme))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer SetTheme(nil)
+
+	group := NewToggleGroup(Toggle(xgal.Rect(0, 0, 20, 10), "A", nil))
+	button := Button(xgal.Rect(0, 0, 20, 10), "OK", nil)
+	other := Button(xgal.Rect(0, 0, 20, 10), "Other", nil)
+
+	SetTheme(th, group, button)
+	if fill := group.Toggles[0].Style.Fill; fill != xgal.Paint(0x10, 0x20, 0x30, 0xff) {
+		t.Errorf("toggle: fill %v", fill)
+	}
+	if button.Style.Fill != xgal.Paint(0x40, 0x50, 0x60, 0xff) {
+		t.Errorf("button: fill %v", button.Style.Fill)
+	}
+	if other.Style.Fill == button.Style.Fill {
+		t.Errorf("button that is not a root restyled")
+	}
+
+	SetTheme(nil, button)
+	if button.Style != NamedStyle("button") {
+		t.Errorf("button not restored: %v", button.Style)
+	}
+}
+
+func TestSetScale(t *testing.T) {
+	defer SetScale(1)
+
+	list := List(xgal.Rect(0, 0, 100, 100))
+	table := Table(xgal.Rect(0, 0, 200, 100), 3, func(row, column int) string { return "" }, "Name", "Size")
+	table.Columns[1].Width = 30
+
+	SetScale(2, list, table)
+	if list.ItemHeight != ListItemHeight*2 {
+		t.Errorf("list: item height %d", list.ItemHeight)
+	}
+	if table.RowHeight != ListItemHeight*2 || table.Columns[0].Width != TableColumnW*2 || table.Columns[1].Width != 60 {
+		t.Errorf("table: row height %d, columns %v", table.RowHeight, table.Columns)
+	}
+	if dockTabHeight() != DockTabHeight*2 {
+		t.Errorf("dock tab height %d", dockTabHeight())
+	}
+	if tree := Tree(xgal.Rect(0, 0, 100, 100)); tree.ItemHeight != ListItemHeight*2 {
+		t.Errorf("new tree: item height %d", tree.ItemHeight)
+	}
+
+	SetScale(1, table)
+	if table.Columns[0].Width != TableColumnW || table.Columns[1].Width != 30 {
+		t.Errorf("table not scaled back: %v", table.Columns)
+	}
+}
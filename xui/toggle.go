@@ -8,6 +8,7 @@ type ToggleLayer struct {
 	Bounds  xgal.Rectangle
 	Style   Style
 	Text    string
+	Key     string // Key is the string table key of the text, if any.
 	Icon    Icon
 	Active  bool
 	Group   *int // shared selection index, nil for independent toggle
@@ -32,11 +33,15 @@ func Toggle(bounds xgal.Rectangle, text string, toggled func(active bool)) *Togg
 var _ Widget = &ToggleLayer{}
 
 func (t *ToggleLayer) Poll() Reply {
-	t.hover = xgal.Cursor().In(t.Bounds)
+	hover := xgal.Cursor().In(t.Bounds)
 	if t.Group != nil {
 		t.Active = (*t.Group == t.Idx)
 		t.lastAct = t.Active
 	}
+	if hover && !t.hover {
+		announce(xgal.CueFocus, t.Text, sayChecked(t.Active, "on", "off"))
+	}
+	t.hover = hover
 	if !t.hover {
 		return Ignore
 	}
@@ -54,8 +59,11 @@ func (t *ToggleLayer) Poll() Reply {
 		} else {
 			t.Active = !t.Active
 		}
-		if t.Toggled != nil && t.Active != t.lastAct {
-			t.Toggled(t.Active)
+		if t.Active != t.lastAct {
+			announce(xgal.CueValue, t.Text, sayChecked(t.Active, "on", "off"))
+			if t.Toggled != nil {
+				t.Toggled(t.Active)
+			}
 		}
 		t.lastAct = t.Active
 		return Accept
@@ -9,6 +9,7 @@ import "github.com/xmasengine/xmas/xgal"
 // Keep it in stored the parent's Kids.
 type TooltipLayer struct {
 	Text    string
+	Key     string // Key is the string table key of the text, if any.
 	Style   Style
 	Offset  xgal.Point // Offset from cursor, default (12, 12)
 	Bounds  xgal.Rectangle
@@ -0,0 +1,60 @@
+package xui
+
+// Translator is an optional interface for widgets with translatable text.
+type Translator interface {
+	// Translate should set the texts that have a key to tr of that key.
+	Translate(tr func(key string) string)
+}
+
+// translate sets text to tr of key if there is a key.
+func translate(key string, text *string, tr func(key string) string) {
+	if key != "" {
+		*text = tr(key)
+	}
+}
+
+// translateKids translates the kids that are translators.
+func translateKids(kids []Widget, tr func(key string) string) {
+	for _, kid := range kids {
+		if t, ok := kid.(Translator); ok {
+			t.Translate(tr)
+		}
+	}
+}
+
+// Translate translates the kids of the layer. Since widgets are placed
+// every frame, the new texts are laid out on the next frame.
+func (m *Layer) Translate(tr func(key string) string) {
+	translateKids(m.Kids, tr)
+}
+
+func (m *MenuLayer) Translate(tr func(key string) string) {
+	translateKids(m.Kids, tr)
+}
+
+func (b *ButtonLayer) Translate(tr func(key string) string) {
+	translate(b.Key, &b.Text, tr)
+}
+
+func (c *CheckboxLayer) Translate(tr func(key string) string) {
+	translate(c.Key, &c.Text, tr)
+}
+
+func (l *LabelLayer) Translate(tr func(key string) string) {
+	translate(l.Key, &l.Text, tr)
+}
+
+func (t *ToggleLayer) Translate(tr func(key string) string) {
+	translate(t.Key, &t.Text, tr)
+}
+
+func (t *TooltipLayer) Translate(tr func(key string) string) {
+	translate(t.Key, &t.Text, tr)
+}
+
+func (i *MenuItemLayer) Translate(tr func(key string) string) {
+	translate(i.Key, &i.Text, tr)
+	if i.Submenu != nil {
+		i.Submenu.Translate(tr)
+	}
+}
@@ -0,0 +1,287 @@
+package xui
+
+import "github.com/xmasengine/xmas/xgal"
+
+const TreeIndent = 10
+
+// TreeNode is a node of a [TreeLayer], with kids that are shown when it
+// is open.
+type TreeNode struct {
+	Text string
+	Data any // Data is what the node stands for, such as a layer of a zone.
+	Kids []*TreeNode
+	Open bool
+}
+
+// Add adds a kid to the node and returns it.
+func (n *TreeNode) Add(text string, data any) *TreeNode {
+	kid := &TreeNode{Text: text, Data: data}
+	n.Kids = append(n.Kids, kid)
+	return kid
+}
+
+// TreeRow is a node of a tree as it is shown, at its depth.
+type TreeRow struct {
+	Node   *TreeNode
+	Parent *TreeNode // Parent is nil for the roots.
+	Depth  int
+}
+
+// TreeLayer is a collapsible tree of text nodes, such as the layers, things
+// and talks of a zone. Clicking the marker in front of a node opens or
+// closes it, clicking its text selects it. With the cursor over the tree,
+// the up and down keys select, and left and right close and open.
+type TreeLayer struct {
+	Layer
+	Roots      []*TreeNode
+	Selected   *TreeNode // Selected is nil for none.
+	OnSelect   func(node *TreeNode)
+	ItemHeight int
+	Indent     int
+	Limit      int // max visible rows, 0 = show all
+	Offset     int // first visible row index
+	hover      *TreeNode
+}
+
+// Tree returns a new [TreeLayer].
+func Tree(bounds xgal.Rectangle) *TreeLayer {
+	t := &TreeLayer{ItemHeight: xgal.UILength(ListItemHeight), Indent: TreeIndent}
+	t.Layer = MakeLayer(bounds)
+	return t
+}
+
+var _ Widget = &TreeLayer{}
+
+// AddTree is a helper to add a [TreeLayer] to a [Layer].
+func (m *Layer) AddTree(bounds xgal.Rectangle) *TreeLayer {
+	t := Tree(bounds)
+	m.Add(t)
+	return t
+}
+
+// AddRoot adds a root node to the tree and returns it.
+func (t *TreeLayer) AddRoot(text string, data any) *TreeNode {
+	root := &TreeNode{Text: text, Data: data}
+	t.Roots = append(t.Roots, root)
+	return root
+}
+
+// Rows returns the rows that are shown, which are the roots and the kids
+// of the open nodes.
+func (t *TreeLayer) Rows() []TreeRow {
+	var rows []TreeRow
+	var walk func(nodes []*TreeNode, parent *TreeNode, depth int)
+	walk = func(nodes []*TreeNode, parent *TreeNode, depth int) {
+		for _, node := range nodes {
+			rows = append(rows, TreeRow{Node: node, Parent: parent, Depth: depth})
+			if node.Open {
+				walk(node.Kids, node, depth+1)
+			}
+		}
+	}
+	walk(t.Roots, nil, 0)
+	return rows
+}
+
+// rowIndex returns the index of the row of the node, or -1.
+func rowIndex(rows []TreeRow, node *TreeNode) int {
+	for i, row := range rows {
+		if row.Node == node {
+			return i
+		}
+	}
+	return -1
+}
+
+// Reveal opens the ancestors of the node so it is shown. It reports
+// whether the node is in the tree.
+func (t *TreeLayer) Reveal(node *TreeNode) bool {
+	var find func(nodes []*TreeNode) bool
+	find = func(nodes []*TreeNode) bool {
+		for _, n := range nodes {
+			if n == node {
+				return true
+			}
+			if find(n.Kids) {
+				n.Open = true
+				return true
+			}
+		}
+		return false
+	}
+	return find(t.Roots)
+}
+
+// SelectNode selects the node, shows it and calls OnSelect.
+func (t *TreeLayer) SelectNode(node *TreeNode) {
+	if node == nil || !t.Reveal(node) {
+		return
+	}
+	t.Selected = node
+	t.EnsureVisible()
+	if t.OnSelect != nil {
+		t.OnSelect(node)
+	}
+}
+
+// Toggle opens the node if it is closed, and closes it otherwise.
+func (t *TreeLayer) Toggle(node *TreeNode) {
+	node.Open = !node.Open && len(node.Kids) > 0
+	t.clampOffset(t.Rows())
+}
+
+// EnsureVisible scrolls the tree so the selected node is in view.
+func (t *TreeLayer) EnsureVisible() {
+	rows := t.Rows()
+	idx := rowIndex(rows, t.Selected)
+	if idx < 0 || t.Limit <= 0 {
+		t.clampOffset(rows)
+		return
+	}
+	if idx < t.Offset {
+		t.Offset = idx
+	}
+	if idx >= t.Offset+t.Limit {
+		t.Offset = idx - t.Limit + 1
+	}
+	t.clampOffset(rows)
+}
+
+func (t *TreeLayer) visibleCount(rows []TreeRow) int {
+	if t.Limit > 0 && len(rows) > t.Limit {
+		return t.Limit
+	}
+	return len(rows)
+}
+
+func (t *TreeLayer) clampOffset(rows []TreeRow) {
+	t.Offset = clamp(t.Offset, 0, max(len(rows)-t.visibleCount(rows), 0))
+}
+
+// rowBounds returns the bounds of the i-th visible row.
+func (t *TreeLayer) rowBounds(i int) xgal.Rectangle {
+	y := t.Bounds.Min.Y + i*t.ItemHeight
+	return xgal.Rect(t.Bounds.Min.X, y, t.Bounds.Max.X, y+t.ItemHeight)
+}
+
+// marker returns the box of the open and close marker of the row.
+func (t *TreeLayer) marker(row TreeRow, bounds xgal.Rectangle) xgal.Rectangle {
+	x := bounds.Min.X + row.Depth*t.Indent
+	return xgal.Rect(x, bounds.Min.Y, x+t.Indent, bounds.Max.Y)
+}
+
+// step handles the keys that move through the tree.
+func (t *TreeLayer) step(rows []TreeRow) bool {
+	idx := rowIndex(rows, t.Selected)
+	if idx < 0 {
+		return false
+	}
+	row := rows[idx]
+	switch {
+	case xgal.Tap(xgal.KeyArrowDown) && idx < len(rows)-1:
+		t.SelectNode(rows[idx+1].Node)
+	case xgal.Tap(xgal.KeyArrowUp) && idx > 0:
+		t.SelectNode(rows[idx-1].Node)
+	case xgal.Tap(xgal.KeyArrowRight) && len(row.Node.Kids) > 0:
+		if row.Node.Open {
+			t.SelectNode(row.Node.Kids[0])
+		} else {
+			t.Toggle(row.Node)
+		}
+	case xgal.Tap(xgal.KeyArrowLeft):
+		if row.Node.Open {
+			t.Toggle(row.Node)
+		} else if row.Parent != nil {
+			t.SelectNode(row.Parent)
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+func (t *TreeLayer) Poll() Reply {
+	pos := xgal.Cursor()
+	t.hover = nil
+	if !pos.In(t.Bounds) {
+		return Ignore
+	}
+
+	rows := t.Rows()
+	t.clampOffset(rows)
+	if _, wy := xgal.Wheel(); wy != 0 && t.Limit > 0 {
+		t.Offset -= int(wy)
+		t.clampOffset(rows)
+	}
+
+	if t.step(rows) {
+		return Accept
+	}
+
+	vc := t.visibleCount(rows)
+	for i := 0; i < vc; i++ {
+		row := rows[t.Offset+i]
+		bounds := t.rowBounds(i)
+		if !pos.In(bounds) {
+			continue
+		}
+		t.hover = row.Node
+		if xgal.Click(xgal.MouseButtonLeft) {
+			if pos.In(t.marker(row, bounds)) {
+				t.Toggle(row.Node)
+			} else {
+				t.SelectNode(row.Node)
+			}
+		}
+		break
+	}
+	return Accept
+}
+
+func (t *TreeLayer) Render(s *xgal.Surface) {
+	t.Layer.Render(s)
+
+	rows := t.Rows()
+	t.clampOffset(rows)
+	vc := t.visibleCount(rows)
+	for i := 0; i < vc; i++ {
+		row := rows[t.Offset+i]
+		bounds := t.rowBounds(i)
+
+		st := t.Style
+		if row.Node == t.hover {
+			st = st.HoverStyle()
+		}
+		if row.Node == t.Selected {
+			st = st.ActiveStyle()
+		}
+		st.DrawBox(s, bounds)
+
+		mark := t.marker(row, bounds)
+		if len(row.Node.Kids) > 0 {
+			c := xgal.Pt((mark.Min.X+mark.Max.X)/2, (mark.Min.Y+mark.Max.Y)/2)
+			if row.Node.Open {
+				xgal.Polyfill(s, st.Fore, c.X-3, c.Y-2, c.X+3, c.Y-2, c.X, c.Y+2)
+			} else {
+				xgal.Polyfill(s, st.Fore, c.X-2, c.Y-3, c.X-2, c.Y+3, c.X+2, c.Y)
+			}
+		}
+		text := bounds
+		text.Min.X = mark.Max.X
+		st.Ink(s, text, row.Node.Text)
+	}
+}
+
+func (t *TreeLayer) Place(bounds xgal.Rectangle) xgal.Rectangle {
+	rows := t.Rows()
+	nw := minTotalW
+	for _, row := range rows {
+		sz := t.Style.MeasureText(row.Node.Text)
+		if w := (row.Depth+1)*t.Indent + sz.X + t.Style.Margin.X*2; w > nw {
+			nw = w
+		}
+	}
+	nh := t.visibleCount(rows) * t.ItemHeight
+	t.Bounds = xgal.Rect(bounds.Min.X, bounds.Min.Y, bounds.Min.X+nw, bounds.Min.Y+nh)
+	return t.Bounds
+}
@@ -0,0 +1,39 @@
+package xui
+
+import (
+	"testing"
+
+	"github.com/xmasengine/xmas/xgal"
+)
+
+func TestTreeRows(t *testing.T) {
+	tree := Tree(xgal.Rect(0, 0, 100, 100))
+	zone := tree.AddRoot("zone", nil)
+	layer := zone.Add("layer", nil)
+	thing := layer.Add("thing", nil)
+	thing.Add("talk", nil)
+	tree.AddRoot("other", nil)
+
+	if rows := tree.Rows(); len(rows) != 2 {
+		t.Fatalf("closed tree has %d rows", len(rows))
+	}
+	var selected *TreeNode
+	tree.OnSelect = func(node *TreeNode) { selected = node }
+	tree.SelectNode(thing)
+	if selected != thing || !zone.Open || !layer.Open || thing.Open {
+		t.Errorf("select did not reveal the node")
+	}
+	rows := tree.Rows()
+	if len(rows) != 4 || rows[2].Node != thing || rows[2].Depth != 2 || rows[2].Parent != layer {
+		t.Errorf("rows %+v", rows)
+	}
+	tree.Toggle(zone)
+	if rows := tree.Rows(); len(rows) != 2 {
+		t.Errorf("closed zone shows %d rows", len(rows))
+	}
+	// Nodes without kids do not open.
+	tree.Toggle(tree.Roots[1])
+	if tree.Roots[1].Open {
+		t.Errorf("empty node opened")
+	}
+}
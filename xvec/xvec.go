@@ -1114,3 +1114,15 @@ func ParseFS(fs fs.FS, name string) (*XVEC, error) {
 	defer fin.Close()
 	return Parse(fin)
 }
+
+// Preview parses the named xvec file of fsys and draws it on a new surface
+// of its size, for example to show it in a file dialog.
+func Preview(fsys fs.FS, name string) (*Surface, error) {
+	vec, err := ParseFS(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	s := ebiten.NewImage(max(int(vec.Size.W), 1), max(int(vec.Size.H), 1))
+	vec.Draw(s)
+	return s, nil
+}
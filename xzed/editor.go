@@ -3,15 +3,20 @@ package xzed
 import (
 	"fmt"
 	"image"
+	"io/fs"
 	"log/slog"
+	"os"
 	"path"
-	//	"os"
+	"slices"
+	"strconv"
 )
 
 import (
+	"github.com/xmasengine/xmas/wfs"
 	"github.com/xmasengine/xmas/xdat"
 	"github.com/xmasengine/xmas/xgal"
 	"github.com/xmasengine/xmas/xlui"
+	_ "github.com/xmasengine/xmas/xres" // Tile sources may be .aseprite files.
 )
 
 // interface to engine to avoid import cycles
@@ -19,6 +24,10 @@ type Engine interface {
 	LoadZone(name string) (*xdat.Zone, error)
 	SetLayerSource(layer *xdat.Layer, name string) error
 	GetLayer(depth int) *xdat.Layer
+	InvalidateTiles(depth int, r xgal.Rectangle)
+	ResetEmitters()
+	Effects() []string
+	Translate(key, fallback string) string
 }
 
 type Editor struct {
@@ -38,7 +47,10 @@ type Editor struct {
 	MessageTicks  int
 	Choosers      xlui.Stack
 	Done          bool
-	Mods          xlui.Mods // Mods are the latest latest key modifier
+	Mods          xlui.Mods   // Mods are the latest latest key modifier
+	Mark          image.Point // Mark is the first corner of a block to copy.
+	Block         *xdat.Tiles // Block is the copied block of tiles, if any.
+	Zones         fs.FS       // Zones is where the file dialogs browse the zones.
 	// Presence      Presence
 	// Backup
 	// Commander *Tila
@@ -110,7 +122,13 @@ func (e *Editor) Render(screen *xgal.Surface) {
 		cr := xgal.Bound(e.Over.X*m.TileWidth, e.Over.Y*m.TileHeight,
 			m.TileWidth, m.TileHeight).Sub(e.Camera.Min)
 
-		if e.Over.In(image.Rect(0, 0, m.Width-1, m.Height-1)) {
+		if e.Block != nil && len(e.Block.Rows) > 0 {
+			br := xgal.Bound(cr.Min.X, cr.Min.Y,
+				len(e.Block.Rows[0])*m.TileWidth, len(e.Block.Rows)*m.TileHeight)
+			e.renderBlock(screen, m, cr.Min)
+			style.DrawRect(screen, br)
+		} else if e.Over.In(image.Rect(0, 0, m.Width-1, m.Height-1)) {
+			e.renderCell(screen, m, cr, e.Cell)
 			style.DrawRect(screen, cr)
 		}
 		pr := cr.Min.Add(xgal.Pt(m.TileWidth, 0))
@@ -123,6 +141,9 @@ func (e *Editor) Render(screen *xgal.Surface) {
 			e.Name, tok, e.Over.X, e.Over.Y, e.Depth, e.Cell.X, e.Cell.Y, e.Cell.Flag))
 	}
 
+	e.renderLights(screen)
+	e.renderEmitters(screen)
+
 	pr := xgal.Pt(0, 0)
 	di := xgal.Pt(0, 12)
 	pr = pr.Add(di)
@@ -136,6 +157,220 @@ func (e *Editor) Render(screen *xgal.Surface) {
 	}
 }
 
+// renderLights shows where the fixed lights of the zone are and how far
+// they shine.
+func (e *Editor) renderLights(screen *xgal.Surface) {
+	if e.Zone == nil {
+		return
+	}
+	for _, light := range e.Zone.Lights {
+		if light.Follow != "" {
+			continue
+		}
+		at := light.At().Sub(e.Camera.Min)
+		col := light.Color.RGBA()
+		xgal.Disk(screen, at, 2, col)
+		xgal.Circle(screen, at, light.Radius, 1, col)
+	}
+}
+
+// renderEmitters marks where the fixed emitters of the zone are.
+func (e *Editor) renderEmitters(screen *xgal.Surface) {
+	if e.Zone == nil {
+		return
+	}
+	col := xgal.Tint(255, 255, 255)
+	for _, emitter := range e.Zone.Emitters {
+		if emitter.Flag != 0 {
+			continue
+		}
+		at := emitter.At().Sub(e.Camera.Min)
+		xgal.Andreas(screen, xgal.Rect(at.X-3, at.Y-3, at.X+3, at.Y+3), 1, col)
+	}
+}
+
+// overCenter returns the center of the hovered tile in pixels.
+func (e *Editor) overCenter() (xgal.Point, bool) {
+	m := e.ActiveLayer()
+	if m == nil {
+		return xgal.Point{}, false
+	}
+	return xgal.Pt(e.Over.X*m.TileWidth+m.TileWidth/2,
+		e.Over.Y*m.TileHeight+m.TileHeight/2), true
+}
+
+// LightRadiusStep is the step with which the radius of a light changes.
+const LightRadiusStep = 8
+
+// LightRadiusMax is the largest radius of a light placed in the editor.
+const LightRadiusMax = 128
+
+// ToggleLight places a light on the hovered tile, or removes the light
+// that is there.
+func (e *Editor) ToggleLight() {
+	at, ok := e.overCenter()
+	if !ok || e.Zone == nil {
+		return
+	}
+	if i := e.Zone.LightAt(at); i >= 0 {
+		e.Zone.RemoveLight(i)
+		e.ShowMessage("Removed light")
+		return
+	}
+	e.Zone.AddLight(xdat.NewLight(at.X, at.Y, 4*LightRadiusStep))
+	e.ShowMessage("Placed light at %d,%d", at.X, at.Y)
+}
+
+// hoveredLight returns the index of the light on the hovered tile, or -1
+// if there is none.
+func (e *Editor) hoveredLight() int {
+	at, ok := e.overCenter()
+	if !ok || e.Zone == nil {
+		return -1
+	}
+	return e.Zone.LightAt(at)
+}
+
+// GrowLight grows the radius of the hovered light, wrapping around to
+// the smallest radius after LightRadiusMax.
+func (e *Editor) GrowLight() {
+	i := e.hoveredLight()
+	if i < 0 {
+		return
+	}
+	light := &e.Zone.Lights[i]
+	light.Radius += LightRadiusStep
+	if light.Radius > LightRadiusMax {
+		light.Radius = LightRadiusStep
+	}
+	e.ShowMessage("Light radius %d", light.Radius)
+}
+
+// AskLightColor asks for the color of the hovered light, as #rrggbb or
+// #rrggbbaa.
+func (e *Editor) AskLightColor() {
+	i := e.hoveredLight()
+	if i < 0 {
+		return
+	}
+	xlui.Ask(50, 50, 250, 100, "Light color", e.Zone.Lights[i].Color.String(), func(text string) bool {
+		var col xdat.Color
+		if err := col.UnmarshalText([]byte(text)); err != nil {
+			e.Error = err
+			xlui.Complain(10, 10, 270, 120, err)
+			return false
+		}
+		e.Zone.Lights[i].Color = col
+		e.ShowMessage("Light color %s", col)
+		return true
+	})
+}
+
+// AskLightFlicker asks for the flicker of the hovered light, from 0 for a
+// steady light to 1.
+func (e *Editor) AskLightFlicker() {
+	i := e.hoveredLight()
+	if i < 0 {
+		return
+	}
+	flicker := strconv.FormatFloat(e.Zone.Lights[i].Flicker, 'g', -1, 64)
+	xlui.Ask(50, 50, 250, 100, "Light flicker", flicker, func(text string) bool {
+		flicker, err := strconv.ParseFloat(text, 64)
+		if err == nil && (flicker < 0 || flicker > 1) {
+			err = fmt.Errorf("flicker %g is not from 0 to 1", flicker)
+		}
+		if err != nil {
+			e.Error = err
+			xlui.Complain(10, 10, 270, 120, err)
+			return false
+		}
+		e.Zone.Lights[i].Flicker = flicker
+		e.ShowMessage("Light flicker %g", flicker)
+		return true
+	})
+}
+
+// PlaceEmitter places an emitter of the named effect on the hovered tile,
+// drawn over the active layer.
+func (e *Editor) PlaceEmitter(name string) bool {
+	at, ok := e.overCenter()
+	if !ok || e.Zone == nil {
+		return false
+	}
+	if !slices.Contains(e.Engine.Effects(), name) {
+		e.Error = fmt.Errorf("no such effect: %s", name)
+		return false
+	}
+	e.Zone.AddEmitter(xdat.Emitter{Effect: name, X: at.X, Y: at.Y, Depth: e.Depth})
+	e.Engine.ResetEmitters()
+	e.ShowMessage("Placed %s at %d,%d", name, at.X, at.Y)
+	return true
+}
+
+// RemoveEmitter removes the emitter on the hovered tile.
+func (e *Editor) RemoveEmitter() {
+	at, ok := e.overCenter()
+	if !ok || e.Zone == nil {
+		return
+	}
+	if i := e.Zone.EmitterAt(at); i >= 0 {
+		e.Zone.RemoveEmitter(i)
+		e.Engine.ResetEmitters()
+		e.ShowMessage("Removed emitter")
+	}
+}
+
+// renderCell previews cell in the rectangle to.
+func (e *Editor) renderCell(screen *xgal.Surface, m *xdat.Layer, to xgal.Rectangle, cell xdat.Tile) {
+	if m.Texture == nil {
+		return
+	}
+	fx := int(cell.X) * m.TileWidth
+	fy := int(cell.Y) * m.TileHeight
+	from := xgal.Bound(fx, fy, m.TileWidth, m.TileHeight)
+	xgal.Blit(screen, m.Texture, to, from, cell.Orientation().Opts())
+}
+
+// renderBlock previews the copied block with its top left corner at.
+func (e *Editor) renderBlock(screen *xgal.Surface, m *xdat.Layer, at xgal.Point) {
+	for y, row := range e.Block.Rows {
+		for x, cell := range row {
+			to := xgal.Bound(at.X+x*m.TileWidth, at.Y+y*m.TileHeight,
+				m.TileWidth, m.TileHeight)
+			e.renderCell(screen, m, to, cell)
+		}
+	}
+}
+
+// CopyBlock copies the block of tiles between the mark and the hovered tile.
+func (e *Editor) CopyBlock() {
+	m := e.ActiveLayer()
+	if m == nil {
+		return
+	}
+	r := image.Rectangle{Min: e.Mark, Max: e.Over}.Canon()
+	r.Max = r.Max.Add(image.Pt(1, 1))
+	block := m.Tiles.Block(r)
+	e.Block = &block
+	e.ShowMessage("Copied block %dx%d", r.Dx(), r.Dy())
+}
+
+// OrientBlock orients the copied block, including the tiles in it.
+func (e *Editor) OrientBlock(o xdat.Orientation) {
+	block := e.Block.Orient(o)
+	e.Block = &block
+}
+
+// OrientCell changes the orientation of the current cell, or of the
+// copied block if there is one.
+func (e *Editor) OrientCell(o xdat.Orientation) {
+	if e.Block != nil {
+		e.OrientBlock(o)
+		return
+	}
+	e.Cell.Flag = e.Cell.Flag.Orient(e.Cell.Orientation().Then(o))
+}
+
 func (e *Editor) UpdateChoosers() {
 	m := e.ActiveLayer()
 	if m == nil || m.Texture == nil {
@@ -171,6 +406,23 @@ func (e *Editor) LoadSource(name string) bool {
 	return e.Error == nil
 }
 
+// SetScale sets the scale of the user interface from the text of a
+// whole number of at least 1.
+func (e *Editor) SetScale(text string) bool {
+	scale, err := strconv.Atoi(text)
+	if err == nil && scale < 1 {
+		err = fmt.Errorf("UI scale %d is less than 1", scale)
+	}
+	e.Error = err
+	if err != nil {
+		xlui.Complain(10, 10, 270, 120, err)
+		return false
+	}
+	e.Scale = scale
+	xlui.SetScale(scale)
+	return true
+}
+
 func (e *Editor) LoadSpriteSurface(name string) bool {
 	/*
 		 TODO
@@ -253,9 +505,29 @@ func (e *Editor) SpriteSelected(x, y int) {
 const ZonePath = "pack/map"
 const TilePath = "pack/tile"
 
+// ZoneFS returns the file system of ZonePath that the file dialogs
+// browse, which can make folders if the directory can be opened as a
+// [wfs.CreateMkdirFS].
+func (e *Editor) ZoneFS() fs.FS {
+	if e.Zones == nil {
+		if zones, err := wfs.New(ZonePath); err == nil {
+			e.Zones = zones
+		} else {
+			e.Zones = os.DirFS(ZonePath)
+		}
+	}
+	return e.Zones
+}
+
+// SaveZone saves the zone to the named file of ZoneFS.
 func (e *Editor) SaveZone(name string) bool {
-	fullName := path.Join(ZonePath, name)
-	err := e.Zone.SaveFile(fullName)
+	out, err := wfs.Create(e.ZoneFS(), name)
+	if err == nil {
+		err = e.Zone.SaveTo(out)
+		if cerr := out.Close(); err == nil {
+			err = cerr
+		}
+	}
 	e.Error = err
 	if e.Error == nil {
 		e.Name = name
@@ -291,8 +563,11 @@ func (e Editor) FloodFill(at xgal.Point, cell xdat.Tile) {
 		return
 	}
 	m.FloodFill(at, cell)
+	e.Engine.InvalidateTiles(e.Depth, xgal.Rect(0, 0, m.Width, m.Height))
 }
 
+// HELP is the English help of the editor, shown when the string table
+// has no editor.help.
 const HELP = `HELP
 Mouse: Draw, select, drag pop up panes.
 Mouse Wheel: Select tile index.
@@ -302,10 +577,15 @@ Left Control+Alt: Flood fill.
 Pause: Exit without save.
 F1: This help.          | F2: Save map.
 F3: Show tile selector. | F4: Load map.
-						| P: Edit Prefix.
+S:  Set UI scale.       | P: Edit Prefix.
 F:  Load tile image.    | M: Toggle flag mode.
 H: Horizontal flip      | V: Vertical flip
-Y: Yank hovered tile.   | G: Edit flags.
+R: Rotate clockwise.    | Y: Yank hovered tile.
+K: Mark block corner.   | C: Copy block to here.
+X: Drop copied block.   | Click: Paste block.
+L: Place/remove light.  | Shift+L: Grow light.
+Ctrl+L: Light color.    | Alt+L: Light flicker.
+E: Place effect.        | Shift+E: Remove effect.
 Enter: Confirm dialogs. | Esc: Cancel dialogs.
 `
 
@@ -324,10 +604,15 @@ func (e *Editor) Click(at xgal.Point, button int) xlui.Reply {
 		return xlui.Ignore
 	}
 	if xgal.MouseButton(button) == xgal.MouseButtonLeft {
-		if e.Mods.Alt && e.Mods.Control {
+		if e.Block != nil && len(e.Block.Rows) > 0 {
+			layer.Tiles.Paste(e.Over, *e.Block)
+			size := xgal.Pt(len(e.Block.Rows[0]), len(e.Block.Rows))
+			e.Engine.InvalidateTiles(e.Depth, image.Rectangle{Min: e.Over, Max: e.Over.Add(size)})
+		} else if e.Mods.Alt && e.Mods.Control {
 			e.FloodFill(e.Over, e.Cell)
 		} else {
 			layer.Set(e.Over, e.Cell)
+			e.Engine.InvalidateTiles(e.Depth, xgal.Bound(e.Over.X, e.Over.Y, 1, 1))
 		}
 	}
 
@@ -404,23 +689,51 @@ func (e *Editor) Tap(key int, mods xlui.Mods) xlui.Reply {
 		e.Cell = e.ActiveLayer().Get(e.Over)
 		e.ShowMessage("Yanked %d", e.Cell)
 	case xgal.KeyH:
-		e.Cell.Flag.Toggle(xdat.FlagHorizontal)
+		e.OrientCell(xdat.OrientFlipH)
 	case xgal.KeyV:
-		e.Cell.Flag.Toggle(xdat.FlagVertical)
+		e.OrientCell(xdat.OrientFlipV)
 	case xgal.KeyB:
 		e.Cell.Flag.Toggle(xdat.FlagSolid)
 	case xgal.KeyR:
-		e.Cell.Flag.Rotate()
+		e.OrientCell(xdat.Orient90)
+	case xgal.KeyK:
+		e.Mark = e.Over
+		e.ShowMessage("Marked %d,%d", e.Mark.X, e.Mark.Y)
+	case xgal.KeyC:
+		e.CopyBlock()
+	case xgal.KeyX:
+		e.Block = nil
+	case xgal.KeyE:
+		if mods.Shift {
+			e.RemoveEmitter()
+		} else {
+			effect := ""
+			if names := e.Engine.Effects(); len(names) > 0 {
+				effect = names[0]
+			}
+			xlui.Ask(50, 50, 250, 100, "Effect", effect, e.PlaceEmitter)
+		}
+	case xgal.KeyL:
+		switch {
+		case mods.Shift:
+			e.GrowLight()
+		case mods.Control:
+			e.AskLightColor()
+		case mods.Alt:
+			e.AskLightFlicker()
+		default:
+			e.ToggleLight()
+		}
 	/*
 		case xgal.Key(xgal.KeyG):
 			e.Layer.AskText(50, 50, 250, 100, "Flag", &e.Cell.Flag)
 	*/
 	case xgal.KeyF1:
-		xlui.Display(10, 0, 300, 190, HELP)
+		xlui.Display(10, 0, 300, 190, e.Engine.Translate("editor.help", HELP))
 	case xgal.KeyF2:
-		xlui.Ask(50, 50, 250, 100, "Save As", e.Name, e.SaveZone)
+		xlui.FileDialog(10, 6, 300, 180, e.Engine.Translate("editor.save", "Save As"), e.ZoneFS(), e.Name, true, e.SaveZone, ".xml")
 	case xgal.KeyF4:
-		xlui.Ask(50, 50, 250, 100, "Load From", e.Name, e.LoadZone)
+		xlui.FileDialog(10, 6, 300, 180, e.Engine.Translate("editor.load", "Load From"), e.ZoneFS(), e.Name, false, e.LoadZone, ".xml")
 	case xgal.KeyU:
 		if mods.Shift {
 			// e.Backup.Commit(e.SaveZoneToFile)
@@ -444,7 +757,7 @@ func (e *Editor) Tap(key int, mods xlui.Mods) xlui.Reply {
 	case xgal.KeyO:
 		// e.Layer.AskInt(50, 50, 250, 100, "Offset", &e.Zone.Offset)
 	case xgal.KeyS:
-		// e.Layer.AskInt(50, 50, 250, 100, "UI Scale", &e.Scale)
+		xlui.Ask(50, 50, 250, 100, e.Engine.Translate("editor.scale", "UI Scale"), strconv.Itoa(e.Scale), e.SetScale)
 	case xgal.KeyF3:
 		if xgal.Key(xgal.KeyShiftLeft) {
 			// choose := e.Layer.Chooser(200, 100, e.Zone.Sprites.Surface, e.SpriteSelected)